@@ -0,0 +1,27 @@
+// SPDX-License-Identifier: MIT
+
+pragma solidity ^0.8.0;
+
+/**
+ * @dev Interface of a push price feed - an aggregator contract to which a provider's go-ooo
+ * node submits answers on deviation or heartbeat triggers. Reads follow the Chainlink
+ * AggregatorV3Interface, so that consumers can use existing feed clients.
+ */
+interface IPriceFeed {
+    event AnswerUpdated(int256 indexed current, uint256 indexed roundId, uint256 updatedAt);
+
+    function decimals() external view returns (uint8);
+
+    function latestRoundData()
+        external
+        view
+        returns (
+            uint80 roundId,
+            int256 answer,
+            uint256 startedAt,
+            uint256 updatedAt,
+            uint80 answeredInRound
+        );
+
+    function updateAnswer(int256 _answer) external;
+}
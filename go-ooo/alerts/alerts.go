@@ -0,0 +1,426 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// alert types
+const (
+	AlertLowBalance        = "low_balance"
+	AlertFulfillmentFailed = "fulfillment_failed"
+	AlertRpcDown           = "rpc_down"
+	AlertSubgraphUnhealthy = "subgraph_unhealthy"
+	AlertGasBudgetExceeded = "gas_budget_exceeded"
+	AlertOutdatedVersion   = "outdated_version"
+	AlertPeerDeviation     = "peer_deviation"
+	AlertPairHeartbeat     = "pair_heartbeat"
+	// AlertFulfillmentQuiesced is keyed by the condition, e.g. provider_deregistered
+	AlertFulfillmentQuiesced = "fulfillment_quiesced"
+	AlertFeeSchedule         = "fee_schedule"
+	AlertClockSkew           = "clock_skew"
+	// AlertCredentialRotation and AlertCredentialQuota are keyed by the adapter credential's name
+	AlertCredentialRotation = "credential_rotation"
+	AlertCredentialQuota    = "credential_quota"
+	AlertReconciliation     = "reconciliation"
+	// AlertMaintenance is sent when maintenance mode starts, and resolved when it ends. It is the
+	// only alert sent during maintenance
+	AlertMaintenance = "maintenance"
+	// AlertPriceBound is keyed by the endpoint whose answer was outside its pair's price bound
+	AlertPriceBound = "price_bound"
+	// AlertPairDelisted is keyed by the pair Finchains stopped listing, and names the consumers
+	// which recently requested it
+	AlertPairDelisted = "pair_delisted"
+	// AlertStateSnapshot is sent when the state snapshot saved on shutdown does not match the db or
+	// chain on the next startup, or when no snapshot was saved because the node did not shut down cleanly
+	AlertStateSnapshot = "state_snapshot"
+)
+
+// severity levels, matching those of the PagerDuty Events API
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityError    = "error"
+	SeverityCritical = "critical"
+)
+
+// defaultSeverities - severity of each alert type, unless overridden in config
+var defaultSeverities = map[string]string{
+	AlertLowBalance:          SeverityCritical,
+	AlertFulfillmentFailed:   SeverityError,
+	AlertRpcDown:             SeverityCritical,
+	AlertSubgraphUnhealthy:   SeverityWarning,
+	AlertGasBudgetExceeded:   SeverityWarning,
+	AlertOutdatedVersion:     SeverityWarning,
+	AlertPeerDeviation:       SeverityWarning,
+	AlertPairHeartbeat:       SeverityError,
+	AlertFulfillmentQuiesced: SeverityCritical,
+	AlertFeeSchedule:         SeverityWarning,
+	AlertClockSkew:           SeverityWarning,
+	AlertCredentialRotation:  SeverityWarning,
+	AlertCredentialQuota:     SeverityError,
+	AlertReconciliation:      SeverityWarning,
+	AlertMaintenance:         SeverityInfo,
+	AlertPriceBound:          SeverityError,
+	AlertPairDelisted:        SeverityWarning,
+	AlertStateSnapshot:       SeverityWarning,
+}
+
+// sink names, used to route alert types to sinks
+const (
+	SinkTelegram  = "telegram"
+	SinkSlack     = "slack"
+	SinkWebhook   = "webhook"
+	SinkPagerDuty = "pagerduty"
+	SinkEmail     = "email"
+)
+
+// queueSize - alerts are dropped if this many are waiting to be delivered
+const queueSize = 64
+
+// Alert is a single notification pushed to each configured sink
+type Alert struct {
+	Type     string
+	Key      string
+	Severity string
+	Message  string
+	// Resolved is set when the alert's condition has cleared
+	Resolved  bool
+	Timestamp int64
+}
+
+func (a Alert) text() string {
+	return fmt.Sprintf("[go-ooo] %s %s: %s", strings.ToUpper(a.Severity), a.Type, a.Message)
+}
+
+func (a Alert) dedupKey() string {
+	return fmt.Sprintf("%s:%s", a.Type, a.Key)
+}
+
+type sink interface {
+	name() string
+	send(ctx context.Context, client *http.Client, a Alert) error
+}
+
+// Config holds the sink credentials and delivery settings for an Alerter
+type Config struct {
+	TelegramBotToken string
+	TelegramChatId   string
+	SlackWebhookUrl  string
+	WebhookUrl       string
+	// WebhookSecret - if set, webhook payloads are signed with HMAC-SHA256
+	WebhookSecret       string
+	PagerDutyRoutingKey string
+	Email               EmailConfig
+	// Routes - the sinks each alert type is sent to. Types without a route are sent to all sinks
+	Routes map[string][]string
+	// Severities - overrides the default severity of alert types
+	Severities map[string]string
+	// Cooldown - an alert with the same type and key is not repeated within this period
+	Cooldown time.Duration
+	Timeout  time.Duration
+}
+
+// Alerter pushes operational alerts to Telegram, Slack, a generic webhook, PagerDuty and
+// email in the background
+type Alerter struct {
+	mu       sync.Mutex
+	settings *alerterSettings
+	running  bool
+	lastSent map[string]time.Time
+	queue    chan Alert
+	logger   *logrus.Logger
+	ctx      context.Context
+
+	// maintenance suppresses alerts, e.g. during planned work
+	maintenance bool
+}
+
+// alerterSettings are the sinks and delivery settings built from a Config, which Reconfigure
+// replaces
+type alerterSettings struct {
+	sinks      []sink
+	routes     map[string]map[string]bool
+	severities map[string]string
+	cooldown   time.Duration
+	client     *http.Client
+	// cancel stops the sinks' background work, e.g. email batching
+	cancel context.CancelFunc
+}
+
+// NewAlerter returns an Alerter with a sink for each configured service. Delivery
+// runs until ctx is done
+func NewAlerter(ctx context.Context, logger *logrus.Logger, cfg Config) *Alerter {
+	a := &Alerter{
+		lastSent: make(map[string]time.Time),
+		queue:    make(chan Alert, queueSize),
+		logger:   logger,
+		ctx:      ctx,
+	}
+
+	a.Reconfigure(cfg)
+
+	return a
+}
+
+// Reconfigure replaces the sinks and delivery settings, e.g. when the config is reloaded.
+// Alerts already queued are delivered with the new settings, and cooldowns carry over
+func (a *Alerter) Reconfigure(cfg Config) {
+	ctx, cancel := context.WithCancel(a.ctx)
+	s := &alerterSettings{
+		routes:     make(map[string]map[string]bool),
+		severities: make(map[string]string),
+		cooldown:   cfg.Cooldown,
+		client:     &http.Client{Timeout: cfg.Timeout},
+		cancel:     cancel,
+	}
+
+	for alertType, severity := range defaultSeverities {
+		s.severities[alertType] = severity
+	}
+
+	for alertType, severity := range cfg.Severities {
+		severity = strings.ToLower(severity)
+		switch severity {
+		case SeverityInfo, SeverityWarning, SeverityError, SeverityCritical:
+			s.severities[strings.ToLower(alertType)] = severity
+		default:
+			a.logger.WithFields(logrus.Fields{
+				"package":  "alerts",
+				"function": "Reconfigure",
+				"type":     alertType,
+				"severity": severity,
+			}).Warn("unknown alert severity - using default")
+		}
+	}
+
+	for alertType, sinks := range cfg.Routes {
+		route := make(map[string]bool)
+		for _, name := range sinks {
+			route[strings.ToLower(name)] = true
+		}
+		s.routes[strings.ToLower(alertType)] = route
+	}
+
+	if cfg.TelegramBotToken != "" && cfg.TelegramChatId != "" {
+		s.sinks = append(s.sinks, &telegramSink{token: cfg.TelegramBotToken, chatId: cfg.TelegramChatId})
+	}
+
+	if cfg.SlackWebhookUrl != "" {
+		s.sinks = append(s.sinks, &slackSink{webhookUrl: cfg.SlackWebhookUrl})
+	}
+
+	if cfg.WebhookUrl != "" {
+		s.sinks = append(s.sinks, &webhookSink{url: cfg.WebhookUrl, secret: cfg.WebhookSecret})
+	}
+
+	if cfg.PagerDutyRoutingKey != "" {
+		s.sinks = append(s.sinks, &pagerDutySink{routingKey: cfg.PagerDutyRoutingKey})
+	}
+
+	if cfg.Email.Host != "" && cfg.Email.From != "" && len(cfg.Email.To) > 0 {
+		s.sinks = append(s.sinks, newEmailSink(ctx, a.logger, cfg.Email, cfg.Timeout))
+	}
+
+	a.mu.Lock()
+	old := a.settings
+	a.settings = s
+	start := len(s.sinks) > 0 && !a.running
+	if start {
+		a.running = true
+	}
+	a.mu.Unlock()
+
+	if old != nil {
+		old.cancel()
+	}
+	if start {
+		go a.run()
+	}
+}
+
+// current returns the settings in use
+func (a *Alerter) current() *alerterSettings {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.settings
+}
+
+// Enabled returns true if any sinks are configured
+func (a *Alerter) Enabled() bool {
+	return a != nil && len(a.current().sinks) > 0
+}
+
+// SetMaintenance suppresses all alerts other than AlertMaintenance while active is true, so that
+// planned work does not page the on-call. Suppressed alerts are not subject to the cooldown, and
+// are sent by the next check after maintenance ends if their condition persists
+func (a *Alerter) SetMaintenance(active bool) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	a.maintenance = active
+	a.mu.Unlock()
+}
+
+// Alert queues an alert for delivery, unless one with the same type and key was sent
+// within the cooldown period, or the node is in maintenance. It does not block
+func (a *Alerter) Alert(alertType string, key string, message string) {
+	if !a.Enabled() {
+		return
+	}
+
+	a.mu.Lock()
+	suppressed := a.maintenance && alertType != AlertMaintenance
+	a.mu.Unlock()
+	if suppressed {
+		a.logger.WithFields(logrus.Fields{
+			"package":  "alerts",
+			"function": "Alert",
+			"type":     alertType,
+			"key":      key,
+		}).Debug("alert suppressed during maintenance: ", message)
+		return
+	}
+
+	al := Alert{
+		Type:      alertType,
+		Key:       key,
+		Severity:  a.current().severity(alertType),
+		Message:   message,
+		Timestamp: time.Now().Unix(),
+	}
+
+	a.mu.Lock()
+	if last, ok := a.lastSent[al.dedupKey()]; ok && time.Since(last) < a.settings.cooldown {
+		a.mu.Unlock()
+		return
+	}
+	a.lastSent[al.dedupKey()] = time.Now()
+	a.pruneLocked()
+	a.mu.Unlock()
+
+	a.enqueue(al)
+}
+
+// Resolve marks an alert's condition as cleared. Sinks which track incidents are notified, and
+// the cooldown is reset so that the alert is sent straight away if the condition recurs
+func (a *Alerter) Resolve(alertType string, key string) {
+	if !a.Enabled() {
+		return
+	}
+
+	al := Alert{
+		Type:      alertType,
+		Key:       key,
+		Severity:  a.current().severity(alertType),
+		Message:   "resolved",
+		Resolved:  true,
+		Timestamp: time.Now().Unix(),
+	}
+
+	a.mu.Lock()
+	_, active := a.lastSent[al.dedupKey()]
+	delete(a.lastSent, al.dedupKey())
+	a.mu.Unlock()
+
+	if active {
+		a.enqueue(al)
+	}
+}
+
+// pruneLocked forgets alerts whose cooldown has passed, e.g. failed fulfillments, which
+// are keyed by request and never resolved. a.mu must be held
+func (a *Alerter) pruneLocked() {
+	if len(a.lastSent) < queueSize {
+		return
+	}
+	for id, last := range a.lastSent {
+		if time.Since(last) >= a.settings.cooldown {
+			delete(a.lastSent, id)
+		}
+	}
+}
+
+func (s *alerterSettings) severity(alertType string) string {
+	if severity, ok := s.severities[alertType]; ok {
+		return severity
+	}
+	return SeverityWarning
+}
+
+// routed returns true if alerts of the given type should be sent to the sink
+func (s *alerterSettings) routed(alertType string, sinkName string) bool {
+	route, ok := s.routes[alertType]
+	if !ok {
+		return true
+	}
+	return route[sinkName]
+}
+
+func (a *Alerter) enqueue(al Alert) {
+	select {
+	case a.queue <- al:
+	default:
+		a.logger.WithFields(logrus.Fields{
+			"package":  "alerts",
+			"function": "Alert",
+			"type":     al.Type,
+			"key":      al.Key,
+		}).Warn("alert queue full - alert dropped")
+	}
+}
+
+func (a *Alerter) run() {
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case al := <-a.queue:
+			settings := a.current()
+			for _, s := range settings.sinks {
+				if settings.routed(al.Type, s.name()) {
+					a.deliver(settings, s, al)
+				}
+			}
+		}
+	}
+}
+
+func (a *Alerter) deliver(settings *alerterSettings, s sink, al Alert) {
+	send := func() error {
+		return s.send(a.ctx, settings.client, al)
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.MaxElapsedTime = time.Minute
+
+	err := backoff.Retry(send, backoff.WithContext(b, a.ctx))
+	if err != nil {
+		a.logger.WithFields(logrus.Fields{
+			"package":  "alerts",
+			"function": "deliver",
+			"sink":     s.name(),
+			"type":     al.Type,
+			"key":      al.Key,
+		}).Error(err.Error())
+	}
+}
+
+// checkResponse returns a retryable error for 5xx and 429 responses, and a permanent one for
+// any other non-2xx response
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("alert sink returned %s", resp.Status)
+	}
+	if resp.StatusCode >= 300 {
+		return backoff.Permanent(fmt.Errorf("alert sink returned %s", resp.Status))
+	}
+	return nil
+}
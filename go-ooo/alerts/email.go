@@ -0,0 +1,285 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/sirupsen/logrus"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// SMTP connection security
+const (
+	SmtpTlsStartTls = "starttls" // upgrade a plain connection with STARTTLS. Required, not opportunistic
+	SmtpTlsImplicit = "tls"      // connect over TLS, usually port 465
+	SmtpTlsNone     = "none"     // plain connection, e.g. to a local relay
+)
+
+const defaultEmailSubjectTemplate = `[go-ooo] {{if eq (len .Alerts) 1}}{{with index .Alerts 0}}{{upper .Severity}} {{.Type}}{{if .Resolved}} resolved{{end}}{{end}}{{else}}{{len .Alerts}} alerts{{end}}`
+
+const defaultEmailBodyTemplate = `{{range .Alerts}}{{time .Timestamp}}  {{upper .Severity}}  {{.Type}}{{if .Key}} ({{.Key}}){{end}}
+  {{if .Resolved}}resolved{{else}}{{.Message}}{{end}}
+
+{{end}}--
+sent by go-ooo on {{.Host}}
+`
+
+var emailTemplateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"time": func(ts int64) string {
+		return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+	},
+}
+
+// EmailConfig holds the SMTP settings for the email sink
+type EmailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+	// Tls - starttls, tls or none
+	Tls string
+	// SubjectTemplate and BodyTemplate are text/template templates, executed with the Alerts
+	// in the batch and the Host sending them. Empty uses the defaults
+	SubjectTemplate string
+	BodyTemplate    string
+	// BatchWindow - alerts raised within this period are sent in a single email. 0 sends each immediately
+	BatchWindow time.Duration
+}
+
+// emailTemplateData is passed to the subject and body templates
+type emailTemplateData struct {
+	Alerts []Alert
+	Host   string
+}
+
+// emailSink sends alerts by email, batching those raised close together into a single message
+type emailSink struct {
+	cfg     EmailConfig
+	subject *template.Template
+	body    *template.Template
+	timeout time.Duration
+	logger  *logrus.Logger
+
+	mu      sync.Mutex
+	pending []Alert
+}
+
+func newEmailSink(ctx context.Context, logger *logrus.Logger, cfg EmailConfig, timeout time.Duration) *emailSink {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	cfg.Tls = strings.ToLower(cfg.Tls)
+	switch cfg.Tls {
+	case SmtpTlsStartTls, SmtpTlsImplicit, SmtpTlsNone:
+	default:
+		cfg.Tls = SmtpTlsStartTls
+	}
+
+	e := &emailSink{
+		cfg:     cfg,
+		subject: parseEmailTemplate(logger, "subject", cfg.SubjectTemplate, defaultEmailSubjectTemplate),
+		body:    parseEmailTemplate(logger, "body", cfg.BodyTemplate, defaultEmailBodyTemplate),
+		timeout: timeout,
+		logger:  logger,
+	}
+
+	if cfg.BatchWindow > 0 {
+		go e.run(ctx)
+	}
+
+	return e
+}
+
+// parseEmailTemplate parses an operator supplied template, falling back to the default if it is invalid
+func parseEmailTemplate(logger *logrus.Logger, name string, text string, defaultText string) *template.Template {
+	if text != "" {
+		t, err := template.New(name).Funcs(emailTemplateFuncs).Parse(text)
+		if err == nil {
+			return t
+		}
+		logger.WithFields(logrus.Fields{
+			"package":  "alerts",
+			"function": "parseEmailTemplate",
+			"template": name,
+		}).Warn(fmt.Sprintf("invalid email template - using default: %s", err.Error()))
+	}
+	return template.Must(template.New(name).Funcs(emailTemplateFuncs).Parse(defaultText))
+}
+
+func (e *emailSink) name() string {
+	return SinkEmail
+}
+
+func (e *emailSink) send(ctx context.Context, client *http.Client, a Alert) error {
+	if e.cfg.BatchWindow <= 0 {
+		return e.sendBatch([]Alert{a})
+	}
+
+	e.mu.Lock()
+	e.pending = append(e.pending, a)
+	e.mu.Unlock()
+
+	return nil
+}
+
+// run sends the alerts accumulated in each batch window, until ctx is done, when any still
+// pending are sent once, e.g. when the alerter is reconfigured
+func (e *emailSink) run(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.BatchWindow)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.mu.Lock()
+			batch := e.pending
+			e.pending = nil
+			e.mu.Unlock()
+
+			if len(batch) > 0 {
+				if err := e.sendBatch(batch); err != nil {
+					e.logger.WithFields(logrus.Fields{
+						"package":    "alerts",
+						"function":   "run",
+						"sink":       SinkEmail,
+						"num_alerts": len(batch),
+					}).Error(err.Error())
+				}
+			}
+			return
+		case <-ticker.C:
+			e.mu.Lock()
+			batch := e.pending
+			e.pending = nil
+			e.mu.Unlock()
+
+			if len(batch) == 0 {
+				continue
+			}
+
+			b := backoff.NewExponentialBackOff()
+			b.MaxElapsedTime = time.Minute
+
+			err := backoff.Retry(func() error {
+				return e.sendBatch(batch)
+			}, backoff.WithContext(b, ctx))
+			if err != nil {
+				e.logger.WithFields(logrus.Fields{
+					"package":    "alerts",
+					"function":   "run",
+					"sink":       SinkEmail,
+					"num_alerts": len(batch),
+				}).Error(err.Error())
+			}
+		}
+	}
+}
+
+func (e *emailSink) sendBatch(batch []Alert) error {
+	host, _ := os.Hostname()
+	data := emailTemplateData{Alerts: batch, Host: host}
+
+	var subject, body bytes.Buffer
+	if err := e.subject.Execute(&subject, data); err != nil {
+		return backoff.Permanent(err)
+	}
+	if err := e.body.Execute(&body, data); err != nil {
+		return backoff.Permanent(err)
+	}
+
+	return e.deliver(e.message(subject.String(), body.String()))
+}
+
+// message builds the RFC 5322 message
+func (e *emailSink) message(subject string, body string) []byte {
+	// header values must not contain line breaks
+	subject = strings.Join(strings.Fields(subject), " ")
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+
+	return msg.Bytes()
+}
+
+// deliver sends msg to the configured recipients over SMTP
+func (e *emailSink) deliver(msg []byte) error {
+	addr := net.JoinHostPort(e.cfg.Host, fmt.Sprint(e.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: e.cfg.Host}
+	dialer := &net.Dialer{Timeout: e.timeout}
+
+	var conn net.Conn
+	var err error
+	if e.cfg.Tls == SmtpTlsImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(e.timeout))
+
+	c, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if e.cfg.Tls == SmtpTlsStartTls {
+		if err = c.StartTLS(tlsConfig); err != nil {
+			return backoff.Permanent(fmt.Errorf("starttls: %s", err.Error()))
+		}
+	}
+
+	if e.cfg.Username != "" {
+		if err = c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)); err != nil {
+			return backoff.Permanent(fmt.Errorf("smtp auth: %s", err.Error()))
+		}
+	}
+
+	if err = c.Mail(e.cfg.From); err != nil {
+		return err
+	}
+	for _, to := range e.cfg.To {
+		if err = c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
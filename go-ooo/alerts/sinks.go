@@ -0,0 +1,171 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/cenkalti/backoff/v4"
+	"go-ooo/webhooks"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	telegramApiUrl  = "https://api.telegram.org"
+	pagerDutyApiUrl = "https://events.pagerduty.com/v2/enqueue"
+)
+
+// telegramSink sends alerts to a chat via the Telegram Bot API
+type telegramSink struct {
+	token  string
+	chatId string
+}
+
+func (t *telegramSink) name() string {
+	return SinkTelegram
+}
+
+func (t *telegramSink) send(ctx context.Context, client *http.Client, a Alert) error {
+	if a.Resolved {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"chat_id": t.chatId,
+		"text":    a.text(),
+	})
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+
+	return postJson(ctx, client, fmt.Sprintf("%s/bot%s/sendMessage", telegramApiUrl, t.token), body, nil)
+}
+
+// slackSink sends alerts to a Slack incoming webhook
+type slackSink struct {
+	webhookUrl string
+}
+
+func (s *slackSink) name() string {
+	return SinkSlack
+}
+
+func (s *slackSink) send(ctx context.Context, client *http.Client, a Alert) error {
+	if a.Resolved {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"text": a.text(),
+	})
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+
+	return postJson(ctx, client, s.webhookUrl, body, nil)
+}
+
+// WebhookPayload is the JSON body POSTed to the generic alert webhook
+type WebhookPayload struct {
+	Type      string `json:"type"`
+	Key       string `json:"key,omitempty"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+	Resolved  bool   `json:"resolved"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// webhookSink POSTs alerts, and their resolution, as JSON to an operator supplied url
+type webhookSink struct {
+	url    string
+	secret string
+}
+
+func (w *webhookSink) name() string {
+	return SinkWebhook
+}
+
+func (w *webhookSink) send(ctx context.Context, client *http.Client, a Alert) error {
+	body, err := json.Marshal(WebhookPayload{
+		Type:      a.Type,
+		Key:       a.Key,
+		Severity:  a.Severity,
+		Message:   a.Message,
+		Resolved:  a.Resolved,
+		Timestamp: a.Timestamp,
+	})
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+
+	var headers map[string]string
+	if w.secret != "" {
+		headers = map[string]string{webhooks.SignatureHeader: webhooks.Sign(body, w.secret)}
+	}
+
+	return postJson(ctx, client, w.url, body, headers)
+}
+
+// pagerDutySink triggers and resolves PagerDuty incidents via the Events API v2. Alerts with
+// the same type and key are grouped into one incident
+type pagerDutySink struct {
+	routingKey string
+}
+
+func (p *pagerDutySink) name() string {
+	return SinkPagerDuty
+}
+
+func (p *pagerDutySink) send(ctx context.Context, client *http.Client, a Alert) error {
+	event := map[string]interface{}{
+		"routing_key":  p.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    a.dedupKey(),
+	}
+
+	if a.Resolved {
+		event["event_action"] = "resolve"
+	} else {
+		event["payload"] = map[string]interface{}{
+			"summary":   a.text(),
+			"source":    "go-ooo",
+			"severity":  a.Severity,
+			"component": a.Type,
+			"timestamp": time.Unix(a.Timestamp, 0).UTC().Format(time.RFC3339),
+		}
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+
+	return postJson(ctx, client, pagerDutyApiUrl, body, nil)
+}
+
+// postJson POSTs body to sinkUrl. Sink urls contain credentials, so they are stripped
+// from any returned error
+func postJson(ctx context.Context, client *http.Client, sinkUrl string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", sinkUrl, bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(fmt.Errorf("invalid alert sink url"))
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp)
+}
@@ -2,15 +2,25 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"go-ooo/chain"
 	"go-ooo/config"
+	"go-ooo/credentials"
 	"go-ooo/database"
+	"go-ooo/errreport"
 	"go-ooo/keystore"
+	"go-ooo/logrotate"
+	"go-ooo/redact"
 	"go-ooo/service"
+	"go-ooo/signer"
+	"go-ooo/supervisor"
+	"go-ooo/vault"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"go-ooo/version"
 )
@@ -22,6 +32,9 @@ type Server struct {
 	Vers        version.Info
 	keystore    *keystore.Keystorage
 	db          *database.DB
+	reporter    *errreport.Reporter
+	vault       *vault.Client
+	vaultSecret map[string]string
 	decryptPass string
 }
 
@@ -38,6 +51,8 @@ func NewServer(decryptPass string) (*Server, error) {
 }
 
 func (s *Server) InitServer() {
+	defer s.reportPanic()
+
 	s.logger.WithFields(logrus.Fields{
 		"package":  "main",
 		"function": "main",
@@ -46,23 +61,113 @@ func (s *Server) InitServer() {
 }
 
 func (s *Server) Run() {
+	defer s.reportPanic()
+
 	s.srv.Run()
 }
 
+// Replay initialises the service, without running it, and replays events from fromBlock
+func (s *Server) Replay(fromBlock uint64) (chain.ReplaySummary, error) {
+	s.initLogger()
+	s.initVault()
+	s.initDatabase()
+	s.initKeystore()
+	s.initService()
+
+	return s.srv.Replay(fromBlock)
+}
+
+// Backtest initialises the service, without running it, and backtests requests made between
+// fromBlock and toBlock against the current config
+func (s *Server) Backtest(fromBlock uint64, toBlock uint64) (chain.BacktestSummary, error) {
+	s.initLogger()
+	s.initVault()
+	s.initDatabase()
+	s.initKeystore()
+	s.initService()
+
+	return s.srv.Backtest(fromBlock, toBlock)
+}
+
 func (s *Server) initServer() {
 	s.initLogger()
+	s.initErrorReporting()
+	s.initVault()
 	s.initDatabase()
 	s.initKeystore()
 	s.initService()
 	s.initSignal()
+	s.initLogLevelSignal()
+	s.initReloadSignal()
 }
 
 func (s *Server) initLogger() {
-	s.logger.SetFormatter(&logrus.TextFormatter{
-		//DisableColors: true,
-		FullTimestamp: true,
+	// added first, so that secrets are masked before any other hook sees an entry
+	redact.AddConfigSecrets(viper.GetViper())
+	s.logger.AddHook(redact.Hook{})
+	logrus.AddHook(redact.Hook{})
+
+	switch viper.GetString(config.LogFormat) {
+	case "json":
+		s.logger.SetFormatter(&logrus.JSONFormatter{})
+	case "logfmt":
+		s.logger.SetFormatter(&logrus.TextFormatter{
+			DisableColors: true,
+			FullTimestamp: true,
+		})
+	default:
+		s.logger.SetFormatter(&logrus.TextFormatter{
+			//DisableColors: true,
+			FullTimestamp: true,
+		})
+	}
+
+	logrusLevel := configuredLogLevel()
+	s.logger.SetLevel(logrusLevel)
+	s.logger.SetOutput(os.Stdout)
+
+	logFile := viper.GetString(config.LogFile)
+	if logFile != "" {
+		w, err := logrotate.NewWriter(logFile, viper.GetInt64(config.LogMaxSize)*1024*1024,
+			viper.GetInt(config.LogMaxBackups), viper.GetBool(config.LogCompress))
+		if err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"package":  "main",
+				"function": "initLogger",
+				"file":     logFile,
+			}).Error(fmt.Sprintf("cannot open log file, logging to stdout: %s", err.Error()))
+			return
+		}
+		s.logger.SetOutput(w)
+	}
+}
+
+// initErrorReporting reports panics, and errors which keep being logged, to Sentry if configured
+func (s *Server) initErrorReporting() {
+	s.reporter = errreport.NewReporter(s.logger, errreport.Config{
+		Dsn:             viper.GetString(config.ErrorReportingSentryDsn),
+		Environment:     viper.GetString(config.ErrorReportingEnvironment),
+		Release:         s.Vers.Version,
+		RepeatThreshold: viper.GetInt(config.ErrorReportingRepeatThreshold),
+		RepeatWindow:    time.Duration(viper.GetInt64(config.ErrorReportingRepeatWindow)) * time.Second,
 	})
 
+	if s.reporter != nil {
+		s.logger.AddHook(s.reporter)
+	}
+}
+
+// reportPanic reports a panic in the calling goroutine, if error reporting is configured,
+// then re-panics
+func (s *Server) reportPanic() {
+	if p := recover(); p != nil {
+		p = redact.Panic(p)
+		s.reporter.ReportPanic(p)
+		panic(p)
+	}
+}
+
+func configuredLogLevel() logrus.Level {
 	logLevel := viper.GetString(config.LogLevel)
 	logrusLevel := logrus.InfoLevel
 
@@ -78,8 +183,43 @@ func (s *Server) initLogger() {
 		break
 	}
 
-	s.logger.SetLevel(logrusLevel)
-	s.logger.SetOutput(os.Stdout)
+	return logrusLevel
+}
+
+// initLogLevelSignal toggles between debug and the configured log level on SIGUSR1
+func (s *Server) initLogLevelSignal() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGUSR1)
+	go func() {
+		for range c {
+			level := logrus.DebugLevel
+			if s.logger.GetLevel() == logrus.DebugLevel {
+				level = configuredLogLevel()
+			}
+			s.logger.SetLevel(level)
+
+			s.logger.WithFields(logrus.Fields{
+				"package":  "main",
+				"function": "initLogLevelSignal",
+			}).Warn(fmt.Sprintf("log level changed to %s", level.String()))
+		}
+	}()
+}
+
+// initReloadSignal reloads the config file on SIGHUP
+func (s *Server) initReloadSignal() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	go func() {
+		for range c {
+			if _, err := s.srv.ReloadConfig(); err != nil {
+				s.logger.WithFields(logrus.Fields{
+					"package":  "main",
+					"function": "initReloadSignal",
+				}).Error(fmt.Sprintf("cannot reload config: %s", err.Error()))
+			}
+		}
+	}()
 }
 
 func (s *Server) initSignal() {
@@ -116,10 +256,11 @@ func (s *Server) initKeystore() {
 
 	s.keystore = ks
 
-	decryptPassword := ""
-	if s.decryptPass != "" {
-		decryptPassword = getPasswordFromFileOrFlag(s.decryptPass)
+	decryptPassword, err := s.keystorePassword()
+	if err != nil {
+		panic(err)
 	}
+	redact.AddSecrets(decryptPassword)
 
 	if decryptPassword == "" || (s.keystore.CheckToken(decryptPassword) != nil) {
 		err = s.auth()
@@ -128,7 +269,13 @@ func (s *Server) initKeystore() {
 		}
 	}
 
-	err = s.keystore.SelectPrivateKey(viper.GetString(config.KeystorageAccount))
+	if signerBackend() != signer.BackendKeystore {
+		// the oracle key is held by the signer backend
+		return
+	}
+
+	account, _ := oracleAccount()
+	err = s.keystore.SelectPrivateKey(account)
 	if err != nil {
 		panic(err)
 	}
@@ -146,10 +293,55 @@ func (s *Server) initDatabase() {
 		panic(err)
 	}
 	s.db = dbConn
+
+	// refuse to run against a schema this build does not expect, rather than fail on queries later
+	if err = s.db.CheckSchemaVersion(); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "main",
+			"function": "initDatabase",
+		}).Error(err.Error())
+		os.Exit(1)
+	}
+
+	// creates a new database's schema, and adds any new tables and columns
 	err = s.db.Migrate()
 	if err != nil {
 		panic(err)
 	}
+
+	s.initDatabaseEncryption()
+}
+
+// initDatabaseEncryption enables encryption of sensitive columns, if a key is configured, and
+// encrypts any values still in plaintext or under a previous key
+func (s *Server) initDatabaseEncryption() {
+	logger := s.logger.WithFields(logrus.Fields{
+		"package":  "main",
+		"function": "initDatabaseEncryption",
+	})
+
+	key := viper.GetString(config.DatabaseEncryptionKey)
+	if key == "" {
+		key = s.vaultField(config.VaultDatabaseKeyField, "database_encryption_key")
+	}
+	if key == "" {
+		return
+	}
+	redact.AddSecrets(key)
+
+	if err := s.db.SetEncryptionKeys(key, viper.GetStringSlice(config.DatabaseEncryptionPreviousKeys)); err != nil {
+		logger.Error("database encryption key: ", err.Error())
+		os.Exit(1)
+	}
+
+	updated, err := s.db.EncryptSensitiveColumns()
+	if err != nil {
+		logger.Error("cannot encrypt sensitive columns: ", err.Error())
+		os.Exit(1)
+	}
+	if updated > 0 {
+		logger.WithField("rows", updated).Info("encrypted sensitive columns with the current key")
+	}
 }
 
 func (s *Server) initService() {
@@ -158,8 +350,34 @@ func (s *Server) initService() {
 		"function": "initService",
 	}).Info("initialise service")
 
-	srv, err := service.NewService(s.ctx, s.logger, []byte(s.keystore.GetSelectedPrivateKey()),
-		s.db, s.keystore.KeyStore.GetToken())
+	oracleSigner, err := s.newSigner()
+	if err != nil {
+		panic(err)
+	}
+
+	retiringSigner, err := s.retiringSigner()
+	if err != nil {
+		panic(err)
+	}
+
+	vorSigner, err := s.vorSigner()
+	if err != nil {
+		panic(err)
+	}
+
+	signers := chain.Signers{
+		Oracle:   oracleSigner,
+		Retiring: retiringSigner,
+		Vor:      vorSigner,
+	}
+
+	creds, err := credentials.New(s.db, s.logger, s.vaultSecret)
+	if err != nil {
+		panic(err)
+	}
+
+	srv, err := service.NewService(s.ctx, s.logger, signers, s.db, s.keystore.KeyStore.GetToken(),
+		supervisor.New(s.logger, s.reporter), creds)
 	if err != nil {
 		panic(err)
 	}
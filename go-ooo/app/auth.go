@@ -2,17 +2,28 @@ package app
 
 import (
 	"fmt"
+	"go-ooo/config"
+	"golang.org/x/term"
 	"io/ioutil"
 	"os"
 	"strings"
 )
 
 func (s *Server) inputKey() (err error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("no keystore password - use --pass, or set %s, %s or the %s environment variable",
+			config.KeystoragePasswordFile, config.KeystoragePasswordCommand, passwordEnv())
+	}
+
 	fmt.Println("")
 	fmt.Println("Please enter the cli/HTTP key, which was provided to you by Oracle")
 	fmt.Print("Key: ")
-	var key string
-	fmt.Scanf("%s\n", &key)
+	keyBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	fmt.Println("")
+	key := strings.TrimSpace(string(keyBytes))
 	err = s.keystore.CheckToken(key)
 	if err == nil {
 		fmt.Println("Okay, let's continue...")
@@ -22,7 +33,6 @@ func (s *Server) inputKey() (err error) {
 		err = s.inputKey()
 		return
 	}
-	return
 }
 
 func (s *Server) auth() (err error) {
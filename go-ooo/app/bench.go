@@ -0,0 +1,64 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database"
+	"go-ooo/ooo_api"
+	"go-ooo/supervisor"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// BenchSources fetches each pair, e.g. BTC.USD, from every data source, rounds times, waiting
+// interval between rounds, and summarises each source's latency, error rate and price dispersion.
+// The database is only read, for the DEX pairs discovered by the service. progress, if not nil,
+// is called before each pair is fetched
+func (s *Server) BenchSources(pairs []string, rounds int, interval time.Duration, progress func(pair string, round int)) ([]ooo_api.BenchSummary, error) {
+	s.logger.SetOutput(ioutil.Discard)
+
+	for _, pair := range pairs {
+		if _, _, ok := ooo_api.SplitPair(pair); !ok {
+			return nil, fmt.Errorf("pair %s is not of the form BASE.TARGET", pair)
+		}
+	}
+
+	if viper.GetString(config.DatabaseDialect) == "sqlite" {
+		storage := viper.GetString(config.DatabaseStorage)
+		if _, err := os.Stat(storage); errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s does not exist - start the node first, so that DEX pairs are discovered", storage)
+		}
+	}
+
+	db, err := database.NewDb()
+	if err == nil && db == nil {
+		err = errors.New("not configured")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to the database: %s", err.Error())
+	}
+
+	api, err := ooo_api.NewApi(s.ctx, db, s.logger, nil, supervisor.New(s.logger, s.reporter))
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialise data sources: %s", err.Error())
+	}
+
+	var samples []ooo_api.BenchSample
+	for round := 0; round < rounds; round++ {
+		if round > 0 {
+			time.Sleep(interval)
+		}
+		for _, pair := range pairs {
+			base, target, _ := ooo_api.SplitPair(pair)
+			if progress != nil {
+				progress(pair, round)
+			}
+			samples = append(samples, api.BenchSources(base, target, round)...)
+		}
+	}
+
+	return ooo_api.SummariseBench(samples), nil
+}
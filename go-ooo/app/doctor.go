@@ -0,0 +1,398 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/spf13/viper"
+	"go-ooo/cache"
+	"go-ooo/chain"
+	"go-ooo/config"
+	"go-ooo/database"
+	"go-ooo/keystore"
+	"go-ooo/ooo_api"
+	"go-ooo/ooo_router"
+	"go-ooo/signer"
+	"go-ooo/supervisor"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// doctorTimeout - time allowed for each network check
+const doctorTimeout = 15 * time.Second
+
+// Doctor check results
+const (
+	DoctorPass = "PASS"
+	DoctorWarn = "WARN"
+	DoctorFail = "FAIL"
+)
+
+// DoctorCheck is the result of a single startup diagnostic
+type DoctorCheck struct {
+	Name   string
+	Status string
+	Detail string
+}
+
+// doctor holds what earlier checks have set up for later ones
+type doctor struct {
+	s       *Server
+	checks  []DoctorCheck
+	db      *database.DB
+	client  *ethclient.Client
+	address *common.Address
+}
+
+func (d *doctor) pass(name string, format string, a ...interface{}) {
+	d.checks = append(d.checks, DoctorCheck{Name: name, Status: DoctorPass, Detail: fmt.Sprintf(format, a...)})
+}
+
+func (d *doctor) warn(name string, format string, a ...interface{}) {
+	d.checks = append(d.checks, DoctorCheck{Name: name, Status: DoctorWarn, Detail: fmt.Sprintf(format, a...)})
+}
+
+func (d *doctor) fail(name string, format string, a ...interface{}) {
+	d.checks = append(d.checks, DoctorCheck{Name: name, Status: DoctorFail, Detail: fmt.Sprintf(format, a...)})
+}
+
+// Doctor checks that the node is ready to start - its config, database, chain RPC, subgraphs,
+// keystore and wallet - without starting it or changing anything. Checks which depend on one
+// which failed are skipped
+func (s *Server) Doctor() []DoctorCheck {
+	s.logger.SetOutput(ioutil.Discard)
+
+	d := &doctor{s: s}
+
+	if !d.checkConfig() {
+		return d.checks
+	}
+
+	d.checkDatabase()
+	d.checkRpc()
+	d.checkVault()
+	d.checkCache()
+	d.checkSubgraphs()
+	d.checkKeystore()
+	d.checkBalances()
+
+	if d.client != nil {
+		d.client.Close()
+	}
+
+	return d.checks
+}
+
+func (d *doctor) checkConfig() bool {
+	if err := config.ReadInConfig(viper.GetViper(), viper.GetString(config.Profile)); err != nil {
+		d.fail("config", "cannot read %s: %s", viper.ConfigFileUsed(), err.Error())
+		return false
+	}
+
+	problems := ValidateConfig()
+	for _, p := range problems {
+		d.fail("config", "%s", p.Error())
+	}
+	if len(problems) > 0 {
+		return false
+	}
+
+	d.pass("config", "%s", viper.ConfigFileUsed())
+	return true
+}
+
+func (d *doctor) checkDatabase() {
+	if viper.GetString(config.DatabaseDialect) == "sqlite" {
+		storage := viper.GetString(config.DatabaseStorage)
+		if storage == database.MemoryStorage {
+			d.warn("database", "in memory - nothing is kept once the node stops")
+			return
+		}
+		if _, err := os.Stat(storage); errors.Is(err, os.ErrNotExist) {
+			d.warn("database", "%s does not exist, and will be created on start", storage)
+			return
+		}
+	}
+
+	db, err := database.NewDb()
+	if err == nil && db == nil {
+		err = fmt.Errorf("not configured")
+	}
+	if err != nil {
+		d.fail("database", "cannot connect: %s", err.Error())
+		return
+	}
+
+	sqlDb, err := db.DB.DB()
+	if err == nil {
+		err = sqlDb.Ping()
+	}
+	if err != nil {
+		d.fail("database", "cannot connect: %s", err.Error())
+		return
+	}
+
+	d.db = db
+	d.pass("database", "connected (%s)", viper.GetString(config.DatabaseDialect))
+
+	if err = db.CheckSchemaVersion(); err != nil {
+		d.fail("db schema", "%s", err.Error())
+		return
+	}
+
+	version, err := db.GetDbSchemaVersion()
+	if err != nil {
+		d.warn("db schema", "new database - the schema will be created on start")
+		return
+	}
+	d.pass("db schema", "v%d", version)
+}
+
+func (d *doctor) checkRpc() {
+	host := viper.GetString(config.ChainEthWsHost)
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+
+	client, err := ethclient.DialContext(ctx, host)
+	if err != nil {
+		d.fail("rpc", "cannot connect to %s: %s", host, err.Error())
+		return
+	}
+
+	chainId, err := client.ChainID(ctx)
+	if err != nil {
+		client.Close()
+		d.fail("rpc", "cannot query %s: %s", host, err.Error())
+		return
+	}
+
+	d.client = client
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		d.fail("rpc", "cannot get the latest block from %s: %s", host, err.Error())
+		return
+	}
+	d.pass("rpc", "%s at block %d", host, head)
+
+	networkId := viper.GetInt64(config.ChainNetworkId)
+	if chainId.Cmp(big.NewInt(networkId)) != 0 {
+		d.fail("chain id", "node is on chain %s but %s is %d", chainId.String(), config.ChainNetworkId, networkId)
+		return
+	}
+	d.pass("chain id", "%s", chainId.String())
+}
+
+func (d *doctor) checkSubgraphs() {
+	if d.db == nil {
+		d.warn("subgraphs", "skipped - needs the database")
+		return
+	}
+
+	api, err := ooo_api.NewApi(d.s.ctx, d.db, d.s.logger, nil, supervisor.New(d.s.logger, d.s.reporter))
+	if err != nil {
+		d.fail("subgraphs", "cannot initialise data sources: %s", err.Error())
+		return
+	}
+
+	results := api.CheckSubgraphHealth()
+
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		check := fmt.Sprintf("subgraph %s", name)
+		if err := results[name]; err != nil {
+			d.fail(check, "%s", err.Error())
+		} else {
+			d.pass(check, "healthy")
+		}
+	}
+}
+
+func (d *doctor) checkVault() {
+	if viper.GetString(config.VaultAddress) == "" {
+		return
+	}
+
+	if err := d.s.loadVaultSecret(); err != nil {
+		d.fail("vault", "%s", err.Error())
+		return
+	}
+
+	var fields []string
+	if d.s.vaultField(config.VaultPrivateKeyField, "private_key") != "" {
+		fields = append(fields, "private key")
+	}
+	if d.s.vaultField(config.VaultPasswordField, "keystore_password") != "" {
+		fields = append(fields, "keystore password")
+	}
+	if len(fields) == 0 {
+		d.warn("vault", "secret has neither a private key nor a keystore password")
+		return
+	}
+	d.pass("vault", "read %s", strings.Join(fields, " and "))
+}
+
+// checkCache warns, rather than fails, if the shared cache cannot be reached, since the node
+// falls back to its own cache and rate limit counters
+func (d *doctor) checkCache() {
+	shared := cache.Shared()
+	if !shared.Enabled() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	if err := shared.Ping(ctx); err != nil {
+		d.warn("shared cache", "%s", err.Error())
+		return
+	}
+	d.pass("shared cache", "reachable")
+}
+
+func (d *doctor) checkKeystore() {
+	file := viper.GetString(config.KeystorageFile)
+	if _, err := os.Stat(file); err != nil {
+		d.fail("keystore", "cannot read %s: %s", file, err.Error())
+		return
+	}
+
+	ks, err := keystore.NewKeyStorageNoLogger(file)
+	if err != nil {
+		d.fail("keystore", "cannot read %s: %s", file, err.Error())
+		return
+	}
+	defer ks.File.Close()
+
+	backend := signerBackend()
+	if backend == signer.BackendKeystore && !ks.Exists() {
+		d.fail("keystore", "%s has no keys - run 'go-ooo init'", file)
+		return
+	}
+
+	password, err := d.s.keystorePassword()
+	if err != nil {
+		d.fail("keystore", "%s", err.Error())
+		return
+	}
+	if password == "" {
+		d.fail("keystore", "no password given")
+		return
+	}
+	if err = ks.CheckToken(password); err != nil {
+		d.fail("keystore", "cannot unlock %s: incorrect password", file)
+		return
+	}
+
+	if backend != signer.BackendKeystore {
+		d.pass("keystore", "unlocked")
+		d.checkSigner(ks)
+		return
+	}
+
+	account, _ := oracleAccount()
+	if err = ks.SelectPrivateKey(account); err != nil {
+		d.warn("keystore", "account %q not found, the first key will be used", account)
+	}
+
+	oracleSigner, err := d.s.signerFor(ks)
+	if err != nil {
+		d.fail("keystore", "cannot decrypt the private key: %s", err.Error())
+		return
+	}
+
+	address := oracleSigner.Address()
+	d.address = &address
+	d.pass("keystore", "unlocked, oracle address %s", address.Hex())
+
+	if account = vorAccount(); account != "" {
+		if _, err = ks.GetByAccount(account); err != nil {
+			d.fail("vor key", "account %q not found", account)
+		} else {
+			d.pass("vor key", "account %q", account)
+		}
+	}
+}
+
+// checkSigner checks a remote signer backend can be reached, and its key used
+func (d *doctor) checkSigner(ks *keystore.Keystorage) {
+	oracleSigner, err := d.s.signerFor(ks)
+	if err != nil {
+		d.fail("signer", "%s: %s", signerBackend(), err.Error())
+		return
+	}
+
+	// signing with a ledger needs confirming on the device, so connecting to it is enough
+	if ledger, ok := oracleSigner.(*signer.LedgerSigner); ok {
+		ledger.Close()
+		address := oracleSigner.Address()
+		d.address = &address
+		d.pass("signer", "%s connected, oracle address %s", signerBackend(), address.Hex())
+		return
+	}
+
+	if _, err = oracleSigner.SignText([]byte("go-ooo doctor")); err != nil {
+		d.fail("signer", "%s: cannot sign: %s", signerBackend(), err.Error())
+		return
+	}
+
+	address := oracleSigner.Address()
+	d.address = &address
+	d.pass("signer", "%s, oracle address %s", signerBackend(), address.Hex())
+}
+
+func (d *doctor) checkBalances() {
+	if d.client == nil || d.address == nil {
+		d.warn("balances", "skipped - needs the rpc and keystore")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+
+	eth, err := d.client.BalanceAt(ctx, *d.address, nil)
+	switch {
+	case err != nil:
+		d.fail("eth balance", "cannot query: %s", err.Error())
+	case eth.Sign() == 0:
+		d.fail("eth balance", "0 ETH - fulfilment transactions cannot be paid for")
+	default:
+		d.pass("eth balance", "%s ETH", toUnits(eth, params.Ether))
+	}
+
+	contractAddress := common.HexToAddress(viper.GetString(config.ChainContractAddress))
+	router, err := ooo_router.NewOooRouter(contractAddress, d.client)
+	if err != nil {
+		d.fail("xfund balance", "cannot bind router %s: %s", contractAddress.Hex(), err.Error())
+		return
+	}
+
+	xfund, err := chain.XfundBalanceOf(&bind.CallOpts{From: *d.address, Context: ctx}, d.client, router, *d.address)
+	if err != nil {
+		d.fail("xfund balance", "cannot query: %s", err.Error())
+		return
+	}
+	if xfund.Sign() == 0 {
+		d.warn("xfund balance", "0 xFUND")
+		return
+	}
+	d.pass("xfund balance", "%s xFUND", toUnits(xfund, params.GWei))
+}
+
+// toUnits converts an amount in a token's smallest unit to whole tokens
+func toUnits(amount *big.Int, unit float64) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(amount), big.NewFloat(unit)).Text('f', 6)
+}
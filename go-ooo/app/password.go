@@ -0,0 +1,82 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// passwordCommandTimeout - how long KeystoragePasswordCommand may run
+const passwordCommandTimeout = 30 * time.Second
+
+// keystorePassword returns the keystore password from the first of: --pass, the password
+// command, the password file, the password environment variable, or Vault. "" is returned if no
+// source is configured, and the password is then prompted for
+func (s *Server) keystorePassword() (string, error) {
+	if s.decryptPass != "" {
+		return getPasswordFromFileOrFlag(s.decryptPass), nil
+	}
+
+	if command := viper.GetString(config.KeystoragePasswordCommand); command != "" {
+		return passwordFromCommand(command)
+	}
+
+	if file := viper.GetString(config.KeystoragePasswordFile); file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("cannot read %s: %s", config.KeystoragePasswordFile, err.Error())
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	if password := os.Getenv(passwordEnv()); password != "" {
+		return password, nil
+	}
+
+	return s.vaultField(config.VaultPasswordField, "keystore_password"), nil
+}
+
+// HasKeystorePasswordSource returns true if the keystore password can be read without --pass or
+// a prompt
+func HasKeystorePasswordSource() bool {
+	return viper.GetString(config.KeystoragePasswordCommand) != "" ||
+		viper.GetString(config.KeystoragePasswordFile) != "" ||
+		os.Getenv(passwordEnv()) != "" ||
+		viper.GetString(config.VaultAddress) != ""
+}
+
+func passwordEnv() string {
+	env := viper.GetString(config.KeystoragePasswordEnv)
+	if env == "" {
+		return "GO_OOO_KEYSTORE_PASSWORD"
+	}
+	return env
+}
+
+// passwordFromCommand runs command, returning its trimmed output as the password
+func passwordFromCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), passwordCommandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s failed: %s: %s", config.KeystoragePasswordCommand, err.Error(), strings.TrimSpace(stderr.String()))
+	}
+
+	password := strings.TrimSpace(stdout.String())
+	if password == "" {
+		return "", fmt.Errorf("%s printed no password", config.KeystoragePasswordCommand)
+	}
+	return password, nil
+}
@@ -0,0 +1,374 @@
+package app
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database"
+	"go-ooo/keystore"
+	"go-ooo/ooo_router"
+	"go-ooo/signer"
+	"math/big"
+	"time"
+)
+
+// rotationTxTimeout - how long to wait for a rotation tx to be mined
+const rotationTxTimeout = 5 * time.Minute
+
+// KeyRotation describes a key rotation started by RotateKey
+type KeyRotation struct {
+	OldAccount string
+	OldAddress common.Address
+	NewAccount string
+	NewAddress common.Address
+	Until      time.Time
+	// RegisterTx is the tx registering the new key as a provider, if it was sent
+	RegisterTx string
+}
+
+// KeyRetirement describes a retirement completed by RetireKey
+type KeyRetirement struct {
+	Account string
+	Address common.Address
+	// WithdrawTx is the tx withdrawing the retired key's fees, if any were withdrawn
+	WithdrawTx string
+}
+
+// RotateKey adds a new key to the keystore as newAccount - privateKey, or a generated key if it
+// is empty - and registers it as a provider with the current key's fee. If newAccount already
+// exists, e.g. because registration failed as it had no funds for gas, its key is reused. The
+// config is then switched to the new key, with the current key retiring after grace. The change
+// takes effect when the node is restarted, when requests already addressed to the current key
+// continue to be fulfilled with it
+func (s *Server) RotateKey(newAccount string, privateKey string, grace time.Duration, register bool) (*KeyRotation, error) {
+	if signerBackend() != signer.BackendKeystore {
+		return nil, fmt.Errorf("key rotation is only supported with the %s signer backend", signer.BackendKeystore)
+	}
+	if retiring := viper.GetString(config.KeystorageRetiringAccount); retiring != "" {
+		return nil, fmt.Errorf("account %s is already being retired - run 'go-ooo keys retire' first", retiring)
+	}
+
+	ks, err := s.openKeystore()
+	if err != nil {
+		return nil, err
+	}
+	defer ks.File.Close()
+
+	oldAccount, accountKey := oracleAccount()
+	oldKey, err := ks.GetByAccount(oldAccount)
+	if err != nil {
+		return nil, fmt.Errorf("current account %s not found in the keystore", oldAccount)
+	}
+	if newAccount == oldAccount {
+		return nil, fmt.Errorf("account %s is the current account", newAccount)
+	}
+
+	oldSigner, err := signer.NewLocalSigner(oldKey.GetPrivate())
+	if err != nil {
+		return nil, err
+	}
+
+	exists := ks.ExistsByUsername(newAccount)
+	switch {
+	case exists && privateKey != "":
+		return nil, fmt.Errorf("account %s already exists", newAccount)
+	case exists:
+		existing, err := ks.GetByAccount(newAccount)
+		if err != nil {
+			return nil, err
+		}
+		privateKey = existing.GetPrivate()
+	case privateKey == "":
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		privateKey = hex.EncodeToString(crypto.FromECDSA(key))
+	}
+	newSigner, err := signer.NewLocalSigner(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %s", err.Error())
+	}
+
+	rotation := &KeyRotation{
+		OldAccount: oldAccount,
+		OldAddress: oldSigner.Address(),
+		NewAccount: newAccount,
+		NewAddress: newSigner.Address(),
+		Until:      time.Now().Add(grace),
+	}
+	if rotation.NewAddress == rotation.OldAddress {
+		return nil, errors.New("the new key is the current key")
+	}
+
+	if !exists {
+		if err = ks.AddExisting(newAccount, privateKey); err != nil {
+			return nil, err
+		}
+	}
+
+	if register {
+		if rotation.RegisterTx, err = registerRotatedKey(rotation.OldAddress, newSigner); err != nil {
+			return rotation, fmt.Errorf("key %s (%s) added, but not registered: %s", newAccount,
+				rotation.NewAddress.Hex(), err.Error())
+		}
+	}
+
+	err = updateConfigFile(map[string]interface{}{
+		accountKey:                       newAccount,
+		config.KeystorageRetiringAccount: oldAccount,
+		config.KeystorageRetiringUntil:   rotation.Until.Unix(),
+	})
+	if err != nil {
+		return rotation, fmt.Errorf("key %s added, but the config could not be updated: %s", newAccount, err.Error())
+	}
+
+	return rotation, nil
+}
+
+// RetireKey ends a key rotation, so the retiring key is no longer used once the node is
+// restarted. Unless force is set, the grace window must have ended and no requests to the key
+// may be pending. If withdrawTo is set, the key's fees are withdrawn to it. The key is kept in
+// the keystore
+func (s *Server) RetireKey(withdrawTo string, force bool) (*KeyRetirement, error) {
+	account := viper.GetString(config.KeystorageRetiringAccount)
+	if account == "" {
+		return nil, errors.New("no key rotation in progress")
+	}
+	if withdrawTo != "" && !common.IsHexAddress(withdrawTo) {
+		return nil, fmt.Errorf("%s is not a valid address", withdrawTo)
+	}
+
+	ks, err := s.openKeystore()
+	if err != nil {
+		return nil, err
+	}
+	defer ks.File.Close()
+
+	key, err := ks.GetByAccount(account)
+	if err != nil {
+		return nil, fmt.Errorf("retiring account %s not found in the keystore", account)
+	}
+	oldSigner, err := signer.NewLocalSigner(key.GetPrivate())
+	if err != nil {
+		return nil, err
+	}
+
+	retirement := &KeyRetirement{Account: account, Address: oldSigner.Address()}
+
+	if !force {
+		until := time.Unix(viper.GetInt64(config.KeystorageRetiringUntil), 0)
+		if time.Now().Before(until) {
+			return nil, fmt.Errorf("the grace window ends at %s - use --force to retire the key early",
+				until.UTC().Format(time.RFC3339))
+		}
+
+		db, err := database.NewDb()
+		if err != nil {
+			return nil, err
+		}
+		pending, err := db.CountPendingJobsForProvider(retirement.Address.Hex())
+		if err != nil {
+			return nil, err
+		}
+		if pending > 0 {
+			return nil, fmt.Errorf("%d requests to %s are still pending - wait for them to be fulfilled, or use --force",
+				pending, retirement.Address.Hex())
+		}
+	}
+
+	if withdrawTo != "" {
+		if retirement.WithdrawTx, err = withdrawRetiredKeyFees(oldSigner, common.HexToAddress(withdrawTo)); err != nil {
+			return nil, err
+		}
+	}
+
+	err = updateConfigFile(map[string]interface{}{
+		config.KeystorageRetiringAccount: "",
+		config.KeystorageRetiringUntil:   0,
+	})
+	if err != nil {
+		return retirement, err
+	}
+
+	return retirement, nil
+}
+
+// updateConfigFile sets values in the config file, in the selected profile if it sets them. The
+// file is re-read without environment variable overrides, so that they are not written to it
+func updateConfigFile(values map[string]interface{}) error {
+	fileConfig := viper.New()
+	fileConfig.SetConfigFile(viper.ConfigFileUsed())
+	if err := fileConfig.ReadInConfig(); err != nil {
+		return err
+	}
+
+	for k, v := range values {
+		fileConfig.Set(config.SourceKey(k), v)
+		viper.Set(k, v)
+	}
+
+	return fileConfig.WriteConfig()
+}
+
+// openKeystore opens and unlocks the keystore, without the running node's logger
+func (s *Server) openKeystore() (*keystore.Keystorage, error) {
+	if viper.GetString(config.VaultAddress) != "" {
+		if err := s.loadVaultSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	ks, err := keystore.NewKeyStorageNoLogger(viper.GetString(config.KeystorageFile))
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := s.keystorePassword()
+	if err == nil && ks.CheckToken(password) != nil {
+		err = errors.New("cannot unlock the keystore: incorrect password")
+	}
+	if err != nil {
+		ks.File.Close()
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// registerRotatedKey registers the new key as a provider with the old key's fee
+func registerRotatedKey(oldAddress common.Address, newSigner signer.Signer) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), rotationTxTimeout)
+	defer cancel()
+
+	client, router, err := dialRouter(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	callOpts := &bind.CallOpts{Context: ctx}
+	if registered, err := router.GetProviderMinFee(callOpts, newSigner.Address()); err == nil && registered.Sign() > 0 {
+		// registered by a previous run
+		return "", nil
+	}
+
+	fee, err := router.GetProviderMinFee(callOpts, oldAddress)
+	if err != nil {
+		return "", err
+	}
+	if fee.Sign() == 0 {
+		return "", fmt.Errorf("%s is not a registered provider", oldAddress.Hex())
+	}
+
+	balance, err := client.BalanceAt(ctx, newSigner.Address(), nil)
+	if err != nil {
+		return "", err
+	}
+	if balance.Sign() == 0 {
+		return "", fmt.Errorf("it has no funds for gas - fund it and run again, or use --no-register and "+
+			"run 'go-ooo admin register %s' once the node has restarted", fee.String())
+	}
+
+	opts, err := signer.NewTransactOpts(newSigner, big.NewInt(viper.GetInt64(config.ChainNetworkId)))
+	if err != nil {
+		return "", err
+	}
+	opts.Context = ctx
+
+	tx, err := router.RegisterAsProvider(opts, fee)
+	if err != nil {
+		return "", fmt.Errorf("cannot register %s: %s", newSigner.Address().Hex(), err.Error())
+	}
+
+	return tx.Hash().Hex(), waitForRotationTx(ctx, client, tx)
+}
+
+// withdrawRetiredKeyFees withdraws all of the retiring key's fees to recipient
+func withdrawRetiredKeyFees(oldSigner signer.Signer, recipient common.Address) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), rotationTxTimeout)
+	defer cancel()
+
+	client, router, err := dialRouter(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	available, err := router.GetWithdrawableTokens(&bind.CallOpts{Context: ctx}, oldSigner.Address())
+	if err != nil {
+		return "", err
+	}
+	if available.Sign() == 0 {
+		return "", nil
+	}
+
+	opts, err := signer.NewTransactOpts(oldSigner, big.NewInt(viper.GetInt64(config.ChainNetworkId)))
+	if err != nil {
+		return "", err
+	}
+	opts.Context = ctx
+
+	tx, err := router.Withdraw(opts, recipient, available)
+	if err != nil {
+		return "", fmt.Errorf("cannot withdraw fees: %s", err.Error())
+	}
+
+	return tx.Hash().Hex(), waitForRotationTx(ctx, client, tx)
+}
+
+func dialRouter(ctx context.Context) (*ethclient.Client, *ooo_router.OooRouter, error) {
+	client, err := ethclient.DialContext(ctx, viper.GetString(config.ChainEthWsHost))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	router, err := ooo_router.NewOooRouter(common.HexToAddress(viper.GetString(config.ChainContractAddress)), client)
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+
+	return client, router, nil
+}
+
+func waitForRotationTx(ctx context.Context, client *ethclient.Client, tx *types.Transaction) error {
+	receipt, err := bind.WaitMined(ctx, client, tx)
+	if err != nil {
+		return fmt.Errorf("tx %s not mined: %s", tx.Hash().Hex(), err.Error())
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("tx %s failed", tx.Hash().Hex())
+	}
+	return nil
+}
+
+// ProviderKey returns the oracle's private key from the keystore, e.g. so that a devnet can
+// register it as a provider
+func (s *Server) ProviderKey() (string, error) {
+	if signerBackend() != signer.BackendKeystore {
+		return "", fmt.Errorf("the provider key can only be read with the %s signer backend", signer.BackendKeystore)
+	}
+
+	ks, err := s.openKeystore()
+	if err != nil {
+		return "", err
+	}
+	defer ks.File.Close()
+
+	account, _ := oracleAccount()
+	key, err := ks.GetByAccount(account)
+	if err != nil {
+		return "", fmt.Errorf("account %s not found in the keystore", account)
+	}
+
+	return key.GetPrivate(), nil
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/keystore"
+	"go-ooo/signer"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// signerBackend returns the configured signer backend, defaulting to the keystore
+func signerBackend() string {
+	backend := strings.ToLower(viper.GetString(config.SignerBackend))
+	if backend == "" {
+		return signer.BackendKeystore
+	}
+	return backend
+}
+
+// newSigner returns the signer for the configured backend. The keystore backend signs with the
+// key selected from the unlocked keystore, and the vault backend with the key read from Vault
+func (s *Server) newSigner() (signer.Signer, error) {
+	oracleSigner, err := s.signerFor(s.keystore)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "main",
+		"function": "newSigner",
+		"backend":  signerBackend(),
+		"address":  oracleSigner.Address().Hex(),
+	}).Info("initialised signer")
+
+	return oracleSigner, nil
+}
+
+// oracleAccount returns the keystore account for the configured router and chain, and the config
+// key assigning it - a KeystorageAccounts entry for the router address or network id, or
+// KeystorageAccount
+func oracleAccount() (string, string) {
+	accounts := viper.GetStringMapString(config.KeystorageAccounts)
+	for _, k := range []string{viper.GetString(config.ChainContractAddress), viper.GetString(config.ChainNetworkId)} {
+		// viper lower cases map keys
+		k = strings.ToLower(k)
+		if account := accounts[k]; k != "" && account != "" {
+			return account, config.KeystorageAccounts + "." + k
+		}
+	}
+	return viper.GetString(config.KeystorageAccount), config.KeystorageAccount
+}
+
+// vorAccount returns the keystore account assigned to the configured VOR coordinator, or an
+// empty string if VOR uses the oracle key
+func vorAccount() string {
+	vorAddress := strings.ToLower(viper.GetString(config.ChainVorCoordinatorAddress))
+	if vorAddress == "" {
+		return ""
+	}
+	return viper.GetStringMapString(config.KeystorageAccounts)[vorAddress]
+}
+
+// keystoreSigner returns a signer for account, which must be in the unlocked keystore. Only the
+// keystore backend holds more than the oracle key, so other backends cannot be used with it
+func (s *Server) keystoreSigner(account string, configKey string) (signer.Signer, error) {
+	if signerBackend() != signer.BackendKeystore {
+		return nil, fmt.Errorf("%s is only supported with the %s signer backend", configKey, signer.BackendKeystore)
+	}
+
+	key, err := s.keystore.GetByAccount(account)
+	if err != nil {
+		return nil, fmt.Errorf("%s account %s not found in the keystore", configKey, account)
+	}
+	return signer.NewLocalSigner(key.GetPrivate())
+}
+
+// vorSigner returns the signer for the key assigned to the VOR coordinator, or nil if VOR uses
+// the oracle key
+func (s *Server) vorSigner() (signer.Signer, error) {
+	account := vorAccount()
+	if account == "" {
+		return nil, nil
+	}
+	return s.keystoreSigner(account, config.KeystorageAccounts)
+}
+
+// retiringSigner returns the signer for the previous key while a key rotation is in progress,
+// or nil
+func (s *Server) retiringSigner() (signer.Signer, error) {
+	account := viper.GetString(config.KeystorageRetiringAccount)
+	if account == "" {
+		return nil, nil
+	}
+	return s.keystoreSigner(account, config.KeystorageRetiringAccount)
+}
+
+func (s *Server) signerFor(ks *keystore.Keystorage) (signer.Signer, error) {
+	switch signerBackend() {
+	case signer.BackendKeystore:
+		return signer.NewLocalSigner(ks.GetSelectedPrivateKey())
+	case signer.BackendVault:
+		key, err := s.vaultPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		return signer.NewLocalSigner(key)
+	case signer.BackendAwsKms:
+		return signer.NewKmsSigner(s.ctx, signer.KmsConfig{
+			KeyId:           viper.GetString(config.SignerAwsKmsKeyId),
+			Region:          viper.GetString(config.SignerAwsRegion),
+			Endpoint:        viper.GetString(config.SignerAwsKmsEndpoint),
+			AccessKeyId:     viper.GetString(config.SignerAwsAccessKeyId),
+			SecretAccessKey: viper.GetString(config.SignerAwsSecretAccessKey),
+			SessionToken:    viper.GetString(config.SignerAwsSessionToken),
+		})
+	case signer.BackendGcpKms:
+		return signer.NewGcpKmsSigner(s.ctx, signer.GcpKmsConfig{
+			KeyVersion:      viper.GetString(config.SignerGcpKmsKeyVersion),
+			CredentialsFile: viper.GetString(config.SignerGcpCredentialsFile),
+			Endpoint:        viper.GetString(config.SignerGcpKmsEndpoint),
+		})
+	case signer.BackendLedger:
+		return signer.NewLedgerSigner(signer.LedgerConfig{
+			Path:                viper.GetString(config.SignerLedgerPath),
+			AutoApproveGasPrice: new(big.Int).Mul(big.NewInt(viper.GetInt64(config.SignerLedgerAutoApproveGasPrice)), big.NewInt(params.GWei)),
+			ApprovalTimeout:     time.Duration(viper.GetInt64(config.SignerLedgerApprovalTimeout)) * time.Second,
+			Logger:              s.logger,
+		})
+	case signer.BackendPkcs11:
+		return nil, fmt.Errorf("%s %s is not supported - use %s with a Cloud HSM key, or %s with a CloudHSM-backed key",
+			config.SignerBackend, signer.BackendPkcs11, signer.BackendGcpKms, signer.BackendAwsKms)
+	default:
+		return nil, fmt.Errorf("unknown %s %q - must be %s, %s, %s, %s or %s", config.SignerBackend,
+			viper.GetString(config.SignerBackend), signer.BackendKeystore, signer.BackendVault,
+			signer.BackendAwsKms, signer.BackendGcpKms, signer.BackendLedger)
+	}
+}
@@ -0,0 +1,792 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/alerts"
+	"go-ooo/cache"
+	"go-ooo/chain"
+	"go-ooo/config"
+	"go-ooo/database"
+	"go-ooo/ooo_api"
+	"go-ooo/schedule"
+	"go-ooo/service"
+	"go-ooo/signer"
+	"go-ooo/utils"
+	"go-ooo/webhooks"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConfigError is a problem with the value of a config key
+type ConfigError struct {
+	Key     string
+	Message string
+}
+
+func (e ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key, e.Message)
+}
+
+// subchainIds - the chain each subchain RPC must be on
+var subchainIds = map[string]int64{
+	config.SubChainEthHttpRpc:     1,
+	config.SubChainPolygonHttpRpc: 137,
+	config.SubChainBcsHttpRpc:     56,
+	config.SubChainXdaiHttpRpc:    100,
+}
+
+var alertTypes = []string{alerts.AlertLowBalance, alerts.AlertFulfillmentFailed, alerts.AlertRpcDown,
+	alerts.AlertSubgraphUnhealthy, alerts.AlertGasBudgetExceeded, alerts.AlertOutdatedVersion, alerts.AlertPeerDeviation,
+	alerts.AlertPairHeartbeat, alerts.AlertFulfillmentQuiesced, alerts.AlertFeeSchedule, alerts.AlertClockSkew,
+	alerts.AlertCredentialRotation, alerts.AlertCredentialQuota, alerts.AlertReconciliation, alerts.AlertMaintenance,
+	alerts.AlertPriceBound, alerts.AlertPairDelisted, alerts.AlertStateSnapshot}
+
+var alertSinks = []string{alerts.SinkTelegram, alerts.SinkSlack, alerts.SinkWebhook, alerts.SinkPagerDuty,
+	alerts.SinkEmail}
+
+var alertSeverities = []string{alerts.SeverityInfo, alerts.SeverityWarning, alerts.SeverityError,
+	alerts.SeverityCritical}
+
+var webhookEvents = []string{webhooks.EventReceived, webhooks.EventFulfilled, webhooks.EventFailed,
+	webhooks.EventSkipped}
+
+// configValidator collects the problems found by ValidateConfig
+type configValidator struct {
+	errs []ConfigError
+}
+
+func (v *configValidator) fail(key string, format string, a ...interface{}) {
+	v.errs = append(v.errs, ConfigError{Key: config.SourceKey(key), Message: fmt.Sprintf(format, a...)})
+}
+
+func (v *configValidator) required(key string) bool {
+	if viper.GetString(key) == "" {
+		v.fail(key, "is not set")
+		return false
+	}
+	return true
+}
+
+// address checks key is a hex address, if it is set
+func (v *configValidator) address(key string) {
+	if value := viper.GetString(key); value != "" && !common.IsHexAddress(value) {
+		v.fail(key, "%q is not a valid address", value)
+	}
+}
+
+// url checks key is an absolute url with one of schemes, if it is set
+func (v *configValidator) url(key string, value string, schemes ...string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		v.fail(key, "%q is not a valid url: %s", value, err.Error())
+		return
+	}
+	if !inList(strings.ToLower(u.Scheme), schemes) || u.Host == "" {
+		v.fail(key, "%q must be a %s url", value, joinOr(schemes))
+	}
+}
+
+// oneOf checks key is one of values, case insensitively. An unset key is allowed if the node has a
+// default for it
+func (v *configValidator) oneOf(key string, allowEmpty bool, values ...string) {
+	value := strings.ToLower(viper.GetString(key))
+	if value == "" && allowEmpty {
+		return
+	}
+	if !inList(value, values) {
+		v.fail(key, "%q must be %s", viper.GetString(key), joinOr(values))
+	}
+}
+
+func (v *configValidator) port(key string) {
+	if port := viper.GetInt(key); port < 0 || port > 65535 {
+		v.fail(key, "%d is not a valid port", port)
+	}
+}
+
+// ValidateConfig checks the loaded config without connecting to anything - that required keys are
+// set, and addresses, urls and enumerated values are valid. Problems are returned in the order
+// of the config file's sections
+func ValidateConfig() []ConfigError {
+	v := &configValidator{}
+
+	v.validateChain()
+	v.validateKeystore()
+	v.validateSigner()
+	v.validateDatabase()
+	v.validateJobs()
+	v.validateAlerts()
+	v.validateWebhooks()
+	v.validateIpfs()
+	v.validateCache()
+	v.validatePushFeeds()
+	v.validateFeeSchedule()
+	v.validateConsensus()
+	v.validateServices()
+	v.validateFeatures()
+
+	return v.errs
+}
+
+func (v *configValidator) validateChain() {
+	if v.required(config.ChainContractAddress) {
+		v.address(config.ChainContractAddress)
+	}
+	v.address(config.ChainVorCoordinatorAddress)
+	for i, s := range viper.GetStringSlice(config.ChainXfundSpenders) {
+		if !common.IsHexAddress(s) {
+			v.fail(fmt.Sprintf("%s[%d]", config.ChainXfundSpenders, i), "%q is not a valid address", s)
+		}
+	}
+	if _, err := chain.LoadEventFilters(); err != nil {
+		v.fail(config.ChainEventFilters, "%s", err.Error())
+	}
+
+	if v.required(config.ChainEthWsHost) {
+		host := viper.GetString(config.ChainEthWsHost)
+		// an IPC endpoint is a path
+		if !filepath.IsAbs(host) {
+			v.url(config.ChainEthWsHost, host, "ws", "wss", "http", "https")
+		}
+	}
+	v.url(config.ChainEthHttpHost, viper.GetString(config.ChainEthHttpHost), "http", "https")
+
+	if viper.GetString(config.ChainNetworkId) == "" {
+		v.fail(config.ChainNetworkId, "is not set")
+	} else if viper.GetInt64(config.ChainNetworkId) <= 0 {
+		v.fail(config.ChainNetworkId, "%q must be a positive chain id", viper.GetString(config.ChainNetworkId))
+	}
+
+	for _, key := range sortedKeys(subchainIds) {
+		v.url(key, viper.GetString(key), "http", "https", "ws", "wss")
+	}
+
+	v.oneOf(config.ChainGasLimitAction, true, chain.GasLimitActionSkip, chain.GasLimitActionDefer)
+
+	v.oneOf(config.ChainCatchupMode, true, chain.CatchupModeAll, chain.CatchupModeRecent, chain.CatchupModeHead)
+
+	if viper.IsSet(config.ChainCatchupMaxAge) && viper.GetInt64(config.ChainCatchupMaxAge) <= 0 {
+		v.fail(config.ChainCatchupMaxAge, "%d must be > 0", viper.GetInt64(config.ChainCatchupMaxAge))
+	}
+
+	if viper.GetFloat64(config.ChainGasLimitBuffer) < 0 {
+		v.fail(config.ChainGasLimitBuffer, "%g must not be negative", viper.GetFloat64(config.ChainGasLimitBuffer))
+	}
+
+	if viper.IsSet(config.ChainGasLimitCeiling) && viper.GetInt64(config.ChainGasLimitCeiling) <= 0 {
+		v.fail(config.ChainGasLimitCeiling, "%d must be > 0", viper.GetInt64(config.ChainGasLimitCeiling))
+	}
+}
+
+func (v *configValidator) validateKeystore() {
+	v.required(config.KeystorageFile)
+
+	if signerBackend() == signer.BackendKeystore {
+		if account, key := oracleAccount(); account == "" {
+			v.fail(key, "is not set")
+		}
+	}
+
+	accounts := viper.GetStringMapString(config.KeystorageAccounts)
+	for _, k := range sortedKeys(accounts) {
+		key := config.KeystorageAccounts + "." + k
+		if _, err := strconv.ParseInt(k, 10, 64); err != nil && !common.IsHexAddress(k) {
+			v.fail(key, "must be a network id or a contract address")
+		}
+		if accounts[k] == "" {
+			v.fail(key, "account is empty")
+		}
+	}
+
+	if viper.GetString(config.KeystorageRetiringAccount) != "" && viper.GetInt64(config.KeystorageRetiringUntil) <= 0 {
+		v.fail(config.KeystorageRetiringUntil, "must be set while %s is set", config.KeystorageRetiringAccount)
+	}
+
+	if file := viper.GetString(config.KeystoragePasswordFile); file != "" {
+		if _, err := os.Stat(file); err != nil {
+			v.fail(config.KeystoragePasswordFile, "cannot read %s: %s", file, err.Error())
+		}
+	}
+}
+
+func (v *configValidator) validateSigner() {
+	backend := signerBackend()
+	switch backend {
+	case signer.BackendKeystore, signer.BackendLedger:
+	case signer.BackendVault:
+		v.required(config.VaultAddress)
+	case signer.BackendAwsKms:
+		v.required(config.SignerAwsKmsKeyId)
+		v.required(config.SignerAwsRegion)
+	case signer.BackendGcpKms:
+		v.required(config.SignerGcpKmsKeyVersion)
+	case signer.BackendPkcs11:
+		v.fail(config.SignerBackend, "%s is not supported - use %s with a Cloud HSM key, or %s with a CloudHSM-backed key",
+			backend, signer.BackendGcpKms, signer.BackendAwsKms)
+		return
+	default:
+		v.fail(config.SignerBackend, "%q must be %s", backend, joinOr([]string{signer.BackendKeystore,
+			signer.BackendVault, signer.BackendAwsKms, signer.BackendGcpKms, signer.BackendLedger}))
+		return
+	}
+
+	remoteSigner := backend == signer.BackendAwsKms || backend == signer.BackendGcpKms || backend == signer.BackendLedger
+	if remoteSigner && viper.GetString(config.ChainVorCoordinatorAddress) != "" {
+		v.fail(config.ChainVorCoordinatorAddress, "VOR is not available with the %s signer backend", backend)
+	}
+	if vorAccount() != "" && backend != signer.BackendKeystore {
+		v.fail(config.KeystorageAccounts, "a VOR account needs the %s signer backend", signer.BackendKeystore)
+	}
+
+	v.url(config.VaultAddress, viper.GetString(config.VaultAddress), "http", "https")
+}
+
+func (v *configValidator) validateDatabase() {
+	switch viper.GetString(config.DatabaseDialect) {
+	case "sqlite":
+		v.required(config.DatabaseStorage)
+	case "postgres":
+		v.required(config.DatabaseHost)
+		if viper.GetInt(config.DatabasePort) == 0 {
+			v.fail(config.DatabasePort, "is not set")
+		}
+		v.port(config.DatabasePort)
+	default:
+		v.fail(config.DatabaseDialect, "%q must be sqlite or postgres", viper.GetString(config.DatabaseDialect))
+	}
+
+	if viper.GetBool(config.HaEnabled) && viper.GetString(config.DatabaseDialect) != "postgres" {
+		v.fail(config.HaEnabled, "leader election requires the postgres database dialect")
+	}
+
+	if key := viper.GetString(config.DatabaseEncryptionKey); key != "" {
+		if _, err := database.DecodeEncryptionKey(key); err != nil {
+			v.fail(config.DatabaseEncryptionKey, "%s", err.Error())
+		}
+	}
+	for _, key := range viper.GetStringSlice(config.DatabaseEncryptionPreviousKeys) {
+		if _, err := database.DecodeEncryptionKey(key); err != nil {
+			v.fail(config.DatabaseEncryptionPreviousKeys, "%s", err.Error())
+		}
+	}
+
+	if h := viper.GetInt(config.DatabaseMaintenanceHour); h < -1 || h > 23 {
+		v.fail(config.DatabaseMaintenanceHour, "%d must be 0 - 23, or -1 to disable", h)
+	}
+	tables := database.TableNames()
+	for _, table := range viper.GetStringSlice(config.DatabaseMaintenanceTables) {
+		if !inList(table, tables) {
+			v.fail(config.DatabaseMaintenanceTables, "%q must be one of %s", table, joinOr(tables))
+		}
+	}
+}
+
+func (v *configValidator) validateJobs() {
+	v.url(config.JobsOooApiUrl, viper.GetString(config.JobsOooApiUrl), "http", "https")
+	v.url(config.JobsOooApiUrlSecondary, viper.GetString(config.JobsOooApiUrlSecondary), "http", "https")
+	v.url(config.JobsForexApiUrl, viper.GetString(config.JobsForexApiUrl), "http", "https")
+
+	if viper.GetUint(config.JobsAnswerDecimals) > utils.MaxUint256Decimals {
+		v.fail(config.JobsAnswerDecimals, "%d must be <= %d", viper.GetUint(config.JobsAnswerDecimals),
+			utils.MaxUint256Decimals)
+	}
+
+	v.oneOf(config.JobsConsumerRateLimitAction, true, chain.RateLimitActionDefer, chain.RateLimitActionSkip)
+	v.oneOf(config.JobsProfitabilityAction, true, chain.ProfitabilityActionDefer, chain.ProfitabilityActionSkip)
+	v.oneOf(config.JobsAnswerRounding, true, utils.RoundingModes...)
+	v.oneOf(config.JobsStablecoinRates, true, ooo_api.StablecoinRatesMarket, ooo_api.StablecoinRatesPar)
+
+	if viper.GetFloat64(config.JobsStablecoinMaxDepeg) < 0 {
+		v.fail(config.JobsStablecoinMaxDepeg, "%g must not be negative", viper.GetFloat64(config.JobsStablecoinMaxDepeg))
+	}
+
+	for consumer, policy := range viper.GetStringMapString(config.JobsConsumerSourcePolicies) {
+		key := fmt.Sprintf("%s.%s", config.JobsConsumerSourcePolicies, consumer)
+		if !common.IsHexAddress(consumer) {
+			v.fail(key, "%q is not a valid consumer address", consumer)
+		}
+		if !inList(strings.ToLower(policy), ooo_api.SourcePolicies) {
+			v.fail(key, "%q must be %s", policy, joinOr(ooo_api.SourcePolicies))
+		}
+	}
+
+	tiered := make(map[string]string)
+	tiers := viper.GetStringMapStringSlice(config.JobsSourceTiers)
+	for _, tier := range sortedKeys(tiers) {
+		key := fmt.Sprintf("%s.%s", config.JobsSourceTiers, tier)
+		if !inList(strings.ToLower(tier), ooo_api.SourceTiers) {
+			v.fail(key, "tier must be %s", joinOr(ooo_api.SourceTiers))
+		}
+		for _, source := range tiers[tier] {
+			if other, ok := tiered[strings.ToLower(source)]; ok {
+				v.fail(key, "source %q is already in tier %s", source, other)
+			}
+			tiered[strings.ToLower(source)] = tier
+		}
+	}
+
+	for symbol, addresses := range viper.GetStringMapStringSlice(config.JobsTokenPins) {
+		for i, a := range addresses {
+			if !common.IsHexAddress(a) {
+				v.fail(fmt.Sprintf("%s.%s[%d]", config.JobsTokenPins, symbol, i), "%q is not a valid address", a)
+			}
+		}
+	}
+
+	if viper.GetFloat64(config.JobsMinVolume24h) < 0 {
+		v.fail(config.JobsMinVolume24h, "%g must not be negative", viper.GetFloat64(config.JobsMinVolume24h))
+	}
+
+	for _, key := range []string{config.JobsDexImpactNotional, config.JobsDexMaxPriceImpact} {
+		if viper.GetFloat64(key) < 0 {
+			v.fail(key, "%g must not be negative", viper.GetFloat64(key))
+		}
+	}
+	if viper.GetFloat64(config.JobsDexMaxPriceImpact) > 100 {
+		v.fail(config.JobsDexMaxPriceImpact, "%g must be a percent <= 100", viper.GetFloat64(config.JobsDexMaxPriceImpact))
+	}
+
+	for _, key := range []string{config.JobsSubgraphMonthlyBudget, config.JobsSubgraphQueryCost} {
+		if viper.GetFloat64(key) < 0 {
+			v.fail(key, "%g must not be negative", viper.GetFloat64(key))
+		}
+	}
+	for dex := range viper.GetStringMap(config.JobsSubgraphQueryCosts) {
+		key := fmt.Sprintf("%s.%s", config.JobsSubgraphQueryCosts, dex)
+		if viper.GetFloat64(key) < 0 {
+			v.fail(key, "%g must not be negative", viper.GetFloat64(key))
+		}
+	}
+	if share := viper.GetFloat64(config.JobsSubgraphSyncBudgetShare); share < 0 || share > 100 {
+		v.fail(config.JobsSubgraphSyncBudgetShare, "%g must be 0 - 100", share)
+	}
+	if w := viper.GetInt64(config.JobsSubgraphBatchWindow); w < 0 {
+		v.fail(config.JobsSubgraphBatchWindow, "%d must not be negative", w)
+	}
+	if viper.IsSet(config.JobsSubgraphBatchSize) && viper.GetInt(config.JobsSubgraphBatchSize) < 1 {
+		v.fail(config.JobsSubgraphBatchSize, "%d must be at least 1", viper.GetInt(config.JobsSubgraphBatchSize))
+	}
+
+	if r := viper.GetInt(config.JobsSourceRetries); r < 0 || r > 10 {
+		v.fail(config.JobsSourceRetries, "%d must be 0 - 10", r)
+	}
+
+	if viper.IsSet(config.JobsDelistingLookback) && viper.GetInt(config.JobsDelistingLookback) <= 0 {
+		v.fail(config.JobsDelistingLookback, "%d must be > 0 days", viper.GetInt(config.JobsDelistingLookback))
+	}
+
+	if viper.GetInt64(config.JobsSourceExcludeDuration) < 0 {
+		v.fail(config.JobsSourceExcludeDuration, "%d must not be negative", viper.GetInt64(config.JobsSourceExcludeDuration))
+	}
+
+	if viper.GetInt64(config.JobsDexFullSyncInterval) < 0 {
+		v.fail(config.JobsDexFullSyncInterval, "%d must not be negative", viper.GetInt64(config.JobsDexFullSyncInterval))
+	}
+
+	if h := viper.GetInt(config.JobsReconcileHour); h < -1 || h > 23 {
+		v.fail(config.JobsReconcileHour, "%d must be 0 - 23, or -1 to disable", h)
+	}
+
+	if _, err := schedule.Load(); err != nil {
+		v.fail(config.ScheduleMaintenanceWindows, "%s", err.Error())
+	}
+
+	if viper.IsSet(config.JobsReconcileLookback) && viper.GetInt(config.JobsReconcileLookback) <= 0 {
+		v.fail(config.JobsReconcileLookback, "%d must be > 0 hours", viper.GetInt(config.JobsReconcileLookback))
+	}
+
+	if viper.GetFloat64(config.JobsSubmissionRate) < 0 {
+		v.fail(config.JobsSubmissionRate, "%g must not be negative", viper.GetFloat64(config.JobsSubmissionRate))
+	}
+
+	if viper.GetInt(config.JobsMaxInFlight) < 0 {
+		v.fail(config.JobsMaxInFlight, "%d must not be negative", viper.GetInt(config.JobsMaxInFlight))
+	}
+
+	if viper.GetInt64(config.JobsAnswerDelayMax) < 0 {
+		v.fail(config.JobsAnswerDelayMax, "%d must not be negative", viper.GetInt64(config.JobsAnswerDelayMax))
+	}
+
+	if _, err := chain.LoadAnswerDelays(); err != nil {
+		v.fail(config.JobsAnswerDelays, "%s", err.Error())
+	}
+
+	if viper.GetInt64(config.JobsPriceFreshness) < 0 {
+		v.fail(config.JobsPriceFreshness, "%d must not be negative", viper.GetInt64(config.JobsPriceFreshness))
+	}
+
+	if b := viper.GetInt(config.JobsGasEscalationBlocks); b < 0 || b > chain.RequestMaxAge {
+		v.fail(config.JobsGasEscalationBlocks, "%d must be between 0 and %d blocks", b, chain.RequestMaxAge)
+	}
+
+	if m := viper.GetFloat64(config.JobsGasEscalationMaxMultiplier); viper.IsSet(config.JobsGasEscalationMaxMultiplier) && m < 1 {
+		v.fail(config.JobsGasEscalationMaxMultiplier, "%g must be >= 1", m)
+	}
+
+	if w := viper.GetFloat64(config.JobsBlendDexWeight); viper.IsSet(config.JobsBlendDexWeight) && (w <= 0 || w > 1) {
+		v.fail(config.JobsBlendDexWeight, "%g must be > 0 and <= 1", w)
+	}
+
+	if viper.GetFloat64(config.JobsBlendMaxDeviation) < 0 {
+		v.fail(config.JobsBlendMaxDeviation, "%g must not be negative", viper.GetFloat64(config.JobsBlendMaxDeviation))
+	}
+
+	if viper.GetFloat64(config.JobsProfitabilityMargin) < 0 {
+		v.fail(config.JobsProfitabilityMargin, "%g must not be negative", viper.GetFloat64(config.JobsProfitabilityMargin))
+	}
+
+	if viper.GetBool(config.JobsProfitabilityCheck) && viper.IsSet(config.JobsProfitabilityPriceEndpoint) {
+		if _, _, _, _, _, _, _, err := ooo_api.ParseEndpoint(viper.GetString(config.JobsProfitabilityPriceEndpoint)); err != nil {
+			v.fail(config.JobsProfitabilityPriceEndpoint, "%s", err.Error())
+		}
+	}
+
+	high := viper.GetInt(config.JobsBackpressureHighWater)
+	if low := viper.GetInt(config.JobsBackpressureLowWater); high > 0 && low >= high {
+		v.fail(config.JobsBackpressureLowWater, "%d must be less than %s (%d)", low, config.JobsBackpressureHighWater, high)
+	}
+
+	if _, err := ooo_api.LoadTwapPools(); err != nil {
+		v.fail(config.JobsTwapPools, "%s", err.Error())
+	}
+
+	if _, err := ooo_api.LoadCustomPairs(); err != nil {
+		v.fail(config.JobsCustomPairs, "%s", err.Error())
+	}
+
+	if _, err := ooo_api.LoadPriceBounds(); err != nil {
+		v.fail(config.JobsPriceBounds, "%s", err.Error())
+	}
+
+	subgraphUrls := viper.GetStringMapString(config.JobsSubgraphUrls)
+	for _, dex := range sortedKeys(subgraphUrls) {
+		key := config.JobsSubgraphUrls + "." + dex
+		if !inList(dex, ooo_api.SubgraphDexNames()) {
+			v.fail(key, "unknown DEX - must be %s", joinOr(ooo_api.SubgraphDexNames()))
+			continue
+		}
+		v.url(key, subgraphUrls[dex], "http", "https")
+	}
+
+	for _, key := range []string{config.JobsServePairs, config.JobsExcludePairs} {
+		for _, pattern := range viper.GetStringSlice(key) {
+			if err := ooo_api.ValidatePairPattern(pattern); err != nil {
+				v.fail(key, "%s", err.Error())
+			}
+		}
+	}
+
+	for _, key := range []string{config.JobsPairSourcesFile, config.JobsMockPricesFile} {
+		if file := viper.GetString(key); file != "" {
+			if _, err := os.Stat(file); err != nil {
+				v.fail(key, "cannot read %s: %s", file, err.Error())
+			}
+		}
+	}
+}
+
+func (v *configValidator) validateAlerts() {
+	v.url(config.AlertsSlackWebhookUrl, viper.GetString(config.AlertsSlackWebhookUrl), "https")
+	v.url(config.AlertsWebhookUrl, viper.GetString(config.AlertsWebhookUrl), "http", "https")
+
+	if (viper.GetString(config.AlertsTelegramBotToken) == "") != (viper.GetString(config.AlertsTelegramChatId) == "") {
+		v.fail(config.AlertsTelegramChatId, "%s and %s must both be set", config.AlertsTelegramBotToken,
+			config.AlertsTelegramChatId)
+	}
+
+	if viper.GetString(config.AlertsSmtpHost) != "" {
+		v.required(config.AlertsSmtpFrom)
+		if len(viper.GetStringSlice(config.AlertsSmtpTo)) == 0 {
+			v.fail(config.AlertsSmtpTo, "is not set")
+		}
+		v.oneOf(config.AlertsSmtpTls, true, alerts.SmtpTlsStartTls, alerts.SmtpTlsImplicit, alerts.SmtpTlsNone)
+		v.port(config.AlertsSmtpPort)
+	}
+
+	routes := viper.GetStringMapStringSlice(config.AlertsRoutes)
+	for _, alertType := range sortedKeys(routes) {
+		key := config.AlertsRoutes + "." + alertType
+		if !inList(alertType, alertTypes) {
+			v.fail(key, "unknown alert type - must be %s", joinOr(alertTypes))
+		}
+		for _, sink := range routes[alertType] {
+			if !inList(strings.ToLower(sink), alertSinks) {
+				v.fail(key, "unknown sink %q - must be %s", sink, joinOr(alertSinks))
+			}
+		}
+	}
+
+	severities := viper.GetStringMapString(config.AlertsSeverities)
+	for _, alertType := range sortedKeys(severities) {
+		key := config.AlertsSeverities + "." + alertType
+		if !inList(alertType, alertTypes) {
+			v.fail(key, "unknown alert type - must be %s", joinOr(alertTypes))
+		}
+		if !inList(strings.ToLower(severities[alertType]), alertSeverities) {
+			v.fail(key, "%q must be %s", severities[alertType], joinOr(alertSeverities))
+		}
+	}
+
+	if skew := viper.GetInt64(config.AlertsMaxClockSkew); skew < 0 {
+		v.fail(config.AlertsMaxClockSkew, "%d must not be negative", skew)
+	}
+}
+
+func (v *configValidator) validateWebhooks() {
+	for i, u := range viper.GetStringSlice(config.WebhooksUrls) {
+		v.url(fmt.Sprintf("%s[%d]", config.WebhooksUrls, i), u, "http", "https")
+	}
+	for i, e := range viper.GetStringSlice(config.WebhooksEvents) {
+		if !inList(strings.ToLower(e), webhookEvents) {
+			v.fail(fmt.Sprintf("%s[%d]", config.WebhooksEvents, i), "%q must be %s", e, joinOr(webhookEvents))
+		}
+	}
+}
+
+func (v *configValidator) validateIpfs() {
+	v.url(config.IpfsApiUrl, viper.GetString(config.IpfsApiUrl), "http", "https")
+	if timeout := viper.GetInt64(config.IpfsTimeout); timeout < 0 {
+		v.fail(config.IpfsTimeout, "%d must not be negative", timeout)
+	}
+}
+
+func (v *configValidator) validateCache() {
+	numErrs := len(v.errs)
+	v.url(config.CacheRedisUrl, viper.GetString(config.CacheRedisUrl), "redis", "rediss")
+	if len(v.errs) == numErrs {
+		// the database number
+		if _, err := cache.New(cache.ConfigFromViper()); err != nil {
+			v.fail(config.CacheRedisUrl, "%s", err.Error())
+		}
+	}
+	if timeout := viper.GetInt64(config.CacheTimeout); timeout < 0 {
+		v.fail(config.CacheTimeout, "%d must not be negative", timeout)
+	}
+}
+
+// validateFeatures checks only known subsystems are disabled, with booleans
+func (v *configValidator) validateFeatures() {
+	features := viper.GetStringMapString("features")
+	for _, name := range sortedKeys(features) {
+		key := "features." + name
+		if !inList(key, config.Features) {
+			v.fail(key, "unknown feature - must be %s", joinOr(config.Features))
+			continue
+		}
+		if _, err := strconv.ParseBool(features[name]); err != nil {
+			v.fail(key, "%q must be true or false", features[name])
+		}
+	}
+}
+
+func (v *configValidator) validatePushFeeds() {
+	if _, err := chain.LoadPushFeeds(); err != nil {
+		v.fail(config.PushFeeds, "%s", err.Error())
+	}
+	if interval := viper.GetInt64(config.PushFeedsCheckInterval); interval < 0 {
+		v.fail(config.PushFeedsCheckInterval, "%d must not be negative", interval)
+	}
+}
+
+func (v *configValidator) validateFeeSchedule() {
+	for _, key := range []string{config.FeeScheduleInterval, config.FeeScheduleWindow, config.FeeScheduleMinSamples,
+		config.FeeScheduleMargin, config.FeeScheduleThreshold, config.FeeScheduleMinFee, config.FeeScheduleMaxFee} {
+		if viper.GetFloat64(key) < 0 {
+			v.fail(key, "%v must not be negative", viper.Get(key))
+		}
+	}
+
+	maxFee := viper.GetUint64(config.FeeScheduleMaxFee)
+	if maxFee > 0 && maxFee < viper.GetUint64(config.FeeScheduleMinFee) {
+		v.fail(config.FeeScheduleMaxFee, "%d must not be less than %s", maxFee, config.FeeScheduleMinFee)
+	}
+	if viper.GetBool(config.FeeScheduleAutoUpdate) && maxFee == 0 {
+		v.fail(config.FeeScheduleMaxFee, "must be set if %s is set", config.FeeScheduleAutoUpdate)
+	}
+}
+
+func (v *configValidator) validateConsensus() {
+	if _, err := chain.LoadConsensusPeers(); err != nil {
+		v.fail(config.ConsensusPeers, "%s", err.Error())
+	}
+	v.port(config.ConsensusPort)
+	if viper.GetInt(config.ConsensusPort) != 0 && len(viper.GetString(config.ConsensusToken)) < 16 {
+		v.fail(config.ConsensusToken, "must be at least 16 characters if %s is set", config.ConsensusPort)
+	}
+	for _, key := range []string{config.ConsensusMaxDeviation, config.ConsensusMinPeers, config.ConsensusMaxAge, config.ConsensusTimeout} {
+		if viper.GetFloat64(key) < 0 {
+			v.fail(key, "%v must not be negative", viper.Get(key))
+		}
+	}
+}
+
+func (v *configValidator) validateServices() {
+	v.port(config.ServePort)
+	v.port(config.AdminApiPort)
+	v.validateAdminApi()
+	v.port(config.PriceApiPort)
+	v.validatePriceApi()
+	v.port(config.PrometheusPort)
+	if viper.GetInt64(config.PrometheusSnapshotInterval) < 0 {
+		v.fail(config.PrometheusSnapshotInterval, "%v must not be negative", viper.Get(config.PrometheusSnapshotInterval))
+	}
+	v.port(config.PprofPort)
+
+	if level := viper.GetString(config.LogLevel); level != "" {
+		if _, err := logrus.ParseLevel(level); err != nil {
+			v.fail(config.LogLevel, "%q must be panic, fatal, error, warning, info, debug or trace", level)
+		}
+	}
+	v.oneOf(config.LogFormat, true, "text", "logfmt", "json")
+
+	if ratio := viper.GetFloat64(config.TracingSampleRatio); ratio < 0 || ratio > 1 {
+		v.fail(config.TracingSampleRatio, "%v must be between 0 and 1", ratio)
+	}
+	v.url(config.ErrorReportingSentryDsn, viper.GetString(config.ErrorReportingSentryDsn), "http", "https")
+	v.url(config.UpdateCheckUrl, viper.GetString(config.UpdateCheckUrl), "http", "https")
+	v.url(config.HttpProxy, viper.GetString(config.HttpProxy), "http", "https", "socks5")
+}
+
+func (v *configValidator) validateAdminApi() {
+	tokens, err := service.AdminTokens()
+	if err != nil {
+		v.fail(config.AdminApiTokens, "must be a table of tokens, each with a key and scope: %s", err.Error())
+	}
+	names := make([]string, 0, len(tokens))
+	for name := range tokens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	scopes := []string{service.AdminScopeRead, service.AdminScopeOperator}
+	for _, name := range names {
+		key := config.AdminApiTokens + "." + name
+		if len(tokens[name].Key) < 16 {
+			v.fail(key+".key", "must be at least 16 characters")
+		}
+		if !inList(strings.ToLower(tokens[name].Scope), scopes) {
+			v.fail(key+".scope", "%q must be %s", tokens[name].Scope, joinOr(scopes))
+		}
+	}
+
+	if secret := viper.GetString(config.AdminApiJwtSecret); secret != "" && len(secret) < 32 {
+		v.fail(config.AdminApiJwtSecret, "must be at least 32 characters")
+	}
+
+	certFile := viper.GetString(config.AdminApiTlsCertFile)
+	keyFile := viper.GetString(config.AdminApiTlsKeyFile)
+	if (certFile == "") != (keyFile == "") {
+		v.fail(config.AdminApiTlsKeyFile, "%s and %s must both be set", config.AdminApiTlsCertFile, config.AdminApiTlsKeyFile)
+	}
+	if viper.GetString(config.AdminApiTlsClientCaFile) != "" && certFile == "" {
+		v.fail(config.AdminApiTlsClientCaFile, "needs %s and %s", config.AdminApiTlsCertFile, config.AdminApiTlsKeyFile)
+	}
+	for _, key := range []string{config.AdminApiTlsCertFile, config.AdminApiTlsKeyFile, config.AdminApiTlsClientCaFile} {
+		if file := viper.GetString(key); file != "" {
+			if _, err := os.Stat(file); err != nil {
+				v.fail(key, "cannot read %s: %s", file, err.Error())
+			}
+		}
+	}
+}
+
+func (v *configValidator) validatePriceApi() {
+	if viper.GetInt(config.PriceApiPort) == 0 {
+		return
+	}
+	for _, key := range []string{config.PriceApiCacheTtl, config.PriceApiRateLimit, config.PriceApiRateBurst} {
+		if viper.GetFloat64(key) < 0 {
+			v.fail(key, "%v must not be negative", viper.Get(key))
+		}
+	}
+}
+
+// CheckChainIds connects to each configured RPC and checks it is on the expected chain - the
+// node's RPCs on chain.network_id and the subchain RPCs on theirs
+func CheckChainIds(ctx context.Context) []ConfigError {
+	var errs []ConfigError
+
+	check := func(key string, expected int64, reason string) {
+		host := viper.GetString(key)
+		if host == "" {
+			return
+		}
+
+		client, err := ethclient.DialContext(ctx, host)
+		if err != nil {
+			errs = append(errs, ConfigError{Key: config.SourceKey(key), Message: fmt.Sprintf("cannot connect to %s: %s", host, err.Error())})
+			return
+		}
+		defer client.Close()
+
+		chainId, err := client.ChainID(ctx)
+		if err != nil {
+			errs = append(errs, ConfigError{Key: config.SourceKey(key), Message: fmt.Sprintf("cannot query %s: %s", host, err.Error())})
+			return
+		}
+		if chainId.Cmp(big.NewInt(expected)) != 0 {
+			errs = append(errs, ConfigError{Key: config.SourceKey(key), Message: fmt.Sprintf("%s is on chain %s, but %s", host,
+				chainId.String(), reason)})
+		}
+	}
+
+	networkId := viper.GetInt64(config.ChainNetworkId)
+	reason := fmt.Sprintf("%s is %d", config.ChainNetworkId, networkId)
+	check(config.ChainEthWsHost, networkId, reason)
+	check(config.ChainEthHttpHost, networkId, reason)
+	for _, key := range sortedKeys(subchainIds) {
+		check(key, subchainIds[key], fmt.Sprintf("it must be on chain %d", subchainIds[key]))
+	}
+
+	return errs
+}
+
+func inList(value string, list []string) bool {
+	for _, l := range list {
+		if value == l {
+			return true
+		}
+	}
+	return false
+}
+
+// joinOr formats values as "a, b or c"
+func joinOr(values []string) string {
+	if len(values) < 2 {
+		return strings.Join(values, "")
+	}
+	return strings.Join(values[:len(values)-1], ", ") + " or " + values[len(values)-1]
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch t := m.(type) {
+	case map[string]string:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string][]string:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string]int64:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
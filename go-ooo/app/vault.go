@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/vault"
+	"time"
+)
+
+// initVault reads the node's secret from Vault, if configured, and keeps the Vault token renewed
+func (s *Server) initVault() {
+	if viper.GetString(config.VaultAddress) == "" {
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "main",
+		"function": "initVault",
+	}).Info("initialise vault")
+
+	if err := s.loadVaultSecret(); err != nil {
+		panic(err)
+	}
+
+	go s.vault.RunRenewal(s.ctx)
+}
+
+func (s *Server) loadVaultSecret() error {
+	client, err := vault.NewClient(s.logger, vault.Config{
+		Address:      viper.GetString(config.VaultAddress),
+		Namespace:    viper.GetString(config.VaultNamespace),
+		Token:        viper.GetString(config.VaultToken),
+		RoleId:       viper.GetString(config.VaultRoleId),
+		SecretId:     viper.GetString(config.VaultSecretId),
+		SecretIdFile: viper.GetString(config.VaultSecretIdFile),
+		AppRoleMount: viper.GetString(config.VaultAppRoleMount),
+		Timeout:      10 * time.Second,
+	})
+	if err != nil {
+		return err
+	}
+
+	mount := viper.GetString(config.VaultKvMount)
+	if mount == "" {
+		mount = "secret"
+	}
+	path := viper.GetString(config.VaultSecretPath)
+	if path == "" {
+		path = "go-ooo"
+	}
+
+	secret, err := client.ReadKv(mount, path)
+	if err != nil {
+		return err
+	}
+
+	s.vault = client
+	s.vaultSecret = secret
+
+	return nil
+}
+
+// vaultField returns a field of the node's Vault secret, or "" if Vault is not configured
+func (s *Server) vaultField(key string, defaultField string) string {
+	field := viper.GetString(key)
+	if field == "" {
+		field = defaultField
+	}
+	return s.vaultSecret[field]
+}
+
+// vaultPrivateKey returns the oracle private key read from Vault
+func (s *Server) vaultPrivateKey() (string, error) {
+	if s.vault == nil {
+		return "", fmt.Errorf("the vault signer backend needs %s", config.VaultAddress)
+	}
+	key := s.vaultField(config.VaultPrivateKeyField, "private_key")
+	if key == "" {
+		return "", fmt.Errorf("vault secret has no private key field")
+	}
+	return key, nil
+}
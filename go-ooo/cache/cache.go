@@ -0,0 +1,408 @@
+// Package cache provides the optional Redis backed cache shared by the nodes of an active/standby
+// pair, and any read-only API replicas, so that source responses, the price API's prices and
+// rate limit counters are held once, rather than by each node, which would otherwise query the
+// upstream sources independently. It speaks enough of the Redis protocol for the few commands
+// used, over a small pool of connections
+package cache
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPrefix - prepended to every key, if cache.prefix is not set
+	DefaultPrefix = "go-ooo:"
+	// defaultTimeout - milliseconds each command may take, if cache.timeout is not set
+	defaultTimeout = 500
+	// maxIdleConns - connections kept open for reuse
+	maxIdleConns = 8
+)
+
+var requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ooo_shared_cache_requests_total",
+	Help: "Number of shared cache commands, by command and result - hit, miss, ok or error",
+}, []string{"command", "result"})
+
+// Config is the Redis server to share the cache through
+type Config struct {
+	// Url is redis://host:port[/db], or rediss:// for TLS, optionally with a username
+	Url      string
+	Password string
+	Prefix   string
+	Timeout  time.Duration
+}
+
+// Client is a connection pool to the shared cache. A nil Client is a cache which is never hit,
+// so callers need not check whether a shared cache is configured
+type Client struct {
+	cfg      Config
+	addr     string
+	useTls   bool
+	username string
+	db       int
+	idle     chan *conn
+}
+
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// redisError is an error reply from the server
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+var (
+	sharedOnce sync.Once
+	shared     *Client
+	sharedErr  error
+)
+
+// Shared returns the client for the [cache] config, connected the first time it is used. It is
+// nil, along with any error in the config, if no Redis url is configured, or it is invalid
+func Shared() *Client {
+	sharedOnce.Do(func() {
+		shared, sharedErr = New(ConfigFromViper())
+	})
+	return shared
+}
+
+// SharedErr returns the error, if any, from configuring the shared client
+func SharedErr() error {
+	Shared()
+	return sharedErr
+}
+
+// ConfigFromViper returns the [cache] config
+func ConfigFromViper() Config {
+	timeout := int64(defaultTimeout)
+	if viper.IsSet(config.CacheTimeout) {
+		timeout = viper.GetInt64(config.CacheTimeout)
+	}
+	prefix := DefaultPrefix
+	if viper.IsSet(config.CachePrefix) {
+		prefix = viper.GetString(config.CachePrefix)
+	}
+	return Config{
+		Url:      viper.GetString(config.CacheRedisUrl),
+		Password: viper.GetString(config.CacheRedisPassword),
+		Prefix:   prefix,
+		Timeout:  time.Duration(timeout) * time.Millisecond,
+	}
+}
+
+// New returns a client for the Redis server at cfg.Url, or nil if cfg.Url is empty. No
+// connection is made until the first command
+func New(cfg Config) (*Client, error) {
+	if cfg.Url == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(cfg.Url)
+	if err != nil {
+		return nil, err
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if (scheme != "redis" && scheme != "rediss") || u.Host == "" {
+		return nil, fmt.Errorf("%q must be a redis or rediss url", cfg.Url)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout * time.Millisecond
+	}
+
+	c := &Client{
+		cfg:    cfg,
+		addr:   u.Host,
+		useTls: scheme == "rediss",
+		idle:   make(chan *conn, maxIdleConns),
+	}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		if password, ok := u.User.Password(); ok && cfg.Password == "" {
+			c.cfg.Password = password
+		}
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil || c.db < 0 {
+			return nil, fmt.Errorf("%q: database %q must be a number", cfg.Url, db)
+		}
+	}
+
+	return c, nil
+}
+
+// Enabled returns true if a shared cache is configured
+func (c *Client) Enabled() bool {
+	return c != nil
+}
+
+// Get returns the value of key, and false if it is not set
+func (c *Client) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if c == nil {
+		return nil, false, nil
+	}
+
+	reply, err := c.do(ctx, "GET", c.cfg.Prefix+key)
+	if err != nil {
+		requestsTotal.WithLabelValues("get", "error").Inc()
+		return nil, false, err
+	}
+	if reply == nil {
+		requestsTotal.WithLabelValues("get", "miss").Inc()
+		return nil, false, nil
+	}
+
+	requestsTotal.WithLabelValues("get", "hit").Inc()
+	value, _ := reply.([]byte)
+	return value, true, nil
+}
+
+// Set sets key to value, expiring after ttl
+func (c *Client) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if c == nil {
+		return nil
+	}
+
+	_, err := c.do(ctx, "SET", c.cfg.Prefix+key, string(value), "PX", ttlMillis(ttl))
+	requestsTotal.WithLabelValues("set", result(err)).Inc()
+	return err
+}
+
+// incrScript increments a counter, setting its expiry if it is new, in one step, so that a
+// counter is never left without an expiry
+const incrScript = `
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return n`
+
+// Incr increments the counter at key, returning its new value. A new counter expires after ttl
+func (c *Client) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	if c == nil {
+		return 0, errors.New("no shared cache configured")
+	}
+
+	reply, err := c.eval(ctx, incrScript, []string{key}, ttlMillis(ttl))
+	requestsTotal.WithLabelValues("incr", result(err)).Inc()
+	if err != nil {
+		return 0, err
+	}
+	n, _ := reply.(int64)
+	return n, nil
+}
+
+// Eval runs a Lua script atomically, with keys prefixed, returning its reply - nil, an int64, a
+// string, []byte or []interface{}
+func (c *Client) Eval(ctx context.Context, script string, keys []string, args ...string) (interface{}, error) {
+	if c == nil {
+		return nil, errors.New("no shared cache configured")
+	}
+
+	reply, err := c.eval(ctx, script, keys, args...)
+	requestsTotal.WithLabelValues("eval", result(err)).Inc()
+	return reply, err
+}
+
+func (c *Client) eval(ctx context.Context, script string, keys []string, args ...string) (interface{}, error) {
+	cmd := make([]string, 0, 3+len(keys)+len(args))
+	cmd = append(cmd, "EVAL", script, strconv.Itoa(len(keys)))
+	for _, k := range keys {
+		cmd = append(cmd, c.cfg.Prefix+k)
+	}
+	return c.do(ctx, append(cmd, args...)...)
+}
+
+// ttlMillis returns ttl in milliseconds, at least 1, since Redis rejects an expiry of 0
+func ttlMillis(ttl time.Duration) string {
+	ms := ttl.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	return strconv.FormatInt(ms, 10)
+}
+
+// Ping checks the server can be reached and authenticated with
+func (c *Client) Ping(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	_, err := c.do(ctx, "PING")
+	return err
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+// do sends a command and returns its reply - nil, an int64, a string, []byte or []interface{}
+func (c *Client) do(ctx context.Context, args ...string) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.do(c.deadline(ctx), args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		// the connection's state is unknown
+		_ = cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return reply, err
+}
+
+func (c *Client) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(c.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		return d
+	}
+	return deadline
+}
+
+// get returns an idle connection, or a new one, authenticated and on the configured database
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Deadline: c.deadline(ctx)}
+	var nc net.Conn
+	var err error
+	if c.useTls {
+		host, _, _ := net.SplitHostPort(c.addr)
+		nc, err = tls.DialWithDialer(dialer, "tcp", c.addr, &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12})
+	} else {
+		nc, err = dialer.DialContext(ctx, "tcp", c.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc)}
+	if c.cfg.Password != "" {
+		args := []string{"AUTH", c.cfg.Password}
+		if c.username != "" {
+			args = []string{"AUTH", c.username, c.cfg.Password}
+		}
+		if _, err = cn.do(c.deadline(ctx), args...); err != nil {
+			_ = nc.Close()
+			return nil, err
+		}
+	}
+	if c.db != 0 {
+		if _, err = cn.do(c.deadline(ctx), "SELECT", strconv.Itoa(c.db)); err != nil {
+			_ = nc.Close()
+			return nil, err
+		}
+	}
+
+	return cn, nil
+}
+
+// put returns a connection to the pool, closing it if the pool is full
+func (c *Client) put(cn *conn) {
+	select {
+	case c.idle <- cn:
+	default:
+		_ = cn.Close()
+	}
+}
+
+func (cn *conn) do(deadline time.Time, args ...string) (interface{}, error) {
+	if err := cn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := cn.Write([]byte(b.String())); err != nil {
+		return nil, err
+	}
+
+	return cn.readReply()
+}
+
+// readReply reads a reply in the Redis serialization protocol
+func (cn *conn) readReply() (interface{}, error) {
+	line, err := cn.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err = io.ReadFull(cn.r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			item, err := cn.readReply()
+			var replyErr redisError
+			if err != nil && !errors.As(err, &replyErr) {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
@@ -2,7 +2,9 @@ package chain
 
 import (
 	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/sirupsen/logrus"
 	go_ooo_types "go-ooo/types"
@@ -11,18 +13,14 @@ import (
 
 func (o *OoORouterService) ProcessAdminTask(task go_ooo_types.AdminTask) go_ooo_types.AdminTaskResponse {
 
-	err := o.RenewTransactOpts()
-	if err != nil {
-		o.logger.WithFields(logrus.Fields{
-			"package":  "chain",
-			"function": "ProcessAdminTask",
-			"action":   "RenewTransactOpts",
-		}).Error(err.Error())
-		return go_ooo_types.AdminTaskResponse{
-			AdminTask: task,
-			Success:   false,
-			Error:     err.Error(),
-		}
+	// pausing does not send txs, and may be needed while the RPC or key is unavailable
+	switch task.Task {
+	case "pause":
+		return o.pause(task)
+	case "resume":
+		return o.resume(task)
+	case "query_paused":
+		return o.queryPaused(task)
 	}
 
 	switch task.Task {
@@ -40,6 +38,14 @@ func (o *OoORouterService) ProcessAdminTask(task go_ooo_types.AdminTask) go_ooo_
 		return o.queryFees(task)
 	case "query_granular_fees":
 		return o.queryGranularFees(task)
+	case "xfund_allowance":
+		return o.queryXfundAllowance(task)
+	case "xfund_approve":
+		return o.approveXfund(task)
+	case "vor_register":
+		return o.vorRegisterProvingKey(task)
+	case "vor_key":
+		return o.vorQueryKey(task)
 	default:
 		return go_ooo_types.AdminTaskResponse{
 			AdminTask: task,
@@ -48,6 +54,27 @@ func (o *OoORouterService) ProcessAdminTask(task go_ooo_types.AdminTask) go_ooo_
 	}
 }
 
+// sendAdminTx sends an admin task's tx from the oracle key, with a copy of the renewed transact
+// opts. txMu is held from renewing the opts until the nonce is updated, so that the tx can't be
+// sent with the nonce of a fulfillment in flight
+func (o *OoORouterService) sendAdminTx(send func(opts *bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, error) {
+	o.txMu.Lock()
+	defer o.txMu.Unlock()
+
+	if err := o.RenewTransactOpts(); err != nil {
+		return nil, err
+	}
+
+	opts := *o.transactOpts
+	tx, err := send(&opts)
+	if err != nil {
+		return nil, err
+	}
+	o.setNextTxNonce(tx.Nonce(), false)
+
+	return tx, nil
+}
+
 func (o *OoORouterService) registerAsProvider(task go_ooo_types.AdminTask) go_ooo_types.AdminTaskResponse {
 
 	var resp go_ooo_types.AdminTaskResponse
@@ -61,7 +88,9 @@ func (o *OoORouterService) registerAsProvider(task go_ooo_types.AdminTask) go_oo
 		"fee":      fee,
 	}).Debug("begin register as provider")
 
-	tx, err := o.contractInstance.RegisterAsProvider(o.transactOpts, big.NewInt(int64(fee)))
+	tx, err := o.sendAdminTx(func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return o.contractInstance.RegisterAsProvider(opts, big.NewInt(int64(fee)))
+	})
 	if err != nil {
 		o.logger.WithFields(logrus.Fields{
 			"package":  "chain",
@@ -77,8 +106,6 @@ func (o *OoORouterService) registerAsProvider(task go_ooo_types.AdminTask) go_oo
 			"tx":       tx.Hash(),
 		}).Info("register as provider tx sent")
 
-		o.setNextTxNonce(tx.Nonce(), false)
-
 		resp.Result = fmt.Sprintf("Sent! Tx Hash: %s", tx.Hash().String())
 		resp.Success = true
 	}
@@ -99,7 +126,9 @@ func (o *OoORouterService) setGlobalFee(task go_ooo_types.AdminTask) go_ooo_type
 		"fee":      fee,
 	}).Debug("begin set global fee")
 
-	tx, err := o.contractInstance.SetProviderMinFee(o.transactOpts, big.NewInt(int64(fee)))
+	tx, err := o.sendAdminTx(func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return o.contractInstance.SetProviderMinFee(opts, big.NewInt(int64(fee)))
+	})
 	if err != nil {
 		o.logger.WithFields(logrus.Fields{
 			"package":  "chain",
@@ -120,7 +149,6 @@ func (o *OoORouterService) setGlobalFee(task go_ooo_types.AdminTask) go_ooo_type
 
 		resp.Result = fmt.Sprintf("Sent! Tx Hash: %s", tx.Hash().String())
 		resp.Success = true
-		o.setNextTxNonce(tx.Nonce(), false)
 	}
 
 	return resp
@@ -141,7 +169,9 @@ func (o *OoORouterService) setGranularFee(task go_ooo_types.AdminTask) go_ooo_ty
 		"consumer": consumer,
 	}).Debug("begin set granular fee")
 
-	tx, err := o.contractInstance.SetProviderGranularFee(o.transactOpts, common.HexToAddress(consumer), big.NewInt(int64(fee)))
+	tx, err := o.sendAdminTx(func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return o.contractInstance.SetProviderGranularFee(opts, common.HexToAddress(consumer), big.NewInt(int64(fee)))
+	})
 	if err != nil {
 		o.logger.WithFields(logrus.Fields{
 			"package":  "chain",
@@ -164,7 +194,6 @@ func (o *OoORouterService) setGranularFee(task go_ooo_types.AdminTask) go_ooo_ty
 
 		resp.Result = fmt.Sprintf("Sent! Tx Hash: %s", tx.Hash().String())
 		resp.Success = true
-		o.setNextTxNonce(tx.Nonce(), false)
 	}
 
 	return resp
@@ -205,7 +234,9 @@ func (o *OoORouterService) withdraw(task go_ooo_types.AdminTask) go_ooo_types.Ad
 		return resp
 	}
 
-	tx, err := o.contractInstance.Withdraw(o.transactOpts, common.HexToAddress(recipient), amountBig)
+	tx, err := o.sendAdminTx(func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return o.contractInstance.Withdraw(opts, common.HexToAddress(recipient), amountBig)
+	})
 	if err != nil {
 		o.logger.WithFields(logrus.Fields{
 			"package":   "chain",
@@ -226,7 +257,6 @@ func (o *OoORouterService) withdraw(task go_ooo_types.AdminTask) go_ooo_types.Ad
 
 		resp.Result = fmt.Sprintf("Sent! Tx Hash: %s", tx.Hash().String())
 		resp.Success = true
-		o.setNextTxNonce(tx.Nonce(), false)
 	}
 
 	return resp
@@ -0,0 +1,215 @@
+package chain
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	go_ooo_types "go-ooo/types"
+	"math/big"
+	"strings"
+)
+
+// spender names which can be given in place of an address
+const (
+	SpenderRouter = "router"
+	SpenderVor    = "vor"
+)
+
+// erc20AllowanceAbi is the part of the xFUND token's ABI used to manage the oracle key's approvals
+const erc20AllowanceAbi = `[{"constant":true,"inputs":[{"name":"_owner","type":"address"},{"name":"_spender","type":"address"}],"name":"allowance","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":false,"inputs":[{"name":"_spender","type":"address"},{"name":"_value","type":"uint256"}],"name":"approve","outputs":[{"name":"","type":"bool"}],"type":"function"}]`
+
+var xfundAllowanceGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "ooo_xfund_allowance",
+	Help: "xFUND, in the smallest unit, the oracle key has approved each monitored spender to transfer, by spender name and address",
+}, []string{"spender", "address"})
+
+// xfundToken returns the xFUND token the router is configured with, bound to the approval ABI
+func (o *OoORouterService) xfundToken() (*bind.BoundContract, error) {
+	tokenAddress, err := o.contractInstance.GetTokenAddress(o.callOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(erc20AllowanceAbi))
+	if err != nil {
+		return nil, err
+	}
+
+	return bind.NewBoundContract(tokenAddress, parsed, o.client, o.client, o.client), nil
+}
+
+// allowanceSpenders returns the spenders whose allowances are monitored, by name - the router,
+// the VORCoordinator if VOR is enabled, and any in chain.xfund_spenders, named by address
+func (o *OoORouterService) allowanceSpenders() ([]string, map[string]common.Address) {
+	names := []string{SpenderRouter}
+	spenders := map[string]common.Address{SpenderRouter: o.contractAddress}
+
+	if o.VorEnabled() {
+		names = append(names, SpenderVor)
+		spenders[SpenderVor] = common.HexToAddress(viper.GetString(config.ChainVorCoordinatorAddress))
+	}
+
+	for _, s := range viper.GetStringSlice(config.ChainXfundSpenders) {
+		if !common.IsHexAddress(s) {
+			continue
+		}
+		address := common.HexToAddress(s)
+		if _, ok := spenders[address.Hex()]; !ok {
+			names = append(names, address.Hex())
+			spenders[address.Hex()] = address
+		}
+	}
+
+	return names, spenders
+}
+
+// resolveSpender returns the address of spender, a spender name or an address
+func (o *OoORouterService) resolveSpender(spender string) (string, common.Address, error) {
+	_, spenders := o.allowanceSpenders()
+	if address, ok := spenders[strings.ToLower(spender)]; ok {
+		return strings.ToLower(spender), address, nil
+	}
+	if !common.IsHexAddress(spender) {
+		return "", common.Address{}, fmt.Errorf("spender %q must be an address, %s or %s", spender, SpenderRouter, SpenderVor)
+	}
+	address := common.HexToAddress(spender)
+	return address.Hex(), address, nil
+}
+
+// XfundAllowances returns the oracle key's xFUND allowance for each monitored spender, updating
+// the allowance metrics
+func (o *OoORouterService) XfundAllowances() ([]go_ooo_types.XfundAllowance, error) {
+	token, err := o.xfundToken()
+	if err != nil {
+		return nil, err
+	}
+
+	names, spenders := o.allowanceSpenders()
+	allowances := make([]go_ooo_types.XfundAllowance, 0, len(names))
+
+	for _, name := range names {
+		allowance, err := o.xfundAllowance(token, spenders[name])
+		if err != nil {
+			return allowances, err
+		}
+		f, _ := new(big.Float).SetInt(allowance).Float64()
+		xfundAllowanceGauge.WithLabelValues(name, spenders[name].Hex()).Set(f)
+
+		allowances = append(allowances, go_ooo_types.XfundAllowance{
+			Spender:   name,
+			Address:   spenders[name].Hex(),
+			Allowance: allowance.String(),
+		})
+	}
+
+	return allowances, nil
+}
+
+func (o *OoORouterService) xfundAllowance(token *bind.BoundContract, spender common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := token.Call(o.callOpts, &out, "allowance", o.oracleAddress, spender)
+	if err != nil {
+		rpcError("allowance")
+		return nil, err
+	}
+
+	return *abi.ConvertType(out[0], new(*big.Int)).(**big.Int), nil
+}
+
+func (o *OoORouterService) queryXfundAllowance(task go_ooo_types.AdminTask) go_ooo_types.AdminTaskResponse {
+	var resp go_ooo_types.AdminTaskResponse
+	resp.AdminTask = task
+
+	allowances, err := o.XfundAllowances()
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "queryXfundAllowance",
+		}).Error(err.Error())
+		resp.Error = err.Error()
+		resp.Success = false
+		return resp
+	}
+
+	lines := make([]string, 0, len(allowances))
+	for _, a := range allowances {
+		lines = append(lines, fmt.Sprintf("%s (%s): %s", a.Spender, a.Address, a.Allowance))
+	}
+
+	resp.Result = strings.Join(lines, ", ")
+	resp.Success = true
+
+	return resp
+}
+
+// approveXfund sets the oracle key's xFUND allowance for the spender in task.ToOrConsumer to
+// task.FeeOrAmount. An amount of 0 revokes the approval
+func (o *OoORouterService) approveXfund(task go_ooo_types.AdminTask) go_ooo_types.AdminTaskResponse {
+	var resp go_ooo_types.AdminTaskResponse
+	resp.AdminTask = task
+
+	amount := new(big.Int).SetUint64(task.FeeOrAmount)
+
+	name, spender, err := o.resolveSpender(task.ToOrConsumer)
+	if err != nil {
+		resp.Error = err.Error()
+		resp.Success = false
+		return resp
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "approveXfund",
+		"spender":  name,
+		"address":  spender.Hex(),
+		"amount":   amount.String(),
+	}).Debug("begin approve xfund")
+
+	token, err := o.xfundToken()
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "approveXfund",
+			"action":   "bind xfund token",
+		}).Error(err.Error())
+		resp.Error = err.Error()
+		resp.Success = false
+		return resp
+	}
+
+	tx, err := o.sendAdminTx(func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return token.Transact(opts, "approve", spender, amount)
+	})
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "approveXfund",
+			"spender":  name,
+			"address":  spender.Hex(),
+			"amount":   amount.String(),
+		}).Error(err.Error())
+		resp.Error = err.Error()
+		resp.Success = false
+	} else {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "approveXfund",
+			"spender":  name,
+			"address":  spender.Hex(),
+			"amount":   amount.String(),
+			"tx":       tx.Hash(),
+		}).Info("approve xfund tx sent")
+
+		resp.Result = fmt.Sprintf("Sent! Tx Hash: %s", tx.Hash().String())
+		resp.Success = true
+	}
+
+	return resp
+}
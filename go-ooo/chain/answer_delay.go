@@ -0,0 +1,146 @@
+package chain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	"go-ooo/ooo_api"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// answerDelayExpiryBlocks - a request within this many blocks of being abandoned as too old is
+// fulfilled without a delay
+const answerDelayExpiryBlocks = 10
+
+var answerDelayHistogram = promauto.NewHistogram(prometheus.HistogramOpts{
+	Name:    "ooo_answer_delay_seconds",
+	Help:    "Random delay before fulfillment txs were submitted",
+	Buckets: []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 30},
+})
+
+// AnswerDelay is a pair's maximum random delay before its fulfillments are submitted, in place of
+// jobs.answer_delay_max, e.g.
+//
+//	[[jobs.answer_delays]]
+//	pair = "ETH.USD"
+//	max = 3000
+//
+// A max of 0 submits the pair's fulfillments without a delay
+type AnswerDelay struct {
+	Pair string `mapstructure:"pair" json:"pair"`
+	Max  int64  `mapstructure:"max" json:"max"`
+}
+
+// LoadAnswerDelays reads and validates the configured per-pair answer delays
+func LoadAnswerDelays() ([]AnswerDelay, error) {
+	var delays []AnswerDelay
+	var err error
+	if raw, ok := viper.Get(config.JobsAnswerDelays).(string); ok {
+		// set by an environment variable, as a JSON array of delays
+		err = json.Unmarshal([]byte(raw), &delays)
+	} else {
+		err = viper.UnmarshalKey(config.JobsAnswerDelays, &delays)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make(map[string]bool)
+	for i, d := range delays {
+		base, target, ok := ooo_api.SplitPair(d.Pair)
+		if !ok {
+			return nil, fmt.Errorf("answer delay %d: pair %q must be of the form BASE.TARGET", i+1, d.Pair)
+		}
+		pair := fmt.Sprintf("%s.%s", base, target)
+		if pairs[pair] {
+			return nil, fmt.Errorf("duplicate answer delay for %s", pair)
+		}
+		pairs[pair] = true
+
+		if d.Max < 0 {
+			return nil, fmt.Errorf("answer delay %s: max must not be negative", pair)
+		}
+	}
+
+	return delays, nil
+}
+
+// answerDelayMax returns the maximum random delay before submitting a fulfillment for the
+// endpoint's pair - the pair's own if configured, otherwise jobs.answer_delay_max
+func answerDelayMax(endpoint string) time.Duration {
+	max := viper.GetInt64(config.JobsAnswerDelayMax)
+
+	base, target, _, _, _, _, _, err := ooo_api.ParseEndpoint(endpoint)
+	if err == nil {
+		// validated when the config is loaded
+		delays, _ := LoadAnswerDelays()
+		for _, d := range delays {
+			b, t, _ := ooo_api.SplitPair(d.Pair)
+			if strings.EqualFold(b, base) && strings.EqualFold(t, target) {
+				max = d.Max
+				break
+			}
+		}
+	}
+
+	return time.Duration(max) * time.Millisecond
+}
+
+// delayAnswer waits a random time, up to the pair's configured maximum, before the job's
+// fulfillment is submitted, so that searchers cannot time their txs around the provider's oracle
+// updates. The delay is bounded by the time left before the job times out, and skipped for a
+// request close to being abandoned as too old. It returns false if ctx is done first
+func (o *OoORouterService) delayAnswer(ctx context.Context, job models.DataRequests, currentBlockNum uint64) bool {
+	max := answerDelayMax(job.GetEndpointDecoded())
+	if max <= 0 {
+		return true
+	}
+
+	if currentBlockNum+answerDelayExpiryBlocks > job.GetRequestBlockNumber()+RequestMaxAge {
+		return true
+	}
+
+	// leave at least half the time remaining to send the tx
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline) / 2; remaining < max {
+			max = remaining
+		}
+	}
+	if max <= 0 {
+		return true
+	}
+
+	// not math/rand, whose unseeded sequence is the same on every start
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		return true
+	}
+	delay := time.Duration(n.Int64())
+
+	o.jobLogger(job).WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "delayAnswer",
+		"request_id": job.GetRequestId(),
+		"delay":      delay.String(),
+	}).Debug("delaying fulfillment")
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+	}
+
+	answerDelayHistogram.Observe(delay.Seconds())
+	return true
+}
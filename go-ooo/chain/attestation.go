@@ -0,0 +1,119 @@
+package chain
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	solsha3 "github.com/miguelmota/go-solidity-sha3"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"strings"
+	"time"
+)
+
+// SignAttestation generates the attestation hash and its signature for an answer. The hash is
+//
+//	keccak256(abi.encodePacked(requestId, endpoint, price, timestamp, sources))
+//
+// where sources is a comma separated list of the data sources used, and it is signed as an
+// Ethereum signed message by signFn, e.g. Signer.SignText, so can be verified using ecrecover in
+// the same way as fulfillments.
+func SignAttestation(requestId string, endpoint string, price string, timestamp int64, sources string,
+	signFn func([]byte) ([]byte, error)) (common.Hash, []byte, error) {
+
+	hash := attestationHash(requestId, endpoint, price, timestamp, sources)
+
+	signatureBytes, err := signFn(hash.Bytes())
+
+	if err != nil {
+		return hash, nil, err
+	}
+
+	signatureBytes[64] = uint8(int(signatureBytes[64])) + 27
+
+	return hash, signatureBytes, nil
+}
+
+func attestationHash(requestId string, endpoint string, price string, timestamp int64, sources string) common.Hash {
+	return common.BytesToHash(solsha3.SoliditySHA3(
+		solsha3.Bytes32(common.FromHex(requestId)),
+		solsha3.String(endpoint),
+		solsha3.Uint256(price),
+		solsha3.Uint256(fmt.Sprintf("%d", timestamp)),
+		solsha3.String(sources),
+	))
+}
+
+// AttestationDocument is an attestation as published to IPFS - the signed fields, with the
+// context needed to verify it against the on-chain answer
+type AttestationDocument struct {
+	RequestId string   `json:"request_id"`
+	Endpoint  string   `json:"endpoint"`
+	Pair      string   `json:"pair,omitempty"`
+	Price     string   `json:"price"`
+	Decimals  uint     `json:"decimals"`
+	Sources   []string `json:"sources"`
+	Timestamp int64    `json:"timestamp"`
+	ChainId   int64    `json:"chain_id"`
+	// Contract - the router the answer was submitted to
+	Contract    string `json:"contract"`
+	Signer      string `json:"signer"`
+	MessageHash string `json:"message_hash"`
+	Signature   string `json:"signature"`
+}
+
+// createAttestation signs the answer for a request with the provider key and stores it
+func (o *OoORouterService) createAttestation(requestId string, endpoint string, price string, sources []string) {
+	timestamp := time.Now().Unix()
+	sourcesStr := strings.Join(sources, ",")
+
+	hash, signature, err := SignAttestation(requestId, endpoint, price, timestamp, sourcesStr, o.oracleSigner.SignText)
+
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "createAttestation",
+			"action":     "sign attestation",
+			"request_id": requestId,
+		}).Error(err.Error())
+		return
+	}
+
+	err = o.db.UpsertAttestation(requestId, endpoint, price, timestamp, sourcesStr,
+		o.oracleAddress.Hex(), hash.Hex(), common.Bytes2Hex(signature))
+
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "createAttestation",
+			"action":     "store attestation",
+			"request_id": requestId,
+		}).Error(err.Error())
+		return
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "createAttestation",
+		"request_id": requestId,
+		"sources":    sourcesStr,
+		"hash":       hash.Hex(),
+	}).Debug("answer attestation signed")
+
+	if o.ipfs.Enabled() {
+		o.publishAttestation(AttestationDocument{
+			RequestId:   requestId,
+			Endpoint:    endpoint,
+			Pair:        endpointPair(endpoint),
+			Price:       price,
+			Decimals:    o.oooApi.AnswerDecimals(),
+			Sources:     sources,
+			Timestamp:   timestamp,
+			ChainId:     viper.GetInt64(config.ChainNetworkId),
+			Contract:    o.contractAddress.Hex(),
+			Signer:      o.oracleAddress.Hex(),
+			MessageHash: hash.Hex(),
+			Signature:   common.Bytes2Hex(signature),
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package chain
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/ooo_router"
+)
+
+var (
+	backfillQueueGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ooo_backfill_queue_ranges",
+		Help: "Number of fetched block ranges waiting to be ingested by the backfill",
+	})
+
+	backfillScannedGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ooo_backfill_scanned_block",
+		Help: "Last block whose logs were fetched by the backfill's log scan",
+	})
+)
+
+func (o *OoORouterService) GetHistoricalEvents() {
+	o.startCatchup()
+	o.getEventsFrom(o.historicalFilterOpts)
+}
+
+// fetchedRange is the events of a block range, fetched by the log scan and waiting to be ingested
+type fetchedRange struct {
+	opts        bind.FilterOpts
+	requests    []*ooo_router.OooRouterDataRequested
+	fulfilments []*ooo_router.OooRouterRequestFulfilled
+}
+
+// getEventsFrom processes the events from filterOpts.Start to filterOpts.End, or until caught
+// up with the chain head if End is nil. The log scan fetches chain.backfill_block_range blocks at
+// a time, and queues up to chain.backfill_queue_ranges fetched ranges for ingestion, so that slow
+// db writes do not hold up fetching logs, nor slow fetches the db writes. The last block
+// processed is saved after each range is ingested, so that an interrupted backfill resumes from
+// the last range it completed
+func (o *OoORouterService) getEventsFrom(filterOpts *bind.FilterOpts) {
+	queueSize := viper.GetInt(config.ChainBackfillQueueRanges)
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	ranges := make(chan *fetchedRange, queueSize)
+	o.supervisor.Go("backfill_scan", func() {
+		o.scanLogs(filterOpts, ranges)
+	})
+
+	for r := range ranges {
+		backfillQueueGauge.Set(float64(len(ranges)))
+		o.ingestRange(r)
+		o.setLastBlockNumber(*r.opts.End)
+	}
+	backfillQueueGauge.Set(0)
+}
+
+// scanLogs fetches the events from filterOpts.Start to filterOpts.End, or the chain head if End
+// is nil, a range at a time, sending each range to ranges. ranges is closed when the scan is
+// complete, or a range cannot be fetched
+func (o *OoORouterService) scanLogs(filterOpts *bind.FilterOpts, ranges chan<- *fetchedRange) {
+	defer close(ranges)
+
+	me := o.providerAddresses()
+	blockRange := viper.GetUint64(config.ChainBackfillBlockRange)
+	fromBlock := filterOpts.Start
+
+	for {
+		var toBlock uint64
+		if filterOpts.End != nil {
+			toBlock = *filterOpts.End
+		} else {
+			currentBlockNum, err := o.client.BlockNumber(o.context)
+			if err != nil {
+				o.logger.WithFields(logrus.Fields{
+					"package":  "chain",
+					"function": "GetHistoricalEvents",
+					"action":   "get block num",
+				}).Error(err.Error())
+				rpcError("BlockNumber")
+
+				return
+			}
+			toBlock = currentBlockNum
+		}
+
+		if fromBlock > toBlock {
+			return
+		}
+
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "GetHistoricalEvents",
+			"from_block": fromBlock,
+			"to_block":   toBlock,
+		}).Info("get event history")
+
+		for fromBlock <= toBlock {
+			endBlock := toBlock
+			if blockRange > 0 && toBlock-fromBlock >= blockRange {
+				endBlock = fromBlock + blockRange - 1
+			}
+
+			opts := *filterOpts
+			opts.Start = fromBlock
+			opts.End = &endBlock
+
+			r, ok := o.fetchRange(&opts, me)
+			if !ok {
+				return
+			}
+
+			select {
+			case ranges <- r:
+			case <-o.context.Done():
+				return
+			}
+			backfillScannedGauge.Set(float64(endBlock))
+			fromBlock = endBlock + 1
+		}
+
+		// blocks mined during the backfill are picked up on the next pass
+		if filterOpts.End != nil {
+			return
+		}
+	}
+}
+
+// fetchRange fetches the router events between opts.Start and *opts.End. Returns false if the
+// events could not be fetched
+func (o *OoORouterService) fetchRange(opts *bind.FilterOpts, me []common.Address) (*fetchedRange, bool) {
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "fetchRange",
+		"from_block": opts.Start,
+		"to_block":   *opts.End,
+	})
+
+	logger.Debug("get events in range")
+
+	r := &fetchedRange{opts: *opts}
+
+	itrDr, err := o.contractInstance.FilterDataRequested(opts, nil, me, nil)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"action": "get FilterDataRequested events",
+		}).Error(err.Error())
+		rpcError("FilterDataRequested")
+
+		return nil, false
+	}
+
+	for itrDr.Next() {
+		r.requests = append(r.requests, itrDr.Event)
+	}
+
+	err = itrDr.Error()
+	_ = itrDr.Close()
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"action": "read FilterDataRequested events",
+		}).Error(err.Error())
+
+		return nil, false
+	}
+
+	itrFr, err := o.contractInstance.FilterRequestFulfilled(opts, nil, me, nil)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"action": "get FilterRequestFulfilled events",
+		}).Error(err.Error())
+		rpcError("FilterRequestFulfilled")
+
+		return nil, false
+	}
+
+	for itrFr.Next() {
+		r.fulfilments = append(r.fulfilments, itrFr.Event)
+	}
+
+	err = itrFr.Error()
+	_ = itrFr.Close()
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"action": "read FilterRequestFulfilled events",
+		}).Error(err.Error())
+
+		return nil, false
+	}
+
+	return r, true
+}
+
+// ingestRange processes a fetched range's events. New requests are added to the db
+// chain.backfill_batch_size at a time
+func (o *OoORouterService) ingestRange(r *fetchedRange) {
+	batchSize := viper.GetInt(config.ChainBackfillBatchSize)
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	for i := 0; i < len(r.requests); i += batchSize {
+		end := i + batchSize
+		if end > len(r.requests) {
+			end = len(r.requests)
+		}
+		o.ingestRequests(r.requests[i:end])
+	}
+
+	for _, ev := range r.fulfilments {
+		o.processIncomingFulfilments(ev)
+	}
+
+	if o.VorEnabled() {
+		o.getVorHistoricalEvents(&r.opts)
+	}
+	if o.EventFiltersEnabled() {
+		o.getFilteredHistoricalEvents(&r.opts)
+	}
+}
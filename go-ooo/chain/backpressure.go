@@ -0,0 +1,78 @@
+package chain
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"time"
+)
+
+var (
+	jobBacklogGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ooo_job_backlog",
+		Help: "Number of pending jobs whose fulfillment tx has not yet been sent",
+	})
+
+	ingestionThrottledGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ooo_ingestion_throttled",
+		Help: "1 if event ingestion is suspended by backpressure",
+	})
+)
+
+// CheckBackpressure suspends event ingestion while the job backlog is above the high water mark,
+// or the database is slow to respond, so that work does not accumulate during traffic spikes.
+// Ingestion resumes once the backlog drains below the low water mark, and events emitted in
+// the meantime are then processed
+func (o *OoORouterService) CheckBackpressure() {
+	highWater := viper.GetInt64(config.JobsBackpressureHighWater)
+	if highWater <= 0 {
+		return
+	}
+
+	lowWater := viper.GetInt64(config.JobsBackpressureLowWater)
+	if lowWater <= 0 || lowWater > highWater {
+		lowWater = highWater / 2
+	}
+	maxDbLatency := time.Duration(viper.GetInt64(config.JobsBackpressureDbLatency)) * time.Millisecond
+
+	start := time.Now()
+	backlog, err := o.db.CountUnsentJobs()
+	dbLatency := time.Since(start)
+
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "CheckBackpressure",
+		"backlog":    backlog,
+		"high_water": highWater,
+		"low_water":  lowWater,
+		"db_latency": dbLatency.String(),
+	})
+
+	if err != nil {
+		logger.Error(err.Error())
+	} else {
+		jobBacklogGauge.Set(float64(backlog))
+	}
+
+	o.paused.mu.RLock()
+	throttled := o.paused.throttled
+	o.paused.mu.RUnlock()
+
+	overloaded := err != nil || backlog >= highWater || (maxDbLatency > 0 && dbLatency > maxDbLatency)
+
+	switch {
+	case overloaded && !throttled:
+		if err := o.suspendIngestion(&o.paused.throttled); err != nil {
+			logger.Error(err.Error())
+			return
+		}
+		ingestionThrottledGauge.Set(1)
+		logger.Warn("backpressure - suspend event ingestion")
+	case throttled && !overloaded && backlog <= lowWater:
+		o.releaseIngestion(&o.paused.throttled)
+		ingestionThrottledGauge.Set(0)
+		logger.Info("backlog drained - resume event ingestion")
+	}
+}
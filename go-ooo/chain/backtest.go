@@ -0,0 +1,189 @@
+package chain
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"go-ooo/ooo_api"
+	"math/big"
+)
+
+// BacktestResult compares the answer the current config gives for a historical request, as of
+// the block it was made in, with the answer submitted on chain
+type BacktestResult struct {
+	RequestId string
+	Endpoint  string
+	Block     uint64
+	Timestamp uint64
+	// Queried is the historical endpoint queried for the request's price at Timestamp
+	Queried   string
+	Answer    string
+	Sources   []string
+	Submitted string
+	// DeviationPct is how far Answer is from Submitted, as a percentage of Submitted
+	DeviationPct float64
+	// Error is why the request could not be answered, if it was not
+	Error string
+}
+
+// BacktestSummary counts the requests backtested, with the deviation of the answers from those
+// submitted, for the requests with both
+type BacktestSummary struct {
+	Requests         int
+	Answered         int
+	Compared         int
+	MeanDeviationPct float64
+	MaxDeviationPct  float64
+	Results          []BacktestResult
+}
+
+// Backtest answers each request made to the node between fromBlock and toBlock, or the latest
+// block if toBlock is 0, with the current sources and aggregation settings, using each source's
+// data as of the request's block time, and compares the answers with the fulfillments on chain.
+// Nothing is stored or sent
+func (o *OoORouterService) Backtest(fromBlock uint64, toBlock uint64) (BacktestSummary, error) {
+	var summary BacktestSummary
+
+	if toBlock == 0 {
+		currentBlockNum, err := o.client.BlockNumber(o.context)
+		if err != nil {
+			return summary, err
+		}
+		toBlock = currentBlockNum
+	}
+
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "Backtest",
+		"from_block": fromBlock,
+		"to_block":   toBlock,
+	})
+
+	logger.Info("begin backtest")
+
+	opts := *o.historicalFilterOpts
+	opts.Start = fromBlock
+	opts.End = &toBlock
+
+	me := o.providerAddresses()
+
+	itrFr, err := o.contractInstance.FilterRequestFulfilled(&opts, nil, me, nil)
+	if err != nil {
+		return summary, err
+	}
+	defer itrFr.Close()
+
+	submitted := make(map[string]*big.Int)
+	for itrFr.Next() {
+		submitted[common.Bytes2Hex(itrFr.Event.RequestId[:])] = itrFr.Event.RequestedData
+	}
+	if err = itrFr.Error(); err != nil {
+		return summary, err
+	}
+
+	itrDr, err := o.contractInstance.FilterDataRequested(&opts, nil, me, nil)
+	if err != nil {
+		return summary, err
+	}
+	defer itrDr.Close()
+
+	blockTimes := make(map[uint64]uint64)
+	totalDeviation := float64(0)
+
+	for itrDr.Next() {
+		summary.Requests++
+
+		result := BacktestResult{
+			RequestId: common.Bytes2Hex(itrDr.Event.RequestId[:]),
+			Endpoint:  string(common.TrimRightZeroes(itrDr.Event.Data[:])),
+			Block:     itrDr.Event.Raw.BlockNumber,
+		}
+		if s, ok := submitted[result.RequestId]; ok {
+			result.Submitted = s.String()
+		}
+
+		ts, ok := blockTimes[result.Block]
+		if !ok {
+			header, err := o.client.HeaderByNumber(o.context, new(big.Int).SetUint64(result.Block))
+			if err != nil {
+				return summary, err
+			}
+			ts = header.Time
+			blockTimes[result.Block] = ts
+		}
+		result.Timestamp = ts
+
+		o.backtestRequest(&result)
+
+		if result.Answer != "" {
+			summary.Answered++
+			if d, ok := deviationPct(result.Answer, result.Submitted); ok {
+				result.DeviationPct = d
+				summary.Compared++
+				totalDeviation += d
+				if d > summary.MaxDeviationPct {
+					summary.MaxDeviationPct = d
+				}
+			}
+		}
+
+		summary.Results = append(summary.Results, result)
+	}
+	if err = itrDr.Error(); err != nil {
+		return summary, err
+	}
+
+	if summary.Compared > 0 {
+		summary.MeanDeviationPct = totalDeviation / float64(summary.Compared)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"requests":           summary.Requests,
+		"answered":           summary.Answered,
+		"compared":           summary.Compared,
+		"mean_deviation_pct": summary.MeanDeviationPct,
+		"max_deviation_pct":  summary.MaxDeviationPct,
+	}).Info("backtest complete")
+
+	return summary, nil
+}
+
+// backtestRequest answers the result's request from the sources' data as of its timestamp
+func (o *OoORouterService) backtestRequest(result *BacktestResult) {
+	if rejection := o.oooApi.ValidateRequestEndpoint(result.Endpoint); rejection != nil {
+		result.Error = rejection.Error()
+		return
+	}
+
+	queried, err := ooo_api.HistoricalEndpoint(result.Endpoint, int64(result.Timestamp))
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+	result.Queried = queried
+
+	answer, sources, err := o.oooApi.QueryEndpoint(o.context, queried, result.RequestId)
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+	result.Answer = answer
+	result.Sources = sources
+}
+
+// deviationPct returns how far answer is from submitted, as a percentage of submitted
+func deviationPct(answer string, submitted string) (float64, bool) {
+	a, ok := new(big.Float).SetString(answer)
+	if !ok {
+		return 0, false
+	}
+	s, ok := new(big.Float).SetString(submitted)
+	if !ok || s.Sign() == 0 {
+		return 0, false
+	}
+
+	d := new(big.Float).Sub(a, s)
+	d.Abs(d).Quo(d, s).Mul(d, big.NewFloat(100))
+	pct, _ := d.Float64()
+
+	return pct, true
+}
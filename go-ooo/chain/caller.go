@@ -1,13 +1,22 @@
 package chain
 
 import (
+	"context"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"go-ooo/config"
 	"math/big"
+	"strings"
 )
 
+var nonceResyncsCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "ooo_nonce_resyncs_total",
+	Help: "The number of times the tracked tx nonce was resynchronised after the account's pending nonce jumped ahead of it",
+})
+
 func (o *OoORouterService) setNextTxNonce(nonce uint64, isFromPending bool) {
 	nextNonce := nonce
 	if !isFromPending {
@@ -26,34 +35,70 @@ func (o *OoORouterService) setNextTxNonce(nonce uint64, isFromPending bool) {
 			"is_pending": isFromPending,
 		}).Debug()
 
+		o.prevTxNonce = nextNonce
+		o.transactOpts.Nonce = big.NewInt(int64(nextNonce))
+	} else if isFromPending && nextNonce > o.prevTxNonce {
+		// the nonce jumped, e.g. the operator sent txs from the account outside go-ooo. Resync,
+		// rather than failing every later tx with nonce too low
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "setNextTxNonce",
+			"prev_nonce": o.prevTxNonce,
+			"next_nonce": nextNonce,
+			"gap":        nextNonce - o.prevTxNonce,
+		}).Warn("pending nonce jumped ahead - txs sent from the account outside go-ooo? resynchronising")
+
+		nonceResyncsCounter.Inc()
 		o.prevTxNonce = nextNonce
 		o.transactOpts.Nonce = big.NewInt(int64(nextNonce))
 	}
 }
 
+// isNonceTooLow returns true if a tx was rejected because its nonce has already been used
+func isNonceTooLow(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
 func (o *OoORouterService) RenewTransactOpts() error {
 
 	nonce, err := o.client.PendingNonceAt(o.context, o.oracleAddress)
 	if err != nil {
+		rpcError("PendingNonceAt")
 		return err
 	}
 
 	o.setNextTxNonce(nonce, true)
 
-	gasPrice, err := o.client.SuggestGasPrice(o.context)
+	gasPrice, err := o.suggestGasPrice(o.context)
 	if err != nil {
 		return err
 	}
 	o.transactOpts.GasPrice = gasPrice
 
+	return nil
+}
+
+// suggestGasPrice returns the node's suggested gas price, capped at chain.max_gas_price
+func (o *OoORouterService) suggestGasPrice(ctx context.Context) (*big.Int, error) {
+	gasPrice, err := o.client.SuggestGasPrice(ctx)
+	if err != nil {
+		rpcError("SuggestGasPrice")
+		return nil, err
+	}
+
+	return capGasPrice(gasPrice), nil
+}
+
+// capGasPrice returns gasPrice, or chain.max_gas_price if it is set and gasPrice exceeds it
+func capGasPrice(gasPrice *big.Int) *big.Int {
 	maxGasPriceConf := viper.GetInt64(config.ChainMaxGasPrice)
 
 	if maxGasPriceConf > 0 {
 		maxGasPrice := big.NewInt(0).Mul(big.NewInt(maxGasPriceConf), big.NewInt(params.GWei))
 		if gasPrice.Cmp(maxGasPrice) > 0 {
-			o.transactOpts.GasPrice = maxGasPrice
+			return maxGasPrice
 		}
 	}
 
-	return nil
+	return gasPrice
 }
@@ -0,0 +1,117 @@
+package chain
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	"go-ooo/errcodes"
+	"go-ooo/webhooks"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	CatchupModeAll    = "all"    // every request missed during downtime is fulfilled
+	CatchupModeRecent = "recent" // only missed requests within chain.catchup_max_age blocks of the head are fulfilled
+	CatchupModeHead   = "head"   // no missed requests are fulfilled - processing starts at the chain head
+)
+
+// defaultCatchupMaxAge - blocks behind the head a missed request may be and still be fulfilled, in
+// "recent" catch-up mode, if chain.catchup_max_age is not set
+const defaultCatchupMaxAge = 100
+
+var catchupSkippedRequests = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "ooo_catchup_skipped_total",
+	Help: "Number of requests missed during downtime which were skipped by the catch-up mode",
+})
+
+// catchupMode returns chain.catchup_mode, defaulting to "all"
+func catchupMode() string {
+	mode := strings.ToLower(viper.GetString(config.ChainCatchupMode))
+	if mode != CatchupModeRecent && mode != CatchupModeHead {
+		return CatchupModeAll
+	}
+	return mode
+}
+
+// startCatchup sets the block below which missed requests are skipped rather than fulfilled,
+// according to chain.catchup_mode and the current chain head, and skips the pending jobs, seen
+// before the downtime but not yet sent, which are older. Called before the backfill, each time
+// the node starts processing
+func (o *OoORouterService) startCatchup() {
+	mode := catchupMode()
+	atomic.StoreUint64(&o.catchupCutoff, 0)
+	if mode == CatchupModeAll {
+		return
+	}
+
+	head, err := o.client.BlockNumber(o.context)
+	if err != nil {
+		rpcError("BlockNumber")
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "startCatchup",
+			"mode":     mode,
+		}).Error("cannot get chain head - fulfilling all missed requests: ", err.Error())
+		return
+	}
+
+	// the head's own requests were made during the downtime too
+	cutoff := head + 1
+	if mode == CatchupModeRecent {
+		maxAge := uint64(defaultCatchupMaxAge)
+		if viper.IsSet(config.ChainCatchupMaxAge) {
+			maxAge = uint64(viper.GetInt64(config.ChainCatchupMaxAge))
+		}
+		cutoff = 0
+		if head > maxAge {
+			cutoff = head - maxAge
+		}
+	}
+	atomic.StoreUint64(&o.catchupCutoff, cutoff)
+
+	skipped := 0
+	jobs, _ := o.db.GetPendingJobs()
+	for _, job := range jobs {
+		// sent txs are left to be mined
+		if job.GetRequestStatus() == models.REQUEST_STATUS_TX_SENT || !o.missedInCatchup(job.GetRequestBlockNumber()) {
+			continue
+		}
+		o.skipMissedRequest(job.GetRequestId(), job.GetRequestBlockNumber())
+		skipped++
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":      "chain",
+		"function":     "startCatchup",
+		"mode":         mode,
+		"head":         head,
+		"cutoff_block": cutoff,
+		"pending_jobs": skipped,
+	}).Info("skipping missed requests made before the cutoff block")
+}
+
+// missedInCatchup returns true if a request made in blockNumber was missed during downtime, and is
+// too old to be fulfilled in the current catch-up mode
+func (o *OoORouterService) missedInCatchup(blockNumber uint64) bool {
+	return blockNumber < atomic.LoadUint64(&o.catchupCutoff)
+}
+
+// skipMissedRequest records that a request missed during downtime will not be fulfilled
+func (o *OoORouterService) skipMissedRequest(requestId string, blockNumber uint64) {
+	catchupSkippedRequests.Inc()
+	o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "skipMissedRequest",
+		"request_id": requestId,
+		"block_num":  blockNumber,
+	}).Warn("request missed during downtime - request will not be fulfilled")
+
+	_ = o.failRequest(requestId, models.REQUEST_STATUS_SKIPPED_CATCHUP,
+		errcodes.Errorf(errcodes.ErrRequestExpired, "missed during downtime - requested in block %d, before block %d, the oldest fulfilled in chain.catchup_mode %q",
+			blockNumber, atomic.LoadUint64(&o.catchupCutoff), catchupMode()))
+	o.recordJobEvent(webhooks.EventSkipped, requestId)
+}
@@ -13,14 +13,25 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"go-ooo/alerts"
 	"go-ooo/config"
+	"go-ooo/consensus"
 	"go-ooo/database"
+	"go-ooo/database/models"
+	"go-ooo/errcodes"
+	"go-ooo/ipfs"
 	"go-ooo/ooo_api"
 	"go-ooo/ooo_router"
-	"go-ooo/utils"
+	"go-ooo/signer"
+	"go-ooo/supervisor"
+	"go-ooo/tracing"
 	"go-ooo/utils/walletworker"
+	"go-ooo/vor"
+	"go-ooo/vor_coordinator"
+	"go-ooo/webhooks"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -38,12 +49,17 @@ type OoORouterService struct {
 	logRequestFulfilledHash common.Hash
 	contractAbi             abi.ABI
 
-	oracleAddress    common.Address
-	oraclePrivateKey *ecdsa.PrivateKey
+	oracleAddress common.Address
+	oracleSigner  signer.Signer
 
-	db *database.DB
+	// previous provider key, while a key rotation is in progress
+	retiring *retiringKey
+
+	db database.JobStore
 
 	oooApi *ooo_api.OOOApi
+	// recovers and reports panics in the service's goroutines
+	supervisor *supervisor.Supervisor
 
 	watchOpts            *bind.WatchOpts
 	chanDataRequests     chan *ooo_router.OooRouterDataRequested
@@ -54,15 +70,79 @@ type OoORouterService struct {
 
 	lastBlockNumber uint64
 
+	// requests made before this block, missed during downtime, are not fulfilled. See chain.catchup_mode
+	catchupCutoff uint64
+
 	subscriptionDr event.Subscription
 	subscriptionRf event.Subscription
 
 	prevTxNonce uint64
+
+	// serialises use of transactOpts and the tx nonce between job workers
+	txMu sync.Mutex
+
+	workers *jobWorkerPool
+
+	// signalled when new requests arrive, so the job queue is checked promptly
+	jobActivity chan struct{}
+
+	consumerRateLimit *consumerRateLimiter
+
+	// spaces out fulfillment tx broadcasts
+	pacer *submissionPacer
+
+	// 1 while a reconciliation is running
+	reconciling int32
+
+	paused pauseState
+
+	// xFUND price used by the profitability check
+	xfundPrice xfundPriceCache
+
+	heartbeats pairHeartbeats
+
+	// host clock skew, estimated from the chain head
+	clock clockMonitor
+
+	// on-chain conditions, such as a paused router, under which fulfillment is quiesced
+	conditions routerConditions
+
+	feeSchedule feeSchedule
+
+	webhooks    *webhooks.Notifier
+	ipfs        *ipfs.Publisher
+	eventStream *jobEventStream
+	alerter     *alerts.Alerter
+	tracer      *tracing.Tracer
+
+	// VOR randomness fulfillment, if enabled. vorKey is set if VOR has its own key
+	vorKey            *accountKey
+	vorInstance       *vor_coordinator.VorCoordinator
+	vorPrivateKey     *ecdsa.PrivateKey
+	vorPublicKey      vor.Point
+	vorKeyHash        common.Hash
+	chanVorRequests   chan *vor_coordinator.VorCoordinatorRandomnessRequest
+	chanVorFulfilled  chan *vor_coordinator.VorCoordinatorRandomnessRequestFulfilled
+	subscriptionVorRr event.Subscription
+	subscriptionVorRf event.Subscription
+
+	// additional events recorded in the chain_events table, if any chain.event_filters are set
+	eventFilters     []*eventFilter
+	chanFilteredLogs chan types.Log
+	subscriptionEf   event.Subscription
+
+	// push feed contracts answers are pushed to, if any
+	pushFeeds        []*pushFeed
+	pushFeedsRunning uint32
+
+	// checks answers against cooperating providers' observations, if peers are configured
+	consensus *consensus.Checker
 }
 
 func NewOoORouter(ctx context.Context, logger *logrus.Logger, client *ethclient.Client,
 	contractInstance *ooo_router.OooRouter, contractAddress common.Address,
-	oraclePrivateKey []byte, db *database.DB, oooApi *ooo_api.OOOApi) (*OoORouterService, error) {
+	signers Signers, db database.JobStore, oooApi *ooo_api.OOOApi, sup *supervisor.Supervisor) (*OoORouterService, error) {
+	oracleSigner := signers.Oracle
 
 	logDataRequestedHash := crypto.Keccak256Hash([]byte("DataRequested(address,address,uint256,bytes32,bytes32)"))
 	logRequestFulfilledHash := crypto.Keccak256Hash([]byte("RequestFulfilled(address,address,bytes32,uint256)"))
@@ -72,19 +152,7 @@ func NewOoORouter(ctx context.Context, logger *logrus.Logger, client *ethclient.
 		return nil, err
 	}
 
-	oraclePrivateKeyECDSA, err := crypto.HexToECDSA(utils.RemoveHexPrefix(string(oraclePrivateKey)))
-	if err != nil {
-		return nil, err
-	}
-
-	oraclePublicKey := oraclePrivateKeyECDSA.Public()
-
-	ECDSAoraclePublicKey, err := crypto.UnmarshalPubkey(crypto.FromECDSAPub(oraclePublicKey.(*ecdsa.PublicKey)))
-	if err != nil || ECDSAoraclePublicKey == nil {
-		return nil, err
-	}
-	_, oracleAddressStr := walletworker.GenerateAddress(ECDSAoraclePublicKey)
-	oracleAddress := common.HexToAddress(oracleAddressStr)
+	oracleAddress, oracleAddressStr := walletworker.GenerateAddress(oracleSigner.PublicKey())
 
 	logger.WithFields(logrus.Fields{
 		"package":  "chain",
@@ -92,7 +160,7 @@ func NewOoORouter(ctx context.Context, logger *logrus.Logger, client *ethclient.
 		"address":  oracleAddressStr,
 	}).Debug("set our wallet address")
 
-	transactOpts, err := bind.NewKeyedTransactorWithChainID(oraclePrivateKeyECDSA, big.NewInt(viper.GetInt64(config.ChainNetworkId)))
+	transactOpts, err := signer.NewTransactOpts(oracleSigner, big.NewInt(viper.GetInt64(config.ChainNetworkId)))
 	if err != nil {
 		return nil, err
 	}
@@ -142,7 +210,7 @@ func NewOoORouter(ctx context.Context, logger *logrus.Logger, client *ethclient.
 
 	historicalFilterOpts := &bind.FilterOpts{Context: ctx, Start: initialFromBlock, End: nil}
 
-	return &OoORouterService{
+	oooRouterService := &OoORouterService{
 		contractAddress:         contractAddress,
 		client:                  client,
 		contractInstance:        contractInstance,
@@ -156,14 +224,106 @@ func NewOoORouter(ctx context.Context, logger *logrus.Logger, client *ethclient.
 		callOpts:                callOpts,
 		db:                      db,
 		oooApi:                  oooApi,
-		oraclePrivateKey:        oraclePrivateKeyECDSA,
+		supervisor:              sup,
+		oracleSigner:            oracleSigner,
 		watchOpts:               watchOpts,
 		chanDataRequests:        chanDataRequests,
 		chanRequestFulfilled:    chanRequestFulfilled,
 		historicalFilterOpts:    historicalFilterOpts,
 		lastBlockNumber:         initialFromBlock,
 		prevTxNonce:             nonce,
-	}, nil
+		jobActivity:             make(chan struct{}, 1),
+	}
+
+	err = oooRouterService.initRetiringKey(signers.Retiring)
+	if err != nil {
+		return nil, err
+	}
+
+	err = oooRouterService.initVorKey(signers.Vor)
+	if err != nil {
+		return nil, err
+	}
+
+	err = oooRouterService.initVor()
+	if err != nil {
+		return nil, err
+	}
+
+	err = oooRouterService.initEventFilters()
+	if err != nil {
+		return nil, err
+	}
+
+	err = oooRouterService.initPushFeeds()
+	if err != nil {
+		return nil, err
+	}
+
+	err = oooRouterService.initConsensus()
+	if err != nil {
+		return nil, err
+	}
+
+	numWorkers := viper.GetInt(config.JobsWorkers)
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+	oooRouterService.webhooks = webhooks.NewNotifier(ctx, logger,
+		viper.GetStringSlice(config.WebhooksUrls),
+		viper.GetStringSlice(config.WebhooksEvents),
+		viper.GetString(config.WebhooksSecret),
+		time.Duration(viper.GetInt64(config.WebhooksTimeout))*time.Second,
+	)
+	oooRouterService.ipfs = ipfs.NewPublisher(ctx, logger, ipfsConfig(), oooRouterService.attestationPublished)
+	oooRouterService.eventStream = newJobEventStream()
+	oooRouterService.alerter = alerts.NewAlerter(ctx, logger, alertsConfig())
+	oooRouterService.tracer = tracing.NewTracer(ctx, logger, tracing.Config{
+		Endpoint:    viper.GetString(config.TracingOtlpEndpoint),
+		Headers:     viper.GetStringMapString(config.TracingHeaders),
+		ServiceName: viper.GetString(config.TracingServiceName),
+		SampleRatio: viper.GetFloat64(config.TracingSampleRatio),
+	})
+	initMetricLabels()
+	oooRouterService.RestoreMetrics()
+	oooRouterService.consumerRateLimit = newConsumerRateLimiter(viper.GetInt(config.JobsConsumerRateLimit), time.Hour)
+	oooRouterService.pacer = newSubmissionPacer(viper.GetFloat64(config.JobsSubmissionRate))
+	oooRouterService.workers = newJobWorkerPool(numWorkers)
+	oooRouterService.startJobWorkers(numWorkers)
+
+	return oooRouterService, nil
+}
+
+// alertsConfig returns the alerter's config
+func alertsConfig() alerts.Config {
+	if !config.FeatureEnabled(config.FeaturesAlerts) {
+		// no sinks - nothing is alerted
+		return alerts.Config{}
+	}
+	return alerts.Config{
+		TelegramBotToken:    viper.GetString(config.AlertsTelegramBotToken),
+		TelegramChatId:      viper.GetString(config.AlertsTelegramChatId),
+		SlackWebhookUrl:     viper.GetString(config.AlertsSlackWebhookUrl),
+		WebhookUrl:          viper.GetString(config.AlertsWebhookUrl),
+		WebhookSecret:       viper.GetString(config.AlertsWebhookSecret),
+		PagerDutyRoutingKey: viper.GetString(config.AlertsPagerDutyRoutingKey),
+		Email: alerts.EmailConfig{
+			Host:            viper.GetString(config.AlertsSmtpHost),
+			Port:            viper.GetInt(config.AlertsSmtpPort),
+			Username:        viper.GetString(config.AlertsSmtpUsername),
+			Password:        viper.GetString(config.AlertsSmtpPassword),
+			From:            viper.GetString(config.AlertsSmtpFrom),
+			To:              viper.GetStringSlice(config.AlertsSmtpTo),
+			Tls:             viper.GetString(config.AlertsSmtpTls),
+			SubjectTemplate: viper.GetString(config.AlertsSmtpSubjectTemplate),
+			BodyTemplate:    viper.GetString(config.AlertsSmtpBodyTemplate),
+			BatchWindow:     time.Duration(viper.GetInt64(config.AlertsSmtpBatchWindow)) * time.Second,
+		},
+		Routes:     viper.GetStringMapStringSlice(config.AlertsRoutes),
+		Severities: viper.GetStringMapString(config.AlertsSeverities),
+		Cooldown:   time.Duration(viper.GetInt64(config.AlertsCooldown)) * time.Second,
+		Timeout:    time.Duration(viper.GetInt64(config.AlertsTimeout)) * time.Second,
+	}
 }
 
 func (o *OoORouterService) setLastBlockNumber(blockNumber uint64) {
@@ -176,6 +336,7 @@ func (o *OoORouterService) setLastBlockNumber(blockNumber uint64) {
 		}).Debug("set last block number in db")
 
 		o.lastBlockNumber = blockNumber
+		lastProcessedBlockGauge.Set(float64(blockNumber))
 		err := o.db.InsertNewToBlock(blockNumber)
 
 		if err != nil {
@@ -220,52 +381,21 @@ func (o *OoORouterService) Shutdown() {
 		}).Info("unsubscribe from RequestFulfilled events")
 		o.subscriptionRf.Unsubscribe()
 	}
-}
-
-func (o *OoORouterService) GetHistoricalEvents() {
-
-	o.logger.WithFields(logrus.Fields{
-		"package":  "chain",
-		"function": "GetHistoricalEvents",
-	}).Info("get event history")
-
-	me := make([]common.Address, 0, 1)
-	me = append(me, o.oracleAddress)
-
-	itrDr, err := o.contractInstance.FilterDataRequested(o.historicalFilterOpts, nil, me, nil)
-
-	if err != nil {
+	if o.subscriptionVorRr != nil {
 		o.logger.WithFields(logrus.Fields{
 			"package":  "chain",
-			"function": "GetHistoricalEvents",
-			"action":   "get FilterDataRequested events",
-		}).Error(err.Error())
-
-		return
-	}
-
-	for itrDr.Next() {
-		ev := itrDr.Event
-		o.processIncomingRequests(ev)
+			"function": "Shutdown",
+		}).Info("unsubscribe from VOR events")
+		o.subscriptionVorRr.Unsubscribe()
+		o.subscriptionVorRf.Unsubscribe()
 	}
-
-	itrFr, err := o.contractInstance.FilterRequestFulfilled(o.historicalFilterOpts, nil, me, nil)
-
-	if err != nil {
+	if o.subscriptionEf != nil {
 		o.logger.WithFields(logrus.Fields{
 			"package":  "chain",
-			"function": "GetHistoricalEvents",
-			"action":   "get FilterRequestFulfilled events",
-		}).Error(err.Error())
-
-		return
-	}
-
-	for itrFr.Next() {
-		ev := itrFr.Event
-		o.processIncomingFulfilments(ev)
+			"function": "Shutdown",
+		}).Info("unsubscribe from event filters")
+		o.subscriptionEf.Unsubscribe()
 	}
-
 }
 
 func (o *OoORouterService) subscribeToDataRequested(me []common.Address) {
@@ -344,8 +474,7 @@ func (o *OoORouterService) RunEventWatchers() {
 		"function": "RunEventWatchers",
 	}).Info("initialise event subscriptions")
 
-	me := make([]common.Address, 0, 1)
-	me = append(me, o.oracleAddress)
+	me := o.providerAddresses()
 
 	o.subscribeToDataRequested(me)
 	defer o.subscriptionDr.Unsubscribe()
@@ -356,9 +485,13 @@ func (o *OoORouterService) RunEventWatchers() {
 	for {
 		select {
 		case ev := <-o.chanDataRequests:
-			o.processIncomingRequests(ev)
+			if !o.ingestionPaused() {
+				o.processIncomingRequests(ev)
+			}
 		case ev := <-o.chanRequestFulfilled:
-			o.processIncomingFulfilments(ev)
+			if !o.ingestionPaused() {
+				o.processIncomingFulfilments(ev)
+			}
 		case subErr := <-o.subscriptionDr.Err():
 			if subErr != nil {
 				o.logger.WithFields(logrus.Fields{
@@ -366,6 +499,7 @@ func (o *OoORouterService) RunEventWatchers() {
 					"function": "RunEventWatchers",
 					"action":   "DataRequested subscription connection error",
 				}).Error(subErr.Error())
+				rpcError("WatchDataRequested")
 
 				o.subscribeToDataRequested(me)
 			}
@@ -377,6 +511,7 @@ func (o *OoORouterService) RunEventWatchers() {
 					"function": "RunEventWatchers",
 					"action":   "RequestFulfilled subscription connection error",
 				}).Error(subErr.Error())
+				rpcError("WatchRequestFulfilled")
 				o.subscribeToRequestFulfilled(me)
 			}
 		}
@@ -384,23 +519,61 @@ func (o *OoORouterService) RunEventWatchers() {
 }
 
 func (o *OoORouterService) processIncomingRequests(event *ooo_router.OooRouterDataRequested) {
-	consumer := event.Consumer
-	provider := event.Provider
-	requestId := common.Bytes2Hex(event.RequestId[:])
-	endpointStr := string(common.TrimRightZeroes(event.Data[:]))
+	o.ingestRequests([]*ooo_router.OooRouterDataRequested{event})
+}
 
-	o.logger.WithFields(logrus.Fields{
-		"package":   "chain",
-		"function":  "processIncomingRequests",
-		"requestId": requestId,
-	}).Info("got data request event for me")
+// ingestRequests adds the requests in events which are not yet in the db as new jobs, in a
+// single batch insert, then screens each new job. events must be in block order
+func (o *OoORouterService) ingestRequests(events []*ooo_router.OooRouterDataRequested) {
+	if len(events) == 0 {
+		return
+	}
 
-	gasPrice, gasUsed := o.processGasUsage(event.Raw)
+	var newEvents []*ooo_router.OooRouterDataRequested
+	var newRequests []models.DataRequests
+	var spans []*tracing.Span
 
-	// check status and if requests already exists
-	reqDbRes, _ := o.db.FindByRequestId(requestId)
+	for _, event := range events {
+		consumer := event.Consumer
+		provider := event.Provider
+		requestId := common.Bytes2Hex(event.RequestId[:])
+		endpointStr := string(common.TrimRightZeroes(event.Data[:]))
+
+		o.logger.WithFields(logrus.Fields{
+			"package":   "chain",
+			"function":  "processIncomingRequests",
+			"requestId": requestId,
+		}).Info("got data request event for me")
+
+		_, span := o.tracer.StartRequestSpan(o.context, requestId, "ingest")
+		span.SetAttribute("request_id", requestId)
+		span.SetAttribute("consumer", consumer.Hex())
+		span.SetAttribute("endpoint", endpointStr)
+		span.SetAttribute("fee", event.Fee.Uint64())
+		span.SetAttribute("block_number", event.Raw.BlockNumber)
+		span.SetAttribute("tx_hash", event.Raw.TxHash.Hex())
+
+		gasPrice, gasUsed := o.processGasUsage(event.Raw)
+
+		// check status and if requests already exists
+		reqDbRes, _ := o.db.FindByRequestId(requestId)
+
+		if reqDbRes.ID != 0 || requestInBatch(newRequests, requestId) {
+			span.SetAttribute("outcome", "duplicate")
+			o.logger.WithFields(logrus.Fields{
+				"package":    "chainlisten",
+				"function":   "ProcessIncommingEvents",
+				"action":     "check db for request",
+				"request_id": requestId,
+				"status":     reqDbRes.GetRequestStatusString(),
+			}).Info("request already in db")
+			span.End()
+			continue
+		}
+
+		o.journal(models.JOURNAL_KIND_REQUEST_RECEIVED, requestId, event.Raw.TxHash.Hex(), 0, "",
+			event.Raw.BlockNumber, endpointStr)
 
-	if reqDbRes.ID == 0 {
 		o.logger.WithFields(logrus.Fields{
 			"package":   "chain",
 			"function":  "processIncomingRequests",
@@ -422,31 +595,138 @@ func (o *OoORouterService) processIncomingRequests(event *ooo_router.OooRouterDa
 			isAdHoc = false
 		}
 
-		_ = o.db.InsertNewRequest(
-			provider.Hex(),
-			consumer.Hex(),
-			requestId,
-			common.Bytes2Hex(event.Data[:]),
-			endpointStr,
-			event.Raw.TxHash.Hex(),
-			gasUsed,
-			gasPrice,
-			event.Fee.Uint64(),
-			event.Raw.BlockNumber,
-			isAdHoc,
-		)
-	} else {
+		newRequests = append(newRequests, models.DataRequests{
+			Provider:           provider.Hex(),
+			Consumer:           consumer.Hex(),
+			RequestId:          requestId,
+			Endpoint:           common.Bytes2Hex(event.Data[:]),
+			EndpointDecoded:    endpointStr,
+			RequestTxHash:      event.Raw.TxHash.Hex(),
+			RequestGasUsed:     gasUsed,
+			RequestGasPrice:    gasPrice,
+			RequestBlockNumber: event.Raw.BlockNumber,
+			Fee:                event.Fee.Uint64(),
+			IsAdhoc:            isAdHoc,
+		})
+		newEvents = append(newEvents, event)
+		spans = append(spans, span)
+	}
+
+	insertErrs := o.insertNewRequests(newRequests)
+
+	for i, event := range newEvents {
+		spans[i].SetError(insertErrs[i])
+		o.screenNewRequest(event, spans[i])
+		spans[i].End()
+	}
+
+	o.setLastBlockNumber(events[len(events)-1].Raw.BlockNumber)
+}
+
+// insertNewRequests adds requests to the db in one batch. If the batch cannot be inserted, e.g.
+// as one of the requests was added by the event watchers meanwhile, the requests are inserted
+// one at a time instead. Returns the error inserting each request, if any
+func (o *OoORouterService) insertNewRequests(requests []models.DataRequests) []error {
+	errs := make([]error, len(requests))
+	if len(requests) == 0 {
+		return errs
+	}
+
+	err := o.db.InsertNewRequests(requests)
+	if err == nil || len(requests) == 1 {
+		errs[0] = err
+		return errs
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "insertNewRequests",
+		"action":   "insert batch",
+		"requests": len(requests),
+	}).Warn("batch insert failed - inserting requests individually: " + err.Error())
+
+	for i := range requests {
+		errs[i] = o.db.InsertNewRequests(requests[i : i+1])
+	}
+
+	return errs
+}
+
+func requestInBatch(requests []models.DataRequests, requestId string) bool {
+	for _, r := range requests {
+		if r.RequestId == requestId {
+			return true
+		}
+	}
+	return false
+}
+
+// screenNewRequest rejects or skips a newly added request which will not be fulfilled
+func (o *OoORouterService) screenNewRequest(event *ooo_router.OooRouterDataRequested, span *tracing.Span) {
+	provider := event.Provider
+	requestId := common.Bytes2Hex(event.RequestId[:])
+	endpointStr := string(common.TrimRightZeroes(event.Data[:]))
+
+	if o.missedInCatchup(event.Raw.BlockNumber) {
+		span.SetAttribute("outcome", "skipped")
+		o.skipMissedRequest(requestId, event.Raw.BlockNumber)
+		return
+	}
+
+	if o.isRetiredProvider(provider) {
 		o.logger.WithFields(logrus.Fields{
-			"package":    "chainlisten",
-			"function":   "ProcessIncommingEvents",
-			"action":     "check db for request",
-			"request_id": reqDbRes.RequestId,
-			"status":     reqDbRes.GetRequestStatusString(),
-		}).Info("request already in db")
+			"package":    "chain",
+			"function":   "processIncomingRequests",
+			"action":     "check provider",
+			"request_id": requestId,
+			"provider":   provider.Hex(),
+		}).Warn("request to the retiring key after its grace window - request will not be fulfilled")
+
+		span.SetAttribute("outcome", "rejected")
+		_ = o.failRequest(requestId, models.REQUEST_STATUS_REJECTED,
+			errcodes.Errorf(errcodes.ErrProviderRetired, "provider key %s is being retired - use %s", provider.Hex(), o.oracleAddress.Hex()))
+		o.recordJobEvent(webhooks.EventSkipped, requestId)
+		return
 	}
 
-	o.setLastBlockNumber(event.Raw.BlockNumber)
+	if rejection := o.oooApi.ValidateRequestEndpoint(endpointStr); rejection != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":        "chain",
+			"function":       "processIncomingRequests",
+			"action":         "validate endpoint",
+			"request_id":     requestId,
+			"endpoint":       endpointStr,
+			"rejection_code": rejection.Code,
+			"error_code":     rejection.ErrorCode(),
+		}).Warn(rejection.Reason)
+
+		span.SetAttribute("outcome", "rejected")
+		jobFailures.WithLabelValues(string(rejection.ErrorCode()), "failed").Inc()
+		_ = o.db.UpdateRequestRejected(requestId, rejection.Code, string(rejection.ErrorCode()), rejection.Reason)
+		o.recordJobEvent(webhooks.EventSkipped, requestId)
+		return
+	}
 
+	minFee := viper.GetUint64(config.JobsMinFee)
+	// compared as big ints, since fees of 2^64 or more do not fit in a uint64
+	if event.Fee.Cmp(new(big.Int).SetUint64(minFee)) < 0 {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "processIncomingRequests",
+			"action":     "check fee",
+			"request_id": requestId,
+			"fee":        event.Fee.String(),
+			"min_fee":    minFee,
+		}).Warn("fee below minimum - request will not be fulfilled")
+
+		span.SetAttribute("outcome", "low_fee")
+		_ = o.failRequest(requestId, models.REQUEST_STATUS_SKIPPED_LOW_FEE,
+			errcodes.Errorf(errcodes.ErrFeeTooLow, "fee %s below minimum %d", event.Fee, minFee))
+		o.recordJobEvent(webhooks.EventSkipped, requestId)
+	} else {
+		span.SetAttribute("outcome", "received")
+		o.recordJobEvent(webhooks.EventReceived, requestId)
+	}
 }
 
 func (o *OoORouterService) processIncomingFulfilments(event *ooo_router.OooRouterRequestFulfilled) {
@@ -459,11 +739,22 @@ func (o *OoORouterService) processIncomingFulfilments(event *ooo_router.OooRoute
 		"requestId": requestId,
 	}).Info("got request fulfilment event for me")
 
+	_, span := o.tracer.StartRequestSpan(o.context, requestId, "fulfilled")
+	defer span.End()
+	span.SetAttribute("request_id", requestId)
+	span.SetAttribute("block_number", event.Raw.BlockNumber)
+	span.SetAttribute("tx_hash", event.Raw.TxHash.Hex())
+
 	gasPrice, gasUsed := o.processGasUsage(event.Raw)
+	span.SetAttribute("gas_used", gasUsed)
+	span.SetAttribute("gas_price", gasPrice)
 	// check status and if requests already exists
 	reqDbRes, _ := o.db.FindByRequestId(requestId)
 
 	if reqDbRes.ID != 0 {
+		o.journal(models.JOURNAL_KIND_REQUEST_FULFILLED, requestId, event.Raw.TxHash.Hex(), 0, "",
+			event.Raw.BlockNumber, event.RequestedData.String())
+
 		o.logger.WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processIncomingFulfilments",
@@ -478,12 +769,19 @@ func (o *OoORouterService) processIncomingFulfilments(event *ooo_router.OooRoute
 			gasUsed,
 			gasPrice,
 		)
+		o.recordGasSpend(requestId, reqDbRes.GetRequestBlockNumber(), event.Raw.TxHash, event.Raw.BlockNumber, gasUsed, true)
 		if err != nil {
 			o.logger.WithFields(logrus.Fields{
 				"package":  "chain",
 				"function": "processIncomingFulfilments",
 				"action":   "UpdateFulfillmentSuccess",
 			}).Error(err.Error())
+			span.SetError(err)
+		} else {
+			if reqDbRes.GetTxMinedAt() == 0 {
+				o.observeFulfillmentLatency(requestId)
+			}
+			o.recordJobEvent(webhooks.EventFulfilled, requestId)
 		}
 	}
 
@@ -0,0 +1,134 @@
+package chain
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/alerts"
+	"go-ooo/config"
+	"sync"
+	"time"
+)
+
+var clockSkewGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "ooo_clock_skew_seconds",
+	Help: "Estimated offset of the host clock from the eth node's block timestamps. Positive is ahead of the chain",
+})
+
+// clockMonitor estimates the host clock's skew from the chain head, sampled once a minute. A
+// head timestamped in the future means the clock is behind. A head older than one block
+// interval, while the chain is still producing blocks, means the clock is ahead - if the chain
+// has stopped producing blocks, it is the block lag which is reported instead
+type clockMonitor struct {
+	mu        sync.Mutex
+	lastBlock uint64
+	lastTime  uint64
+	skew      time.Duration
+	measured  bool
+}
+
+// defaultMaxClockSkew - seconds of clock skew alerted, if alerts.max_clock_skew is not set
+const defaultMaxClockSkew = 30
+
+// MaxClockSkew returns the skew above which the clock is alerted and the node is reported as not
+// ready. Zero disables the check
+func MaxClockSkew() time.Duration {
+	if !viper.IsSet(config.AlertsMaxClockSkew) {
+		return defaultMaxClockSkew * time.Second
+	}
+	return time.Duration(viper.GetInt64(config.AlertsMaxClockSkew)) * time.Second
+}
+
+// ClockSkew returns the last estimated skew of the host clock, and false if it could not be
+// estimated yet
+func (o *OoORouterService) ClockSkew() (time.Duration, bool) {
+	o.clock.mu.Lock()
+	defer o.clock.mu.Unlock()
+	return o.clock.skew, o.clock.measured
+}
+
+// checkClockSkew validates the head's timestamp against the previous head's, and the host clock
+// read at now, alerting if the skew exceeds MaxClockSkew
+func (o *OoORouterService) checkClockSkew(header *types.Header, now time.Time) {
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "checkClockSkew",
+		"block":    header.Number.Uint64(),
+	})
+
+	number := header.Number.Uint64()
+	age := now.Sub(time.Unix(int64(header.Time), 0))
+
+	o.clock.mu.Lock()
+	lastBlock, lastTime := o.clock.lastBlock, o.clock.lastTime
+	if number > lastBlock {
+		o.clock.lastBlock = number
+		o.clock.lastTime = header.Time
+	}
+	o.clock.mu.Unlock()
+
+	if number > lastBlock && lastBlock > 0 && header.Time < lastTime {
+		logger.WithFields(logrus.Fields{
+			"timestamp":          header.Time,
+			"previous_block":     lastBlock,
+			"previous_timestamp": lastTime,
+		}).Warn("block timestamp is earlier than a previous block's")
+	}
+
+	var skew time.Duration
+	switch {
+	case age < 0:
+		skew = age
+	case number > lastBlock && lastBlock > 0:
+		// the chain is producing blocks, so the head should be at most one interval old
+		interval := time.Duration(0)
+		if header.Time > lastTime {
+			interval = time.Duration((header.Time-lastTime)/(number-lastBlock)) * time.Second
+		}
+		if age > interval {
+			skew = age - interval
+		}
+	default:
+		// the head has not moved since the last check - a stalled chain, not a drifting clock
+		return
+	}
+
+	o.clock.mu.Lock()
+	o.clock.skew = skew
+	o.clock.measured = true
+	o.clock.mu.Unlock()
+
+	clockSkewGauge.Set(skew.Seconds())
+
+	maxSkew := MaxClockSkew()
+	if maxSkew <= 0 {
+		return
+	}
+
+	if skew > maxSkew || skew < -maxSkew {
+		msg := fmt.Sprintf("host clock is %s %s the chain's block timestamps, max %s - staleness checks and TWAP windows are unreliable",
+			absDuration(skew).String(), skewDirection(skew), maxSkew.String())
+		logger.WithField("skew", skew.String()).Warn(msg)
+		o.alerter.Alert(alerts.AlertClockSkew, "", msg)
+		return
+	}
+
+	o.alerter.Resolve(alerts.AlertClockSkew, "")
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func skewDirection(skew time.Duration) string {
+	if skew < 0 {
+		return "behind"
+	}
+	return "ahead of"
+}
@@ -0,0 +1,179 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/alerts"
+	"go-ooo/config"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reasons fulfillment is quiesced for, used as the alert key and metric label
+const (
+	QuiesceProviderDeregistered = "provider_deregistered"
+	QuiesceRouterPaused         = "router_paused"
+)
+
+// defaultConditionCheckInterval - seconds between checks of the router conditions, if
+// chain.condition_check_interval is not set
+const defaultConditionCheckInterval = 60
+
+// routerPausedSelector - paused(), as implemented by OpenZeppelin's Pausable. The current Router
+// is not pausable, and returns no data, but Router deployments which are pausable are detected
+var routerPausedSelector = common.FromHex("0x5c975abb")
+
+var fulfillmentQuiescedGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "ooo_fulfillment_quiesced",
+	Help: "1 while request fulfillment is suspended because fulfillment txs would revert, by reason",
+}, []string{"reason"})
+
+// routerConditions holds the on-chain conditions under which every fulfillment tx would revert,
+// found by the last check. Fulfillment is quiesced while any hold
+type routerConditions struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	quiesced  map[string]string
+}
+
+func conditionCheckInterval() time.Duration {
+	if !viper.IsSet(config.ChainConditionCheckInterval) {
+		return defaultConditionCheckInterval * time.Second
+	}
+	return time.Duration(viper.GetInt64(config.ChainConditionCheckInterval)) * time.Second
+}
+
+// fulfillmentQuiesced returns the conditions fulfillment is quiesced for, or an empty string
+func (o *OoORouterService) fulfillmentQuiesced() string {
+	o.conditions.mu.Lock()
+	defer o.conditions.mu.Unlock()
+
+	reasons := make([]string, 0, len(o.conditions.quiesced))
+	for reason := range o.conditions.quiesced {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	return strings.Join(reasons, ", ")
+}
+
+// CheckRouterConditions checks whether the oracle key is still registered as a provider, and
+// whether the router is paused, at most once per chain.condition_check_interval unless force
+// is set. Router fulfillment is quiesced, with an alert, while either condition holds rather
+// than sending txs which are certain to revert, and resumes once they clear
+func (o *OoORouterService) CheckRouterConditions(force bool) {
+	interval := conditionCheckInterval()
+	if interval <= 0 {
+		return
+	}
+
+	o.conditions.mu.Lock()
+	defer o.conditions.mu.Unlock()
+
+	if !force && time.Since(o.conditions.checkedAt) < interval {
+		return
+	}
+	o.conditions.checkedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(o.context, 10*time.Second)
+	defer cancel()
+
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "CheckRouterConditions",
+	})
+
+	found := make(map[string]string)
+	checked := make(map[string]bool)
+
+	callOpts := *o.callOpts
+	callOpts.Context = ctx
+	fee, err := o.contractInstance.GetProviderMinFee(&callOpts, o.oracleAddress)
+	if err != nil {
+		rpcError("GetProviderMinFee")
+		logger.Warn("cannot check provider registration: ", err.Error())
+	} else {
+		checked[QuiesceProviderDeregistered] = true
+		if fee.Sign() == 0 {
+			found[QuiesceProviderDeregistered] = fmt.Sprintf("provider %s is not registered with the router %s",
+				o.oracleAddress.Hex(), o.contractAddress.Hex())
+		}
+	}
+
+	paused, err := o.routerPaused(ctx)
+	if err != nil {
+		logger.Warn("cannot check whether the router is paused: ", err.Error())
+	} else {
+		checked[QuiesceRouterPaused] = true
+		if paused {
+			found[QuiesceRouterPaused] = fmt.Sprintf("router %s is paused", o.contractAddress.Hex())
+		}
+	}
+
+	if o.conditions.quiesced == nil {
+		o.conditions.quiesced = make(map[string]string)
+	}
+
+	for reason, message := range found {
+		if _, ok := o.conditions.quiesced[reason]; !ok {
+			logger.WithField("reason", reason).Warn(message + " - fulfillment quiesced")
+		}
+		o.conditions.quiesced[reason] = message
+		fulfillmentQuiescedGauge.WithLabelValues(reason).Set(1)
+		o.alerter.Alert(alerts.AlertFulfillmentQuiesced, reason,
+			message+" - fulfillment txs would revert, so requests are held until this clears")
+	}
+
+	// a condition which could not be checked is left as it was
+	resumed := false
+	for reason := range o.conditions.quiesced {
+		if _, ok := found[reason]; ok || !checked[reason] {
+			continue
+		}
+		delete(o.conditions.quiesced, reason)
+		fulfillmentQuiescedGauge.WithLabelValues(reason).Set(0)
+		o.alerter.Resolve(alerts.AlertFulfillmentQuiesced, reason)
+		logger.WithField("reason", reason).Info("condition cleared - fulfillment resumed")
+		resumed = true
+	}
+
+	if resumed && len(o.conditions.quiesced) == 0 {
+		o.signalJobActivity()
+	}
+}
+
+// routerPaused returns true if the router implements paused(), and it returns true
+func (o *OoORouterService) routerPaused(ctx context.Context) (bool, error) {
+	out, err := o.client.CallContract(ctx, ethereum.CallMsg{
+		To:   &o.contractAddress,
+		Data: routerPausedSelector,
+	}, nil)
+	if err != nil {
+		// no paused() - the call reverts in the fallback-less Router
+		if strings.Contains(err.Error(), "revert") {
+			return false, nil
+		}
+		rpcError("paused")
+		return false, err
+	}
+
+	if len(out) != 32 {
+		return false, nil
+	}
+
+	return new(big.Int).SetBytes(out).Sign() != 0, nil
+}
+
+// isRevert returns true if err is a tx, or its gas estimate, being reverted by the contract
+func isRevert(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "revert")
+}
@@ -0,0 +1,188 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/alerts"
+	"go-ooo/config"
+	"go-ooo/consensus"
+	"go-ooo/database/models"
+	"go-ooo/errcodes"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var consensusChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ooo_consensus_checks_total",
+	Help: "Number of answers checked against peer consensus, by pair and outcome - agreed, deviated, withheld or no_quorum",
+}, []string{"pair", "outcome"})
+
+// LoadConsensusPeers returns the consensus.peers config, checking each peer is complete
+func LoadConsensusPeers() ([]consensus.Peer, error) {
+	var peers []consensus.Peer
+	var err error
+	if raw, ok := viper.Get(config.ConsensusPeers).(string); ok {
+		// set by an environment variable, as a JSON array of peers
+		err = json.Unmarshal([]byte(raw), &peers)
+	} else {
+		err = viper.UnmarshalKey(config.ConsensusPeers, &peers)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for i, p := range peers {
+		if p.Name == "" {
+			return nil, fmt.Errorf("peer %d requires a name", i)
+		}
+		if names[p.Name] {
+			return nil, fmt.Errorf("duplicate peer %s", p.Name)
+		}
+		names[p.Name] = true
+
+		if u, err := url.Parse(p.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("peer %s: url %q must be an http or https url", p.Name, p.Url)
+		}
+		if !common.IsHexAddress(p.Address) {
+			return nil, fmt.Errorf("peer %s: address %q is not an address", p.Name, p.Address)
+		}
+	}
+
+	return peers, nil
+}
+
+// consensusConfig returns the consensus checker's config
+func consensusConfig(peers []consensus.Peer) consensus.Config {
+	cfg := consensus.Config{
+		Peers:        peers,
+		MaxDeviation: consensusMaxDeviation(),
+		MinPeers:     viper.GetInt(config.ConsensusMinPeers),
+		MaxAge:       time.Duration(viper.GetInt64(config.ConsensusMaxAge)) * time.Second,
+		Timeout:      time.Duration(viper.GetInt64(config.ConsensusTimeout)) * time.Second,
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = time.Minute
+	}
+	return cfg
+}
+
+// consensusMaxDeviation returns consensus.max_deviation, or 1%, if it is not in the config
+func consensusMaxDeviation() float64 {
+	if !viper.IsSet(config.ConsensusMaxDeviation) {
+		return 1
+	}
+	return viper.GetFloat64(config.ConsensusMaxDeviation)
+}
+
+// initConsensus sets up the exchange of observations with the configured peers, if any
+func (o *OoORouterService) initConsensus() error {
+	peers, err := LoadConsensusPeers()
+	if err != nil {
+		return fmt.Errorf("%s: %s", config.ConsensusPeers, err.Error())
+	}
+
+	fetch := func(ctx context.Context, endpoint string) (string, error) {
+		if rejection := o.oooApi.ValidateRequestEndpoint(endpoint); rejection != nil {
+			return "", rejection
+		}
+		price, _, err := o.oooApi.QueryEndpoint(ctx, endpoint, "consensus")
+		return price, err
+	}
+
+	o.consensus = consensus.NewChecker(consensusConfig(peers), o.oracleAddress, o.oracleSigner.SignText, fetch)
+
+	if o.consensus.Enabled() {
+		o.logger.WithFields(logrus.Fields{
+			"package":       "chain",
+			"function":      "initConsensus",
+			"peers":         len(peers),
+			"max_deviation": consensusMaxDeviation(),
+			"withhold":      viper.GetBool(config.ConsensusWithhold),
+		}).Info("peer consensus check enabled")
+	}
+
+	return nil
+}
+
+// Consensus returns the peer consensus checker, to serve the node's observations to peers
+func (o *OoORouterService) Consensus() *consensus.Checker {
+	return o.consensus
+}
+
+// withholdForConsensus checks the answer for a job against the peers' observations, flagging it
+// if it deviates from their median. Returns true if the answer deviates and consensus.withhold is
+// set, in which case the job is failed, to be retried later
+func (o *OoORouterService) withholdForConsensus(ctx context.Context, job models.DataRequests, price string) bool {
+	if !o.consensus.Enabled() {
+		return false
+	}
+
+	requestId := job.GetRequestId()
+	endpoint := job.GetEndpointDecoded()
+	pair := pairLabels.value(latencyPair(job))
+
+	// peers checking their answers at the same time are served this one
+	if _, err := o.consensus.Observe(endpoint, price); err != nil {
+		o.jobLogger(job).WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "withholdForConsensus",
+			"action":     "sign observation",
+			"request_id": requestId,
+		}).Error(err.Error())
+	}
+
+	res := o.consensus.Check(ctx, endpoint, price)
+
+	logger := o.jobLogger(job).WithFields(logrus.Fields{
+		"package":       "chain",
+		"function":      "withholdForConsensus",
+		"request_id":    requestId,
+		"endpoint":      endpoint,
+		"price":         price,
+		"median":        res.Median,
+		"deviation_pct": res.DeviationPct,
+	})
+
+	if !res.Quorum {
+		var errs []string
+		for _, p := range res.Peers {
+			if p.Error != "" {
+				errs = append(errs, p.Peer+": "+p.Error)
+			}
+		}
+		logger.WithFields(logrus.Fields{
+			"peer_errors": strings.Join(errs, "; "),
+		}).Warn("too few peer observations to check answer")
+		consensusChecks.WithLabelValues(pair, "no_quorum").Inc()
+		return false
+	}
+
+	if !res.Deviates {
+		logger.Debug("answer agrees with peer consensus")
+		consensusChecks.WithLabelValues(pair, "agreed").Inc()
+		return false
+	}
+
+	reason := fmt.Sprintf("answer %s deviates %.2f%% from peer median %s", price, res.DeviationPct, res.Median)
+	o.alerter.Alert(alerts.AlertPeerDeviation, endpoint, fmt.Sprintf("request %s for %s: %s", requestId, endpoint, reason))
+
+	if !viper.GetBool(config.ConsensusWithhold) {
+		logger.Warn("answer deviates from peer consensus")
+		consensusChecks.WithLabelValues(pair, "deviated").Inc()
+		return false
+	}
+
+	logger.Warn("answer deviates from peer consensus - withheld")
+	consensusChecks.WithLabelValues(pair, "withheld").Inc()
+	o.failJob(requestId, models.REQUEST_STATUS_API_ERROR, errcodes.Errorf(errcodes.ErrConsensusDeviation, "%s", reason))
+
+	return true
+}
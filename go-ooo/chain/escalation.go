@@ -0,0 +1,203 @@
+package chain
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	"math/big"
+)
+
+// RequestMaxAge - blocks, roughly an hour, after which a request not yet fulfilled is abandoned
+// as too old
+const RequestMaxAge = 250
+
+// defaultGasEscalationMaxMultiplier - multiple of the suggested gas price paid at a request's
+// deadline, if jobs.gas_escalation_max_multiplier is not set
+const defaultGasEscalationMaxMultiplier = 3
+
+// replacementPriceBump - percentage by which a replacement tx's gas price must exceed the
+// pending tx's for eth nodes to accept it into the tx pool
+const replacementPriceBump = 10
+
+var gasEscalations = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ooo_gas_escalations_total",
+	Help: "Number of fulfillment txs sent at an escalated gas price as their request's deadline approached, by kind - initial or replacement",
+}, []string{"kind"})
+
+// gasEscalationMultiplier returns the multiple of the suggested gas price to pay for the job's
+// fulfillment tx at currentBlockNum. It is 1 until jobs.gas_escalation_blocks before the
+// request's deadline, then rises linearly to jobs.gas_escalation_max_multiplier at the deadline
+func gasEscalationMultiplier(job models.DataRequests, currentBlockNum uint64) float64 {
+	window := viper.GetUint64(config.JobsGasEscalationBlocks)
+	if window == 0 || window > RequestMaxAge {
+		return 1
+	}
+
+	maxMultiplier := float64(defaultGasEscalationMaxMultiplier)
+	if viper.IsSet(config.JobsGasEscalationMaxMultiplier) {
+		maxMultiplier = viper.GetFloat64(config.JobsGasEscalationMaxMultiplier)
+	}
+	if maxMultiplier <= 1 {
+		return 1
+	}
+
+	age := uint64(0)
+	if currentBlockNum > job.RequestBlockNumber {
+		age = currentBlockNum - job.RequestBlockNumber
+	}
+	start := uint64(RequestMaxAge) - window
+	if age <= start {
+		return 1
+	}
+
+	progress := float64(age-start) / float64(window)
+	if progress > 1 {
+		progress = 1
+	}
+	return 1 + (maxMultiplier-1)*progress
+}
+
+// escalatedGasPrice returns gasPrice multiplied by multiplier, within chain.max_gas_price
+func escalatedGasPrice(gasPrice *big.Int, multiplier float64) *big.Int {
+	escalated, _ := new(big.Float).Mul(new(big.Float).SetInt(gasPrice), big.NewFloat(multiplier)).Int(nil)
+	return capGasPrice(escalated)
+}
+
+// escalateGasPrice returns opts with the gas price escalated for the job's deadline, or opts as
+// they are if the deadline is not yet close. Getting the answer mined before the request is
+// abandoned takes priority over its cost, so escalated txs are not rechecked for profitability
+func (o *OoORouterService) escalateGasPrice(job models.DataRequests, opts *bind.TransactOpts, currentBlockNum uint64) *bind.TransactOpts {
+	multiplier := gasEscalationMultiplier(job, currentBlockNum)
+	if multiplier <= 1 || opts.GasPrice == nil {
+		return opts
+	}
+
+	gasPrice := escalatedGasPrice(opts.GasPrice, multiplier)
+	if gasPrice.Cmp(opts.GasPrice) <= 0 {
+		// already at chain.max_gas_price
+		return opts
+	}
+
+	o.jobLogger(job).WithFields(logrus.Fields{
+		"package":             "chain",
+		"function":            "escalateGasPrice",
+		"request_id":          job.GetRequestId(),
+		"request_age":         currentBlockNum - job.RequestBlockNumber,
+		"multiplier":          multiplier,
+		"gas_price":           opts.GasPrice.String(),
+		"escalated_gas_price": gasPrice.String(),
+	}).Info("request deadline approaching - escalating gas price")
+	gasEscalations.WithLabelValues("initial").Inc()
+
+	escalated := *opts
+	escalated.GasPrice = gasPrice
+	return &escalated
+}
+
+// replaceFulfillmentTx replaces the job's pending fulfillment tx with one paying the gas price
+// escalated for the request's deadline, with the same nonce, if that exceeds the pending tx's
+// price by enough for the replacement to be accepted. Returns true if a replacement was sent
+func (o *OoORouterService) replaceFulfillmentTx(ctx context.Context, job models.DataRequests, pending *types.Transaction, currentBlockNum uint64) bool {
+	if pending == nil || pending.Type() != types.LegacyTxType {
+		return false
+	}
+
+	multiplier := gasEscalationMultiplier(job, currentBlockNum)
+	if multiplier <= 1 || o.fulfillmentPaused() || o.fulfillmentQuiesced() != "" {
+		return false
+	}
+
+	requestId := job.GetRequestId()
+	logger := o.jobLogger(job).WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "replaceFulfillmentTx",
+		"request_id": requestId,
+		"tx_hash":    pending.Hash().Hex(),
+	})
+
+	suggested, err := o.suggestGasPrice(ctx)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"action": "suggest gas price",
+		}).Error(err.Error())
+		return false
+	}
+
+	gasPrice := escalatedGasPrice(suggested, multiplier)
+	minGasPrice := new(big.Int).Mul(pending.GasPrice(), big.NewInt(100+replacementPriceBump))
+	minGasPrice.Div(minGasPrice, big.NewInt(100))
+	if gasPrice.Cmp(minGasPrice) < 0 {
+		return false
+	}
+
+	if priceStale(job) {
+		var ok bool
+		if job, ok = o.repriceJob(ctx, job); !ok {
+			return false
+		}
+		repricesTotal.WithLabelValues(RepriceReplacement).Inc()
+	}
+
+	reqIdBytes32, priceBigInt, signatureBytes, err := o.fulfillmentArgs(job)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"action": "sign message",
+		}).Error(err.Error())
+		return false
+	}
+
+	o.txMu.Lock()
+	defer o.txMu.Unlock()
+
+	txOpts, _, err := o.transactOptsFor(job.GetProvider())
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"action": "get transact opts",
+		}).Error(err.Error())
+		return false
+	}
+
+	opts := *txOpts
+	opts.Nonce = new(big.Int).SetUint64(pending.Nonce())
+	opts.GasPrice = gasPrice
+	opts.GasLimit = pending.Gas()
+
+	tx, err := o.sendJournaledTx(requestId, job.GetPriceResult(), currentBlockNum, &opts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return o.contractInstance.FulfillRequest(opts, reqIdBytes32, priceBigInt, signatureBytes)
+	})
+	if err != nil {
+		// e.g. the pending tx was mined in the meantime
+		logger.WithFields(logrus.Fields{
+			"action": "send replacement transaction",
+		}).Warn(err.Error())
+		return false
+	}
+
+	err = o.db.UpdateFulfillmentSent(requestId, tx.Hash().Hex(), currentBlockNum)
+	if err == nil {
+		err = o.db.ResolveTxIntent(tx.Hash().Hex())
+	}
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"action": "record replacement transaction",
+		}).Error(err.Error())
+	}
+
+	gasEscalations.WithLabelValues("replacement").Inc()
+	logger.WithFields(logrus.Fields{
+		"replacement_tx":    tx.Hash().Hex(),
+		"nonce":             tx.Nonce(),
+		"request_age":       currentBlockNum - job.RequestBlockNumber,
+		"multiplier":        multiplier,
+		"pending_gas_price": pending.GasPrice().String(),
+		"gas_price":         gasPrice.String(),
+	}).Info("request deadline approaching - replaced pending fulfill tx at an escalated gas price")
+
+	return true
+}
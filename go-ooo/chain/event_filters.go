@@ -0,0 +1,475 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	go_ooo_types "go-ooo/types"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var chainEventsRecorded = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ooo_chain_events_total",
+	Help: "Number of events recorded for the chain.event_filters, by filter",
+}, []string{"filter"})
+
+// EventFilter is an additional contract event the node subscribes to, alongside the router's
+// DataRequested and RequestFulfilled, and records in the chain_events table, e.g.
+//
+//	[[chain.event_filters]]
+//	name = "PriceConsumed"
+//	addresses = ["0x..."]
+//	abi = '{"type":"event","name":"PriceConsumed","inputs":[{"name":"requestId","type":"bytes32","indexed":true},{"name":"price","type":"uint256"}]}'
+//
+// Abi is the event's ABI fragment, as a JSON object or a single element array. Name defaults
+// to the event's name. Addresses are the contracts emitting it, defaulting to the router. Topic,
+// the event's signature hash, is computed from the fragment, and must match it if set
+type EventFilter struct {
+	Name      string   `mapstructure:"name" json:"name"`
+	Addresses []string `mapstructure:"addresses" json:"addresses"`
+	Topic     string   `mapstructure:"topic" json:"topic"`
+	Abi       string   `mapstructure:"abi" json:"abi"`
+}
+
+// eventFilter is a configured filter, with its ABI fragment parsed
+type eventFilter struct {
+	EventFilter
+	event     abi.Event
+	topic     common.Hash
+	addresses []common.Address
+}
+
+// matches returns true if the log was emitted by one of the filter's contracts
+func (f *eventFilter) matches(l types.Log) bool {
+	if len(l.Topics) == 0 || l.Topics[0] != f.topic {
+		return false
+	}
+	if len(f.addresses) == 0 {
+		return true
+	}
+	for _, a := range f.addresses {
+		if a == l.Address {
+			return true
+		}
+	}
+	return false
+}
+
+// decode returns the log's indexed and non-indexed arguments, by name
+func (f *eventFilter) decode(l types.Log) (map[string]interface{}, error) {
+	args := make(map[string]interface{})
+
+	if err := f.event.Inputs.NonIndexed().UnpackIntoMap(args, l.Data); err != nil {
+		return nil, err
+	}
+
+	var indexed abi.Arguments
+	for _, in := range f.event.Inputs {
+		if in.Indexed {
+			indexed = append(indexed, in)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(args, indexed, l.Topics[1:]); err != nil {
+		return nil, err
+	}
+
+	for k, v := range args {
+		args[k] = eventArgValue(reflect.ValueOf(v))
+	}
+	return args, nil
+}
+
+// eventArgValue converts a decoded argument to a value which encodes to readable JSON
+func eventArgValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch a := v.Interface().(type) {
+	case common.Address:
+		return a.Hex()
+	case common.Hash:
+		return a.Hex()
+	case *big.Int:
+		return a.String()
+	case []byte:
+		return hexutil.Encode(a)
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Array, reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return hexutil.Encode(b)
+		}
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = eventArgValue(v.Index(i))
+		}
+		return values
+	case reflect.Struct:
+		// tuples are decoded to anonymous structs, tagged with the component names
+		values := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			name := v.Type().Field(i).Tag.Get("json")
+			if name == "" {
+				name = v.Type().Field(i).Name
+			}
+			values[name] = eventArgValue(v.Field(i))
+		}
+		return values
+	case reflect.Ptr:
+		return eventArgValue(v.Elem())
+	}
+
+	return v.Interface()
+}
+
+// parseEventAbi parses an event's ABI fragment, given as a JSON object or single element array
+func parseEventAbi(fragment string) (abi.Event, error) {
+	fragment = strings.TrimSpace(fragment)
+	if !strings.HasPrefix(fragment, "[") {
+		fragment = "[" + fragment + "]"
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(fragment))
+	if err != nil {
+		return abi.Event{}, fmt.Errorf("abi is not a valid ABI fragment: %s", err.Error())
+	}
+	if len(parsed.Events) != 1 || len(parsed.Methods) != 0 {
+		return abi.Event{}, fmt.Errorf("abi must define exactly one event")
+	}
+
+	var ev abi.Event
+	for _, e := range parsed.Events {
+		ev = e
+	}
+	if ev.Anonymous {
+		return abi.Event{}, fmt.Errorf("anonymous event %s has no topic to filter on", ev.Name)
+	}
+
+	// unnamed arguments are decoded by position
+	for i := range ev.Inputs {
+		if ev.Inputs[i].Name == "" {
+			ev.Inputs[i].Name = fmt.Sprintf("arg%d", i)
+		}
+	}
+
+	return ev, nil
+}
+
+// LoadEventFilters returns the chain.event_filters config, checking each filter is valid
+func LoadEventFilters() ([]EventFilter, error) {
+	filters, err := loadEventFilters()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]EventFilter, 0, len(filters))
+	for _, f := range filters {
+		result = append(result, f.EventFilter)
+	}
+	return result, nil
+}
+
+func loadEventFilters() ([]*eventFilter, error) {
+	var filters []EventFilter
+	var err error
+	if raw, ok := viper.Get(config.ChainEventFilters).(string); ok {
+		// set by an environment variable, as a JSON array of filters
+		err = json.Unmarshal([]byte(raw), &filters)
+	} else {
+		err = viper.UnmarshalKey(config.ChainEventFilters, &filters)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	result := make([]*eventFilter, 0, len(filters))
+	for i, f := range filters {
+		if strings.TrimSpace(f.Abi) == "" {
+			return nil, fmt.Errorf("event filter %d requires an abi", i)
+		}
+		ev, err := parseEventAbi(f.Abi)
+		if err != nil {
+			return nil, fmt.Errorf("event filter %d: %s", i, err.Error())
+		}
+
+		if f.Name == "" {
+			f.Name = ev.Name
+		}
+		if names[f.Name] {
+			return nil, fmt.Errorf("duplicate event filter %s", f.Name)
+		}
+		names[f.Name] = true
+
+		if f.Topic != "" && common.HexToHash(f.Topic) != ev.ID {
+			return nil, fmt.Errorf("event filter %s: topic %s does not match the abi's %s, %s",
+				f.Name, f.Topic, ev.Sig, ev.ID.Hex())
+		}
+		f.Topic = ev.ID.Hex()
+
+		filter := &eventFilter{EventFilter: f, event: ev, topic: ev.ID}
+		for _, a := range f.Addresses {
+			if !common.IsHexAddress(a) {
+				return nil, fmt.Errorf("event filter %s: address %q is not an address", f.Name, a)
+			}
+			filter.addresses = append(filter.addresses, common.HexToAddress(a))
+		}
+
+		result = append(result, filter)
+	}
+
+	return result, nil
+}
+
+// initEventFilters loads the chain.event_filters. Filters without addresses watch the router
+func (o *OoORouterService) initEventFilters() error {
+	filters, err := loadEventFilters()
+	if err != nil {
+		return fmt.Errorf("%s: %s", config.ChainEventFilters, err.Error())
+	}
+
+	for _, f := range filters {
+		if len(f.addresses) == 0 {
+			f.addresses = []common.Address{o.contractAddress}
+		}
+
+		o.logger.WithFields(logrus.Fields{
+			"package":   "chain",
+			"function":  "initEventFilters",
+			"filter":    f.Name,
+			"event":     f.event.Sig,
+			"topic":     f.Topic,
+			"addresses": f.addresses,
+		}).Info("event filter enabled")
+	}
+
+	o.eventFilters = filters
+	if len(filters) > 0 {
+		o.chanFilteredLogs = make(chan types.Log)
+	}
+	return nil
+}
+
+// EventFiltersEnabled returns true if any chain.event_filters are configured
+func (o *OoORouterService) EventFiltersEnabled() bool {
+	return len(o.eventFilters) > 0
+}
+
+// eventFilterQuery returns a query for the logs of every filter
+func (o *OoORouterService) eventFilterQuery() ethereum.FilterQuery {
+	var addresses []common.Address
+	var topics []common.Hash
+	seen := make(map[common.Address]bool)
+	for _, f := range o.eventFilters {
+		topics = append(topics, f.topic)
+		for _, a := range f.addresses {
+			if !seen[a] {
+				seen[a] = true
+				addresses = append(addresses, a)
+			}
+		}
+	}
+	return ethereum.FilterQuery{Addresses: addresses, Topics: [][]common.Hash{topics}}
+}
+
+// recordFilteredEvent records a log for each filter it matches, or deletes the events recorded
+// for it if the log has been removed by a reorg
+func (o *OoORouterService) recordFilteredEvent(l types.Log) {
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":   "chain",
+		"function":  "recordFilteredEvent",
+		"tx_hash":   l.TxHash.Hex(),
+		"log_index": l.Index,
+	})
+
+	if l.Removed {
+		if err := o.db.DeleteChainEvent(l.TxHash.Hex(), l.Index); err != nil {
+			logger.WithFields(logrus.Fields{
+				"action": "delete removed chain event",
+			}).Error(err.Error())
+		}
+		return
+	}
+
+	for _, f := range o.eventFilters {
+		if !f.matches(l) {
+			continue
+		}
+
+		args, err := f.decode(l)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"filter": f.Name,
+				"action": "decode event",
+			}).Error(err.Error())
+			continue
+		}
+		argsJson, _ := json.Marshal(args)
+
+		err = o.db.InsertChainEvent(models.ChainEvents{
+			Name:        f.Name,
+			Address:     l.Address.Hex(),
+			Topic:       f.Topic,
+			BlockNumber: l.BlockNumber,
+			TxHash:      l.TxHash.Hex(),
+			LogIndex:    l.Index,
+			Args:        string(argsJson),
+			Data:        hexutil.Encode(l.Data),
+		})
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"filter": f.Name,
+				"action": "insert chain event",
+			}).Error(err.Error())
+			continue
+		}
+
+		chainEventsRecorded.WithLabelValues(f.Name).Inc()
+		logger.WithFields(logrus.Fields{
+			"filter":       f.Name,
+			"address":      l.Address.Hex(),
+			"block_number": l.BlockNumber,
+		}).Debug("chain event recorded")
+	}
+}
+
+// getFilteredHistoricalEvents records the filtered events between filterOpts.Start and
+// *filterOpts.End, e.g. those emitted while the node was down
+func (o *OoORouterService) getFilteredHistoricalEvents(filterOpts *bind.FilterOpts) {
+	q := o.eventFilterQuery()
+	q.FromBlock = new(big.Int).SetUint64(filterOpts.Start)
+	if filterOpts.End != nil {
+		q.ToBlock = new(big.Int).SetUint64(*filterOpts.End)
+	}
+
+	logs, err := o.client.FilterLogs(o.context, q)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "getFilteredHistoricalEvents",
+			"from_block": filterOpts.Start,
+			"action":     "get filtered events",
+		}).Error(err.Error())
+		rpcError("FilterLogs")
+		return
+	}
+
+	for _, l := range logs {
+		o.recordFilteredEvent(l)
+	}
+}
+
+func (o *OoORouterService) subscribeToEventFilters() {
+	if o.subscriptionEf != nil {
+		o.subscriptionEf.Unsubscribe()
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.MaxElapsedTime = 10 * time.Minute
+
+	var sub event.Subscription
+
+	retryable := func() error {
+		var subErr error
+		sub, subErr = o.client.SubscribeFilterLogs(o.context, o.eventFilterQuery(), o.chanFilteredLogs)
+		return subErr
+	}
+
+	notify := func(err error, t time.Duration) {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "subscribeToEventFilters",
+			"action":   "init subscription",
+		}).Error(err.Error())
+	}
+
+	err := backoff.RetryNotify(retryable, b, notify)
+
+	if err != nil {
+		// no point continuing if we can't connect after retrying
+		panic(err)
+	}
+
+	o.subscriptionEf = sub
+}
+
+func (o *OoORouterService) RunEventFilterWatchers() {
+	o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "RunEventFilterWatchers",
+		"filters":  len(o.eventFilters),
+	}).Info("initialise event filter subscriptions")
+
+	o.subscribeToEventFilters()
+
+	for {
+		select {
+		case l := <-o.chanFilteredLogs:
+			if !o.ingestionPaused() {
+				o.recordFilteredEvent(l)
+			}
+		case subErr := <-o.subscriptionEf.Err():
+			if subErr != nil {
+				o.logger.WithFields(logrus.Fields{
+					"package":  "chain",
+					"function": "RunEventFilterWatchers",
+					"action":   "event filter subscription connection error",
+				}).Error(subErr.Error())
+				rpcError("SubscribeFilterLogs")
+				o.subscribeToEventFilters()
+			}
+		}
+	}
+}
+
+// GetChainEvents returns the last limit events recorded for the chain.event_filters, most
+// recent first, optionally only those of the named filter
+func (o *OoORouterService) GetChainEvents(name string, limit int) ([]go_ooo_types.ChainEvent, error) {
+	rows, err := o.db.GetChainEvents(name, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]go_ooo_types.ChainEvent, 0, len(rows))
+	for _, r := range rows {
+		e := go_ooo_types.ChainEvent{
+			Name:        r.Name,
+			Address:     r.Address,
+			Topic:       r.Topic,
+			BlockNumber: r.BlockNumber,
+			TxHash:      r.TxHash,
+			LogIndex:    r.LogIndex,
+			Args:        map[string]interface{}{},
+			Data:        r.Data,
+			RecordedAt:  r.CreatedAt.Unix(),
+		}
+		_ = json.Unmarshal([]byte(r.Args), &e.Args)
+		events = append(events, e)
+	}
+	return events, nil
+}
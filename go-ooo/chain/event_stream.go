@@ -0,0 +1,56 @@
+package chain
+
+import (
+	"go-ooo/webhooks"
+	"sync"
+	"time"
+)
+
+// jobEventStream fans job lifecycle events out to live subscribers, e.g. admin API clients
+type jobEventStream struct {
+	mu   sync.Mutex
+	subs map[chan webhooks.JobEvent]bool
+}
+
+func newJobEventStream() *jobEventStream {
+	return &jobEventStream{
+		subs: make(map[chan webhooks.JobEvent]bool),
+	}
+}
+
+// SubscribeJobEvents returns a channel receiving job events as they happen, and a function
+// to unsubscribe. Events are dropped for subscribers which do not keep up
+func (o *OoORouterService) SubscribeJobEvents() (<-chan webhooks.JobEvent, func()) {
+	ch := make(chan webhooks.JobEvent, 64)
+
+	o.eventStream.mu.Lock()
+	o.eventStream.subs[ch] = true
+	o.eventStream.mu.Unlock()
+
+	unsubscribe := func() {
+		o.eventStream.mu.Lock()
+		defer o.eventStream.mu.Unlock()
+		if o.eventStream.subs[ch] {
+			delete(o.eventStream.subs, ch)
+			close(ch)
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+func (s *jobEventStream) publish(ev webhooks.JobEvent) {
+	if ev.Timestamp == 0 {
+		ev.Timestamp = time.Now().Unix()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
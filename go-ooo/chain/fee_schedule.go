@@ -0,0 +1,216 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/alerts"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	go_ooo_types "go-ooo/types"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// defaults used if the fee schedule is enabled in configs written by older versions, without
+// its other settings
+var feeScheduleDefaults = map[string]float64{
+	config.FeeScheduleInterval:   3600,
+	config.FeeScheduleWindow:     86400,
+	config.FeeScheduleMinSamples: 5,
+	config.FeeScheduleMargin:     25,
+	config.FeeScheduleThreshold:  10,
+}
+
+// feeSchedule serialises fee recommendations, and tracks whether the operator is being alerted
+// about the current fee
+type feeSchedule struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	alerting  bool
+}
+
+func feeScheduleSetting(key string) float64 {
+	if !viper.IsSet(key) {
+		return feeScheduleDefaults[key]
+	}
+	return viper.GetFloat64(key)
+}
+
+// RecommendFee returns the provider fee covering the mean gas cost of the fulfillments sent in
+// the last fee_schedule.window seconds, at the current xFUND price, plus fee_schedule.margin,
+// alongside the current fee
+func (o *OoORouterService) RecommendFee(ctx context.Context) (go_ooo_types.FeeRecommendation, error) {
+	var rec go_ooo_types.FeeRecommendation
+
+	window := time.Duration(feeScheduleSetting(config.FeeScheduleWindow)) * time.Second
+	minSamples := int(feeScheduleSetting(config.FeeScheduleMinSamples))
+	rec.Window = int64(window.Seconds())
+	rec.Margin = feeScheduleSetting(config.FeeScheduleMargin)
+
+	now := time.Now()
+	jobs, err := o.db.GetRequestsReceivedBetween(now.Add(-window), now)
+	if err != nil {
+		return rec, err
+	}
+
+	gasUsed := new(big.Int)
+	gasPrice := new(big.Int)
+	cost := new(big.Int)
+	for _, j := range jobs {
+		if j.GetRequestStatus() != models.REQUEST_STATUS_SUCCESS || j.GetFulfillGasUsed() == 0 || j.GetFulfillGasPrice() == 0 {
+			continue
+		}
+		used := new(big.Int).SetUint64(j.GetFulfillGasUsed())
+		price := new(big.Int).SetUint64(j.GetFulfillGasPrice())
+		gasUsed.Add(gasUsed, used)
+		gasPrice.Add(gasPrice, price)
+		cost.Add(cost, used.Mul(used, price))
+		rec.Samples++
+	}
+
+	if rec.Samples == 0 || rec.Samples < minSamples {
+		return rec, fmt.Errorf("%d fulfillments in the last %s - at least %d are needed to recommend a fee",
+			rec.Samples, window, minSamples)
+	}
+
+	samples := big.NewInt(int64(rec.Samples))
+	rec.MeanGasUsed = gasUsed.Div(gasUsed, samples).Uint64()
+	rec.MeanGasPrice = gasPrice.Div(gasPrice, samples).String()
+	cost.Div(cost, samples)
+	rec.MeanCost = cost.String()
+
+	xfundPrice, err := o.xfundNativePrice(ctx)
+	if err != nil {
+		return rec, err
+	}
+	rec.XfundPrice = xfundPrice.String()
+
+	recommended := o.nativeToXfund(cost, xfundPrice, rec.Margin)
+	if minFee := new(big.Int).SetUint64(viper.GetUint64(config.FeeScheduleMinFee)); recommended.Cmp(minFee) < 0 {
+		recommended = minFee
+		rec.Bounded = true
+	}
+	if maxFee := new(big.Int).SetUint64(viper.GetUint64(config.FeeScheduleMaxFee)); maxFee.Sign() > 0 && recommended.Cmp(maxFee) > 0 {
+		recommended = maxFee
+		rec.Bounded = true
+	}
+	// the router does not accept a fee of 0
+	if recommended.Sign() == 0 {
+		recommended = big.NewInt(1)
+	}
+	rec.RecommendedFee = recommended.String()
+
+	callOpts := *o.callOpts
+	callOpts.Context = ctx
+	current, err := o.contractInstance.GetProviderMinFee(&callOpts, o.oracleAddress)
+	if err != nil {
+		rpcError("GetProviderMinFee")
+		return rec, err
+	}
+	rec.CurrentFee = current.String()
+
+	if current.Sign() > 0 {
+		change, _ := new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).Sub(recommended, current)), new(big.Float).SetInt(current)).Float64()
+		rec.Change = math.Round(change*10000) / 100
+	}
+
+	return rec, nil
+}
+
+// CheckFeeSchedule recommends a provider fee, at most once per fee_schedule.interval, and if it
+// differs from the current fee by more than fee_schedule.threshold percent, alerts the
+// operator, or if fee_schedule.auto_update is set, sends the fee update tx
+func (o *OoORouterService) CheckFeeSchedule() {
+	if !viper.GetBool(config.FeeScheduleEnabled) {
+		return
+	}
+
+	o.feeSchedule.mu.Lock()
+	defer o.feeSchedule.mu.Unlock()
+
+	if time.Since(o.feeSchedule.checkedAt) < time.Duration(feeScheduleSetting(config.FeeScheduleInterval))*time.Second {
+		return
+	}
+	o.feeSchedule.checkedAt = time.Now()
+
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "CheckFeeSchedule",
+	})
+
+	ctx, cancel := context.WithTimeout(o.context, 30*time.Second)
+	defer cancel()
+
+	rec, err := o.RecommendFee(ctx)
+	if err != nil {
+		logger.Warn("cannot recommend a fee: ", err.Error())
+		return
+	}
+
+	logger = logger.WithFields(logrus.Fields{
+		"current_fee":     rec.CurrentFee,
+		"recommended_fee": rec.RecommendedFee,
+		"change":          rec.Change,
+		"samples":         rec.Samples,
+		"mean_cost":       rec.MeanCost,
+	})
+
+	if rec.CurrentFee != "0" && math.Abs(rec.Change) < feeScheduleSetting(config.FeeScheduleThreshold) {
+		logger.Debug("current fee is within the threshold of the recommended fee")
+		if o.feeSchedule.alerting {
+			o.alerter.Resolve(alerts.AlertFeeSchedule, o.oracleAddress.Hex())
+			o.feeSchedule.alerting = false
+		}
+		return
+	}
+
+	summary := fmt.Sprintf("recommended fee %s differs from the current fee %s by %g%%, from a mean cost of %s wei over %d fulfillments",
+		rec.RecommendedFee, rec.CurrentFee, rec.Change, rec.MeanCost, rec.Samples)
+
+	if !viper.GetBool(config.FeeScheduleAutoUpdate) {
+		logger.Warn("current fee differs from the recommended fee")
+		o.alerter.Alert(alerts.AlertFeeSchedule, o.oracleAddress.Hex(), summary)
+		o.feeSchedule.alerting = true
+		return
+	}
+
+	tx, err := o.updateProviderFee(rec.RecommendedFee)
+	if err != nil {
+		logger.Error("fee update tx failed: ", err.Error())
+		o.alerter.Alert(alerts.AlertFeeSchedule, o.oracleAddress.Hex(), summary+" - fee update tx failed: "+err.Error())
+		o.feeSchedule.alerting = true
+		return
+	}
+
+	logger.WithField("tx", tx.Hex()).Info("fee update tx sent")
+	o.alerter.Alert(alerts.AlertFeeSchedule, o.oracleAddress.Hex(), summary+" - fee update tx sent: "+tx.Hex())
+	o.feeSchedule.alerting = true
+}
+
+// updateProviderFee sends the tx setting the provider's fee to fee
+func (o *OoORouterService) updateProviderFee(fee string) (common.Hash, error) {
+	newFee, ok := new(big.Int).SetString(fee, 10)
+	if !ok {
+		return common.Hash{}, fmt.Errorf("invalid fee %q", fee)
+	}
+
+	o.txMu.Lock()
+	defer o.txMu.Unlock()
+
+	if err := o.RenewTransactOpts(); err != nil {
+		return common.Hash{}, err
+	}
+
+	tx, err := o.contractInstance.SetProviderMinFee(o.transactOpts, newFee)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	o.setNextTxNonce(tx.Nonce(), false)
+
+	return tx.Hash(), nil
+}
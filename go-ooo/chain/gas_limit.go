@@ -0,0 +1,130 @@
+package chain
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	"go-ooo/errcodes"
+	"go-ooo/webhooks"
+	"strings"
+)
+
+const (
+	GasLimitActionSkip  = "skip"  // requests needing more than the gas limit ceiling are skipped and not fulfilled
+	GasLimitActionDefer = "defer" // requests needing more than the gas limit ceiling are left pending until it is raised
+)
+
+const (
+	// defaultGasLimitBuffer - percent added to a fulfillment's estimated gas, if chain.gas_limit_buffer is not set
+	defaultGasLimitBuffer = 20
+	// defaultGasLimitCeiling - maximum fulfillment gas limit, if chain.gas_limit_ceiling is not set
+	defaultGasLimitCeiling = 2000000
+)
+
+var (
+	fulfillmentGasEstimates = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "ooo_fulfillment_gas_estimate",
+		Help:    "Estimated gas of fulfillment txs, including the consumer's callback",
+		Buckets: prometheus.ExponentialBuckets(50000, 1.5, 12),
+	})
+
+	gasLimitExceededRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_gas_limit_exceeded_total",
+		Help: "Number of times a request's fulfillment was estimated to need more than the gas limit ceiling, by action taken",
+	}, []string{"action"})
+)
+
+// fulfillmentGasLimit returns the gas the job's fulfillment tx data, sent to the router, is
+// estimated to use, including the consumer's callback, and the gas limit to send it with - the
+// estimate plus chain.gas_limit_buffer. If the estimate is over chain.gas_limit_ceiling, the job
+// is skipped or deferred and false returned. If estimation is disabled, or fails, e.g. because the
+// callback reverts, both are 0, and the tx is sent with chain.gas_limit
+func (o *OoORouterService) fulfillmentGasLimit(ctx context.Context, job models.DataRequests, data []byte) (uint64, uint64, bool) {
+	if viper.IsSet(config.ChainEstimateGasLimit) && !viper.GetBool(config.ChainEstimateGasLimit) {
+		return 0, 0, true
+	}
+
+	estimate, err := o.client.EstimateGas(ctx, ethereum.CallMsg{
+		From: common.HexToAddress(job.GetProvider()),
+		To:   &o.contractAddress,
+		Data: data,
+	})
+	if err != nil {
+		rpcError("EstimateGas")
+		o.jobLogger(job).WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "fulfillmentGasLimit",
+			"request_id": job.GetRequestId(),
+		}).Warn("cannot estimate fulfillment gas - using chain.gas_limit: ", err.Error())
+		return 0, 0, true
+	}
+
+	fulfillmentGasEstimates.Observe(float64(estimate))
+
+	buffer := float64(defaultGasLimitBuffer)
+	if viper.IsSet(config.ChainGasLimitBuffer) {
+		buffer = viper.GetFloat64(config.ChainGasLimitBuffer)
+	}
+	ceiling := uint64(defaultGasLimitCeiling)
+	if viper.IsSet(config.ChainGasLimitCeiling) {
+		ceiling = uint64(viper.GetInt64(config.ChainGasLimitCeiling))
+	}
+
+	logger := o.jobLogger(job).WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "fulfillmentGasLimit",
+		"request_id": job.GetRequestId(),
+		"consumer":   job.GetConsumer(),
+		"estimate":   estimate,
+		"ceiling":    ceiling,
+	})
+
+	if estimate > ceiling {
+		o.gasLimitExceeded(job, estimate, ceiling, logger)
+		return estimate, 0, false
+	}
+
+	// never above the ceiling, which the estimate alone fits in
+	limit := estimate + uint64(float64(estimate)*buffer/100)
+	if limit > ceiling {
+		limit = ceiling
+	}
+
+	logger.WithField("gas_limit", limit).Debug("fulfillment gas estimated")
+
+	return estimate, limit, true
+}
+
+// gasLimitExceeded skips or defers a job whose fulfillment is estimated to need more gas than
+// chain.gas_limit_ceiling, recording the estimate as the job's status reason
+func (o *OoORouterService) gasLimitExceeded(job models.DataRequests, estimate uint64, ceiling uint64, logger *logrus.Entry) {
+	requestId := job.GetRequestId()
+
+	action := strings.ToLower(viper.GetString(config.ChainGasLimitAction))
+	if action != GasLimitActionDefer {
+		action = GasLimitActionSkip
+	}
+	gasLimitExceededRequests.WithLabelValues(action).Inc()
+
+	failure := errcodes.Errorf(errcodes.ErrGasCapExceeded,
+		"fulfillment, including consumer %s's callback, estimated at %d gas, over the %d gas limit ceiling",
+		job.GetConsumer(), estimate, ceiling)
+
+	logger.WithField("action", action).Warn("fulfillment gas over gas limit ceiling")
+
+	if action == GasLimitActionSkip {
+		_ = o.failRequest(requestId, models.REQUEST_STATUS_SKIPPED_GAS_LIMIT, failure)
+		o.recordJobEvent(webhooks.EventSkipped, requestId)
+		return
+	}
+
+	// leave ready to send, and check again on the next job queue check
+	_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_DATA_READY_TO_SEND, string(errcodes.CodeOf(failure)),
+		"deferred: "+failure.Error())
+}
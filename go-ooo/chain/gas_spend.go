@@ -0,0 +1,64 @@
+package chain
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"go-ooo/database/models"
+	"math/big"
+)
+
+// recordGasSpend records the base fee of the block a fulfillment tx for the request was mined in,
+// and the priority fee the tx paid above it, so that failed or slow fulfillments can be compared
+// with the fee market at the time. Errors are only logged, since the fulfillment's outcome has
+// already been recorded
+func (o *OoORouterService) recordGasSpend(requestId string, requestBlock uint64, txHash common.Hash,
+	blockNumber uint64, gasUsed uint64, success bool) {
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "recordGasSpend",
+		"request_id": requestId,
+		"tx_hash":    txHash.Hex(),
+	})
+
+	header, err := o.client.HeaderByNumber(o.context, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		rpcError("HeaderByNumber")
+		logger.Error(err.Error())
+		return
+	}
+
+	tx, _, err := o.client.TransactionByHash(o.context, txHash)
+	if err != nil {
+		rpcError("TransactionByHash")
+		logger.Error(err.Error())
+		return
+	}
+
+	// before EIP-1559 there is no base fee, and the whole gas price is the priority fee
+	baseFee := big.NewInt(0)
+	if header.BaseFee != nil {
+		baseFee = header.BaseFee
+	}
+	tip := tx.EffectiveGasTipValue(header.BaseFee)
+
+	blocks := uint64(0)
+	if blockNumber > requestBlock {
+		blocks = blockNumber - requestBlock
+	}
+
+	err = o.db.InsertGasSpend(models.GasSpends{
+		RequestId:   requestId,
+		TxHash:      txHash.Hex(),
+		BlockNumber: blockNumber,
+		BlockTime:   int64(header.Time),
+		BaseFee:     baseFee.Uint64(),
+		PriorityFee: tip.Uint64(),
+		GasPrice:    new(big.Int).Add(baseFee, tip).Uint64(),
+		GasUsed:     gasUsed,
+		Blocks:      blocks,
+		Success:     success,
+	})
+	if err != nil {
+		logger.Error(err.Error())
+	}
+}
@@ -0,0 +1,135 @@
+package chain
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/alerts"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+var pairLastFulfilled = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "ooo_pair_last_fulfilled_timestamp",
+	Help: "Unix time of the last successful fulfillment seen for each pair",
+}, []string{"pair"})
+
+// pairHeartbeats tracks when each pair was last successfully fulfilled, and the pairs currently
+// alerted as only failing
+type pairHeartbeats struct {
+	mu            sync.Mutex
+	lastFulfilled map[string]time.Time
+	alerting      map[string]bool
+}
+
+// pairFulfilled records a successful fulfillment for pair at t
+func (p *pairHeartbeats) pairFulfilled(pair string, t time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.lastFulfilled == nil {
+		p.lastFulfilled = make(map[string]time.Time)
+	}
+	if t.After(p.lastFulfilled[pair]) {
+		p.lastFulfilled[pair] = t
+		pairLastFulfilled.WithLabelValues(pairLabels.value(pair)).Set(float64(t.Unix()))
+	}
+}
+
+// isHeartbeatFailure returns true for statuses which mean the data could not be fetched or sent,
+// rather than that the request was deliberately not fulfilled
+func isHeartbeatFailure(status int) bool {
+	switch status {
+	case models.REQUEST_STATUS_API_ERROR,
+		models.REQUEST_STATUS_TX_FAILED,
+		models.REQUEST_STATUS_TIMEOUT,
+		models.REQUEST_STATUS_FULFILMENT_FAILED,
+		models.REQUEST_STATUS_DEAD:
+		return true
+	}
+	return false
+}
+
+// CheckPairHeartbeats alerts for each pair with at least alerts.pair_heartbeat_min_failures
+// failed requests, and no successful fulfillments, among the requests received in the last
+// alerts.pair_heartbeat_window seconds - catching data source breakage affecting only some pairs
+func (o *OoORouterService) CheckPairHeartbeats() {
+	window := time.Duration(viper.GetInt64(config.AlertsPairHeartbeatWindow)) * time.Second
+	if window <= 0 {
+		return
+	}
+
+	minFailures := viper.GetInt(config.AlertsPairHeartbeatMinFailures)
+	if minFailures <= 0 {
+		minFailures = 3
+	}
+
+	now := time.Now()
+	jobs, err := o.db.GetRequestsReceivedBetween(now.Add(-window), now)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "CheckPairHeartbeats",
+		}).Error(err.Error())
+		return
+	}
+
+	failures := make(map[string]int)
+	fulfilled := make(map[string]bool)
+	for _, j := range jobs {
+		pair := latencyPair(j)
+		switch {
+		case j.GetRequestStatus() == models.REQUEST_STATUS_SUCCESS:
+			fulfilled[pair] = true
+			o.heartbeats.pairFulfilled(pair, j.UpdatedAt)
+		case isHeartbeatFailure(j.GetRequestStatus()):
+			failures[pair]++
+		}
+	}
+
+	o.heartbeats.mu.Lock()
+	defer o.heartbeats.mu.Unlock()
+
+	silent := make(map[string]bool)
+	pairs := make([]string, 0, len(failures))
+	for pair := range failures {
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+
+	for _, pair := range pairs {
+		if fulfilled[pair] || failures[pair] < minFailures {
+			continue
+		}
+		silent[pair] = true
+
+		last := "not fulfilled since go-ooo started"
+		if t, ok := o.heartbeats.lastFulfilled[pair]; ok {
+			last = fmt.Sprintf("last fulfilled %s ago", now.Sub(t).Round(time.Second))
+		}
+
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "CheckPairHeartbeats",
+			"pair":     pair,
+			"failures": failures[pair],
+			"window":   window.String(),
+		}).Warn("pair has only failed requests")
+
+		o.alerter.Alert(alerts.AlertPairHeartbeat, pair,
+			fmt.Sprintf("%s: %d failed requests and none fulfilled in the last %s - %s", pair, failures[pair], window, last)+
+				o.alertTags("", pair))
+	}
+
+	for pair := range o.heartbeats.alerting {
+		if !silent[pair] {
+			o.alerter.Resolve(alerts.AlertPairHeartbeat, pair)
+		}
+	}
+	o.heartbeats.alerting = silent
+}
@@ -0,0 +1,78 @@
+package chain
+
+import (
+	"encoding/json"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/ipfs"
+	"go-ooo/ooo_api"
+	"strings"
+	"time"
+)
+
+var attestationsPublished = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "ooo_attestations_published_total",
+	Help: "Number of answer attestations published to IPFS",
+})
+
+// ipfsConfig returns the IPFS API attestations are published to. An empty api url disables
+// publishing
+func ipfsConfig() ipfs.Config {
+	return ipfs.Config{
+		ApiUrl:   viper.GetString(config.IpfsApiUrl),
+		Username: viper.GetString(config.IpfsUsername),
+		Password: viper.GetString(config.IpfsPassword),
+		Timeout:  time.Duration(viper.GetInt64(config.IpfsTimeout)) * time.Second,
+	}
+}
+
+// endpointPair returns the pair an endpoint is for, e.g. BTC.USD, or "" if it is not a pair
+func endpointPair(endpoint string) string {
+	base, target, _, _, _, _, _, err := ooo_api.ParseEndpoint(endpoint)
+	if err != nil {
+		return ""
+	}
+	return strings.ToUpper(base) + "." + strings.ToUpper(target)
+}
+
+// publishAttestation queues doc to be published to IPFS. Its CID is recorded with the
+// attestation once published
+func (o *OoORouterService) publishAttestation(doc AttestationDocument) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "publishAttestation",
+			"request_id": doc.RequestId,
+		}).Error(err.Error())
+		return
+	}
+
+	o.ipfs.Publish(doc.MessageHash, "attestation-"+doc.RequestId+".json", data)
+}
+
+// attestationPublished records the CID of the attestation with messageHash
+func (o *OoORouterService) attestationPublished(messageHash string, cid string) {
+	err := o.db.UpdateAttestationCid(messageHash, cid)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "attestationPublished",
+			"hash":     messageHash,
+			"cid":      cid,
+		}).Error(err.Error())
+		return
+	}
+
+	attestationsPublished.Inc()
+
+	o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "attestationPublished",
+		"hash":     messageHash,
+		"cid":      cid,
+	}).Info("attestation published to ipfs")
+}
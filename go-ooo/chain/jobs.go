@@ -1,18 +1,44 @@
 package chain
 
 import (
+	"context"
 	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/core/types"
 	solsha3 "github.com/miguelmota/go-solidity-sha3"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"go-ooo/alerts"
 	"go-ooo/config"
 	"go-ooo/database/models"
+	"go-ooo/errcodes"
+	"go-ooo/ooo_api"
+	"go-ooo/tracing"
+	"go-ooo/webhooks"
 	"math/big"
+	"strings"
 )
 
-func (o *OoORouterService) ProcessPendingJobQueue() {
+// ProcessPendingJobQueue dispatches each pending job to the job workers, returning the number of
+// pending jobs
+func (o *OoORouterService) ProcessPendingJobQueue() int {
+	if o.fulfillmentPaused() {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "ProcessPendingJobQueue",
+		}).Info("fulfillment paused - skip job queue")
+		return 0
+	}
+
+	if quiesced := o.fulfillmentQuiesced(); quiesced != "" {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "ProcessPendingJobQueue",
+			"reason":   quiesced,
+		}).Warn("fulfillment quiesced - skip job queue")
+		return 0
+	}
 
 	o.logger.WithFields(logrus.Fields{
 		"package":  "chain",
@@ -31,7 +57,7 @@ func (o *OoORouterService) ProcessPendingJobQueue() {
 			"num_jobs": len(requests),
 		}).Error(err.Error())
 
-		return
+		return 0
 	}
 
 	if len(requests) > 0 {
@@ -44,20 +70,46 @@ func (o *OoORouterService) ProcessPendingJobQueue() {
 				"function": "ProcessPendingJobQueue",
 				"action":   "get block num",
 			}).Error(err.Error())
+			rpcError("BlockNumber")
 
-			return
+			return len(requests)
 		}
 
+		// stop fetching data for requests which can no longer be fulfilled
+		o.expireJobs(currentBlockNum)
+
 		for _, request := range requests {
 			// process
-			o.preProcessPendingJob(request, currentBlockNum)
+			o.dispatchJob(request, currentBlockNum)
 		}
 	}
+
+	return len(requests)
+}
+
+// JobActivity receives when new requests are ingested, or fulfillment is resumed
+func (o *OoORouterService) JobActivity() <-chan struct{} {
+	return o.jobActivity
+}
+
+func (o *OoORouterService) signalJobActivity() {
+	select {
+	case o.jobActivity <- struct{}{}:
+	default:
+		// a signal is already waiting
+	}
 }
 
-func (o *OoORouterService) preProcessPendingJob(job models.DataRequests, currentBlockNum uint64) {
+func (o *OoORouterService) preProcessPendingJob(ctx context.Context, job models.DataRequests, currentBlockNum uint64) {
 	requestId := job.GetRequestId()
-	o.logger.WithFields(logrus.Fields{
+
+	ctx, span := o.tracer.StartRequestSpan(ctx, requestId, "job")
+	defer span.End()
+	span.SetAttribute("request_id", requestId)
+	span.SetAttribute("status", job.GetRequestStatusString())
+	span.SetAttribute("block_number", currentBlockNum)
+
+	o.jobLogger(job).WithFields(logrus.Fields{
 		"package":    "chain",
 		"function":   "preProcessPendingJob",
 		"action":     "preprocess job",
@@ -66,16 +118,17 @@ func (o *OoORouterService) preProcessPendingJob(job models.DataRequests, current
 	}).Info()
 
 	// get request Tx receipt from chain
-	requestTxReceipt, err := o.client.TransactionReceipt(o.context, common.HexToHash(job.GetRequestTxHash()))
+	requestTxReceipt, err := o.client.TransactionReceipt(ctx, common.HexToHash(job.GetRequestTxHash()))
 	if err != nil {
 		// possibly not in Tx pool yet
-		o.logger.WithFields(logrus.Fields{
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "preProcessPendingJob",
 			"action":     "get tx receipt from chain",
 			"request_id": requestId,
 			"request_tx": job.GetRequestTxHash(),
 		}).Error(err.Error())
+		span.SetError(err)
 		return
 	}
 
@@ -84,12 +137,14 @@ func (o *OoORouterService) preProcessPendingJob(job models.DataRequests, current
 	case models.REQUEST_STATUS_INITIALISED:
 		waitConfirmations := viper.GetUint64(config.JobsWaitConfirmations)
 		if requestBlockDiff >= waitConfirmations {
-			go func(o *OoORouterService, job models.DataRequests) {
-				o.processFulfillmentFetchData(job, currentBlockNum)
-			}(o, job)
+			if !o.consumerRateLimit.allow(job.GetConsumer()) {
+				o.processRateLimitedJob(job)
+				return
+			}
+			o.processFulfillmentFetchData(ctx, job, currentBlockNum)
 		} else {
 			// log it
-			o.logger.WithFields(logrus.Fields{
+			o.jobLogger(job).WithFields(logrus.Fields{
 				"package":       "chain",
 				"function":      "preProcessPendingJob",
 				"action":        "check confirmations for initialised job",
@@ -102,19 +157,26 @@ func (o *OoORouterService) preProcessPendingJob(job models.DataRequests, current
 		}
 		return
 	case models.REQUEST_STATUS_DATA_READY_TO_SEND:
-		o.sendFulfillmentTx(job, currentBlockNum)
-		return
-	case models.REQUEST_STATUS_TX_FAILED:
-		o.processSendFailedJob(job, currentBlockNum)
+		o.sendFulfillmentTx(ctx, job, currentBlockNum)
 		return
-	case models.REQUEST_STATUS_API_ERROR:
-		o.processSendFailedJob(job, currentBlockNum)
+	case models.REQUEST_STATUS_TX_FAILED, models.REQUEST_STATUS_API_ERROR, models.REQUEST_STATUS_TIMEOUT:
+		if !jobReadyForRetry(job) {
+			o.jobLogger(job).WithFields(logrus.Fields{
+				"package":       "chain",
+				"function":      "preProcessPendingJob",
+				"action":        "check retry backoff",
+				"request_id":    requestId,
+				"next_retry_at": job.GetNextRetryAt(),
+			}).Debug("waiting for retry backoff")
+			return
+		}
+		o.processSendFailedJob(ctx, job, currentBlockNum)
 		return
 	case models.REQUEST_STATUS_FETCHING_DATA:
-		o.processPossiblyStuckDataFetch(job, currentBlockNum)
+		o.processPossiblyStuckDataFetch(ctx, job, currentBlockNum)
 		return
 	case models.REQUEST_STATUS_TX_SENT:
-		o.processPossiblyStuckSentTx(job, currentBlockNum)
+		o.processPossiblyStuckSentTx(ctx, job, currentBlockNum)
 		return
 	default:
 		return
@@ -122,19 +184,44 @@ func (o *OoORouterService) preProcessPendingJob(job models.DataRequests, current
 
 }
 
-func (o *OoORouterService) processFulfillmentFetchData(job models.DataRequests, currentBlockNum uint64) {
+// processRateLimitedJob defers or skips a job whose consumer has exceeded its fulfillment rate limit
+func (o *OoORouterService) processRateLimitedJob(job models.DataRequests) {
+	requestId := job.GetRequestId()
+	action := strings.ToLower(viper.GetString(config.JobsConsumerRateLimitAction))
+
+	o.jobLogger(job).WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "processRateLimitedJob",
+		"request_id": requestId,
+		"consumer":   job.GetConsumer(),
+		"limit":      viper.GetInt(config.JobsConsumerRateLimit),
+		"action":     action,
+	}).Warn("consumer rate limit exceeded")
+
+	if action == RateLimitActionSkip {
+		_ = o.failRequest(requestId, models.REQUEST_STATUS_SKIPPED_RATE_LIMIT,
+			errcodes.Errorf(errcodes.ErrRateLimited, "consumer exceeded %d fulfillments per hour", viper.GetInt(config.JobsConsumerRateLimit)))
+		o.recordJobEvent(webhooks.EventSkipped, requestId)
+	}
+
+	// otherwise, leave as initialised, and check again on the next job queue check
+}
+
+func (o *OoORouterService) processFulfillmentFetchData(ctx context.Context, job models.DataRequests, currentBlockNum uint64) {
 
 	requestId := job.GetRequestId()
 
-	o.logger.WithFields(logrus.Fields{
+	o.jobLogger(job).WithFields(logrus.Fields{
 		"package":    "chain",
 		"function":   "processFulfillmentFetchData",
 		"request_id": requestId,
 	}).Debug("begin fetching data")
 
+	o.txMu.Lock()
 	err := o.RenewTransactOpts()
+	o.txMu.Unlock()
 	if err != nil {
-		o.logger.WithFields(logrus.Fields{
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "ProcessAdminTask",
 			"action":     "RenewTransactOpts",
@@ -143,16 +230,19 @@ func (o *OoORouterService) processFulfillmentFetchData(job models.DataRequests,
 		return
 	}
 
-	err = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_FETCHING_DATA, "")
+	_, dbSpan := tracing.StartSpan(ctx, "db.mark_fetching")
+	err = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_FETCHING_DATA, "", "")
 
 	if err != nil {
 		// possibly not in Tx pool yet
-		o.logger.WithFields(logrus.Fields{
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processFulfillmentFetchData",
 			"action":     "update processing status in db",
 			"request_id": requestId,
 		}).Error(err.Error())
+		dbSpan.SetError(err)
+		dbSpan.End()
 		return
 	}
 
@@ -160,12 +250,14 @@ func (o *OoORouterService) processFulfillmentFetchData(job models.DataRequests,
 
 	if err != nil {
 		// possibly not in Tx pool yet
-		o.logger.WithFields(logrus.Fields{
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processFulfillmentFetchData",
 			"action":     "update fulfilment attempts in db",
 			"request_id": requestId,
 		}).Error(err.Error())
+		dbSpan.SetError(err)
+		dbSpan.End()
 		return
 	}
 
@@ -173,121 +265,273 @@ func (o *OoORouterService) processFulfillmentFetchData(job models.DataRequests,
 
 	if err != nil {
 		// possibly not in Tx pool yet
-		o.logger.WithFields(logrus.Fields{
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processFulfillmentFetchData",
 			"action":     "update last fetch blocknum in db",
 			"request_id": requestId,
 		}).Error(err.Error())
+		dbSpan.SetError(err)
+		dbSpan.End()
 		return
 	}
 
-	endpoint := job.GetEndpointDecoded()
+	dbSpan.End()
 
-	isAdHoc := job.GetIsAdHoc()
+	endpoint := job.GetEndpointDecoded()
+	sourcePolicy := ooo_api.ConsumerSourcePolicy(job.GetConsumer())
 
-	var price string
+	price, sources, explain, err := o.oooApi.QueryEndpointExplained(ctx, endpoint, requestId, sourcePolicy)
 
-	if isAdHoc {
-		price, err = o.oooApi.QueryAdhoc(endpoint, requestId)
-	} else {
-		price, err = o.oooApi.QueryFinchainsEndpoint(endpoint, requestId)
+	if ctx.Err() != nil {
+		// job timed out or was cancelled while fetching data. Either has already been recorded
+		return
 	}
 
 	if err != nil {
-		o.logger.WithFields(logrus.Fields{
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processFulfillmentFetchData",
 			"action":     "run api query",
 			"request_id": requestId,
 		}).Error(err.Error())
-		_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_API_ERROR, err.Error())
+		tracing.FromContext(ctx).SetError(err)
+		o.failJob(requestId, models.REQUEST_STATUS_API_ERROR, err)
 		return
 	}
 
-	if price == "" {
-		// no price returned
-		o.logger.WithFields(logrus.Fields{
+	err = ooo_api.ValidatePriceResult(price)
+
+	if err != nil {
+		// no usable price returned
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processFulfillmentFetchData",
-			"action":     "query api",
+			"action":     "validate price",
 			"request_id": requestId,
-		}).Error("empty price returned")
-		_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_API_ERROR, "empty price returned")
+			"price":      price,
+		}).Error(err.Error())
+		tracing.FromContext(ctx).SetError(err)
+		o.failJob(requestId, models.REQUEST_STATUS_API_ERROR, err)
 		return
 	}
 
-	o.logger.WithFields(logrus.Fields{
+	err = o.oooApi.CheckAnswerBound(endpoint, price)
+
+	if err != nil {
+		// never submitted. Failed, to be retried, as the sources may recover
+		o.jobLogger(job).WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "processFulfillmentFetchData",
+			"action":     "check price bound",
+			"request_id": requestId,
+			"price":      price,
+		}).Error(err.Error())
+		tracing.FromContext(ctx).SetError(err)
+		o.alerter.Alert(alerts.AlertPriceBound, endpoint, fmt.Sprintf("request %s for %s: %s", requestId, endpoint, err.Error()))
+		o.failJob(requestId, models.REQUEST_STATUS_API_ERROR, err)
+		return
+	}
+
+	o.jobLogger(job).WithFields(logrus.Fields{
 		"package":    "chain",
 		"function":   "processFulfillmentFetchData",
 		"request_id": requestId,
 		"endpoint":   job.Endpoint,
 		"price":      price,
+		"policy":     sourcePolicy,
 	}).Debug("price fetched")
 
-	_ = o.db.UpdateDataFetched(requestId, price)
+	if o.withholdForConsensus(ctx, job, price) {
+		return
+	}
+
+	sourceTier := ""
+	if explain != nil {
+		sourceTier = explain.SourceTier
+	}
+
+	_, dbSpan = tracing.StartSpan(ctx, "db.save_result")
+	dbSpan.SetError(o.db.UpdateDataFetched(requestId, price, o.oooApi.AnswerRounding(), sourcePolicy, sourceTier))
+	o.saveRequestSources(requestId, explain)
+	dbSpan.End()
+
+	_, attestSpan := tracing.StartSpan(ctx, "attest")
+	o.createAttestation(requestId, endpoint, price, sources)
+	attestSpan.End()
 
 	return
 }
 
-func (o *OoORouterService) sendFulfillmentTx(job models.DataRequests, currentBlockNum uint64) {
+func (o *OoORouterService) sendFulfillmentTx(ctx context.Context, job models.DataRequests, currentBlockNum uint64) {
 	requestId := job.GetRequestId()
 	price := job.GetPriceResult()
 
-	o.logger.WithFields(logrus.Fields{
+	o.jobLogger(job).WithFields(logrus.Fields{
 		"package":    "chain",
 		"function":   "sendFulfillmentTx",
 		"request_id": requestId,
 	}).Debug("begin send fulfillment transaction")
 
-	// https://ethereum.stackexchange.com/questions/51566/from-golang-sha3-to-solidity-sha3
-	priceBigInt := big.NewInt(0)
-	priceBigInt.SetString(price, 10)
-
-	reqIdBytes := common.FromHex(requestId)
-	reqIdBytes32 := [32]byte{}
-	copy(reqIdBytes32[:], reqIdBytes)
+	// jobs already being processed when fulfillment is paused are sent on resume
+	if o.fulfillmentPaused() {
+		o.jobLogger(job).WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "sendFulfillmentTx",
+			"request_id": requestId,
+		}).Info("fulfillment paused - tx will be sent on resume")
+		return
+	}
 
-	hash := solsha3.SoliditySHA3(
-		solsha3.Bytes32(reqIdBytes),
-		solsha3.Uint256(price),
-		solsha3.Address(job.Consumer),
-	)
+	if quiesced := o.fulfillmentQuiesced(); quiesced != "" {
+		o.jobLogger(job).WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "sendFulfillmentTx",
+			"request_id": requestId,
+			"reason":     quiesced,
+		}).Info("fulfillment quiesced - tx will be sent once the condition clears")
+		return
+	}
 
-	msg := fmt.Sprintf("\x19Ethereum Signed Message:\n32%s", hash)
-	msgHash := crypto.Keccak256Hash([]byte(msg))
+	// guard against double fulfillment, e.g. after a crash between sending and recording the tx.
+	// The router deletes requests once fulfilled
+	exists, err := o.requestExistsOnChain(ctx, requestId)
+	if err != nil {
+		o.jobLogger(job).WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "sendFulfillmentTx",
+			"action":     "check request exists",
+			"request_id": requestId,
+		}).Error(err.Error())
+		return
+	}
 
-	signatureBytes, err := crypto.Sign(msgHash.Bytes(), o.oraclePrivateKey)
+	if !exists {
+		o.jobLogger(job).WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "sendFulfillmentTx",
+			"action":     "check request exists",
+			"request_id": requestId,
+		}).Warn("request already fulfilled - not sending")
+		_ = o.failRequest(requestId, models.REQUEST_STATUS_DUPLICATE, errcodes.ErrAlreadyFulfilled)
+		o.recordJobEvent(webhooks.EventSkipped, requestId)
+		return
+	}
 
+	reqIdBytes32, priceBigInt, signatureBytes, err := o.fulfillmentArgs(job)
 	if err != nil {
-		o.logger.WithFields(logrus.Fields{
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "sendFulfillmentTx",
 			"action":     "sign message",
 			"request_id": requestId,
 		}).Error(err.Error())
-		_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_TX_FAILED, err.Error())
+		tracing.FromContext(ctx).SetError(err)
+		o.failJob(requestId, models.REQUEST_STATUS_TX_FAILED, errcodes.Wrap(errcodes.ErrTxFailed, err))
 		return
 	}
 
-	// grr - https://ethereum.stackexchange.com/questions/45580/validating-go-ethereum-key-signature-with-ecrecover
-	signatureBytes[64] = uint8(int(signatureBytes[64])) + 27
+	data, err := o.contractAbi.Pack("fulfillRequest", reqIdBytes32, priceBigInt, signatureBytes)
+	if err != nil {
+		o.jobLogger(job).WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "sendFulfillmentTx",
+			"action":     "pack tx data",
+			"request_id": requestId,
+		}).Error(err.Error())
+		tracing.FromContext(ctx).SetError(err)
+		o.failJob(requestId, models.REQUEST_STATUS_TX_FAILED, errcodes.Wrap(errcodes.ErrTxFailed, err))
+		return
+	}
+
+	// sized for the consumer's callback, rather than the same limit for every consumer
+	gasEstimate, gasLimit, fits := o.fulfillmentGasLimit(ctx, job, data)
+	if !fits {
+		return
+	}
+
+	if !o.fulfillmentProfitable(ctx, job, data, gasEstimate) {
+		return
+	}
+
+	_, delaySpan := tracing.StartSpan(ctx, "tx.answer_delay")
+	delayed := o.delayAnswer(ctx, job, currentBlockNum)
+	delaySpan.End()
+	if !delayed {
+		return
+	}
+
+	_, paceSpan := tracing.StartSpan(ctx, "tx.pace")
+	release, ok := o.paceSubmission(ctx, requestId)
+	paceSpan.End()
+	if !ok {
+		// job timed out while bursts of txs were being spaced out. The timeout has already been recorded
+		return
+	}
+	defer release()
 
-	tx, err := o.contractInstance.FulfillRequest(o.transactOpts, reqIdBytes32, priceBigInt, signatureBytes)
+	_, lockSpan := tracing.StartSpan(ctx, "tx.wait_lock")
+	o.txMu.Lock()
+	defer o.txMu.Unlock()
+	lockSpan.End()
+
+	if ctx.Err() != nil {
+		// job timed out or was cancelled before the tx could be sent. Either has already been recorded
+		return
+	}
+
+	_, txSpan := tracing.StartSpan(ctx, "tx.submit")
+	txOpts, retiring, err := o.transactOptsFor(job.GetProvider())
+	var tx *types.Transaction
+	if err == nil {
+		if gasLimit > 0 {
+			opts := *txOpts
+			opts.GasLimit = gasLimit
+			txOpts = &opts
+		}
+		txOpts = o.escalateGasPrice(job, txOpts, currentBlockNum)
+		tx, err = o.sendJournaledTx(requestId, price, currentBlockNum, txOpts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return o.contractInstance.FulfillRequest(opts, reqIdBytes32, priceBigInt, signatureBytes)
+		})
+	}
+	if err == nil {
+		txSpan.SetAttribute("tx_hash", tx.Hash().Hex())
+		txSpan.SetAttribute("nonce", tx.Nonce())
+		txSpan.SetAttribute("gas_price", tx.GasPrice().String())
+	}
+	txSpan.SetError(err)
+	txSpan.End()
 
 	if err != nil {
-		o.logger.WithFields(logrus.Fields{
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "sendFulfillmentTx",
 			"action":     "send transaction",
 			"request_id": requestId,
 		}).Error(err.Error())
 
-		_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_TX_FAILED, err.Error())
+		tracing.FromContext(ctx).SetError(err)
+		if isRevert(err) {
+			err = errcodes.Wrap(errcodes.ErrTxReverted, err)
+		}
+		o.failJob(requestId, models.REQUEST_STATUS_TX_FAILED, errcodes.Wrap(errcodes.ErrTxFailed, err))
+
+		// e.g. the provider has been deregistered - check now, rather than sending more txs
+		// which will revert
+		if isRevert(err) {
+			o.supervisor.Go("router_conditions", func() {
+				o.CheckRouterConditions(true)
+			})
+		}
+		// txs were sent from the account since the nonce was last renewed. Resync now, so that
+		// the retry and the txs queued behind it use a free nonce
+		if isNonceTooLow(err) && !retiring {
+			_ = o.RenewTransactOpts()
+		}
 		return
 	}
 
-	o.logger.WithFields(logrus.Fields{
+	o.jobLogger(job).WithFields(logrus.Fields{
 		"package":    "chain",
 		"function":   "sendFulfillmentTx",
 		"action":     "send transaction",
@@ -295,17 +539,97 @@ func (o *OoORouterService) sendFulfillmentTx(job models.DataRequests, currentBlo
 		"tx":         tx.Hash().Hex(),
 	}).Info("fulfill tx sent")
 
-	_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_TX_SENT, "")
-	_ = o.db.UpdateFulfillmentSent(requestId, tx.Hash().Hex(), currentBlockNum)
+	_, dbSpan := tracing.StartSpan(ctx, "db.mark_sent")
+	_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_TX_SENT, "", "")
+	err = o.db.UpdateFulfillmentSent(requestId, tx.Hash().Hex(), currentBlockNum)
+	if err == nil {
+		// the queue tracks the receipt from here. An intent left unresolved is reconciled on restart
+		err = o.db.ResolveTxIntent(tx.Hash().Hex())
+	}
+	dbSpan.SetError(err)
+	dbSpan.End()
 
-	o.setNextTxNonce(tx.Nonce(), false)
+	if !retiring {
+		o.setNextTxNonce(tx.Nonce(), false)
+	}
 
 	_ = o.RenewTransactOpts()
 }
 
-func (o *OoORouterService) processPossiblyStuckDataFetch(job models.DataRequests, currentBlockNum uint64) {
+// fulfillmentArgs returns the args of the router's fulfillRequest for the job's fetched price -
+// the request id, the price and the provider's signature of them
+func (o *OoORouterService) fulfillmentArgs(job models.DataRequests) ([32]byte, *big.Int, []byte, error) {
 	requestId := job.GetRequestId()
-	o.logger.WithFields(logrus.Fields{
+	price := job.GetPriceResult()
+
+	// https://ethereum.stackexchange.com/questions/51566/from-golang-sha3-to-solidity-sha3
+	priceBigInt := big.NewInt(0)
+	priceBigInt.SetString(price, 10)
+
+	reqIdBytes := common.FromHex(requestId)
+	reqIdBytes32 := [32]byte{}
+	copy(reqIdBytes32[:], reqIdBytes)
+
+	hash := solsha3.SoliditySHA3(
+		solsha3.Bytes32(reqIdBytes),
+		solsha3.Uint256(price),
+		solsha3.Address(job.Consumer),
+	)
+
+	// requests are fulfilled by the key they were addressed to, which may be a retiring key
+	signatureBytes, err := o.signerFor(job.GetProvider()).SignText(hash)
+	if err != nil {
+		return reqIdBytes32, priceBigInt, nil, err
+	}
+
+	// grr - https://ethereum.stackexchange.com/questions/45580/validating-go-ethereum-key-signature-with-ecrecover
+	signatureBytes[64] = uint8(int(signatureBytes[64])) + 27
+
+	return reqIdBytes32, priceBigInt, signatureBytes, nil
+}
+
+// recoverFulfilledEvent looks for a missed RequestFulfilled event for the request and, if
+// found, processes it. Returns true if the event was found
+func (o *OoORouterService) recoverFulfilledEvent(requestId string, fromBlock uint64) (bool, error) {
+	reqIdBytes32 := [32]byte{}
+	copy(reqIdBytes32[:], common.FromHex(requestId))
+
+	opts := *o.historicalFilterOpts
+	opts.Start = fromBlock
+
+	itrFr, err := o.contractInstance.FilterRequestFulfilled(&opts, nil, nil, [][32]byte{reqIdBytes32})
+	if err != nil {
+		return false, err
+	}
+	defer itrFr.Close()
+
+	found := false
+	for itrFr.Next() {
+		o.processIncomingFulfilments(itrFr.Event)
+		found = true
+	}
+
+	return found, itrFr.Error()
+}
+
+// requestExistsOnChain returns false if the request is no longer pending in the router
+func (o *OoORouterService) requestExistsOnChain(ctx context.Context, requestId string) (bool, error) {
+	reqIdBytes32 := [32]byte{}
+	copy(reqIdBytes32[:], common.FromHex(requestId))
+
+	callOpts := *o.callOpts
+	callOpts.Context = ctx
+
+	exists, err := o.contractInstance.RequestExists(&callOpts, reqIdBytes32)
+	if err != nil {
+		rpcError("RequestExists")
+	}
+	return exists, err
+}
+
+func (o *OoORouterService) processPossiblyStuckDataFetch(ctx context.Context, job models.DataRequests, currentBlockNum uint64) {
+	requestId := job.GetRequestId()
+	o.jobLogger(job).WithFields(logrus.Fields{
 		"package":    "chain",
 		"function":   "processPossiblyStuckDataFetch",
 		"action":     "start",
@@ -313,17 +637,8 @@ func (o *OoORouterService) processPossiblyStuckDataFetch(job models.DataRequests
 	}).Debug("begin processing possibly stuck data fetch")
 
 	// at some point, we just have to stop trying...
-	if job.GetFulfillmentAttempts() >= 3 {
-		// too many fails
-		o.logger.WithFields(logrus.Fields{
-			"package":      "chain",
-			"function":     "processPossiblyStuckDataFetch",
-			"action":       "check num attempts",
-			"request_id":   requestId,
-			"num_attempts": job.GetFulfillmentAttempts(),
-		}).Warn()
-
-		_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_FULFILMENT_FAILED, "too many failed attempts")
+	if job.GetFulfillmentAttempts() >= maxJobAttempts() {
+		o.deadLetterJob(requestId, job.GetFulfillmentAttempts(), job.GetErrorCode(), job.GetStatusReason())
 		return
 	}
 
@@ -331,7 +646,7 @@ func (o *OoORouterService) processPossiblyStuckDataFetch(job models.DataRequests
 
 	// still relatively new - ignore
 	if lastFetchBlockDiff < 5 {
-		o.logger.WithFields(logrus.Fields{
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processPossiblyStuckDataFetch",
 			"action":     "check request age",
@@ -343,26 +658,27 @@ func (o *OoORouterService) processPossiblyStuckDataFetch(job models.DataRequests
 	requestBlockDiff := currentBlockNum - job.RequestBlockNumber
 
 	// is the request > 1 hour old?
-	if requestBlockDiff > 250 {
-		o.logger.WithFields(logrus.Fields{
+	if requestBlockDiff > RequestMaxAge {
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processPossiblyStuckDataFetch",
 			"action":     "check request age",
 			"request_id": requestId,
 		}).Warn("request too old")
-		_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_FULFILMENT_FAILED, "request too old")
+		_ = o.failRequest(requestId, models.REQUEST_STATUS_FULFILMENT_FAILED, errcodes.ErrRequestExpired)
+		o.recordJobEvent(webhooks.EventFailed, requestId)
 		return
 	}
 
 	// finally, try to re-fetch data for fulfillment
-	o.processFulfillmentFetchData(job, currentBlockNum)
+	o.processFulfillmentFetchData(ctx, job, currentBlockNum)
 }
 
 // processSendFailedJob will try to see why a fulfilment didn't even send, and resend
-func (o *OoORouterService) processSendFailedJob(job models.DataRequests, currentBlockNum uint64) {
+func (o *OoORouterService) processSendFailedJob(ctx context.Context, job models.DataRequests, currentBlockNum uint64) {
 
 	requestId := job.GetRequestId()
-	o.logger.WithFields(logrus.Fields{
+	o.jobLogger(job).WithFields(logrus.Fields{
 		"package":    "chain",
 		"function":   "processSendFailedJob",
 		"action":     "start",
@@ -373,42 +689,34 @@ func (o *OoORouterService) processSendFailedJob(job models.DataRequests, current
 	_ = o.db.InsertNewFailedFulfilment(requestId, "", 0, 0, job.GetStatusReason())
 
 	// at some point, we just have to stop trying...
-	if job.GetFulfillmentAttempts() >= 3 {
-		// too many fails
-		o.logger.WithFields(logrus.Fields{
-			"package":      "chain",
-			"function":     "processSendFailedJob",
-			"action":       "check num attempts",
-			"request_id":   requestId,
-			"num_attempts": job.GetFulfillmentAttempts(),
-		}).Warn("too many failed attempts")
-
-		_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_FULFILMENT_FAILED, "too many failed attempts")
+	if job.GetFulfillmentAttempts() >= maxJobAttempts() {
+		o.deadLetterJob(requestId, job.GetFulfillmentAttempts(), job.GetErrorCode(), job.GetStatusReason())
 		return
 	}
 
 	requestBlockDiff := currentBlockNum - job.RequestBlockNumber
 
 	// is the request > 1 hour old?
-	if requestBlockDiff > 250 {
-		o.logger.WithFields(logrus.Fields{
+	if requestBlockDiff > RequestMaxAge {
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processSendFailedJob",
 			"action":     "check request age",
 			"request_id": requestId,
 		}).Warn("request too old")
-		_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_FULFILMENT_FAILED, "request too old")
+		_ = o.failRequest(requestId, models.REQUEST_STATUS_FULFILMENT_FAILED, errcodes.ErrRequestExpired)
+		o.recordJobEvent(webhooks.EventFailed, requestId)
 		return
 	}
 
 	// finally, try to re-fetch data for fulfillment
-	o.processFulfillmentFetchData(job, currentBlockNum)
+	o.processFulfillmentFetchData(ctx, job, currentBlockNum)
 
 }
 
-func (o *OoORouterService) processPossiblyStuckSentTx(job models.DataRequests, currentBlockNum uint64) {
+func (o *OoORouterService) processPossiblyStuckSentTx(ctx context.Context, job models.DataRequests, currentBlockNum uint64) {
 	requestId := job.GetRequestId()
-	o.logger.WithFields(logrus.Fields{
+	o.jobLogger(job).WithFields(logrus.Fields{
 		"package":    "chain",
 		"function":   "processPossiblyStuckSentTx",
 		"action":     "start",
@@ -418,7 +726,7 @@ func (o *OoORouterService) processPossiblyStuckSentTx(job models.DataRequests, c
 	lastFulfillSentBlockDiff := currentBlockNum - job.GetLastFulfillSentBlockNumber()
 	if lastFulfillSentBlockDiff < 3 {
 		// too soon - may take a while for Tx to be broadcast/picked up
-		o.logger.WithFields(logrus.Fields{
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processPossiblyStuckSentTx",
 			"action":     "check block diff since fulfill tx sent",
@@ -430,11 +738,11 @@ func (o *OoORouterService) processPossiblyStuckSentTx(job models.DataRequests, c
 
 	fulfilTxHash := common.HexToHash(job.GetFulfillTxHash())
 	// check if it's pending
-	_, isPending, err := o.client.TransactionByHash(o.context, fulfilTxHash)
+	pendingTx, isPending, err := o.client.TransactionByHash(ctx, fulfilTxHash)
 
 	if err != nil {
 		// possibly not in Tx pool yet
-		o.logger.WithFields(logrus.Fields{
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processPossiblyStuckSentTx",
 			"action":     "get fulfill tx",
@@ -444,9 +752,13 @@ func (o *OoORouterService) processPossiblyStuckSentTx(job models.DataRequests, c
 		return
 	}
 
-	// no point continuing if it's still pending. Log it and move on.
+	// no point continuing if it's still pending, unless the request's deadline is close enough
+	// to replace it at an escalated gas price. Log it and move on.
 	if isPending {
-		o.logger.WithFields(logrus.Fields{
+		if o.replaceFulfillmentTx(ctx, job, pendingTx, currentBlockNum) {
+			return
+		}
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processPossiblyStuckSentTx",
 			"action":     "check fulfill tx pending",
@@ -457,9 +769,9 @@ func (o *OoORouterService) processPossiblyStuckSentTx(job models.DataRequests, c
 	}
 
 	// try and get the receipt
-	fulfillReceipt, err := o.client.TransactionReceipt(o.context, fulfilTxHash)
+	fulfillReceipt, err := o.client.TransactionReceipt(ctx, fulfilTxHash)
 	if err != nil {
-		o.logger.WithFields(logrus.Fields{
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processPossiblyStuckSentTx",
 			"action":     "get fulfil tx receipt",
@@ -473,32 +785,19 @@ func (o *OoORouterService) processPossiblyStuckSentTx(job models.DataRequests, c
 		// Tx was successful. double check for RandomnessRequestFulfilled event
 		// in case it was missed
 
-		o.logger.WithFields(logrus.Fields{
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processPossiblyStuckSentTx",
 			"action":     "check fulfill tx status",
 			"request_id": requestId,
 		}).Info("tx was successful. check for RequestFulfilled event")
-		reqIdBytes := common.FromHex(requestId)
-		reqIdBytes32 := [32]byte{}
-		copy(reqIdBytes32[:], reqIdBytes)
-		reqArr := make([][32]byte, 0, 1)
-		reqArr = append(reqArr, reqIdBytes32)
-		opts := o.historicalFilterOpts
-		opts.Start = job.RequestBlockNumber
-		itrFr, err := o.contractInstance.FilterRequestFulfilled(opts, nil, nil, reqArr)
+		_, err = o.recoverFulfilledEvent(requestId, job.RequestBlockNumber)
 		if err != nil {
-			o.logger.WithFields(logrus.Fields{
+			o.jobLogger(job).WithFields(logrus.Fields{
 				"package":  "chain",
 				"function": "processPossiblyStuckSentTx",
 				"action":   "get FilterRequestFulfilled events",
 			}).Error(err.Error())
-			return
-		}
-
-		for itrFr.Next() {
-			event := itrFr.Event
-			o.processIncomingFulfilments(event)
 		}
 		return
 	}
@@ -511,38 +810,48 @@ func (o *OoORouterService) processPossiblyStuckSentTx(job models.DataRequests, c
 
 	// Add fail info to failed Tx history table
 	_ = o.db.InsertNewFailedFulfilment(requestId, fulfilTxHash.Hex(), failedGasUsed, failedGasPrice, failReason)
+	o.recordGasSpend(requestId, job.RequestBlockNumber, fulfilTxHash, fulfillReceipt.BlockNumber.Uint64(), fulfillReceipt.GasUsed, false)
 
-	// at some point, we just have to stop trying...
-	if job.GetFulfillmentAttempts() >= 3 {
-		// too many fails
-		o.logger.WithFields(logrus.Fields{
-			"package":      "chain",
-			"function":     "processSendFailedJob",
-			"action":       "check num attempts",
-			"request_id":   requestId,
-			"num_attempts": job.GetFulfillmentAttempts(),
-		}).Warn("too many failed attempts")
+	// before retrying, check the revert was not due to the router's conditions
+	o.CheckRouterConditions(true)
 
-		_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_FULFILMENT_FAILED, "too many failed attempts")
+	// at some point, we just have to stop trying...
+	if job.GetFulfillmentAttempts() >= maxJobAttempts() {
+		o.deadLetterJob(requestId, job.GetFulfillmentAttempts(), string(errcodes.CodeTxReverted), failReason)
 		return
 	}
 
 	requestBlockDiff := currentBlockNum - job.RequestBlockNumber
 
 	// is the request > 1 hour?
-	if requestBlockDiff > 250 {
-		o.logger.WithFields(logrus.Fields{
+	if requestBlockDiff > RequestMaxAge {
+		o.jobLogger(job).WithFields(logrus.Fields{
 			"package":    "chain",
 			"function":   "processSendFailedJob",
 			"action":     "check request age",
 			"request_id": requestId,
 		}).Warn("request too old")
-		_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_FULFILMENT_FAILED, "request too old")
+		_ = o.failRequest(requestId, models.REQUEST_STATUS_FULFILMENT_FAILED, errcodes.ErrRequestExpired)
+		o.recordJobEvent(webhooks.EventFailed, requestId)
+		return
+	}
+
+	// a price fetched long enough ago to have gone stale is fetched again, rather than resubmitted
+	if priceStale(job) {
+		o.jobLogger(job).WithFields(logrus.Fields{
+			"package":         "chain",
+			"function":        "processPossiblyStuckSentTx",
+			"action":          "check price freshness",
+			"request_id":      requestId,
+			"data_fetched_at": job.GetDataFetchedAt(),
+		}).Info("price stale - fetching again before resubmitting")
+		repricesTotal.WithLabelValues(RepriceReverted).Inc()
+		o.processFulfillmentFetchData(ctx, job, currentBlockNum)
 		return
 	}
 
 	// finally, try to send a new fulfillment
-	o.sendFulfillmentTx(job, currentBlockNum)
+	o.sendFulfillmentTx(ctx, job, currentBlockNum)
 
 	return
 }
@@ -0,0 +1,213 @@
+package chain
+
+import (
+	"encoding/json"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+	"go-ooo/database/models"
+)
+
+func (o *OoORouterService) journal(kind string, requestId string, txHash string, nonce uint64,
+	rawTx string, blockNumber uint64, data string) {
+	err := o.db.InsertJournalEntry(kind, requestId, txHash, nonce, rawTx, blockNumber, data)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "journal",
+			"kind":       kind,
+			"request_id": requestId,
+		}).Error(err.Error())
+	}
+}
+
+// txIntent is the data of a tx intent journal entry - the value being fulfilled with and the
+// gas settings it was signed with
+type txIntent struct {
+	Value     string `json:"value"`
+	Sender    string `json:"sender"`
+	Gas       uint64 `json:"gas"`
+	GasPrice  string `json:"gas_price,omitempty"`
+	GasTipCap string `json:"gas_tip_cap,omitempty"`
+	GasFeeCap string `json:"gas_fee_cap,omitempty"`
+}
+
+func newTxIntent(value string, sender common.Address, tx *types.Transaction) txIntent {
+	intent := txIntent{
+		Value:  value,
+		Sender: sender.Hex(),
+		Gas:    tx.Gas(),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		intent.GasTipCap = tx.GasTipCap().String()
+		intent.GasFeeCap = tx.GasFeeCap().String()
+	} else {
+		intent.GasPrice = tx.GasPrice().String()
+	}
+	return intent
+}
+
+// sendJournaledTx signs the tx built by buildTx with a copy of baseOpts, records the intended tx,
+// with the value it fulfills the request with, in the journal and only then broadcasts it. Once
+// broadcast, the intent is marked as such, but left unresolved until the caller has recorded the
+// request as sent and calls ResolveTxIntent. If the node crashes at any point, ReconcileJournal
+// can determine whether the tx was broadcast. Must be called with txMu held
+func (o *OoORouterService) sendJournaledTx(requestId string, value string, currentBlockNum uint64, baseOpts *bind.TransactOpts,
+	buildTx func(opts *bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, error) {
+
+	opts := *baseOpts
+	opts.NoSend = true
+
+	tx, err := buildTx(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rawTx, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	txHash := tx.Hash().Hex()
+
+	data, err := json.Marshal(newTxIntent(value, opts.From, tx))
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.db.InsertJournalEntry(models.JOURNAL_KIND_TX_INTENT, requestId, txHash, tx.Nonce(),
+		hexutil.Encode(rawTx), currentBlockNum, string(data))
+	if err != nil {
+		// never broadcast a tx which can't be accounted for after a crash
+		return nil, err
+	}
+
+	err = o.client.SendTransaction(o.context, tx)
+	if err != nil {
+		rpcError("SendTransaction")
+		o.journal(models.JOURNAL_KIND_TX_FAILED, requestId, txHash, tx.Nonce(), "", currentBlockNum, err.Error())
+		_ = o.db.ResolveTxIntent(txHash)
+		return nil, err
+	}
+
+	o.journal(models.JOURNAL_KIND_TX_BROADCAST, requestId, txHash, tx.Nonce(), "", currentBlockNum, "")
+	if err = o.db.MarkTxIntentBroadcast(txHash); err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "sendJournaledTx",
+			"request_id": requestId,
+			"tx_hash":    txHash,
+		}).Error("cannot mark tx intent as broadcast: ", err.Error())
+	}
+
+	return tx, nil
+}
+
+// ReconcileJournal resolves fulfillment txs which were signed but not recorded as sent, e.g.
+// because the node crashed, and updates the requests accordingly. Intents which were never
+// broadcast are broadcast as originally signed, if their nonce is still free. Intents which
+// were broadcast are tracked from their tx hash, and are only re-sent if the tx was dropped
+func (o *OoORouterService) ReconcileJournal() {
+	intents, err := o.db.GetUnresolvedTxIntents()
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "ReconcileJournal",
+			"action":   "get unresolved tx intents",
+		}).Error(err.Error())
+		return
+	}
+
+	for _, intent := range intents {
+		o.reconcileTxIntent(intent)
+		_ = o.db.ResolveTxIntent(intent.GetTxHash())
+	}
+}
+
+func (o *OoORouterService) reconcileTxIntent(intent models.JournalEntries) {
+	requestId := intent.GetRequestId()
+	txHash := intent.GetTxHash()
+
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "reconcileTxIntent",
+		"request_id": requestId,
+		"tx_hash":    txHash,
+		"nonce":      intent.GetNonce(),
+		"broadcast":  intent.GetBroadcast(),
+	})
+
+	if intent.GetBroadcast() {
+		logger.Info("reconciling tx which was broadcast, but whose outcome is unknown")
+	} else {
+		logger.Info("reconciling tx which was signed, but never broadcast")
+	}
+
+	job, err := o.db.FindByRequestId(requestId)
+	if err != nil || job.GetJobStatus() != models.JOB_STATUS_PENDING {
+		// already resolved
+		return
+	}
+
+	markSent := func() {
+		logger.Info("journaled tx was broadcast")
+		_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_TX_SENT, "", "")
+		_ = o.db.UpdateFulfillmentSent(requestId, txHash, intent.GetBlockNumber())
+	}
+
+	// known to the node - either pending or mined. The job queue will track it from here
+	_, _, err = o.client.TransactionByHash(o.context, common.HexToHash(txHash))
+	if err == nil {
+		markSent()
+		return
+	}
+
+	resend := func(reason string) {
+		logger.Warn(reason)
+		_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_DATA_READY_TO_SEND, "", reason)
+	}
+
+	rawTx, err := hexutil.Decode(intent.GetRawTx())
+	if err != nil {
+		resend("cannot decode journaled tx - resend")
+		return
+	}
+
+	tx := new(types.Transaction)
+	if err = tx.UnmarshalBinary(rawTx); err != nil {
+		resend("cannot decode journaled tx - resend")
+		return
+	}
+
+	// the tx may have been sent from a retiring key
+	sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		resend("cannot decode journaled tx - resend")
+		return
+	}
+
+	pendingNonce, err := o.client.PendingNonceAt(o.context, sender)
+	if err != nil {
+		resend("cannot check nonce for journaled tx - resend")
+		return
+	}
+
+	if pendingNonce > intent.GetNonce() {
+		// nonce used by another tx, so this tx will never be mined
+		resend("journaled tx nonce already used - resend")
+		return
+	}
+
+	// never broadcast, or broadcast and dropped - broadcast the signed tx as originally intended
+
+	if err = o.client.SendTransaction(o.context, tx); err != nil {
+		o.journal(models.JOURNAL_KIND_TX_FAILED, requestId, txHash, tx.Nonce(), "", intent.GetBlockNumber(), err.Error())
+		resend("cannot broadcast journaled tx - resend")
+		return
+	}
+
+	o.journal(models.JOURNAL_KIND_TX_BROADCAST, requestId, txHash, tx.Nonce(), "", intent.GetBlockNumber(), "")
+	markSent()
+}
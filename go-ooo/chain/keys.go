@@ -0,0 +1,164 @@
+package chain
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/signer"
+	go_ooo_types "go-ooo/types"
+	"math/big"
+)
+
+const (
+	KeyRoleOracle   = "oracle"
+	KeyRoleRetiring = "retiring"
+	KeyRoleVor      = "vor"
+)
+
+var keyBalanceGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "ooo_key_balance_wei",
+	Help: "ETH balance, in wei, of each key the node sends txs with, by role and address",
+}, []string{"role", "address"})
+
+// Signers are the keys the node signs with. Only Oracle is required - Retiring is set during a
+// key rotation, and Vor if the VOR coordinator is assigned its own key
+type Signers struct {
+	Oracle   signer.Signer
+	Retiring signer.Signer
+	Vor      signer.Signer
+}
+
+// accountKey is a key, other than the oracle key, which the node sends txs with
+type accountKey struct {
+	address      common.Address
+	signer       signer.Signer
+	transactOpts *bind.TransactOpts
+}
+
+func (o *OoORouterService) newAccountKey(keySigner signer.Signer) (*accountKey, error) {
+	transactOpts, err := signer.NewTransactOpts(keySigner, big.NewInt(viper.GetInt64(config.ChainNetworkId)))
+	if err != nil {
+		return nil, err
+	}
+	transactOpts.Value = big.NewInt(0)
+	transactOpts.Context = o.context
+
+	return &accountKey{
+		address:      keySigner.Address(),
+		signer:       keySigner,
+		transactOpts: transactOpts,
+	}, nil
+}
+
+// initVorKey sets up the VOR coordinator's own key, if it is assigned one
+func (o *OoORouterService) initVorKey(vorSigner signer.Signer) error {
+	if vorSigner == nil {
+		return nil
+	}
+
+	key, err := o.newAccountKey(vorSigner)
+	if err != nil {
+		return err
+	}
+	o.vorKey = key
+
+	o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "initVorKey",
+		"address":  key.address.Hex(),
+	}).Info("VOR uses its own key")
+
+	return nil
+}
+
+// keyTransactOpts returns transact opts for k, with the gas price and limit of the oracle's opts.
+// These keys send few txs, so the nonce is fetched for each. Must be called with txMu held, after
+// RenewTransactOpts
+func (o *OoORouterService) keyTransactOpts(k *accountKey) (*bind.TransactOpts, error) {
+	nonce, err := o.client.PendingNonceAt(o.context, k.address)
+	if err != nil {
+		rpcError("PendingNonceAt")
+		return nil, err
+	}
+
+	opts := *k.transactOpts
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	opts.GasPrice = o.transactOpts.GasPrice
+	opts.GasLimit = o.transactOpts.GasLimit
+
+	return &opts, nil
+}
+
+// vorTransactOpts returns the transact opts for VOR txs. Must be called with txMu held, after
+// RenewTransactOpts
+func (o *OoORouterService) vorTransactOpts() (*bind.TransactOpts, error) {
+	if o.vorKey == nil {
+		return o.transactOpts, nil
+	}
+	return o.keyTransactOpts(o.vorKey)
+}
+
+// vorAddress returns the address VOR txs are sent from, and its proving key registered for
+func (o *OoORouterService) vorAddress() common.Address {
+	if o.vorKey != nil {
+		return o.vorKey.address
+	}
+	return o.oracleAddress
+}
+
+// keyAddresses returns the address of each key the node sends txs with, by role
+func (o *OoORouterService) keyAddresses() map[string]common.Address {
+	keys := map[string]common.Address{KeyRoleOracle: o.oracleAddress}
+	if o.retiring != nil {
+		keys[KeyRoleRetiring] = o.retiring.address
+	}
+	if o.vorKey != nil {
+		keys[KeyRoleVor] = o.vorKey.address
+	}
+	return keys
+}
+
+// KeyBalances returns the ETH and xFUND balances of each key the node sends txs with, oracle
+// key first
+func (o *OoORouterService) KeyBalances(ctx context.Context) ([]go_ooo_types.KeyBalance, error) {
+	keys := o.keyAddresses()
+	balances := make([]go_ooo_types.KeyBalance, 0, len(keys))
+
+	for _, role := range []string{KeyRoleOracle, KeyRoleRetiring, KeyRoleVor} {
+		address, ok := keys[role]
+		if !ok {
+			continue
+		}
+
+		balance, err := o.client.BalanceAt(ctx, address, nil)
+		if err != nil {
+			rpcError("BalanceAt")
+			return balances, err
+		}
+		keyBalanceGauge.WithLabelValues(role, address.Hex()).Set(weiToFloat(balance))
+
+		xfundBalance, err := XfundBalanceOf(&bind.CallOpts{From: address, Context: ctx}, o.client, o.contractInstance, address)
+		if err != nil {
+			return balances, err
+		}
+
+		balances = append(balances, go_ooo_types.KeyBalance{
+			Role:          role,
+			Address:       address.Hex(),
+			WalletBalance: balance.String(),
+			XfundBalance:  xfundBalance.String(),
+		})
+	}
+
+	return balances, nil
+}
+
+func weiToFloat(wei *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(wei).Float64()
+	return f
+}
@@ -0,0 +1,221 @@
+package chain
+
+import (
+	"fmt"
+	"github.com/montanaflynn/stats"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	"go-ooo/ooo_api"
+	go_ooo_types "go-ooo/types"
+	"strings"
+	"time"
+)
+
+// Fulfillment processing stages, from the request event being seen to the fulfillment tx being mined
+const (
+	latencyStageFetch = "fetch" // event seen -> data fetched
+	latencyStageSend  = "send"  // data fetched -> tx sent
+	latencyStageMine  = "mine"  // tx sent -> tx mined
+	latencyStageTotal = "total" // event seen -> tx mined
+)
+
+var (
+	latencyBuckets = []float64{1, 2, 5, 10, 15, 30, 60, 120, 300, 600}
+
+	fulfillmentLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ooo_fulfillment_latency_seconds",
+		Help:    "Time taken by each stage of fulfilling a request",
+		Buckets: latencyBuckets,
+	}, []string{"stage", "pair"})
+
+	sourceFulfillmentLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ooo_source_fulfillment_latency_seconds",
+		Help:    "Time taken from a request event being seen to its fulfillment being mined, by answer source",
+		Buckets: latencyBuckets,
+	}, []string{"source"})
+
+	fulfillmentSloBreaches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_fulfillment_slo_breaches_total",
+		Help: "Number of fulfillments which took longer than the latency SLO",
+	}, []string{"pair"})
+)
+
+// jobLatency is the time taken by each stage of a fulfilled job, in seconds
+type jobLatency struct {
+	fetch float64
+	send  float64
+	mine  float64
+	total float64
+}
+
+func latencySlo() time.Duration {
+	return time.Duration(viper.GetInt64(config.JobsLatencySlo)) * time.Second
+}
+
+// getJobLatency returns false if the job was not timed through every stage, e.g.
+// because it was fulfilled by another node, or manually
+func getJobLatency(job models.DataRequests) (jobLatency, bool) {
+	seenAt := job.CreatedAt.UnixNano() / int64(time.Millisecond)
+	fetchedAt := job.GetDataFetchedAt()
+	sentAt := job.GetTxSentAt()
+	minedAt := job.GetTxMinedAt()
+
+	if fetchedAt == 0 || sentAt == 0 || minedAt == 0 || fetchedAt < seenAt || sentAt < fetchedAt {
+		return jobLatency{}, false
+	}
+
+	toSeconds := func(ms int64) float64 {
+		if ms < 0 {
+			// mined event processed before the tx was recorded as sent
+			return 0
+		}
+		return float64(ms) / 1000
+	}
+
+	return jobLatency{
+		fetch: toSeconds(fetchedAt - seenAt),
+		send:  toSeconds(sentAt - fetchedAt),
+		mine:  toSeconds(minedAt - sentAt),
+		total: toSeconds(minedAt - seenAt),
+	}, true
+}
+
+func latencyPair(job models.DataRequests) string {
+	base, target, _, _, _, _, _, err := ooo_api.ParseEndpoint(job.GetEndpointDecoded())
+	if err != nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s.%s", base, target)
+}
+
+// observeFulfillmentLatency records the timing breakdown of a job once its fulfillment has been mined
+func (o *OoORouterService) observeFulfillmentLatency(requestId string) {
+	job, err := o.db.FindByRequestId(requestId)
+	if err != nil {
+		return
+	}
+
+	l, ok := getJobLatency(job)
+	if !ok {
+		return
+	}
+
+	pair := pairLabels.value(latencyPair(job))
+
+	fulfillmentLatency.WithLabelValues(latencyStageFetch, pair).Observe(l.fetch)
+	fulfillmentLatency.WithLabelValues(latencyStageSend, pair).Observe(l.send)
+	fulfillmentLatency.WithLabelValues(latencyStageMine, pair).Observe(l.mine)
+	fulfillmentLatency.WithLabelValues(latencyStageTotal, pair).Observe(l.total)
+
+	if attestation, err := o.db.GetAttestationByRequestId(requestId); err == nil {
+		for _, source := range strings.Split(attestation.Sources, ",") {
+			if source != "" {
+				sourceFulfillmentLatency.WithLabelValues(source).Observe(l.total)
+			}
+		}
+	}
+
+	if slo := latencySlo(); slo > 0 && l.total > slo.Seconds() {
+		fulfillmentSloBreaches.WithLabelValues(pair).Inc()
+	}
+}
+
+// LatencyReport returns fulfillment latency percentiles, overall and by pair and source,
+// for requests fulfilled since the given time
+func (o *OoORouterService) LatencyReport(since time.Time) (go_ooo_types.LatencyReport, error) {
+	slo := latencySlo()
+
+	report := go_ooo_types.LatencyReport{
+		Since:      since.Unix(),
+		SloSeconds: int64(slo.Seconds()),
+		Pairs:      make(map[string]go_ooo_types.LatencyStats),
+		Sources:    make(map[string]go_ooo_types.LatencyStats),
+	}
+
+	jobs, err := o.db.GetFulfilledRequestsSince(since)
+	if err != nil {
+		return report, err
+	}
+
+	requestIds := make([]string, 0, len(jobs))
+	for _, j := range jobs {
+		requestIds = append(requestIds, j.GetRequestId())
+	}
+
+	attestations, err := o.db.GetAttestationsByRequestIds(requestIds)
+	if err != nil {
+		return report, err
+	}
+
+	sources := make(map[string][]string)
+	for _, a := range attestations {
+		sources[a.GetRequestId()] = strings.Split(a.Sources, ",")
+	}
+
+	var overall []jobLatency
+	byPair := make(map[string][]jobLatency)
+	bySource := make(map[string][]jobLatency)
+
+	for _, j := range jobs {
+		l, ok := getJobLatency(j)
+		if !ok {
+			continue
+		}
+		overall = append(overall, l)
+		pair := latencyPair(j)
+		byPair[pair] = append(byPair[pair], l)
+		for _, s := range sources[j.GetRequestId()] {
+			if s != "" {
+				bySource[s] = append(bySource[s], l)
+			}
+		}
+	}
+
+	report.Overall = latencyStats(overall, slo)
+	for pair, l := range byPair {
+		report.Pairs[pair] = latencyStats(l, slo)
+	}
+	for source, l := range bySource {
+		report.Sources[source] = latencyStats(l, slo)
+	}
+
+	return report, nil
+}
+
+func latencyStats(latencies []jobLatency, slo time.Duration) go_ooo_types.LatencyStats {
+	res := go_ooo_types.LatencyStats{Count: len(latencies)}
+	if len(latencies) == 0 {
+		return res
+	}
+
+	var fetch, send, mine, total stats.Float64Data
+	withinSlo := 0
+
+	for _, l := range latencies {
+		fetch = append(fetch, l.fetch)
+		send = append(send, l.send)
+		mine = append(mine, l.mine)
+		total = append(total, l.total)
+		if slo <= 0 || l.total <= slo.Seconds() {
+			withinSlo++
+		}
+	}
+
+	res.WithinSloPct = float64(withinSlo) / float64(len(latencies)) * 100
+	res.Fetch = latencyPercentiles(fetch)
+	res.Send = latencyPercentiles(send)
+	res.Mine = latencyPercentiles(mine)
+	res.Total = latencyPercentiles(total)
+
+	return res
+}
+
+func latencyPercentiles(data stats.Float64Data) go_ooo_types.LatencyPercentiles {
+	p50, _ := data.Percentile(50)
+	p90, _ := data.Percentile(90)
+	p99, _ := data.Percentile(99)
+	return go_ooo_types.LatencyPercentiles{P50: p50, P90: p90, P99: p99}
+}
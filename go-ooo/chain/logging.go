@@ -0,0 +1,23 @@
+package chain
+
+import (
+	"github.com/sirupsen/logrus"
+	"go-ooo/database/models"
+)
+
+// jobLogger returns a logger carrying the fields identifying a job, so that every
+// log line from the fulfillment path can be searched by request, pair, consumer and tx
+func (o *OoORouterService) jobLogger(job models.DataRequests) *logrus.Entry {
+	fields := logrus.Fields{
+		"request_id": job.GetRequestId(),
+		"consumer":   job.GetConsumer(),
+		"endpoint":   job.GetEndpointDecoded(),
+		"pair":       latencyPair(job),
+	}
+
+	if txHash := job.GetFulfillTxHash(); txHash != "" {
+		fields["tx_hash"] = txHash
+	}
+
+	return o.logger.WithFields(fields)
+}
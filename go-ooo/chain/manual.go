@@ -0,0 +1,99 @@
+package chain
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"go-ooo/database/models"
+	"go-ooo/errcodes"
+	"go-ooo/ooo_api"
+	"go-ooo/webhooks"
+)
+
+// ManualSourceName - source recorded in attestations for operator supplied values
+const ManualSourceName = "manual"
+
+// ForceFulfill sets an operator supplied value for a request which has not yet been fulfilled.
+// The value must already be scaled to the answer decimals. A data fetch in progress for the
+// request is cancelled. The fulfillment is sent on the next job queue check
+func (o *OoORouterService) ForceFulfill(requestId string, price string) error {
+	job, err := o.db.FindByRequestId(requestId)
+	if err != nil {
+		return fmt.Errorf("request %s not found", requestId)
+	}
+
+	if job.GetRequestStatus() == models.REQUEST_STATUS_SUCCESS || job.GetRequestStatus() == models.REQUEST_STATUS_TX_SENT {
+		return fmt.Errorf("request %s already %s", requestId, job.GetRequestStatusString())
+	}
+
+	err = ooo_api.ValidatePriceResult(price)
+	if err != nil {
+		return err
+	}
+
+	if o.cancelJob(requestId, "manual value set by operator") {
+		// the job may have sent its fulfillment before it could be cancelled
+		job, err = o.db.FindByRequestId(requestId)
+		if err != nil {
+			return err
+		}
+		if job.GetRequestStatus() == models.REQUEST_STATUS_SUCCESS || job.GetRequestStatus() == models.REQUEST_STATUS_TX_SENT {
+			return fmt.Errorf("request %s already %s", requestId, job.GetRequestStatusString())
+		}
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "ForceFulfill",
+		"request_id": requestId,
+		"price":      price,
+	}).Warn("manual value set for request")
+
+	err = o.db.UpdateManualFulfillment(requestId, price, "manual value supplied by operator")
+	if err != nil {
+		return err
+	}
+
+	o.saveRequestSources(requestId, o.oooApi.ExplainSingleSource(job.GetEndpointDecoded(), price, ManualSourceName))
+	o.createAttestation(requestId, job.GetEndpointDecoded(), price, []string{ManualSourceName})
+
+	return nil
+}
+
+// SkipJob marks a pending request as skipped, so that it is not fulfilled. A data fetch in
+// progress for the request is cancelled
+func (o *OoORouterService) SkipJob(requestId string) error {
+	job, err := o.db.FindByRequestId(requestId)
+	if err != nil {
+		return fmt.Errorf("request %s not found", requestId)
+	}
+
+	if job.GetJobStatus() != models.JOB_STATUS_PENDING {
+		return fmt.Errorf("request %s is not pending", requestId)
+	}
+
+	if o.cancelJob(requestId, "skipped by operator") {
+		// the job may have sent its fulfillment before it could be cancelled
+		job, err = o.db.FindByRequestId(requestId)
+		if err != nil {
+			return err
+		}
+		if job.GetJobStatus() != models.JOB_STATUS_PENDING || job.GetRequestStatus() == models.REQUEST_STATUS_TX_SENT {
+			return fmt.Errorf("request %s already %s", requestId, job.GetRequestStatusString())
+		}
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "SkipJob",
+		"request_id": requestId,
+	}).Warn("request skipped by operator")
+
+	err = o.failRequest(requestId, models.REQUEST_STATUS_SKIPPED_MANUAL, errcodes.ErrSkippedByOperator)
+	if err != nil {
+		return err
+	}
+
+	o.recordJobEvent(webhooks.EventSkipped, requestId)
+
+	return nil
+}
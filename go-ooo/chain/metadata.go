@@ -0,0 +1,29 @@
+package chain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// alertTags describes the operator's tags for a consumer and pair, either of which may be empty,
+// for including in alerts, e.g. " [consumer tags: acme; pair tags: fx]". Returns "" if neither
+// has tags
+func (o *OoORouterService) alertTags(consumer string, pair string) string {
+	parts := make([]string, 0, 2)
+
+	if consumer != "" {
+		if m, err := o.db.GetConsumerMetadata(consumer); err == nil && len(m.GetTags()) > 0 {
+			parts = append(parts, fmt.Sprintf("consumer tags: %s", strings.Join(m.GetTags(), ", ")))
+		}
+	}
+	if pair != "" {
+		if m, err := o.db.GetPairMetadata(pair); err == nil && len(m.GetTags()) > 0 {
+			parts = append(parts, fmt.Sprintf("pair tags: %s", strings.Join(m.GetTags(), ", ")))
+		}
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, "; ") + "]"
+}
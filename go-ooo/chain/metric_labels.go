@@ -0,0 +1,69 @@
+package chain
+
+import (
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"strings"
+	"sync"
+)
+
+// metricLabelOther is the label value used for pairs and consumers beyond the tracked limit
+const metricLabelOther = "other"
+
+var (
+	pairLabels     *labelLimiter
+	consumerLabels *labelLimiter
+)
+
+// labelLimiter bounds the number of distinct values of a metric label, so that requests for
+// arbitrary pairs, or from arbitrary consumers, cannot create unbounded numbers of series.
+// Configured values are always tracked. Other values are tracked first come, first served
+// until the limit is reached, after which they are counted as "other"
+type labelLimiter struct {
+	mu        sync.Mutex
+	max       int
+	normalise func(string) string
+	tracked   map[string]bool
+}
+
+func newLabelLimiter(max int, always []string, normalise func(string) string) *labelLimiter {
+	l := &labelLimiter{
+		max:       max,
+		normalise: normalise,
+		tracked:   make(map[string]bool),
+	}
+	for _, v := range always {
+		l.tracked[normalise(v)] = true
+	}
+	return l
+}
+
+// value returns the label value to use for v
+func (l *labelLimiter) value(v string) string {
+	if l == nil || v == "" {
+		return metricLabelOther
+	}
+
+	v = l.normalise(v)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.tracked[v] {
+		return v
+	}
+	if len(l.tracked) >= l.max {
+		return metricLabelOther
+	}
+
+	l.tracked[v] = true
+	return v
+}
+
+// initMetricLabels configures the pair and consumer label limits from config
+func initMetricLabels() {
+	pairLabels = newLabelLimiter(viper.GetInt(config.PrometheusMaxPairLabels),
+		viper.GetStringSlice(config.PrometheusLabelPairs), strings.ToUpper)
+	consumerLabels = newLabelLimiter(viper.GetInt(config.PrometheusMaxConsumerLabels),
+		viper.GetStringSlice(config.PrometheusLabelConsumers), strings.ToLower)
+}
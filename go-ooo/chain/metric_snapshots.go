@@ -0,0 +1,197 @@
+package chain
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	go_ooo_types "go-ooo/types"
+	"go-ooo/webhooks"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultMetricsSnapshotInterval - seconds between saves of the lifetime counters, if
+// prometheus.snapshot_interval is not set
+const defaultMetricsSnapshotInterval = 60
+
+// snapshotCounters are the counters saved to the database, and restored on start up, so that
+// lifetime totals of fulfillments, fees and gas survive restarts. Rate-style counters, such as
+// RPC errors, start from zero with each process
+var snapshotCounters = map[string]*prometheus.CounterVec{
+	"ooo_job_events_total":               jobEventsTotal,
+	"ooo_fulfillment_gas_used_total":     fulfillmentGasUsed,
+	"ooo_fulfillment_gas_cost_wei_total": fulfillmentGasCost,
+	"ooo_fees_earned_total":              feesEarned,
+}
+
+// MetricsSnapshotInterval returns how often the lifetime counters are saved. Zero disables
+// snapshots
+func MetricsSnapshotInterval() time.Duration {
+	if !viper.IsSet(config.PrometheusSnapshotInterval) {
+		return defaultMetricsSnapshotInterval * time.Second
+	}
+	return time.Duration(viper.GetInt64(config.PrometheusSnapshotInterval)) * time.Second
+}
+
+// counterSeries returns the current value of each series of the snapshotted counters, keyed by
+// metric name, then label pairs
+func counterSeries() map[string]map[string]float64 {
+	res := make(map[string]map[string]float64)
+
+	// a gather error still returns the families which could be collected
+	families, _ := prometheus.DefaultGatherer.Gather()
+	for _, f := range families {
+		if _, ok := snapshotCounters[f.GetName()]; !ok {
+			continue
+		}
+		series := make(map[string]float64)
+		for _, m := range f.GetMetric() {
+			labels := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			series[encodeMetricLabels(labels)] = m.GetCounter().GetValue()
+		}
+		res[f.GetName()] = series
+	}
+
+	return res
+}
+
+// encodeMetricLabels encodes label pairs as name=value, sorted by name and comma separated
+func encodeMetricLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for n := range labels {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, n := range names {
+		pairs[i] = n + "=" + labels[n]
+	}
+	return strings.Join(pairs, ",")
+}
+
+func decodeMetricLabels(encoded string) map[string]string {
+	labels := make(map[string]string)
+	for _, p := range strings.Split(encoded, ",") {
+		if kv := strings.SplitN(p, "=", 2); len(kv) == 2 {
+			labels[kv[0]] = kv[1]
+		}
+	}
+	return labels
+}
+
+// restoredLabel maps a saved pair or consumer label through the current label limits, which may
+// have changed since it was saved
+func restoredLabel(limiter *labelLimiter, v string) string {
+	if v == metricLabelOther {
+		return v
+	}
+	return limiter.value(v)
+}
+
+// RestoreMetrics raises the lifetime counters to their last saved values. Counters are only
+// ever added to, so it is safe to call more than once, e.g. when a standby becomes the leader
+// and picks up the totals the previous leader saved
+func (o *OoORouterService) RestoreMetrics() {
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "RestoreMetrics",
+	})
+
+	snapshots, err := o.db.GetMetricSnapshots()
+	if err != nil {
+		logger.Error("cannot load metric snapshots: ", err.Error())
+		return
+	}
+
+	// saved series are merged where the label limits now count them as "other"
+	saved := make(map[string]map[string]float64)
+	for _, s := range snapshots {
+		if _, ok := snapshotCounters[s.GetName()]; !ok {
+			continue
+		}
+		labels := decodeMetricLabels(s.GetLabels())
+		if v, ok := labels["pair"]; ok {
+			labels["pair"] = restoredLabel(pairLabels, v)
+		}
+		if v, ok := labels["consumer"]; ok {
+			labels["consumer"] = restoredLabel(consumerLabels, v)
+		}
+		if saved[s.GetName()] == nil {
+			saved[s.GetName()] = make(map[string]float64)
+		}
+		saved[s.GetName()][encodeMetricLabels(labels)] += s.GetValue()
+	}
+
+	current := counterSeries()
+	restored := 0
+	for name, series := range saved {
+		for labels, value := range series {
+			delta := value - current[name][labels]
+			if delta <= 0 {
+				continue
+			}
+			c, err := snapshotCounters[name].GetMetricWith(decodeMetricLabels(labels))
+			if err != nil {
+				logger.WithFields(logrus.Fields{"metric": name, "labels": labels}).Warn("cannot restore series: ", err.Error())
+				continue
+			}
+			c.Add(delta)
+			restored++
+		}
+	}
+
+	if restored > 0 {
+		logger.WithField("series", restored).Info("restored lifetime counters")
+	}
+}
+
+// SnapshotMetrics saves the current value of the lifetime counters
+func (o *OoORouterService) SnapshotMetrics() {
+	var snapshots []models.MetricSnapshots
+	for name, series := range counterSeries() {
+		for labels, value := range series {
+			snapshots = append(snapshots, models.MetricSnapshots{Name: name, Labels: labels, Value: value})
+		}
+	}
+
+	if err := o.db.SaveMetricSnapshots(snapshots); err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "SnapshotMetrics",
+		}).Error("cannot save metric snapshots: ", err.Error())
+	}
+}
+
+// LifetimeTotals sums the lifetime counters over every pair and consumer
+func LifetimeTotals() go_ooo_types.LifetimeTotals {
+	series := counterSeries()
+	sum := func(name string) float64 {
+		var total float64
+		for _, v := range series[name] {
+			total += v
+		}
+		return total
+	}
+
+	var fulfillments float64
+	for labels, v := range series["ooo_job_events_total"] {
+		if decodeMetricLabels(labels)["event"] == webhooks.EventFulfilled {
+			fulfillments += v
+		}
+	}
+
+	return go_ooo_types.LifetimeTotals{
+		Fulfillments: uint64(fulfillments),
+		FeesEarned:   strconv.FormatFloat(sum("ooo_fees_earned_total"), 'f', 0, 64),
+		GasUsed:      strconv.FormatFloat(sum("ooo_fulfillment_gas_used_total"), 'f', 0, 64),
+		GasCost:      strconv.FormatFloat(sum("ooo_fulfillment_gas_cost_wei_total"), 'f', 0, 64),
+	}
+}
@@ -0,0 +1,123 @@
+package chain
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"go-ooo/database/models"
+	"go-ooo/webhooks"
+	"math/big"
+	"time"
+)
+
+var (
+	jobEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_job_events_total",
+		Help: "Number of job lifecycle events - received, fulfilled, failed, skipped - by pair and consumer",
+	}, []string{"event", "pair", "consumer"})
+
+	fulfillmentGasUsed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_fulfillment_gas_used_total",
+		Help: "Gas used by confirmed fulfillment txs, by pair and consumer",
+	}, []string{"pair", "consumer"})
+
+	fulfillmentGasCost = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_fulfillment_gas_cost_wei_total",
+		Help: "Gas cost, in wei, of confirmed fulfillment txs, by pair and consumer",
+	}, []string{"pair", "consumer"})
+
+	feesEarned = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_fees_earned_total",
+		Help: "Fees, in the smallest xFUND unit, for fulfilled requests, by pair and consumer",
+	}, []string{"pair", "consumer"})
+
+	rpcErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_rpc_errors_total",
+		Help: "Number of failed eth RPC calls, by call",
+	}, []string{"call"})
+
+	blockLagGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ooo_block_lag_seconds",
+		Help: "Time since the latest block seen by the eth node was produced",
+	})
+
+	lastProcessedBlockGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ooo_last_processed_block",
+		Help: "Last block for which events were processed",
+	})
+)
+
+func observeJobEvent(event string, job models.DataRequests) {
+	pair := pairLabels.value(latencyPair(job))
+	consumer := consumerLabels.value(job.GetConsumer())
+
+	jobEventsTotal.WithLabelValues(event, pair, consumer).Inc()
+
+	if event != webhooks.EventFulfilled {
+		return
+	}
+
+	feesEarned.WithLabelValues(pair, consumer).Add(float64(job.GetFee()))
+	fulfillmentGasUsed.WithLabelValues(pair, consumer).Add(float64(job.GetFulfillGasUsed()))
+	fulfillmentGasCost.WithLabelValues(pair, consumer).Add(float64(job.GetFulfillGasUsed()) * float64(job.GetFulfillGasPrice()))
+}
+
+// rpcError counts a failed eth RPC call
+func rpcError(call string) {
+	rpcErrors.WithLabelValues(call).Inc()
+}
+
+// BlockLag returns the time since the latest block seen by the eth node was produced
+func (o *OoORouterService) BlockLag(ctx context.Context) (time.Duration, error) {
+	header, err := o.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		rpcError("HeaderByNumber")
+		return 0, err
+	}
+
+	return time.Since(time.Unix(int64(header.Time), 0)), nil
+}
+
+// WalletBalance returns the oracle wallet's balance, in wei
+func (o *OoORouterService) WalletBalance(ctx context.Context) (*big.Int, error) {
+	balance, err := o.client.BalanceAt(ctx, o.oracleAddress, nil)
+	if err != nil {
+		rpcError("BalanceAt")
+	}
+	return balance, err
+}
+
+// UpdateChainMetrics refreshes metrics which are sampled rather than counted
+func (o *OoORouterService) UpdateChainMetrics() {
+	header, err := o.client.HeaderByNumber(o.context, nil)
+	if err != nil {
+		rpcError("HeaderByNumber")
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "UpdateChainMetrics",
+			"action":   "get block lag",
+		}).Error(err.Error())
+		return
+	}
+
+	now := time.Now()
+	blockLagGauge.Set(now.Sub(time.Unix(int64(header.Time), 0)).Seconds())
+	o.checkClockSkew(header, now)
+
+	if _, err = o.KeyBalances(o.context); err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "UpdateChainMetrics",
+			"action":   "get key balances",
+		}).Error(err.Error())
+	}
+
+	if _, err = o.XfundAllowances(); err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "UpdateChainMetrics",
+			"action":   "get xfund allowances",
+		}).Error(err.Error())
+	}
+}
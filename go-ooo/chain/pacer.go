@@ -0,0 +1,131 @@
+package chain
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"golang.org/x/time/rate"
+	"sync"
+	"time"
+)
+
+var (
+	submissionWaitHistogram = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "ooo_submission_wait_seconds",
+		Help:    "Time fulfillment txs waited for the submission pacer before being broadcast",
+		Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60},
+	})
+
+	txsInFlightGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ooo_txs_in_flight",
+		Help: "Number of fulfillment txs broadcast but not yet seen fulfilled on chain, as last counted by the submission pacer",
+	})
+)
+
+// inFlightPollInterval - how often a tx waiting for in-flight txs to be mined recounts them
+const inFlightPollInterval = time.Second
+
+// submissionPacer spaces out fulfillment tx broadcasts, so that hundreds of requests arriving in
+// one block do not cause a nonce storm or hit the eth provider's rate limits. Txs are admitted
+// one at a time, at most jobs.submission_rate per second, and only while fewer than
+// jobs.max_in_flight txs are broadcast but not yet mined
+type submissionPacer struct {
+	// serialises admission, so that txs are admitted in turn
+	mu      sync.Mutex
+	limiter *rate.Limiter
+
+	// admitted, but not yet recorded as sent
+	reservedMu sync.Mutex
+	reserved   int64
+}
+
+func newSubmissionPacer(txsPerSecond float64) *submissionPacer {
+	p := &submissionPacer{limiter: rate.NewLimiter(rate.Inf, 1)}
+	p.setRate(txsPerSecond)
+	return p
+}
+
+// setRate changes the rate, e.g. when the config is reloaded. 0 disables the limit
+func (p *submissionPacer) setRate(txsPerSecond float64) {
+	if txsPerSecond <= 0 {
+		p.limiter.SetLimit(rate.Inf)
+		return
+	}
+	p.limiter.SetLimit(rate.Limit(txsPerSecond))
+}
+
+func (p *submissionPacer) release() {
+	p.reservedMu.Lock()
+	defer p.reservedMu.Unlock()
+	p.reserved--
+}
+
+// paceSubmission waits until a fulfillment tx may be broadcast, returning a func the caller must
+// call once the tx has been recorded as sent, or has failed. It returns false if ctx is done first
+func (o *OoORouterService) paceSubmission(ctx context.Context, requestId string) (func(), bool) {
+	p := o.pacer
+	start := time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.limiter.Wait(ctx); err != nil {
+		return nil, false
+	}
+
+	logged := false
+	for {
+		maxInFlight := viper.GetInt64(config.JobsMaxInFlight)
+		if maxInFlight <= 0 {
+			break
+		}
+
+		inFlight, err := o.db.CountInFlightTxs()
+		if err != nil {
+			// never hold up fulfillment because the count is unavailable
+			o.logger.WithFields(logrus.Fields{
+				"package":  "chain",
+				"function": "paceSubmission",
+				"action":   "count in flight txs",
+			}).Error(err.Error())
+			break
+		}
+		txsInFlightGauge.Set(float64(inFlight))
+
+		p.reservedMu.Lock()
+		inFlight += p.reserved
+		p.reservedMu.Unlock()
+
+		if inFlight < maxInFlight {
+			break
+		}
+
+		if !logged {
+			o.logger.WithFields(logrus.Fields{
+				"package":       "chain",
+				"function":      "paceSubmission",
+				"request_id":    requestId,
+				"in_flight":     inFlight,
+				"max_in_flight": maxInFlight,
+			}).Debug("waiting for in flight txs to be mined")
+			logged = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-time.After(inFlightPollInterval):
+		}
+	}
+
+	p.reservedMu.Lock()
+	p.reserved++
+	p.reservedMu.Unlock()
+
+	submissionWaitHistogram.Observe(time.Since(start).Seconds())
+
+	return p.release, true
+}
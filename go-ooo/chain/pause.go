@@ -0,0 +1,182 @@
+package chain
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	go_ooo_types "go-ooo/types"
+	"sync"
+)
+
+// Pause scopes for the pause and resume admin tasks
+const (
+	PauseScopeIngestion   = "ingestion"
+	PauseScopeFulfillment = "fulfillment"
+	PauseScopeAll         = "all"
+)
+
+// pauseState allows event ingestion and fulfillment submission to be paused separately,
+// e.g. during key rotation or contract upgrades. Ingestion is also suspended while
+// throttled by backpressure
+type pauseState struct {
+	mu          sync.RWMutex
+	ingestion   bool
+	throttled   bool
+	fulfillment bool
+	// block at which ingestion was suspended. Events from here are processed on resume
+	ingestionPausedAt uint64
+}
+
+func (p *pauseState) ingestionSuspended() bool {
+	return p.ingestion || p.throttled
+}
+
+func (o *OoORouterService) ingestionPaused() bool {
+	o.paused.mu.RLock()
+	defer o.paused.mu.RUnlock()
+	return o.paused.ingestionSuspended()
+}
+
+// suspendIngestion sets reason, which must be one of the pauseState ingestion flags,
+// recording the current block if ingestion was not already suspended
+func (o *OoORouterService) suspendIngestion(reason *bool) error {
+	currentBlockNum, err := o.client.BlockNumber(o.context)
+	if err != nil {
+		return err
+	}
+
+	o.paused.mu.Lock()
+	defer o.paused.mu.Unlock()
+
+	if !o.paused.ingestionSuspended() {
+		o.paused.ingestionPausedAt = currentBlockNum
+	}
+	*reason = true
+
+	return nil
+}
+
+// releaseIngestion clears reason. Once ingestion is no longer suspended for any reason, events
+// emitted while suspended are processed. Events already processed since resuming are ignored
+func (o *OoORouterService) releaseIngestion(reason *bool) {
+	o.paused.mu.Lock()
+	wasSet := *reason
+	*reason = false
+	catchUp := wasSet && !o.paused.ingestionSuspended()
+	fromBlock := o.paused.ingestionPausedAt
+	o.paused.mu.Unlock()
+
+	if catchUp {
+		o.supervisor.Go("ingestion_catch_up", func() {
+			opts := *o.historicalFilterOpts
+			opts.Start = fromBlock
+			o.getEventsFrom(&opts)
+		})
+	}
+}
+
+func (o *OoORouterService) fulfillmentPaused() bool {
+	o.paused.mu.RLock()
+	defer o.paused.mu.RUnlock()
+	return o.paused.fulfillment
+}
+
+func (o *OoORouterService) pauseStatus() string {
+	quiesced := o.fulfillmentQuiesced()
+
+	o.paused.mu.RLock()
+	defer o.paused.mu.RUnlock()
+	status := fmt.Sprintf("ingestion paused: %t, ingestion throttled: %t, fulfillment paused: %t",
+		o.paused.ingestion, o.paused.throttled, o.paused.fulfillment)
+	if quiesced != "" {
+		status += ", fulfillment quiesced: " + quiesced
+	}
+	return status
+}
+
+func parsePauseScope(scope string) (ingestion bool, fulfillment bool, err error) {
+	switch scope {
+	case PauseScopeIngestion:
+		return true, false, nil
+	case PauseScopeFulfillment:
+		return false, true, nil
+	case PauseScopeAll, "":
+		return true, true, nil
+	default:
+		return false, false, fmt.Errorf("unknown scope %s. Expected %s, %s or %s",
+			scope, PauseScopeIngestion, PauseScopeFulfillment, PauseScopeAll)
+	}
+}
+
+func (o *OoORouterService) pause(task go_ooo_types.AdminTask) go_ooo_types.AdminTaskResponse {
+	var resp go_ooo_types.AdminTaskResponse
+	resp.AdminTask = task
+
+	ingestion, fulfillment, err := parsePauseScope(task.Scope)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	if ingestion {
+		if err := o.suspendIngestion(&o.paused.ingestion); err != nil {
+			resp.Error = err.Error()
+			return resp
+		}
+	}
+
+	if fulfillment {
+		o.paused.mu.Lock()
+		o.paused.fulfillment = true
+		o.paused.mu.Unlock()
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "pause",
+		"scope":    task.Scope,
+	}).Warn(o.pauseStatus())
+
+	resp.Success = true
+	resp.Result = o.pauseStatus()
+	return resp
+}
+
+func (o *OoORouterService) resume(task go_ooo_types.AdminTask) go_ooo_types.AdminTaskResponse {
+	var resp go_ooo_types.AdminTaskResponse
+	resp.AdminTask = task
+
+	ingestion, fulfillment, err := parsePauseScope(task.Scope)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	if ingestion {
+		o.releaseIngestion(&o.paused.ingestion)
+	}
+
+	if fulfillment {
+		o.paused.mu.Lock()
+		o.paused.fulfillment = false
+		o.paused.mu.Unlock()
+		o.signalJobActivity()
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "resume",
+		"scope":    task.Scope,
+	}).Info(o.pauseStatus())
+
+	resp.Success = true
+	resp.Result = o.pauseStatus()
+	return resp
+}
+
+func (o *OoORouterService) queryPaused(task go_ooo_types.AdminTask) go_ooo_types.AdminTaskResponse {
+	return go_ooo_types.AdminTaskResponse{
+		AdminTask: task,
+		Success:   true,
+		Result:    o.pauseStatus(),
+	}
+}
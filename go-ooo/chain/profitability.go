@@ -0,0 +1,208 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	"go-ooo/errcodes"
+	"go-ooo/webhooks"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ProfitabilityActionDefer = "defer" // unprofitable requests are left pending until gas prices fall
+	ProfitabilityActionSkip  = "skip"  // unprofitable requests are skipped and not fulfilled
+)
+
+// DefaultProfitabilityPriceEndpoint - the DEX price of xFUND in ETH
+const DefaultProfitabilityPriceEndpoint = "XFUND.ETH.AD"
+
+// xfundPriceTtl - how long the xFUND price used to convert fulfillment costs is cached for
+const xfundPriceTtl = 5 * time.Minute
+
+var unprofitableRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ooo_unprofitable_requests_total",
+	Help: "Number of times a request's fee did not cover the estimated fulfillment cost plus margin, by action taken",
+}, []string{"action"})
+
+// xfundPriceCache holds the price of 1 xFUND in the chain's native token, as answered by the
+// node's own data sources for jobs.profitability_price_endpoint
+type xfundPriceCache struct {
+	mu        sync.Mutex
+	endpoint  string
+	price     *big.Int
+	fetchedAt time.Time
+}
+
+// xfundNativePrice returns the price of 1 xFUND in the chain's native token, scaled to the
+// answer decimals
+func (o *OoORouterService) xfundNativePrice(ctx context.Context) (*big.Int, error) {
+	endpoint := DefaultProfitabilityPriceEndpoint
+	if viper.IsSet(config.JobsProfitabilityPriceEndpoint) {
+		endpoint = strings.ToUpper(viper.GetString(config.JobsProfitabilityPriceEndpoint))
+	}
+
+	o.xfundPrice.mu.Lock()
+	defer o.xfundPrice.mu.Unlock()
+
+	if o.xfundPrice.price != nil && o.xfundPrice.endpoint == endpoint && time.Since(o.xfundPrice.fetchedAt) < xfundPriceTtl {
+		return o.xfundPrice.price, nil
+	}
+
+	answer, _, err := o.oooApi.QueryEndpoint(ctx, endpoint, "")
+	if err != nil {
+		return nil, err
+	}
+
+	price, ok := new(big.Int).SetString(answer, 10)
+	if !ok || price.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid xFUND price %q from %s", answer, endpoint)
+	}
+
+	o.xfundPrice.endpoint = endpoint
+	o.xfundPrice.price = price
+	o.xfundPrice.fetchedAt = time.Now()
+
+	return price, nil
+}
+
+// fulfillmentCostXfund converts the cost, in wei of the native token, of gas at gasPrice to
+// xFUND's smallest unit, including margin percent
+func (o *OoORouterService) fulfillmentCostXfund(gas uint64, gasPrice *big.Int, xfundPrice *big.Int, margin float64) *big.Int {
+	return o.nativeToXfund(new(big.Int).Mul(new(big.Int).SetUint64(gas), gasPrice), xfundPrice, margin)
+}
+
+// nativeToXfund converts wei of the native token to xFUND's smallest unit at xfundPrice,
+// including margin percent
+func (o *OoORouterService) nativeToXfund(wei *big.Int, xfundPrice *big.Int, margin float64) *big.Int {
+	cost := new(big.Float).SetPrec(256).SetInt(wei)
+	cost.Mul(cost, big.NewFloat(1+margin/100))
+
+	// cost in native token / native token per xFUND, with xFUND's 9 decimals
+	answerUnit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(o.oooApi.AnswerDecimals())), nil)
+	cost.Mul(cost, new(big.Float).SetInt(answerUnit))
+	cost.Quo(cost, new(big.Float).SetInt(xfundPrice))
+	cost.Quo(cost, big.NewFloat(params.Ether/params.GWei))
+
+	xfund, _ := cost.Int(nil)
+	return xfund
+}
+
+// fulfillmentProfitable estimates the cost of sending a job's fulfillment tx data at the current
+// gas price, and returns false if the job's fee does not cover it plus
+// jobs.profitability_margin. gas is the tx's estimated gas, or 0 to estimate it. Unprofitable
+// jobs are deferred or skipped, with the estimate recorded as the job's status reason. If the
+// cost cannot be estimated, the job is fulfilled
+func (o *OoORouterService) fulfillmentProfitable(ctx context.Context, job models.DataRequests, data []byte, gas uint64) bool {
+	if !viper.GetBool(config.JobsProfitabilityCheck) {
+		return true
+	}
+
+	gas, gasPrice, xfundPrice, err := o.estimateFulfillmentCost(ctx, job, data, gas)
+	if err != nil {
+		o.jobLogger(job).WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "fulfillmentProfitable",
+			"request_id": job.GetRequestId(),
+		}).Warn("cannot estimate fulfillment cost - fulfilling anyway: ", err.Error())
+		return true
+	}
+
+	return o.checkFulfillmentCost(job, gas, gasPrice, xfundPrice)
+}
+
+// estimateFulfillmentCost returns the gas needed to send the job's fulfillment tx data, estimated
+// unless gas is already known, the gas price it would be sent at, and the xFUND price to convert
+// the cost with
+func (o *OoORouterService) estimateFulfillmentCost(ctx context.Context, job models.DataRequests, data []byte, gas uint64) (uint64, *big.Int, *big.Int, error) {
+	gasPrice, err := o.suggestGasPrice(ctx)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	xfundPrice, err := o.xfundNativePrice(ctx)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	if gas > 0 {
+		return gas, gasPrice, xfundPrice, nil
+	}
+
+	gas, err = o.client.EstimateGas(ctx, ethereum.CallMsg{
+		From:     common.HexToAddress(job.GetProvider()),
+		To:       &o.contractAddress,
+		GasPrice: gasPrice,
+		Data:     data,
+	})
+	if err != nil {
+		rpcError("EstimateGas")
+		return 0, nil, nil, err
+	}
+
+	return gas, gasPrice, xfundPrice, nil
+}
+
+func profitabilityMargin() float64 {
+	if !viper.IsSet(config.JobsProfitabilityMargin) {
+		return 10
+	}
+	return viper.GetFloat64(config.JobsProfitabilityMargin)
+}
+
+func (o *OoORouterService) checkFulfillmentCost(job models.DataRequests, gas uint64, gasPrice *big.Int, xfundPrice *big.Int) bool {
+	requestId := job.GetRequestId()
+	margin := profitabilityMargin()
+	required := o.fulfillmentCostXfund(gas, gasPrice, xfundPrice, margin)
+	fee := new(big.Int).SetUint64(job.GetFee())
+
+	logger := o.jobLogger(job).WithFields(logrus.Fields{
+		"package":      "chain",
+		"function":     "checkFulfillmentCost",
+		"request_id":   requestId,
+		"fee":          fee.String(),
+		"required_fee": required.String(),
+		"gas":          gas,
+		"gas_price":    gasPrice.String(),
+		"margin":       margin,
+	})
+
+	if fee.Cmp(required) >= 0 {
+		logger.Debug("fee covers fulfillment cost")
+		return true
+	}
+
+	action := strings.ToLower(viper.GetString(config.JobsProfitabilityAction))
+	if action != ProfitabilityActionSkip {
+		action = ProfitabilityActionDefer
+	}
+	unprofitableRequests.WithLabelValues(action).Inc()
+
+	failure := errcodes.Errorf(errcodes.ErrFeeTooLow, "fee %s below estimated cost %s, including %g%% margin, of %d gas at %s wei",
+		fee.String(), required.String(), margin, gas, gasPrice.String())
+
+	logger.WithField("action", action).Warn("fee does not cover fulfillment cost")
+
+	if action == ProfitabilityActionSkip {
+		_ = o.failRequest(requestId, models.REQUEST_STATUS_SKIPPED_LOW_FEE, failure)
+		o.recordJobEvent(webhooks.EventSkipped, requestId)
+		return false
+	}
+
+	// leave ready to send, and check again on the next job queue check
+	_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_DATA_READY_TO_SEND, string(errcodes.CodeOf(failure)),
+		"deferred: "+failure.Error())
+
+	return false
+}
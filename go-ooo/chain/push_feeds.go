@@ -0,0 +1,332 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/ooo_api"
+	"go-ooo/price_feed"
+	"math/big"
+	"strings"
+	"sync/atomic"
+)
+
+// push feed update triggers
+const (
+	PushTriggerInitial   = "initial"
+	PushTriggerDeviation = "deviation"
+	PushTriggerHeartbeat = "heartbeat"
+)
+
+var pushFeedUpdates = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ooo_push_feed_updates_total",
+	Help: "Number of answers pushed to on-chain price feeds, by feed and trigger",
+}, []string{"feed", "trigger"})
+
+// PushFeed is an on-chain price feed contract, implementing IPriceFeed, to which the node pushes
+// the answer for Endpoint, independent of requests, when it deviates from the feed's answer by
+// at least Deviation percent, or once the feed was last updated Heartbeat seconds ago, e.g.
+//
+//	[[push_feeds.feeds]]
+//	name = "ETH.USD"
+//	contract = "0x..."
+//	deviation = 0.5
+//	heartbeat = 3600
+//
+// Endpoint defaults to the default endpoint of the pair given by Name. At least one of Deviation
+// and Heartbeat must be set
+type PushFeed struct {
+	Name      string  `mapstructure:"name"`
+	Contract  string  `mapstructure:"contract"`
+	Endpoint  string  `mapstructure:"endpoint"`
+	Deviation float64 `mapstructure:"deviation"`
+	Heartbeat int64   `mapstructure:"heartbeat"`
+}
+
+// pushFeed is a configured feed, bound to its contract
+type pushFeed struct {
+	PushFeed
+	instance *price_feed.PriceFeed
+	// read from the contract on the first check
+	decimals    uint8
+	hasDecimals bool
+	// the last update tx, until it is mined or dropped
+	pendingTx common.Hash
+}
+
+// LoadPushFeeds returns the push_feeds.feeds config, checking each feed is complete
+func LoadPushFeeds() ([]PushFeed, error) {
+	var feeds []PushFeed
+	var err error
+	if raw, ok := viper.Get(config.PushFeeds).(string); ok {
+		// set by an environment variable, as a JSON array of feeds
+		err = json.Unmarshal([]byte(raw), &feeds)
+	} else {
+		err = viper.UnmarshalKey(config.PushFeeds, &feeds)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for i, f := range feeds {
+		name := strings.ToUpper(f.Name)
+		if name == "" {
+			return nil, fmt.Errorf("push feed %d requires a name", i)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("duplicate push feed %s", name)
+		}
+		names[name] = true
+
+		if !common.IsHexAddress(f.Contract) {
+			return nil, fmt.Errorf("push feed %s: contract %q is not an address", name, f.Contract)
+		}
+		if f.Deviation < 0 || f.Heartbeat < 0 {
+			return nil, fmt.Errorf("push feed %s: deviation and heartbeat must not be negative", name)
+		}
+		if f.Deviation == 0 && f.Heartbeat == 0 {
+			return nil, fmt.Errorf("push feed %s requires a deviation or heartbeat", name)
+		}
+
+		if f.Endpoint == "" {
+			if _, _, ok := ooo_api.SplitPair(name); !ok {
+				return nil, fmt.Errorf("push feed %s: name must be a pair, e.g. ETH.USD, if no endpoint is set", name)
+			}
+		} else if historical, err := ooo_api.IsHistorical(f.Endpoint); err != nil || historical {
+			return nil, fmt.Errorf("push feed %s: %q must be a current price endpoint", name, f.Endpoint)
+		}
+
+		feeds[i].Name = name
+		feeds[i].Endpoint = strings.ToUpper(f.Endpoint)
+	}
+
+	return feeds, nil
+}
+
+// initPushFeeds binds the configured push feed contracts
+func (o *OoORouterService) initPushFeeds() error {
+	if !config.FeatureEnabled(config.FeaturesPushFeeds) {
+		return nil
+	}
+
+	feeds, err := LoadPushFeeds()
+	if err != nil {
+		return fmt.Errorf("%s: %s", config.PushFeeds, err.Error())
+	}
+
+	for _, f := range feeds {
+		instance, err := price_feed.NewPriceFeed(common.HexToAddress(f.Contract), o.client)
+		if err != nil {
+			return err
+		}
+
+		if f.Endpoint == "" {
+			base, target, _ := ooo_api.SplitPair(f.Name)
+			f.Endpoint = o.oooApi.DefaultPairEndpoint(base, target)
+		}
+
+		o.pushFeeds = append(o.pushFeeds, &pushFeed{PushFeed: f, instance: instance})
+
+		o.logger.WithFields(logrus.Fields{
+			"package":   "chain",
+			"function":  "initPushFeeds",
+			"feed":      f.Name,
+			"contract":  f.Contract,
+			"endpoint":  f.Endpoint,
+			"deviation": f.Deviation,
+			"heartbeat": f.Heartbeat,
+		}).Info("push feed enabled")
+	}
+
+	return nil
+}
+
+// PushFeedsEnabled returns true if any push feeds are configured
+func (o *OoORouterService) PushFeedsEnabled() bool {
+	return len(o.pushFeeds) > 0
+}
+
+// CheckPushFeeds pushes a new answer to each feed whose deviation or heartbeat has been
+// triggered. Feeds are not updated while fulfillment is paused. If a previous check is still
+// running, e.g. waiting on slow sources, this check is skipped
+func (o *OoORouterService) CheckPushFeeds() {
+	if !atomic.CompareAndSwapUint32(&o.pushFeedsRunning, 0, 1) {
+		return
+	}
+	defer atomic.StoreUint32(&o.pushFeedsRunning, 0)
+
+	if o.fulfillmentPaused() {
+		return
+	}
+
+	for _, f := range o.pushFeeds {
+		o.checkPushFeed(f)
+	}
+}
+
+func (o *OoORouterService) checkPushFeed(f *pushFeed) {
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "checkPushFeed",
+		"feed":     f.Name,
+	})
+
+	if f.pendingTx != (common.Hash{}) {
+		_, isPending, err := o.client.TransactionByHash(o.context, f.pendingTx)
+		if err == nil && isPending {
+			logger.WithFields(logrus.Fields{
+				"tx": f.pendingTx.Hex(),
+			}).Debug("previous update pending")
+			return
+		}
+		if err != nil && err != ethereum.NotFound {
+			rpcError("TransactionByHash")
+			logger.WithFields(logrus.Fields{
+				"action": "get pending tx",
+			}).Error(err.Error())
+			return
+		}
+		// mined, or dropped and to be replaced
+		f.pendingTx = common.Hash{}
+	}
+
+	if !f.hasDecimals {
+		decimals, err := f.instance.Decimals(o.callOpts)
+		if err != nil {
+			rpcError("Decimals")
+			logger.WithFields(logrus.Fields{
+				"action": "get feed decimals",
+			}).Error(err.Error())
+			return
+		}
+		f.decimals = decimals
+		f.hasDecimals = true
+	}
+
+	round, err := f.instance.LatestRoundData(o.callOpts)
+	if err != nil {
+		rpcError("LatestRoundData")
+		logger.WithFields(logrus.Fields{
+			"action": "get latest round",
+		}).Error(err.Error())
+		return
+	}
+
+	price, _, err := o.oooApi.QueryEndpoint(o.context, f.Endpoint, "feed."+f.Name)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"action":   "get price",
+			"endpoint": f.Endpoint,
+		}).Error(err.Error())
+		return
+	}
+
+	answer, err := scaleAnswer(price, o.oooApi.AnswerDecimals(), uint(f.decimals))
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"action": "scale price",
+			"price":  price,
+		}).Error(err.Error())
+		return
+	}
+
+	// the feed's updatedAt is a block timestamp, so the heartbeat is timed by the chain's clock
+	head, err := o.client.HeaderByNumber(o.context, nil)
+	if err != nil {
+		rpcError("HeaderByNumber")
+		logger.WithFields(logrus.Fields{
+			"action": "get latest block",
+		}).Error(err.Error())
+		return
+	}
+
+	trigger := f.trigger(answer, round.Answer, round.UpdatedAt, head.Time)
+	if trigger == "" {
+		logger.WithFields(logrus.Fields{
+			"answer":      answer.String(),
+			"feed_answer": round.Answer.String(),
+		}).Debug("feed up to date")
+		return
+	}
+
+	o.txMu.Lock()
+	defer o.txMu.Unlock()
+
+	err = o.RenewTransactOpts()
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"action": "RenewTransactOpts",
+		}).Error(err.Error())
+		return
+	}
+
+	tx, err := f.instance.UpdateAnswer(o.transactOpts, answer)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"action": "send transaction",
+		}).Error(err.Error())
+		return
+	}
+
+	o.setNextTxNonce(tx.Nonce(), false)
+	f.pendingTx = tx.Hash()
+	pushFeedUpdates.WithLabelValues(f.Name, trigger).Inc()
+
+	logger.WithFields(logrus.Fields{
+		"trigger":     trigger,
+		"answer":      answer.String(),
+		"feed_answer": round.Answer.String(),
+		"tx":          tx.Hash().Hex(),
+	}).Info("feed update tx sent")
+}
+
+// trigger returns why answer should be pushed to the feed, whose current answer is feedAnswer,
+// last updated at updatedAt, or "" if the feed is up to date. now is the latest block's timestamp
+func (f *pushFeed) trigger(answer *big.Int, feedAnswer *big.Int, updatedAt *big.Int, now uint64) string {
+	if updatedAt == nil || updatedAt.Sign() == 0 {
+		return PushTriggerInitial
+	}
+
+	if f.Deviation > 0 && answer.Cmp(feedAnswer) != 0 {
+		if feedAnswer.Sign() == 0 {
+			return PushTriggerDeviation
+		}
+		diff := new(big.Rat).SetInt(new(big.Int).Sub(answer, feedAnswer))
+		diff.Abs(diff).Quo(diff, new(big.Rat).SetInt(new(big.Int).Abs(feedAnswer))).Mul(diff, big.NewRat(100, 1))
+		deviation, _ := diff.Float64()
+		if deviation >= f.Deviation {
+			return PushTriggerDeviation
+		}
+	}
+
+	if f.Heartbeat > 0 && int64(now)-updatedAt.Int64() >= f.Heartbeat {
+		return PushTriggerHeartbeat
+	}
+
+	return ""
+}
+
+// scaleAnswer rescales price, an integer answer with fromDecimals decimals, to toDecimals.
+// Digits beyond toDecimals are truncated
+func scaleAnswer(price string, fromDecimals uint, toDecimals uint) (*big.Int, error) {
+	answer, ok := new(big.Int).SetString(price, 10)
+	if !ok {
+		return nil, fmt.Errorf("%q is not an integer", price)
+	}
+
+	switch {
+	case toDecimals > fromDecimals:
+		answer.Mul(answer, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(toDecimals-fromDecimals)), nil))
+	case toDecimals < fromDecimals:
+		answer.Quo(answer, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fromDecimals-toDecimals)), nil))
+	}
+
+	return answer, nil
+}
@@ -0,0 +1,125 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"go-ooo/cache"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RateLimitActionDefer = "defer" // over limit requests are left pending until the consumer is under the limit
+	RateLimitActionSkip  = "skip"  // over limit requests are skipped and not fulfilled
+)
+
+// consumerRateLimiter limits the number of fulfillments per consumer within a sliding window. If
+// cache.redis_url is set, the count is shared with the other nodes, so that it survives a failover
+type consumerRateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	sent   map[string][]time.Time
+	shared *cache.Client
+}
+
+func newConsumerRateLimiter(limit int, window time.Duration) *consumerRateLimiter {
+	return &consumerRateLimiter{
+		limit:  limit,
+		window: window,
+		sent:   make(map[string][]time.Time),
+		shared: cache.Shared(),
+	}
+}
+
+// setLimit changes the limit, e.g. when the config is reloaded. Recent fulfillments still count
+func (r *consumerRateLimiter) setLimit(limit int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limit = limit
+}
+
+// allow returns true, and records the fulfillment, if the consumer is under its limit
+func (r *consumerRateLimiter) allow(consumer string) bool {
+	r.mu.Lock()
+	limit, window := r.limit, r.window
+	r.mu.Unlock()
+
+	if limit <= 0 {
+		return true
+	}
+
+	consumer = strings.ToLower(consumer)
+	now := time.Now()
+	cutoff := now.Add(-window)
+
+	// the shared count is checked without holding mu, so that a slow cache does not block
+	// the other workers' checks
+	sharedAllowed, sharedErr := r.allowShared(consumer, now, limit, window)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recent := r.sent[consumer][:0]
+	for _, t := range r.sent[consumer] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+
+	// this node's own count is kept, to fall back on if the shared cache cannot be reached
+	allowed := len(recent) < limit
+	if sharedErr == nil {
+		allowed = sharedAllowed
+	}
+
+	if !allowed {
+		r.sent[consumer] = recent
+		return false
+	}
+
+	r.sent[consumer] = append(recent, now)
+
+	return true
+}
+
+// sharedRateLimitScript checks the sliding window count and counts the fulfillment in one step,
+// so that concurrent workers, or nodes, can't both take the last of the limit. KEYS are the
+// previous and current fixed windows' counters, ARGV the fraction of the current window elapsed,
+// the limit and the counters' ttl in milliseconds. Returns 1 if the fulfillment is allowed
+const sharedRateLimitScript = `
+local prev = tonumber(redis.call('GET', KEYS[1]) or '0')
+local curr = tonumber(redis.call('GET', KEYS[2]) or '0')
+if prev * (1 - tonumber(ARGV[1])) + curr >= tonumber(ARGV[2]) then
+	return 0
+end
+if redis.call('INCR', KEYS[2]) == 1 then
+	redis.call('PEXPIRE', KEYS[2], ARGV[3])
+end
+return 1`
+
+// allowShared counts the fulfillment against the consumer's shared count, if it is under the
+// limit. The sliding window is approximated from the counts of the current and previous fixed
+// windows, the previous weighted by how much of it is still within the sliding window
+func (r *consumerRateLimiter) allowShared(consumer string, now time.Time, limit int, window time.Duration) (bool, error) {
+	if !r.shared.Enabled() {
+		return false, fmt.Errorf("no shared cache configured")
+	}
+
+	ctx := context.Background()
+	current := now.UnixNano() / int64(window)
+	key := func(fixed int64) string {
+		return fmt.Sprintf("ratelimit:consumer:%s:%d:%d", consumer, int64(window/time.Second), fixed)
+	}
+
+	elapsed := float64(now.UnixNano()%int64(window)) / float64(window)
+	reply, err := r.shared.Eval(ctx, sharedRateLimitScript, []string{key(current - 1), key(current)},
+		strconv.FormatFloat(elapsed, 'f', -1, 64), strconv.Itoa(limit), strconv.FormatInt((2*window).Milliseconds(), 10))
+	if err != nil {
+		return false, err
+	}
+	allowed, _ := reply.(int64)
+	return allowed == 1, nil
+}
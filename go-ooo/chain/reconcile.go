@@ -0,0 +1,445 @@
+package chain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/alerts"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	"go-ooo/ooo_router"
+	"go-ooo/schedule"
+	go_ooo_types "go-ooo/types"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// kinds of reconciliation discrepancy
+const (
+	// DiscrepancyFulfilledOnChain - fulfilled on chain, but not marked as such in the db, e.g. marked failed
+	DiscrepancyFulfilledOnChain = "fulfilled_on_chain"
+	// DiscrepancyNotInDb - fulfilled on chain by the node's provider, but not in the db
+	DiscrepancyNotInDb = "not_in_db"
+	// DiscrepancyPendingOnChain - marked as fulfilled in the db, but still pending on chain
+	DiscrepancyPendingOnChain = "pending_on_chain"
+	// DiscrepancyFeeBalance - the on-chain fee balance differs from the fees earned according to the db
+	DiscrepancyFeeBalance = "fee_balance"
+)
+
+// defaultReconcileLookback - hours of received requests reconciled, if jobs.reconcile_lookback is not set
+const defaultReconcileLookback = 24
+
+// reconcileMinInterval - a nightly run is skipped if the last run, e.g. one run by the operator,
+// was more recent
+const reconcileMinInterval = 12 * time.Hour
+
+var errReconcileRunning = errors.New("a reconciliation is already running")
+
+var reconcileDiscrepanciesGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "ooo_reconcile_discrepancies",
+	Help: "Discrepancies between the db and on-chain state found by the last reconciliation, by kind",
+}, []string{"kind"})
+
+// ReconcileIfDue runs the nightly reconciliation, if it is jobs.reconcile_hour and it has not run
+// in the last 12 hours. If the reconciliation is confined to maintenance windows, a run due at the
+// hour waits for the next window. Differences are corrected if jobs.reconcile_fix is set
+func (o *OoORouterService) ReconcileIfDue() {
+	hour := viper.GetInt(config.JobsReconcileHour)
+	if hour < 0 {
+		return
+	}
+
+	last, err := o.db.GetLatestReconciliation()
+	if err != nil || (last.ID != 0 && time.Since(last.CreatedAt) < reconcileMinInterval) {
+		return
+	}
+
+	lastRun := time.Time{}
+	if last.ID != 0 {
+		lastRun = last.CreatedAt
+	}
+	if !schedule.DueDaily(schedule.TaskReconcile, hour, lastRun) {
+		return
+	}
+
+	_, err = o.Reconcile(viper.GetBool(config.JobsReconcileFix))
+	if err != nil && err != errReconcileRunning {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "ReconcileIfDue",
+		}).Error(err.Error())
+	}
+}
+
+// Reconcile compares the db's view of requests with the router's on-chain state, from the block
+// after the last reconciliation, or the first request received in the lookback window on the
+// first run, to the chain head:
+//
+//   - requests fulfilled on chain for the node's providers, but not marked fulfilled in the db,
+//     e.g. marked failed after a timeout although the tx was mined
+//   - requests marked fulfilled in the db, but still pending on chain
+//   - the providers' withdrawable fee balance, against the last run's balance plus the fees of
+//     the requests fulfilled since, less the fees withdrawn
+//
+// If fix is true, requests are corrected as they would be by a replay - fulfillments are
+// recorded, and requests still pending are returned to the job queue. The fee balance is only
+// reported. The run is recorded, and a reconciliation alert raised if anything was found
+func (o *OoORouterService) Reconcile(fix bool) (go_ooo_types.Reconciliation, error) {
+	report := go_ooo_types.Reconciliation{
+		RanAt:         time.Now().Unix(),
+		Discrepancies: []go_ooo_types.ReconciliationDiscrepancy{},
+	}
+
+	if !atomic.CompareAndSwapInt32(&o.reconciling, 0, 1) {
+		return report, errReconcileRunning
+	}
+	defer atomic.StoreInt32(&o.reconciling, 0)
+
+	head, err := o.client.BlockNumber(o.context)
+	if err != nil {
+		rpcError("BlockNumber")
+		return report, err
+	}
+
+	last, err := o.db.GetLatestReconciliation()
+	if err != nil {
+		return report, err
+	}
+
+	lookback := time.Duration(defaultReconcileLookback) * time.Hour
+	if viper.IsSet(config.JobsReconcileLookback) && viper.GetInt64(config.JobsReconcileLookback) > 0 {
+		lookback = time.Duration(viper.GetInt64(config.JobsReconcileLookback)) * time.Hour
+	}
+	received, err := o.db.GetRequestsReceivedBetween(time.Now().Add(-lookback), time.Now())
+	if err != nil {
+		return report, err
+	}
+
+	fromBlock := head + 1
+	if last.ID != 0 {
+		fromBlock = last.GetToBlock() + 1
+	} else {
+		for _, job := range received {
+			if job.RequestBlockNumber > 0 && job.RequestBlockNumber < fromBlock {
+				fromBlock = job.RequestBlockNumber
+			}
+		}
+	}
+	report.FromBlock = fromBlock
+	report.ToBlock = head
+
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "Reconcile",
+		"from_block": fromBlock,
+		"to_block":   head,
+		"fix":        fix,
+	})
+	logger.Info("begin reconciliation")
+
+	me := o.providerAddresses()
+
+	fulfilled, withdrawn, err := o.reconcileEvents(me, fromBlock, head)
+	if err != nil {
+		return report, err
+	}
+
+	discrepancy := func(d go_ooo_types.ReconciliationDiscrepancy) {
+		logger.WithFields(logrus.Fields{
+			"kind":       d.Kind,
+			"request_id": d.RequestId,
+			"db_status":  d.DbStatus,
+			"fixed":      d.Fixed,
+		}).Warn(d.Detail)
+		if d.Fixed {
+			report.Fixed++
+		}
+		report.Discrepancies = append(report.Discrepancies, d)
+	}
+
+	// fulfillments in the range, and the fees the db expects them to have earned
+	fees := big.NewInt(0)
+	feesKnown := true
+	for requestId, event := range fulfilled {
+		report.Checked++
+		job, _ := o.db.FindByRequestId(requestId)
+		if job.ID == 0 {
+			feesKnown = false
+			discrepancy(go_ooo_types.ReconciliationDiscrepancy{
+				Kind:      DiscrepancyNotInDb,
+				RequestId: requestId,
+				Detail:    fmt.Sprintf("request fulfilled on chain in tx %s is not in the db", event.Raw.TxHash.Hex()),
+			})
+			continue
+		}
+		fees.Add(fees, new(big.Int).SetUint64(job.Fee))
+
+		if job.GetRequestStatus() == models.REQUEST_STATUS_SUCCESS {
+			continue
+		}
+		d := go_ooo_types.ReconciliationDiscrepancy{
+			Kind:      DiscrepancyFulfilledOnChain,
+			RequestId: requestId,
+			DbStatus:  job.GetRequestStatusString(),
+			Detail:    fmt.Sprintf("request fulfilled on chain in tx %s, but not marked fulfilled in the db", event.Raw.TxHash.Hex()),
+		}
+		if fix {
+			o.processIncomingFulfilments(event)
+			d.Fixed = true
+		}
+		discrepancy(d)
+	}
+
+	// finished requests received in the lookback window, which were not fulfilled in the range.
+	// Pending requests are the job queue's, and the stuck job watchdog's
+	for _, job := range received {
+		requestId := job.GetRequestId()
+		if _, ok := fulfilled[requestId]; ok || job.GetJobStatus() == models.JOB_STATUS_PENDING {
+			continue
+		}
+		report.Checked++
+
+		exists, err := o.requestExistsOnChain(o.context, requestId)
+		if err != nil {
+			return report, err
+		}
+
+		switch {
+		case exists && job.GetRequestStatus() == models.REQUEST_STATUS_SUCCESS:
+			d := go_ooo_types.ReconciliationDiscrepancy{
+				Kind:      DiscrepancyPendingOnChain,
+				RequestId: requestId,
+				DbStatus:  job.GetRequestStatusString(),
+				Detail:    "request marked fulfilled in the db, but still pending on chain",
+			}
+			if fix {
+				d.Fixed = o.db.ResetRequestForReplay(requestId) == nil
+			}
+			discrepancy(d)
+		case !exists && job.GetRequestStatus() != models.REQUEST_STATUS_SUCCESS:
+			// removed from the router - fulfilled before the range, or never addressed to this
+			// provider's key
+			event, err := o.findFulfilledEvent(requestId, job.RequestBlockNumber, head)
+			if err != nil {
+				return report, err
+			}
+			if event == nil {
+				continue
+			}
+			d := go_ooo_types.ReconciliationDiscrepancy{
+				Kind:      DiscrepancyFulfilledOnChain,
+				RequestId: requestId,
+				DbStatus:  job.GetRequestStatusString(),
+				Detail:    fmt.Sprintf("request fulfilled on chain in tx %s, but not marked fulfilled in the db", event.Raw.TxHash.Hex()),
+			}
+			if fix {
+				o.processIncomingFulfilments(event)
+				d.Fixed = true
+			}
+			discrepancy(d)
+		}
+	}
+
+	// the fee balance, as of the head the events were read to
+	callOpts := *o.callOpts
+	callOpts.BlockNumber = new(big.Int).SetUint64(head)
+	withdrawable := big.NewInt(0)
+	for _, provider := range me {
+		balance, err := o.contractInstance.GetWithdrawableTokens(&callOpts, provider)
+		if err != nil {
+			rpcError("GetWithdrawableTokens")
+			return report, err
+		}
+		withdrawable.Add(withdrawable, balance)
+	}
+	report.Withdrawable = withdrawable.String()
+
+	if previous, ok := new(big.Int).SetString(last.GetWithdrawable(), 10); ok && last.ID != 0 {
+		expected := new(big.Int).Add(previous, fees)
+		expected.Sub(expected, withdrawn)
+		report.ExpectedWithdrawable = expected.String()
+
+		if expected.Cmp(withdrawable) != 0 {
+			detail := fmt.Sprintf("withdrawable fees are %s on chain, expected %s - %s at block %d, plus %s earned, less %s withdrawn",
+				withdrawable.String(), expected.String(), previous.String(), last.GetToBlock(), fees.String(), withdrawn.String())
+			if !feesKnown {
+				detail += ". Requests missing from the db are not counted"
+			}
+			discrepancy(go_ooo_types.ReconciliationDiscrepancy{
+				Kind:   DiscrepancyFeeBalance,
+				Detail: detail,
+			})
+		}
+	}
+
+	o.recordReconciliation(report)
+
+	logger.WithFields(logrus.Fields{
+		"checked":       report.Checked,
+		"discrepancies": len(report.Discrepancies),
+		"fixed":         report.Fixed,
+		"withdrawable":  report.Withdrawable,
+	}).Info("reconciliation complete")
+
+	return report, nil
+}
+
+// reconcileEvents returns the RequestFulfilled events for providers from fromBlock to toBlock,
+// keyed by request id, and the total fees withdrawn. Logs are fetched chain.backfill_block_range
+// blocks at a time
+func (o *OoORouterService) reconcileEvents(providers []common.Address, fromBlock uint64,
+	toBlock uint64) (map[string]*ooo_router.OooRouterRequestFulfilled, *big.Int, error) {
+
+	fulfilled := make(map[string]*ooo_router.OooRouterRequestFulfilled)
+	withdrawn := big.NewInt(0)
+	blockRange := viper.GetUint64(config.ChainBackfillBlockRange)
+
+	for start := fromBlock; start <= toBlock; {
+		end := toBlock
+		if blockRange > 0 && toBlock-start >= blockRange {
+			end = start + blockRange - 1
+		}
+		opts := &bind.FilterOpts{Context: o.context, Start: start, End: &end}
+
+		itrFr, err := o.contractInstance.FilterRequestFulfilled(opts, nil, providers, nil)
+		if err != nil {
+			rpcError("FilterRequestFulfilled")
+			return nil, nil, err
+		}
+		for itrFr.Next() {
+			fulfilled[common.Bytes2Hex(itrFr.Event.RequestId[:])] = itrFr.Event
+		}
+		err = itrFr.Error()
+		itrFr.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		itrWf, err := o.contractInstance.FilterWithdrawFees(opts, providers, nil)
+		if err != nil {
+			rpcError("FilterWithdrawFees")
+			return nil, nil, err
+		}
+		for itrWf.Next() {
+			withdrawn.Add(withdrawn, itrWf.Event.Amount)
+		}
+		err = itrWf.Error()
+		itrWf.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		start = end + 1
+	}
+
+	return fulfilled, withdrawn, nil
+}
+
+// findFulfilledEvent returns the request's RequestFulfilled event from fromBlock to toBlock, or
+// nil if it was not fulfilled in that range
+func (o *OoORouterService) findFulfilledEvent(requestId string, fromBlock uint64,
+	toBlock uint64) (*ooo_router.OooRouterRequestFulfilled, error) {
+
+	reqIdBytes32 := [32]byte{}
+	copy(reqIdBytes32[:], common.FromHex(requestId))
+
+	opts := &bind.FilterOpts{Context: o.context, Start: fromBlock, End: &toBlock}
+	itrFr, err := o.contractInstance.FilterRequestFulfilled(opts, nil, nil, [][32]byte{reqIdBytes32})
+	if err != nil {
+		rpcError("FilterRequestFulfilled")
+		return nil, err
+	}
+	defer itrFr.Close()
+
+	var event *ooo_router.OooRouterRequestFulfilled
+	for itrFr.Next() {
+		event = itrFr.Event
+	}
+	return event, itrFr.Error()
+}
+
+// recordReconciliation saves the report, updates the discrepancy gauges and raises or resolves
+// the reconciliation alert
+func (o *OoORouterService) recordReconciliation(report go_ooo_types.Reconciliation) {
+	discrepancies, _ := json.Marshal(report.Discrepancies)
+	err := o.db.InsertReconciliation(models.Reconciliations{
+		FromBlock:            report.FromBlock,
+		ToBlock:              report.ToBlock,
+		Checked:              report.Checked,
+		NumDiscrepancies:     len(report.Discrepancies),
+		Fixed:                report.Fixed,
+		Withdrawable:         report.Withdrawable,
+		ExpectedWithdrawable: report.ExpectedWithdrawable,
+		Discrepancies:        string(discrepancies),
+	})
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "recordReconciliation",
+		}).Error(err.Error())
+	}
+
+	counts := map[string]int{
+		DiscrepancyFulfilledOnChain: 0,
+		DiscrepancyNotInDb:          0,
+		DiscrepancyPendingOnChain:   0,
+		DiscrepancyFeeBalance:       0,
+	}
+	unfixed := make(map[string]int)
+	for _, d := range report.Discrepancies {
+		counts[d.Kind]++
+		if !d.Fixed {
+			unfixed[d.Kind]++
+		}
+	}
+	for kind, n := range counts {
+		reconcileDiscrepanciesGauge.WithLabelValues(kind).Set(float64(n))
+	}
+
+	if len(unfixed) == 0 {
+		o.alerter.Resolve(alerts.AlertReconciliation, "")
+		return
+	}
+
+	var found []string
+	for _, kind := range []string{DiscrepancyFulfilledOnChain, DiscrepancyNotInDb, DiscrepancyPendingOnChain, DiscrepancyFeeBalance} {
+		if unfixed[kind] > 0 {
+			found = append(found, fmt.Sprintf("%d %s", unfixed[kind], kind))
+		}
+	}
+	o.alerter.Alert(alerts.AlertReconciliation, "", fmt.Sprintf("reconciliation of blocks %d - %d found %s",
+		report.FromBlock, report.ToBlock, strings.Join(found, ", ")))
+}
+
+// GetReconciliations returns the last limit reconciliation reports, most recent first
+func (o *OoORouterService) GetReconciliations(limit int) ([]go_ooo_types.Reconciliation, error) {
+	runs, err := o.db.GetReconciliations(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]go_ooo_types.Reconciliation, 0, len(runs))
+	for _, r := range runs {
+		report := go_ooo_types.Reconciliation{
+			RanAt:                r.CreatedAt.Unix(),
+			FromBlock:            r.GetFromBlock(),
+			ToBlock:              r.GetToBlock(),
+			Checked:              r.Checked,
+			Fixed:                r.Fixed,
+			Withdrawable:         r.GetWithdrawable(),
+			ExpectedWithdrawable: r.ExpectedWithdrawable,
+			Discrepancies:        []go_ooo_types.ReconciliationDiscrepancy{},
+		}
+		_ = json.Unmarshal([]byte(r.GetDiscrepancies()), &report.Discrepancies)
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
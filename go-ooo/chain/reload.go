@@ -0,0 +1,24 @@
+package chain
+
+import (
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"time"
+)
+
+// ReloadConfig applies settings which the service holds rather than reading from the config
+// each time - webhooks, alerts, the consumer rate limit, the submission rate and the data API's settings. In-flight
+// jobs are unaffected
+func (o *OoORouterService) ReloadConfig() error {
+	o.webhooks.Reconfigure(
+		viper.GetStringSlice(config.WebhooksUrls),
+		viper.GetStringSlice(config.WebhooksEvents),
+		viper.GetString(config.WebhooksSecret),
+		time.Duration(viper.GetInt64(config.WebhooksTimeout))*time.Second,
+	)
+	o.alerter.Reconfigure(alertsConfig())
+	o.consumerRateLimit.setLimit(viper.GetInt(config.JobsConsumerRateLimit))
+	o.pacer.setRate(viper.GetFloat64(config.JobsSubmissionRate))
+
+	return o.oooApi.ReloadConfig()
+}
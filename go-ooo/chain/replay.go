@@ -0,0 +1,133 @@
+package chain
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"go-ooo/database/models"
+	"go-ooo/errcodes"
+)
+
+// ReplaySummary counts the changes made by a replay
+type ReplaySummary struct {
+	Requests  int // DataRequested events found
+	Inserted  int // requests missing from the db
+	Fulfilled int // RequestFulfilled events found
+	Reset     int // requests marked as fulfilled in the db, but still pending on chain
+	Missing   int // requests neither fulfilled nor pending on chain
+}
+
+// Replay re-processes all events from fromBlock against the current db, then reconciles each
+// request's status against its on-chain state. No fulfillment txs are sent. Requests which
+// are still pending on chain are fulfilled by the job queue once the service is started
+func (o *OoORouterService) Replay(fromBlock uint64) (ReplaySummary, error) {
+	var summary ReplaySummary
+
+	currentBlockNum, err := o.client.BlockNumber(o.context)
+	if err != nil {
+		return summary, err
+	}
+
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "Replay",
+		"from_block": fromBlock,
+		"to_block":   currentBlockNum,
+	})
+
+	logger.Info("begin replay")
+
+	opts := *o.historicalFilterOpts
+	opts.Start = fromBlock
+	opts.End = &currentBlockNum
+
+	me := o.providerAddresses()
+
+	itrDr, err := o.contractInstance.FilterDataRequested(&opts, nil, me, nil)
+	if err != nil {
+		return summary, err
+	}
+	defer itrDr.Close()
+
+	var requestIds []string
+
+	for itrDr.Next() {
+		requestId := common.Bytes2Hex(itrDr.Event.RequestId[:])
+		requestIds = append(requestIds, requestId)
+		summary.Requests++
+
+		if existing, _ := o.db.FindByRequestId(requestId); existing.ID == 0 {
+			summary.Inserted++
+		}
+		o.processIncomingRequests(itrDr.Event)
+	}
+
+	if err = itrDr.Error(); err != nil {
+		return summary, err
+	}
+
+	itrFr, err := o.contractInstance.FilterRequestFulfilled(&opts, nil, me, nil)
+	if err != nil {
+		return summary, err
+	}
+	defer itrFr.Close()
+
+	fulfilled := make(map[string]bool)
+
+	for itrFr.Next() {
+		requestId := common.Bytes2Hex(itrFr.Event.RequestId[:])
+		fulfilled[requestId] = true
+		summary.Fulfilled++
+
+		if job, _ := o.db.FindByRequestId(requestId); job.GetRequestStatus() == models.REQUEST_STATUS_SUCCESS {
+			continue
+		}
+		o.processIncomingFulfilments(itrFr.Event)
+	}
+
+	if err = itrFr.Error(); err != nil {
+		return summary, err
+	}
+
+	for _, requestId := range requestIds {
+		if fulfilled[requestId] {
+			continue
+		}
+
+		exists, err := o.requestExistsOnChain(o.context, requestId)
+		if err != nil {
+			return summary, err
+		}
+
+		job, err := o.db.FindByRequestId(requestId)
+		if err != nil {
+			continue
+		}
+
+		reqLogger := logger.WithFields(logrus.Fields{
+			"request_id": requestId,
+			"status":     job.GetRequestStatusString(),
+		})
+
+		switch {
+		case !exists && job.GetJobStatus() == models.JOB_STATUS_PENDING:
+			// removed from the router without a fulfillment event for this provider
+			reqLogger.Warn("request not found on chain - will not be fulfilled")
+			_ = o.failRequest(requestId, models.REQUEST_STATUS_DUPLICATE, errcodes.Errorf(errcodes.ErrAlreadyFulfilled, "not found on chain during replay"))
+			summary.Missing++
+		case exists && job.GetRequestStatus() == models.REQUEST_STATUS_SUCCESS:
+			reqLogger.Warn("request marked as fulfilled but still pending on chain - return to job queue")
+			_ = o.db.ResetRequestForReplay(requestId)
+			summary.Reset++
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"requests":  summary.Requests,
+		"inserted":  summary.Inserted,
+		"fulfilled": summary.Fulfilled,
+		"reset":     summary.Reset,
+		"missing":   summary.Missing,
+	}).Info("replay complete")
+
+	return summary, nil
+}
@@ -0,0 +1,104 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/alerts"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	"go-ooo/ooo_api"
+	"time"
+)
+
+// defaultPriceFreshness - seconds a fetched price may be resubmitted for, if
+// jobs.price_freshness is not set
+const defaultPriceFreshness = 60
+
+// kinds of fulfillment resubmission which can re-price a job
+const (
+	RepriceReverted    = "reverted"
+	RepriceReplacement = "replacement"
+)
+
+var repricesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ooo_fulfillment_reprices_total",
+	Help: "Number of jobs whose price was fetched again before their fulfillment was resubmitted, as the price had gone stale, by kind - reverted or replacement",
+}, []string{"kind"})
+
+func priceFreshness() time.Duration {
+	freshness := int64(defaultPriceFreshness)
+	if viper.IsSet(config.JobsPriceFreshness) {
+		freshness = viper.GetInt64(config.JobsPriceFreshness)
+	}
+	return time.Duration(freshness) * time.Second
+}
+
+// priceStale returns true if the job's price was fetched longer than jobs.price_freshness ago, so
+// should be fetched again rather than resubmitted. Jobs fetched before the fetch time was recorded
+// are never stale
+func priceStale(job models.DataRequests) bool {
+	freshness := priceFreshness()
+	if freshness <= 0 || job.GetDataFetchedAt() == 0 {
+		return false
+	}
+	return time.Since(time.Unix(0, job.GetDataFetchedAt()*int64(time.Millisecond))) > freshness
+}
+
+// repriceJob fetches the price for a job whose fulfillment tx is being replaced, and records it in
+// place of the stale one, without changing the job's status. The returned job has the new price.
+// It returns false if no usable price could be fetched, in which case the tx should not be
+// replaced. The answer is not checked against the consensus peers, since it cannot be withheld
+// while the tx it replaces is pending
+func (o *OoORouterService) repriceJob(ctx context.Context, job models.DataRequests) (models.DataRequests, bool) {
+	requestId := job.GetRequestId()
+	endpoint := job.GetEndpointDecoded()
+	logger := o.jobLogger(job).WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "repriceJob",
+		"request_id": requestId,
+		"endpoint":   endpoint,
+	})
+
+	sourcePolicy := ooo_api.ConsumerSourcePolicy(job.GetConsumer())
+	price, sources, explain, err := o.oooApi.QueryEndpointExplained(ctx, endpoint, requestId, sourcePolicy)
+	if err == nil {
+		err = ooo_api.ValidatePriceResult(price)
+	}
+	if err == nil {
+		if err = o.oooApi.CheckAnswerBound(endpoint, price); err != nil {
+			o.alerter.Alert(alerts.AlertPriceBound, endpoint, fmt.Sprintf("request %s for %s: %s", requestId, endpoint, err.Error()))
+		}
+	}
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"action": "fetch price",
+		}).Error(err.Error())
+		return job, false
+	}
+
+	sourceTier := ""
+	if explain != nil {
+		sourceTier = explain.SourceTier
+	}
+	if err = o.db.UpdateRepricedResult(requestId, price, o.oooApi.AnswerRounding(), sourcePolicy, sourceTier); err != nil {
+		logger.WithFields(logrus.Fields{
+			"action": "save price",
+		}).Error(err.Error())
+		return job, false
+	}
+	o.saveRequestSources(requestId, explain)
+	o.createAttestation(requestId, endpoint, price, sources)
+
+	logger.WithFields(logrus.Fields{
+		"stale_price": job.GetPriceResult(),
+		"price":       price,
+	}).Info("stale price fetched again")
+
+	job.PriceResult = price
+	job.DataFetchedAt = time.Now().UnixNano() / int64(time.Millisecond)
+	return job, true
+}
@@ -0,0 +1,37 @@
+package chain
+
+import (
+	"github.com/sirupsen/logrus"
+	"go-ooo/database/models"
+	"go-ooo/ooo_api"
+)
+
+// saveRequestSources records the breakdown of the sources a request's price was aggregated from,
+// so that it can be audited after the request is fulfilled
+func (o *OoORouterService) saveRequestSources(requestId string, explain *ooo_api.PriceExplanation) {
+	if explain == nil {
+		return
+	}
+
+	values := append(append([]ooo_api.ExplainedValue{}, explain.Values...), explain.Rejections...)
+	sources := make([]models.RequestSources, 0, len(values))
+	for _, v := range values {
+		sources = append(sources, models.RequestSources{
+			Source:      v.Source,
+			Value:       v.Value,
+			Deviation:   v.Deviation,
+			Excluded:    v.Excluded,
+			Weight:      v.Weight,
+			LatencyMs:   v.Latency.Milliseconds(),
+			PriceImpact: v.PriceImpact,
+		})
+	}
+
+	if err := o.db.SaveRequestSources(requestId, sources); err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "saveRequestSources",
+			"request_id": requestId,
+		}).Error(err.Error())
+	}
+}
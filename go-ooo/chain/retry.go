@@ -0,0 +1,127 @@
+package chain
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	"go-ooo/errcodes"
+	"go-ooo/webhooks"
+	"time"
+)
+
+var jobFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ooo_job_failures_total",
+	Help: "Number of job failures, by error code and outcome - retried, or failed and not to be fulfilled",
+}, []string{"code", "outcome"})
+
+func maxJobAttempts() uint64 {
+	maxAttempts := viper.GetUint64(config.JobsMaxAttempts)
+	if maxAttempts == 0 {
+		maxAttempts = 3
+	}
+	return maxAttempts
+}
+
+// retryBackoff returns the delay before the next retry, doubling with each failed attempt
+func retryBackoff(attempts uint64) time.Duration {
+	base := time.Duration(viper.GetInt64(config.JobsRetryBackoff)) * time.Second
+	max := time.Duration(viper.GetInt64(config.JobsRetryBackoffMax)) * time.Second
+
+	if base <= 0 {
+		return 0
+	}
+
+	delay := base
+	for i := uint64(1); i < attempts; i++ {
+		delay *= 2
+		if max > 0 && delay >= max {
+			return max
+		}
+	}
+
+	if max > 0 && delay > max {
+		return max
+	}
+
+	return delay
+}
+
+// jobReadyForRetry returns false if a failed job's backoff has not yet elapsed
+func jobReadyForRetry(job models.DataRequests) bool {
+	return job.GetNextRetryAt() <= time.Now().Unix()
+}
+
+// failJob records a failed attempt for a job, with the error's code. The job is retried after a
+// backoff, or dead lettered if it has exhausted its attempts
+func (o *OoORouterService) failJob(requestId string, status int, failure error) {
+	code := string(errcodes.CodeOf(failure))
+	reason := failure.Error()
+
+	job, err := o.db.FindByRequestId(requestId)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "failJob",
+			"action":     "get job",
+			"request_id": requestId,
+		}).Error(err.Error())
+		return
+	}
+
+	if job.GetFulfillmentAttempts() >= maxJobAttempts() {
+		o.deadLetterJob(requestId, job.GetFulfillmentAttempts(), code, reason)
+		return
+	}
+
+	nextRetryAt := time.Now().Add(retryBackoff(job.GetFulfillmentAttempts()))
+
+	o.jobLogger(job).WithFields(logrus.Fields{
+		"package":       "chain",
+		"function":      "failJob",
+		"request_id":    requestId,
+		"error_code":    code,
+		"num_attempts":  job.GetFulfillmentAttempts(),
+		"next_retry_at": nextRetryAt.Unix(),
+	}).Debug("schedule retry")
+
+	jobFailures.WithLabelValues(code, "retried").Inc()
+	_ = o.db.UpdateRequestRetry(requestId, status, code, reason, nextRetryAt.Unix())
+}
+
+// deadLetterJob fails a job which has exhausted its attempts, keeping the code of its last
+// failure, if it has one
+func (o *OoORouterService) deadLetterJob(requestId string, attempts uint64, code string, reason string) {
+	if code == "" {
+		code = string(errcodes.CodeRetriesExhausted)
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":      "chain",
+		"function":     "deadLetterJob",
+		"request_id":   requestId,
+		"error_code":   code,
+		"num_attempts": attempts,
+		"reason":       reason,
+	}).Warn("too many failed attempts - job is dead")
+
+	deadReason := errcodes.ErrRetriesExhausted.Error()
+	if reason != "" {
+		deadReason = fmt.Sprintf("%s: %s", deadReason, reason)
+	}
+
+	jobFailures.WithLabelValues(code, "failed").Inc()
+	_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_DEAD, code, deadReason)
+	o.recordJobEvent(webhooks.EventFailed, requestId)
+}
+
+// failRequest sets a status meaning the request will not be fulfilled, e.g. skipped for a low
+// fee, with the failure's code
+func (o *OoORouterService) failRequest(requestId string, status int, failure error) error {
+	code := string(errcodes.CodeOf(failure))
+	jobFailures.WithLabelValues(code, "failed").Inc()
+	return o.db.UpdateRequestStatus(requestId, status, code, failure.Error())
+}
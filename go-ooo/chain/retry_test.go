@@ -0,0 +1,63 @@
+package chain
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"go-ooo/database/mock"
+	"go-ooo/database/models"
+	"go-ooo/errcodes"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func testRouterService(db *mock.Store) *OoORouterService {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	return &OoORouterService{db: db, logger: logger}
+}
+
+func TestFailJobSchedulesRetry(t *testing.T) {
+	db := &mock.Store{
+		FindByRequestIdFunc: func(requestId string) (models.DataRequests, error) {
+			return models.DataRequests{RequestId: requestId, FulfillmentAttempts: 1}, nil
+		},
+	}
+	o := testRouterService(db)
+
+	before := time.Now().Unix()
+	o.failJob("0x01", models.REQUEST_STATUS_API_ERROR, errcodes.Errorf(errcodes.ErrSourceUnavailable, "subgraph down"))
+
+	calls := db.CallsTo("UpdateRequestRetry")
+	if len(calls) != 1 {
+		t.Fatalf("%d retries recorded, want 1", len(calls))
+	}
+	args := calls[0].Args
+	if args[0] != "0x01" || args[1] != models.REQUEST_STATUS_API_ERROR {
+		t.Errorf("retry recorded for %v with status %v", args[0], args[1])
+	}
+	if code := errcodes.CodeOf(errcodes.ErrSourceUnavailable); args[2] != string(code) {
+		t.Errorf("retry recorded with code %v, want %s", args[2], code)
+	}
+	if next := args[4].(int64); next < before {
+		t.Errorf("next retry at %d, before the failure at %d", next, before)
+	}
+	if n := len(db.CallsTo("UpdateRequestStatus")); n != 0 {
+		t.Errorf("job with attempts left dead lettered")
+	}
+}
+
+func TestFailJobUnknownRequest(t *testing.T) {
+	db := &mock.Store{
+		FindByRequestIdFunc: func(string) (models.DataRequests, error) {
+			return models.DataRequests{}, errors.New("record not found")
+		},
+	}
+	o := testRouterService(db)
+
+	o.failJob("0x01", models.REQUEST_STATUS_API_ERROR, errors.New("failed"))
+
+	if n := len(db.Calls()); n != 1 {
+		t.Errorf("%d calls to the database, want only the lookup", n)
+	}
+}
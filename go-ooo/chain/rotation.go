@@ -0,0 +1,78 @@
+package chain
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/signer"
+	"time"
+)
+
+// retiringKey is the previous provider key during a key rotation. Requests addressed to it
+// are still fulfilled with it, until it is retired. New requests are only accepted for it
+// until the grace window ends, after which consumers must use the new provider address
+type retiringKey struct {
+	*accountKey
+	until time.Time
+}
+
+// initRetiringKey sets up the retiring key, if a key rotation is in progress
+func (o *OoORouterService) initRetiringKey(retiringSigner signer.Signer) error {
+	if retiringSigner == nil {
+		return nil
+	}
+
+	key, err := o.newAccountKey(retiringSigner)
+	if err != nil {
+		return err
+	}
+
+	o.retiring = &retiringKey{
+		accountKey: key,
+		until:      time.Unix(viper.GetInt64(config.KeystorageRetiringUntil), 0),
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "initRetiringKey",
+		"address":  o.retiring.address.Hex(),
+		"until":    o.retiring.until.UTC().Format(time.RFC3339),
+	}).Info("key rotation in progress - fulfilling requests to the retiring key")
+
+	return nil
+}
+
+// providerAddresses returns the addresses whose requests the node handles
+func (o *OoORouterService) providerAddresses() []common.Address {
+	me := []common.Address{o.oracleAddress}
+	if o.retiring != nil {
+		me = append(me, o.retiring.address)
+	}
+	return me
+}
+
+// isRetiredProvider returns true if provider is the retiring key, and its grace window has ended
+func (o *OoORouterService) isRetiredProvider(provider common.Address) bool {
+	return o.retiring != nil && provider == o.retiring.address && time.Now().After(o.retiring.until)
+}
+
+// signerFor returns the signer for requests addressed to provider
+func (o *OoORouterService) signerFor(provider string) signer.Signer {
+	if o.retiring != nil && common.HexToAddress(provider) == o.retiring.address {
+		return o.retiring.signer
+	}
+	return o.oracleSigner
+}
+
+// transactOptsFor returns the transact opts for fulfilling requests addressed to provider, and
+// whether they are the retiring key's. Must be called with txMu held
+func (o *OoORouterService) transactOptsFor(provider string) (*bind.TransactOpts, bool, error) {
+	if o.retiring == nil || common.HexToAddress(provider) != o.retiring.address {
+		return o.transactOpts, false, nil
+	}
+
+	opts, err := o.keyTransactOpts(o.retiring.accountKey)
+	return opts, true, err
+}
@@ -0,0 +1,254 @@
+package chain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/alerts"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	go_ooo_types "go-ooo/types"
+	"os"
+	"sort"
+	"strings"
+)
+
+// kinds of difference found verifying a state snapshot
+const (
+	// SnapshotNetwork - the snapshot was saved by a node on another network
+	SnapshotNetwork = "network"
+	// SnapshotBlock - the db's last processed block is behind the snapshot's, e.g. restored from an
+	// older backup. The blocks between are processed again
+	SnapshotBlock = "block"
+	// SnapshotNonce - a key's next nonce on chain is not the one it had at shutdown
+	SnapshotNonce = "nonce"
+	// SnapshotTxDropped - a tx in flight at shutdown is neither mined nor known to the chain node
+	SnapshotTxDropped = "tx_dropped"
+)
+
+var stateSnapshotDiscrepanciesGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "ooo_state_snapshot_discrepancies",
+	Help: "Differences found verifying the state snapshot the last node saved on shutdown",
+})
+
+// SaveStateSnapshot records the last block processed, each key's next nonce and the fulfillment
+// txs in flight, to be verified by VerifyStateSnapshot the next time a node starts processing.
+// Called by the leader on a clean shutdown, after the event subscriptions are closed
+func (o *OoORouterService) SaveStateSnapshot() {
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "SaveStateSnapshot",
+	})
+
+	nonces := make(map[string]uint64)
+	for role, address := range o.keyAddresses() {
+		nonce, err := o.client.PendingNonceAt(o.context, address)
+		if err != nil {
+			rpcError("PendingNonceAt")
+			logger.WithFields(logrus.Fields{
+				"role":    role,
+				"address": address.Hex(),
+			}).Error("cannot get next nonce - not saved in snapshot: ", err.Error())
+			continue
+		}
+		nonces[address.Hex()] = nonce
+	}
+
+	pendingTxs, err := o.inFlightTxHashes()
+	if err != nil {
+		logger.Error("cannot get in flight txs - not saved in snapshot: ", err.Error())
+	}
+
+	host, _ := os.Hostname()
+	noncesJson, _ := json.Marshal(nonces)
+	pendingJson, _ := json.Marshal(pendingTxs)
+	err = o.db.InsertStateSnapshot(models.StateSnapshots{
+		Host:       host,
+		NetworkId:  viper.GetInt64(config.ChainNetworkId),
+		LastBlock:  o.lastBlockNumber,
+		Nonces:     string(noncesJson),
+		PendingTxs: string(pendingJson),
+	})
+	if err != nil {
+		logger.Error("cannot save state snapshot: ", err.Error())
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"last_block": o.lastBlockNumber,
+		"num_keys":   len(nonces),
+		"in_flight":  len(pendingTxs),
+		"host":       host,
+		"network_id": viper.GetInt64(config.ChainNetworkId),
+	}).Info("saved state snapshot")
+}
+
+// inFlightTxHashes returns the hashes of the data and VOR fulfillment txs sent but not yet mined
+func (o *OoORouterService) inFlightTxHashes() ([]string, error) {
+	hashes := []string{}
+
+	jobs, err := o.db.GetPendingJobs()
+	if err != nil {
+		return hashes, err
+	}
+	for _, job := range jobs {
+		if job.GetRequestStatus() == models.REQUEST_STATUS_TX_SENT && job.GetFulfillTxHash() != "" {
+			hashes = append(hashes, job.GetFulfillTxHash())
+		}
+	}
+
+	vorRequests, err := o.db.GetPendingVorRequests()
+	if err != nil {
+		return hashes, err
+	}
+	for _, req := range vorRequests {
+		if req.GetRequestStatus() == models.REQUEST_STATUS_TX_SENT && req.GetFulfillTxHash() != "" {
+			hashes = append(hashes, req.GetFulfillTxHash())
+		}
+	}
+
+	return hashes, nil
+}
+
+// VerifyStateSnapshot checks the state the last node saved on shutdown against the db and the
+// chain, before the journal is reconciled and missed events are processed. Differences are
+// logged, alerted and recorded with the snapshot. If the last snapshot has already been verified,
+// the node which ran since did not shut down cleanly, which is alerted too
+func (o *OoORouterService) VerifyStateSnapshot() {
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "VerifyStateSnapshot",
+	})
+
+	snapshot, err := o.db.GetLatestStateSnapshot()
+	if err != nil {
+		logger.Error("cannot load state snapshot: ", err.Error())
+		return
+	}
+	if snapshot.ID == 0 {
+		logger.Info("no state snapshot saved - nothing to verify")
+		return
+	}
+	if snapshot.GetVerifiedAt() != 0 {
+		stateSnapshotDiscrepanciesGauge.Set(0)
+		msg := fmt.Sprintf("the last node to run did not shut down cleanly - no state snapshot saved since %s's at block %d",
+			snapshot.GetHost(), snapshot.GetLastBlock())
+		logger.Warn(msg)
+		o.alerter.Alert(alerts.AlertStateSnapshot, "", msg)
+		return
+	}
+
+	discrepancies := o.verifyStateSnapshot(snapshot)
+	verification, _ := json.Marshal(discrepancies)
+	if err = o.db.UpdateStateSnapshotVerified(snapshot.ID, string(verification)); err != nil {
+		logger.Error("cannot record state snapshot verification: ", err.Error())
+	}
+	stateSnapshotDiscrepanciesGauge.Set(float64(len(discrepancies)))
+
+	if len(discrepancies) == 0 {
+		logger.WithFields(logrus.Fields{
+			"host":       snapshot.GetHost(),
+			"last_block": snapshot.GetLastBlock(),
+		}).Info("state snapshot verified")
+		o.alerter.Resolve(alerts.AlertStateSnapshot, "")
+		return
+	}
+
+	var found []string
+	for _, d := range discrepancies {
+		found = append(found, d.Detail)
+	}
+	msg := fmt.Sprintf("state snapshot saved by %s at block %d: %s", snapshot.GetHost(), snapshot.GetLastBlock(),
+		strings.Join(found, "; "))
+	logger.Warn(msg)
+	o.alerter.Alert(alerts.AlertStateSnapshot, "", msg)
+}
+
+// verifyStateSnapshot returns the differences between the snapshot and the current db and chain
+func (o *OoORouterService) verifyStateSnapshot(snapshot models.StateSnapshots) []go_ooo_types.StateSnapshotDiscrepancy {
+	discrepancies := []go_ooo_types.StateSnapshotDiscrepancy{}
+	discrepancy := func(kind string, detail string) {
+		discrepancies = append(discrepancies, go_ooo_types.StateSnapshotDiscrepancy{Kind: kind, Detail: detail})
+	}
+
+	if networkId := viper.GetInt64(config.ChainNetworkId); snapshot.GetNetworkId() != networkId {
+		// nothing else is comparable
+		discrepancy(SnapshotNetwork, fmt.Sprintf("saved on network %d, node is on network %d", snapshot.GetNetworkId(), networkId))
+		return discrepancies
+	}
+
+	if lastBlock, err := o.db.GetLastBlockNumQueried(); err == nil && lastBlock.GetBlockNum() < snapshot.GetLastBlock() {
+		discrepancy(SnapshotBlock, fmt.Sprintf("db's last processed block %d is behind the snapshot's %d - blocks %d to %d will be processed again",
+			lastBlock.GetBlockNum(), snapshot.GetLastBlock(), lastBlock.GetBlockNum()+1, snapshot.GetLastBlock()))
+	}
+
+	nonces := make(map[string]uint64)
+	_ = json.Unmarshal([]byte(snapshot.GetNonces()), &nonces)
+	addresses := make([]string, 0, len(nonces))
+	for address := range nonces {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	for _, address := range addresses {
+		saved := nonces[address]
+		nonce, err := o.client.PendingNonceAt(o.context, common.HexToAddress(address))
+		if err != nil {
+			rpcError("PendingNonceAt")
+			continue
+		}
+		switch {
+		case nonce < saved:
+			discrepancy(SnapshotNonce, fmt.Sprintf("%s next nonce is %d, %d at shutdown - txs were dropped by the chain node", address, nonce, saved))
+		case nonce > saved:
+			discrepancy(SnapshotNonce, fmt.Sprintf("%s next nonce is %d, %d at shutdown - %d txs were sent with the key since, is another node using it?",
+				address, nonce, saved, nonce-saved))
+		}
+	}
+
+	var pendingTxs []string
+	_ = json.Unmarshal([]byte(snapshot.GetPendingTxs()), &pendingTxs)
+	for _, txHash := range pendingTxs {
+		hash := common.HexToHash(txHash)
+		if _, err := o.client.TransactionReceipt(o.context, hash); err == nil {
+			continue
+		}
+		_, _, err := o.client.TransactionByHash(o.context, hash)
+		if errors.Is(err, ethereum.NotFound) {
+			discrepancy(SnapshotTxDropped, fmt.Sprintf("tx %s in flight at shutdown is unknown to the chain node - it will be resent once stuck", txHash))
+		} else if err != nil {
+			rpcError("TransactionByHash")
+		}
+	}
+
+	return discrepancies
+}
+
+// GetStateSnapshot returns the last state snapshot saved on shutdown, and its verification. The
+// snapshot's SavedAt is 0 if none has been saved
+func (o *OoORouterService) GetStateSnapshot() (go_ooo_types.StateSnapshot, error) {
+	snapshot, err := o.db.GetLatestStateSnapshot()
+	if err != nil || snapshot.ID == 0 {
+		return go_ooo_types.StateSnapshot{}, err
+	}
+
+	res := go_ooo_types.StateSnapshot{
+		SavedAt:       snapshot.CreatedAt.Unix(),
+		Host:          snapshot.GetHost(),
+		NetworkId:     snapshot.GetNetworkId(),
+		LastBlock:     snapshot.GetLastBlock(),
+		Nonces:        map[string]uint64{},
+		PendingTxs:    []string{},
+		VerifiedAt:    snapshot.GetVerifiedAt(),
+		Discrepancies: []go_ooo_types.StateSnapshotDiscrepancy{},
+	}
+	_ = json.Unmarshal([]byte(snapshot.GetNonces()), &res.Nonces)
+	_ = json.Unmarshal([]byte(snapshot.GetPendingTxs()), &res.PendingTxs)
+	_ = json.Unmarshal([]byte(snapshot.GetVerification()), &res.Discrepancies)
+	return res, nil
+}
@@ -0,0 +1,102 @@
+package chain
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"go-ooo/ooo_router"
+	go_ooo_types "go-ooo/types"
+	"go-ooo/version"
+	"math/big"
+	"strings"
+)
+
+// erc20BalanceOfAbi is the only part of the xFUND token's ABI the node needs
+const erc20BalanceOfAbi = `[{"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"}]`
+
+// NodeStatus returns the current state of the node, for the status command and admin API
+func (o *OoORouterService) NodeStatus() (go_ooo_types.NodeStatus, error) {
+	status := go_ooo_types.NodeStatus{
+		Version:         version.NewInfo().StringLine(),
+		OracleAddress:   o.oracleAddress.Hex(),
+		ContractAddress: o.contractAddress.Hex(),
+		Paused:          o.pauseStatus(),
+		LastBlock:       o.lastBlockNumber,
+		Workers:         o.workers.size,
+		VorEnabled:      o.VorEnabled(),
+		Totals:          LifetimeTotals(),
+	}
+
+	if o.retiring != nil {
+		status.RetiringAddress = o.retiring.address.Hex()
+		status.RetiringUntil = o.retiring.until.Unix()
+	}
+
+	currentBlockNum, err := o.client.BlockNumber(o.context)
+	if err != nil {
+		return status, err
+	}
+	status.CurrentBlock = currentBlockNum
+
+	balance, err := o.WalletBalance(o.context)
+	if err != nil {
+		return status, err
+	}
+	status.WalletBalance = balance.String()
+
+	xfundBalance, err := o.XfundBalance()
+	if err != nil {
+		return status, err
+	}
+	status.XfundBalance = xfundBalance.String()
+
+	withdrawable, err := o.contractInstance.GetWithdrawableTokens(o.callOpts, o.oracleAddress)
+	if err != nil {
+		return status, err
+	}
+	status.WithdrawableFees = withdrawable.String()
+
+	if len(o.keyAddresses()) > 1 {
+		keys, err := o.KeyBalances(o.context)
+		if err != nil {
+			return status, err
+		}
+		status.Keys = keys
+	}
+
+	pendingJobs, err := o.db.CountUnsentJobs()
+	if err != nil {
+		return status, err
+	}
+	status.PendingJobs = pendingJobs
+
+	return status, nil
+}
+
+// XfundBalance returns the oracle wallet's xFUND balance
+func (o *OoORouterService) XfundBalance() (*big.Int, error) {
+	return XfundBalanceOf(o.callOpts, o.client, o.contractInstance, o.oracleAddress)
+}
+
+// XfundBalanceOf returns the xFUND balance of address, using the token the router is configured with
+func XfundBalanceOf(callOpts *bind.CallOpts, client bind.ContractBackend, router *ooo_router.OooRouter,
+	address common.Address) (*big.Int, error) {
+	tokenAddress, err := router.GetTokenAddress(callOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(erc20BalanceOfAbi))
+	if err != nil {
+		return nil, err
+	}
+
+	var out []interface{}
+	token := bind.NewBoundContract(tokenAddress, parsed, client, client, client)
+	err = token.Call(callOpts, &out, "balanceOf", address)
+	if err != nil {
+		return nil, err
+	}
+
+	return *abi.ConvertType(out[0], new(*big.Int)).(**big.Int), nil
+}
@@ -0,0 +1,550 @@
+package chain
+
+import (
+	"fmt"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	go_ooo_types "go-ooo/types"
+	"go-ooo/vor"
+	"go-ooo/vor_coordinator"
+	"math/big"
+	"time"
+)
+
+// blockhash() is only available for the most recent 256 blocks, so the coordinator can't
+// verify proofs for requests older than this
+const vorMaxRequestAge = 250
+
+// initVor binds the VORCoordinator contract, if configured. The oracle key, or VOR's own key
+// if assigned, is also used as the VOR proving key, so VOR needs a signer backend which holds
+// the key locally.
+func (o *OoORouterService) initVor() error {
+	vorAddress := viper.GetString(config.ChainVorCoordinatorAddress)
+	if len(vorAddress) == 0 || !config.FeatureEnabled(config.FeaturesVor) {
+		return nil
+	}
+
+	vorSigner := o.oracleSigner
+	if o.vorKey != nil {
+		vorSigner = o.vorKey.signer
+	}
+	vorPrivateKey, err := vorSigner.PrivateKey()
+	if err != nil {
+		return fmt.Errorf("VOR proofs are generated with the oracle key: %s", err.Error())
+	}
+
+	vorInstance, err := vor_coordinator.NewVorCoordinator(common.HexToAddress(vorAddress), o.client)
+	if err != nil {
+		return err
+	}
+
+	o.vorInstance = vorInstance
+	o.vorPrivateKey = vorPrivateKey
+	o.vorPublicKey = vor.PublicKey(vorPrivateKey)
+	o.vorKeyHash = vor.KeyHash(o.vorPublicKey)
+	o.chanVorRequests = make(chan *vor_coordinator.VorCoordinatorRandomnessRequest)
+	o.chanVorFulfilled = make(chan *vor_coordinator.VorCoordinatorRandomnessRequestFulfilled)
+
+	o.logger.WithFields(logrus.Fields{
+		"package":     "chain",
+		"function":    "initVor",
+		"coordinator": vorAddress,
+		"address":     o.vorAddress().Hex(),
+		"key_hash":    o.vorKeyHash.Hex(),
+	}).Info("VOR fulfillment enabled")
+
+	return nil
+}
+
+// VorEnabled returns true if a VORCoordinator contract is configured
+func (o *OoORouterService) VorEnabled() bool {
+	return o.vorInstance != nil
+}
+
+func (o *OoORouterService) getVorHistoricalEvents(filterOpts *bind.FilterOpts) {
+	o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "getVorHistoricalEvents",
+		"from_block": filterOpts.Start,
+	}).Debug("get VOR event history")
+
+	itrRr, err := o.vorInstance.FilterRandomnessRequest(filterOpts, nil)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "getVorHistoricalEvents",
+			"action":   "get FilterRandomnessRequest events",
+		}).Error(err.Error())
+		return
+	}
+
+	for itrRr.Next() {
+		o.processIncomingVorRequests(itrRr.Event)
+	}
+
+	itrRf, err := o.vorInstance.FilterRandomnessRequestFulfilled(filterOpts)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "getVorHistoricalEvents",
+			"action":   "get FilterRandomnessRequestFulfilled events",
+		}).Error(err.Error())
+		return
+	}
+
+	for itrRf.Next() {
+		o.processIncomingVorFulfilments(itrRf.Event)
+	}
+}
+
+func (o *OoORouterService) subscribeToVorEvents() {
+	if o.subscriptionVorRr != nil {
+		o.subscriptionVorRr.Unsubscribe()
+	}
+	if o.subscriptionVorRf != nil {
+		o.subscriptionVorRf.Unsubscribe()
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.MaxElapsedTime = 10 * time.Minute
+
+	var subRr event.Subscription
+	var subRf event.Subscription
+
+	retryable := func() error {
+		var subErr error
+		subRr, subErr = o.vorInstance.WatchRandomnessRequest(o.watchOpts, o.chanVorRequests, nil)
+		if subErr != nil {
+			return subErr
+		}
+		subRf, subErr = o.vorInstance.WatchRandomnessRequestFulfilled(o.watchOpts, o.chanVorFulfilled)
+		if subErr != nil {
+			subRr.Unsubscribe()
+		}
+		return subErr
+	}
+
+	notify := func(err error, t time.Duration) {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "subscribeToVorEvents",
+			"action":   "init subscription",
+		}).Error(err.Error())
+	}
+
+	err := backoff.RetryNotify(retryable, b, notify)
+
+	if err != nil {
+		// no point continuing if we can't connect after retrying
+		panic(err)
+	}
+
+	o.subscriptionVorRr = subRr
+	o.subscriptionVorRf = subRf
+}
+
+func (o *OoORouterService) RunVorEventWatchers() {
+	o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "RunVorEventWatchers",
+	}).Info("initialise VOR event subscriptions")
+
+	o.subscribeToVorEvents()
+
+	for {
+		select {
+		case ev := <-o.chanVorRequests:
+			if !o.ingestionPaused() {
+				o.processIncomingVorRequests(ev)
+			}
+		case ev := <-o.chanVorFulfilled:
+			if !o.ingestionPaused() {
+				o.processIncomingVorFulfilments(ev)
+			}
+		case subErr := <-o.subscriptionVorRr.Err():
+			if subErr != nil {
+				o.logger.WithFields(logrus.Fields{
+					"package":  "chain",
+					"function": "RunVorEventWatchers",
+					"action":   "RandomnessRequest subscription connection error",
+				}).Error(subErr.Error())
+				o.subscribeToVorEvents()
+			}
+		case subErr := <-o.subscriptionVorRf.Err():
+			if subErr != nil {
+				o.logger.WithFields(logrus.Fields{
+					"package":  "chain",
+					"function": "RunVorEventWatchers",
+					"action":   "RandomnessRequestFulfilled subscription connection error",
+				}).Error(subErr.Error())
+				o.subscribeToVorEvents()
+			}
+		}
+	}
+}
+
+func (o *OoORouterService) processIncomingVorRequests(ev *vor_coordinator.VorCoordinatorRandomnessRequest) {
+	// key hash is not indexed, so we receive all requests
+	if common.Hash(ev.KeyHash) != o.vorKeyHash {
+		return
+	}
+
+	requestId := common.Bytes2Hex(ev.RequestID[:])
+
+	o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "processIncomingVorRequests",
+		"request_id": requestId,
+		"sender":     ev.Sender.Hex(),
+	}).Info("got randomness request event for me")
+
+	reqDbRes, _ := o.db.FindVorRequestByRequestId(requestId)
+	if reqDbRes.ID != 0 {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "processIncomingVorRequests",
+			"action":     "check db for request",
+			"request_id": requestId,
+		}).Info("request already in db")
+		return
+	}
+
+	err := o.db.InsertNewVorRequest(
+		requestId,
+		o.vorKeyHash.Hex(),
+		ev.Sender.Hex(),
+		ev.Seed.String(),
+		ev.Fee.String(),
+		ev.Raw.BlockNumber,
+		ev.Raw.BlockHash.Hex(),
+		ev.Raw.TxHash.Hex(),
+	)
+
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "processIncomingVorRequests",
+			"action":     "InsertNewVorRequest",
+			"request_id": requestId,
+		}).Error(err.Error())
+		return
+	}
+
+	o.signalJobActivity()
+}
+
+func (o *OoORouterService) processIncomingVorFulfilments(ev *vor_coordinator.VorCoordinatorRandomnessRequestFulfilled) {
+	requestId := common.Bytes2Hex(ev.RequestId[:])
+
+	// requestId is not indexed, so only process fulfilments for requests we know about
+	reqDbRes, _ := o.db.FindVorRequestByRequestId(requestId)
+	if reqDbRes.ID == 0 {
+		return
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "processIncomingVorFulfilments",
+		"action":     "confirm fulfillment",
+		"request_id": requestId,
+	}).Info("confirmed randomness fulfilment for request")
+
+	err := o.db.UpdateVorFulfillmentSuccess(requestId, ev.Raw.BlockNumber, ev.Raw.TxHash.Hex(), ev.Output.String())
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "processIncomingVorFulfilments",
+			"action":   "UpdateVorFulfillmentSuccess",
+		}).Error(err.Error())
+	}
+}
+
+// ProcessPendingVorRequests fulfills pending VOR requests, returning the number pending
+func (o *OoORouterService) ProcessPendingVorRequests() int {
+	if !o.VorEnabled() || o.fulfillmentPaused() {
+		return 0
+	}
+
+	requests, err := o.db.GetPendingVorRequests()
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "ProcessPendingVorRequests",
+			"action":   "get pending VOR requests",
+		}).Error(err.Error())
+		return 0
+	}
+
+	if len(requests) == 0 {
+		return 0
+	}
+
+	currentBlockNum, err := o.client.BlockNumber(o.context)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "ProcessPendingVorRequests",
+			"action":   "get block num",
+		}).Error(err.Error())
+		return len(requests)
+	}
+
+	for _, req := range requests {
+		o.preProcessPendingVorRequest(req, currentBlockNum)
+	}
+
+	return len(requests)
+}
+
+func (o *OoORouterService) preProcessPendingVorRequest(req models.VorRequests, currentBlockNum uint64) {
+	requestId := req.GetRequestId()
+	requestBlockDiff := currentBlockNum - req.GetRequestBlockNumber()
+
+	switch req.GetRequestStatus() {
+	case models.REQUEST_STATUS_INITIALISED, models.REQUEST_STATUS_TX_FAILED:
+		if req.GetFulfillmentAttempts() >= 3 {
+			o.logger.WithFields(logrus.Fields{
+				"package":      "chain",
+				"function":     "preProcessPendingVorRequest",
+				"action":       "check num attempts",
+				"request_id":   requestId,
+				"num_attempts": req.GetFulfillmentAttempts(),
+			}).Warn("too many failed attempts")
+			_ = o.db.UpdateVorRequestStatus(requestId, models.REQUEST_STATUS_FULFILMENT_FAILED, "too many failed attempts")
+			return
+		}
+		if requestBlockDiff > vorMaxRequestAge {
+			o.logger.WithFields(logrus.Fields{
+				"package":    "chain",
+				"function":   "preProcessPendingVorRequest",
+				"action":     "check request age",
+				"request_id": requestId,
+			}).Warn("request too old")
+			_ = o.db.UpdateVorRequestStatus(requestId, models.REQUEST_STATUS_FULFILMENT_FAILED, "request too old")
+			return
+		}
+		if requestBlockDiff < viper.GetUint64(config.JobsWaitConfirmations) {
+			return
+		}
+		o.sendVorFulfillmentTx(req, currentBlockNum)
+	case models.REQUEST_STATUS_TX_SENT:
+		o.processPossiblyStuckVorTx(req, currentBlockNum)
+	}
+}
+
+func (o *OoORouterService) sendVorFulfillmentTx(req models.VorRequests, currentBlockNum uint64) {
+	requestId := req.GetRequestId()
+
+	o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "sendVorFulfillmentTx",
+		"request_id": requestId,
+	}).Debug("begin send randomness fulfillment transaction")
+
+	preSeed, ok := new(big.Int).SetString(req.GetSeed(), 10)
+	if !ok {
+		_ = o.db.UpdateVorRequestStatus(requestId, models.REQUEST_STATUS_FULFILMENT_FAILED, "invalid seed")
+		return
+	}
+
+	seed := vor.FinalSeed(preSeed, common.HexToHash(req.GetRequestBlockHash()))
+
+	proof, err := vor.GenerateProof(o.vorPrivateKey, seed)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "sendVorFulfillmentTx",
+			"action":     "generate proof",
+			"request_id": requestId,
+		}).Error(err.Error())
+		_ = o.db.UpdateVorRequestStatus(requestId, models.REQUEST_STATUS_TX_FAILED, err.Error())
+		return
+	}
+
+	release, ok := o.paceSubmission(o.context, requestId)
+	if !ok {
+		// shutting down
+		return
+	}
+	defer release()
+
+	o.txMu.Lock()
+	defer o.txMu.Unlock()
+
+	err = o.RenewTransactOpts()
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "sendVorFulfillmentTx",
+			"action":     "RenewTransactOpts",
+			"request_id": requestId,
+		}).Error(err.Error())
+		return
+	}
+
+	txOpts, err := o.vorTransactOpts()
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "sendVorFulfillmentTx",
+			"action":     "get transact opts",
+			"request_id": requestId,
+		}).Error(err.Error())
+		return
+	}
+
+	tx, err := o.vorInstance.FulfillRandomnessRequest(txOpts, vor.MarshalForCoordinator(proof, preSeed, req.GetRequestBlockNumber()))
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "sendVorFulfillmentTx",
+			"action":     "send transaction",
+			"request_id": requestId,
+		}).Error(err.Error())
+		_ = o.db.UpdateVorRequestStatus(requestId, models.REQUEST_STATUS_TX_FAILED, err.Error())
+		return
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "sendVorFulfillmentTx",
+		"action":     "send transaction",
+		"request_id": requestId,
+		"tx":         tx.Hash().Hex(),
+	}).Info("randomness fulfill tx sent")
+
+	_ = o.db.UpdateVorFulfillmentSent(requestId, tx.Hash().Hex(), currentBlockNum, proof.Output.String())
+
+	if o.vorKey == nil {
+		o.setNextTxNonce(tx.Nonce(), false)
+	}
+}
+
+func (o *OoORouterService) processPossiblyStuckVorTx(req models.VorRequests, currentBlockNum uint64) {
+	requestId := req.GetRequestId()
+
+	if currentBlockNum-req.GetLastFulfillSentBlockNumber() < 3 {
+		// too soon - may take a while for Tx to be broadcast/picked up
+		return
+	}
+
+	fulfilTxHash := common.HexToHash(req.GetFulfillTxHash())
+	_, isPending, err := o.client.TransactionByHash(o.context, fulfilTxHash)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "processPossiblyStuckVorTx",
+			"action":     "get fulfill tx",
+			"request_id": requestId,
+			"tx_hash":    req.GetFulfillTxHash(),
+		}).Error(err.Error())
+		return
+	}
+
+	if isPending {
+		return
+	}
+
+	fulfillReceipt, err := o.client.TransactionReceipt(o.context, fulfilTxHash)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "processPossiblyStuckVorTx",
+			"action":     "get fulfil tx receipt",
+			"request_id": requestId,
+			"tx_hash":    req.GetFulfillTxHash(),
+		}).Error(err.Error())
+		return
+	}
+
+	if fulfillReceipt.Status == 1 {
+		// RandomnessRequestFulfilled event may have been missed
+		_ = o.db.UpdateVorFulfillmentSuccess(requestId, fulfillReceipt.BlockNumber.Uint64(), req.GetFulfillTxHash(), req.GetRandomness())
+		return
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "processPossiblyStuckVorTx",
+		"action":     "check fulfill tx status",
+		"request_id": requestId,
+		"tx_hash":    req.GetFulfillTxHash(),
+	}).Warn("randomness fulfill tx reverted")
+
+	_ = o.db.UpdateVorRequestStatus(requestId, models.REQUEST_STATUS_TX_FAILED, "tx reverted")
+}
+
+func (o *OoORouterService) vorRegisterProvingKey(task go_ooo_types.AdminTask) go_ooo_types.AdminTaskResponse {
+	var resp go_ooo_types.AdminTaskResponse
+	resp.AdminTask = task
+
+	if !o.VorEnabled() {
+		resp.Error = "VOR not enabled. Set chain.vor_coordinator_address in config"
+		return resp
+	}
+
+	fee := task.FeeOrAmount
+	publicKey := [2]*big.Int{o.vorPublicKey.X, o.vorPublicKey.Y}
+
+	// the proving key may be the oracle key, whose nonce is shared with the fulfillment workers
+	o.txMu.Lock()
+	defer o.txMu.Unlock()
+
+	if err := o.RenewTransactOpts(); err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+	txOpts, err := o.vorTransactOpts()
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	tx, err := o.vorInstance.RegisterProvingKey(txOpts, big.NewInt(int64(fee)), o.vorAddress(), publicKey, false)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "vorRegisterProvingKey",
+		}).Error(err.Error())
+		resp.Error = err.Error()
+		return resp
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":  "chain",
+		"function": "vorRegisterProvingKey",
+		"key_hash": o.vorKeyHash.Hex(),
+		"fee":      fee,
+		"tx":       tx.Hash(),
+	}).Info("register VOR proving key tx sent")
+
+	if o.vorKey == nil {
+		o.setNextTxNonce(tx.Nonce(), false)
+	}
+
+	resp.Result = fmt.Sprintf("Sent! Key hash: %s, Tx Hash: %s", o.vorKeyHash.Hex(), tx.Hash().String())
+	resp.Success = true
+
+	return resp
+}
+
+func (o *OoORouterService) vorQueryKey(task go_ooo_types.AdminTask) go_ooo_types.AdminTaskResponse {
+	var resp go_ooo_types.AdminTaskResponse
+	resp.AdminTask = task
+
+	if !o.VorEnabled() {
+		resp.Error = "VOR not enabled. Set chain.vor_coordinator_address in config"
+		return resp
+	}
+
+	resp.Result = fmt.Sprintf("Key hash: %s, Public key: [%s, %s]", o.vorKeyHash.Hex(), o.vorPublicKey.X.String(), o.vorPublicKey.Y.String())
+	resp.Success = true
+
+	return resp
+}
@@ -0,0 +1,89 @@
+package chain
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	"go-ooo/errcodes"
+	"go-ooo/webhooks"
+	"time"
+)
+
+// RunStuckJobWatchdog finds jobs which have been fetching data for longer than the stuck job
+// threshold, e.g. because the node crashed mid-flight, verifies their on-chain state, and either
+// returns them to the job queue or marks them appropriately
+func (o *OoORouterService) RunStuckJobWatchdog() {
+	threshold := time.Duration(viper.GetInt64(config.JobsStuckThreshold)) * time.Second
+	if threshold <= 0 {
+		return
+	}
+
+	// give in-flight jobs at least the job timeout to complete
+	if threshold < jobTimeout() {
+		threshold = jobTimeout()
+	}
+
+	jobs, err := o.db.GetStuckJobs(models.REQUEST_STATUS_FETCHING_DATA, time.Now().Add(-threshold))
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "chain",
+			"function": "RunStuckJobWatchdog",
+			"action":   "get stuck jobs",
+		}).Error(err.Error())
+		return
+	}
+
+	for _, job := range jobs {
+		if o.workers.isInFlight(job.GetRequestId()) {
+			continue
+		}
+		o.reviveStuckJob(job)
+	}
+}
+
+func (o *OoORouterService) reviveStuckJob(job models.DataRequests) {
+	requestId := job.GetRequestId()
+
+	o.jobLogger(job).WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "reviveStuckJob",
+		"request_id": requestId,
+		"status":     job.GetRequestStatusString(),
+		"updated_at": job.UpdatedAt.Unix(),
+	}).Warn("found stuck job")
+
+	exists, err := o.requestExistsOnChain(o.context, requestId)
+	if err != nil {
+		o.jobLogger(job).WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "reviveStuckJob",
+			"action":     "check request exists",
+			"request_id": requestId,
+		}).Error(err.Error())
+		return
+	}
+
+	if !exists {
+		// already fulfilled. Try to pick up the fulfilment event, otherwise record as a duplicate
+		found, err := o.recoverFulfilledEvent(requestId, job.GetRequestBlockNumber())
+		if err != nil || !found {
+			_ = o.failRequest(requestId, models.REQUEST_STATUS_DUPLICATE, errcodes.ErrAlreadyFulfilled)
+			o.recordJobEvent(webhooks.EventSkipped, requestId)
+		}
+		return
+	}
+
+	if job.GetFulfillmentAttempts() >= maxJobAttempts() {
+		o.deadLetterJob(requestId, job.GetFulfillmentAttempts(), job.GetErrorCode(), "stuck fetching data")
+		return
+	}
+
+	o.jobLogger(job).WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "reviveStuckJob",
+		"request_id": requestId,
+	}).Info("return stuck job to queue")
+
+	_ = o.db.UpdateRequestStatus(requestId, models.REQUEST_STATUS_INITIALISED, "", "revived by watchdog")
+}
@@ -0,0 +1,62 @@
+package chain
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"go-ooo/alerts"
+	"go-ooo/database/models"
+	"go-ooo/webhooks"
+	"time"
+)
+
+// recordJobEvent journals a job lifecycle event, and sends the job's current state to the
+// configured webhooks and any live event stream subscribers
+func (o *OoORouterService) recordJobEvent(event string, requestId string) {
+	job, err := o.db.FindByRequestId(requestId)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "recordJobEvent",
+			"event":      event,
+			"request_id": requestId,
+		}).Error(err.Error())
+		return
+	}
+
+	o.journal(models.JOURNAL_KIND_JOB_EVENT, requestId, job.GetFulfillTxHash(), 0, "", 0,
+		fmt.Sprintf("%s: %s %s", event, job.GetRequestStatusString(), job.GetStatusReason()))
+
+	ev := webhooks.JobEvent{
+		Event:         event,
+		RequestId:     requestId,
+		Consumer:      job.GetConsumer(),
+		Endpoint:      job.GetEndpointDecoded(),
+		RequestStatus: job.GetRequestStatusString(),
+		StatusReason:  job.GetStatusReason(),
+		ErrorCode:     job.GetErrorCode(),
+		Price:         job.GetPriceResult(),
+		TxHash:        job.GetFulfillTxHash(),
+		Timestamp:     time.Now().Unix(),
+	}
+
+	observeJobEvent(event, job)
+	switch event {
+	case webhooks.EventReceived:
+		o.signalJobActivity()
+	case webhooks.EventFulfilled:
+		o.heartbeats.pairFulfilled(latencyPair(job), time.Now())
+	}
+	o.webhooks.Notify(ev)
+	o.eventStream.publish(ev)
+
+	if event == webhooks.EventFailed {
+		o.alerter.Alert(alerts.AlertFulfillmentFailed, requestId,
+			fmt.Sprintf("request %s for %s from %s failed: %s", requestId, ev.Endpoint, ev.Consumer, ev.StatusReason)+
+				o.alertTags(ev.Consumer, latencyPair(job)))
+	}
+}
+
+// Alerter returns the service's operational alerter
+func (o *OoORouterService) Alerter() *alerts.Alerter {
+	return o.alerter
+}
@@ -0,0 +1,285 @@
+package chain
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	"go-ooo/errcodes"
+	"sync"
+	"time"
+)
+
+// pendingJob is a job queued for processing by a worker, along with the block
+// number at which it was picked up from the job queue
+type pendingJob struct {
+	job             models.DataRequests
+	currentBlockNum uint64
+	// ctx is cancelled if the job is cancelled or expires while queued or being processed
+	ctx context.Context
+}
+
+// inFlightJob is a job queued or being processed by a worker
+type inFlightJob struct {
+	requestBlock uint64
+	// fetching is false for jobs dispatched to send, or check, a fulfillment tx
+	fetching bool
+	cancel   context.CancelFunc
+	// reason is why the job was cancelled, if it was
+	reason string
+	// done is closed once processing has stopped
+	done chan struct{}
+}
+
+// jobWorkerPool processes pending jobs concurrently, so that a slow upstream fetch
+// for one request does not delay every other request
+type jobWorkerPool struct {
+	size     int
+	jobs     chan pendingJob
+	mu       sync.Mutex
+	inFlight map[string]*inFlightJob
+}
+
+func newJobWorkerPool(numWorkers int) *jobWorkerPool {
+	return &jobWorkerPool{
+		size:     numWorkers,
+		jobs:     make(chan pendingJob, numWorkers*4),
+		inFlight: make(map[string]*inFlightJob),
+	}
+}
+
+// startJobWorkers starts numWorkers goroutines, which run until the service's context is done
+func (o *OoORouterService) startJobWorkers(numWorkers int) {
+	o.logger.WithFields(logrus.Fields{
+		"package":     "chain",
+		"function":    "startJobWorkers",
+		"num_workers": numWorkers,
+	}).Info("start job workers")
+
+	for i := 0; i < numWorkers; i++ {
+		workerId := i
+		go o.supervisor.Run(o.context, "job_worker", func() {
+			o.runJobWorker(workerId)
+		})
+	}
+}
+
+func (o *OoORouterService) runJobWorker(workerId int) {
+	for {
+		select {
+		case <-o.context.Done():
+			return
+		case p := <-o.workers.jobs:
+			o.processJobSafely(workerId, p)
+		}
+	}
+}
+
+// processJobSafely processes a single job, recovering from any panic so that
+// only the job being processed is affected and the worker carries on. If the job
+// exceeds the job timeout, it is failed with a TIMEOUT reason and the worker is freed.
+// A job cancelled while being processed frees the worker in the same way, and its
+// upstream calls are abandoned, but its status is left to whatever cancelled it
+func (o *OoORouterService) processJobSafely(workerId int, p pendingJob) {
+	requestId := p.job.GetRequestId()
+	timeout := jobTimeout()
+
+	ctx, cancel := context.WithTimeout(p.ctx, timeout)
+	defer cancel()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer func() {
+			// only release the job once processing has actually stopped, so that
+			// a timed out job is not picked up twice
+			o.workers.release(requestId)
+		}()
+
+		panicked := o.supervisor.Do("job", func() {
+			o.preProcessPendingJob(ctx, p.job, p.currentBlockNum)
+		})
+		if panicked {
+			// the panic and its stack are logged by the supervisor
+			o.jobLogger(p.job).WithFields(logrus.Fields{
+				"package":    "chain",
+				"function":   "processJobSafely",
+				"worker_id":  workerId,
+				"request_id": requestId,
+			}).Error("job processing panicked")
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		select {
+		case <-done:
+			// finished just as the timeout fired
+			return
+		default:
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			o.jobLogger(p.job).WithFields(logrus.Fields{
+				"package":    "chain",
+				"function":   "processJobSafely",
+				"worker_id":  workerId,
+				"request_id": requestId,
+				"timeout":    timeout.String(),
+			}).Warn("job timed out")
+
+			o.failJob(requestId, models.REQUEST_STATUS_TIMEOUT, errcodes.Errorf(errcodes.ErrJobTimeout, "TIMEOUT: job exceeded %s", timeout.String()))
+		} else if reason := o.workers.cancelReason(requestId); reason != "" {
+			o.jobLogger(p.job).WithFields(logrus.Fields{
+				"package":    "chain",
+				"function":   "processJobSafely",
+				"worker_id":  workerId,
+				"request_id": requestId,
+				"reason":     reason,
+			}).Info("job cancelled")
+		}
+	}
+}
+
+func jobTimeout() time.Duration {
+	timeout := viper.GetInt64(config.JobsTimeout)
+	if timeout <= 0 {
+		timeout = 120
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+// dispatchJob queues a job for the workers. Jobs already queued or being processed are
+// ignored, as are jobs which cannot be queued because all workers are busy - they
+// will be picked up again on the next job queue check
+func (o *OoORouterService) dispatchJob(job models.DataRequests, currentBlockNum uint64) {
+	requestId := job.GetRequestId()
+
+	o.workers.mu.Lock()
+	defer o.workers.mu.Unlock()
+
+	if _, ok := o.workers.inFlight[requestId]; ok {
+		o.jobLogger(job).WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "dispatchJob",
+			"request_id": requestId,
+		}).Debug("job already in progress")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(o.context)
+
+	select {
+	case o.workers.jobs <- pendingJob{job: job, currentBlockNum: currentBlockNum, ctx: ctx}:
+		o.workers.inFlight[requestId] = &inFlightJob{
+			requestBlock: job.RequestBlockNumber,
+			fetching:     job.GetRequestStatus() != models.REQUEST_STATUS_DATA_READY_TO_SEND && job.GetRequestStatus() != models.REQUEST_STATUS_TX_SENT,
+			cancel:       cancel,
+			done:         make(chan struct{}),
+		}
+	default:
+		cancel()
+		o.jobLogger(job).WithFields(logrus.Fields{
+			"package":    "chain",
+			"function":   "dispatchJob",
+			"request_id": requestId,
+		}).Debug("all workers busy - job will be retried on next check")
+	}
+}
+
+// cancelJob cancels the job if it is queued or being processed, abandoning its upstream calls,
+// and waits for processing to stop. A tx already being submitted is not abandoned. Returns
+// false if the job was not in flight
+func (o *OoORouterService) cancelJob(requestId string, reason string) bool {
+	done := o.workers.cancel(requestId, reason)
+	if done == nil {
+		return false
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":    "chain",
+		"function":   "cancelJob",
+		"request_id": requestId,
+		"reason":     reason,
+	}).Info("cancel in-flight job")
+
+	select {
+	case <-done:
+	case <-o.context.Done():
+	}
+
+	return true
+}
+
+// expireJobs cancels in-flight data fetches for requests older than RequestMaxAge, which are
+// failed as too old by a later job queue check. Jobs sending a fulfillment are left to finish
+func (o *OoORouterService) expireJobs(currentBlockNum uint64) {
+	for _, requestId := range o.workers.olderThan(currentBlockNum, RequestMaxAge) {
+		o.workers.cancel(requestId, "request too old")
+	}
+}
+
+func (w *jobWorkerPool) isInFlight(requestId string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.inFlight[requestId]
+	return ok
+}
+
+// cancel cancels the in-flight job's context, returning a channel closed once processing has
+// stopped, or nil if the job is not in flight
+func (w *jobWorkerPool) cancel(requestId string, reason string) <-chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	j, ok := w.inFlight[requestId]
+	if !ok {
+		return nil
+	}
+	if j.reason == "" {
+		j.reason = reason
+	}
+	j.cancel()
+
+	return j.done
+}
+
+// cancelReason returns why the in-flight job was cancelled, or "" if it was not
+func (w *jobWorkerPool) cancelReason(requestId string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if j, ok := w.inFlight[requestId]; ok {
+		return j.reason
+	}
+	return ""
+}
+
+// olderThan returns the in-flight data fetches for requests made more than maxAge blocks
+// before currentBlockNum
+func (w *jobWorkerPool) olderThan(currentBlockNum uint64, maxAge uint64) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var res []string
+	for requestId, j := range w.inFlight {
+		if j.fetching && j.requestBlock > 0 && currentBlockNum > j.requestBlock+maxAge {
+			res = append(res, requestId)
+		}
+	}
+	return res
+}
+
+// release removes the job once processing has stopped, waking anything waiting for it to be cancelled
+func (w *jobWorkerPool) release(requestId string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if j, ok := w.inFlight[requestId]; ok {
+		j.cancel()
+		close(j.done)
+		delete(w.inFlight, requestId)
+	}
+}
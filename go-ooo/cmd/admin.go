@@ -1,19 +1,13 @@
 package cmd
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"github.com/spf13/viper"
-	"go-ooo/config"
 	go_ooo_types "go-ooo/types"
-	"io/ioutil"
 	"net/http"
-	"strings"
-	"syscall"
+	"os"
 
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 )
 
 // adminCmd represents the admin command
@@ -31,50 +25,38 @@ func init() {
 
 func processAdminTask(adminTask go_ooo_types.AdminTask) {
 
-	fmt.Print("Enter your password:	")
-
-	bytePassword, err := term.ReadPassword(int(syscall.Stdin))
+	pass, err := readPassword()
 	if err != nil {
 		fmt.Println(err.Error())
 		return
 	}
 
-	pass := strings.TrimSpace(string(bytePassword))
+	if !machineOutput(false) {
+		fmt.Println("attempting to send task", adminTask.Task)
+		fmt.Println("")
+	}
 
-	fmt.Println("")
-	fmt.Println("attempting to send task", adminTask.Task)
-	fmt.Println("")
+	body, statusCode, err := sendApiRequest(pass, "POST", "/admin", adminTask)
 
-	requestJSON, err := json.Marshal(adminTask)
-	if err != nil {
-		fmt.Println("Can't marshal request")
+	if machineOutput(false) {
+		if err != nil || statusCode != 200 {
+			exitWithApiError(body, statusCode, err)
+		}
+		printJSON(body)
+		var decodedResponse go_ooo_types.AdminTaskResponse
+		if json.Unmarshal(body, &decodedResponse) == nil && !decodedResponse.Success {
+			os.Exit(1)
+		}
 		return
 	}
-	request := bytes.NewBuffer(requestJSON)
-	url := fmt.Sprintf("http://%s:%d", viper.GetString(config.ServeHost), viper.GetInt(config.ServePort))
-
-	req, err := http.NewRequest("POST", fmt.Sprint(url, "/admin"), request)
-
-	bearer := "Bearer " + pass
-	req.Header.Add("Authorization", bearer)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
 
 	if err != nil {
 		fmt.Println("Something went wrong.")
 		fmt.Println(err.Error())
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-
-	if err != nil {
-		fmt.Println(err.Error())
 		return
 	}
 
-	if resp.StatusCode == 200 {
+	if statusCode == 200 {
 
 		var decodedResponse go_ooo_types.AdminTaskResponse
 		err = json.Unmarshal(body, &decodedResponse)
@@ -91,7 +73,7 @@ func processAdminTask(adminTask go_ooo_types.AdminTask) {
 			fmt.Println("Error   :", decodedResponse.Error)
 		}
 	} else {
-		fmt.Println("Error   :", resp.Status)
+		fmt.Println("Error   :", http.StatusText(statusCode))
 		fmt.Println("Message :", string(body))
 	}
 
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/service"
+	"os"
+	"strings"
+	"time"
+)
+
+var adminTokenScope string
+var adminTokenJwt bool
+var adminTokenTtl time.Duration
+
+// adminTokenCmd represents the admin token command
+var adminTokenCmd = &cobra.Command{
+	Use:   "token [name]",
+	Short: "Create a scoped credential for the admin API",
+	Long: `Create a credential for the admin API with the read scope, which only allows GET
+requests, or the operator scope, which allows all requests, e.g. pause, requeue and withdraw.
+
+By default, a random API key is generated and printed as an admin_api.tokens entry to add to
+the config file. With --jwt, a JWT signed with admin_api.jwt_secret is printed instead, with
+name as its subject. JWTs need no config change, and expire after --ttl.
+
+Clients authenticate with an "Authorization: Bearer <key or JWT>" header.
+
+Examples:
+
+  go-ooo admin token grafana --scope=read
+  go-ooo admin token ci --scope=operator --jwt --ttl=1h
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		scope := strings.ToLower(adminTokenScope)
+		if scope != service.AdminScopeRead && scope != service.AdminScopeOperator {
+			fmt.Printf("scope must be %s or %s\n", service.AdminScopeRead, service.AdminScopeOperator)
+			os.Exit(1)
+		}
+
+		if adminTokenJwt {
+			secret := viper.GetString(config.AdminApiJwtSecret)
+			if secret == "" {
+				fmt.Println(config.AdminApiJwtSecret, "is not set")
+				os.Exit(1)
+			}
+
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"sub":   name,
+				"scope": scope,
+				"iat":   time.Now().Unix(),
+				"exp":   time.Now().Add(adminTokenTtl).Unix(),
+			}).SignedString([]byte(secret))
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+
+			fmt.Println(token)
+			return
+		}
+
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Printf("Add to %s, then restart the node:\n\n", viper.ConfigFileUsed())
+		fmt.Printf("[%s.%s]\n", config.AdminApiTokens, name)
+		fmt.Printf("key = %q\n", hex.EncodeToString(key))
+		fmt.Printf("scope = %q\n", scope)
+	},
+}
+
+func init() {
+	adminTokenCmd.Flags().StringVar(&adminTokenScope, "scope", service.AdminScopeRead, "read or operator")
+	adminTokenCmd.Flags().BoolVar(&adminTokenJwt, "jwt", false, "print a JWT signed with admin_api.jwt_secret, instead of an API key")
+	adminTokenCmd.Flags().DurationVar(&adminTokenTtl, "ttl", 24*time.Hour, "how long the JWT is valid for")
+	adminCmd.AddCommand(adminTokenCmd)
+}
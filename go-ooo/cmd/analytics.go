@@ -1,20 +1,15 @@
 package cmd
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-	"go-ooo/config"
+	"go-ooo/httpclient"
 	go_ooo_types "go-ooo/types"
-	"golang.org/x/term"
 	"io/ioutil"
 	"math/big"
 	"net/http"
-	"strings"
-	"syscall"
 )
 
 var (
@@ -112,79 +107,53 @@ func init() {
 }
 
 func processAnalyticsTask(task go_ooo_types.AnalyticsTask) {
-	fmt.Print("Enter your password:	")
-
-	bytePassword, err := term.ReadPassword(int(syscall.Stdin))
+	pass, err := readPassword()
 	if err != nil {
 		fmt.Println(err.Error())
 		return
 	}
 
-	pass := strings.TrimSpace(string(bytePassword))
-
-	fmt.Println("")
-	fmt.Println("attempting to send analytics task")
-	fmt.Println("")
-
-	requestJSON, err := json.Marshal(task)
-	if err != nil {
-		fmt.Println("Can't marshal request")
-		return
+	if !machineOutput(false) {
+		fmt.Println("attempting to send analytics task")
+		fmt.Println("")
 	}
-	request := bytes.NewBuffer(requestJSON)
-	url := fmt.Sprintf("http://%s:%d", viper.GetString(config.ServeHost), viper.GetInt(config.ServePort))
-
-	req, err := http.NewRequest("POST", fmt.Sprint(url, "/analytics"), request)
 
-	bearer := "Bearer " + pass
-	req.Header.Add("Authorization", bearer)
+	body, statusCode, err := sendApiRequest(pass, "POST", "/analytics", task)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-
-	if err != nil {
-		fmt.Println("Something went wrong.")
-		fmt.Println(err.Error())
+	if (err != nil || statusCode != 200) && machineOutput(false) {
+		exitWithApiError(body, statusCode, err)
 	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
 
 	if err != nil {
+		fmt.Println("Something went wrong.")
 		fmt.Println(err.Error())
 		return
 	}
 
-	if resp.StatusCode == 200 {
+	if statusCode == 200 {
 		var decodedResponse go_ooo_types.AnalyticsTaskResponse
 		err = json.Unmarshal(body, &decodedResponse)
 		if err != nil {
 			fmt.Println(err.Error())
 			return
 		}
-		var prettyJSON bytes.Buffer
-		err = json.Indent(&prettyJSON, body, "", "  ")
-		if err != nil {
-			fmt.Println("JSON parse error: ", err)
-			return
-		}
-		if decodedResponse.Task.SuggestFee {
+		if decodedResponse.Task.SuggestFee && !machineOutput(false) {
 			usableSuggestedFee := new(big.Float).Mul(big.NewFloat(decodedResponse.Result.SuggestedFee), big.NewFloat(params.GWei))
 			fee, _ := usableSuggestedFee.Uint64()
 			fmt.Println("Suggested fee              :", decodedResponse.Result.SuggestedFee, "xFUND")
 			fmt.Println("Fee in lowest denomination :", fee)
 			fmt.Println("Profit/Loss                :", fmt.Sprintf("%.18f", decodedResponse.Result.Earnings.ProfitLossEth), "ETH")
 		} else {
-			fmt.Println(string(prettyJSON.Bytes()))
+			printJSON(body)
 		}
 	} else {
-		fmt.Println("Error   :", resp.Status)
+		fmt.Println("Error   :", http.StatusText(statusCode))
 		fmt.Println("Body :", string(body))
 	}
 }
 
 func getXfundPrice() *go_ooo_types.CoinGeckoResponse {
-	client := &http.Client{}
+	client := httpclient.New(0)
 	cgUrl := "https://api.coingecko.com/api/v3/simple/price?ids=xfund&vs_currencies=eth%2Cusd"
 	req, err := http.NewRequest("GET", cgUrl, nil)
 	if err != nil {
@@ -197,9 +166,7 @@ func getXfundPrice() *go_ooo_types.CoinGeckoResponse {
 		return &go_ooo_types.CoinGeckoResponse{}
 	}
 
-	if res.Body != nil {
-		defer res.Body.Close()
-	}
+	defer httpclient.Close(res.Body)
 
 	body, readErr := ioutil.ReadAll(res.Body)
 	if readErr != nil {
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go-ooo/app"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	backtestFromBlock uint64
+	backtestToBlock   uint64
+)
+
+// backtestCmd represents the backtest command
+var backtestCmd = &cobra.Command{
+	Use:   "backtest",
+	Short: "Compare the current config's answers for historical requests with those submitted",
+	Long: `Find the requests made to the node between --from-block and --to-block, or the latest block,
+and answer each with the current data sources, pair source overrides and aggregation settings,
+using the sources' data as of the block each request was made in. Each answer is compared with
+the one the node submitted on chain.
+
+Price requests are answered with the exchange kline mean as of the request, and ad-hoc requests
+from subgraph snapshots at the request's block, i.e. as BASE.TARGET.PR.AT.<time> and
+BASE.TARGET.AD.AT.<time> requests. JSON feed requests have no history and are not answered.
+
+Nothing is stored or sent.
+
+Examples:
+
+  go-ooo backtest --from-block 12345678
+  go-ooo backtest --from-block 12345678 --to-block 12350000 --pass=/path/to/pass.txt
+`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(viper.ConfigFileUsed()); errors.Is(err, os.ErrNotExist) {
+			fmt.Println(viper.ConfigFileUsed(), "does not exist. please run 'go-ooo init'")
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if backtestToBlock != 0 && backtestToBlock < backtestFromBlock {
+			fmt.Println("--to-block must not be before --from-block")
+			os.Exit(1)
+		}
+
+		server, err := app.NewServer(keystorePass)
+		if err != nil {
+			panic(err)
+		}
+
+		summary, err := server.Backtest(backtestFromBlock, backtestToBlock)
+		if err != nil {
+			fmt.Println("backtest failed:", err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Println("")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "REQUEST ID\tENDPOINT\tBLOCK\tTIME\tANSWER\tSUBMITTED\tDEVIATION\tSOURCES")
+		for _, r := range summary.Results {
+			answer := r.Answer
+			if r.Error != "" {
+				answer = "error: " + r.Error
+			}
+			submitted := r.Submitted
+			deviation := ""
+			if submitted == "" {
+				submitted = "not fulfilled"
+			} else if r.Answer != "" {
+				deviation = fmt.Sprintf("%.4f%%", r.DeviationPct)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n", r.RequestId, r.Endpoint, r.Block,
+				time.Unix(int64(r.Timestamp), 0).UTC().Format(time.RFC3339), answer, submitted, deviation,
+				strings.Join(r.Sources, ","))
+		}
+		_ = w.Flush()
+
+		fmt.Println("")
+		fmt.Println("Requests       :", summary.Requests)
+		fmt.Println("Answered       :", summary.Answered)
+		fmt.Println("Compared       :", summary.Compared)
+		fmt.Printf("Mean deviation : %.4f%%\n", summary.MeanDeviationPct)
+		fmt.Printf("Max deviation  : %.4f%%\n", summary.MaxDeviationPct)
+	},
+}
+
+func init() {
+	backtestCmd.Flags().Uint64Var(&backtestFromBlock, "from-block", 0, "block to backtest requests from")
+	backtestCmd.Flags().Uint64Var(&backtestToBlock, "to-block", 0, "block to backtest requests to. Defaults to the latest block")
+	backtestCmd.Flags().StringVar(&keystorePass, "pass", "", "keystore password or password file location")
+	_ = backtestCmd.MarkFlagRequired("from-block")
+	rootCmd.AddCommand(backtestCmd)
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go-ooo/app"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	benchPairs    []string
+	benchRounds   int
+	benchInterval time.Duration
+	benchJson     bool
+)
+
+// benchCmd represents the bench command
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Run a sub-command",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("run one of the sub-commands. See 'go-ooo bench --help'")
+	},
+}
+
+// benchSourcesCmd represents the bench sources command
+var benchSourcesCmd = &cobra.Command{
+	Use:   "sources",
+	Short: "Benchmark the latency, reliability and prices of every data source",
+	Long: `Fetch each pair from every data source - the Finchains endpoints, DEX subgraphs, exchange
+kline APIs and json feeds - --rounds times, and report each source's p50 and p95 latency, error
+rate and price dispersion, to help choose which sources to enable for a pair.
+
+Dispersion is the mean deviation of a source's prices from the median of all sources' prices
+for the same pair and round. Pair source overrides are ignored, so that disabled sources can be
+compared too. DEX sources use the pairs discovered by the node, from its database.
+
+Nothing is stored or submitted, and the service does not need to be running.
+
+Examples:
+
+  go-ooo bench sources
+  go-ooo bench sources --pairs BTC.USD,ETH.USD,XFUND.ETH --rounds 10 --interval 5s
+  go-ooo bench sources --pairs ETH-GBP --json
+`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(viper.ConfigFileUsed()); errors.Is(err, os.ErrNotExist) {
+			fmt.Println(viper.ConfigFileUsed(), "does not exist. please run 'go-ooo init'")
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if benchRounds < 1 {
+			fmt.Println("--rounds must be at least 1")
+			os.Exit(1)
+		}
+
+		server, err := app.NewServer("")
+		if err != nil {
+			panic(err)
+		}
+
+		progress := func(pair string, round int) {
+			if !machineOutput(benchJson) {
+				fmt.Printf("round %d/%d: %s\n", round+1, benchRounds, pair)
+			}
+		}
+
+		summaries, err := server.BenchSources(benchPairs, benchRounds, benchInterval, progress)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		if machineOutput(benchJson) {
+			body, _ := json.Marshal(summaries)
+			printJSON(body)
+			return
+		}
+
+		fmt.Println("")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SOURCE\tKIND\tSAMPLES\tERROR RATE\tP50\tP95\tDISPERSION\tLAST ERROR")
+		for _, s := range summaries {
+			dispersion := "-"
+			if s.Errors < s.Samples {
+				dispersion = fmt.Sprintf("%.3f%%", s.DispersionPct)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%.0f%%\t%s\t%s\t%s\t%s\n", s.Source, s.Kind, s.Samples,
+				float64(s.Errors)/float64(s.Samples)*100, s.P50.Round(time.Millisecond), s.P95.Round(time.Millisecond),
+				dispersion, s.LastError)
+		}
+		_ = w.Flush()
+	},
+}
+
+func init() {
+	benchSourcesCmd.Flags().StringSliceVar(&benchPairs, "pairs", []string{"BTC.USD", "ETH.USD"}, "pairs to fetch, e.g. BTC.USD,XFUND.ETH")
+	benchSourcesCmd.Flags().IntVar(&benchRounds, "rounds", 5, "number of times to fetch each pair from each source")
+	benchSourcesCmd.Flags().DurationVar(&benchInterval, "interval", 2*time.Second, "time to wait between rounds")
+	benchSourcesCmd.Flags().BoolVar(&benchJson, "json", false, "output raw JSON")
+
+	benchCmd.AddCommand(benchSourcesCmd)
+	rootCmd.AddCommand(benchCmd)
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/viper"
+	"go-ooo/app"
+	"go-ooo/config"
+	go_ooo_types "go-ooo/types"
+	"golang.org/x/term"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+	"syscall"
+)
+
+// readPassword prompts for the keystore decryption & admin password
+func readPassword() (string, error) {
+	// prompts are written to stderr, so that the output can be piped
+	fmt.Fprint(os.Stderr, "Enter your password:	")
+
+	bytePassword, err := term.ReadPassword(int(syscall.Stdin))
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprintln(os.Stderr, "")
+
+	return strings.TrimSpace(string(bytePassword)), nil
+}
+
+// keystorePassOrPrompt returns the --pass value, prompting for the keystore password if neither
+// it nor any other password source is configured
+func keystorePassOrPrompt() (string, error) {
+	if keystorePass != "" || app.HasKeystorePasswordSource() {
+		return keystorePass, nil
+	}
+	return readPassword()
+}
+
+// confirm asks the user to confirm an action, returning true if they answer y or yes
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+
+	var answer string
+	_, _ = fmt.Scanln(&answer)
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes"
+}
+
+// apiV1 - the path the node serves its admin API routes at, on the service port as on the admin API
+const apiV1 = "/api/v1"
+
+// newApiRequest returns an authenticated request to the running go-ooo service
+func newApiRequest(pass string, method string, path string, body io.Reader) (*http.Request, error) {
+	url := fmt.Sprintf("http://%s:%d", viper.GetString(config.ServeHost), viper.GetInt(config.ServePort))
+
+	req, err := http.NewRequest(method, fmt.Sprint(url, path), body)
+	if err != nil {
+		return nil, err
+	}
+
+	bearer := "Bearer " + pass
+	req.Header.Add("Authorization", bearer)
+	req.Header.Add(go_ooo_types.AuditActorHeader, auditActor())
+
+	return req, nil
+}
+
+// sendApiRequest sends an authenticated request to the running go-ooo service. If payload
+// is not nil, it is sent as the JSON request body. The response body and status code are returned.
+func sendApiRequest(pass string, method string, path string, payload interface{}) ([]byte, int, error) {
+	var reqBody io.Reader
+
+	if payload != nil {
+		requestJSON, err := json.Marshal(payload)
+		if err != nil {
+			return nil, 0, fmt.Errorf("can't marshal request: %s", err.Error())
+		}
+		reqBody = bytes.NewBuffer(requestJSON)
+	}
+
+	req, err := newApiRequest(pass, method, path, reqBody)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if payload != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+
+	return body, resp.StatusCode, err
+}
+
+// auditActor identifies the person running the CLI, as user@host, for the node's audit log
+func auditActor() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		return name
+	}
+
+	return fmt.Sprintf("%s@%s", name, host)
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go-ooo/app"
+	"go-ooo/config"
+	"os"
+	"time"
+)
+
+// configChainIdTimeout - time allowed for cross-checking the RPCs' chain IDs
+const configChainIdTimeout = 15 * time.Second
+
+var configValidateOffline bool
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Config file tools",
+}
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config file for errors",
+	Long: `Parse the config file, including any environment variable overrides, and check that
+required keys are set, and that addresses, urls and values such as signer.backend and
+log.format are valid. Unless --offline is given, each RPC is then connected to, to check
+that chain.eth_ws_host and chain.eth_http_host are on chain.network_id, and that the
+subchain RPCs are on the chains they serve.
+
+Each error is printed with the key it relates to. Exits with status 1 if there are any.
+
+Examples:
+
+  go-ooo config validate
+  go-ooo config validate --offline
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(viper.ConfigFileUsed()); errors.Is(err, os.ErrNotExist) {
+			fmt.Println(viper.ConfigFileUsed(), "does not exist. please run 'go-ooo init'")
+			os.Exit(1)
+		}
+		if err := config.ReadInConfig(viper.GetViper(), viper.GetString(config.Profile)); err != nil {
+			fmt.Printf("cannot parse %s: %s\n", viper.ConfigFileUsed(), err.Error())
+			os.Exit(1)
+		}
+
+		problems := app.ValidateConfig()
+		if len(problems) == 0 && !configValidateOffline {
+			ctx, cancel := context.WithTimeout(context.Background(), configChainIdTimeout)
+			problems = app.CheckChainIds(ctx)
+			cancel()
+		}
+
+		for _, p := range problems {
+			fmt.Println(p.Error())
+		}
+		if len(problems) > 0 {
+			fmt.Printf("\n%s: %d error(s)\n", viper.ConfigFileUsed(), len(problems))
+			os.Exit(1)
+		}
+		fmt.Printf("%s is valid\n", viper.ConfigFileUsed())
+	},
+}
+
+func init() {
+	configValidateCmd.Flags().BoolVar(&configValidateOffline, "offline", false, "don't connect to the RPCs to check their chain IDs")
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
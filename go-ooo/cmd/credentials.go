@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"golang.org/x/term"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	credentialsFlagRef         string
+	credentialsFlagValueFile   string
+	credentialsFlagRotateAfter uint64
+	credentialsFlagDailyQuota  uint64
+	credentialsFlagJson        bool
+)
+
+// credentialsCmd represents the credentials command
+var credentialsCmd = &cobra.Command{
+	Use:   "credentials",
+	Short: "Manage the API keys used by data source adapters",
+	Long: `Manage the API keys used by data source adapters, e.g. JSON feeds and the FX rates API, instead of
+writing them into the config file. Adapter config references a credential by name, for example
+
+  [[jobs.json_feeds]]
+  url = "https://api.example.com/rates/{base}?apikey={credential:example}"
+
+A credential's value is stored in the database, encrypted if database.encryption_key is set, or
+read on each use from an external reference - env:NAME, file:PATH or vault:FIELD. Each use is
+counted against an optional daily quota.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("run one of the sub-commands. See 'go-ooo credentials --help'")
+	},
+}
+
+// credentialsListCmd represents the credentials list command
+var credentialsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the adapter credentials, with their rotation and usage",
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", apiV1+"/credentials", nil)
+		if err != nil || statusCode != 200 || machineOutput(credentialsFlagJson) {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		var list []go_ooo_types.AdapterCredential
+		if err = json.Unmarshal(body, &list); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if len(list) == 0 {
+			fmt.Println("no adapter credentials are set")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSOURCE\tVERSION\tROTATED\tUSED TODAY\tTOTAL USES\tLAST USED\tSTATUS")
+		for _, c := range list {
+			source := "stored"
+			if c.Ref != "" {
+				source = c.Ref
+			}
+			used := fmt.Sprintf("%d", c.UsedToday)
+			if c.DailyQuota > 0 {
+				used = fmt.Sprintf("%d/%d", c.UsedToday, c.DailyQuota)
+			}
+			lastUsed := "never"
+			if c.LastUsedAt > 0 {
+				lastUsed = time.Unix(c.LastUsedAt, 0).UTC().Format(time.RFC3339)
+			}
+			var status []string
+			if c.RotationDue {
+				status = append(status, "rotation due")
+			}
+			if c.Error != "" {
+				status = append(status, c.Error)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%d\t%s\t%s\n", c.Name, source, c.Version,
+				time.Unix(c.RotatedAt, 0).UTC().Format(time.RFC3339), used, c.TotalUses, lastUsed, strings.Join(status, "; "))
+		}
+		_ = w.Flush()
+	},
+}
+
+// credentialsSetCmd represents the credentials set command
+var credentialsSetCmd = &cobra.Command{
+	Use:   "set [NAME]",
+	Short: "Set or rotate an adapter credential",
+	Long: `Set an adapter credential, replacing any already set. Changing the value or reference rotates
+the credential, incrementing its version. The value is prompted for, or read from --value-file,
+so that it is not kept in the shell history.
+
+Examples:
+
+  go-ooo credentials set ecbfx --daily-quota 1000 --rotate-after 90
+  go-ooo credentials set oxr --ref env:OXR_APP_ID
+  go-ooo credentials set cmc --ref vault:cmc_api_key
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		request := go_ooo_types.AdapterCredentialRequest{
+			Ref:             credentialsFlagRef,
+			RotateAfterDays: credentialsFlagRotateAfter,
+			DailyQuota:      credentialsFlagDailyQuota,
+		}
+
+		if request.Ref == "" {
+			value, err := readCredentialValue()
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+			request.Value = value
+		}
+
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "PUT", apiV1+"/credentials/"+url.PathEscape(args[0]), request)
+		printJobsResponse(body, statusCode, err)
+	},
+}
+
+// credentialsDeleteCmd represents the credentials delete command
+var credentialsDeleteCmd = &cobra.Command{
+	Use:   "delete [NAME]",
+	Short: "Delete an adapter credential",
+	Long: `Delete an adapter credential. Adapters referencing it fail until it is set again.
+
+Example:
+
+  go-ooo credentials delete ecbfx
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendJobsRequest("DELETE", apiV1+"/credentials/"+url.PathEscape(args[0]))
+	},
+}
+
+// readCredentialValue reads the credential's value from --value-file, or prompts for it
+func readCredentialValue() (string, error) {
+	if credentialsFlagValueFile != "" {
+		b, err := ioutil.ReadFile(credentialsFlagValueFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Enter the credential's value:	")
+	b, err := term.ReadPassword(int(syscall.Stdin))
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintln(os.Stderr, "")
+
+	value := strings.TrimSpace(string(b))
+	if value == "" {
+		return "", fmt.Errorf("no value given")
+	}
+	return value, nil
+}
+
+func init() {
+	credentialsListCmd.Flags().BoolVar(&credentialsFlagJson, "json", false, "output raw JSON")
+	credentialsSetCmd.Flags().StringVar(&credentialsFlagRef, "ref", "", "external reference to read the value from on each use: env:NAME, file:PATH or vault:FIELD")
+	credentialsSetCmd.Flags().StringVar(&credentialsFlagValueFile, "value-file", "", "file to read the value from, instead of prompting for it")
+	credentialsSetCmd.Flags().Uint64Var(&credentialsFlagRotateAfter, "rotate-after", 0, "days after which rotation is alerted. 0 disables")
+	credentialsSetCmd.Flags().Uint64Var(&credentialsFlagDailyQuota, "daily-quota", 0, "maximum uses per UTC day. 0 is unlimited")
+
+	credentialsCmd.AddCommand(credentialsListCmd)
+	credentialsCmd.AddCommand(credentialsSetCmd)
+	credentialsCmd.AddCommand(credentialsDeleteCmd)
+
+	rootCmd.AddCommand(credentialsCmd)
+}
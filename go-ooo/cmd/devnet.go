@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/spf13/cobra"
+	"go-ooo/app"
+	"go-ooo/config"
+	"go-ooo/devnet"
+	"math/big"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var devnetCfg devnet.Config
+var devnetPricePort int
+var devnetPrices map[string]string
+var devnetFee uint64
+var devnetProviderKey string
+var devnetProvider string
+var devnetCount int
+var devnetEndpoint string
+var devnetTimeout time.Duration
+
+// devnetCmd represents the devnet command
+var devnetCmd = &cobra.Command{
+	Use:   "devnet",
+	Short: "Run a local chain for testing the node end to end",
+	Long: `Start an in-process dev chain, or use an external one such as anvil with --rpc, and deploy
+a mock xFUND token, the Router and a mock consumer. The node's provider key, read from the
+keystore or given with --provider-key, is funded and registered on the Router with --fee.
+
+Unless --price-port is 0, a stub of the Finchains API is also served, with the --price
+values, so that the node can fulfil price requests offline.
+
+The environment variables pointing the node at the devnet are printed. The faucet deploying
+the contracts defaults to anvil's first account, so the contract addresses are the same on
+every fresh devnet, and the node only needs configuring once.
+
+The devnet runs until interrupted. Send requests and check they are fulfilled with
+'go-ooo devnet request'.
+
+Examples:
+
+  go-ooo devnet
+  go-ooo devnet --block-time=2 --price BTC.USD=50000 --price ETH.USD=3000
+  go-ooo devnet --rpc=ws://127.0.0.1:8545
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		providerKey := devnetProviderKey
+		if providerKey == "" {
+			providerKey = devnetReadProviderKey()
+		}
+
+		d, err := devnet.Start(ctx, devnetCfg)
+		devnetExitOnError(err)
+		defer d.Close()
+
+		// fund the consumer with enough for 10000 requests
+		tokens := new(big.Int).Mul(new(big.Int).SetUint64(devnetFee), big.NewInt(10000))
+		devnetExitOnError(d.Deploy(ctx, tokens))
+
+		provider, err := d.RegisterProvider(ctx, providerKey, big.NewInt(params.Ether), new(big.Int).SetUint64(devnetFee))
+		devnetExitOnError(err)
+
+		env := map[string]string{
+			config.ChainEthWsHost:       d.WsUrl,
+			config.ChainEthHttpHost:     d.HttpUrl,
+			config.ChainContractAddress: d.Router.Hex(),
+			config.ChainNetworkId:       d.ChainId.String(),
+		}
+		// the subchain RPCs are only queried for ad-hoc requests, but must be set
+		for _, k := range []string{config.SubChainEthHttpRpc, config.SubChainPolygonHttpRpc, config.SubChainBcsHttpRpc, config.SubChainXdaiHttpRpc} {
+			env[k] = d.HttpUrl
+		}
+
+		if devnetPricePort > 0 {
+			prices, err := devnet.ServePrices(fmt.Sprintf("%s:%d", devnetCfg.Host, devnetPricePort))
+			devnetExitOnError(err)
+			defer prices.Close()
+
+			for pair, price := range devnetPrices {
+				devnetExitOnError(prices.SetPrice(pair, price))
+			}
+			env[config.JobsOooApiUrl] = prices.Url
+		}
+
+		fmt.Println("chain id :", d.ChainId.String())
+		fmt.Println("ws       :", d.WsUrl)
+		fmt.Println("http     :", d.HttpUrl)
+		fmt.Println("token    :", d.Token.Hex())
+		fmt.Println("router   :", d.Router.Hex())
+		fmt.Println("consumer :", d.Consumer.Hex())
+		fmt.Println("provider :", provider.Hex())
+		fmt.Println("")
+		fmt.Println("start the node with:")
+		fmt.Println("")
+
+		keys := make([]string, 0, len(env))
+		for k := range env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("  export %s=%s\n", config.EnvVar(k), env[k])
+		}
+
+		fmt.Println("")
+		fmt.Println("devnet running - press Ctrl-C to stop")
+
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+		<-c
+	},
+}
+
+// devnetRequestCmd represents the devnet request command
+var devnetRequestCmd = &cobra.Command{
+	Use:   "request",
+	Short: "Send requests on a running devnet and check they are fulfilled",
+	Long: `Send requests from the mock consumer on a devnet started with 'go-ooo devnet' to the node's
+provider address, read from the keystore or given with --provider, and wait for each to be
+fulfilled. Exits with status 1 if any are not fulfilled within --timeout.
+
+Examples:
+
+  go-ooo devnet request
+  go-ooo devnet request --count=10 --endpoint=ETH.USD.PR.AVC --timeout=5m
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := context.WithTimeout(context.Background(), devnetTimeout)
+		defer cancel()
+
+		provider := common.HexToAddress(devnetProvider)
+		if devnetProvider == "" {
+			key, err := crypto.HexToECDSA(strings.TrimPrefix(devnetReadProviderKey(), "0x"))
+			devnetExitOnError(err)
+			provider = crypto.PubkeyToAddress(key.PublicKey)
+		} else if !common.IsHexAddress(devnetProvider) {
+			devnetExitOnError(fmt.Errorf("%s is not a valid address", devnetProvider))
+		}
+
+		rpc := devnetCfg.RpcUrl
+		if rpc == "" {
+			rpc = fmt.Sprintf("ws://%s:%d", devnetCfg.Host, devnetCfg.WsPort)
+		}
+		d, err := devnet.Start(ctx, devnet.Config{RpcUrl: rpc, FaucetKey: devnetCfg.FaucetKey})
+		devnetExitOnError(err)
+		defer d.Close()
+		devnetExitOnError(d.Attach(ctx))
+
+		requestIds := make([]common.Hash, 0, devnetCount)
+		for i := 0; i < devnetCount; i++ {
+			requestId, err := d.Request(ctx, provider, new(big.Int).SetUint64(devnetFee), devnetEndpoint)
+			devnetExitOnError(err)
+			fmt.Println("requested", requestId.Hex())
+			requestIds = append(requestIds, requestId)
+		}
+
+		failed := 0
+		for _, requestId := range requestIds {
+			start := time.Now()
+			value, err := d.WaitFulfilled(ctx, requestId)
+			if err != nil {
+				fmt.Println(err.Error())
+				failed++
+				continue
+			}
+			fmt.Printf("fulfilled %s: %s (%s)\n", requestId.Hex(), value.String(), time.Since(start).Round(time.Millisecond))
+		}
+
+		fmt.Printf("\n%d of %d requests fulfilled\n", len(requestIds)-failed, len(requestIds))
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// devnetReadProviderKey reads the node's provider key from the keystore
+func devnetReadProviderKey() string {
+	pass, err := keystorePassOrPrompt()
+	devnetExitOnError(err)
+
+	server, err := app.NewServer(pass)
+	devnetExitOnError(err)
+
+	key, err := server.ProviderKey()
+	devnetExitOnError(err)
+
+	return key
+}
+
+func devnetExitOnError(err error) {
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
+
+func init() {
+	devnetCmd.PersistentFlags().StringVar(&devnetCfg.RpcUrl, "rpc", "", "external dev chain to use, e.g. anvil, instead of an in-process chain")
+	devnetCmd.PersistentFlags().StringVar(&devnetCfg.FaucetKey, "faucet-key", "", "funded private key deploying and paying for requests. Defaults to anvil's first account")
+	devnetCmd.PersistentFlags().StringVar(&devnetCfg.Host, "host", "127.0.0.1", "host the in-process chain and price stub listen on")
+	devnetCmd.PersistentFlags().IntVar(&devnetCfg.WsPort, "ws-port", 8546, "in-process chain's websocket port")
+	devnetCmd.PersistentFlags().Uint64Var(&devnetFee, "fee", 100000, "provider fee, in xFUND * 10^9")
+	devnetCmd.PersistentFlags().StringVar(&keystorePass, "pass", "", "keystore password or password file location")
+
+	devnetCmd.Flags().IntVar(&devnetCfg.HttpPort, "http-port", 8545, "in-process chain's http port")
+	devnetCmd.Flags().Uint64Var(&devnetCfg.BlockTime, "block-time", 1, "in-process chain's block time in seconds. 0 mines a block only for each tx, so the node's confirmations wait for later txs")
+	devnetCmd.Flags().IntVar(&devnetPricePort, "price-port", 8548, "port to serve the stub price API on. 0 disables it")
+	devnetCmd.Flags().StringToStringVar(&devnetPrices, "price", map[string]string{"BTC.USD": "50000", "ETH.USD": "3000"}, "price served by the stub price API for a pair")
+	devnetCmd.Flags().StringVar(&devnetProviderKey, "provider-key", "", "provider private key to register, instead of the keystore's")
+
+	devnetRequestCmd.Flags().StringVar(&devnetProvider, "provider", "", "provider address to send requests to, instead of the keystore's")
+	devnetRequestCmd.Flags().IntVar(&devnetCount, "count", 1, "number of requests to send")
+	devnetRequestCmd.Flags().StringVar(&devnetEndpoint, "endpoint", "BTC.USD.PR.AVC", "endpoint to request")
+	devnetRequestCmd.Flags().DurationVar(&devnetTimeout, "timeout", 2*time.Minute, "time allowed for all requests to be fulfilled")
+
+	devnetCmd.AddCommand(devnetRequestCmd)
+	rootCmd.AddCommand(devnetCmd)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go-ooo/app"
+	"os"
+	"text/tabwriter"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the node is ready to start",
+	Long: `Run startup diagnostics without starting the service: config validity, database
+connectivity and schema version, RPC reachability and chain ID, shared cache and subgraph
+health, whether the keystore can be unlocked, and the oracle wallet's balances.
+
+Exits with status 1 if any check fails.
+
+Examples:
+
+  go-ooo doctor
+  go-ooo doctor --pass=/path/to/pass.txt
+`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(viper.ConfigFileUsed()); errors.Is(err, os.ErrNotExist) {
+			fmt.Println(viper.ConfigFileUsed(), "does not exist. please run 'go-ooo init'")
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := keystorePassOrPrompt()
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		server, err := app.NewServer(pass)
+		if err != nil {
+			panic(err)
+		}
+
+		failed := 0
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, c := range server.Doctor() {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", c.Status, c.Name, c.Detail)
+			if c.Status == app.DoctorFail {
+				failed++
+			}
+		}
+		_ = w.Flush()
+
+		fmt.Println("")
+		if failed > 0 {
+			fmt.Printf("%d check(s) failed\n", failed)
+			os.Exit(1)
+		}
+		fmt.Println("all checks passed")
+	},
+}
+
+func init() {
+	doctorCmd.Flags().StringVar(&keystorePass, "pass", "", "keystore password or password file location")
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// envCmd represents the env command
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "List the environment variables which override config keys",
+	Long: `List the environment variable which overrides each key in the config file, and whether
+it is set. Any config key can be overridden by prefixing it with ` + config.EnvPrefix + `_, upper
+casing it and replacing dots with underscores, e.g. chain.eth_ws_host is overridden by
+` + config.EnvVar(config.ChainEthWsHost) + `. Keys which are not in the config file can be set
+this way too.
+
+Lists, e.g. webhooks.urls, are space separated. Tables, e.g. alerts.routes, are JSON objects
+which replace the whole table, and arrays of tables, jobs.json_feeds, push_feeds.feeds and consensus.peers, are
+JSON arrays of objects:
+
+  ` + config.EnvVar(config.AlertsRoutes) + `='{"rpc_down": ["pagerduty"]}'
+  ` + config.EnvVar(config.JobsJsonFeeds) + `='[{"name": "ECBFX", "url": "...", "path": "rates.{target}"}]'
+
+Overrides are not written to the config file by commands which update it, e.g.
+'go-ooo keys rotate'.
+
+Examples:
+
+  go-ooo env
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// entries of tables are listed as the table, which is overridden as a whole
+		seen := make(map[string]bool)
+		var keys []string
+		for _, k := range viper.AllKeys() {
+			for _, t := range config.TableKeys {
+				if strings.HasPrefix(k, t+".") {
+					k = t
+				}
+			}
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tENVIRONMENT VARIABLE\tSET")
+		for _, k := range keys {
+			_, set := os.LookupEnv(config.EnvVar(k))
+			fmt.Fprintf(w, "%s\t%s\t%v\n", k, config.EnvVar(k), set)
+		}
+		_ = w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+}
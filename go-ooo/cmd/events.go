@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+var (
+	eventsFlagName  string
+	eventsFlagLimit int
+	eventsFlagJson  bool
+)
+
+// eventsCmd represents the events command
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "List the events recorded for the chain.event_filters",
+	Long: `List the most recent events recorded for the additional contract events configured in
+chain.event_filters, most recent first, with their decoded arguments.
+
+Examples:
+
+  go-ooo events
+  go-ooo events --name PriceConsumed --limit 10
+  go-ooo events -o json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		params := url.Values{}
+		params.Set("limit", strconv.Itoa(eventsFlagLimit))
+		if eventsFlagName != "" {
+			params.Set("name", eventsFlagName)
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", apiV1+"/chain_events?"+params.Encode(), nil)
+		if err != nil || statusCode != 200 || machineOutput(eventsFlagJson) {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		var events []go_ooo_types.ChainEvent
+		if err = json.Unmarshal(body, &events); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if len(events) == 0 {
+			fmt.Println("no events recorded")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "BLOCK\tNAME\tADDRESS\tTX\tARGS")
+		for _, e := range events {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", e.BlockNumber, e.Name, e.Address, e.TxHash, formatEventArgs(e.Args))
+		}
+		_ = w.Flush()
+	},
+}
+
+// formatEventArgs formats decoded event arguments as name=value pairs, sorted by name
+func formatEventArgs(args map[string]interface{}) string {
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		value, ok := args[name].(string)
+		if !ok {
+			b, _ := json.Marshal(args[name])
+			value = string(b)
+		}
+		pairs = append(pairs, name+"="+value)
+	}
+	return strings.Join(pairs, " ")
+}
+
+func init() {
+	eventsCmd.Flags().StringVar(&eventsFlagName, "name", "", "only list the events of this filter")
+	eventsCmd.Flags().IntVar(&eventsFlagLimit, "limit", 50, "number of events to list")
+	eventsCmd.Flags().BoolVar(&eventsFlagJson, "json", false, "output raw JSON")
+
+	rootCmd.AddCommand(eventsCmd)
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	gasFrom     string
+	gasTo       string
+	gasInterval time.Duration
+)
+
+// gasCmd represents the gas command
+var gasCmd = &cobra.Command{
+	Use:   "gas",
+	Short: "Show the fee market seen by fulfillment txs over time",
+	Long: `Show the base fee of the blocks fulfillment txs were mined in, and the priority fee
+paid above it, in gwei, for each --interval between --from and --to, along with the number
+of fulfillments and failed txs, and the mean blocks from request to fulfillment. Useful for
+correlating failed or slow fulfillments with fee market conditions.
+
+Dates are YYYY-MM-DD or RFC3339. --from defaults to 30 days before --to, which defaults
+to now. Intervals with no fulfillment txs are left out.
+
+Examples:
+
+  go-ooo admin gas
+  go-ooo admin gas --from 2022-01-01 --to 2022-01-02 --interval 10m
+  go-ooo admin gas --interval 24h --output json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		params := url.Values{}
+		params.Set("interval", strconv.FormatInt(int64(gasInterval.Seconds()), 10))
+
+		for name, value := range map[string]string{"from": gasFrom, "to": gasTo} {
+			if value == "" {
+				continue
+			}
+			t, err := parseReportDate(value)
+			if err != nil {
+				fmt.Printf("invalid --%s: %s\n", name, err.Error())
+				return
+			}
+			params.Set(name, strconv.FormatInt(t.Unix(), 10))
+		}
+
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", fmt.Sprintf(apiV1+"/gas?%s", params.Encode()), nil)
+		if err != nil || statusCode != 200 {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		if machineOutput(false) {
+			printJSON(body)
+			return
+		}
+
+		var series go_ooo_types.GasSeries
+		if err = json.Unmarshal(body, &series); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if len(series.Points) == 0 {
+			fmt.Println("no fulfillment txs in period")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "START\tFULFILLED\tFAILED\tBASE FEE (MIN/MED/MAX)\tPRIORITY FEE (MIN/MED/MAX)\tMEAN BLOCKS")
+		for _, p := range series.Points {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%.1f\n", time.Unix(p.Start, 0).UTC().Format(time.RFC3339),
+				p.Fulfilled, p.Failed, formatGasFeeStats(p.BaseFee), formatGasFeeStats(p.PriorityFee), p.MeanBlocks)
+		}
+		_ = w.Flush()
+	},
+}
+
+func formatGasFeeStats(s go_ooo_types.GasFeeStats) string {
+	return fmt.Sprintf("%.2f/%.2f/%.2f", s.Min, s.Median, s.Max)
+}
+
+func init() {
+	gasCmd.Flags().StringVar(&gasFrom, "from", "", "show fulfillment txs from this date")
+	gasCmd.Flags().StringVar(&gasTo, "to", "", "show fulfillment txs up to this date")
+	gasCmd.Flags().DurationVar(&gasInterval, "interval", time.Hour, "length of each interval, at least 1m")
+	adminCmd.AddCommand(gasCmd)
+}
@@ -6,10 +6,14 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/spf13/viper"
 	"go-ooo/config"
+	"go-ooo/database"
 	"go-ooo/keystore"
+	"go-ooo/signer"
 	"go-ooo/utils"
 	"go-ooo/utils/walletworker"
+	"go-ooo/version"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -20,14 +24,19 @@ var initCmd = &cobra.Command{
 	Short: "Initialise your OoO service",
 	Long: `Initialise your OoO service with some default configuration values.
 
-The command will initialise the config.toml file according to the given network option, and
+The command will initialise the config file according to the given network option, and
 also your keystore. You can generate a new key, or import an existing private key hex string,
 and will be prompted to do so during the process.
 
 By default, the environment will use $HOME/.go-ooo to store the app's configuration. You can
 specify where to store these files with the --home flag.
 
-Once initialised, you must edit the config.toml file with the appropriate values for your
+For a guided setup which checks each setting as it goes, run 'go-ooo setup' instead.
+
+The config file is config.toml, or config.yaml or config.json with --format. Existing config
+files in any of these formats are detected from their extension.
+
+Once initialised, you must edit the config file with the appropriate values for your
 database configuration, Eth RPC URLs etc.
 
 Current network options are:
@@ -40,6 +49,7 @@ Examples:
 
   go-ooo init rinkeby
   go-ooo init dev --home=/path/to/go-ooo-home
+  go-ooo init mainnet --format=yaml
 
 `,
 	Args: cobra.ExactArgs(1),
@@ -50,6 +60,12 @@ Examples:
 		fmt.Println("cfg", viper.ConfigFileUsed())
 
 		if _, err := os.Stat(viper.ConfigFileUsed()); errors.Is(err, os.ErrNotExist) {
+			if err := config.CheckFormat(initFormat); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+			viper.SetConfigFile(config.FilePath(appHomePath, strings.ToLower(initFormat)))
+
 			fmt.Println(viper.ConfigFileUsed(), "does not exist. Creating with defaults")
 
 			if _, err := os.Stat(appHomePath); errors.Is(err, os.ErrNotExist) {
@@ -59,24 +75,6 @@ Examples:
 				}
 			}
 
-			switch network {
-			case "rinkeby":
-				initForRinkeby()
-				break
-			case "mainnet":
-				initForMainnet()
-				break
-			case "polygon":
-				initForPolygon()
-				break
-			case "dev":
-				initForDevnet()
-				break
-			default:
-				initForDevnet()
-				break
-			}
-
 			ks, _ := keystore.NewKeyStorageNoLogger(keyStorePath)
 
 			err, ksUser := initNewKeystore(ks)
@@ -84,32 +82,7 @@ Examples:
 				panic(err)
 			}
 
-			viper.SetDefault(config.JobsOooApiUrl, "https://crypto.finchains.io/api")
-			viper.SetDefault(config.ServeHost, "127.0.0.1")
-			viper.SetDefault(config.ServePort, "8445")
-			viper.SetDefault(config.KeystorageFile, keyStorePath)
-			viper.SetDefault(config.KeystorageAccount, ksUser)
-			viper.SetDefault(config.ChainGasLimit, 500000)
-			viper.SetDefault(config.ChainMaxGasPrice, 150)
-			viper.SetDefault(config.JobsCheckDuration, 5)
-			viper.SetDefault(config.JobsWaitConfirmations, 2)
-
-			viper.SetDefault(config.DatabaseDialect, "sqlite")
-			viper.SetDefault(config.DatabaseStorage, dbPath)
-			viper.SetDefault(config.DatabaseHost, "localhost")
-			viper.SetDefault(config.DatabasePort, 5432)
-			viper.SetDefault(config.DatabaseUser, "")
-			viper.SetDefault(config.DatabasePassword, "")
-			viper.SetDefault(config.DatabaseDatabase, "")
-
-			viper.SetDefault(config.PrometheusPort, "9000")
-
-			viper.SetDefault(config.LogLevel, "info")
-
-			viper.SetDefault(config.SubChainEthHttpRpc, "")
-			viper.SetDefault(config.SubChainPolygonHttpRpc, "")
-			viper.SetDefault(config.SubChainBcsHttpRpc, "")
-			viper.SetDefault(config.SubChainXdaiHttpRpc, "")
+			setConfigDefaults(network, ksUser)
 
 			err = viper.SafeWriteConfigAs(viper.ConfigFileUsed())
 			if err != nil {
@@ -122,10 +95,291 @@ Examples:
 	},
 }
 
+var initFormat string
+
 func init() {
+	initCmd.Flags().StringVar(&initFormat, "format", config.DefaultFormat, "config file format - toml, yaml or json")
 	rootCmd.AddCommand(initCmd)
 }
 
+// setConfigDefaults sets the config values written by init for network, with ksUser as the
+// oracle's keystore account
+func setConfigDefaults(network string, ksUser string) {
+	switch network {
+	case "rinkeby":
+		initForRinkeby()
+		break
+	case "mainnet":
+		initForMainnet()
+		break
+	case "polygon":
+		initForPolygon()
+		break
+	case "dev":
+		initForDevnet()
+		break
+	default:
+		initForDevnet()
+		break
+	}
+
+	viper.SetDefault(config.JobsOooApiUrl, "https://crypto.finchains.io/api")
+	viper.SetDefault(config.JobsOooApiUrlSecondary, "")
+	viper.SetDefault(config.JobsForexApiUrl, "https://api.frankfurter.app/latest?from=USD")
+	viper.SetDefault(config.JobsStablecoinRates, "market")
+	viper.SetDefault(config.JobsStablecoinMaxDepeg, 2)
+	viper.SetDefault(config.JobsAnswerDecimals, 18)
+	viper.SetDefault(config.JobsAnswerRounding, utils.RoundFloor)
+	viper.SetDefault(config.JobsAdhocDMax, 3)
+	viper.SetDefault(config.JobsExactMath, false)
+	viper.SetDefault(config.JobsPairSourcesFile, "")
+	viper.SetDefault(config.JobsSourceRetries, 2)
+	viper.SetDefault(config.JobsSourceExcludeDuration, 3600)
+	viper.SetDefault(config.JobsDelistingLookback, 30)
+	viper.SetDefault(config.JobsMockSources, false)
+	viper.SetDefault(config.JobsMockPricesFile, "")
+	viper.SetDefault(config.JobsLiquidityAlertThreshold, 30000)
+	viper.SetDefault(config.JobsMinVolume24h, 0)
+	viper.SetDefault(config.JobsDexImpactNotional, 10000)
+	viper.SetDefault(config.JobsDexMaxPriceImpact, 0)
+	viper.SetDefault(config.JobsTokenPins, map[string][]string{})
+	viper.SetDefault(config.JobsConsumerSourcePolicies, map[string]string{})
+	viper.SetDefault(config.JobsSourceTiers, map[string][]string{})
+	viper.SetDefault(config.JobsSubgraphMonthlyBudget, 0)
+	viper.SetDefault(config.JobsSubgraphQueryCost, 0)
+	viper.SetDefault(config.JobsSubgraphSyncBudgetShare, 80)
+	viper.SetDefault(config.JobsSubgraphBatchWindow, 25)
+	viper.SetDefault(config.JobsSubgraphBatchSize, 10)
+	viper.SetDefault(config.JobsDexIncrementalSync, true)
+	viper.SetDefault(config.JobsDexFullSyncInterval, 86400)
+	viper.SetDefault(config.JobsBlendDexSources, false)
+	viper.SetDefault(config.JobsBlendDexWeight, 0.5)
+	viper.SetDefault(config.JobsBlendMaxDeviation, 5)
+	viper.SetDefault(config.ServeHost, "127.0.0.1")
+	viper.SetDefault(config.ServePort, "8445")
+	viper.SetDefault(config.KeystorageFile, keyStorePath)
+	viper.SetDefault(config.KeystorageAccount, ksUser)
+	viper.SetDefault(config.KeystorageAccounts, map[string]string{})
+	viper.SetDefault(config.KeystorageRetiringAccount, "")
+	viper.SetDefault(config.KeystorageRetiringUntil, 0)
+	viper.SetDefault(config.KeystoragePasswordCommand, "")
+	viper.SetDefault(config.KeystoragePasswordFile, "")
+	viper.SetDefault(config.KeystoragePasswordEnv, "GO_OOO_KEYSTORE_PASSWORD")
+	viper.SetDefault(config.SignerBackend, signer.BackendKeystore)
+	viper.SetDefault(config.SignerAwsKmsKeyId, "")
+	viper.SetDefault(config.SignerAwsRegion, "")
+	viper.SetDefault(config.SignerAwsKmsEndpoint, "")
+	viper.SetDefault(config.SignerGcpKmsKeyVersion, "")
+	viper.SetDefault(config.SignerGcpCredentialsFile, "")
+	viper.SetDefault(config.SignerGcpKmsEndpoint, "")
+	viper.SetDefault(config.SignerLedgerPath, "m/44'/60'/0'/0/0")
+	viper.SetDefault(config.SignerLedgerAutoApproveGasPrice, 0)
+	viper.SetDefault(config.SignerLedgerApprovalTimeout, 60)
+
+	viper.SetDefault(config.VaultAddress, "")
+	viper.SetDefault(config.VaultNamespace, "")
+	viper.SetDefault(config.VaultToken, "")
+	viper.SetDefault(config.VaultRoleId, "")
+	viper.SetDefault(config.VaultSecretId, "")
+	viper.SetDefault(config.VaultSecretIdFile, "")
+	viper.SetDefault(config.VaultAppRoleMount, "approle")
+	viper.SetDefault(config.VaultKvMount, "secret")
+	viper.SetDefault(config.VaultSecretPath, "go-ooo")
+	viper.SetDefault(config.VaultPrivateKeyField, "private_key")
+	viper.SetDefault(config.VaultPasswordField, "keystore_password")
+	viper.SetDefault(config.VaultDatabaseKeyField, "database_encryption_key")
+	viper.SetDefault(config.ChainGasLimit, 500000)
+	viper.SetDefault(config.ChainEstimateGasLimit, true)
+	viper.SetDefault(config.ChainGasLimitBuffer, 20)
+	viper.SetDefault(config.ChainGasLimitCeiling, 2000000)
+	viper.SetDefault(config.ChainGasLimitAction, "skip")
+	viper.SetDefault(config.ChainCatchupMode, "all")
+	viper.SetDefault(config.ChainCatchupMaxAge, 100)
+	viper.SetDefault(config.ChainMaxGasPrice, 150)
+	viper.SetDefault(config.ChainVorCoordinatorAddress, "")
+	viper.SetDefault(config.ChainXfundSpenders, []string{})
+	viper.SetDefault(config.ChainBackfillBlockRange, 5000)
+	viper.SetDefault(config.ChainBackfillBatchSize, 100)
+	viper.SetDefault(config.ChainBackfillQueueRanges, 4)
+	viper.SetDefault(config.ChainConditionCheckInterval, 60)
+	viper.SetDefault(config.JobsWorkers, 4)
+	viper.SetDefault(config.JobsMaxAttempts, 3)
+	viper.SetDefault(config.JobsRetryBackoff, 15)
+	viper.SetDefault(config.JobsRetryBackoffMax, 300)
+	viper.SetDefault(config.JobsTimeout, 120)
+	viper.SetDefault(config.JobsMinFee, 0)
+	viper.SetDefault(config.JobsConsumerRateLimit, 0)
+	viper.SetDefault(config.JobsConsumerRateLimitAction, "defer")
+	viper.SetDefault(config.JobsSubmissionRate, 0)
+	viper.SetDefault(config.JobsReconcileHour, 0)
+	viper.SetDefault(config.JobsReconcileLookback, 24)
+	viper.SetDefault(config.JobsReconcileFix, false)
+	viper.SetDefault(config.JobsMaxInFlight, 0)
+	viper.SetDefault(config.JobsAnswerDelayMax, 0)
+	viper.SetDefault(config.JobsPriceFreshness, 60)
+	viper.SetDefault(config.JobsGasEscalationBlocks, 100)
+	viper.SetDefault(config.JobsGasEscalationMaxMultiplier, 3)
+	viper.SetDefault(config.JobsProfitabilityCheck, false)
+	viper.SetDefault(config.JobsProfitabilityMargin, 10)
+	viper.SetDefault(config.JobsProfitabilityAction, "defer")
+	viper.SetDefault(config.JobsProfitabilityPriceEndpoint, "XFUND.ETH.AD")
+	viper.SetDefault(config.JobsStuckThreshold, 600)
+	viper.SetDefault(config.JobsCoalesceWindow, 5)
+	viper.SetDefault(config.JobsLatencySlo, 60)
+	viper.SetDefault(config.JobsBackpressureHighWater, 500)
+	viper.SetDefault(config.JobsBackpressureLowWater, 250)
+	viper.SetDefault(config.JobsBackpressureDbLatency, 2000)
+	viper.SetDefault(config.JobsServePairs, []string{})
+	viper.SetDefault(config.JobsExcludePairs, []string{})
+	viper.SetDefault(config.JobsCheckDuration, 5)
+	viper.SetDefault(config.JobsCheckDurationMax, 30)
+	viper.SetDefault(config.JobsWaitConfirmations, 2)
+
+	viper.SetDefault(config.DatabaseDialect, "sqlite")
+	viper.SetDefault(config.DatabaseStorage, dbPath)
+	viper.SetDefault(config.DatabaseHost, "localhost")
+	viper.SetDefault(config.DatabasePort, 5432)
+	viper.SetDefault(config.DatabaseUser, "")
+	viper.SetDefault(config.DatabasePassword, "")
+	viper.SetDefault(config.DatabaseDatabase, "")
+	viper.SetDefault(config.DatabaseEncryptionKey, "")
+	viper.SetDefault(config.DatabaseEncryptionPreviousKeys, []string{})
+	viper.SetDefault(config.DatabaseMaintenanceHour, -1)
+	viper.SetDefault(config.DatabaseMaintenanceTables, database.DefaultMaintenanceTables)
+
+	viper.SetDefault(config.PrometheusPort, "9000")
+	viper.SetDefault(config.PrometheusMaxPairLabels, 25)
+	viper.SetDefault(config.PrometheusMaxConsumerLabels, 25)
+	viper.SetDefault(config.PrometheusLabelPairs, []string{})
+	viper.SetDefault(config.PrometheusLabelConsumers, []string{})
+	viper.SetDefault(config.PrometheusSnapshotInterval, 60)
+
+	viper.SetDefault(config.HealthMinBalance, 0.05)
+	viper.SetDefault(config.HealthMaxBlockLag, 120)
+
+	viper.SetDefault(config.PprofEnabled, false)
+	viper.SetDefault(config.PprofPort, 6060)
+
+	viper.SetDefault(config.AdminApiHost, "127.0.0.1")
+	viper.SetDefault(config.AdminApiPort, 8446)
+	viper.SetDefault(config.AdminApiDashboard, true)
+	viper.SetDefault(config.AdminApiTokens, map[string]interface{}{})
+	viper.SetDefault(config.AdminApiJwtSecret, "")
+	viper.SetDefault(config.AdminApiTlsCertFile, "")
+	viper.SetDefault(config.AdminApiTlsKeyFile, "")
+	viper.SetDefault(config.AdminApiTlsClientCaFile, "")
+
+	viper.SetDefault(config.PriceApiHost, "0.0.0.0")
+	viper.SetDefault(config.PriceApiPort, 0)
+	viper.SetDefault(config.PriceApiCacheTtl, 10)
+	viper.SetDefault(config.PriceApiRateLimit, 5)
+	viper.SetDefault(config.PriceApiRateBurst, 10)
+	viper.SetDefault(config.PriceApiTrustProxy, false)
+
+	viper.SetDefault(config.WebhooksUrls, []string{})
+	viper.SetDefault(config.WebhooksEvents, []string{})
+	viper.SetDefault(config.WebhooksSecret, "")
+	viper.SetDefault(config.WebhooksTimeout, 10)
+	viper.SetDefault(config.IpfsApiUrl, "")
+	viper.SetDefault(config.IpfsUsername, "")
+	viper.SetDefault(config.IpfsPassword, "")
+	viper.SetDefault(config.IpfsTimeout, 30)
+	viper.SetDefault(config.CacheRedisUrl, "")
+	viper.SetDefault(config.CacheRedisPassword, "")
+	viper.SetDefault(config.CachePrefix, "go-ooo:")
+	viper.SetDefault(config.CacheTimeout, 500)
+	viper.SetDefault(config.PushFeedsCheckInterval, 60)
+	viper.SetDefault(config.FeeScheduleEnabled, false)
+	viper.SetDefault(config.FeeScheduleInterval, 3600)
+	viper.SetDefault(config.FeeScheduleWindow, 86400)
+	viper.SetDefault(config.FeeScheduleMinSamples, 5)
+	viper.SetDefault(config.FeeScheduleMargin, 25)
+	viper.SetDefault(config.FeeScheduleThreshold, 10)
+	viper.SetDefault(config.FeeScheduleAutoUpdate, false)
+	viper.SetDefault(config.FeeScheduleMinFee, 0)
+	viper.SetDefault(config.FeeScheduleMaxFee, 0)
+	viper.SetDefault(config.ConsensusHost, "0.0.0.0")
+	viper.SetDefault(config.ConsensusPort, 0)
+	viper.SetDefault(config.ConsensusToken, "")
+	viper.SetDefault(config.ConsensusMaxDeviation, 1)
+	viper.SetDefault(config.ConsensusMinPeers, 1)
+	viper.SetDefault(config.ConsensusMaxAge, 60)
+	viper.SetDefault(config.ConsensusTimeout, 5)
+	viper.SetDefault(config.ConsensusWithhold, false)
+	viper.SetDefault(config.HttpTimeout, 15)
+	viper.SetDefault(config.HttpDialTimeout, 10)
+	viper.SetDefault(config.HttpTlsHandshakeTimeout, 10)
+	viper.SetDefault(config.HttpResponseHeaderTimeout, 10)
+	viper.SetDefault(config.HttpKeepAlive, 30)
+	viper.SetDefault(config.HttpIdleConnTimeout, 90)
+	viper.SetDefault(config.HttpMaxIdleConns, 100)
+	viper.SetDefault(config.HttpMaxIdleConnsPerHost, 10)
+	viper.SetDefault(config.HttpMaxConnsPerHost, 0)
+	viper.SetDefault(config.HttpProxy, "")
+
+	viper.SetDefault(config.AlertsTelegramBotToken, "")
+	viper.SetDefault(config.AlertsTelegramChatId, "")
+	viper.SetDefault(config.AlertsSlackWebhookUrl, "")
+	viper.SetDefault(config.AlertsWebhookUrl, "")
+	viper.SetDefault(config.AlertsWebhookSecret, "")
+	viper.SetDefault(config.AlertsPagerDutyRoutingKey, "")
+	viper.SetDefault(config.AlertsSmtpHost, "")
+	viper.SetDefault(config.AlertsSmtpPort, 587)
+	viper.SetDefault(config.AlertsSmtpUsername, "")
+	viper.SetDefault(config.AlertsSmtpPassword, "")
+	viper.SetDefault(config.AlertsSmtpFrom, "")
+	viper.SetDefault(config.AlertsSmtpTo, []string{})
+	viper.SetDefault(config.AlertsSmtpTls, "starttls")
+	viper.SetDefault(config.AlertsSmtpBatchWindow, 60)
+	viper.SetDefault(config.AlertsSmtpSubjectTemplate, "")
+	viper.SetDefault(config.AlertsSmtpBodyTemplate, "")
+	viper.SetDefault(config.AlertsRoutes, map[string][]string{})
+	viper.SetDefault(config.AlertsSeverities, map[string]string{})
+	viper.SetDefault(config.AlertsCooldown, 3600)
+	viper.SetDefault(config.AlertsTimeout, 10)
+	viper.SetDefault(config.AlertsGasBudget, 0)
+	viper.SetDefault(config.AlertsMaxClockSkew, 30)
+	viper.SetDefault(config.AlertsPairHeartbeatWindow, 3600)
+	viper.SetDefault(config.AlertsPairHeartbeatMinFailures, 3)
+
+	viper.SetDefault(config.TracingOtlpEndpoint, "")
+	viper.SetDefault(config.TracingHeaders, map[string]string{})
+	viper.SetDefault(config.TracingServiceName, "go-ooo")
+	viper.SetDefault(config.TracingSampleRatio, 1.0)
+
+	viper.SetDefault(config.ErrorReportingSentryDsn, "")
+	viper.SetDefault(config.ErrorReportingEnvironment, "")
+	viper.SetDefault(config.ErrorReportingRepeatThreshold, 5)
+	viper.SetDefault(config.ErrorReportingRepeatWindow, 600)
+
+	viper.SetDefault(config.HaEnabled, false)
+	viper.SetDefault(config.HaLockKey, 706070)
+	viper.SetDefault(config.HaCheckInterval, 5)
+
+	viper.SetDefault(config.LogLevel, "info")
+	viper.SetDefault(config.LogFormat, "text")
+	viper.SetDefault(config.LogFile, "")
+	viper.SetDefault(config.LogMaxSize, 100)
+	viper.SetDefault(config.LogMaxBackups, 5)
+	viper.SetDefault(config.LogCompress, true)
+
+	viper.SetDefault(config.UpdateCheckEnabled, false)
+	viper.SetDefault(config.UpdateCheckUrl, version.DefaultReleaseUrl)
+	viper.SetDefault(config.UpdateCheckInterval, 24)
+
+	viper.SetDefault(config.SubChainEthHttpRpc, "")
+	viper.SetDefault(config.SubChainPolygonHttpRpc, "")
+	viper.SetDefault(config.SubChainBcsHttpRpc, "")
+	viper.SetDefault(config.SubChainXdaiHttpRpc, "")
+
+	viper.SetDefault(config.FeaturesDex, true)
+	viper.SetDefault(config.FeaturesCex, true)
+	viper.SetDefault(config.FeaturesVor, true)
+	viper.SetDefault(config.FeaturesPushFeeds, true)
+	viper.SetDefault(config.FeaturesAdminApi, true)
+	viper.SetDefault(config.FeaturesAlerts, true)
+}
+
 func initForRinkeby() {
 	viper.SetDefault(config.ChainContractAddress, "0x05AB63BeC9CfC3897a20dE62f5f812de10301FDf")
 	viper.SetDefault(config.ChainEthHttpHost, "")
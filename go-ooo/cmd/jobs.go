@@ -0,0 +1,366 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	jDeadLimit int
+	jYes       bool
+
+	jStatus    string
+	jJobStatus string
+	jConsumer  string
+	jEndpoint  string
+	jErrorCode string
+	jTag       string
+	jSince     int
+	jLimit     int
+	jOffset    int
+	jJson      bool
+)
+
+// jobsCmd represents the jobs command
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "Job queue sub-commands",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("run one of the sub-commands. See 'go-ooo jobs --help'")
+	},
+}
+
+// jobsListCmd represents the jobs list command
+var jobsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List jobs",
+	Long: `List jobs, most recent first, optionally filtered by request status (e.g. SENT, TX FAILED, DEAD),
+job status (PENDING, SUCCESS or FAIL), consumer, endpoint prefix, tag and hours since last
+update. A tag matches jobs whose consumer or pair has it - see 'go-ooo tags'.
+
+Examples:
+
+  go-ooo jobs list
+  go-ooo jobs list --job-status pending
+  go-ooo jobs list --status "tx failed" --since 24
+  go-ooo jobs list --consumer 0x1234... --endpoint BTC.USD --json
+  go-ooo jobs list --tag acme
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		params := url.Values{}
+		if jStatus != "" {
+			params.Set("status", jStatus)
+		}
+		if jJobStatus != "" {
+			params.Set("job_status", jJobStatus)
+		}
+		listJobs(params)
+	},
+}
+
+// jobsFailedCmd represents the jobs failed command
+var jobsFailedCmd = &cobra.Command{
+	Use:   "failed",
+	Short: "List failed jobs",
+	Long: `List jobs which will not be fulfilled - dead, rejected, skipped etc. - most recent first.
+
+Each records the code of its failure, e.g. PAIR_UNSUPPORTED, INSUFFICIENT_SOURCES,
+PRICE_OUT_OF_BOUNDS, FEE_TOO_LOW, GAS_CAP_EXCEEDED, REQUEST_EXPIRED or TX_REVERTED, which
+--error-code filters by.
+
+Examples:
+
+  go-ooo jobs failed --since 24
+  go-ooo jobs failed --consumer 0x1234... --json
+  go-ooo jobs failed --error-code INSUFFICIENT_SOURCES
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		params := url.Values{}
+		params.Set("job_status", "fail")
+		listJobs(params)
+	},
+}
+
+// jobsGetCmd represents the jobs get command
+var jobsGetCmd = &cobra.Command{
+	Use:   "get [request_id]",
+	Short: "Get a job",
+	Long: `Get a job's current state, and the breakdown of the source values its price was
+aggregated from - each value, its weight in the answer, why it was excluded if it was, and
+how long the fetch took.
+
+Examples:
+
+  go-ooo jobs get 0x1234...
+  go-ooo jobs get 0x1234... --json
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", fmt.Sprintf(apiV1+"/jobs/%s", args[0]), nil)
+		if err != nil || statusCode != 200 || machineOutput(jJson) {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		var req go_ooo_types.RequestInfo
+		err = json.Unmarshal(body, &req)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Request ID\t%s\n", req.RequestId)
+		fmt.Fprintf(w, "Consumer\t%s\n", req.Consumer)
+		fmt.Fprintf(w, "Endpoint\t%s\n", req.Endpoint)
+		fmt.Fprintf(w, "Fee\t%d\n", req.Fee)
+		fmt.Fprintf(w, "Request status\t%s\n", req.RequestStatus)
+		fmt.Fprintf(w, "Job status\t%s\n", req.JobStatus)
+		fmt.Fprintf(w, "Status reason\t%s\n", req.StatusReason)
+		if req.RejectionCode != "" {
+			fmt.Fprintf(w, "Rejection code\t%s\n", req.RejectionCode)
+		}
+		if req.ErrorCode != "" {
+			fmt.Fprintf(w, "Error code\t%s\n", req.ErrorCode)
+		}
+		fmt.Fprintf(w, "Price\t%s\n", req.PriceResult)
+		if req.SourceTier != "" {
+			fmt.Fprintf(w, "Source tier\t%s\n", req.SourceTier)
+		}
+		fmt.Fprintf(w, "Attempts\t%d\n", req.FulfillmentAttempts)
+		fmt.Fprintf(w, "Fulfill tx\t%s\n", req.FulfillTxHash)
+		_ = w.Flush()
+
+		if len(req.Sources) > 0 {
+			fmt.Println()
+			w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SOURCE\tVALUE\tWEIGHT\tLATENCY\tIMPACT\tEXCLUDED")
+			for _, s := range req.Sources {
+				value := strconv.FormatFloat(s.Value, 'g', -1, 64)
+				if s.Value == 0 && s.Excluded != "" {
+					// rejected responses have no value
+					value = "-"
+				}
+				impact := "-"
+				if s.PriceImpact > 0 {
+					impact = fmt.Sprintf("%.2f%%", s.PriceImpact)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%.4f\t%dms\t%s\t%s\n", s.Source, value, s.Weight, s.LatencyMs, impact, s.Excluded)
+			}
+			_ = w.Flush()
+		}
+	},
+}
+
+// jobsDeadCmd represents the jobs dead command
+var jobsDeadCmd = &cobra.Command{
+	Use:   "dead",
+	Short: "List dead jobs",
+	Long: `List jobs which have been dead lettered after exhausting their retry attempts.
+
+Example:
+
+  go-ooo jobs dead --limit 20
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		sendJobsRequest("GET", fmt.Sprintf(apiV1+"/jobs/dead?limit=%d", jDeadLimit))
+	},
+}
+
+// jobsRequeueCmd represents the jobs requeue command
+var jobsRequeueCmd = &cobra.Command{
+	Use:   "requeue [request_id]",
+	Short: "Requeue a failed job",
+	Long: `Requeue a failed, e.g. dead, job. The job's attempts are reset and it is processed again from scratch.
+
+Example:
+
+  go-ooo jobs requeue 0x1234...
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendJobsRequest("POST", fmt.Sprintf(apiV1+"/jobs/%s/requeue", args[0]))
+	},
+}
+
+// jobsFulfillCmd represents the jobs fulfill command
+var jobsFulfillCmd = &cobra.Command{
+	Use:   "fulfill [request_id] [value]",
+	Short: "Force fulfill a request with a manually supplied value",
+	Long: `Force fulfill a request with a manually supplied value, which must already be scaled to
+the answer decimals. For example, 1.5 with 18 decimals is:
+
+  1500000000000000000
+
+The fulfillment is sent on the next job queue check. You will be asked to confirm unless --yes is set.
+
+Example:
+
+  go-ooo jobs fulfill 0x1234... 1500000000000000000
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !jYes && !confirm(fmt.Sprintf("fulfill request %s with value %s?", args[0], args[1])) {
+			fmt.Println("aborted")
+			return
+		}
+
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "POST", fmt.Sprintf(apiV1+"/jobs/%s/fulfill", args[0]),
+			go_ooo_types.ManualFulfillment{Value: args[1]})
+		printJobsResponse(body, statusCode, err)
+	},
+}
+
+// jobsSkipCmd represents the jobs skip command
+var jobsSkipCmd = &cobra.Command{
+	Use:   "skip [request_id]",
+	Short: "Skip a pending job",
+	Long: `Skip a pending job, so that it is not fulfilled.
+
+Example:
+
+  go-ooo jobs skip 0x1234...
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendJobsRequest("POST", fmt.Sprintf(apiV1+"/jobs/%s/skip", args[0]))
+	},
+}
+
+func init() {
+	jobsDeadCmd.Flags().IntVar(&jDeadLimit, "limit", 0, "max number of dead jobs to list. 0 lists all")
+
+	for _, c := range []*cobra.Command{jobsListCmd, jobsFailedCmd} {
+		c.Flags().StringVar(&jConsumer, "consumer", "", "filter by consumer contract address")
+		c.Flags().StringVar(&jEndpoint, "endpoint", "", "filter by endpoint prefix, e.g. BTC.USD")
+		c.Flags().StringVar(&jTag, "tag", "", "filter by consumer or pair tag")
+		c.Flags().StringVar(&jErrorCode, "error-code", "", "filter by the code of the failure, e.g. PRICE_OUT_OF_BOUNDS")
+		c.Flags().IntVar(&jSince, "since", 0, "only jobs updated in the last n hours. 0 lists all")
+		c.Flags().IntVar(&jLimit, "limit", 100, "max number of jobs to list")
+		c.Flags().IntVar(&jOffset, "offset", 0, "number of jobs to skip, for paging")
+		c.Flags().BoolVar(&jJson, "json", false, "output raw JSON")
+	}
+	jobsListCmd.Flags().StringVar(&jStatus, "status", "", "filter by request status")
+	jobsListCmd.Flags().StringVar(&jJobStatus, "job-status", "", "filter by job status - pending, success or fail")
+	jobsGetCmd.Flags().BoolVar(&jJson, "json", false, "output raw JSON")
+
+	jobsCmd.AddCommand(jobsListCmd)
+	jobsCmd.AddCommand(jobsFailedCmd)
+	jobsCmd.AddCommand(jobsGetCmd)
+	jobsCmd.AddCommand(jobsDeadCmd)
+	jobsFulfillCmd.Flags().BoolVar(&jYes, "yes", false, "do not ask for confirmation")
+
+	jobsCmd.AddCommand(jobsRequeueCmd)
+	jobsCmd.AddCommand(jobsFulfillCmd)
+	jobsCmd.AddCommand(jobsSkipCmd)
+
+	rootCmd.AddCommand(jobsCmd)
+}
+
+// listJobs sends a job search with the common filter flags, and prints the result as a table, or JSON if --json is set
+func listJobs(params url.Values) {
+	if jConsumer != "" {
+		params.Set("consumer", jConsumer)
+	}
+	if jEndpoint != "" {
+		params.Set("endpoint", jEndpoint)
+	}
+	if jTag != "" {
+		params.Set("tag", jTag)
+	}
+	if jErrorCode != "" {
+		params.Set("error_code", jErrorCode)
+	}
+	if jSince > 0 {
+		params.Set("since", strconv.Itoa(jSince))
+	}
+	params.Set("limit", strconv.Itoa(jLimit))
+	if jOffset > 0 {
+		params.Set("offset", strconv.Itoa(jOffset))
+	}
+
+	pass, err := readPassword()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	body, statusCode, err := sendApiRequest(pass, "GET", fmt.Sprintf(apiV1+"/jobs?%s", params.Encode()), nil)
+	if err != nil || statusCode != 200 || machineOutput(jJson) {
+		printJobsResponse(body, statusCode, err)
+		return
+	}
+
+	var jobs []go_ooo_types.JobSummary
+	err = json.Unmarshal(body, &jobs)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if len(jobs) == 0 {
+		fmt.Println("no jobs found")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REQUEST ID\tENDPOINT\tCONSUMER\tREQUEST STATUS\tJOB STATUS\tATTEMPTS\tUPDATED\tTAGS\tERROR CODE\tREASON")
+	for _, j := range jobs {
+		tags := strings.Join(append(append([]string{}, j.ConsumerTags...), j.PairTags...), ",")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n", j.RequestId, j.Endpoint, j.Consumer, j.RequestStatus,
+			j.JobStatus, j.FulfillmentAttempts, time.Unix(j.UpdatedAt, 0).UTC().Format(time.RFC3339), tags, j.ErrorCode, j.StatusReason)
+	}
+	_ = w.Flush()
+}
+
+func sendJobsRequest(method string, path string) {
+	pass, err := readPassword()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	body, statusCode, err := sendApiRequest(pass, method, path, nil)
+	printJobsResponse(body, statusCode, err)
+}
+
+func printJobsResponse(body []byte, statusCode int, err error) {
+	if (err != nil || statusCode != 200) && machineOutput(false) {
+		exitWithApiError(body, statusCode, err)
+	}
+
+	if err != nil {
+		fmt.Println("Something went wrong.")
+		fmt.Println(err.Error())
+		return
+	}
+
+	if statusCode != 200 {
+		fmt.Println("Error   :", http.StatusText(statusCode))
+		fmt.Println("Message :", string(body))
+		return
+	}
+
+	printJSON(body)
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go-ooo/app"
+	"golang.org/x/term"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	kImport     bool
+	kGrace      int
+	kNoRegister bool
+	kWithdrawTo string
+	kForce      bool
+)
+
+// keysCmd represents the keys command
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Provider key sub-commands",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(viper.ConfigFileUsed()); errors.Is(err, os.ErrNotExist) {
+			fmt.Println(viper.ConfigFileUsed(), "does not exist. please run 'go-ooo init'")
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("run one of the sub-commands. See 'go-ooo keys --help'")
+	},
+}
+
+// keysRotateCmd represents the keys rotate command
+var keysRotateCmd = &cobra.Command{
+	Use:   "rotate [new_account]",
+	Short: "Rotate to a new provider key",
+	Long: `Start rotating to a new provider key. A new key is generated, or imported with --import,
+and added to the keystore as new_account. It is registered on the router as a provider with
+the current key's fee, and the config is switched to it, with the current key retiring.
+
+The router does not support transferring a provider to a new address, so consumers must
+send new requests to the new address. After restarting the node, requests already
+addressed to the current key continue to be fulfilled with it, and new requests to it are
+accepted until the --grace window ends. Run 'go-ooo keys retire' once it has ended.
+
+The new key needs funds for gas to register. If it has none, the key is still added - fund
+it and run the same command again, or use --no-register and register it with
+'go-ooo admin register' once the node has restarted.
+
+Examples:
+
+  go-ooo keys rotate provider-2
+  go-ooo keys rotate provider-2 --import --grace 168
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := keystorePassOrPrompt()
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		privateKey := ""
+		if kImport {
+			fmt.Print("Private key: ")
+			keyBytes, err := term.ReadPassword(int(syscall.Stdin))
+			fmt.Println("")
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+			privateKey = strings.TrimSpace(string(keyBytes))
+		}
+
+		server, err := app.NewServer(pass)
+		if err != nil {
+			panic(err)
+		}
+
+		rotation, err := server.RotateKey(args[0], privateKey, time.Duration(kGrace)*time.Hour, !kNoRegister)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Printf("new key   : %s (%s)\n", rotation.NewAccount, rotation.NewAddress.Hex())
+		fmt.Printf("retiring  : %s (%s)\n", rotation.OldAccount, rotation.OldAddress.Hex())
+		fmt.Printf("grace ends: %s\n", rotation.Until.UTC().Format(time.RFC3339))
+		if rotation.RegisterTx != "" {
+			fmt.Printf("registered: %s\n", rotation.RegisterTx)
+		}
+		fmt.Println("")
+		fmt.Println("restart the node to switch keys, and ask consumers to send requests to the new address")
+	},
+}
+
+// keysRetireCmd represents the keys retire command
+var keysRetireCmd = &cobra.Command{
+	Use:   "retire",
+	Short: "Retire the previous provider key",
+	Long: `Finish a key rotation, so the previous key is no longer used once the node is restarted.
+The grace window must have ended and all requests to the key must have been processed,
+unless --force is set. With --withdraw-to, the key's fees are withdrawn first - the key
+needs funds for gas. The key is kept in the keystore.
+
+Examples:
+
+  go-ooo keys retire
+  go-ooo keys retire --withdraw-to 0x1234...
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := keystorePassOrPrompt()
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		server, err := app.NewServer(pass)
+		if err != nil {
+			panic(err)
+		}
+
+		retirement, err := server.RetireKey(kWithdrawTo, kForce)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Printf("retired  : %s (%s)\n", retirement.Account, retirement.Address.Hex())
+		if retirement.WithdrawTx != "" {
+			fmt.Printf("withdrawn: %s\n", retirement.WithdrawTx)
+		}
+		fmt.Println("")
+		fmt.Println("restart the node to stop using the key")
+	},
+}
+
+func init() {
+	keysRotateCmd.Flags().BoolVar(&kImport, "import", false, "import an existing private key instead of generating one")
+	keysRotateCmd.Flags().IntVar(&kGrace, "grace", 72, "hours for which new requests to the current key are accepted")
+	keysRotateCmd.Flags().BoolVar(&kNoRegister, "no-register", false, "do not register the new key on the router")
+	keysRetireCmd.Flags().StringVar(&kWithdrawTo, "withdraw-to", "", "withdraw the retiring key's fees to this address")
+	keysRetireCmd.Flags().BoolVar(&kForce, "force", false, "retire before the grace window ends, or with requests pending")
+	keysCmd.PersistentFlags().StringVar(&keystorePass, "pass", "", "keystore password or password file location")
+
+	keysCmd.AddCommand(keysRotateCmd)
+	keysCmd.AddCommand(keysRetireCmd)
+	rootCmd.AddCommand(keysCmd)
+}
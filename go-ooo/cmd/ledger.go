@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/spf13/cobra"
+	"go-ooo/signer"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+var lJson bool
+
+// ledgerCmd represents the admin ledger command
+var ledgerCmd = &cobra.Command{
+	Use:   "ledger",
+	Short: "Approve fulfillment txs held for a Ledger signer",
+	Long: `When fulfillments are signed with a Ledger and signer.ledger_auto_approve_gas_price is set,
+txs with a gas price above it are held until approved here. Approved txs must then be
+confirmed on the device as usual.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("run one of the sub-commands. See 'go-ooo admin ledger --help'")
+	},
+}
+
+// ledgerPendingCmd represents the admin ledger pending command
+var ledgerPendingCmd = &cobra.Command{
+	Use:   "pending",
+	Short: "List txs held for approval",
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", apiV1+"/ledger/pending", nil)
+		if err != nil || statusCode != 200 || machineOutput(lJson) {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		var pending []signer.LedgerPendingTx
+		if err = json.Unmarshal(body, &pending); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if len(pending) == 0 {
+			fmt.Println("no txs pending approval")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNONCE\tTO\tGAS\tGAS PRICE (GWEI)\tMAX FEE (ETH)\tWAITING")
+		for _, p := range pending {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%s\t%s\t%s\n", p.Id, p.Nonce, p.To, p.Gas, formatUnits(p.GasPrice, params.GWei),
+				formatUnits(p.MaxFee, params.Ether), time.Since(time.Unix(p.Created, 0)).Truncate(time.Second).String())
+		}
+		_ = w.Flush()
+	},
+}
+
+// ledgerApproveCmd represents the admin ledger approve command
+var ledgerApproveCmd = &cobra.Command{
+	Use:   "approve [id]",
+	Short: "Approve a held tx, to be confirmed on the Ledger",
+	Long: `Approve a held tx by its id from 'go-ooo admin ledger pending'. It is then sent to the
+Ledger, where it must be confirmed.
+
+Example:
+
+  go-ooo admin ledger approve 3
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendJobsRequest("POST", fmt.Sprintf(apiV1+"/ledger/pending/%s/approve", args[0]))
+	},
+}
+
+// ledgerRejectCmd represents the admin ledger reject command
+var ledgerRejectCmd = &cobra.Command{
+	Use:   "reject [id]",
+	Short: "Reject a held tx",
+	Long: `Reject a held tx by its id from 'go-ooo admin ledger pending'. The fulfillment fails, and is
+retried as usual.
+
+Example:
+
+  go-ooo admin ledger reject 3
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendJobsRequest("POST", fmt.Sprintf(apiV1+"/ledger/pending/%s/reject", args[0]))
+	},
+}
+
+func init() {
+	ledgerPendingCmd.Flags().BoolVar(&lJson, "json", false, "output raw JSON")
+
+	ledgerCmd.AddCommand(ledgerPendingCmd)
+	ledgerCmd.AddCommand(ledgerApproveCmd)
+	ledgerCmd.AddCommand(ledgerRejectCmd)
+	adminCmd.AddCommand(ledgerCmd)
+}
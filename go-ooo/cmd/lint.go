@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"net/url"
+	"os"
+	"strings"
+)
+
+var lintFlagJson bool
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint [ENDPOINT]",
+	Short: "Check whether the node can serve a request endpoint",
+	Long: `Check a prospective request endpoint against the running node, before paying for a request.
+Prints whether the node would serve it, the bytes32 data to send with the request, the sources
+which would be used, and an example of the current value. If the endpoint would be rejected,
+the rejection code and reason are printed instead.
+
+Endpoints are case sensitive, and are checked as given.
+
+Examples:
+
+  go-ooo lint BTC.USD.PR.AVC
+  go-ooo lint XFUND.ETH.AD --json
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		query := url.Values{}
+		query.Set("endpoint", args[0])
+
+		body, statusCode, err := sendApiRequest(pass, "GET", apiV1+"/lint?"+query.Encode(), nil)
+		if err != nil || statusCode != 200 {
+			printJobsResponse(body, statusCode, err)
+			os.Exit(1)
+		}
+
+		if machineOutput(lintFlagJson) {
+			printJSON(body)
+			return
+		}
+
+		var l go_ooo_types.EndpointLint
+		if err = json.Unmarshal(body, &l); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		printEndpointLint(l)
+
+		if !l.Servable {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	lintCmd.Flags().BoolVar(&lintFlagJson, "json", false, "output raw JSON")
+	rootCmd.AddCommand(lintCmd)
+}
+
+func printEndpointLint(l go_ooo_types.EndpointLint) {
+	fmt.Println("Endpoint :", l.Endpoint)
+	if l.Data != "" {
+		fmt.Println("Data     :", l.Data)
+	}
+	if l.Kind != "" {
+		fmt.Println("Kind     :", l.Kind)
+	}
+	if l.Pair != "" {
+		fmt.Println("Pair     :", l.Pair)
+	}
+
+	switch {
+	case l.RejectionCode != "":
+		fmt.Printf("Servable : no - %s: %s\n", l.RejectionCode, l.Reason)
+	case l.Error != "":
+		fmt.Printf("Servable : no - the endpoint is valid, but could not be answered now: %s\n", l.Error)
+	default:
+		fmt.Println("Servable : yes")
+		if len(l.Sources) > 0 {
+			fmt.Println("Sources  :", strings.Join(l.Sources, ", "))
+		}
+		fmt.Printf("Answer   : %s (%s at %d decimals)\n", formatAnswer(l.Answer, l.AnswerDecimals), l.Answer, l.AnswerDecimals)
+	}
+}
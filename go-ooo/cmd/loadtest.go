@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"go-ooo/devnet"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	loadTestRpc       string
+	loadTestHost      string
+	loadTestWsPort    int
+	loadTestFaucetKey string
+	loadTestProvider  string
+	loadTestFee       uint64
+	loadTestEndpoints []string
+	loadTestRate      float64
+	loadTestDuration  time.Duration
+	loadTestDrain     time.Duration
+	loadTestJson      bool
+)
+
+// loadTestCmd represents the loadtest command
+var loadTestCmd = &cobra.Command{
+	Use:   "loadtest",
+	Short: "Send synthetic requests to a devnet at a fixed rate and report the node's throughput",
+	Long: `Send requests from the mock consumer on a devnet started with 'go-ooo devnet' to the node's
+provider address, read from the keystore or given with --provider, at --rate requests per
+second for --duration, then wait up to --drain for outstanding requests to be fulfilled.
+
+The node's throughput and p50, p90 and p99 latencies are reported - from sending each request
+to it being mined, from it being mined to its fulfillment, and in total - along with the
+requests which failed, by failure mode:
+
+  send_failed  the request tx could not be sent
+  not_mined    the request tx was not mined before the test ended
+  reverted     the request tx failed, e.g. the consumer ran out of xFUND
+  unfulfilled  the node did not fulfil the request before the test ended
+
+Requests are sent without waiting for each to be mined, so nothing else should send txs
+from the faucet during the test. Endpoints given with --endpoints are requested in turn.
+Exits with status 1 if any requests failed.
+
+Examples:
+
+  go-ooo loadtest --rate 2 --duration 1m
+  go-ooo loadtest --rate 10 --duration 5m --endpoints BTC.USD.PR.AVC,ETH.USD.PR.AVC --drain 10m
+  go-ooo loadtest --rpc ws://127.0.0.1:8545 --provider 0x70997970C51812dc3A010C7d01b50e0d17dc79C8 --json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		provider := common.HexToAddress(loadTestProvider)
+		if loadTestProvider == "" {
+			key, err := crypto.HexToECDSA(strings.TrimPrefix(devnetReadProviderKey(), "0x"))
+			devnetExitOnError(err)
+			provider = crypto.PubkeyToAddress(key.PublicKey)
+		} else if !common.IsHexAddress(loadTestProvider) {
+			devnetExitOnError(fmt.Errorf("%s is not a valid address", loadTestProvider))
+		}
+
+		rpc := loadTestRpc
+		if rpc == "" {
+			rpc = fmt.Sprintf("ws://%s:%d", loadTestHost, loadTestWsPort)
+		}
+		d, err := devnet.Start(ctx, devnet.Config{RpcUrl: rpc, FaucetKey: loadTestFaucetKey})
+		devnetExitOnError(err)
+		defer d.Close()
+		devnetExitOnError(d.Attach(ctx))
+
+		progress := func(p devnet.LoadTestProgress) {
+			if !machineOutput(loadTestJson) {
+				fmt.Printf("%s: %d sent, %d fulfilled, %d outstanding\n", p.Elapsed.Round(time.Second), p.Sent,
+					p.Fulfilled, p.Outstanding)
+			}
+		}
+
+		res, err := d.LoadTest(ctx, devnet.LoadTestConfig{
+			Provider:  provider,
+			Fee:       new(big.Int).SetUint64(loadTestFee),
+			Endpoints: loadTestEndpoints,
+			Rate:      loadTestRate,
+			Duration:  loadTestDuration,
+			Drain:     loadTestDrain,
+		}, progress)
+		devnetExitOnError(err)
+
+		if machineOutput(loadTestJson) {
+			body, _ := json.Marshal(res)
+			printJSON(body)
+		} else {
+			printLoadTestResult(res)
+		}
+
+		for _, n := range res.Failures {
+			if n > 0 {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+func printLoadTestResult(res devnet.LoadTestResult) {
+	fmt.Println("")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Rate\t%.2f/s sent, %.2f/s target\n", res.SentRate, res.TargetRate)
+	fmt.Fprintf(w, "Requests\t%d sent, %d mined, %d fulfilled\n", res.Sent, res.Mined, res.Fulfilled)
+	fmt.Fprintf(w, "Throughput\t%.2f fulfillments/s over %s\n", res.Throughput, res.Elapsed.Round(time.Millisecond))
+	_ = w.Flush()
+
+	fmt.Println("")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LATENCY\tP50\tP90\tP99\tMAX")
+	for _, l := range []struct {
+		name    string
+		latency devnet.LoadTestLatency
+	}{
+		{"request mined", res.MineLatency},
+		{"fulfilled", res.FulfilLatency},
+		{"total", res.TotalLatency},
+	} {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", l.name, l.latency.P50.Round(time.Millisecond),
+			l.latency.P90.Round(time.Millisecond), l.latency.P99.Round(time.Millisecond), l.latency.Max.Round(time.Millisecond))
+	}
+	_ = w.Flush()
+
+	if len(res.Failures) == 0 {
+		return
+	}
+
+	modes := make([]string, 0, len(res.Failures))
+	for mode := range res.Failures {
+		modes = append(modes, mode)
+	}
+	sort.Strings(modes)
+
+	fmt.Println("")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FAILURE\tCOUNT\tLAST ERROR")
+	for _, mode := range modes {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", mode, res.Failures[mode], res.LastErrors[mode])
+	}
+	_ = w.Flush()
+}
+
+func init() {
+	loadTestCmd.Flags().StringVar(&loadTestRpc, "rpc", "", "devnet chain's RPC, e.g. anvil's. Defaults to the in-process chain's websocket")
+	loadTestCmd.Flags().StringVar(&loadTestHost, "host", "127.0.0.1", "host the in-process chain listens on")
+	loadTestCmd.Flags().IntVar(&loadTestWsPort, "ws-port", 8546, "in-process chain's websocket port")
+	loadTestCmd.Flags().StringVar(&loadTestFaucetKey, "faucet-key", "", "private key which deployed the devnet and pays for requests. Defaults to anvil's first account")
+	loadTestCmd.Flags().StringVar(&loadTestProvider, "provider", "", "provider address to send requests to, instead of the keystore's")
+	loadTestCmd.Flags().StringVar(&keystorePass, "pass", "", "keystore password or password file location")
+	loadTestCmd.Flags().Uint64Var(&loadTestFee, "fee", 100000, "fee paid for each request, in xFUND * 10^9")
+	loadTestCmd.Flags().StringSliceVar(&loadTestEndpoints, "endpoints", []string{"BTC.USD.PR.AVC"}, "endpoints to request in turn")
+	loadTestCmd.Flags().Float64Var(&loadTestRate, "rate", 1, "requests sent per second")
+	loadTestCmd.Flags().DurationVar(&loadTestDuration, "duration", time.Minute, "time to send requests for")
+	loadTestCmd.Flags().DurationVar(&loadTestDrain, "drain", 2*time.Minute, "time to wait, once requests stop, for outstanding requests to be fulfilled")
+	loadTestCmd.Flags().BoolVar(&loadTestJson, "json", false, "output raw JSON")
+
+	rootCmd.AddCommand(loadTestCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+)
+
+// logLevelCmd represents the log-level command
+var logLevelCmd = &cobra.Command{
+	Use:   "log-level [level]",
+	Short: "Query or change the service's log level",
+	Long: `Query the current log level or, if a level is given, change it without restarting
+the service. Levels are panic, fatal, error, warn, info, debug and trace.
+
+Sending SIGUSR1 to the service toggles between debug and the configured log level.
+
+Examples:
+
+  go-ooo admin log-level
+  go-ooo admin log-level debug
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if len(args) == 0 {
+			body, statusCode, err := sendApiRequest(pass, "GET", apiV1+"/log/level", nil)
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "PUT", apiV1+"/log/level", go_ooo_types.LogLevel{Level: args[0]})
+		printJobsResponse(body, statusCode, err)
+	},
+}
+
+func init() {
+	adminCmd.AddCommand(logLevelCmd)
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"strings"
+	"time"
+)
+
+var (
+	maintenanceFlagDuration string
+	maintenanceFlagJson     bool
+)
+
+// maintenanceCmd represents the maintenance command
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Query maintenance mode",
+	Long: `Query whether the node is in maintenance mode. During maintenance, e.g. planned work, alerts
+are suppressed, log entries are annotated with maintenance=true and the ooo_maintenance_mode
+metric is 1. Jobs are processed as usual - use 'go-ooo admin pause' to stop them. Starting and
+ending maintenance is recorded in the audit log.
+
+Examples:
+
+  go-ooo admin maintenance
+  go-ooo admin maintenance start "upgrading the eth node" --duration 2h
+  go-ooo admin maintenance end
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", apiV1+"/maintenance", nil)
+		printMaintenanceResponse(body, statusCode, err)
+	},
+}
+
+// maintenanceStartCmd represents the maintenance start command
+var maintenanceStartCmd = &cobra.Command{
+	Use:   "start [note]",
+	Short: "Put the node into maintenance mode",
+	Long: `Put the node into maintenance mode, with an optional note shown in status and the dashboard.
+Maintenance lasts until ended, or for --duration. A maintenance alert is sent when it starts,
+and resolved when it ends.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		request := go_ooo_types.MaintenanceRequest{
+			Note:     strings.Join(args, " "),
+			Duration: maintenanceFlagDuration,
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "POST", apiV1+"/maintenance", request)
+		printMaintenanceResponse(body, statusCode, err)
+	},
+}
+
+// maintenanceEndCmd represents the maintenance end command
+var maintenanceEndCmd = &cobra.Command{
+	Use:   "end",
+	Short: "End maintenance mode",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "DELETE", apiV1+"/maintenance", nil)
+		printMaintenanceResponse(body, statusCode, err)
+	},
+}
+
+func printMaintenanceResponse(body []byte, statusCode int, err error) {
+	if err != nil || statusCode != 200 || machineOutput(maintenanceFlagJson) {
+		printJobsResponse(body, statusCode, err)
+		return
+	}
+
+	var status go_ooo_types.MaintenanceStatus
+	if err = json.Unmarshal(body, &status); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fmt.Println(formatMaintenance(status))
+}
+
+// formatMaintenance returns a one line summary of the maintenance mode
+func formatMaintenance(m go_ooo_types.MaintenanceStatus) string {
+	if !m.Active {
+		return "no"
+	}
+
+	res := fmt.Sprintf("since %s by %s", time.Unix(m.StartedAt, 0).UTC().Format(time.RFC3339), m.StartedBy)
+	if m.Until > 0 {
+		res += fmt.Sprintf(", until %s", time.Unix(m.Until, 0).UTC().Format(time.RFC3339))
+	}
+	if m.Note != "" {
+		res += " - " + m.Note
+	}
+	return res
+}
+
+func init() {
+	maintenanceCmd.PersistentFlags().BoolVar(&maintenanceFlagJson, "json", false, "output raw JSON")
+	maintenanceStartCmd.Flags().StringVar(&maintenanceFlagDuration, "duration", "", "end maintenance automatically after this long, e.g. 2h")
+	maintenanceCmd.AddCommand(maintenanceStartCmd)
+	maintenanceCmd.AddCommand(maintenanceEndCmd)
+	adminCmd.AddCommand(maintenanceCmd)
+}
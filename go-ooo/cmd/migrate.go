@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go-ooo/database"
+	"os"
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the database schema to the version this go-ooo requires",
+	Long: `Migrate the configured database to the schema version this version of go-ooo
+requires. The node refuses to start against a database with an older schema, so run this
+after upgrading go-ooo. Stop the node, and back up the database, first - migrations may
+delete data which is no longer valid.
+
+A database with a newer schema than this version of go-ooo supports is not changed.
+
+Examples:
+
+  go-ooo migrate
+  go-ooo migrate --home=/home/user/some-other-go-ooo
+`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(viper.ConfigFileUsed()); errors.Is(err, os.ErrNotExist) {
+			fmt.Println(viper.ConfigFileUsed(), "does not exist. please run 'go-ooo init'")
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		db, err := database.NewDb()
+		if err != nil {
+			fmt.Println("cannot open database:", err.Error())
+			os.Exit(1)
+		}
+
+		var mismatch database.SchemaMismatchError
+		err = db.CheckSchemaVersion()
+		switch {
+		case err == nil:
+			// any tables and columns added since the schema version was last changed are created
+			fmt.Printf("database schema is v%d - updating tables\n", database.LatestDbSchemaVersion)
+		case !errors.As(err, &mismatch):
+			fmt.Println("cannot read database schema version:", err.Error())
+			os.Exit(1)
+		case mismatch.Version > mismatch.Expected:
+			fmt.Println(err.Error())
+			os.Exit(1)
+		default:
+			fmt.Printf("migrating database schema from v%d to v%d\n", mismatch.Version, mismatch.Expected)
+		}
+
+		if err = db.Migrate(); err != nil {
+			fmt.Println("migration failed:", err.Error())
+			os.Exit(1)
+		}
+
+		if err = db.CheckSchemaVersion(); err != nil {
+			fmt.Println("migration failed:", err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Printf("database schema is now v%d\n", database.LatestDbSchemaVersion)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+}
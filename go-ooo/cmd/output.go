@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// formats of the --output flag. table is the human readable output of each command, and json and
+// yaml the API's response, for scripts. csv is only supported by report, other commands print
+// their table
+const (
+	OutputTable = "table"
+	OutputJson  = "json"
+	OutputYaml  = "yaml"
+	OutputCsv   = "csv"
+)
+
+var outputFormats = []string{OutputTable, OutputJson, OutputYaml, OutputCsv}
+
+var outputFormat string
+
+// checkOutputFormat exits if --output is not a known format
+func checkOutputFormat() {
+	outputFormat = strings.ToLower(outputFormat)
+	for _, f := range outputFormats {
+		if outputFormat == f {
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "--output must be one of %s\n", strings.Join(outputFormats, ", "))
+	os.Exit(1)
+}
+
+// machineOutput returns true if the output is for scripts - --output json or yaml, or the
+// command's own --json flag
+func machineOutput(jsonFlag bool) bool {
+	return jsonFlag || outputFormat == OutputJson || outputFormat == OutputYaml
+}
+
+// printJSON prints a JSON response body indented, or converted to YAML with --output yaml
+func printJSON(body []byte) {
+	if outputFormat == OutputYaml {
+		out, err := jsonToYaml(body)
+		if err != nil {
+			fmt.Println("JSON parse error: ", err)
+			return
+		}
+		fmt.Print(string(out))
+		return
+	}
+
+	var prettyJSON bytes.Buffer
+	err := json.Indent(&prettyJSON, body, "", "  ")
+	if err != nil {
+		fmt.Println("JSON parse error: ", err)
+		return
+	}
+	fmt.Println(string(prettyJSON.Bytes()))
+}
+
+// printOutput prints v, marshalled to JSON, as printJSON does
+func printOutput(v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	printJSON(body)
+}
+
+// exitWithApiError writes a failed API request's error to stderr, and exits with status 1, so
+// that scripts can tell it from a response
+func exitWithApiError(body []byte, statusCode int, err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+	} else {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", http.StatusText(statusCode), strings.TrimSpace(string(body)))
+	}
+	os.Exit(1)
+}
+
+// jsonToYaml converts a JSON document to YAML, keeping the order of object keys
+func jsonToYaml(body []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	v, err := decodeOrdered(d)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(v)
+}
+
+// decodeOrdered decodes the next JSON value, with objects as yaml.MapSlice
+func decodeOrdered(d *json.Decoder) (interface{}, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := t.(type) {
+	case json.Delim:
+		if t == '[' {
+			list := []interface{}{}
+			for d.More() {
+				v, err := decodeOrdered(d)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+			_, err = d.Token()
+			return list, err
+		}
+
+		m := yaml.MapSlice{}
+		for d.More() {
+			k, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeOrdered(d)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, yaml.MapItem{Key: k, Value: v})
+		}
+		_, err = d.Token()
+		return m, err
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i, nil
+		}
+		if u, err := strconv.ParseUint(t.String(), 10, 64); err == nil {
+			return u, nil
+		}
+		if strings.ContainsAny(t.String(), ".eE") {
+			if f, err := t.Float64(); err == nil {
+				return f, nil
+			}
+		}
+		// integers beyond 64 bits are kept exactly, as strings
+		return t.String(), nil
+	default:
+		return t, nil
+	}
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	pRefresh         bool
+	pClearExclusions bool
+	pJson            bool
+)
+
+// pairsCmd represents the pairs command
+var pairsCmd = &cobra.Command{
+	Use:   "pairs [BASE.TARGET]",
+	Short: "Show supported pairs and source health",
+	Long: `Show the supported pairs, the sources which cover each after any pair source overrides,
+the last fulfilled price, the liquidity of the DEX pair used for ad-hoc requests, and the
+health of each upstream data source. Give a pair to show only that pair.
+
+Sources which failed with a permanent error for a pair, such as a 404 because the source does
+not list it, are not used for that pair for jobs.source_exclude_duration seconds, and are listed
+under the source health.
+
+--refresh forces a resync of the supported pairs from Finchains, and of the DEX pairs, first.
+--clear-exclusions lets the excluded sources be used again, for the given pair only if a pair
+is given.
+
+Examples:
+
+  go-ooo pairs
+  go-ooo pairs XFUND.ETH
+  go-ooo pairs --refresh
+  go-ooo pairs XFUND.ETH --clear-exclusions
+  go-ooo pairs --json
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if pRefresh {
+			body, statusCode, err := sendApiRequest(pass, "POST", apiV1+"/pairs/refresh", nil)
+			if err != nil || statusCode != 200 {
+				printJobsResponse(body, statusCode, err)
+				return
+			}
+			fmt.Println(strings.Trim(strings.TrimSpace(string(body)), `"`))
+			fmt.Println("")
+		}
+
+		if pClearExclusions {
+			clearPath := apiV1 + "/sources/exclusions"
+			if len(args) == 1 {
+				clearPath = fmt.Sprintf(apiV1+"/sources/exclusions?pair=%s", url.QueryEscape(args[0]))
+			}
+			body, statusCode, err := sendApiRequest(pass, "DELETE", clearPath, nil)
+			if err != nil || statusCode != 200 {
+				printJobsResponse(body, statusCode, err)
+				return
+			}
+			fmt.Println(strings.Trim(strings.TrimSpace(string(body)), `"`))
+			fmt.Println("")
+		}
+
+		path := apiV1 + "/pairs"
+		if len(args) == 1 {
+			path = fmt.Sprintf(apiV1+"/pairs?pair=%s", url.QueryEscape(args[0]))
+		}
+
+		pairsBody, statusCode, err := sendApiRequest(pass, "GET", path, nil)
+		if err != nil || statusCode != 200 {
+			printJobsResponse(pairsBody, statusCode, err)
+			return
+		}
+
+		sourcesBody, statusCode, err := sendApiRequest(pass, "GET", apiV1+"/sources", nil)
+		if err != nil || statusCode != 200 {
+			printJobsResponse(sourcesBody, statusCode, err)
+			return
+		}
+
+		exclusionsBody, statusCode, err := sendApiRequest(pass, "GET", apiV1+"/sources/exclusions", nil)
+		if err != nil || statusCode != 200 {
+			printJobsResponse(exclusionsBody, statusCode, err)
+			return
+		}
+
+		if machineOutput(pJson) {
+			printJSON([]byte(fmt.Sprintf(`{"pairs":%s,"sources":%s,"exclusions":%s}`, pairsBody, sourcesBody, exclusionsBody)))
+			return
+		}
+
+		var pairs []go_ooo_types.PairStatus
+		var sources []go_ooo_types.SourceHealth
+		var exclusions []go_ooo_types.SourceExclusion
+
+		if err = json.Unmarshal(pairsBody, &pairs); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		if err = json.Unmarshal(sourcesBody, &sources); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		if err = json.Unmarshal(exclusionsBody, &exclusions); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		printPairs(pairs)
+		fmt.Println("")
+		printSourceHealth(sources)
+		if len(exclusions) > 0 {
+			fmt.Println("")
+			printSourceExclusions(exclusions)
+		}
+	},
+}
+
+func init() {
+	pairsCmd.Flags().BoolVar(&pRefresh, "refresh", false, "resync supported and DEX pairs before showing them")
+	pairsCmd.Flags().BoolVar(&pClearExclusions, "clear-exclusions", false, "use sources excluded after permanent errors again")
+	pairsCmd.Flags().BoolVar(&pJson, "json", false, "output raw JSON")
+	rootCmd.AddCommand(pairsCmd)
+}
+
+func printPairs(pairs []go_ooo_types.PairStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PAIR\tSUPPORTED\tSOURCES\tLAST PRICE\tLAST ENDPOINT\tLAST FULFILLED\tTOP DEX LIQUIDITY (USD)")
+	for _, p := range pairs {
+		lastAt := ""
+		if p.LastPriceAt > 0 {
+			lastAt = time.Unix(p.LastPriceAt, 0).UTC().Format(time.RFC3339)
+		}
+		liquidity := ""
+		if len(p.Liquidity) > 0 {
+			liquidity = fmt.Sprintf("%.2f (%s)", p.Liquidity[0].ReserveUsd, p.Liquidity[0].Dex)
+		}
+		fmt.Fprintf(w, "%s\t%v\t%s\t%s\t%s\t%s\t%s\n", p.Pair, p.Supported, strings.Join(p.Sources, ","),
+			p.LastPrice, p.LastPriceEndpoint, lastAt, liquidity)
+	}
+	_ = w.Flush()
+}
+
+func printSourceHealth(sources []go_ooo_types.SourceHealth) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tKIND\tHEALTHY\tLAST CHECKED\tLAST ERROR")
+	for _, s := range sources {
+		lastChecked := "never"
+		if s.LastChecked > 0 {
+			lastChecked = time.Unix(s.LastChecked, 0).UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%v\t%s\t%s\n", s.Name, s.Kind, s.Healthy, lastChecked, s.LastError)
+	}
+	_ = w.Flush()
+}
+
+func printSourceExclusions(exclusions []go_ooo_types.SourceExclusion) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "EXCLUDED SOURCE\tPAIR\tUNTIL\tREASON")
+	for _, e := range exclusions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Source, e.Pair, time.Unix(e.Until, 0).UTC().Format(time.RFC3339), e.Reason)
+	}
+	_ = w.Flush()
+}
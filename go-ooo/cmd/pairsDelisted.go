@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	pdSince string
+	pdJson  bool
+)
+
+// pairsDelistedCmd represents the pairs delisted command
+var pairsDelistedCmd = &cobra.Command{
+	Use:   "delisted",
+	Short: "Show pairs Finchains no longer lists, and the consumers affected",
+	Long: `Show the pairs Finchains has stopped listing, newest first, with the consumers which
+requested each within jobs.delisting_lookback days before it was delisted, their tags and note,
+so that they can be warned. A pair_delisted alert is also sent for each when it is delisted.
+
+Examples:
+
+  go-ooo pairs delisted
+  go-ooo pairs delisted --since 2026-01-01
+  go-ooo pairs delisted --json
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path := apiV1 + "/pairs/delisted"
+		if pdSince != "" {
+			since, err := parseReportDate(pdSince)
+			if err != nil {
+				fmt.Println("invalid --since date:", err.Error())
+				return
+			}
+			path = fmt.Sprintf("%s?since=%d", path, since.Unix())
+		}
+
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", path, nil)
+		if err != nil || statusCode != 200 || machineOutput(pdJson) {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		var delistings []go_ooo_types.PairDelisting
+		if err = json.Unmarshal(body, &delistings); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if len(delistings) == 0 {
+			fmt.Println("no pairs delisted")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PAIR\tDELISTED\tCONSUMER\tREQUESTS\tLAST REQUESTED\tTAGS\tNOTE")
+		for _, d := range delistings {
+			delistedAt := time.Unix(d.DelistedAt, 0).UTC().Format(time.RFC3339)
+			if len(d.Consumers) == 0 {
+				fmt.Fprintf(w, "%s\t%s\t-\t0\t\t\t\n", d.Pair, delistedAt)
+				continue
+			}
+			for _, c := range d.Consumers {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n", d.Pair, delistedAt, c.Consumer, c.Requests,
+					time.Unix(c.LastRequestedAt, 0).UTC().Format(time.RFC3339), strings.Join(c.Tags, ","), c.Note)
+			}
+		}
+		_ = w.Flush()
+	},
+}
+
+func init() {
+	pairsDelistedCmd.Flags().StringVar(&pdSince, "since", "", "show pairs delisted since this date, default jobs.delisting_lookback days ago")
+	pairsDelistedCmd.Flags().BoolVar(&pdJson, "json", false, "output raw JSON")
+	pairsCmd.AddCommand(pairsDelistedCmd)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	phFrom   string
+	phTo     string
+	phSource string
+	phChange string
+	phLimit  int
+	phJson   bool
+)
+
+// pairsHistoryCmd represents the pairs history command
+var pairsHistoryCmd = &cobra.Command{
+	Use:   "history [BASE.TARGET]",
+	Short: "Show the changelog of the supported pairs, DEX pairs and sources",
+	Long: `Show, most recent first, each change to the pairs the node can answer, and why:
+
+  added                pair listed by Finchains, or DEX pair discovered or configured
+  delisted             pair no longer listed by Finchains
+  blocklisted          pattern added to jobs.exclude_pairs
+  unblocklisted        pattern removed from jobs.exclude_pairs
+  liquidity_dropped    DEX pair's liquidity fell below jobs.liquidity_alert_threshold
+  liquidity_recovered  DEX pair's liquidity recovered above the threshold
+  source_excluded      source not used for the pair after a permanent error
+  source_unavailable   Finchains endpoint, subgraph or TWAP pool became unhealthy
+  source_available     Finchains endpoint, subgraph or TWAP pool recovered
+
+Give a pair to show only the changes to it, under any of the names its sources use, the
+blocklist patterns matching it and the changes to sources which affect every pair.
+
+Dates are YYYY-MM-DD or RFC3339. --from defaults to 30 days before --to, which defaults
+to now.
+
+Examples:
+
+  go-ooo pairs history ETH.XYZ
+  go-ooo pairs history --from 2026-01-01 --change delisted
+  go-ooo pairs history --source uniswapv3 --json
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		params := url.Values{}
+		if len(args) == 1 {
+			params.Set("pair", args[0])
+		}
+
+		for name, value := range map[string]string{"from": phFrom, "to": phTo} {
+			if value == "" {
+				continue
+			}
+			t, err := parseReportDate(value)
+			if err != nil {
+				fmt.Printf("invalid --%s: %s\n", name, err.Error())
+				return
+			}
+			params.Set(name, strconv.FormatInt(t.Unix(), 10))
+		}
+		if phSource != "" {
+			params.Set("source", phSource)
+		}
+		if phChange != "" {
+			params.Set("change", phChange)
+		}
+		if phLimit > 0 {
+			params.Set("limit", strconv.Itoa(phLimit))
+		}
+
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", fmt.Sprintf(apiV1+"/pairs/history?%s", params.Encode()), nil)
+		if err != nil || statusCode != 200 || machineOutput(phJson) {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		var changes []go_ooo_types.PairChange
+		if err = json.Unmarshal(body, &changes); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if len(changes) == 0 {
+			fmt.Println("no pair changes")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CHANGED\tPAIR\tCHANGE\tSOURCE\tREASON")
+		for _, c := range changes {
+			pair := c.Pair
+			if pair == "" {
+				pair = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", time.Unix(c.ChangedAt, 0).UTC().Format(time.RFC3339), pair,
+				c.Change, c.Source, c.Reason)
+		}
+		_ = w.Flush()
+	},
+}
+
+func init() {
+	pairsHistoryCmd.Flags().StringVar(&phFrom, "from", "", "show changes from this date")
+	pairsHistoryCmd.Flags().StringVar(&phTo, "to", "", "show changes up to this date")
+	pairsHistoryCmd.Flags().StringVar(&phSource, "source", "", "only show changes to this source, e.g. a DEX name")
+	pairsHistoryCmd.Flags().StringVar(&phChange, "change", "", "only show this kind of change")
+	pairsHistoryCmd.Flags().IntVar(&phLimit, "limit", 0, "max changes to show, default 100")
+	pairsHistoryCmd.Flags().BoolVar(&phJson, "json", false, "output raw JSON")
+	pairsCmd.AddCommand(pairsHistoryCmd)
+}
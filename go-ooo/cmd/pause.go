@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+)
+
+// pauseCmd represents the pause command
+var pauseCmd = &cobra.Command{
+	Use:   "pause [ingestion|fulfillment|all]",
+	Short: "Pause event ingestion and/or fulfillment submission",
+	Long: `Pause processing without stopping the service, e.g. for key rotation or contract
+upgrades. Pausing ingestion ignores new events until resumed, when events emitted
+while paused are processed. Pausing fulfillment stops fulfillment txs being sent.
+Defaults to all.
+
+Examples:
+
+  go-ooo admin pause
+  go-ooo admin pause fulfillment
+
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		adminTask := go_ooo_types.AdminTask{}
+
+		adminTask.Task = "pause"
+		if len(args) > 0 {
+			adminTask.Scope = args[0]
+		}
+
+		processAdminTask(adminTask)
+	},
+}
+
+// resumeCmd represents the resume command
+var resumeCmd = &cobra.Command{
+	Use:   "resume [ingestion|fulfillment|all]",
+	Short: "Resume paused event ingestion and/or fulfillment submission",
+	Long: `Resume processing previously paused with 'go-ooo admin pause'. Defaults to all.
+
+Examples:
+
+  go-ooo admin resume
+  go-ooo admin resume ingestion
+
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		adminTask := go_ooo_types.AdminTask{}
+
+		adminTask.Task = "resume"
+		if len(args) > 0 {
+			adminTask.Scope = args[0]
+		}
+
+		processAdminTask(adminTask)
+	},
+}
+
+// queryPausedCmd represents the query paused command
+var queryPausedCmd = &cobra.Command{
+	Use:   "paused",
+	Short: "Query whether ingestion and fulfillment are paused",
+	Run: func(cmd *cobra.Command, args []string) {
+		adminTask := go_ooo_types.AdminTask{}
+
+		adminTask.Task = "query_paused"
+
+		processAdminTask(adminTask)
+	},
+}
+
+func init() {
+	adminCmd.AddCommand(pauseCmd)
+	adminCmd.AddCommand(resumeCmd)
+	queryCmd.AddCommand(queryPausedCmd)
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"math/big"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+var (
+	priceFlagPair     string
+	priceFlagEndpoint string
+	priceFlagExplain  bool
+	priceFlagJson     bool
+)
+
+// priceCmd represents the price command
+var priceCmd = &cobra.Command{
+	Use:   "price",
+	Short: "Fetch and aggregate a price now, without submitting it",
+	Long: `Run the full fetch and aggregation path in the running node for a pair, or an endpoint, and
+print the answer it would submit. Nothing is stored or sent to the chain, and results shared
+between requests for the same endpoint are not used.
+
+--pair uses the Finchains average, BASE.TARGET.PR.AVC, for supported pairs, and the DEX ad-hoc
+price, BASE.TARGET.AD, otherwise. Use --endpoint for any other request type.
+
+--explain also prints each source's raw value, which were discarded as outliers and why, the
+weight of each value in the answer, and any source data which was rejected.
+
+Examples:
+
+  go-ooo price --pair ETH-USD
+  go-ooo price --pair XFUND.ETH --explain
+  go-ooo price --endpoint ETH.GBP.AD.AT.1650000000 --explain
+  go-ooo price --pair BTC.USD --json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if (priceFlagPair == "") == (priceFlagEndpoint == "") {
+			fmt.Println("give one of --pair or --endpoint")
+			os.Exit(1)
+		}
+
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		query := url.Values{}
+		if priceFlagEndpoint != "" {
+			query.Set("endpoint", priceFlagEndpoint)
+		} else {
+			query.Set("pair", priceFlagPair)
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", apiV1+"/price?"+query.Encode(), nil)
+		if err != nil || statusCode != 200 {
+			printJobsResponse(body, statusCode, err)
+			os.Exit(1)
+		}
+
+		if machineOutput(priceFlagJson) {
+			printJSON(body)
+			return
+		}
+
+		var e go_ooo_types.PriceExplanation
+		if err = json.Unmarshal(body, &e); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		if priceFlagExplain {
+			printPriceExplanation(e)
+		}
+
+		if e.Error != "" {
+			fmt.Printf("%s: no answer: %s\n", e.Endpoint, e.Error)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%s: %s (%s at %d decimals)\n", e.Endpoint, formatAnswer(e.Answer, e.AnswerDecimals), e.Answer, e.AnswerDecimals)
+	},
+}
+
+func init() {
+	priceCmd.Flags().StringVar(&priceFlagPair, "pair", "", "pair to price, e.g. ETH-USD or ETH.USD")
+	priceCmd.Flags().StringVar(&priceFlagEndpoint, "endpoint", "", "endpoint to price, e.g. BTC.USD.PR.AVI")
+	priceCmd.Flags().BoolVar(&priceFlagExplain, "explain", false, "show each source's value, the outliers and the weights")
+	priceCmd.Flags().BoolVar(&priceFlagJson, "json", false, "output raw JSON")
+	rootCmd.AddCommand(priceCmd)
+}
+
+func printPriceExplanation(e go_ooo_types.PriceExplanation) {
+	fmt.Println("Endpoint :", e.Endpoint)
+	if e.Method != "" {
+		fmt.Println("Method   :", e.Method)
+	}
+	if e.Mean != 0 {
+		fmt.Printf("Mean     : %v\n", e.Mean)
+	}
+	if e.StdDev != 0 {
+		fmt.Printf("Std dev  : %v (outliers are %v or more std devs from the mean)\n", e.StdDev, e.DMax)
+	}
+	if e.FxRate != 0 && e.FxRate != 1 {
+		fmt.Printf("FX rate  : %v (applied to the USD values)\n", e.FxRate)
+	}
+	fmt.Println("")
+
+	if len(e.Values) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SOURCE\tVALUE\tDEVIATION\tWEIGHT\tDISCARDED")
+		for _, v := range e.Values {
+			deviation := ""
+			if e.StdDev != 0 {
+				deviation = fmt.Sprintf("%.3f", v.Deviation)
+			}
+			fmt.Fprintf(w, "%s\t%v\t%s\t%.4f\t%s\n", v.Source, v.Value, deviation, v.Weight, v.Excluded)
+		}
+		_ = w.Flush()
+		fmt.Println("")
+	}
+
+	if len(e.Rejected) > 0 {
+		fmt.Println("Rejected source data:")
+		for _, r := range e.Rejected {
+			fmt.Println("  -", r)
+		}
+		fmt.Println("")
+	}
+}
+
+// formatAnswer returns the answer, scaled to decimals, as a decimal string
+func formatAnswer(answer string, decimals uint) string {
+	a, ok := new(big.Float).SetPrec(256).SetString(answer)
+	if !ok {
+		return answer
+	}
+	a.Quo(a, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+
+	s := a.Text('f', int(decimals))
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
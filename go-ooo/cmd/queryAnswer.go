@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"net/http"
+)
+
+// queryAnswerCmd represents the query answer command
+var queryAnswerCmd = &cobra.Command{
+	Use:   "answer [request_id|tx_hash]",
+	Short: "Look up how a request was answered",
+	Long: `Look up everything stored about a request and its answer - the endpoint, the value
+computed and the source values it was aggregated from, how long each stage took, and the
+request's fee and gas, including the fee market of each mined fulfillment tx. The request can
+be given by its request id, or by the hash of its request tx or of any of its fulfillment txs.
+
+The same record is served by the admin API at /api/v1/answers/<id>, and, if the public price
+API is enabled, at /v1/answers/<id>, without the status reason.
+
+Examples:
+
+  go-ooo query answer 0x1234...
+  go-ooo query answer 0xabcd...
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", fmt.Sprintf(apiV1+"/answers/%s", args[0]), nil)
+		if err != nil {
+			fmt.Println("Something went wrong.")
+			fmt.Println(err.Error())
+			return
+		}
+
+		if statusCode != 200 {
+			fmt.Println("Error   :", http.StatusText(statusCode))
+			fmt.Println("Message :", string(body))
+			return
+		}
+
+		printJSON(body)
+	},
+}
+
+func init() {
+	queryCmd.AddCommand(queryAnswerCmd)
+}
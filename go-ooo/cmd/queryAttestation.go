@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"net/http"
+)
+
+// queryAttestationCmd represents the query attestation command
+var queryAttestationCmd = &cobra.Command{
+	Use:   "attestation [request_id]",
+	Short: "Query the signed attestation for a fulfilled request",
+	Long: `Query the signed off-chain attestation for a fulfilled request. The attestation
+contains the price, timestamp and sources, signed by the oracle key, and the CID of the
+attestation on IPFS, if ipfs.api_url is set and it has been published.
+
+Example:
+
+  go-ooo query attestation 0x1234...
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", fmt.Sprintf(apiV1+"/jobs/%s/attestation", args[0]), nil)
+		if err != nil {
+			fmt.Println("Something went wrong.")
+			fmt.Println(err.Error())
+			return
+		}
+
+		if statusCode != 200 {
+			fmt.Println("Error   :", http.StatusText(statusCode))
+			fmt.Println("Message :", string(body))
+			return
+		}
+
+		printJSON(body)
+	},
+}
+
+func init() {
+	queryCmd.AddCommand(queryAttestationCmd)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"net/http"
+)
+
+var latencyHours int
+
+// queryLatencyCmd represents the query latency command
+var queryLatencyCmd = &cobra.Command{
+	Use:   "latency",
+	Short: "Query fulfillment latency percentiles",
+	Long: `Query p50, p90 and p99 fulfillment latency, in seconds, for each stage of processing:
+event seen to data fetched, data fetched to tx sent, tx sent to tx mined, and the total.
+Latency is reported overall, and by pair and source, along with the percentage of
+fulfillments within the configured jobs.latency_slo.
+
+Examples:
+
+  go-ooo query latency
+  go-ooo query latency --hours 168
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", fmt.Sprintf(apiV1+"/latency?hours=%d", latencyHours), nil)
+		if err != nil {
+			fmt.Println("Something went wrong.")
+			fmt.Println(err.Error())
+			return
+		}
+
+		if statusCode != 200 {
+			fmt.Println("Error   :", http.StatusText(statusCode))
+			fmt.Println("Message :", string(body))
+			return
+		}
+
+		printJSON(body)
+	},
+}
+
+func init() {
+	queryLatencyCmd.Flags().IntVar(&latencyHours, "hours", 24, "report on fulfillments from the last n hours")
+	queryCmd.AddCommand(queryLatencyCmd)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"net/http"
+)
+
+// queryRequestCmd represents the query request command
+var queryRequestCmd = &cobra.Command{
+	Use:   "request [request_id]",
+	Short: "Query the status of a request",
+	Long: `Query the processing status of a request. If the request was rejected at ingestion,
+the machine readable rejection code and reason are included.
+
+Example:
+
+  go-ooo query request 0x1234...
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", fmt.Sprintf(apiV1+"/jobs/%s", args[0]), nil)
+		if err != nil {
+			fmt.Println("Something went wrong.")
+			fmt.Println(err.Error())
+			return
+		}
+
+		if statusCode != 200 {
+			fmt.Println("Error   :", http.StatusText(statusCode))
+			fmt.Println("Message :", string(body))
+			return
+		}
+
+		printJSON(body)
+	},
+}
+
+func init() {
+	queryCmd.AddCommand(queryRequestCmd)
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	reconcileFlagFix   bool
+	reconcileFlagLimit int
+	reconcileFlagJson  bool
+)
+
+// reconcileCmd represents the reconcile command
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Reconcile the database against the router's on-chain state now",
+	Long: `Compare the database's view of requests with the router's on-chain state, as the node does
+nightly at jobs.reconcile_hour, or in the next of schedule.maintenance_windows after it, and print
+the discrepancies found:
+
+  fulfilled_on_chain  fulfilled on chain, but not marked fulfilled in the db, e.g. marked failed
+  not_in_db           fulfilled on chain for the node's provider, but missing from the db
+  pending_on_chain    marked fulfilled in the db, but still pending on chain
+  fee_balance         the withdrawable fee balance differs from the fees earned since the last run
+
+With --fix, requests are corrected - fulfillments are recorded, and requests still pending are
+returned to the job queue. The fee balance is only reported.
+
+Examples:
+
+  go-ooo reconcile
+  go-ooo reconcile --fix
+  go-ooo reconcile history --limit 5
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "POST", apiV1+"/reconcile", reconcileBody{Fix: reconcileFlagFix})
+		if err != nil || statusCode != 200 || machineOutput(reconcileFlagJson) {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		var report go_ooo_types.Reconciliation
+		if err = json.Unmarshal(body, &report); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		printReconciliation(report)
+		if len(report.Discrepancies) > report.Fixed {
+			os.Exit(1)
+		}
+	},
+}
+
+// reconcileHistoryCmd represents the reconcile history command
+var reconcileHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the most recent reconciliation runs",
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", fmt.Sprintf(apiV1+"/reconciliations?limit=%d", reconcileFlagLimit), nil)
+		if err != nil || statusCode != 200 || machineOutput(reconcileFlagJson) {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		var reports []go_ooo_types.Reconciliation
+		if err = json.Unmarshal(body, &reports); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if len(reports) == 0 {
+			fmt.Println("no reconciliations have run")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "RAN AT\tBLOCKS\tCHECKED\tDISCREPANCIES\tFIXED\tWITHDRAWABLE")
+		for _, r := range reports {
+			fmt.Fprintf(w, "%s\t%d - %d\t%d\t%d\t%d\t%s\n", time.Unix(r.RanAt, 0).UTC().Format(time.RFC3339),
+				r.FromBlock, r.ToBlock, r.Checked, len(r.Discrepancies), r.Fixed, r.Withdrawable)
+		}
+		_ = w.Flush()
+	},
+}
+
+type reconcileBody struct {
+	Fix bool `json:"fix"`
+}
+
+func printReconciliation(report go_ooo_types.Reconciliation) {
+	fmt.Printf("blocks %d - %d: %d requests checked, %d discrepancies, %d fixed\n", report.FromBlock,
+		report.ToBlock, report.Checked, len(report.Discrepancies), report.Fixed)
+	fmt.Printf("withdrawable fees: %s", report.Withdrawable)
+	if report.ExpectedWithdrawable != "" {
+		fmt.Printf(" (expected %s)", report.ExpectedWithdrawable)
+	}
+	fmt.Println()
+
+	if len(report.Discrepancies) == 0 {
+		return
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tREQUEST\tDB STATUS\tFIXED\tDETAIL")
+	for _, d := range report.Discrepancies {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\n", d.Kind, d.RequestId, d.DbStatus, d.Fixed, d.Detail)
+	}
+	_ = w.Flush()
+}
+
+func init() {
+	reconcileCmd.Flags().BoolVar(&reconcileFlagFix, "fix", false, "correct the requests found in the db")
+	reconcileCmd.PersistentFlags().BoolVar(&reconcileFlagJson, "json", false, "output raw JSON")
+	reconcileHistoryCmd.Flags().IntVar(&reconcileFlagLimit, "limit", 10, "number of runs to list")
+
+	reconcileCmd.AddCommand(reconcileHistoryCmd)
+
+	rootCmd.AddCommand(reconcileCmd)
+}
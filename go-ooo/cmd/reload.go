@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"strings"
+)
+
+// reloadCmd represents the reload command
+var reloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Reload the config file without restarting",
+	Long: `Reload the config file without restarting the service or dropping in-flight jobs.
+Settings such as fee thresholds, retry settings, the consumer rate limit, alerts, webhooks,
+pair source overrides and the log level are applied straight away. Changes to settings only
+read at startup, such as the chain, database, keys and listeners, are listed, and applied
+when the service is restarted.
+
+Sending SIGHUP to the service also reloads the config file.
+
+Examples:
+
+  go-ooo admin reload
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "POST", apiV1+"/config/reload", nil)
+		if err != nil || statusCode != 200 {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		if machineOutput(false) {
+			printJSON(body)
+			return
+		}
+
+		var res go_ooo_types.ConfigReload
+		if err = json.Unmarshal(body, &res); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if len(res.Changed) == 0 && len(res.RestartRequired) == 0 {
+			fmt.Println("config reloaded - no changes")
+			return
+		}
+		if len(res.Changed) > 0 {
+			fmt.Printf("applied: %s\n", strings.Join(res.Changed, ", "))
+		}
+		if len(res.RestartRequired) > 0 {
+			fmt.Printf("restart required: %s\n", strings.Join(res.RestartRequired, ", "))
+		}
+	},
+}
+
+func init() {
+	adminCmd.AddCommand(reloadCmd)
+}
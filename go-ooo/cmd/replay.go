@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go-ooo/app"
+	"os"
+)
+
+var replayFromBlock uint64
+
+// replayCmd represents the replay command
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Re-process historical events from a block",
+	Long: `Re-process all DataRequested and RequestFulfilled events from a block against the
+current database, e.g. after restoring it from a backup. Missing requests are added,
+and each request's status is reconciled against its on-chain fulfillment state.
+
+No fulfillment transactions are sent. Requests which are still pending on chain are
+fulfilled as normal once the service is started. The service should be stopped while
+running a replay.
+
+Examples:
+
+  go-ooo replay --from-block 12345678
+  go-ooo replay --from-block 12345678 --pass=/path/to/pass.txt
+`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(viper.ConfigFileUsed()); errors.Is(err, os.ErrNotExist) {
+			fmt.Println(viper.ConfigFileUsed(), "does not exist. please run 'go-ooo init'")
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		server, err := app.NewServer(keystorePass)
+		if err != nil {
+			panic(err)
+		}
+
+		summary, err := server.Replay(replayFromBlock)
+		if err != nil {
+			fmt.Println("replay failed:", err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Println("Requests           :", summary.Requests)
+		fmt.Println("Added to db        :", summary.Inserted)
+		fmt.Println("Fulfilled on chain :", summary.Fulfilled)
+		fmt.Println("Returned to queue  :", summary.Reset)
+		fmt.Println("Missing on chain   :", summary.Missing)
+	},
+}
+
+func init() {
+	replayCmd.Flags().Uint64Var(&replayFromBlock, "from-block", 0, "block to replay events from")
+	replayCmd.Flags().StringVar(&keystorePass, "pass", "", "keystore password or password file location")
+	_ = replayCmd.MarkFlagRequired("from-block")
+	rootCmd.AddCommand(replayCmd)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	reportFrom       string
+	reportTo         string
+	reportXfundPrice float64
+)
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report fees earned and gas spent on fulfillments",
+	Long: `Report the number of requests fulfilled, xFUND fees earned, ETH spent on gas and the
+net margin, in ETH, overall and by pair and consumer, for fulfillments between --from and --to.
+
+Dates are YYYY-MM-DD or RFC3339. --from defaults to 30 days before --to, which defaults
+to now. The current xFUND price is fetched from CoinGecko unless --xfund-price is given.
+
+Examples:
+
+  go-ooo report --from 2022-01-01 --to 2022-02-01
+  go-ooo report --from 2022-01-01 --output csv > report.csv
+  go-ooo report --output json --xfund-price 0.1
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		params := url.Values{}
+
+		for name, value := range map[string]string{"from": reportFrom, "to": reportTo} {
+			if value == "" {
+				continue
+			}
+			t, err := parseReportDate(value)
+			if err != nil {
+				fmt.Printf("invalid --%s: %s\n", name, err.Error())
+				return
+			}
+			params.Set(name, strconv.FormatInt(t.Unix(), 10))
+		}
+
+		xfundPrice := reportXfundPrice
+		if xfundPrice <= 0 {
+			xfundPrice = getXfundPrice().Xfund.Eth
+		}
+		params.Set("xfund_price", strconv.FormatFloat(xfundPrice, 'f', -1, 64))
+
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", fmt.Sprintf(apiV1+"/report?%s", params.Encode()), nil)
+		if err != nil || statusCode != 200 {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		if machineOutput(false) {
+			printJSON(body)
+			return
+		}
+
+		var report go_ooo_types.EarningsReport
+		err = json.Unmarshal(body, &report)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if outputFormat == OutputCsv {
+			printReportCsv(report)
+		} else {
+			printReportTable(report)
+		}
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportFrom, "from", "", "report on fulfillments from this date")
+	reportCmd.Flags().StringVar(&reportTo, "to", "", "report on fulfillments up to this date")
+	reportCmd.Flags().Float64Var(&reportXfundPrice, "xfund-price", 0.0, "xFUND price in ETH, used to calculate the net margin")
+	rootCmd.AddCommand(reportCmd)
+}
+
+func parseReportDate(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func reportRowValues(group string, row go_ooo_types.EarningsReportRow) []string {
+	return []string{
+		group,
+		row.Key,
+		strconv.FormatUint(row.NumFulfilled, 10),
+		strconv.FormatFloat(row.FeesXfund, 'f', 9, 64),
+		strconv.FormatFloat(row.FeesEth, 'f', 18, 64),
+		strconv.FormatFloat(row.GasSpentEth, 'f', 18, 64),
+		strconv.FormatFloat(row.NetMarginEth, 'f', 18, 64),
+	}
+}
+
+var reportHeader = []string{"group", "key", "fulfilled", "fees_xfund", "fees_eth", "gas_spent_eth", "net_margin_eth"}
+
+func printReportCsv(report go_ooo_types.EarningsReport) {
+	w := csv.NewWriter(os.Stdout)
+	_ = w.Write(reportHeader)
+	_ = w.Write(reportRowValues("total", report.Total))
+	for _, row := range report.Pairs {
+		_ = w.Write(reportRowValues("pair", row))
+	}
+	for _, row := range report.Consumers {
+		_ = w.Write(reportRowValues("consumer", row))
+	}
+	w.Flush()
+}
+
+func printReportTable(report go_ooo_types.EarningsReport) {
+	fmt.Printf("Fulfillments from %s to %s, xFUND price %v ETH\n\n",
+		time.Unix(report.From, 0).UTC().Format(time.RFC3339),
+		time.Unix(report.To, 0).UTC().Format(time.RFC3339),
+		report.XfundPriceEth)
+
+	printTable := func(title string, rows []go_ooo_types.EarningsReportRow) {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "%s\tFULFILLED\tFEES (xFUND)\tFEES (ETH)\tGAS (ETH)\tNET MARGIN (ETH)\n", title)
+		for _, row := range rows {
+			key := row.Key
+			if key == "" {
+				key = "total"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%.4f\t%.6f\t%.6f\t%.6f\n", key, row.NumFulfilled,
+				row.FeesXfund, row.FeesEth, row.GasSpentEth, row.NetMarginEth)
+		}
+		_ = w.Flush()
+		fmt.Println("")
+	}
+
+	printTable("TOTAL", []go_ooo_types.EarningsReportRow{report.Total})
+	printTable("PAIR", report.Pairs)
+	printTable("CONSUMER", report.Consumers)
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/cobra"
+	"go-ooo/config"
 	"os"
 	"path/filepath"
 
@@ -16,14 +17,19 @@ var keystorePass string
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "app",
-	Short: "A brief description of your application",
-	Long: `A longer description that spans multiple lines and likely contains
-examples and usage of using your application. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Use:   "go-ooo",
+	Short: "xFUND Router provider oracle node",
+	Long: `go-ooo runs an xFUND Router data provider - it watches the Router for data requests
+addressed to the provider, fetches and aggregates the prices requested, and fulfills them.
+
+The other commands manage a running node through its admin API. Each prints a table for
+people, or the API's response with --output json or --output yaml, for scripts. Errors are
+then written to stderr, with a non-zero exit status.
+
+Shell completion scripts are generated with 'go-ooo completion', e.g.
+
+  source <(go-ooo completion bash)
+  go-ooo completion zsh > "${fpath[1]}/_go-ooo"`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
@@ -36,38 +42,46 @@ func Execute() {
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
+	cobra.OnInitialize(checkOutputFormat, initConfig)
 
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&appHomePath, "home", "", "app home file (default is $HOME/.go-ooo)")
+	rootCmd.PersistentFlags().String("profile", "", "network profile to use, from the config file's [profiles] (default is the profile key, if set)")
+	cobra.CheckErr(viper.BindPFlag(config.Profile, rootCmd.PersistentFlags().Lookup("profile")))
+
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", OutputTable, "output format - table, json or yaml. report also supports csv")
+	cobra.CheckErr(rootCmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return outputFormats, cobra.ShellCompDirectiveNoFileComp
+	}))
 }
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
-	if appHomePath != "" {
-		// Use config file from the flag.
-		appCfg := filepath.Join(appHomePath, "config.toml")
-		keyStorePath = filepath.Join(appHomePath, "keystore.json")
-		dbPath = filepath.Join(appHomePath, "ooo.db")
-		viper.SetConfigFile(appCfg)
-	} else {
+	if appHomePath == "" {
 		// Find home directory.
 		home, err := os.UserHomeDir()
 		cobra.CheckErr(err)
 		appHomePath = filepath.Join(home, ".go-ooo")
-		appCfg := filepath.Join(appHomePath, "config.toml")
-		keyStorePath = filepath.Join(appHomePath, "keystore.json")
-		dbPath = filepath.Join(appHomePath, "ooo.db")
-		viper.SetConfigFile(appCfg)
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	// config.toml, config.yaml or config.json - the format is detected from the extension
+	keyStorePath = filepath.Join(appHomePath, "keystore.json")
+	dbPath = filepath.Join(appHomePath, "ooo.db")
+	viper.SetConfigFile(config.FindFile(appHomePath))
+
+	// read in environment variables that match, e.g. GO_OOO_CHAIN_ETH_WS_HOST
+	config.BindEnv(viper.GetViper())
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+
+		if profile := viper.GetString(config.Profile); profile != "" {
+			cobra.CheckErr(config.ApplyProfile(viper.GetViper(), profile))
+			fmt.Fprintln(os.Stderr, "Using profile:", profile)
+		}
 	}
 }
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"net/url"
+	"os"
+	"text/tabwriter"
+)
+
+// showConfigCmd represents the show-config command
+var showConfigCmd = &cobra.Command{
+	Use:   "show-config [prefix]",
+	Short: "Show the config the service is running with",
+	Long: `Show the running value of each config key, after profile and environment variable
+overrides and defaults have been applied, and where each was set - env, profile, file or
+default. Secrets, such as passwords, tokens and API keys in urls, are redacted.
+
+Settings which need a restart keep their running values after a reload, so this shows
+what the service is using rather than what the config file says. If a prefix is given,
+only keys starting with it are shown.
+
+Examples:
+
+  go-ooo admin show-config
+  go-ooo admin show-config jobs.
+  go-ooo admin show-config --output json
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		path := apiV1 + "/config"
+		if len(args) > 0 {
+			path += "?prefix=" + url.QueryEscape(args[0])
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", path, nil)
+		if err != nil || statusCode != 200 {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		if machineOutput(false) {
+			printJSON(body)
+			return
+		}
+
+		var res go_ooo_types.EffectiveConfig
+		if err = json.Unmarshal(body, &res); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		fmt.Printf("config file: %s\n", res.ConfigFile)
+		if res.Profile != "" {
+			fmt.Printf("profile: %s\n", res.Profile)
+		}
+		fmt.Println("")
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tSOURCE\tVALUE")
+		for _, s := range res.Settings {
+			value, _ := json.Marshal(s.Value)
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, s.Source, value)
+		}
+		_ = w.Flush()
+	},
+}
+
+func init() {
+	adminCmd.AddCommand(showConfigCmd)
+}
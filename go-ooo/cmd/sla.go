@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	slaFrom           string
+	slaTo             string
+	slaConsumer       string
+	slaTag            string
+	slaDeadlineBlocks uint64
+)
+
+// slaCmd represents the sla command
+var slaCmd = &cobra.Command{
+	Use:   "sla",
+	Short: "Report each consumer's service level",
+	Long: `Report, for each consumer, the percentage of its requests received between --from and
+--to which were fulfilled on time, the average latency from the request being seen to its
+fulfillment being mined, and why the requests which were not fulfilled failed.
+
+A request is on time if fulfilled within --deadline-blocks of the block it was made in, by
+default the blocks after which the node abandons a request. Requests not fulfilled for the
+consumer's own reasons - a fee below the minimum, the rate limit, an invalid endpoint or a
+callback needing more gas than the ceiling - are excluded from the percentage, as are those
+still pending.
+
+Dates are YYYY-MM-DD or RFC3339. --from defaults to 30 days before --to, which defaults
+to now. Consumers with the worst service level are first.
+
+Examples:
+
+  go-ooo sla --from 2022-01-01 --to 2022-02-01
+  go-ooo sla --consumer 0x... --deadline-blocks 20
+  go-ooo sla --tag acme --output csv > sla.csv
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		params := url.Values{}
+
+		for name, value := range map[string]string{"from": slaFrom, "to": slaTo} {
+			if value == "" {
+				continue
+			}
+			t, err := parseReportDate(value)
+			if err != nil {
+				fmt.Printf("invalid --%s: %s\n", name, err.Error())
+				return
+			}
+			params.Set(name, strconv.FormatInt(t.Unix(), 10))
+		}
+		if slaConsumer != "" {
+			params.Set("consumer", slaConsumer)
+		}
+		if slaTag != "" {
+			params.Set("tag", slaTag)
+		}
+		if slaDeadlineBlocks > 0 {
+			params.Set("deadline_blocks", strconv.FormatUint(slaDeadlineBlocks, 10))
+		}
+
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", fmt.Sprintf(apiV1+"/sla?%s", params.Encode()), nil)
+		if err != nil || statusCode != 200 {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		if machineOutput(false) {
+			printJSON(body)
+			return
+		}
+
+		var report go_ooo_types.SlaReport
+		err = json.Unmarshal(body, &report)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if outputFormat == OutputCsv {
+			printSlaCsv(report)
+		} else {
+			printSlaTable(report)
+		}
+	},
+}
+
+func init() {
+	slaCmd.Flags().StringVar(&slaFrom, "from", "", "report on requests received from this date")
+	slaCmd.Flags().StringVar(&slaTo, "to", "", "report on requests received up to this date")
+	slaCmd.Flags().StringVar(&slaConsumer, "consumer", "", "only report this consumer")
+	slaCmd.Flags().StringVar(&slaTag, "tag", "", "only report consumers with this tag")
+	slaCmd.Flags().Uint64Var(&slaDeadlineBlocks, "deadline-blocks", 0, "blocks after the request within which a fulfillment is on time")
+	rootCmd.AddCommand(slaCmd)
+}
+
+// slaReasons formats the failure reasons as REASON=count pairs, marking those excluded from the
+// service level
+func slaReasons(reasons []go_ooo_types.SlaFailureReason) string {
+	formatted := make([]string, 0, len(reasons))
+	for _, r := range reasons {
+		reason := fmt.Sprintf("%s=%d", r.Reason, r.Count)
+		if r.Excluded {
+			reason += " (excluded)"
+		}
+		formatted = append(formatted, reason)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+var slaHeader = []string{"consumer", "requests", "on_time", "late", "failed", "excluded", "pending",
+	"on_time_percent", "avg_latency_ms", "avg_latency_blocks", "failure_reasons"}
+
+func slaRowValues(sla go_ooo_types.ConsumerSla) []string {
+	consumer := sla.Consumer
+	if consumer == "" {
+		consumer = "total"
+	}
+	return []string{
+		consumer,
+		strconv.FormatUint(sla.NumRequests, 10),
+		strconv.FormatUint(sla.NumOnTime, 10),
+		strconv.FormatUint(sla.NumLate, 10),
+		strconv.FormatUint(sla.NumFailed, 10),
+		strconv.FormatUint(sla.NumExcluded, 10),
+		strconv.FormatUint(sla.NumPending, 10),
+		strconv.FormatFloat(sla.OnTimePercent, 'f', 2, 64),
+		strconv.FormatInt(sla.AvgLatencyMs, 10),
+		strconv.FormatFloat(sla.AvgLatencyBlocks, 'f', 2, 64),
+		slaReasons(sla.FailureReasons),
+	}
+}
+
+func printSlaCsv(report go_ooo_types.SlaReport) {
+	w := csv.NewWriter(os.Stdout)
+	_ = w.Write(slaHeader)
+	_ = w.Write(slaRowValues(report.Total))
+	for _, sla := range report.Consumers {
+		_ = w.Write(slaRowValues(sla))
+	}
+	w.Flush()
+}
+
+func printSlaTable(report go_ooo_types.SlaReport) {
+	fmt.Printf("Requests received from %s to %s, on time within %d blocks\n\n",
+		time.Unix(report.From, 0).UTC().Format(time.RFC3339),
+		time.Unix(report.To, 0).UTC().Format(time.RFC3339),
+		report.DeadlineBlocks)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONSUMER\tREQUESTS\tON TIME\tLATE\tFAILED\tEXCLUDED\tPENDING\tON TIME %\tAVG LATENCY\tAVG BLOCKS\tFAILURE REASONS")
+	for _, sla := range append([]go_ooo_types.ConsumerSla{report.Total}, report.Consumers...) {
+		row := slaRowValues(sla)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", row[0], row[1], row[2], row[3], row[4], row[5],
+			row[6], row[7], (time.Duration(sla.AvgLatencyMs) * time.Millisecond).String(), row[9], row[10])
+	}
+	_ = w.Flush()
+}
@@ -22,11 +22,31 @@ the configuration.
 
 The --home path can be specified.
 The --pass flag can also be used to pass the location of the file containing your
-keystore password, or the password itself.
+keystore password, or the password itself. Without --pass, the password is taken from
+keystorage.password_command, keystorage.password_file, the environment variable named
+by keystorage.password_env (GO_OOO_KEYSTORE_PASSWORD by default) or Vault, and is
+otherwise prompted for.
+
+Any config key can be overridden by an environment variable, e.g. GO_OOO_CHAIN_ETH_WS_HOST
+for chain.eth_ws_host - see 'go-ooo env'.
+
+One config file can hold settings for several networks as named profiles, selected with
+--profile. A profile may set any key - usually the RPCs, contract addresses, gas settings,
+pair source overrides and database - over those at the top level of the file:
+
+  [profiles.sepolia.chain]
+  contract_address = "0x..."
+  eth_ws_host = "wss://..."
+  network_id = 11155111
+  max_gas_price = 50
+
+  [profiles.sepolia.jobs]
+  pair_sources_file = "/home/user/.go-ooo/sepolia_sources.toml"
 
 Examples:
 
   go-ooo start
+  go-ooo start --profile=sepolia
   go-ooo start --home=/home/user/some-other-go-ooo
   go-ooo start --home=/home/user/some-other-go-ooo --pass=/path/to/pass.txt
 `,
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var statusJson bool
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show a summary of the node's status",
+	Long: `Show a one-shot summary of the running node: the chain head and last processed block,
+wallet ETH and xFUND balances, and those of any other keys in use, fees available to withdraw,
+pending jobs, the health of each upstream data source, and the node's version.
+
+Examples:
+
+  go-ooo status
+  go-ooo status --json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", apiV1+"/status", nil)
+		if err != nil || statusCode != 200 {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		if machineOutput(statusJson) {
+			printJSON(body)
+			return
+		}
+
+		var status go_ooo_types.NodeStatus
+		if err = json.Unmarshal(body, &status); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		printNodeStatus(status)
+		if len(status.Keys) > 0 {
+			fmt.Println("")
+			printKeyBalances(status.Keys)
+		}
+		fmt.Println("")
+		printSourceHealth(status.Sources)
+	},
+}
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusJson, "json", false, "output raw JSON")
+	rootCmd.AddCommand(statusCmd)
+}
+
+func printNodeStatus(s go_ooo_types.NodeStatus) {
+	leader := "yes"
+	if !s.Leader {
+		leader = "no (standby)"
+	}
+
+	paused := s.Paused
+	if paused == "" {
+		paused = "no"
+	}
+
+	blocksBehind := int64(s.CurrentBlock) - int64(s.LastBlock)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Version\t%s\n", s.Version)
+	fmt.Fprintf(w, "Oracle\t%s\n", s.OracleAddress)
+	if s.RetiringAddress != "" {
+		fmt.Fprintf(w, "Retiring key\t%s (new requests accepted until %s)\n", s.RetiringAddress,
+			time.Unix(s.RetiringUntil, 0).UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "Router\t%s\n", s.ContractAddress)
+	fmt.Fprintf(w, "Leader\t%s\n", leader)
+	fmt.Fprintf(w, "Paused\t%s\n", paused)
+	if s.Maintenance != nil {
+		fmt.Fprintf(w, "Maintenance\t%s\n", formatMaintenance(*s.Maintenance))
+	}
+	fmt.Fprintf(w, "Chain head\t%d\n", s.CurrentBlock)
+	fmt.Fprintf(w, "Last processed block\t%d (%d behind)\n", s.LastBlock, blocksBehind)
+	fmt.Fprintf(w, "ETH balance\t%s ETH\n", formatUnits(s.WalletBalance, params.Ether))
+	fmt.Fprintf(w, "xFUND balance\t%s xFUND\n", formatUnits(s.XfundBalance, params.GWei))
+	fmt.Fprintf(w, "Withdrawable fees\t%s xFUND\n", formatUnits(s.WithdrawableFees, params.GWei))
+	fmt.Fprintf(w, "Pending jobs\t%d\n", s.PendingJobs)
+	if b := s.SubgraphBudget; b != nil {
+		fmt.Fprintf(w, "Subgraph spend\t%s\n", formatSubgraphBudget(*b))
+	}
+	fmt.Fprintf(w, "Workers\t%d\n", s.Workers)
+	fmt.Fprintf(w, "VOR\t%v\n", s.VorEnabled)
+	fmt.Fprintf(w, "Lifetime fulfillments\t%d\n", s.Totals.Fulfillments)
+	fmt.Fprintf(w, "Lifetime fees earned\t%s xFUND\n", formatUnits(s.Totals.FeesEarned, params.GWei))
+	fmt.Fprintf(w, "Lifetime gas\t%s gas, %s ETH\n", s.Totals.GasUsed, formatUnits(s.Totals.GasCost, params.Ether))
+	_ = w.Flush()
+}
+
+func printKeyBalances(keys []go_ooo_types.KeyBalance) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tADDRESS\tETH\tXFUND")
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", k.Role, k.Address, formatUnits(k.WalletBalance, params.Ether),
+			formatUnits(k.XfundBalance, params.GWei))
+	}
+	_ = w.Flush()
+}
+
+// formatUnits converts an integer amount in a token's smallest unit, e.g. wei, to whole tokens
+func formatUnits(amount string, unit float64) string {
+	a, ok := new(big.Float).SetString(amount)
+	if !ok {
+		return amount
+	}
+	return new(big.Float).Quo(a, big.NewFloat(unit)).Text('f', 6)
+}
+
+// formatSubgraphBudget returns a one line summary of the month's subgraph spend
+func formatSubgraphBudget(b go_ooo_types.SubgraphBudget) string {
+	if b.Budget <= 0 {
+		return fmt.Sprintf("%g in %s (no budget)", b.Spent, b.Month)
+	}
+
+	res := fmt.Sprintf("%g of %g in %s (%.1f%%)", b.Spent, b.Budget, b.Month, b.Spent/b.Budget*100)
+	if len(b.Throttled) > 0 {
+		res += fmt.Sprintf(" - %s queries throttled", strings.Join(b.Throttled, ", "))
+	}
+	return res
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+var (
+	tagsFlagTags []string
+	tagsFlagNote string
+	tagsFlagJson bool
+)
+
+// tagsCmd represents the tags command
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "Tag consumers and pairs",
+	Long: `Attach tags and a note to consumers and pairs, e.g. the customer or product line they belong to.
+Tags are shown in job listings, earnings and consumer reports, and alerts, and jobs can be
+listed by tag with 'go-ooo jobs list --tag'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("run one of the sub-commands. See 'go-ooo tags --help'")
+	},
+}
+
+// tagsListCmd represents the tags list command
+var tagsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the tags and notes of every consumer and pair",
+	Run: func(cmd *cobra.Command, args []string) {
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "GET", apiV1+"/tags", nil)
+		if err != nil || statusCode != 200 || machineOutput(tagsFlagJson) {
+			printJobsResponse(body, statusCode, err)
+			return
+		}
+
+		var list go_ooo_types.MetadataList
+		if err = json.Unmarshal(body, &list); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if len(list.Consumers) == 0 && len(list.Pairs) == 0 {
+			fmt.Println("no consumers or pairs are tagged")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KIND\tCONSUMER/PAIR\tTAGS\tNOTE")
+		for _, m := range list.Consumers {
+			fmt.Fprintf(w, "consumer\t%s\t%s\t%s\n", m.Consumer, strings.Join(m.Tags, ","), m.Note)
+		}
+		for _, m := range list.Pairs {
+			fmt.Fprintf(w, "pair\t%s\t%s\t%s\n", m.Pair, strings.Join(m.Tags, ","), m.Note)
+		}
+		_ = w.Flush()
+	},
+}
+
+// tagsSetCmd represents the tags set command
+var tagsSetCmd = &cobra.Command{
+	Use:   "set [consumer|pair] [ADDRESS|PAIR]",
+	Short: "Set the tags and note of a consumer or pair",
+	Long: `Set the tags and note of a consumer or pair, replacing any already set. Tags are lower case
+letters, digits, and _ . : - characters.
+
+Examples:
+
+  go-ooo tags set consumer 0x1234... --tag acme --tag tier:gold --note "Acme Corp, contact ops@acme.example"
+  go-ooo tags set pair BTC.USD --tag core
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := tagsPath(args[0], args[1])
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		body, statusCode, err := sendApiRequest(pass, "PUT", path, go_ooo_types.Metadata{
+			Tags: tagsFlagTags,
+			Note: tagsFlagNote,
+		})
+		printJobsResponse(body, statusCode, err)
+	},
+}
+
+// tagsDeleteCmd represents the tags delete command
+var tagsDeleteCmd = &cobra.Command{
+	Use:   "delete [consumer|pair] [ADDRESS|PAIR]",
+	Short: "Delete the tags and note of a consumer or pair",
+	Long: `Delete the tags and note of a consumer or pair.
+
+Example:
+
+  go-ooo tags delete pair BTC.USD
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := tagsPath(args[0], args[1])
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		sendJobsRequest("DELETE", path)
+	},
+}
+
+func tagsPath(kind string, subject string) (string, error) {
+	switch strings.ToLower(kind) {
+	case "consumer":
+		return apiV1 + "/tags/consumers/" + url.PathEscape(subject), nil
+	case "pair":
+		return apiV1 + "/tags/pairs/" + url.PathEscape(subject), nil
+	default:
+		return "", fmt.Errorf("unknown kind %q - expected consumer or pair", kind)
+	}
+}
+
+func init() {
+	tagsListCmd.Flags().BoolVar(&tagsFlagJson, "json", false, "output raw JSON")
+	tagsSetCmd.Flags().StringSliceVar(&tagsFlagTags, "tag", []string{}, "tag to set. Repeat, or comma separate, for more than one")
+	tagsSetCmd.Flags().StringVar(&tagsFlagNote, "note", "", "free text note")
+
+	tagsCmd.AddCommand(tagsListCmd)
+	tagsCmd.AddCommand(tagsSetCmd)
+	tagsCmd.AddCommand(tagsDeleteCmd)
+
+	rootCmd.AddCommand(tagsCmd)
+}
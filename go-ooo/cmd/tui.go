@@ -0,0 +1,443 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"go-ooo/webhooks"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const (
+	tuiMaxJobs   = 12
+	tuiMaxEvents = 12
+	tuiMaxErrors = 5
+)
+
+// ANSI escape sequences
+const (
+	ansiAltScreenOn  = "\x1b[?1049h"
+	ansiAltScreenOff = "\x1b[?1049l"
+	ansiHideCursor   = "\x1b[?25l"
+	ansiShowCursor   = "\x1b[?25h"
+	ansiClear        = "\x1b[H\x1b[2J"
+	ansiBold         = "\x1b[1m"
+	ansiRed          = "\x1b[31m"
+	ansiGreen        = "\x1b[32m"
+	ansiYellow       = "\x1b[33m"
+	ansiDefault      = "\x1b[39m"
+	ansiReset        = "\x1b[0m"
+)
+
+var tuiRefresh int
+
+// tuiCmd represents the tui command
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Live terminal dashboard",
+	Long: `Show a live dashboard of the running node in the terminal: chain sync, balances, source
+health, the most recently updated jobs and their progress, incoming job events as they happen,
+and any errors. Press q to quit.
+
+Examples:
+
+  go-ooo tui
+  go-ooo tui --refresh 10
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+			fmt.Println("tui must be run in a terminal")
+			return
+		}
+
+		pass, err := readPassword()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if err = runTui(pass, time.Duration(tuiRefresh)*time.Second); err != nil {
+			fmt.Println(err.Error())
+		}
+	},
+}
+
+func init() {
+	tuiCmd.Flags().IntVar(&tuiRefresh, "refresh", 3, "seconds between status and job refreshes")
+	rootCmd.AddCommand(tuiCmd)
+}
+
+// tuiState is the data shown by the dashboard, updated in the background
+type tuiState struct {
+	mu        sync.Mutex
+	status    *go_ooo_types.NodeStatus
+	jobs      []go_ooo_types.JobSummary
+	events    []webhooks.JobEvent // most recent first
+	errors    []string            // most recent first
+	connected bool
+	updated   time.Time
+	changed   chan struct{}
+}
+
+func (t *tuiState) notify() {
+	select {
+	case t.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (t *tuiState) addError(err string) {
+	t.mu.Lock()
+	t.errors = prepend(t.errors, fmt.Sprintf("%s  %s", time.Now().Format("15:04:05"), err), tuiMaxErrors)
+	t.mu.Unlock()
+	t.notify()
+}
+
+func prepend(list []string, s string, max int) []string {
+	list = append([]string{s}, list...)
+	if len(list) > max {
+		list = list[:max]
+	}
+	return list
+}
+
+func runTui(pass string, refresh time.Duration) error {
+	if refresh <= 0 {
+		refresh = 3 * time.Second
+	}
+
+	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(os.Stdin.Fd()), oldState)
+
+	fmt.Print(ansiAltScreenOn + ansiHideCursor)
+	defer fmt.Print(ansiShowCursor + ansiAltScreenOff)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	state := &tuiState{changed: make(chan struct{}, 1)}
+
+	go tuiPoll(ctx, pass, refresh, state)
+	go tuiStreamEvents(ctx, pass, state)
+
+	quit := make(chan struct{})
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if _, err := os.Stdin.Read(buf); err != nil {
+				close(quit)
+				return
+			}
+			// q, Q, ctrl-c or ctrl-d
+			if buf[0] == 'q' || buf[0] == 'Q' || buf[0] == 3 || buf[0] == 4 {
+				close(quit)
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		renderTui(state)
+		select {
+		case <-quit:
+			return nil
+		case <-state.changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+// tuiPoll refreshes the node status and recent jobs until ctx is done
+func tuiPoll(ctx context.Context, pass string, refresh time.Duration, state *tuiState) {
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	for {
+		var status go_ooo_types.NodeStatus
+		statusErr := tuiGet(pass, apiV1+"/status", &status)
+
+		var jobs []go_ooo_types.JobSummary
+		jobsErr := tuiGet(pass, fmt.Sprintf(apiV1+"/jobs?limit=%d", tuiMaxJobs), &jobs)
+
+		state.mu.Lock()
+		if statusErr == nil {
+			state.status = &status
+		}
+		if jobsErr == nil {
+			state.jobs = jobs
+		}
+		if statusErr == nil && jobsErr == nil {
+			state.updated = time.Now()
+		}
+		state.mu.Unlock()
+
+		if statusErr != nil {
+			state.addError(fmt.Sprintf("status: %s", statusErr.Error()))
+		}
+		if jobsErr != nil {
+			state.addError(fmt.Sprintf("jobs: %s", jobsErr.Error()))
+		}
+		state.notify()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func tuiGet(pass string, path string, v interface{}) error {
+	body, statusCode, err := sendApiRequest(pass, "GET", path, nil)
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", http.StatusText(statusCode), strings.Trim(strings.TrimSpace(string(body)), `"`))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// tuiStreamEvents follows the node's job event stream until ctx is done, reconnecting if it drops
+func tuiStreamEvents(ctx context.Context, pass string, state *tuiState) {
+	for {
+		err := tuiReadEvents(ctx, pass, state)
+
+		state.mu.Lock()
+		state.connected = false
+		state.mu.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			state.addError(fmt.Sprintf("event stream: %s", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func tuiReadEvents(ctx context.Context, pass string, state *tuiState) error {
+	req, err := newApiRequest(pass, "GET", apiV1+"/jobs/events", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s", resp.Status)
+	}
+
+	state.mu.Lock()
+	state.connected = true
+	state.mu.Unlock()
+	state.notify()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var ev webhooks.JobEvent
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev); err != nil {
+			continue
+		}
+
+		state.mu.Lock()
+		state.events = append([]webhooks.JobEvent{ev}, state.events...)
+		if len(state.events) > tuiMaxEvents {
+			state.events = state.events[:tuiMaxEvents]
+		}
+		state.mu.Unlock()
+
+		if ev.Event == webhooks.EventFailed {
+			state.addError(fmt.Sprintf("request %s failed: %s", shortId(ev.RequestId), ev.StatusReason))
+		}
+		state.notify()
+	}
+
+	return scanner.Err()
+}
+
+func renderTui(state *tuiState) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 120, 40
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	var out bytes.Buffer
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+
+	updated := "never"
+	if !state.updated.IsZero() {
+		updated = state.updated.Format("15:04:05")
+	}
+	stream := ansiRed + "disconnected" + ansiReset
+	if state.connected {
+		stream = ansiGreen + "live" + ansiReset
+	}
+
+	if s := state.status; s != nil {
+		leader := "leader"
+		if !s.Leader {
+			leader = "standby"
+		}
+		paused := ""
+		if s.Paused != "" {
+			paused = ansiYellow + "  PAUSED: " + s.Paused + ansiReset
+		}
+		if s.Maintenance != nil {
+			paused += ansiYellow + "  MAINTENANCE" + ansiReset
+		}
+		fmt.Fprintf(w, "%sgo-ooo%s  %s  %s%s  updated %s  events %s\n", ansiBold, ansiReset, s.Version, leader, paused, updated, stream)
+		fmt.Fprintf(w, "\n")
+		fmt.Fprintf(w, "Chain head %d\tprocessed %d (%d behind)\tpending jobs %d\tworkers %d\n",
+			s.CurrentBlock, s.LastBlock, int64(s.CurrentBlock)-int64(s.LastBlock), s.PendingJobs, s.Workers)
+		fmt.Fprintf(w, "Wallet %s ETH\t%s xFUND\twithdrawable %s xFUND\t\n",
+			formatUnits(s.WalletBalance, params.Ether), formatUnits(s.XfundBalance, params.GWei),
+			formatUnits(s.WithdrawableFees, params.GWei))
+
+		var sources []string
+		for _, src := range s.Sources {
+			colour := ansiGreen
+			if !src.Healthy {
+				colour = ansiRed
+			}
+			sources = append(sources, colour+src.Name+ansiReset)
+		}
+		fmt.Fprintf(w, "Sources %s\n", strings.Join(sources, " "))
+	} else {
+		fmt.Fprintf(w, "%sgo-ooo%s  connecting...  events %s\n", ansiBold, ansiReset, stream)
+	}
+
+	fmt.Fprintf(w, "\n%sRECENT JOBS%s\n", ansiBold, ansiReset)
+	fmt.Fprintln(w, "REQUEST\tENDPOINT\tSTATUS\tATTEMPTS\tUPDATED\tREASON")
+	for _, j := range state.jobs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", shortId(j.RequestId), j.Endpoint,
+			colourStatus(j.RequestStatus), j.FulfillmentAttempts,
+			time.Unix(j.UpdatedAt, 0).Format("15:04:05"), j.StatusReason)
+	}
+
+	fmt.Fprintf(w, "\n%sLIVE EVENTS%s\n", ansiBold, ansiReset)
+	for _, ev := range state.events {
+		detail := ev.Price
+		if ev.StatusReason != "" {
+			detail = ev.StatusReason
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", time.Unix(ev.Timestamp, 0).Format("15:04:05"),
+			colourEvent(ev.Event), shortId(ev.RequestId), ev.Endpoint, detail)
+	}
+
+	fmt.Fprintf(w, "\n%sERRORS%s\n", ansiBold, ansiReset)
+	for _, e := range state.errors {
+		fmt.Fprintf(w, "%s%s%s\n", ansiRed, e, ansiReset)
+	}
+
+	_ = w.Flush()
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if len(lines) > height-2 {
+		lines = lines[:height-2]
+	}
+
+	var screen strings.Builder
+	screen.WriteString(ansiClear)
+	for _, line := range lines {
+		screen.WriteString(truncateAnsi(line, width))
+		screen.WriteString(ansiReset + "\r\n")
+	}
+	screen.WriteString("\r\nq quit")
+
+	fmt.Print(screen.String())
+}
+
+// truncateAnsi truncates line to width visible characters, ignoring escape sequences
+func truncateAnsi(line string, width int) string {
+	visible := 0
+	inEscape := false
+	for i, r := range line {
+		switch {
+		case r == '\x1b':
+			inEscape = true
+		case inEscape:
+			if r == 'm' {
+				inEscape = false
+			}
+		default:
+			visible++
+			if visible > width {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+func shortId(id string) string {
+	if utf8.RuneCountInString(id) <= 14 {
+		return id
+	}
+	return id[:8] + "…" + id[len(id)-4:]
+}
+
+// colourStatus colours a request status. Every status is wrapped in escape sequences of the
+// same length, so that tabwriter aligns the columns after it
+func colourStatus(status string) string {
+	switch {
+	case status == "SUCCESS":
+		return ansiGreen + status + ansiReset
+	case strings.Contains(status, "FAILED") || status == "TIMEOUT" || status == "DEAD":
+		return ansiRed + status + ansiReset
+	case strings.HasPrefix(status, "SKIPPED") || status == "DUPLICATE" || status == "REJECTED":
+		return ansiYellow + status + ansiReset
+	default:
+		return ansiDefault + status + ansiReset
+	}
+}
+
+func colourEvent(event string) string {
+	switch event {
+	case webhooks.EventFulfilled:
+		return ansiGreen + event + ansiReset
+	case webhooks.EventFailed:
+		return ansiRed + event + ansiReset
+	case webhooks.EventSkipped:
+		return ansiYellow + event + ansiReset
+	default:
+		return ansiDefault + event + ansiReset
+	}
+}
@@ -1,18 +1,42 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/httpclient"
+	go_ooo_types "go-ooo/types"
 	"go-ooo/version"
 	"os"
+	"time"
 )
 
-// startCmd represents the start command
+var (
+	versionNode  bool
+	versionCheck bool
+	versionJson  bool
+)
+
+// versionCmd represents the version command
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Displays the current app version info",
+	Long: `Display the version, git commit and build date of this binary.
+
+With --node, the version of the running node is shown instead, along with the result
+of its last update check. With --check, the release endpoint is queried for a newer
+version.
+
+Examples:
+
+  go-ooo version
+  go-ooo version --check
+  go-ooo version --node --json
+`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		if _, err := os.Stat(viper.ConfigFileUsed()); errors.Is(err, os.ErrNotExist) {
 			fmt.Println(viper.ConfigFileUsed(), "does not exist. please run 'go-ooo init'")
@@ -20,21 +44,92 @@ var versionCmd = &cobra.Command{
 		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println(version.NewInfo())
-		return
+		if versionNode {
+			printNodeVersion()
+			return
+		}
+
+		info := version.NewInfo()
+		if machineOutput(versionJson) {
+			body, _ := json.Marshal(info)
+			printJSON(body)
+		} else {
+			fmt.Println(info)
+		}
+
+		if versionCheck {
+			checkLatestRelease(info.Version)
+		}
 	},
 }
 
 func init() {
+	versionCmd.Flags().BoolVar(&versionNode, "node", false, "show the version of the running node")
+	versionCmd.Flags().BoolVar(&versionCheck, "check", false, "check the release endpoint for a newer version")
+	versionCmd.Flags().BoolVar(&versionJson, "json", false, "output raw JSON")
 	rootCmd.AddCommand(versionCmd)
+}
 
-	// Here you will define your flags and configuration settings.
+func printNodeVersion() {
+	pass, err := readPassword()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
 
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// startCmd.PersistentFlags().String("foo", "", "A help for foo")
+	body, statusCode, err := sendApiRequest(pass, "GET", apiV1+"/version", nil)
+	if err != nil || statusCode != 200 {
+		printJobsResponse(body, statusCode, err)
+		return
+	}
+
+	if machineOutput(versionJson) {
+		printJSON(body)
+		return
+	}
+
+	var res go_ooo_types.VersionResponse
+	if err = json.Unmarshal(body, &res); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fmt.Println(res.Info)
+	fmt.Println("")
+
+	switch {
+	case !res.UpdateCheckEnabled:
+		fmt.Println("update check disabled")
+	case res.CheckError != "":
+		fmt.Println("update check failed:", res.CheckError)
+	case res.UpdateAvailable:
+		fmt.Printf("v%s is available: %s\n", res.LatestVersion, res.ReleaseUrl)
+	case res.LatestVersion != "":
+		fmt.Println("up to date")
+	default:
+		fmt.Println("not checked yet")
+	}
+}
+
+func checkLatestRelease(current string) {
+	url := viper.GetString(config.UpdateCheckUrl)
+	if url == "" {
+		url = version.DefaultReleaseUrl
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	latest, err := version.LatestRelease(ctx, httpclient.New(0), url)
+	if err != nil {
+		fmt.Println("update check failed:", err.Error())
+		return
+	}
 
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// startCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	fmt.Println("")
+	if version.IsNewer(latest.Version, current) {
+		fmt.Printf("v%s is available: %s\n", latest.Version, latest.Url)
+	} else {
+		fmt.Printf("up to date - latest release is v%s\n", latest.Version)
+	}
 }
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"strconv"
+)
+
+// vorCmd represents the vor command
+var vorCmd = &cobra.Command{
+	Use:   "vor",
+	Short: "VOR randomness provider sub-commands",
+	Long: `VOR randomness provider sub-commands. VOR fulfillment is enabled by setting
+chain.vor_coordinator_address in the config. The oracle key is used as the VOR proving key.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("run one of the sub-commands. See 'go-ooo vor --help'")
+	},
+}
+
+// vorRegisterCmd represents the vor register command
+var vorRegisterCmd = &cobra.Command{
+	Use:   "register <fee>",
+	Short: "Register your proving key with the VORCoordinator",
+	Long: `Register your proving key as a new VOR randomness provider.
+
+You must specify a fee, and this must be 10 ^ 9. For example, 0.01 xFUND will be:
+
+  10000000
+
+Examples:
+
+  go-ooo vor register 1000000
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		adminTask := go_ooo_types.AdminTask{}
+
+		fee, _ := strconv.ParseInt(args[0], 10, 64)
+		adminTask.Task = "vor_register"
+		adminTask.FeeOrAmount = uint64(fee)
+
+		processAdminTask(adminTask)
+	},
+}
+
+// vorKeyCmd represents the vor key command
+var vorKeyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Show your VOR key hash and public proving key",
+	Run: func(cmd *cobra.Command, args []string) {
+		adminTask := go_ooo_types.AdminTask{}
+		adminTask.Task = "vor_key"
+		processAdminTask(adminTask)
+	},
+}
+
+func init() {
+	vorCmd.AddCommand(vorRegisterCmd)
+	vorCmd.AddCommand(vorKeyCmd)
+	rootCmd.AddCommand(vorCmd)
+}
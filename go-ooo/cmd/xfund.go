@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	go_ooo_types "go-ooo/types"
+	"strconv"
+)
+
+// xfundCmd represents the xfund command
+var xfundCmd = &cobra.Command{
+	Use:   "xfund",
+	Short: "Manage the oracle key's xFUND token approvals",
+	Long: `Query and manage the xFUND allowances the oracle key has approved. Spenders can be
+given as an address, or as router or vor for the Router and VORCoordinator contracts.
+
+The router, the VORCoordinator if VOR is enabled, and any addresses in chain.xfund_spenders
+are monitored, and their allowances exported as the ooo_xfund_allowance metric.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("run one of the sub-commands. See 'go-ooo admin xfund --help'")
+	},
+}
+
+// xfundAllowanceCmd represents the xfund allowance command
+var xfundAllowanceCmd = &cobra.Command{
+	Use:   "allowance",
+	Short: "Show the oracle key's xFUND allowance for each monitored spender",
+	Run: func(cmd *cobra.Command, args []string) {
+		adminTask := go_ooo_types.AdminTask{}
+		adminTask.Task = "xfund_allowance"
+		processAdminTask(adminTask)
+	},
+}
+
+// xfundApproveCmd represents the xfund approve command
+var xfundApproveCmd = &cobra.Command{
+	Use:   "approve <amount> <spender>",
+	Short: "Approve a spender to transfer the oracle key's xFUND",
+	Long: `Set the amount of xFUND the spender may transfer from the oracle key, replacing any
+previous approval.
+
+The amount must be 10 ^ 9. For example, 1 xFUND will be:
+
+  1000000000
+
+Examples:
+
+  go-ooo admin xfund approve 1000000000 router
+  go-ooo admin xfund approve 1000000000 0x12345abcde...`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		amount, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			fmt.Println("amount must be a whole number of the smallest xFUND unit")
+			return
+		}
+
+		adminTask := go_ooo_types.AdminTask{}
+		adminTask.Task = "xfund_approve"
+		adminTask.FeeOrAmount = amount
+		adminTask.ToOrConsumer = args[1]
+
+		processAdminTask(adminTask)
+	},
+}
+
+// xfundRevokeCmd represents the xfund revoke command
+var xfundRevokeCmd = &cobra.Command{
+	Use:   "revoke <spender>",
+	Short: "Revoke a spender's approval to transfer the oracle key's xFUND",
+	Long: `Set the spender's xFUND allowance from the oracle key to 0.
+
+Examples:
+
+  go-ooo admin xfund revoke vor`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		adminTask := go_ooo_types.AdminTask{}
+		adminTask.Task = "xfund_approve"
+		adminTask.FeeOrAmount = 0
+		adminTask.ToOrConsumer = args[0]
+
+		processAdminTask(adminTask)
+	},
+}
+
+func init() {
+	xfundCmd.AddCommand(xfundAllowanceCmd)
+	xfundCmd.AddCommand(xfundApproveCmd)
+	xfundCmd.AddCommand(xfundRevokeCmd)
+	adminCmd.AddCommand(xfundCmd)
+}
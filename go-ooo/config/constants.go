@@ -1,7 +1,259 @@
 package config
 
 const JobsOooApiUrl = "jobs.ooo_api_url"
+
+// JobsOooApiUrlSecondary optional Finchains API url used if the primary is unavailable
+const JobsOooApiUrlSecondary = "jobs.ooo_api_url_secondary"
+
+// JobsForexApiUrl ECB/openexchangerates style FX rates API, used to price ad-hoc requests in fiat
+const JobsForexApiUrl = "jobs.forex_api_url"
+
+// JobsStablecoinRates how DEX prices quoted in USD stablecoins are converted to USD - "market", at
+// each stablecoin's Finchains USD price, or "par", at exactly 1 USD. Defaults to market
+const JobsStablecoinRates = "jobs.stablecoin_rates"
+
+// JobsStablecoinMaxDepeg percent a stablecoin's USD rate may deviate from 1 before prices quoted
+// in it are rejected. Defaults to 2. 0 disables the check
+const JobsStablecoinMaxDepeg = "jobs.stablecoin_max_depeg"
+
+// JobsAnswerDecimals decimals used to scale submitted answers. Defaults to 18
+const JobsAnswerDecimals = "jobs.answer_decimals"
+
+// JobsAnswerRounding how digits beyond jobs.answer_decimals are rounded - "floor" (default),
+// "half_even" or "ceiling". The mode is recorded with each answer
+const JobsAnswerRounding = "jobs.answer_rounding"
+
+// JobsConsumerSourcePolicies sources allowed to answer each consumer contract's requests, keyed by
+// consumer address - "cex", "dex" or "any" (default). The policy is recorded with each answer
+const JobsConsumerSourcePolicies = "jobs.consumer_source_policies"
+
+// JobsSourceTiers sources in each degradation tier, keyed by "primary", "secondary" or
+// "emergency", e.g. secondary = ["twap"]. Prices are aggregated from the primary sources, falling
+// back tier by tier while none can answer. Sources not listed are primary. The tier is recorded
+// with each answer
+const JobsSourceTiers = "jobs.source_tiers"
+
+// JobsAdhocDMax Chauvenet criterion dMax used to remove outliers from ad-hoc prices. Defaults to 3
+const JobsAdhocDMax = "jobs.adhoc_dmax"
+
+// JobsExactMath aggregate prices with exact rationals, rather than float64 intermediate values,
+// for providers answering high value pairs. Subgraph prices are not rounded to float64 precision,
+// nor rejected for being outside the float64 range
+const JobsExactMath = "jobs.exact_math"
+
+// JobsPairSourcesFile optional toml, yaml or json file of per-pair source include/exclude overrides. Reloaded on change
+const JobsPairSourcesFile = "jobs.pair_sources_file"
+
+// JobsSourceRetries retries of a data source query failing with a transient error, such as a DNS
+// failure, timeout or 5xx response. Defaults to 2. 0 disables retries
+const JobsSourceRetries = "jobs.source_retries"
+
+// JobsSourceExcludeDuration seconds a data source is not used for a pair after a permanent error,
+// such as a 404 for the pair or a response not matching the expected schema. Defaults to 3600.
+// 0 disables exclusions
+const JobsSourceExcludeDuration = "jobs.source_exclude_duration"
+
+// JobsDelistingLookback days of requests checked for the consumers of a pair Finchains stops
+// listing, who are alerted so that the operator can warn them. Defaults to 30
+const JobsDelistingLookback = "jobs.delisting_lookback"
+
+// JobsMockSources answer every request with mock prices instead of querying any data source,
+// for local development. Never enable on a live network
+const JobsMockSources = "jobs.mock_sources"
+
+// JobsMockPricesFile optional toml, yaml or json file of fixed or scripted mock prices per pair. Reloaded on change
+const JobsMockPricesFile = "jobs.mock_prices_file"
+
+// JobsLiquidityAlertThreshold USD liquidity below which an actively answered DEX pair raises an alert
+const JobsLiquidityAlertThreshold = "jobs.liquidity_alert_threshold"
+
+// JobsMinVolume24h USD trade volume over 24 hours below which a DEX pair's prices are not used,
+// however much liquidity it has, since an idle pool's price can be stale. 0 disables the check
+const JobsMinVolume24h = "jobs.min_volume_24h"
+
+// JobsDexImpactNotional USD size of the trade whose price impact is estimated for each DEX pool
+// price, and recorded with the request's sources. Defaults to 10000
+const JobsDexImpactNotional = "jobs.dex_impact_notional"
+
+// JobsDexMaxPriceImpact percent price impact of a jobs.dex_impact_notional trade above which a DEX
+// pool's price is not used. 0 (default) records the impact without excluding any pools
+const JobsDexMaxPriceImpact = "jobs.dex_max_price_impact"
+
+// JobsTokenPins token contract addresses to use for a symbol shared by several contracts on a DEX,
+// keyed by symbol, e.g. USDT = ["0xdac17f958d2ee523a2206206994597c13d831ec7"]. Symbols which are not
+// pinned resolve to the contract with the most liquidity, then volume, across its pairs
+const JobsTokenPins = "jobs.token_pins"
+
+// JobsDexIncrementalSync only fetch the DEX pairs changed since the last sync, tracked by subgraph
+// block, rather than every pair, on each sync. Defaults to true
+const JobsDexIncrementalSync = "jobs.dex_incremental_sync"
+
+// JobsDexFullSyncInterval seconds between syncs of every DEX pair when syncing incrementally, which
+// also refresh the 24 hour volumes of pairs that have not traded. Defaults to 86400. 0 only
+// fetches every pair when the DEX has never been synced
+const JobsDexFullSyncInterval = "jobs.dex_full_sync_interval"
+
+// JobsSubgraphUrls optional table of subgraph urls by DEX name, e.g. sushiswap_polygon, replacing
+// the default hosted service urls, e.g. with a Graph gateway url whose api key is set as
+// {credential:NAME}. An empty url disables the DEX
+const JobsSubgraphUrls = "jobs.subgraph_urls"
+
+// JobsSubgraphMonthlyBudget total cost subgraph queries may spend each UTC month, e.g. in GRT or
+// API credits. 0, the default, is unlimited. Spend is tracked whether or not a budget is set
+const JobsSubgraphMonthlyBudget = "jobs.subgraph_monthly_budget"
+
+// JobsSubgraphQueryCost cost of a subgraph query, in the units of jobs.subgraph_monthly_budget
+const JobsSubgraphQueryCost = "jobs.subgraph_query_cost"
+
+// JobsSubgraphQueryCosts optional table of query costs by DEX name, overriding jobs.subgraph_query_cost,
+// e.g. 0 for a self hosted subgraph
+const JobsSubgraphQueryCosts = "jobs.subgraph_query_costs"
+
+// JobsSubgraphSyncBudgetShare percent of jobs.subgraph_monthly_budget token and pair syncs, liquidity
+// and health checks may spend before they are throttled, leaving the rest for price queries. Defaults to 80
+const JobsSubgraphSyncBudgetShare = "jobs.subgraph_sync_budget_share"
+
+// JobsSubgraphBatchWindow milliseconds a DEX pair price query waits for queries for other pairs to the
+// same subgraph, at the same block, to be sent with it in one query. 0 sends each pair's query alone
+const JobsSubgraphBatchWindow = "jobs.subgraph_batch_window"
+
+// JobsSubgraphBatchSize most pairs queried in one batched subgraph price query. Defaults to 10
+const JobsSubgraphBatchSize = "jobs.subgraph_batch_size"
+
+// JobsBlendDexSources blend the Finchains price of a supported pair with the mean of its DEX
+// prices, when the pair's symbols are also listed on a DEX, rather than answering from Finchains alone
+const JobsBlendDexSources = "jobs.blend_dex_sources"
+
+// JobsBlendDexWeight share, 0 - 1, of a blended answer taken from the DEX mean. Defaults to 0.5
+const JobsBlendDexWeight = "jobs.blend_dex_weight"
+
+// JobsBlendMaxDeviation percent the DEX mean may differ from the Finchains price for the two to be
+// blended. Beyond it, the Finchains price is used alone. 0 disables the check
+const JobsBlendMaxDeviation = "jobs.blend_max_deviation"
+
+// JobsJsonFeeds array of operator configured generic JSON feeds, queried with the JS request type
+const JobsJsonFeeds = "jobs.json_feeds"
+
+// JobsTwapPools array of Uniswap V3 pools whose built in TWAP oracle is read on chain, via the
+// subchain RPCs, as a source for ad-hoc requests
+const JobsTwapPools = "jobs.twap_pools"
+
+// JobsCustomPairs array of operator defined DEX pairs, each mapped to a pair or pool contract and
+// its token contracts on a subgraph DEX, which answer ad-hoc requests for long tail pairs before,
+// or in place of, pair discovery
+const JobsCustomPairs = "jobs.custom_pairs"
+
+// JobsPriceBounds array of per-pair absolute price ranges. Source values outside a pair's range
+// are discarded, and an answer outside it is not submitted, and alerted
+const JobsPriceBounds = "jobs.price_bounds"
+
+// JobsWorkers number of worker goroutines used to process pending jobs concurrently. Defaults to 4
+const JobsWorkers = "jobs.workers"
+
+// JobsMaxAttempts number of failed attempts after which a job is dead lettered. Defaults to 3
+const JobsMaxAttempts = "jobs.max_attempts"
+
+// JobsRetryBackoff base delay, in seconds, before retrying a failed job. Doubled on each attempt
+const JobsRetryBackoff = "jobs.retry_backoff"
+
+// JobsRetryBackoffMax maximum delay, in seconds, between retries of a failed job
+const JobsRetryBackoffMax = "jobs.retry_backoff_max"
+
+// JobsTimeout end-to-end timeout, in seconds, for processing a single job. Defaults to 120
+const JobsTimeout = "jobs.timeout"
+
+// JobsMinFee minimum request fee, in xFUND's smallest unit, below which requests are skipped. 0 disables
+const JobsMinFee = "jobs.min_fee"
+
+// JobsConsumerRateLimit max fulfillments per hour for a single consumer contract. 0 disables
+const JobsConsumerRateLimit = "jobs.consumer_rate_limit"
+
+// JobsSubmissionRate max fulfillment txs broadcast per second, spacing out bursts of requests. 0 disables
+const JobsSubmissionRate = "jobs.submission_rate"
+
+// JobsMaxInFlight max fulfillment txs broadcast but not yet seen fulfilled on chain. Further txs
+// wait until some are mined. 0 disables
+const JobsMaxInFlight = "jobs.max_in_flight"
+
+// JobsAnswerDelayMax max random delay, in milliseconds, before a fulfillment tx is submitted, so
+// that searchers cannot time txs around the provider's oracle updates. Bounded by the job
+// timeout, and not applied to requests about to be abandoned. 0 disables
+const JobsAnswerDelayMax = "jobs.answer_delay_max"
+
+// JobsAnswerDelays array of per-pair max answer delays, in place of JobsAnswerDelayMax
+const JobsAnswerDelays = "jobs.answer_delays"
+
+// JobsPriceFreshness seconds after a price is fetched beyond which it is fetched again, rather
+// than resubmitted, when a fulfillment tx reverts or is replaced. Defaults to 60. 0 disables
+const JobsPriceFreshness = "jobs.price_freshness"
+
+// JobsGasEscalationBlocks blocks before a request would be abandoned as too old from which its
+// fulfillment tx's gas price is escalated, rising linearly to JobsGasEscalationMaxMultiplier
+// times the suggested price, within chain.max_gas_price. Pending txs are replaced at the
+// escalated price. 0 disables
+const JobsGasEscalationBlocks = "jobs.gas_escalation_blocks"
+
+// JobsGasEscalationMaxMultiplier multiple of the suggested gas price paid for a request's
+// fulfillment tx at its deadline. Defaults to 3
+const JobsGasEscalationMaxMultiplier = "jobs.gas_escalation_max_multiplier"
+
+// JobsReconcileHour UTC hour, 0 - 23, at which the db's view of the day's requests and the fee
+// balance are reconciled against the router's on-chain state. -1 disables the nightly run
+const JobsReconcileHour = "jobs.reconcile_hour"
+
+// JobsReconcileLookback hours of received requests whose status is reconciled. Defaults to 24
+const JobsReconcileLookback = "jobs.reconcile_lookback"
+
+// JobsReconcileFix correct the db when the nightly reconciliation finds requests fulfilled on chain
+// but not in the db, or vice versa, rather than only reporting them
+const JobsReconcileFix = "jobs.reconcile_fix"
+
+// JobsConsumerRateLimitAction "defer" (default) leaves over limit requests pending, "skip" skips them
+const JobsConsumerRateLimitAction = "jobs.consumer_rate_limit_action"
+
+// JobsProfitabilityCheck skip or defer requests whose fee does not cover the estimated fulfillment tx cost
+const JobsProfitabilityCheck = "jobs.profitability_check"
+
+// JobsProfitabilityMargin percentage added to the estimated fulfillment cost a fee must cover. Defaults to 10
+const JobsProfitabilityMargin = "jobs.profitability_margin"
+
+// JobsProfitabilityAction "defer" (default) leaves unprofitable requests pending until gas prices fall, "skip" skips them
+const JobsProfitabilityAction = "jobs.profitability_action"
+
+// JobsProfitabilityPriceEndpoint endpoint answering the price of 1 xFUND in the chain's native token. Defaults to XFUND.ETH.AD
+const JobsProfitabilityPriceEndpoint = "jobs.profitability_price_endpoint"
+
+// JobsStuckThreshold seconds after which a job still fetching data is considered stuck and revived. 0 disables
+const JobsStuckThreshold = "jobs.stuck_threshold"
+
+// JobsCoalesceWindow seconds for which a fetched price is shared with new requests for the same endpoint. 0 only shares in-flight fetches
+const JobsCoalesceWindow = "jobs.coalesce_window"
+
+// JobsLatencySlo target time, in seconds, from a request event being seen to its fulfillment being mined
+const JobsLatencySlo = "jobs.latency_slo"
+
+// JobsBackpressureHighWater number of unsent pending jobs at which event ingestion is suspended. 0 disables
+const JobsBackpressureHighWater = "jobs.backpressure_high_water"
+
+// JobsBackpressureLowWater number of unsent pending jobs below which suspended ingestion resumes
+const JobsBackpressureLowWater = "jobs.backpressure_low_water"
+
+// JobsBackpressureDbLatency database response time, in milliseconds, above which event ingestion is suspended
+const JobsBackpressureDbLatency = "jobs.backpressure_db_latency"
+
+// JobsServePairs pairs, e.g. ["BTC.USD"], or patterns, e.g. ["BTC.*", "*.USD"], the node answers
+// price and ad-hoc requests for. Requests for other pairs are rejected. Empty answers every pair
+const JobsServePairs = "jobs.serve_pairs"
+
+// JobsExcludePairs pairs or patterns the node never answers, even if matched by jobs.serve_pairs
+const JobsExcludePairs = "jobs.exclude_pairs"
+
 const JobsCheckDuration = "jobs.check_duration"
+
+// JobsCheckDurationMax seconds the job queue check interval backs off to while there are no pending jobs.
+// New requests return it to jobs.check_duration. Adaptive polling is disabled if not above jobs.check_duration
+const JobsCheckDurationMax = "jobs.check_duration_max"
+
 const JobsWaitConfirmations = "jobs.wait_confirmations"
 
 const ServeHost = "serve.host"
@@ -10,7 +262,141 @@ const ServePort = "serve.port"
 const KeystorageFile = "keystorage.file"
 const KeystorageAccount = "keystorage.account"
 
+// KeystorageAccounts assigns keystore accounts by chain network id or contract address, overriding
+// KeystorageAccount, so one config can serve several chains, e.g. { "1" = "mainnet", "0x..." = "vor" }.
+// A router address is matched before the network id. A VOR coordinator address gives VOR its own key
+const KeystorageAccounts = "keystorage.accounts"
+
+// KeystorageRetiringAccount previous account during a key rotation, set by 'go-ooo keys rotate'. Requests
+// addressed to it are still fulfilled with it until 'go-ooo keys retire' is run
+const KeystorageRetiringAccount = "keystorage.retiring_account"
+
+// KeystorageRetiringUntil unix time at which the rotation grace window ends. After it, new requests to the
+// retiring account are rejected
+const KeystorageRetiringUntil = "keystorage.retiring_until"
+
+// KeystoragePasswordCommand optional command, run with sh -c, which prints the keystore password, e.g. a
+// secrets manager CLI. Used if no --pass is given, before KeystoragePasswordFile and KeystoragePasswordEnv
+const KeystoragePasswordCommand = "keystorage.password_command"
+
+// KeystoragePasswordFile optional file holding the keystore password, e.g. a K8s or Docker secret
+const KeystoragePasswordFile = "keystorage.password_file"
+
+// KeystoragePasswordEnv environment variable holding the keystore password. Defaults to GO_OOO_KEYSTORE_PASSWORD.
+// If no source gives a password, it is read from Vault if configured, or prompted for
+const KeystoragePasswordEnv = "keystorage.password_env"
+
+// SignerBackend keystore (default) signs with the key decrypted from the keystore. vault signs
+// with the key read from VaultPrivateKeyField. aws_kms and gcp_kms sign with an AWS KMS or Google
+// Cloud KMS/HSM key, so the private key is never on the host. ledger signs with a Ledger hardware
+// wallet, on which every fulfillment must be confirmed. The keystore is still used for the API
+// token. VOR is not available with aws_kms, gcp_kms or ledger. Generic PKCS#11 HSMs are not
+// supported - hardware-held keys are available through Cloud HSM with gcp_kms, or CloudHSM-backed
+// keys with aws_kms
+const SignerBackend = "signer.backend"
+
+// SignerAwsKmsKeyId key id, ARN or alias of an ECC_SECG_P256K1 SIGN_VERIFY KMS key
+const SignerAwsKmsKeyId = "signer.aws_kms_key_id"
+
+// SignerAwsRegion AWS region of the KMS key
+const SignerAwsRegion = "signer.aws_region"
+
+// SignerAwsKmsEndpoint optional KMS endpoint, e.g. a VPC endpoint
+const SignerAwsKmsEndpoint = "signer.aws_kms_endpoint"
+
+// SignerAwsAccessKeyId optional static AWS credentials. If unset, the AWS_* environment variables
+// or the instance/task role are used
+const SignerAwsAccessKeyId = "signer.aws_access_key_id"
+const SignerAwsSecretAccessKey = "signer.aws_secret_access_key"
+const SignerAwsSessionToken = "signer.aws_session_token"
+
+// SignerGcpKmsKeyVersion resource name of an EC_SIGN_SECP256K1_SHA256 Cloud KMS key version -
+// projects/*/locations/*/keyRings/*/cryptoKeys/*/cryptoKeyVersions/*
+const SignerGcpKmsKeyVersion = "signer.gcp_kms_key_version"
+
+// SignerGcpCredentialsFile optional service account key file. If unset, GOOGLE_APPLICATION_CREDENTIALS
+// or the GCE metadata server is used
+const SignerGcpCredentialsFile = "signer.gcp_credentials_file"
+
+// SignerGcpKmsEndpoint optional Cloud KMS endpoint, e.g. a Private Service Connect endpoint
+const SignerGcpKmsEndpoint = "signer.gcp_kms_endpoint"
+
+// SignerLedgerPath BIP-32 derivation path of the oracle's account on the Ledger. Defaults to m/44'/60'/0'/0/0
+const SignerLedgerPath = "signer.ledger_path"
+
+// SignerLedgerAutoApproveGasPrice gas price, in gwei, above which fulfillment txs are held until approved
+// with 'go-ooo admin ledger approve', before being confirmed on the Ledger. Txs at or below it go straight
+// to the device. 0 sends every tx straight to the device
+const SignerLedgerAutoApproveGasPrice = "signer.ledger_auto_approve_gas_price"
+
+// SignerLedgerApprovalTimeout seconds a held tx waits for approval before the fulfillment fails and is retried
+const SignerLedgerApprovalTimeout = "signer.ledger_approval_timeout"
+
+// VaultAddress HashiCorp Vault address, e.g. https://vault.example.com:8200. Empty disables Vault
+const VaultAddress = "vault.address"
+
+// VaultNamespace optional Vault Enterprise namespace
+const VaultNamespace = "vault.namespace"
+
+// VaultToken token auth. If empty, VAULT_TOKEN is used unless AppRole auth is configured
+const VaultToken = "vault.token"
+
+// VaultRoleId and VaultSecretId AppRole auth. VaultSecretIdFile may be used instead of VaultSecretId
+const VaultRoleId = "vault.role_id"
+const VaultSecretId = "vault.secret_id"
+const VaultSecretIdFile = "vault.secret_id_file"
+
+// VaultAppRoleMount path the AppRole auth method is mounted at
+const VaultAppRoleMount = "vault.approle_mount"
+
+// VaultKvMount mount path of the KV version 2 secrets engine holding the node's secret
+const VaultKvMount = "vault.kv_mount"
+
+// VaultSecretPath path of the node's secret within VaultKvMount
+const VaultSecretPath = "vault.secret_path"
+
+// VaultPrivateKeyField field of the secret holding the hex encoded oracle private key, used
+// with the vault signer backend
+const VaultPrivateKeyField = "vault.private_key_field"
+
+// VaultPasswordField field of the secret holding the keystore password, used if no --pass is given
+const VaultPasswordField = "vault.password_field"
+
+// VaultDatabaseKeyField field of the secret holding the database encryption key, used if
+// DatabaseEncryptionKey is not set
+const VaultDatabaseKeyField = "vault.database_key_field"
+
+// ChainGasLimit gas limit of txs, and of fulfillment txs if chain.estimate_gas_limit is false or
+// their gas cannot be estimated
 const ChainGasLimit = "chain.gas_limit"
+
+// ChainEstimateGasLimit estimate each fulfillment tx's gas, including the consumer's callback,
+// with eth_estimateGas, rather than sending every fulfillment with chain.gas_limit. Defaults to true
+const ChainEstimateGasLimit = "chain.estimate_gas_limit"
+
+// ChainGasLimitBuffer percent added to a fulfillment tx's estimated gas for its gas limit, in case
+// the consumer's callback uses more gas when the tx is mined. Defaults to 20
+const ChainGasLimitBuffer = "chain.gas_limit_buffer"
+
+// ChainGasLimitCeiling maximum gas limit of a fulfillment tx. Defaults to 2000000. See
+// chain.gas_limit_action
+const ChainGasLimitCeiling = "chain.gas_limit_ceiling"
+
+// ChainGasLimitAction what to do with requests whose fulfillment, including the consumer's
+// callback, is estimated to need more than chain.gas_limit_ceiling - "skip" (default) or "defer",
+// leaving them to be fulfilled if the ceiling is raised
+const ChainGasLimitAction = "chain.gas_limit_action"
+
+// ChainCatchupMode which requests missed while the node was down, or on standby, are fulfilled
+// once it starts processing - "all" (default), "recent", only those made within
+// chain.catchup_max_age blocks of the chain head, or "head", none. Missed requests are still
+// ingested, so those not fulfilled are recorded as skipped, and fulfillments seen
+const ChainCatchupMode = "chain.catchup_mode"
+
+// ChainCatchupMaxAge age, in blocks behind the chain head, of the oldest missed request fulfilled
+// when chain.catchup_mode is "recent". Defaults to 100
+const ChainCatchupMaxAge = "chain.catchup_max_age"
+
 const ChainMaxGasPrice = "chain.max_gas_price"
 const ChainContractAddress = "chain.contract_address"
 const ChainEthHttpHost = "chain.eth_http_host"
@@ -18,6 +404,35 @@ const ChainEthWsHost = "chain.eth_ws_host"
 const ChainNetworkId = "chain.network_id"
 const ChainFirstBlock = "chain.first_block"
 
+// ChainBackfillBlockRange maximum number of blocks whose logs are fetched at once when catching
+// up on missed events, e.g. after downtime. 0 fetches all missed blocks' logs at once
+const ChainBackfillBlockRange = "chain.backfill_block_range"
+
+// ChainBackfillBatchSize number of missed requests added to the db per batch insert when
+// catching up on missed events
+const ChainBackfillBatchSize = "chain.backfill_batch_size"
+
+// ChainBackfillQueueRanges number of fetched block ranges held while waiting to be added to the
+// db when catching up on missed events, so that fetching logs runs ahead of slow db writes
+const ChainBackfillQueueRanges = "chain.backfill_queue_ranges"
+
+// ChainXfundSpenders addresses, other than the router and VORCoordinator, whose xFUND allowances
+// from the oracle key are monitored
+const ChainXfundSpenders = "chain.xfund_spenders"
+
+// ChainConditionCheckInterval seconds between checks that the oracle key is still a registered
+// provider and the router is not paused. Fulfillment is quiesced while either holds. 0 disables
+// the check
+const ChainConditionCheckInterval = "chain.condition_check_interval"
+
+// ChainVorCoordinatorAddress optional VORCoordinator contract. If set, VOR randomness requests
+// for the oracle's proving key are also fulfilled
+const ChainVorCoordinatorAddress = "chain.vor_coordinator_address"
+
+// ChainEventFilters array of additional contract events, each given by an ABI fragment, which
+// are subscribed to and recorded in the chain_events table. See chain.EventFilter
+const ChainEventFilters = "chain.event_filters"
+
 const DatabaseDialect = "database.dialect"
 const DatabaseStorage = "database.storage"
 const DatabaseHost = "database.host"
@@ -26,10 +441,393 @@ const DatabaseUser = "database.user"
 const DatabasePassword = "database.password"
 const DatabaseDatabase = "database.database"
 
+// DatabaseEncryptionKey base64 encoded 32 byte AES-256 key, encrypting consumer and pair notes,
+// adapter credentials and audit log parameters at rest. If empty, the key is read from VaultDatabaseKeyField when
+// Vault is configured. Empty in both disables encryption
+const DatabaseEncryptionKey = "database.encryption_key"
+
+// DatabaseEncryptionPreviousKeys keys DatabaseEncryptionKey has replaced. Values encrypted with
+// them are re-encrypted with the current key on start up, after which they can be removed
+const DatabaseEncryptionPreviousKeys = "database.encryption_previous_keys"
+
+// DatabaseMaintenanceHour UTC hour, 0 - 23, at which database.maintenance_tables are vacuumed and
+// analysed, keeping query plans healthy on long-lived nodes. Pick an off-peak hour, since sqlite
+// locks the database while it is vacuumed. -1, or not setting it, disables the nightly run
+const DatabaseMaintenanceHour = "database.maintenance_hour"
+
+// DatabaseMaintenanceTables tables vacuumed and analysed at database.maintenance_hour. Defaults to the
+// tables updated for every request, e.g. data_requests
+const DatabaseMaintenanceTables = "database.maintenance_tables"
+
 const PrometheusPort = "prometheus.port"
 
+// PrometheusMaxPairLabels maximum number of distinct pairs given their own pair label on
+// fulfillment, fee and latency metrics. Further pairs are labelled "other"
+const PrometheusMaxPairLabels = "prometheus.max_pair_labels"
+
+// PrometheusMaxConsumerLabels maximum number of distinct consumer contracts given their own
+// consumer label on fulfillment and fee metrics. Further consumers are labelled "other"
+const PrometheusMaxConsumerLabels = "prometheus.max_consumer_labels"
+
+// PrometheusLabelPairs pairs, e.g. ["XFUND.ETH"], which always have their own pair label
+const PrometheusLabelPairs = "prometheus.label_pairs"
+
+// PrometheusLabelConsumers consumer contract addresses which always have their own consumer label
+const PrometheusLabelConsumers = "prometheus.label_consumers"
+
+// PrometheusSnapshotInterval seconds between saves of the lifetime fulfillment, fee and gas
+// counters to the database, so that they survive restarts. 0 disables snapshots
+const PrometheusSnapshotInterval = "prometheus.snapshot_interval"
+
+// HealthMinBalance wallet balance, in ETH, below which the node is reported as not ready
+const HealthMinBalance = "health.min_balance"
+
+// HealthMaxBlockLag age, in seconds, of the eth node's latest block above which the node is reported as not ready
+const HealthMaxBlockLag = "health.max_block_lag"
+
+// PprofEnabled serve pprof debug endpoints on localhost
+const PprofEnabled = "pprof.enabled"
+
+// PprofPort localhost port for the pprof debug endpoints
+const PprofPort = "pprof.port"
+
+// AdminApiHost host the admin REST API listens on. Should be a local address
+const AdminApiHost = "admin_api.host"
+
+// AdminApiPort port the admin REST API listens on. 0 disables the admin API
+const AdminApiPort = "admin_api.port"
+
+// AdminApiDashboard serve the read-only status dashboard at /dashboard/ on the admin API port
+const AdminApiDashboard = "admin_api.dashboard"
+
+// AdminApiTokens named API keys for the admin API, each with a scope - read, for GET requests
+// only, or operator - e.g. [admin_api.tokens.grafana] key = "..." scope = "read". The keystore
+// password is always accepted, with the operator scope
+const AdminApiTokens = "admin_api.tokens"
+
+// AdminApiJwtSecret optional secret for HS256 signed JWTs accepted by the admin API. Tokens must
+// have exp and scope claims. The sub claim is recorded in the audit log
+const AdminApiJwtSecret = "admin_api.jwt_secret"
+
+// AdminApiTlsCertFile and AdminApiTlsKeyFile - if both are set, the admin API is served over HTTPS
+const AdminApiTlsCertFile = "admin_api.tls_cert_file"
+const AdminApiTlsKeyFile = "admin_api.tls_key_file"
+
+// AdminApiTlsClientCaFile optional PEM CA bundle. If set, clients must present a certificate
+// signed by it, as well as a token
+const AdminApiTlsClientCaFile = "admin_api.tls_client_ca_file"
+
+// PriceApiHost host the public, read-only price API listens on
+const PriceApiHost = "price_api.host"
+
+// PriceApiPort port the public price API listens on. 0 (default) disables the price API
+const PriceApiPort = "price_api.port"
+
+// PriceApiCacheTtl seconds the price API caches the latest prices for before reading them again
+const PriceApiCacheTtl = "price_api.cache_ttl"
+
+// PriceApiRateLimit requests per second allowed from each client IP, with PriceApiRateBurst
+// allowed at once
+const PriceApiRateLimit = "price_api.rate_limit"
+const PriceApiRateBurst = "price_api.rate_burst"
+
+// PriceApiTrustProxy take client IPs from the X-Forwarded-For header, for the rate limit. Only
+// set if the price API is behind a reverse proxy, else clients can choose their own IPs
+const PriceApiTrustProxy = "price_api.trust_proxy"
+
+// WebhooksUrls urls to POST job lifecycle events to. Empty disables webhooks
+const WebhooksUrls = "webhooks.urls"
+
+// WebhooksEvents events to send - received, fulfilled, failed, skipped. Empty sends all
+const WebhooksEvents = "webhooks.events"
+
+// WebhooksSecret optional HMAC-SHA256 key used to sign webhook payloads
+const WebhooksSecret = "webhooks.secret"
+
+// WebhooksTimeout timeout, in seconds, for each webhook call
+const WebhooksTimeout = "webhooks.timeout"
+
+// IpfsApiUrl url of the IPFS HTTP API, e.g. http://127.0.0.1:5001 or https://ipfs.infura.io:5001,
+// each answer attestation is published to and pinned on. Empty disables publishing
+const IpfsApiUrl = "ipfs.api_url"
+
+// IpfsUsername optional basic auth username for the IPFS API, e.g. an Infura project id
+const IpfsUsername = "ipfs.username"
+
+// IpfsPassword optional basic auth password for the IPFS API
+const IpfsPassword = "ipfs.password"
+
+// IpfsTimeout timeout, in seconds, for each request to the IPFS API
+const IpfsTimeout = "ipfs.timeout"
+
+// CacheRedisUrl url of a Redis server, e.g. redis://10.0.0.5:6379/0 or rediss:// for TLS, shared by
+// the active/standby pair and any read-only API replicas for the subgraph response cache, the price
+// API's prices and the rate limit counters. Empty keeps each node's cache and counters to itself
+const CacheRedisUrl = "cache.redis_url"
+
+// CacheRedisPassword optional password for the Redis server, in place of one in CacheRedisUrl
+const CacheRedisPassword = "cache.redis_password"
+
+// CachePrefix prepended to every key, so that several deployments may share a Redis server.
+// Defaults to go-ooo:
+const CachePrefix = "cache.prefix"
+
+// CacheTimeout timeout, in milliseconds, for each Redis command. On a timeout or any other error
+// the node falls back to its own cache and counters
+const CacheTimeout = "cache.timeout"
+
+// ScheduleMaintenanceWindows array of UTC windows the heavy background tasks - the reconciliation,
+// the DEX full sync and the database vacuum - are confined to. A task falling due outside its
+// windows is deferred until one opens. See schedule.Window
+const ScheduleMaintenanceWindows = "schedule.maintenance_windows"
+
+// PushFeeds array of on-chain price feed contracts the node pushes answers to, independent of
+// requests, when the price deviates or a heartbeat is due. See chain.PushFeed
+const PushFeeds = "push_feeds.feeds"
+
+// PushFeedsCheckInterval seconds between checks of the push feeds' prices. Defaults to 60
+const PushFeedsCheckInterval = "push_feeds.check_interval"
+
+// FeeScheduleEnabled periodically recomputes a recommended provider fee from the gas cost of
+// recent fulfillments and the xFUND price, and alerts if it differs from the current fee
+const FeeScheduleEnabled = "fee_schedule.enabled"
+
+// FeeScheduleInterval seconds between fee recommendations. Defaults to 3600
+const FeeScheduleInterval = "fee_schedule.interval"
+
+// FeeScheduleWindow seconds of fulfillments whose gas costs are averaged. Defaults to 86400
+const FeeScheduleWindow = "fee_schedule.window"
+
+// FeeScheduleMinSamples minimum number of fulfillments in the window needed to recommend a fee.
+// Defaults to 5
+const FeeScheduleMinSamples = "fee_schedule.min_samples"
+
+// FeeScheduleMargin percent added to the mean fulfillment cost. Defaults to 25
+const FeeScheduleMargin = "fee_schedule.margin"
+
+// FeeScheduleThreshold percent the recommended fee must differ from the current fee by before
+// the operator is alerted, or the fee updated. Defaults to 10
+const FeeScheduleThreshold = "fee_schedule.threshold"
+
+// FeeScheduleAutoUpdate sends the fee update tx, rather than only alerting, keeping the fee
+// between fee_schedule.min_fee and fee_schedule.max_fee
+const FeeScheduleAutoUpdate = "fee_schedule.auto_update"
+
+// FeeScheduleMinFee lowest fee, in xFUND's smallest unit, recommended or set
+const FeeScheduleMinFee = "fee_schedule.min_fee"
+
+// FeeScheduleMaxFee highest fee, in xFUND's smallest unit, recommended or set. Required if
+// fee_schedule.auto_update is set. 0 is unbounded
+const FeeScheduleMaxFee = "fee_schedule.max_fee"
+
+// ConsensusPeers array of cooperating providers, each with a name, url, address and optional
+// token, whose signed observations the node's answers are checked against. Empty disables the
+// check. See consensus.Peer
+const ConsensusPeers = "consensus.peers"
+
+// ConsensusHost host to serve the node's observations to its peers on
+const ConsensusHost = "consensus.host"
+
+// ConsensusPort port to serve the node's observations to its peers on. 0 disables serving them
+const ConsensusPort = "consensus.port"
+
+// ConsensusToken bearer token peers must send to request the node's observations. Required if
+// consensus.port is set
+const ConsensusToken = "consensus.token"
+
+// ConsensusMaxDeviation percentage an answer may deviate from the peers' median before it is
+// flagged. Defaults to 1
+const ConsensusMaxDeviation = "consensus.max_deviation"
+
+// ConsensusMinPeers fewest valid peer observations needed to check an answer
+const ConsensusMinPeers = "consensus.min_peers"
+
+// ConsensusMaxAge seconds after which an observation is too old to check against, or to serve
+const ConsensusMaxAge = "consensus.max_age"
+
+// ConsensusTimeout timeout, in seconds, for each request to a peer
+const ConsensusTimeout = "consensus.timeout"
+
+// ConsensusWithhold fail, rather than only alert on, answers which deviate from the peers'
+// consensus, so that they are retried instead of being submitted
+const ConsensusWithhold = "consensus.withhold"
+
+// HttpTimeout timeout, in seconds, for each request to a data source, including reading the response
+const HttpTimeout = "http.timeout"
+
+// HttpDialTimeout timeout, in seconds, for connecting to a data source or proxy
+const HttpDialTimeout = "http.dial_timeout"
+
+// HttpTlsHandshakeTimeout timeout, in seconds, for the TLS handshake with a data source or proxy
+const HttpTlsHandshakeTimeout = "http.tls_handshake_timeout"
+
+// HttpResponseHeaderTimeout timeout, in seconds, waiting for a data source's response headers
+// once the request is sent
+const HttpResponseHeaderTimeout = "http.response_header_timeout"
+
+// HttpKeepAlive interval, in seconds, between TCP keep-alive probes on data source connections
+const HttpKeepAlive = "http.keep_alive"
+
+// HttpIdleConnTimeout seconds an idle keep-alive connection is kept open for reuse
+const HttpIdleConnTimeout = "http.idle_conn_timeout"
+
+// HttpMaxIdleConns maximum number of idle keep-alive connections kept, across all hosts
+const HttpMaxIdleConns = "http.max_idle_conns"
+
+// HttpMaxIdleConnsPerHost maximum number of idle keep-alive connections kept per host
+const HttpMaxIdleConnsPerHost = "http.max_idle_conns_per_host"
+
+// HttpMaxConnsPerHost maximum number of connections to each host, including those in use. 0 is
+// unlimited
+const HttpMaxConnsPerHost = "http.max_conns_per_host"
+
+// HttpProxy optional proxy url, e.g. http://proxy:3128, for requests to data sources. If not set,
+// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used
+const HttpProxy = "http.proxy"
+
+// AlertsTelegramBotToken Telegram bot token used to send alerts. Alerts are sent to Telegram if this and the chat id are set
+const AlertsTelegramBotToken = "alerts.telegram_bot_token"
+
+// AlertsTelegramChatId Telegram chat to send alerts to
+const AlertsTelegramChatId = "alerts.telegram_chat_id"
+
+// AlertsSlackWebhookUrl Slack incoming webhook to send alerts to
+const AlertsSlackWebhookUrl = "alerts.slack_webhook_url"
+
+// AlertsWebhookUrl url to POST alerts to as JSON. Empty disables the webhook sink
+const AlertsWebhookUrl = "alerts.webhook_url"
+
+// AlertsWebhookSecret optional HMAC-SHA256 key used to sign alert webhook payloads
+const AlertsWebhookSecret = "alerts.webhook_secret"
+
+// AlertsPagerDutyRoutingKey PagerDuty Events API v2 integration key. Empty disables PagerDuty
+const AlertsPagerDutyRoutingKey = "alerts.pagerduty_routing_key"
+
+// AlertsSmtpHost SMTP server to send alert emails through. Email alerts are sent if this, the
+// from address and at least one recipient are set
+const AlertsSmtpHost = "alerts.smtp_host"
+
+// AlertsSmtpPort SMTP server port
+const AlertsSmtpPort = "alerts.smtp_port"
+
+// AlertsSmtpUsername SMTP username. Empty sends without authenticating
+const AlertsSmtpUsername = "alerts.smtp_username"
+
+// AlertsSmtpPassword SMTP password
+const AlertsSmtpPassword = "alerts.smtp_password"
+
+// AlertsSmtpFrom address alert emails are sent from
+const AlertsSmtpFrom = "alerts.smtp_from"
+
+// AlertsSmtpTo addresses alert emails are sent to
+const AlertsSmtpTo = "alerts.smtp_to"
+
+// AlertsSmtpTls connection security - starttls, tls (implicit, usually port 465) or none
+const AlertsSmtpTls = "alerts.smtp_tls"
+
+// AlertsSmtpBatchWindow period, in seconds, over which alerts are collected into a single email.
+// 0 sends an email per alert
+const AlertsSmtpBatchWindow = "alerts.smtp_batch_window"
+
+// AlertsSmtpSubjectTemplate optional text/template for alert email subjects, executed with
+// .Alerts - the alerts in the batch - and .Host
+const AlertsSmtpSubjectTemplate = "alerts.smtp_subject_template"
+
+// AlertsSmtpBodyTemplate optional text/template for alert email bodies
+const AlertsSmtpBodyTemplate = "alerts.smtp_body_template"
+
+// AlertsRoutes sinks to send each alert type to, e.g. rpc_down = ["pagerduty", "slack"].
+// Alert types without a route are sent to all configured sinks
+const AlertsRoutes = "alerts.routes"
+
+// AlertsSeverities overrides the severity - info, warning, error or critical - of alert types
+const AlertsSeverities = "alerts.severities"
+
+// AlertsCooldown period, in seconds, during which an alert for the same condition is not repeated
+const AlertsCooldown = "alerts.cooldown"
+
+// AlertsTimeout timeout, in seconds, for each alert sink call
+const AlertsTimeout = "alerts.timeout"
+
+// AlertsPairHeartbeatWindow period, in seconds, after which a pair whose requests have all failed is alerted. 0 disables
+const AlertsPairHeartbeatWindow = "alerts.pair_heartbeat_window"
+
+// AlertsPairHeartbeatMinFailures failed requests for a pair within the heartbeat window needed to alert. Defaults to 3
+const AlertsPairHeartbeatMinFailures = "alerts.pair_heartbeat_min_failures"
+
+// AlertsGasBudget ETH which may be spent on fulfillment gas in any 24 hours before an alert is sent. 0 disables
+const AlertsGasBudget = "alerts.gas_budget"
+
+// AlertsMaxClockSkew seconds the host clock may differ from the chain's block timestamps before an
+// alert is sent and the node is reported as not ready. 0 disables
+const AlertsMaxClockSkew = "alerts.max_clock_skew"
+
+// TracingOtlpEndpoint OTLP/HTTP traces endpoint fulfillment pipeline spans are exported to,
+// e.g. http://localhost:4318/v1/traces. Empty disables tracing
+const TracingOtlpEndpoint = "tracing.otlp_endpoint"
+
+// TracingHeaders additional headers sent with each export, e.g. for collector authentication
+const TracingHeaders = "tracing.headers"
+
+// TracingServiceName service.name resource attribute of exported spans
+const TracingServiceName = "tracing.service_name"
+
+// TracingSampleRatio fraction of requests, 0 - 1, that are traced
+const TracingSampleRatio = "tracing.sample_ratio"
+
+// ErrorReportingSentryDsn Sentry DSN panics and recurring errors are reported to. Empty disables error reporting
+const ErrorReportingSentryDsn = "error_reporting.sentry_dsn"
+
+// ErrorReportingEnvironment environment reported with each event, e.g. mainnet or rinkeby
+const ErrorReportingEnvironment = "error_reporting.environment"
+
+// ErrorReportingRepeatThreshold number of times the same error must be logged within the
+// repeat window before it is reported. Panics are always reported
+const ErrorReportingRepeatThreshold = "error_reporting.repeat_threshold"
+
+// ErrorReportingRepeatWindow window, in seconds, over which repeated errors are counted.
+// Each error is reported at most once per window
+const ErrorReportingRepeatWindow = "error_reporting.repeat_window"
+
+// HaEnabled run in active/standby mode. Instances sharing the same postgres database elect
+// a leader. Only the leader processes events and submits transactions
+const HaEnabled = "ha.enabled"
+
+// HaLockKey postgres advisory lock key used for leader election. Must be the same for all instances
+const HaLockKey = "ha.lock_key"
+
+// HaCheckInterval interval, in seconds, at which the standby tries to take over and the
+// leader checks it still holds the lock
+const HaCheckInterval = "ha.check_interval"
+
 const LogLevel = "log.level"
 
+// LogFormat text (default), logfmt or json
+const LogFormat = "log.format"
+
+// LogFile optional file to write logs to, instead of stdout. The file is rotated once it reaches LogMaxSize
+const LogFile = "log.file"
+
+// LogMaxSize size, in MB, at which the log file is rotated. 0 disables rotation
+const LogMaxSize = "log.max_size"
+
+// LogMaxBackups number of rotated log files to keep. 0 keeps all
+const LogMaxBackups = "log.max_backups"
+
+// LogCompress gzip rotated log files
+const LogCompress = "log.compress"
+
+// UpdateCheckEnabled periodically check the release endpoint for a newer version of go-ooo,
+// logging a warning and alerting operators if one has been released
+const UpdateCheckEnabled = "update_check.enabled"
+
+// UpdateCheckUrl release endpoint. Expects a GitHub style latest release response. Defaults to
+// the go-ooo GitHub releases
+const UpdateCheckUrl = "update_check.url"
+
+// UpdateCheckInterval interval, in hours, between update checks
+const UpdateCheckInterval = "update_check.interval"
+
 // SubChainEthHttpRpc only used to get the latest block number
 const SubChainEthHttpRpc = "subchain.eth_http_rpc"
 
@@ -40,3 +838,33 @@ const SubChainPolygonHttpRpc = "subchain.polygon_http_rpc"
 const SubChainBcsHttpRpc = "subchain.bsc_http_rpc"
 
 const SubChainXdaiHttpRpc = "subchain.xdai_http_rpc"
+
+// Profile name of the network profile to use, from Profiles. Usually selected with --profile
+const Profile = "profile"
+
+// Profiles named network profiles, e.g. [profiles.sepolia.chain]. The selected profile's keys
+// override those at the top level of the config
+const Profiles = "profiles"
+
+// Features enable or disable whole subsystems, e.g. features.vor = false. Every subsystem is
+// enabled unless disabled. Disabled subsystems are neither started nor queried, whatever their
+// own config. See FeatureEnabled
+
+// FeaturesDex the DEX sources - subgraph adapters, pair discovery and TWAP pools
+const FeaturesDex = "features.dex"
+
+// FeaturesCex the centralised exchange sources - Finchains, its supported pair sync, and the
+// exchange klines answering historical requests
+const FeaturesCex = "features.cex"
+
+// FeaturesVor VOR randomness requests, even if chain.vor_coordinator_address is set
+const FeaturesVor = "features.vor"
+
+// FeaturesPushFeeds the push_feeds.feeds price feeds
+const FeaturesPushFeeds = "features.push_feeds"
+
+// FeaturesAdminApi the admin REST API, even if admin_api.port is set
+const FeaturesAdminApi = "features.admin_api"
+
+// FeaturesAlerts alert delivery to every sink
+const FeaturesAlerts = "features.alerts"
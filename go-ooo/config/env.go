@@ -0,0 +1,32 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+	"strings"
+)
+
+// EnvPrefix - every config key can be overridden by an environment variable named EnvPrefix, an
+// underscore, then the key upper cased with dots replaced by underscores, e.g. GO_OOO_CHAIN_ETH_WS_HOST
+// for chain.eth_ws_host. Lists are space separated, tables, e.g. alerts.routes, are JSON objects, and
+// arrays of tables, e.g. jobs.json_feeds, are JSON arrays of objects
+const EnvPrefix = "GO_OOO"
+
+// TableKeys are the config keys holding tables, or arrays of tables, which are overridden as a
+// whole rather than by entry
+var TableKeys = []string{KeystorageAccounts, AlertsRoutes, AlertsSeverities, TracingHeaders, JobsJsonFeeds,
+	AdminApiTokens, PushFeeds, ConsensusPeers}
+
+var envKeyReplacer = strings.NewReplacer(".", "_")
+
+// EnvVar returns the environment variable which overrides key
+func EnvVar(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(envKeyReplacer.Replace(key))
+}
+
+// BindEnv makes v take config values from environment variables, when set, over those in the
+// config file
+func BindEnv(v *viper.Viper) {
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(envKeyReplacer)
+	v.AutomaticEnv()
+}
@@ -0,0 +1,22 @@
+package config
+
+import "github.com/spf13/viper"
+
+// Features are the subsystems which can be disabled, in the order they are reported
+var Features = []string{FeaturesDex, FeaturesCex, FeaturesVor, FeaturesPushFeeds, FeaturesAdminApi, FeaturesAlerts}
+
+// FeatureEnabled returns true unless the feature's subsystem has been disabled
+func FeatureEnabled(feature string) bool {
+	return !viper.IsSet(feature) || viper.GetBool(feature)
+}
+
+// DisabledFeatures returns the features disabled, e.g. for logging at startup
+func DisabledFeatures() []string {
+	var disabled []string
+	for _, f := range Features {
+		if !FeatureEnabled(f) {
+			disabled = append(disabled, f)
+		}
+	}
+	return disabled
+}
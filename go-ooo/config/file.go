@@ -0,0 +1,46 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileName is the name of the config file in the app home, without its extension
+const FileName = "config"
+
+// DefaultFormat is the format of new config files
+const DefaultFormat = "toml"
+
+// Formats are the supported config file extensions, in the order they are looked for. The
+// format is detected from the extension
+var Formats = []string{"toml", "yaml", "yml", "json"}
+
+// FindFile returns the config file in home - the first which exists of config.toml, config.yaml,
+// config.yml and config.json - or config.toml if there is none
+func FindFile(home string) string {
+	for _, format := range Formats {
+		file := FilePath(home, format)
+		if _, err := os.Stat(file); !errors.Is(err, os.ErrNotExist) {
+			return file
+		}
+	}
+	return FilePath(home, DefaultFormat)
+}
+
+// FilePath returns the path of a config file in format in home
+func FilePath(home string, format string) string {
+	return filepath.Join(home, FileName+"."+format)
+}
+
+// CheckFormat returns an error if format is not a supported config file format
+func CheckFormat(format string) error {
+	for _, f := range Formats {
+		if strings.ToLower(format) == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported config format %s - must be toml, yaml or json", format)
+}
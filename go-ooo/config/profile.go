@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"sort"
+	"strings"
+)
+
+// ProfileKey returns the key which sets key in profile
+func ProfileKey(profile string, key string) string {
+	return Profiles + "." + strings.ToLower(profile) + "." + key
+}
+
+// ProfileNames returns the names of the profiles in v's config, sorted
+func ProfileNames(v *viper.Viper) []string {
+	var names []string
+	for name := range v.GetStringMap(Profiles) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ReadInConfig reads v's config file, then applies profile, if set
+func ReadInConfig(v *viper.Viper, profile string) error {
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
+	return ApplyProfile(v, profile)
+}
+
+// ApplyProfile merges profile, if set, over the top level of v's config. Environment variables
+// and flags still override the profile's values. It must be re-applied each time the config
+// file is read
+func ApplyProfile(v *viper.Viper, profile string) error {
+	if profile == "" {
+		return nil
+	}
+
+	values := v.GetStringMap(Profiles + "." + strings.ToLower(profile))
+	if len(values) == 0 {
+		names := ProfileNames(v)
+		if len(names) == 0 {
+			return fmt.Errorf("profile %s is not in %s, which has no profiles", profile, v.ConfigFileUsed())
+		}
+		return fmt.Errorf("profile %s is not in %s - its profiles are %s", profile, v.ConfigFileUsed(),
+			strings.Join(names, ", "))
+	}
+
+	return v.MergeConfigMap(values)
+}
+
+// SourceKey returns the key in the config file which sets key - its entry in the selected
+// profile if the profile sets it, otherwise key
+func SourceKey(key string) string {
+	profile := viper.GetString(Profile)
+	if profile != "" && viper.IsSet(ProfileKey(profile, key)) {
+		return ProfileKey(profile, key)
+	}
+	return key
+}
@@ -0,0 +1,324 @@
+// Package consensus exchanges signed price observations with cooperating providers, so that
+// an answer which deviates materially from the peers' consensus can be flagged, or withheld,
+// before it is submitted
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	solsha3 "github.com/miguelmota/go-solidity-sha3"
+	"go-ooo/httpclient"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ObservationsPath - path of the observations endpoint, served by each provider to its peers
+const ObservationsPath = "/v1/observations"
+
+// observationDomain is hashed with each observation, so that an observation's signature cannot
+// be replayed as one over an attestation or fulfillment
+const observationDomain = "OOO_OBSERVATION"
+
+// maxClockSkew - how far in the future a peer's observation timestamp may be
+const maxClockSkew = 30 * time.Second
+
+// Observation is a provider's signed answer for an endpoint at a point in time. The hash signed,
+// as an Ethereum signed message, is
+//
+//	keccak256(abi.encodePacked("OOO_OBSERVATION", endpoint, price, timestamp))
+type Observation struct {
+	Endpoint  string `json:"endpoint"`
+	Price     string `json:"price"`
+	Timestamp int64  `json:"timestamp"`
+	Signer    string `json:"signer"`
+	Signature string `json:"signature"`
+}
+
+// Peer is a cooperating provider, whose observations are signed with Address. Token, if set, is
+// sent as a bearer token with requests for its observations
+type Peer struct {
+	Name    string `mapstructure:"name"`
+	Url     string `mapstructure:"url"`
+	Address string `mapstructure:"address"`
+	Token   string `mapstructure:"token"`
+}
+
+// Config holds the peers and the thresholds of a Checker
+type Config struct {
+	Peers []Peer
+	// MaxDeviation - percentage an answer may deviate from the peers' median before it is flagged
+	MaxDeviation float64
+	// MinPeers - fewest valid peer observations needed to check an answer
+	MinPeers int
+	// MaxAge - oldest observation accepted from a peer, or served to one
+	MaxAge  time.Duration
+	Timeout time.Duration
+}
+
+// PeerResult is the outcome of requesting a peer's observation
+type PeerResult struct {
+	Peer  string `json:"peer"`
+	Price string `json:"price,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Result is the outcome of checking an answer against the peers' observations
+type Result struct {
+	Endpoint string `json:"endpoint"`
+	Price    string `json:"price"`
+	// Quorum is false if fewer than MinPeers valid observations were received, in which case the
+	// answer is not checked
+	Quorum       bool         `json:"quorum"`
+	Median       string       `json:"median,omitempty"`
+	DeviationPct float64      `json:"deviation_pct"`
+	Deviates     bool         `json:"deviates"`
+	Peers        []PeerResult `json:"peers"`
+}
+
+// Checker signs the node's observations, serves them to peers and checks answers against the
+// peers' observations
+type Checker struct {
+	mu       sync.Mutex
+	cfg      Config
+	client   *http.Client
+	address  common.Address
+	sign     func([]byte) ([]byte, error)
+	fetch    func(ctx context.Context, endpoint string) (string, error)
+	observed map[string]Observation
+}
+
+// NewChecker returns a Checker whose observations are signed as address by sign, e.g.
+// Signer.SignText. fetch answers an endpoint for peers which request one the node has no recent
+// observation of
+func NewChecker(cfg Config, address common.Address, sign func([]byte) ([]byte, error),
+	fetch func(ctx context.Context, endpoint string) (string, error)) *Checker {
+	if cfg.MinPeers < 1 {
+		cfg.MinPeers = 1
+	}
+
+	return &Checker{
+		cfg:      cfg,
+		client:   httpclient.New(cfg.Timeout),
+		address:  address,
+		sign:     sign,
+		fetch:    fetch,
+		observed: make(map[string]Observation),
+	}
+}
+
+// Enabled returns true if any peers are configured
+func (c *Checker) Enabled() bool {
+	return c != nil && len(c.cfg.Peers) > 0
+}
+
+// ObservationHash returns the hash signed for an observation
+func ObservationHash(endpoint string, price string, timestamp int64) common.Hash {
+	return common.BytesToHash(solsha3.SoliditySHA3(
+		solsha3.String(observationDomain),
+		solsha3.String(endpoint),
+		solsha3.Uint256(price),
+		solsha3.Uint256(fmt.Sprintf("%d", timestamp)),
+	))
+}
+
+// Verify checks the observation was signed by its signer
+func (ob Observation) Verify() error {
+	sig := common.FromHex(ob.Signature)
+	if len(sig) != 65 {
+		return errors.New("invalid signature length")
+	}
+	if _, ok := new(big.Int).SetString(ob.Price, 10); !ok {
+		return fmt.Errorf("price %q is not an integer", ob.Price)
+	}
+
+	sig = append([]byte(nil), sig...)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+
+	hash := ObservationHash(ob.Endpoint, ob.Price, ob.Timestamp)
+	pub, err := crypto.SigToPub(accounts.TextHash(hash.Bytes()), sig)
+	if err != nil {
+		return err
+	}
+
+	if signer := crypto.PubkeyToAddress(*pub); signer != common.HexToAddress(ob.Signer) {
+		return fmt.Errorf("signed by %s, not %s", signer.Hex(), ob.Signer)
+	}
+
+	return nil
+}
+
+// Observe signs price as the node's observation for endpoint, and keeps it to serve to peers
+func (c *Checker) Observe(endpoint string, price string) (Observation, error) {
+	endpoint = strings.ToUpper(endpoint)
+	ob := Observation{
+		Endpoint:  endpoint,
+		Price:     price,
+		Timestamp: time.Now().Unix(),
+		Signer:    c.address.Hex(),
+	}
+
+	hash := ObservationHash(ob.Endpoint, ob.Price, ob.Timestamp)
+	sig, err := c.sign(hash.Bytes())
+	if err != nil {
+		return ob, err
+	}
+	sig[64] += 27
+	ob.Signature = common.Bytes2Hex(sig)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.observed[endpoint] = ob
+	for e, o := range c.observed {
+		if time.Since(time.Unix(o.Timestamp, 0)) > c.cfg.MaxAge {
+			delete(c.observed, e)
+		}
+	}
+
+	return ob, nil
+}
+
+// Observation returns the node's observation for endpoint, to serve to a peer - the last
+// observed, if within MaxAge, or a new one
+func (c *Checker) Observation(ctx context.Context, endpoint string) (Observation, error) {
+	endpoint = strings.ToUpper(endpoint)
+
+	c.mu.Lock()
+	ob, ok := c.observed[endpoint]
+	c.mu.Unlock()
+	if ok && time.Since(time.Unix(ob.Timestamp, 0)) <= c.cfg.MaxAge {
+		return ob, nil
+	}
+
+	price, err := c.fetch(ctx, endpoint)
+	if err != nil {
+		return Observation{}, err
+	}
+
+	return c.Observe(endpoint, price)
+}
+
+// Check compares price, the node's answer for endpoint, with the median of the peers'
+// observations of it
+func (c *Checker) Check(ctx context.Context, endpoint string, price string) Result {
+	endpoint = strings.ToUpper(endpoint)
+	res := Result{
+		Endpoint: endpoint,
+		Price:    price,
+		Peers:    make([]PeerResult, len(c.cfg.Peers)),
+	}
+
+	var wg sync.WaitGroup
+	for i, p := range c.cfg.Peers {
+		wg.Add(1)
+		go func(i int, p Peer) {
+			defer wg.Done()
+			res.Peers[i] = PeerResult{Peer: p.Name}
+			ob, err := c.peerObservation(ctx, p, endpoint)
+			if err != nil {
+				res.Peers[i].Error = err.Error()
+				return
+			}
+			res.Peers[i].Price = ob.Price
+		}(i, p)
+	}
+	wg.Wait()
+
+	var prices []*big.Int
+	for _, p := range res.Peers {
+		if p.Error == "" {
+			v, _ := new(big.Int).SetString(p.Price, 10)
+			prices = append(prices, v)
+		}
+	}
+
+	if len(prices) < c.cfg.MinPeers {
+		return res
+	}
+	res.Quorum = true
+
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Cmp(prices[j]) < 0
+	})
+	median := new(big.Rat).SetInt(prices[len(prices)/2])
+	if len(prices)%2 == 0 {
+		median.Add(median, new(big.Rat).SetInt(prices[len(prices)/2-1])).Quo(median, big.NewRat(2, 1))
+	}
+	res.Median = new(big.Int).Quo(median.Num(), median.Denom()).String()
+
+	answer, ok := new(big.Rat).SetString(price)
+	if !ok {
+		res.Deviates = true
+		return res
+	}
+
+	if median.Sign() == 0 {
+		res.Deviates = answer.Sign() != 0
+		return res
+	}
+
+	diff := new(big.Rat).Sub(answer, median)
+	diff.Abs(diff).Quo(diff, new(big.Rat).Abs(median)).Mul(diff, big.NewRat(100, 1))
+	res.DeviationPct, _ = diff.Float64()
+	res.Deviates = res.DeviationPct > c.cfg.MaxDeviation
+
+	return res
+}
+
+// peerObservation requests p's observation of endpoint, and checks it was signed by p, is of
+// endpoint and is recent
+func (c *Checker) peerObservation(ctx context.Context, p Peer, endpoint string) (Observation, error) {
+	var ob Observation
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimRight(p.Url, "/")+ObservationsPath+"?endpoint="+url.QueryEscape(endpoint), nil)
+	if err != nil {
+		return ob, err
+	}
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return ob, err
+	}
+	defer httpclient.Close(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return ob, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&ob); err != nil {
+		return ob, err
+	}
+
+	if !strings.EqualFold(ob.Endpoint, endpoint) {
+		return ob, fmt.Errorf("observation is of %s", ob.Endpoint)
+	}
+	if common.HexToAddress(ob.Signer) != common.HexToAddress(p.Address) {
+		return ob, fmt.Errorf("observation signer %s is not %s", ob.Signer, p.Address)
+	}
+	if err = ob.Verify(); err != nil {
+		return ob, err
+	}
+
+	observedAt := time.Unix(ob.Timestamp, 0)
+	if time.Since(observedAt) > c.cfg.MaxAge || time.Until(observedAt) > maxClockSkew {
+		return ob, fmt.Errorf("observation timestamp %d is out of range", ob.Timestamp)
+	}
+
+	return ob, nil
+}
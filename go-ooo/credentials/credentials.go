@@ -0,0 +1,422 @@
+package credentials
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"go-ooo/database"
+	"go-ooo/database/models"
+	"go-ooo/redact"
+	go_ooo_types "go-ooo/types"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reference schemes for credentials held outside the database
+const (
+	RefEnv   = "env"
+	RefFile  = "file"
+	RefVault = "vault"
+)
+
+// maxNameLength - maximum length of a credential's name
+const maxNameLength = 64
+
+var nameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// placeholderRegex finds credentials referenced in adapter config, e.g. the api key in
+// https://openexchangerates.org/api/latest.json?app_id={credential:oxr}
+var placeholderRegex = regexp.MustCompile(`\{credential:([a-z0-9][a-z0-9_.-]*)\}`)
+
+var (
+	credentialUses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_adapter_credential_uses_total",
+		Help: "The total number of times each adapter credential was used",
+	}, []string{"credential"})
+
+	credentialRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_adapter_credential_rejections_total",
+		Help: "The total number of adapter calls not made, by credential, because the credential's daily quota was used or it could not be read",
+	}, []string{"credential"})
+)
+
+// Manager holds the API keys data source adapters need, e.g. for CEX APIs or the FX rates API,
+// so that they are not scattered across the config file. Adapter config references a credential
+// by name as {credential:NAME}, which is replaced with its value when the adapter is called.
+// A credential's value is stored in the database, encrypted if database encryption is enabled,
+// or read on each use from an external reference:
+//
+//	env:NAME      environment variable NAME
+//	file:PATH     the contents of the file at PATH, e.g. rendered by a Vault agent
+//	vault:FIELD   a field of the node's Vault secret
+//
+// Each use is counted, per UTC day, against the credential's optional daily quota, which stops
+// the node exhausting an API plan's allowance
+type Manager struct {
+	db     database.Store
+	logger *logrus.Logger
+	vault  map[string]string
+
+	mu          sync.Mutex
+	credentials map[string]*credential
+}
+
+type credential struct {
+	models.AdapterCredentials
+
+	// uses since the last flush, on pendingDay
+	pending    uint64
+	pendingDay string
+
+	// the last error reading the credential's reference
+	err string
+}
+
+// New returns a Manager, loading the credentials from db. vault is the node's Vault secret, nil if
+// Vault is not configured
+func New(db database.Store, logger *logrus.Logger, vault map[string]string) (*Manager, error) {
+	m := &Manager{
+		db:          db,
+		logger:      logger,
+		vault:       vault,
+		credentials: make(map[string]*credential),
+	}
+	return m, m.Load()
+}
+
+// Load reloads the credentials from the database, picking up changes made by another instance
+func (m *Manager) Load() error {
+	rows, err := m.db.GetAllAdapterCredentials()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	loaded := make(map[string]*credential, len(rows))
+	for _, r := range rows {
+		c := &credential{AdapterCredentials: r}
+		if prev, ok := m.credentials[r.Name]; ok {
+			// uses since the last flush are not in the database yet
+			c.pending = prev.pending
+			c.pendingDay = prev.pendingDay
+			c.err = prev.err
+			if c.pending > 0 {
+				if c.UsageDay != c.pendingDay {
+					c.UsageDay = c.pendingDay
+					c.UsedToday = 0
+				}
+				c.UsedToday += c.pending
+				c.TotalUses += c.pending
+				if prev.LastUsedAt > c.LastUsedAt {
+					c.LastUsedAt = prev.LastUsedAt
+				}
+			}
+		}
+		loaded[r.Name] = c
+	}
+	m.credentials = loaded
+
+	return nil
+}
+
+// ValidateName returns an error if name cannot be used for a credential
+func ValidateName(name string) error {
+	if len(name) > maxNameLength || !nameRegex.MatchString(name) {
+		return fmt.Errorf("credential name %q must be 1 - %d lower case letters, digits, or _ . - characters", name, maxNameLength)
+	}
+	return nil
+}
+
+// ValidateRef returns an error if ref is not an env:, file: or vault: reference
+func ValidateRef(ref string) error {
+	parts := strings.SplitN(ref, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return fmt.Errorf("reference %q must be of the form env:NAME, file:PATH or vault:FIELD", ref)
+	}
+	switch parts[0] {
+	case RefEnv, RefFile, RefVault:
+		return nil
+	}
+	return fmt.Errorf("unknown reference scheme %q - must be %s, %s or %s", parts[0], RefEnv, RefFile, RefVault)
+}
+
+// Expand replaces each {credential:NAME} in s with the credential's value, counting one use of
+// each credential referenced. s is returned as it is if it references none
+func (m *Manager) Expand(s string) (string, error) {
+	values, err := m.resolve(s)
+	if err != nil {
+		return "", err
+	}
+	return replacePlaceholders(s, values), nil
+}
+
+// ExpandRequest expands an adapter call's url and headers, counting one use of each credential
+// referenced by any of them
+func (m *Manager) ExpandRequest(url string, headers map[string]string) (string, map[string]string, error) {
+	texts := []string{url}
+	for _, v := range headers {
+		texts = append(texts, v)
+	}
+
+	values, err := m.resolve(texts...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	expanded := make(map[string]string, len(headers))
+	for k, v := range headers {
+		expanded[k] = replacePlaceholders(v, values)
+	}
+	return replacePlaceholders(url, values), expanded, nil
+}
+
+// resolve returns the value of each credential referenced in texts, keyed by name
+func (m *Manager) resolve(texts ...string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, s := range texts {
+		for _, match := range placeholderRegex.FindAllStringSubmatch(s, -1) {
+			if _, ok := values[match[1]]; ok {
+				continue
+			}
+			if m == nil {
+				return nil, fmt.Errorf("credential %s referenced, but no credential store is available", match[1])
+			}
+			value, err := m.Use(match[1])
+			if err != nil {
+				return nil, err
+			}
+			values[match[1]] = value
+		}
+	}
+	return values, nil
+}
+
+func replacePlaceholders(s string, values map[string]string) string {
+	if len(values) == 0 {
+		return s
+	}
+	return placeholderRegex.ReplaceAllStringFunc(s, func(p string) string {
+		return values[placeholderRegex.FindStringSubmatch(p)[1]]
+	})
+}
+
+// Use returns the value of the credential, counting a use. It returns an error if the
+// credential is not set, has used its daily quota, or its reference cannot be read
+func (m *Manager) Use(name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.credentials[name]
+	if !ok {
+		return "", fmt.Errorf("credential %s is not set - see 'go-ooo credentials set'", name)
+	}
+
+	now := time.Now().UTC()
+	day := now.Format("2006-01-02")
+	if c.UsageDay != day {
+		c.UsageDay = day
+		c.UsedToday = 0
+	}
+
+	if c.DailyQuota > 0 && c.UsedToday >= c.DailyQuota {
+		credentialRejections.WithLabelValues(name).Inc()
+		return "", fmt.Errorf("credential %s has used its daily quota of %d", name, c.DailyQuota)
+	}
+
+	value, err := m.read(c)
+	if err != nil {
+		c.err = err.Error()
+		credentialRejections.WithLabelValues(name).Inc()
+		return "", fmt.Errorf("credential %s: %s", name, err.Error())
+	}
+	c.err = ""
+
+	// keys are masked wherever they would be logged, e.g. in a url
+	redact.AddSecrets(value)
+
+	if c.pendingDay != day {
+		c.pendingDay = day
+		c.pending = 0
+	}
+	c.pending++
+	c.UsedToday++
+	c.TotalUses++
+	c.LastUsedAt = now.Unix()
+	credentialUses.WithLabelValues(name).Inc()
+
+	return value, nil
+}
+
+// read returns the credential's stored value, or reads its reference
+func (m *Manager) read(c *credential) (string, error) {
+	if c.Ref == "" {
+		if c.Value == "" {
+			return "", fmt.Errorf("no value is set")
+		}
+		return c.Value, nil
+	}
+
+	parts := strings.SplitN(c.Ref, ":", 2)
+	var value string
+	switch parts[0] {
+	case RefEnv:
+		value = os.Getenv(parts[1])
+	case RefFile:
+		b, err := ioutil.ReadFile(parts[1])
+		if err != nil {
+			return "", err
+		}
+		value = strings.TrimSpace(string(b))
+	case RefVault:
+		if m.vault == nil {
+			return "", fmt.Errorf("vault is not configured")
+		}
+		value = m.vault[parts[1]]
+	default:
+		return "", fmt.Errorf("unknown reference %s", c.Ref)
+	}
+
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", c.Ref)
+	}
+	return value, nil
+}
+
+// Flush saves the uses counted since the last flush, then reloads the credentials, so that
+// quotas also count other instances' uses
+func (m *Manager) Flush() {
+	logger := m.logger.WithFields(logrus.Fields{
+		"package":  "credentials",
+		"function": "Flush",
+	})
+
+	type usage struct {
+		name     string
+		day      string
+		uses     uint64
+		lastUsed int64
+	}
+
+	m.mu.Lock()
+	var pending []usage
+	for name, c := range m.credentials {
+		if c.pending == 0 {
+			continue
+		}
+		pending = append(pending, usage{name: name, day: c.pendingDay, uses: c.pending, lastUsed: c.LastUsedAt})
+		c.pending = 0
+	}
+	m.mu.Unlock()
+
+	for _, u := range pending {
+		if err := m.db.AddAdapterCredentialUsage(u.name, u.day, u.uses, u.lastUsed); err != nil {
+			logger.WithField("credential", u.name).Error("cannot save usage: ", err.Error())
+		}
+	}
+
+	if err := m.Load(); err != nil {
+		logger.Error("cannot reload credentials: ", err.Error())
+	}
+}
+
+// Set sets a credential, rotating it if its value or reference has changed
+func (m *Manager) Set(name string, req go_ooo_types.AdapterCredentialRequest) (go_ooo_types.AdapterCredential, error) {
+	if err := ValidateName(name); err != nil {
+		return go_ooo_types.AdapterCredential{}, err
+	}
+	if (req.Value == "") == (req.Ref == "") {
+		return go_ooo_types.AdapterCredential{}, fmt.Errorf("exactly one of value or ref must be given")
+	}
+	if req.Ref != "" {
+		if err := ValidateRef(req.Ref); err != nil {
+			return go_ooo_types.AdapterCredential{}, err
+		}
+	}
+
+	row, err := m.db.UpsertAdapterCredential(name, req.Value, req.Ref, req.RotateAfterDays, req.DailyQuota)
+	if err != nil {
+		return go_ooo_types.AdapterCredential{}, err
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"package":    "credentials",
+		"function":   "Set",
+		"credential": name,
+		"version":    row.Version,
+	}).Info("adapter credential set")
+
+	if err = m.Load(); err != nil {
+		return go_ooo_types.AdapterCredential{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	loaded, ok := m.credentials[name]
+	if !ok {
+		// deleted by another instance since
+		loaded = &credential{AdapterCredentials: row}
+	}
+	return describe(loaded, time.Now()), nil
+}
+
+// Delete deletes a credential. Adapters referencing it fail until it is set again
+func (m *Manager) Delete(name string) error {
+	if err := m.db.DeleteAdapterCredential(name); err != nil {
+		return err
+	}
+	return m.Load()
+}
+
+// List describes every credential, without their values
+func (m *Manager) List() []go_ooo_types.AdapterCredential {
+	if m == nil {
+		return []go_ooo_types.AdapterCredential{}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	res := make([]go_ooo_types.AdapterCredential, 0, len(m.credentials))
+	for _, c := range m.credentials {
+		res = append(res, describe(c, now))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+func describe(c *credential, now time.Time) go_ooo_types.AdapterCredential {
+	usedToday := c.UsedToday
+	if c.UsageDay != now.UTC().Format("2006-01-02") {
+		usedToday = 0
+	}
+
+	rotationDue := false
+	if c.RotateAfterDays > 0 {
+		rotationDue = now.Sub(time.Unix(c.RotatedAt, 0)) > time.Duration(c.RotateAfterDays)*24*time.Hour
+	}
+
+	return go_ooo_types.AdapterCredential{
+		Name:            c.Name,
+		Ref:             c.Ref,
+		Version:         c.Version,
+		RotatedAt:       c.RotatedAt,
+		RotateAfterDays: c.RotateAfterDays,
+		RotationDue:     rotationDue,
+		DailyQuota:      c.DailyQuota,
+		UsedToday:       usedToday,
+		TotalUses:       c.TotalUses,
+		LastUsedAt:      c.LastUsedAt,
+		Error:           c.err,
+	}
+}
@@ -1,15 +1,16 @@
 package database
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/spf13/viper"
 	"go-ooo/config"
 	"go-ooo/database/models"
 	"gorm.io/driver/postgres"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
 	"log"
 	"os"
 	"time"
@@ -17,6 +18,9 @@ import (
 
 type DB struct {
 	*gorm.DB
+
+	// cipher encrypts sensitive columns, if database.encryption_key is set
+	cipher *fieldCipher
 }
 
 func NewDb() (*DB, error) {
@@ -29,23 +33,27 @@ func NewDb() (*DB, error) {
 			Colorful:                  false,       // Disable color
 		},
 	)
+	var db *DB
+	var err error
+
 	switch viper.GetString(config.DatabaseDialect) {
 	case "sqlite":
-		return NewSqliteDb(gormLogger)
+		db, err = NewSqliteDb(gormLogger)
 	case "postgres":
-		return NewPostgresDb(gormLogger)
+		db, err = NewPostgresDb(gormLogger)
 	default:
 		return nil, errors.New("no db dialect in config")
 	}
+
+	if err != nil || db == nil {
+		return db, err
+	}
+
+	return db, db.registerMetrics()
 }
 
 func NewSqliteDb(logger logger.Interface) (*DB, error) {
-	db, err := gorm.Open(sqlite.Open(viper.GetString(config.DatabaseStorage)), &gorm.Config{
-		Logger: logger,
-	})
-	return &DB{
-		db,
-	}, err
+	return openSqlite(viper.GetString(config.DatabaseStorage), logger)
 }
 
 func NewPostgresDb(logger logger.Interface) (*DB, error) {
@@ -69,24 +77,63 @@ func NewPostgresDb(logger logger.Interface) (*DB, error) {
 		return nil, err
 	}
 
-	return &DB{db}, nil
+	return &DB{DB: db}, nil
+}
+
+// allModels are the tables the database holds
+var allModels = []interface{}{
+	&models.DataRequests{},
+	&models.FailedFulfilment{},
+	&models.ToBlocks{},
+	&models.SupportedPairs{},
+	&models.DexTokens{},
+	&models.DexPairs{},
+	&models.TokenContracts{},
+	&models.VersionInfo{},
+	&models.Attestations{},
+	&models.VorRequests{},
+	&models.JournalEntries{},
+	&models.AuditLog{},
+	&models.ConsumerMetadata{},
+	&models.PairMetadata{},
+	&models.MetricSnapshots{},
+	&models.RequestSources{},
+	&models.AdapterCredentials{},
+	&models.Reconciliations{},
+	&models.ChainEvents{},
+	&models.DexSyncCursors{},
+	&models.SubgraphSpend{},
+	&models.MaintenanceWindows{},
+	&models.GasSpends{},
+	&models.PairDelistings{},
+	&models.StateSnapshots{},
+	&models.PairChanges{},
+}
+
+// TableNames returns the names of the tables the database holds
+func TableNames() []string {
+	names := make([]string, 0, len(allModels))
+	for _, m := range allModels {
+		names = append(names, m.(schema.Tabler).TableName())
+	}
+	return names
 }
 
 func (d *DB) Migrate() (err error) {
 	// migrate models
-	err = d.AutoMigrate(
-		&models.DataRequests{},
-		&models.FailedFulfilment{},
-		&models.ToBlocks{},
-		&models.SupportedPairs{},
-		&models.DexTokens{},
-		&models.DexPairs{},
-		&models.TokenContracts{},
-		&models.VersionInfo{},
-	)
+	err = d.AutoMigrate(allModels...)
 
 	// post-model data migration
 	d.MigrateV0ToV1()
 
 	return
 }
+
+// Ping checks the database connection is alive
+func (d *DB) Ping(ctx context.Context) error {
+	sqlDb, err := d.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDb.PingContext(ctx)
+}
@@ -0,0 +1,235 @@
+package database
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-ooo/database/models"
+	"io"
+	"strings"
+)
+
+// encryptedPrefix marks a column value encrypted by fieldCipher. It is followed by the id of the
+// key it was encrypted with, then the base64 encoded nonce and ciphertext, e.g.
+//
+//	enc:v1:3fa27c1b:<base64>
+//
+// Values without the prefix are plaintext, written before encryption was enabled, and are read
+// as they are
+const encryptedPrefix = "enc:v1:"
+
+// fieldCipher encrypts sensitive columns - consumer and pair notes, adapter credentials, and
+// audit log parameters - with AES-256-GCM. Values are encrypted with the current key, and may be decrypted with it or
+// any previous key, so that keys can be rotated without losing existing rows
+type fieldCipher struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// DecodeEncryptionKey decodes a base64 encoded 32 byte AES-256 key
+func DecodeEncryptionKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("encryption key is not base64: %s", err.Error())
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key is %d bytes, expected 32", len(key))
+	}
+	return key, nil
+}
+
+// encryptionKeyId - the first 4 bytes of the key's sha256, identifying the key a value was
+// encrypted with without revealing it
+func encryptionKeyId(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4])
+}
+
+func newFieldCipher(current string, previous []string) (*fieldCipher, error) {
+	c := &fieldCipher{keys: make(map[string]cipher.AEAD)}
+	for i, encoded := range append([]string{current}, previous...) {
+		key, err := DecodeEncryptionKey(encoded)
+		if err != nil {
+			return nil, err
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		id := encryptionKeyId(key)
+		if i == 0 {
+			c.current = id
+		}
+		c.keys[id] = aead
+	}
+	return c, nil
+}
+
+// SetEncryptionKeys enables encryption of sensitive columns with key, the base64 encoded 32 byte
+// key. previous keys are only used to decrypt values written before the key was rotated. An
+// empty key disables encryption, though values already encrypted can then not be read
+func (d *DB) SetEncryptionKeys(key string, previous []string) error {
+	if key == "" {
+		d.cipher = nil
+		return nil
+	}
+	c, err := newFieldCipher(key, previous)
+	if err != nil {
+		return err
+	}
+	d.cipher = c
+	return nil
+}
+
+// encrypt returns value encrypted with the current key, or value if encryption is not enabled
+func (d *DB) encrypt(value string) (string, error) {
+	if d.cipher == nil || value == "" {
+		return value, nil
+	}
+	aead := d.cipher.keys[d.cipher.current]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + d.cipher.current + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt returns the plaintext of an encrypted value. Plaintext values are returned as they are
+func (d *DB) decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	if d.cipher == nil {
+		return "", errors.New("value is encrypted, but no database.encryption_key is set")
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(value, encryptedPrefix), ":", 2)
+	if len(parts) != 2 {
+		return "", errors.New("malformed encrypted value")
+	}
+	aead, ok := d.cipher.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("value is encrypted with unknown key %s - add it to database.encryption_previous_keys", parts[0])
+	}
+	sealed, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt value with key %s: %s", parts[0], err.Error())
+	}
+	return string(plain), nil
+}
+
+// needsEncryption returns true if value is plaintext, or encrypted with a previous key
+func (d *DB) needsEncryption(value string) bool {
+	if value == "" {
+		return false
+	}
+	return !strings.HasPrefix(value, encryptedPrefix+d.cipher.current+":")
+}
+
+// reencrypt decrypts value, and encrypts it with the current key
+func (d *DB) reencrypt(value string) (string, error) {
+	plain, err := d.decrypt(value)
+	if err != nil {
+		return "", err
+	}
+	return d.encrypt(plain)
+}
+
+// EncryptSensitiveColumns encrypts, with the current key, every sensitive value which is still
+// plaintext or was encrypted with a previous key. It returns the number of rows updated. It does
+// nothing if encryption is not enabled
+func (d *DB) EncryptSensitiveColumns() (int, error) {
+	if d.cipher == nil {
+		return 0, nil
+	}
+
+	updated := 0
+
+	var consumers []models.ConsumerMetadata
+	if err := d.Find(&consumers).Error; err != nil {
+		return updated, err
+	}
+	for _, m := range consumers {
+		if !d.needsEncryption(m.Note) {
+			continue
+		}
+		note, err := d.reencrypt(m.Note)
+		if err != nil {
+			return updated, fmt.Errorf("consumer %s note: %s", m.Consumer, err.Error())
+		}
+		if err = d.Model(&m).UpdateColumn("note", note).Error; err != nil {
+			return updated, err
+		}
+		updated++
+	}
+
+	var pairs []models.PairMetadata
+	if err := d.Find(&pairs).Error; err != nil {
+		return updated, err
+	}
+	for _, m := range pairs {
+		if !d.needsEncryption(m.Note) {
+			continue
+		}
+		note, err := d.reencrypt(m.Note)
+		if err != nil {
+			return updated, fmt.Errorf("pair %s note: %s", m.Pair, err.Error())
+		}
+		if err = d.Model(&m).UpdateColumn("note", note).Error; err != nil {
+			return updated, err
+		}
+		updated++
+	}
+
+	var credentials []models.AdapterCredentials
+	if err := d.Find(&credentials).Error; err != nil {
+		return updated, err
+	}
+	for _, c := range credentials {
+		if !d.needsEncryption(c.Value) {
+			continue
+		}
+		value, err := d.reencrypt(c.Value)
+		if err != nil {
+			return updated, fmt.Errorf("credential %s value: %s", c.Name, err.Error())
+		}
+		if err = d.Model(&c).UpdateColumn("value", value).Error; err != nil {
+			return updated, err
+		}
+		updated++
+	}
+
+	var entries []models.AuditLog
+	if err := d.Select("id", "params").Find(&entries).Error; err != nil {
+		return updated, err
+	}
+	for _, e := range entries {
+		if !d.needsEncryption(e.Params) {
+			continue
+		}
+		params, err := d.reencrypt(e.Params)
+		if err != nil {
+			return updated, fmt.Errorf("audit log %d params: %s", e.ID, err.Error())
+		}
+		if err = d.Model(&models.AuditLog{}).Where("id = ?", e.ID).UpdateColumn("params", params).Error; err != nil {
+			return updated, err
+		}
+		updated++
+	}
+
+	return updated, nil
+}
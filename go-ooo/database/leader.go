@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+)
+
+// LeaderLock is a Postgres session level advisory lock, used to elect a single leader
+// between go-ooo instances sharing the same database. The lock is held for as long as
+// the dedicated connection it was acquired on remains open
+type LeaderLock struct {
+	db   *sql.DB
+	conn *sql.Conn
+	key  int64
+}
+
+func (d *DB) NewLeaderLock(key int64) (*LeaderLock, error) {
+	if viper.GetString(config.DatabaseDialect) != "postgres" {
+		return nil, errors.New("leader election requires the postgres database dialect")
+	}
+
+	sqlDb, err := d.DB.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	return &LeaderLock{
+		db:  sqlDb,
+		key: key,
+	}, nil
+}
+
+// TryAcquire attempts to take the lock without blocking. Returns true if this instance holds the lock
+func (l *LeaderLock) TryAcquire(ctx context.Context) (bool, error) {
+	if l.conn != nil {
+		return true, l.Check(ctx)
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired)
+	if err != nil || !acquired {
+		_ = conn.Close()
+		return false, err
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+// Check returns an error if the lock's connection has been lost, in which case
+// the lock may now be held by another instance
+func (l *LeaderLock) Check(ctx context.Context) error {
+	if l.conn == nil {
+		return errors.New("leader lock not held")
+	}
+
+	err := l.conn.PingContext(ctx)
+	if err != nil {
+		_ = l.conn.Close()
+		l.conn = nil
+	}
+
+	return err
+}
+
+// Release gives up the lock, allowing a standby instance to take over
+func (l *LeaderLock) Release(ctx context.Context) error {
+	if l.conn == nil {
+		return nil
+	}
+
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	_ = l.conn.Close()
+	l.conn = nil
+
+	return err
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+	"time"
+)
+
+const metricsStartKey = "metrics:start"
+
+var dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "ooo_db_query_duration_seconds",
+	Help:    "Time taken by database queries, by operation and table",
+	Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+}, []string{"operation", "table"})
+
+// registerMetrics times every query made through gorm
+func (d *DB) registerMetrics() error {
+	before := func(db *gorm.DB) {
+		db.InstanceSet(metricsStartKey, time.Now())
+	}
+
+	after := func(operation string) func(db *gorm.DB) {
+		return func(db *gorm.DB) {
+			v, ok := db.InstanceGet(metricsStartKey)
+			if !ok {
+				return
+			}
+			start, ok := v.(time.Time)
+			if !ok {
+				return
+			}
+			dbQueryDuration.WithLabelValues(operation, db.Statement.Table).Observe(time.Since(start).Seconds())
+		}
+	}
+
+	cb := d.Callback()
+
+	if err := cb.Create().Before("gorm:create").Register("metrics:before_create", before); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("metrics:after_create", after("create")); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("metrics:before_query", before); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("metrics:after_query", after("query")); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("metrics:before_update", before); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("metrics:after_update", after("update")); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("metrics:before_delete", before); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("metrics:after_delete", after("delete")); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("metrics:before_row", before); err != nil {
+		return err
+	}
+	return cb.Row().After("gorm:row").Register("metrics:after_row", after("row"))
+}
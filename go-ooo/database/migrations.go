@@ -1,16 +1,66 @@
 package database
 
+import (
+	"errors"
+	"fmt"
+	"go-ooo/database/models"
+	"gorm.io/gorm"
+)
+
 /*
   Migrations
 */
 
+// LatestDbSchemaVersion is the schema version Migrate brings the database up to
+const LatestDbSchemaVersion uint64 = 1
+
+// SchemaMismatchError is returned by CheckSchemaVersion if the database's schema is not the
+// version this build expects
+type SchemaMismatchError struct {
+	Version  uint64
+	Expected uint64
+}
+
+func (e SchemaMismatchError) Error() string {
+	if e.Version > e.Expected {
+		return fmt.Sprintf("database schema is v%d, which is newer than this version of go-ooo supports (v%d). "+
+			"Upgrade go-ooo, or restore a backup of the database from before it was migrated", e.Version, e.Expected)
+	}
+	return fmt.Sprintf("database schema is v%d, but this version of go-ooo requires v%d. "+
+		"Back up the database, then run 'go-ooo migrate' to upgrade it", e.Version, e.Expected)
+}
+
+// CheckSchemaVersion checks the database's schema is at LatestDbSchemaVersion, without migrating
+// it. A new, empty database passes, as Migrate creates its schema at the latest version. Databases
+// created before schema versions were recorded are v0
+func (d *DB) CheckSchemaVersion() error {
+	if !d.Migrator().HasTable(&models.DataRequests{}) {
+		return nil
+	}
+
+	var version uint64
+	if d.Migrator().HasTable(&models.VersionInfo{}) {
+		v, err := d.getCurrentDbSchemaVersion()
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		version = v.CurrentVersion
+	}
+
+	if version != LatestDbSchemaVersion {
+		return SchemaMismatchError{Version: version, Expected: LatestDbSchemaVersion}
+	}
+
+	return nil
+}
+
 // Schema V0 to V1
 
 func (d *DB) MigrateV0ToV1() {
 	dbVers, _ := d.getCurrentDbSchemaVersion()
 	if dbVers.CurrentVersion == 0 {
 		d.V0ToV1DeleteAdhocTokenData()
-		_ = d.setDbSchemaVersion(1)
+		_ = d.setDbSchemaVersion(LatestDbSchemaVersion)
 	}
 }
 
@@ -0,0 +1,5 @@
+// Package mock provides a mock database.Store, so that the chain and data source logic can be
+// tested without a database. Regenerate store.go after changing the database.Store interface
+package mock
+
+//go:generate go run gen.go
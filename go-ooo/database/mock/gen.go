@@ -0,0 +1,223 @@
+//go:build ignore
+// +build ignore
+
+// gen writes store.go - a mock of the database.Store interface - from the interfaces in
+// database/store.go. Run with "go generate ./database/..." or "make mocks"
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	source   = "../store.go"
+	out      = "store.go"
+	dbImport = "go-ooo/database"
+)
+
+type method struct {
+	name    string
+	params  []string
+	types   []string
+	results []string
+}
+
+func main() {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, source, nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	imports := make(map[string]string)
+	for _, imp := range f.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = path
+	}
+
+	interfaces := make(map[string]*ast.InterfaceType)
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if it, ok := ts.Type.(*ast.InterfaceType); ok {
+				interfaces[ts.Name.Name] = it
+			}
+		}
+	}
+
+	used := map[string]string{"database": dbImport, "sync": "sync"}
+	methods := collect(interfaces, "Store", imports, used)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen.go - DO NOT EDIT.\n")
+	buf.WriteString("// This file is generated from database/store.go by \"go generate ./database/...\".\n\n")
+	buf.WriteString("package mock\n\nimport (\n")
+	var paths []string
+	for _, path := range used {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "\t%q\n", path)
+	}
+	buf.WriteString(")\n\n")
+
+	buf.WriteString("// Call is a call made to the mock, with its arguments\n")
+	buf.WriteString("type Call struct {\n\tMethod string\n\tArgs   []interface{}\n}\n\n")
+	buf.WriteString("// Store is a database.Store which calls the function set for each method, and records each\n")
+	buf.WriteString("// call. Methods without a function return zero values\n")
+	buf.WriteString("type Store struct {\n")
+	for _, m := range methods {
+		fmt.Fprintf(&buf, "\t%sFunc func(%s) %s\n", m.name, strings.Join(m.types, ", "), resultList(m.results, false))
+	}
+	buf.WriteString("\n\tmu    sync.Mutex\n\tcalls []Call\n}\n\n")
+	buf.WriteString("var _ database.Store = (*Store)(nil)\n\n")
+
+	buf.WriteString("// Calls returns the calls made to the mock, in order\n")
+	buf.WriteString("func (m *Store) Calls() []Call {\n\tm.mu.Lock()\n\tdefer m.mu.Unlock()\n\treturn append([]Call(nil), m.calls...)\n}\n\n")
+	buf.WriteString("// CallsTo returns the calls made to method, in order\n")
+	buf.WriteString("func (m *Store) CallsTo(method string) []Call {\n\tvar calls []Call\n\tfor _, c := range m.Calls() {\n")
+	buf.WriteString("\t\tif c.Method == method {\n\t\t\tcalls = append(calls, c)\n\t\t}\n\t}\n\treturn calls\n}\n\n")
+	buf.WriteString("func (m *Store) record(method string, args ...interface{}) {\n\tm.mu.Lock()\n\tdefer m.mu.Unlock()\n")
+	buf.WriteString("\tm.calls = append(m.calls, Call{Method: method, Args: args})\n}\n")
+
+	for _, m := range methods {
+		var params, args []string
+		for i, p := range m.params {
+			params = append(params, p+" "+m.types[i])
+			if strings.HasPrefix(m.types[i], "...") {
+				args = append(args, p+"...")
+			} else {
+				args = append(args, p)
+			}
+		}
+		fmt.Fprintf(&buf, "\nfunc (m *Store) %s(%s) %s {\n", m.name, strings.Join(params, ", "), resultList(m.results, true))
+		fmt.Fprintf(&buf, "\tm.record(%s)\n", strings.Join(append([]string{strconv.Quote(m.name)}, m.params...), ", "))
+		fmt.Fprintf(&buf, "\tif m.%sFunc == nil {\n\t\treturn\n\t}\n", m.name)
+		if len(m.results) == 0 {
+			fmt.Fprintf(&buf, "\tm.%sFunc(%s)\n}\n", m.name, strings.Join(args, ", "))
+		} else {
+			fmt.Fprintf(&buf, "\treturn m.%sFunc(%s)\n}\n", m.name, strings.Join(args, ", "))
+		}
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = ioutil.WriteFile(out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// collect returns the methods of interface name, including those of embedded interfaces, in
+// declaration order
+func collect(interfaces map[string]*ast.InterfaceType, name string, imports map[string]string, used map[string]string) []method {
+	it, ok := interfaces[name]
+	if !ok {
+		log.Fatalf("interface %s not found in %s", name, source)
+	}
+
+	var methods []method
+	for _, field := range it.Methods.List {
+		ft, ok := field.Type.(*ast.FuncType)
+		if !ok {
+			embedded, ok := field.Type.(*ast.Ident)
+			if !ok {
+				log.Fatalf("%s: cannot mock embedded %T", name, field.Type)
+			}
+			methods = append(methods, collect(interfaces, embedded.Name, imports, used)...)
+			continue
+		}
+
+		m := method{name: field.Names[0].Name}
+		for _, p := range ft.Params.List {
+			t := typeString(p.Type, imports, used)
+			if len(p.Names) == 0 {
+				m.params = append(m.params, fmt.Sprintf("p%d", len(m.params)))
+				m.types = append(m.types, t)
+			}
+			for _, n := range p.Names {
+				m.params = append(m.params, n.Name)
+				m.types = append(m.types, t)
+			}
+		}
+		if ft.Results != nil {
+			for _, r := range ft.Results.List {
+				t := typeString(r.Type, imports, used)
+				for i := 0; i < len(r.Names) || (i == 0 && len(r.Names) == 0); i++ {
+					m.results = append(m.results, t)
+				}
+			}
+		}
+		methods = append(methods, m)
+	}
+
+	return methods
+}
+
+// typeString prints the type expr as used in the mock package, qualifying the database
+// package's own types and noting the packages used
+func typeString(expr ast.Expr, imports map[string]string, used map[string]string) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if ast.IsExported(t.Name) {
+			return "database." + t.Name
+		}
+		return t.Name
+	case *ast.SelectorExpr:
+		pkg := t.X.(*ast.Ident).Name
+		used[pkg] = imports[pkg]
+		return pkg + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + typeString(t.X, imports, used)
+	case *ast.ArrayType:
+		return "[]" + typeString(t.Elt, imports, used)
+	case *ast.MapType:
+		return "map[" + typeString(t.Key, imports, used) + "]" + typeString(t.Value, imports, used)
+	case *ast.Ellipsis:
+		return "..." + typeString(t.Elt, imports, used)
+	case *ast.InterfaceType:
+		return "interface{}"
+	}
+	log.Fatalf("cannot mock type %T", expr)
+	return ""
+}
+
+// resultList prints results as a method's result list, with names if named is true so that
+// the method can return zero values
+func resultList(results []string, named bool) string {
+	if len(results) == 0 {
+		return ""
+	}
+	var list []string
+	for i, r := range results {
+		if named {
+			list = append(list, fmt.Sprintf("r%d %s", i, r))
+		} else {
+			list = append(list, r)
+		}
+	}
+	if len(list) == 1 && !named {
+		return list[0]
+	}
+	return "(" + strings.Join(list, ", ") + ")"
+}
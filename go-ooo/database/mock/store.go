@@ -0,0 +1,1051 @@
+// Code generated by gen.go - DO NOT EDIT.
+// This file is generated from database/store.go by "go generate ./database/...".
+
+package mock
+
+import (
+	"context"
+	"go-ooo/database"
+	"go-ooo/database/models"
+	"sync"
+	"time"
+)
+
+// Call is a call made to the mock, with its arguments
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Store is a database.Store which calls the function set for each method, and records each
+// call. Methods without a function return zero values
+type Store struct {
+	GetLastBlockNumQueriedFunc         func() (models.ToBlocks, error)
+	InsertNewToBlockFunc               func(uint64) error
+	FindByRequestIdFunc                func(string) (models.DataRequests, error)
+	InsertNewRequestFunc               func(string, string, string, string, string, string, uint64, uint64, uint64, uint64, bool) error
+	InsertNewRequestsFunc              func([]models.DataRequests) error
+	GetPendingJobsFunc                 func() ([]models.DataRequests, error)
+	GetStuckJobsFunc                   func(int, time.Time) ([]models.DataRequests, error)
+	GetFulfilledRequestsSinceFunc      func(time.Time) ([]models.DataRequests, error)
+	GetRequestsReceivedBetweenFunc     func(time.Time, time.Time) ([]models.DataRequests, error)
+	CountUnsentJobsFunc                func() (int64, error)
+	CountInFlightTxsFunc               func() (int64, error)
+	UpdateRequestStatusFunc            func(string, int, string, string) error
+	UpdateRequestRetryFunc             func(string, int, string, string, int64) error
+	UpdateRequestRejectedFunc          func(string, string, string, string) error
+	UpdateLastDataFetchBlockNumberFunc func(string, uint64) error
+	UpdateDataFetchedFunc              func(string, string, string, string, string) error
+	UpdateRepricedResultFunc           func(string, string, string, string, string) error
+	UpdateFulfillmentSentFunc          func(string, string, uint64) error
+	UpdateFulfillmentSuccessFunc       func(string, uint64, string, uint64, uint64) error
+	UpdateManualFulfillmentFunc        func(string, string, string) error
+	IncrementFulfillmentAttemptsFunc   func(string) error
+	ResetRequestForReplayFunc          func(string) error
+	InsertNewFailedFulfilmentFunc      func(string, string, uint64, uint64, string) error
+	UpsertAttestationFunc              func(string, string, string, int64, string, string, string, string) error
+	GetAttestationByRequestIdFunc      func(string) (models.Attestations, error)
+	GetAttestationsByRequestIdsFunc    func([]string) ([]models.Attestations, error)
+	UpdateAttestationCidFunc           func(string, string) error
+	InsertJournalEntryFunc             func(string, string, string, uint64, string, uint64, string) error
+	GetUnresolvedTxIntentsFunc         func() ([]models.JournalEntries, error)
+	MarkTxIntentBroadcastFunc          func(string) error
+	ResolveTxIntentFunc                func(string) error
+	InsertNewVorRequestFunc            func(string, string, string, string, string, uint64, string, string) error
+	FindVorRequestByRequestIdFunc      func(string) (models.VorRequests, error)
+	GetPendingVorRequestsFunc          func() ([]models.VorRequests, error)
+	UpdateVorRequestStatusFunc         func(string, int, string) error
+	UpdateVorFulfillmentSentFunc       func(string, string, uint64, string) error
+	UpdateVorFulfillmentSuccessFunc    func(string, uint64, string, string) error
+	GetConsumerMetadataFunc            func(string) (models.ConsumerMetadata, error)
+	GetPairMetadataFunc                func(string) (models.PairMetadata, error)
+	GetMetricSnapshotsFunc             func() ([]models.MetricSnapshots, error)
+	SaveMetricSnapshotsFunc            func([]models.MetricSnapshots) error
+	SaveRequestSourcesFunc             func(string, []models.RequestSources) error
+	GetLatestReconciliationFunc        func() (models.Reconciliations, error)
+	GetReconciliationsFunc             func(int) ([]models.Reconciliations, error)
+	InsertReconciliationFunc           func(models.Reconciliations) error
+	InsertChainEventFunc               func(models.ChainEvents) error
+	DeleteChainEventFunc               func(string, uint) error
+	GetChainEventsFunc                 func(string, int) ([]models.ChainEvents, error)
+	InsertGasSpendFunc                 func(models.GasSpends) error
+	GetLatestStateSnapshotFunc         func() (models.StateSnapshots, error)
+	InsertStateSnapshotFunc            func(models.StateSnapshots) error
+	UpdateStateSnapshotVerifiedFunc    func(uint, string) error
+	CountPendingJobsForProviderFunc    func(string) (int64, error)
+	GetDeadJobsFunc                    func(int) ([]models.DataRequests, error)
+	GetLastFulfilledForPairFunc        func(string, string) (models.DataRequests, error)
+	GetLatestFulfilledPerEndpointFunc  func() ([]models.DataRequests, error)
+	SearchJobsFunc                     func(database.JobFilter) ([]models.DataRequests, error)
+	FindByTxHashFunc                   func(string) (models.DataRequests, error)
+	GetFulfilledRequestsBetweenFunc    func(time.Time, time.Time) ([]models.DataRequests, error)
+	GetGasSpendsBetweenFunc            func(time.Time, time.Time) ([]models.GasSpends, error)
+	GetGasSpendsForRequestFunc         func(string) ([]models.GasSpends, error)
+	GetRecentAdhocEndpointsFunc        func(time.Time) ([]string, error)
+	GetLastXSuccessfulRequestsFunc     func(int, string) ([]models.DataRequests, error)
+	GetMostGasUsedFunc                 func() (models.DataRequests, error)
+	GetLeastGasUsedFunc                func() (models.DataRequests, error)
+	RequeueFailedRequestFunc           func(string) error
+	GetJournalEntriesForRequestFunc    func(string) ([]models.JournalEntries, error)
+	GetRequestSourcesFunc              func(string) ([]models.RequestSources, error)
+	CountSupportedPairsFunc            func() (int64, error)
+	GetSupportedPairsFunc              func() ([]models.SupportedPairs, error)
+	PairIsSupportedByPairNameFunc      func(string) (models.SupportedPairs, error)
+	PairIsSupportedByBaseAndTargetFunc func(string, string) (models.SupportedPairs, error)
+	PairsNoLongerSupportedFunc         func([]string) ([]models.SupportedPairs, error)
+	GetRequestsForPairSinceFunc        func(string, string, time.Time) ([]models.DataRequests, error)
+	GetPairDelistingsFunc              func(time.Time) ([]models.PairDelistings, error)
+	GetUnnotifiedPairDelistingsFunc    func() ([]models.PairDelistings, error)
+	InsertPairDelistingsFunc           func([]models.PairDelistings) error
+	MarkPairDelistingsNotifiedFunc     func([]uint) error
+	GetPairChangesFunc                 func(database.PairChangeFilter) ([]models.PairChanges, error)
+	InsertPairChangesFunc              func([]models.PairChanges) error
+	AddNewSupportedPairFunc            func(string, string, string) error
+	DeleteSupportedPairFunc            func(models.SupportedPairs) error
+	FindByDexPairNameFunc              func(string, string, string) (models.DexPairs, error)
+	FindDexPairByContractFunc          func(string, string) (models.DexPairs, error)
+	GetDexPairsByDexNameFunc           func(string) ([]models.DexPairs, error)
+	SyncDexPairsFunc                   func(string, string, []database.DexPairSync) error
+	GetDexSyncCursorFunc               func(string) (models.DexSyncCursors, error)
+	UpdateDexSyncCursorFunc            func(string, uint64, bool) error
+	GetSubgraphSpendFunc               func(string) ([]models.SubgraphSpend, error)
+	AddSubgraphSpendFunc               func(string, string, uint64, float64) error
+	UpdateDexPairReserveUsdFunc        func(string, string, float64) error
+	UpdateDexPairVolumeUsdFunc         func(string, string, float64) error
+	InsertAuditLogFunc                 func(string, string, string, string, string, string, bool, string) error
+	SearchAuditLogFunc                 func(database.AuditFilter) ([]models.AuditLog, error)
+	GetActiveMaintenanceWindowFunc     func() (models.MaintenanceWindows, error)
+	StartMaintenanceWindowFunc         func(string, string, int64) (models.MaintenanceWindows, error)
+	EndMaintenanceWindowFunc           func(string) error
+	GetAllConsumerMetadataFunc         func() ([]models.ConsumerMetadata, error)
+	GetAllPairMetadataFunc             func() ([]models.PairMetadata, error)
+	UpsertConsumerMetadataFunc         func(string, []string, string) error
+	DeleteConsumerMetadataFunc         func(string) error
+	UpsertPairMetadataFunc             func(string, []string, string) error
+	DeletePairMetadataFunc             func(string) error
+	GetAllAdapterCredentialsFunc       func() ([]models.AdapterCredentials, error)
+	UpsertAdapterCredentialFunc        func(string, string, string, uint64, uint64) (models.AdapterCredentials, error)
+	AddAdapterCredentialUsageFunc      func(string, string, uint64, int64) error
+	DeleteAdapterCredentialFunc        func(string) error
+	GetDbSchemaVersionFunc             func() (uint64, error)
+	VacuumFunc                         func(context.Context, []string) error
+	NewLeaderLockFunc                  func(int64) (*database.LeaderLock, error)
+	PingFunc                           func(context.Context) error
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ database.Store = (*Store)(nil)
+
+// Calls returns the calls made to the mock, in order
+func (m *Store) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the calls made to method, in order
+func (m *Store) CallsTo(method string) []Call {
+	var calls []Call
+	for _, c := range m.Calls() {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+func (m *Store) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+func (m *Store) GetLastBlockNumQueried() (r0 models.ToBlocks, r1 error) {
+	m.record("GetLastBlockNumQueried")
+	if m.GetLastBlockNumQueriedFunc == nil {
+		return
+	}
+	return m.GetLastBlockNumQueriedFunc()
+}
+
+func (m *Store) InsertNewToBlock(toBlock uint64) (r0 error) {
+	m.record("InsertNewToBlock", toBlock)
+	if m.InsertNewToBlockFunc == nil {
+		return
+	}
+	return m.InsertNewToBlockFunc(toBlock)
+}
+
+func (m *Store) FindByRequestId(requestId string) (r0 models.DataRequests, r1 error) {
+	m.record("FindByRequestId", requestId)
+	if m.FindByRequestIdFunc == nil {
+		return
+	}
+	return m.FindByRequestIdFunc(requestId)
+}
+
+func (m *Store) InsertNewRequest(provider string, consumer string, requestId string, endpoint string, endpointDecoded string, txHash string, gasUsed uint64, gasPrice uint64, fee uint64, blockNumber uint64, isAdhoc bool) (r0 error) {
+	m.record("InsertNewRequest", provider, consumer, requestId, endpoint, endpointDecoded, txHash, gasUsed, gasPrice, fee, blockNumber, isAdhoc)
+	if m.InsertNewRequestFunc == nil {
+		return
+	}
+	return m.InsertNewRequestFunc(provider, consumer, requestId, endpoint, endpointDecoded, txHash, gasUsed, gasPrice, fee, blockNumber, isAdhoc)
+}
+
+func (m *Store) InsertNewRequests(requests []models.DataRequests) (r0 error) {
+	m.record("InsertNewRequests", requests)
+	if m.InsertNewRequestsFunc == nil {
+		return
+	}
+	return m.InsertNewRequestsFunc(requests)
+}
+
+func (m *Store) GetPendingJobs() (r0 []models.DataRequests, r1 error) {
+	m.record("GetPendingJobs")
+	if m.GetPendingJobsFunc == nil {
+		return
+	}
+	return m.GetPendingJobsFunc()
+}
+
+func (m *Store) GetStuckJobs(status int, before time.Time) (r0 []models.DataRequests, r1 error) {
+	m.record("GetStuckJobs", status, before)
+	if m.GetStuckJobsFunc == nil {
+		return
+	}
+	return m.GetStuckJobsFunc(status, before)
+}
+
+func (m *Store) GetFulfilledRequestsSince(since time.Time) (r0 []models.DataRequests, r1 error) {
+	m.record("GetFulfilledRequestsSince", since)
+	if m.GetFulfilledRequestsSinceFunc == nil {
+		return
+	}
+	return m.GetFulfilledRequestsSinceFunc(since)
+}
+
+func (m *Store) GetRequestsReceivedBetween(from time.Time, to time.Time) (r0 []models.DataRequests, r1 error) {
+	m.record("GetRequestsReceivedBetween", from, to)
+	if m.GetRequestsReceivedBetweenFunc == nil {
+		return
+	}
+	return m.GetRequestsReceivedBetweenFunc(from, to)
+}
+
+func (m *Store) CountUnsentJobs() (r0 int64, r1 error) {
+	m.record("CountUnsentJobs")
+	if m.CountUnsentJobsFunc == nil {
+		return
+	}
+	return m.CountUnsentJobsFunc()
+}
+
+func (m *Store) CountInFlightTxs() (r0 int64, r1 error) {
+	m.record("CountInFlightTxs")
+	if m.CountInFlightTxsFunc == nil {
+		return
+	}
+	return m.CountInFlightTxsFunc()
+}
+
+func (m *Store) UpdateRequestStatus(requestId string, status int, code string, reason string) (r0 error) {
+	m.record("UpdateRequestStatus", requestId, status, code, reason)
+	if m.UpdateRequestStatusFunc == nil {
+		return
+	}
+	return m.UpdateRequestStatusFunc(requestId, status, code, reason)
+}
+
+func (m *Store) UpdateRequestRetry(requestId string, status int, code string, reason string, nextRetryAt int64) (r0 error) {
+	m.record("UpdateRequestRetry", requestId, status, code, reason, nextRetryAt)
+	if m.UpdateRequestRetryFunc == nil {
+		return
+	}
+	return m.UpdateRequestRetryFunc(requestId, status, code, reason, nextRetryAt)
+}
+
+func (m *Store) UpdateRequestRejected(requestId string, code string, errorCode string, reason string) (r0 error) {
+	m.record("UpdateRequestRejected", requestId, code, errorCode, reason)
+	if m.UpdateRequestRejectedFunc == nil {
+		return
+	}
+	return m.UpdateRequestRejectedFunc(requestId, code, errorCode, reason)
+}
+
+func (m *Store) UpdateLastDataFetchBlockNumber(requestId string, blockNum uint64) (r0 error) {
+	m.record("UpdateLastDataFetchBlockNumber", requestId, blockNum)
+	if m.UpdateLastDataFetchBlockNumberFunc == nil {
+		return
+	}
+	return m.UpdateLastDataFetchBlockNumberFunc(requestId, blockNum)
+}
+
+func (m *Store) UpdateDataFetched(requestId string, price string, rounding string, sourcePolicy string, sourceTier string) (r0 error) {
+	m.record("UpdateDataFetched", requestId, price, rounding, sourcePolicy, sourceTier)
+	if m.UpdateDataFetchedFunc == nil {
+		return
+	}
+	return m.UpdateDataFetchedFunc(requestId, price, rounding, sourcePolicy, sourceTier)
+}
+
+func (m *Store) UpdateRepricedResult(requestId string, price string, rounding string, sourcePolicy string, sourceTier string) (r0 error) {
+	m.record("UpdateRepricedResult", requestId, price, rounding, sourcePolicy, sourceTier)
+	if m.UpdateRepricedResultFunc == nil {
+		return
+	}
+	return m.UpdateRepricedResultFunc(requestId, price, rounding, sourcePolicy, sourceTier)
+}
+
+func (m *Store) UpdateFulfillmentSent(requestId string, txHash string, blockNumber uint64) (r0 error) {
+	m.record("UpdateFulfillmentSent", requestId, txHash, blockNumber)
+	if m.UpdateFulfillmentSentFunc == nil {
+		return
+	}
+	return m.UpdateFulfillmentSentFunc(requestId, txHash, blockNumber)
+}
+
+func (m *Store) UpdateFulfillmentSuccess(requestId string, blockNumber uint64, txHash string, gasUsed uint64, gasPrice uint64) (r0 error) {
+	m.record("UpdateFulfillmentSuccess", requestId, blockNumber, txHash, gasUsed, gasPrice)
+	if m.UpdateFulfillmentSuccessFunc == nil {
+		return
+	}
+	return m.UpdateFulfillmentSuccessFunc(requestId, blockNumber, txHash, gasUsed, gasPrice)
+}
+
+func (m *Store) UpdateManualFulfillment(requestId string, price string, reason string) (r0 error) {
+	m.record("UpdateManualFulfillment", requestId, price, reason)
+	if m.UpdateManualFulfillmentFunc == nil {
+		return
+	}
+	return m.UpdateManualFulfillmentFunc(requestId, price, reason)
+}
+
+func (m *Store) IncrementFulfillmentAttempts(requestId string) (r0 error) {
+	m.record("IncrementFulfillmentAttempts", requestId)
+	if m.IncrementFulfillmentAttemptsFunc == nil {
+		return
+	}
+	return m.IncrementFulfillmentAttemptsFunc(requestId)
+}
+
+func (m *Store) ResetRequestForReplay(requestId string) (r0 error) {
+	m.record("ResetRequestForReplay", requestId)
+	if m.ResetRequestForReplayFunc == nil {
+		return
+	}
+	return m.ResetRequestForReplayFunc(requestId)
+}
+
+func (m *Store) InsertNewFailedFulfilment(requestId string, txHash string, gasUsed uint64, gasPrice uint64, reason string) (r0 error) {
+	m.record("InsertNewFailedFulfilment", requestId, txHash, gasUsed, gasPrice, reason)
+	if m.InsertNewFailedFulfilmentFunc == nil {
+		return
+	}
+	return m.InsertNewFailedFulfilmentFunc(requestId, txHash, gasUsed, gasPrice, reason)
+}
+
+func (m *Store) UpsertAttestation(requestId string, endpoint string, price string, timestamp int64, sources string, signer string, messageHash string, signature string) (r0 error) {
+	m.record("UpsertAttestation", requestId, endpoint, price, timestamp, sources, signer, messageHash, signature)
+	if m.UpsertAttestationFunc == nil {
+		return
+	}
+	return m.UpsertAttestationFunc(requestId, endpoint, price, timestamp, sources, signer, messageHash, signature)
+}
+
+func (m *Store) GetAttestationByRequestId(requestId string) (r0 models.Attestations, r1 error) {
+	m.record("GetAttestationByRequestId", requestId)
+	if m.GetAttestationByRequestIdFunc == nil {
+		return
+	}
+	return m.GetAttestationByRequestIdFunc(requestId)
+}
+
+func (m *Store) GetAttestationsByRequestIds(requestIds []string) (r0 []models.Attestations, r1 error) {
+	m.record("GetAttestationsByRequestIds", requestIds)
+	if m.GetAttestationsByRequestIdsFunc == nil {
+		return
+	}
+	return m.GetAttestationsByRequestIdsFunc(requestIds)
+}
+
+func (m *Store) UpdateAttestationCid(messageHash string, cid string) (r0 error) {
+	m.record("UpdateAttestationCid", messageHash, cid)
+	if m.UpdateAttestationCidFunc == nil {
+		return
+	}
+	return m.UpdateAttestationCidFunc(messageHash, cid)
+}
+
+func (m *Store) InsertJournalEntry(kind string, requestId string, txHash string, nonce uint64, rawTx string, blockNumber uint64, data string) (r0 error) {
+	m.record("InsertJournalEntry", kind, requestId, txHash, nonce, rawTx, blockNumber, data)
+	if m.InsertJournalEntryFunc == nil {
+		return
+	}
+	return m.InsertJournalEntryFunc(kind, requestId, txHash, nonce, rawTx, blockNumber, data)
+}
+
+func (m *Store) GetUnresolvedTxIntents() (r0 []models.JournalEntries, r1 error) {
+	m.record("GetUnresolvedTxIntents")
+	if m.GetUnresolvedTxIntentsFunc == nil {
+		return
+	}
+	return m.GetUnresolvedTxIntentsFunc()
+}
+
+func (m *Store) MarkTxIntentBroadcast(txHash string) (r0 error) {
+	m.record("MarkTxIntentBroadcast", txHash)
+	if m.MarkTxIntentBroadcastFunc == nil {
+		return
+	}
+	return m.MarkTxIntentBroadcastFunc(txHash)
+}
+
+func (m *Store) ResolveTxIntent(txHash string) (r0 error) {
+	m.record("ResolveTxIntent", txHash)
+	if m.ResolveTxIntentFunc == nil {
+		return
+	}
+	return m.ResolveTxIntentFunc(txHash)
+}
+
+func (m *Store) InsertNewVorRequest(requestId string, keyHash string, sender string, seed string, fee string, blockNumber uint64, blockHash string, txHash string) (r0 error) {
+	m.record("InsertNewVorRequest", requestId, keyHash, sender, seed, fee, blockNumber, blockHash, txHash)
+	if m.InsertNewVorRequestFunc == nil {
+		return
+	}
+	return m.InsertNewVorRequestFunc(requestId, keyHash, sender, seed, fee, blockNumber, blockHash, txHash)
+}
+
+func (m *Store) FindVorRequestByRequestId(requestId string) (r0 models.VorRequests, r1 error) {
+	m.record("FindVorRequestByRequestId", requestId)
+	if m.FindVorRequestByRequestIdFunc == nil {
+		return
+	}
+	return m.FindVorRequestByRequestIdFunc(requestId)
+}
+
+func (m *Store) GetPendingVorRequests() (r0 []models.VorRequests, r1 error) {
+	m.record("GetPendingVorRequests")
+	if m.GetPendingVorRequestsFunc == nil {
+		return
+	}
+	return m.GetPendingVorRequestsFunc()
+}
+
+func (m *Store) UpdateVorRequestStatus(requestId string, status int, reason string) (r0 error) {
+	m.record("UpdateVorRequestStatus", requestId, status, reason)
+	if m.UpdateVorRequestStatusFunc == nil {
+		return
+	}
+	return m.UpdateVorRequestStatusFunc(requestId, status, reason)
+}
+
+func (m *Store) UpdateVorFulfillmentSent(requestId string, txHash string, blockNumber uint64, randomness string) (r0 error) {
+	m.record("UpdateVorFulfillmentSent", requestId, txHash, blockNumber, randomness)
+	if m.UpdateVorFulfillmentSentFunc == nil {
+		return
+	}
+	return m.UpdateVorFulfillmentSentFunc(requestId, txHash, blockNumber, randomness)
+}
+
+func (m *Store) UpdateVorFulfillmentSuccess(requestId string, blockNumber uint64, txHash string, randomness string) (r0 error) {
+	m.record("UpdateVorFulfillmentSuccess", requestId, blockNumber, txHash, randomness)
+	if m.UpdateVorFulfillmentSuccessFunc == nil {
+		return
+	}
+	return m.UpdateVorFulfillmentSuccessFunc(requestId, blockNumber, txHash, randomness)
+}
+
+func (m *Store) GetConsumerMetadata(consumer string) (r0 models.ConsumerMetadata, r1 error) {
+	m.record("GetConsumerMetadata", consumer)
+	if m.GetConsumerMetadataFunc == nil {
+		return
+	}
+	return m.GetConsumerMetadataFunc(consumer)
+}
+
+func (m *Store) GetPairMetadata(pair string) (r0 models.PairMetadata, r1 error) {
+	m.record("GetPairMetadata", pair)
+	if m.GetPairMetadataFunc == nil {
+		return
+	}
+	return m.GetPairMetadataFunc(pair)
+}
+
+func (m *Store) GetMetricSnapshots() (r0 []models.MetricSnapshots, r1 error) {
+	m.record("GetMetricSnapshots")
+	if m.GetMetricSnapshotsFunc == nil {
+		return
+	}
+	return m.GetMetricSnapshotsFunc()
+}
+
+func (m *Store) SaveMetricSnapshots(snapshots []models.MetricSnapshots) (r0 error) {
+	m.record("SaveMetricSnapshots", snapshots)
+	if m.SaveMetricSnapshotsFunc == nil {
+		return
+	}
+	return m.SaveMetricSnapshotsFunc(snapshots)
+}
+
+func (m *Store) SaveRequestSources(requestId string, sources []models.RequestSources) (r0 error) {
+	m.record("SaveRequestSources", requestId, sources)
+	if m.SaveRequestSourcesFunc == nil {
+		return
+	}
+	return m.SaveRequestSourcesFunc(requestId, sources)
+}
+
+func (m *Store) GetLatestReconciliation() (r0 models.Reconciliations, r1 error) {
+	m.record("GetLatestReconciliation")
+	if m.GetLatestReconciliationFunc == nil {
+		return
+	}
+	return m.GetLatestReconciliationFunc()
+}
+
+func (m *Store) GetReconciliations(limit int) (r0 []models.Reconciliations, r1 error) {
+	m.record("GetReconciliations", limit)
+	if m.GetReconciliationsFunc == nil {
+		return
+	}
+	return m.GetReconciliationsFunc(limit)
+}
+
+func (m *Store) InsertReconciliation(r models.Reconciliations) (r0 error) {
+	m.record("InsertReconciliation", r)
+	if m.InsertReconciliationFunc == nil {
+		return
+	}
+	return m.InsertReconciliationFunc(r)
+}
+
+func (m *Store) InsertChainEvent(e models.ChainEvents) (r0 error) {
+	m.record("InsertChainEvent", e)
+	if m.InsertChainEventFunc == nil {
+		return
+	}
+	return m.InsertChainEventFunc(e)
+}
+
+func (m *Store) DeleteChainEvent(txHash string, logIndex uint) (r0 error) {
+	m.record("DeleteChainEvent", txHash, logIndex)
+	if m.DeleteChainEventFunc == nil {
+		return
+	}
+	return m.DeleteChainEventFunc(txHash, logIndex)
+}
+
+func (m *Store) GetChainEvents(name string, limit int) (r0 []models.ChainEvents, r1 error) {
+	m.record("GetChainEvents", name, limit)
+	if m.GetChainEventsFunc == nil {
+		return
+	}
+	return m.GetChainEventsFunc(name, limit)
+}
+
+func (m *Store) InsertGasSpend(g models.GasSpends) (r0 error) {
+	m.record("InsertGasSpend", g)
+	if m.InsertGasSpendFunc == nil {
+		return
+	}
+	return m.InsertGasSpendFunc(g)
+}
+
+func (m *Store) GetLatestStateSnapshot() (r0 models.StateSnapshots, r1 error) {
+	m.record("GetLatestStateSnapshot")
+	if m.GetLatestStateSnapshotFunc == nil {
+		return
+	}
+	return m.GetLatestStateSnapshotFunc()
+}
+
+func (m *Store) InsertStateSnapshot(s models.StateSnapshots) (r0 error) {
+	m.record("InsertStateSnapshot", s)
+	if m.InsertStateSnapshotFunc == nil {
+		return
+	}
+	return m.InsertStateSnapshotFunc(s)
+}
+
+func (m *Store) UpdateStateSnapshotVerified(id uint, verification string) (r0 error) {
+	m.record("UpdateStateSnapshotVerified", id, verification)
+	if m.UpdateStateSnapshotVerifiedFunc == nil {
+		return
+	}
+	return m.UpdateStateSnapshotVerifiedFunc(id, verification)
+}
+
+func (m *Store) CountPendingJobsForProvider(provider string) (r0 int64, r1 error) {
+	m.record("CountPendingJobsForProvider", provider)
+	if m.CountPendingJobsForProviderFunc == nil {
+		return
+	}
+	return m.CountPendingJobsForProviderFunc(provider)
+}
+
+func (m *Store) GetDeadJobs(limit int) (r0 []models.DataRequests, r1 error) {
+	m.record("GetDeadJobs", limit)
+	if m.GetDeadJobsFunc == nil {
+		return
+	}
+	return m.GetDeadJobsFunc(limit)
+}
+
+func (m *Store) GetLastFulfilledForPair(base string, target string) (r0 models.DataRequests, r1 error) {
+	m.record("GetLastFulfilledForPair", base, target)
+	if m.GetLastFulfilledForPairFunc == nil {
+		return
+	}
+	return m.GetLastFulfilledForPairFunc(base, target)
+}
+
+func (m *Store) GetLatestFulfilledPerEndpoint() (r0 []models.DataRequests, r1 error) {
+	m.record("GetLatestFulfilledPerEndpoint")
+	if m.GetLatestFulfilledPerEndpointFunc == nil {
+		return
+	}
+	return m.GetLatestFulfilledPerEndpointFunc()
+}
+
+func (m *Store) SearchJobs(filter database.JobFilter) (r0 []models.DataRequests, r1 error) {
+	m.record("SearchJobs", filter)
+	if m.SearchJobsFunc == nil {
+		return
+	}
+	return m.SearchJobsFunc(filter)
+}
+
+func (m *Store) FindByTxHash(txHash string) (r0 models.DataRequests, r1 error) {
+	m.record("FindByTxHash", txHash)
+	if m.FindByTxHashFunc == nil {
+		return
+	}
+	return m.FindByTxHashFunc(txHash)
+}
+
+func (m *Store) GetFulfilledRequestsBetween(from time.Time, to time.Time) (r0 []models.DataRequests, r1 error) {
+	m.record("GetFulfilledRequestsBetween", from, to)
+	if m.GetFulfilledRequestsBetweenFunc == nil {
+		return
+	}
+	return m.GetFulfilledRequestsBetweenFunc(from, to)
+}
+
+func (m *Store) GetGasSpendsBetween(from time.Time, to time.Time) (r0 []models.GasSpends, r1 error) {
+	m.record("GetGasSpendsBetween", from, to)
+	if m.GetGasSpendsBetweenFunc == nil {
+		return
+	}
+	return m.GetGasSpendsBetweenFunc(from, to)
+}
+
+func (m *Store) GetGasSpendsForRequest(requestId string) (r0 []models.GasSpends, r1 error) {
+	m.record("GetGasSpendsForRequest", requestId)
+	if m.GetGasSpendsForRequestFunc == nil {
+		return
+	}
+	return m.GetGasSpendsForRequestFunc(requestId)
+}
+
+func (m *Store) GetRecentAdhocEndpoints(since time.Time) (r0 []string, r1 error) {
+	m.record("GetRecentAdhocEndpoints", since)
+	if m.GetRecentAdhocEndpointsFunc == nil {
+		return
+	}
+	return m.GetRecentAdhocEndpointsFunc(since)
+}
+
+func (m *Store) GetLastXSuccessfulRequests(limit int, consumer string) (r0 []models.DataRequests, r1 error) {
+	m.record("GetLastXSuccessfulRequests", limit, consumer)
+	if m.GetLastXSuccessfulRequestsFunc == nil {
+		return
+	}
+	return m.GetLastXSuccessfulRequestsFunc(limit, consumer)
+}
+
+func (m *Store) GetMostGasUsed() (r0 models.DataRequests, r1 error) {
+	m.record("GetMostGasUsed")
+	if m.GetMostGasUsedFunc == nil {
+		return
+	}
+	return m.GetMostGasUsedFunc()
+}
+
+func (m *Store) GetLeastGasUsed() (r0 models.DataRequests, r1 error) {
+	m.record("GetLeastGasUsed")
+	if m.GetLeastGasUsedFunc == nil {
+		return
+	}
+	return m.GetLeastGasUsedFunc()
+}
+
+func (m *Store) RequeueFailedRequest(requestId string) (r0 error) {
+	m.record("RequeueFailedRequest", requestId)
+	if m.RequeueFailedRequestFunc == nil {
+		return
+	}
+	return m.RequeueFailedRequestFunc(requestId)
+}
+
+func (m *Store) GetJournalEntriesForRequest(requestId string) (r0 []models.JournalEntries, r1 error) {
+	m.record("GetJournalEntriesForRequest", requestId)
+	if m.GetJournalEntriesForRequestFunc == nil {
+		return
+	}
+	return m.GetJournalEntriesForRequestFunc(requestId)
+}
+
+func (m *Store) GetRequestSources(requestId string) (r0 []models.RequestSources, r1 error) {
+	m.record("GetRequestSources", requestId)
+	if m.GetRequestSourcesFunc == nil {
+		return
+	}
+	return m.GetRequestSourcesFunc(requestId)
+}
+
+func (m *Store) CountSupportedPairs() (r0 int64, r1 error) {
+	m.record("CountSupportedPairs")
+	if m.CountSupportedPairsFunc == nil {
+		return
+	}
+	return m.CountSupportedPairsFunc()
+}
+
+func (m *Store) GetSupportedPairs() (r0 []models.SupportedPairs, r1 error) {
+	m.record("GetSupportedPairs")
+	if m.GetSupportedPairsFunc == nil {
+		return
+	}
+	return m.GetSupportedPairsFunc()
+}
+
+func (m *Store) PairIsSupportedByPairName(pair string) (r0 models.SupportedPairs, r1 error) {
+	m.record("PairIsSupportedByPairName", pair)
+	if m.PairIsSupportedByPairNameFunc == nil {
+		return
+	}
+	return m.PairIsSupportedByPairNameFunc(pair)
+}
+
+func (m *Store) PairIsSupportedByBaseAndTarget(base string, target string) (r0 models.SupportedPairs, r1 error) {
+	m.record("PairIsSupportedByBaseAndTarget", base, target)
+	if m.PairIsSupportedByBaseAndTargetFunc == nil {
+		return
+	}
+	return m.PairIsSupportedByBaseAndTargetFunc(base, target)
+}
+
+func (m *Store) PairsNoLongerSupported(pairs []string) (r0 []models.SupportedPairs, r1 error) {
+	m.record("PairsNoLongerSupported", pairs)
+	if m.PairsNoLongerSupportedFunc == nil {
+		return
+	}
+	return m.PairsNoLongerSupportedFunc(pairs)
+}
+
+func (m *Store) GetRequestsForPairSince(base string, target string, since time.Time) (r0 []models.DataRequests, r1 error) {
+	m.record("GetRequestsForPairSince", base, target, since)
+	if m.GetRequestsForPairSinceFunc == nil {
+		return
+	}
+	return m.GetRequestsForPairSinceFunc(base, target, since)
+}
+
+func (m *Store) GetPairDelistings(since time.Time) (r0 []models.PairDelistings, r1 error) {
+	m.record("GetPairDelistings", since)
+	if m.GetPairDelistingsFunc == nil {
+		return
+	}
+	return m.GetPairDelistingsFunc(since)
+}
+
+func (m *Store) GetUnnotifiedPairDelistings() (r0 []models.PairDelistings, r1 error) {
+	m.record("GetUnnotifiedPairDelistings")
+	if m.GetUnnotifiedPairDelistingsFunc == nil {
+		return
+	}
+	return m.GetUnnotifiedPairDelistingsFunc()
+}
+
+func (m *Store) InsertPairDelistings(delistings []models.PairDelistings) (r0 error) {
+	m.record("InsertPairDelistings", delistings)
+	if m.InsertPairDelistingsFunc == nil {
+		return
+	}
+	return m.InsertPairDelistingsFunc(delistings)
+}
+
+func (m *Store) MarkPairDelistingsNotified(ids []uint) (r0 error) {
+	m.record("MarkPairDelistingsNotified", ids)
+	if m.MarkPairDelistingsNotifiedFunc == nil {
+		return
+	}
+	return m.MarkPairDelistingsNotifiedFunc(ids)
+}
+
+func (m *Store) GetPairChanges(filter database.PairChangeFilter) (r0 []models.PairChanges, r1 error) {
+	m.record("GetPairChanges", filter)
+	if m.GetPairChangesFunc == nil {
+		return
+	}
+	return m.GetPairChangesFunc(filter)
+}
+
+func (m *Store) InsertPairChanges(changes []models.PairChanges) (r0 error) {
+	m.record("InsertPairChanges", changes)
+	if m.InsertPairChangesFunc == nil {
+		return
+	}
+	return m.InsertPairChangesFunc(changes)
+}
+
+func (m *Store) AddNewSupportedPair(name string, base string, target string) (r0 error) {
+	m.record("AddNewSupportedPair", name, base, target)
+	if m.AddNewSupportedPairFunc == nil {
+		return
+	}
+	return m.AddNewSupportedPairFunc(name, base, target)
+}
+
+func (m *Store) DeleteSupportedPair(pair models.SupportedPairs) (r0 error) {
+	m.record("DeleteSupportedPair", pair)
+	if m.DeleteSupportedPairFunc == nil {
+		return
+	}
+	return m.DeleteSupportedPairFunc(pair)
+}
+
+func (m *Store) FindByDexPairName(base string, target string, dexName string) (r0 models.DexPairs, r1 error) {
+	m.record("FindByDexPairName", base, target, dexName)
+	if m.FindByDexPairNameFunc == nil {
+		return
+	}
+	return m.FindByDexPairNameFunc(base, target, dexName)
+}
+
+func (m *Store) FindDexPairByContract(contractAddress string, dexName string) (r0 models.DexPairs, r1 error) {
+	m.record("FindDexPairByContract", contractAddress, dexName)
+	if m.FindDexPairByContractFunc == nil {
+		return
+	}
+	return m.FindDexPairByContractFunc(contractAddress, dexName)
+}
+
+func (m *Store) GetDexPairsByDexName(dexName string) (r0 []models.DexPairs, r1 error) {
+	m.record("GetDexPairsByDexName", dexName)
+	if m.GetDexPairsByDexNameFunc == nil {
+		return
+	}
+	return m.GetDexPairsByDexNameFunc(dexName)
+}
+
+func (m *Store) SyncDexPairs(dexName string, chain string, pairs []database.DexPairSync) (r0 error) {
+	m.record("SyncDexPairs", dexName, chain, pairs)
+	if m.SyncDexPairsFunc == nil {
+		return
+	}
+	return m.SyncDexPairsFunc(dexName, chain, pairs)
+}
+
+func (m *Store) GetDexSyncCursor(dexName string) (r0 models.DexSyncCursors, r1 error) {
+	m.record("GetDexSyncCursor", dexName)
+	if m.GetDexSyncCursorFunc == nil {
+		return
+	}
+	return m.GetDexSyncCursorFunc(dexName)
+}
+
+func (m *Store) UpdateDexSyncCursor(dexName string, block uint64, full bool) (r0 error) {
+	m.record("UpdateDexSyncCursor", dexName, block, full)
+	if m.UpdateDexSyncCursorFunc == nil {
+		return
+	}
+	return m.UpdateDexSyncCursorFunc(dexName, block, full)
+}
+
+func (m *Store) GetSubgraphSpend(month string) (r0 []models.SubgraphSpend, r1 error) {
+	m.record("GetSubgraphSpend", month)
+	if m.GetSubgraphSpendFunc == nil {
+		return
+	}
+	return m.GetSubgraphSpendFunc(month)
+}
+
+func (m *Store) AddSubgraphSpend(dexName string, month string, queries uint64, cost float64) (r0 error) {
+	m.record("AddSubgraphSpend", dexName, month, queries, cost)
+	if m.AddSubgraphSpendFunc == nil {
+		return
+	}
+	return m.AddSubgraphSpendFunc(dexName, month, queries, cost)
+}
+
+func (m *Store) UpdateDexPairReserveUsd(contractAddress string, dexName string, reserveUsd float64) (r0 error) {
+	m.record("UpdateDexPairReserveUsd", contractAddress, dexName, reserveUsd)
+	if m.UpdateDexPairReserveUsdFunc == nil {
+		return
+	}
+	return m.UpdateDexPairReserveUsdFunc(contractAddress, dexName, reserveUsd)
+}
+
+func (m *Store) UpdateDexPairVolumeUsd(contractAddress string, dexName string, volumeUsd24h float64) (r0 error) {
+	m.record("UpdateDexPairVolumeUsd", contractAddress, dexName, volumeUsd24h)
+	if m.UpdateDexPairVolumeUsdFunc == nil {
+		return
+	}
+	return m.UpdateDexPairVolumeUsdFunc(contractAddress, dexName, volumeUsd24h)
+}
+
+func (m *Store) InsertAuditLog(actor string, source string, remoteAddr string, action string, requestId string, params string, success bool, errMsg string) (r0 error) {
+	m.record("InsertAuditLog", actor, source, remoteAddr, action, requestId, params, success, errMsg)
+	if m.InsertAuditLogFunc == nil {
+		return
+	}
+	return m.InsertAuditLogFunc(actor, source, remoteAddr, action, requestId, params, success, errMsg)
+}
+
+func (m *Store) SearchAuditLog(filter database.AuditFilter) (r0 []models.AuditLog, r1 error) {
+	m.record("SearchAuditLog", filter)
+	if m.SearchAuditLogFunc == nil {
+		return
+	}
+	return m.SearchAuditLogFunc(filter)
+}
+
+func (m *Store) GetActiveMaintenanceWindow() (r0 models.MaintenanceWindows, r1 error) {
+	m.record("GetActiveMaintenanceWindow")
+	if m.GetActiveMaintenanceWindowFunc == nil {
+		return
+	}
+	return m.GetActiveMaintenanceWindowFunc()
+}
+
+func (m *Store) StartMaintenanceWindow(note string, startedBy string, until int64) (r0 models.MaintenanceWindows, r1 error) {
+	m.record("StartMaintenanceWindow", note, startedBy, until)
+	if m.StartMaintenanceWindowFunc == nil {
+		return
+	}
+	return m.StartMaintenanceWindowFunc(note, startedBy, until)
+}
+
+func (m *Store) EndMaintenanceWindow(endedBy string) (r0 error) {
+	m.record("EndMaintenanceWindow", endedBy)
+	if m.EndMaintenanceWindowFunc == nil {
+		return
+	}
+	return m.EndMaintenanceWindowFunc(endedBy)
+}
+
+func (m *Store) GetAllConsumerMetadata() (r0 []models.ConsumerMetadata, r1 error) {
+	m.record("GetAllConsumerMetadata")
+	if m.GetAllConsumerMetadataFunc == nil {
+		return
+	}
+	return m.GetAllConsumerMetadataFunc()
+}
+
+func (m *Store) GetAllPairMetadata() (r0 []models.PairMetadata, r1 error) {
+	m.record("GetAllPairMetadata")
+	if m.GetAllPairMetadataFunc == nil {
+		return
+	}
+	return m.GetAllPairMetadataFunc()
+}
+
+func (m *Store) UpsertConsumerMetadata(consumer string, tags []string, note string) (r0 error) {
+	m.record("UpsertConsumerMetadata", consumer, tags, note)
+	if m.UpsertConsumerMetadataFunc == nil {
+		return
+	}
+	return m.UpsertConsumerMetadataFunc(consumer, tags, note)
+}
+
+func (m *Store) DeleteConsumerMetadata(consumer string) (r0 error) {
+	m.record("DeleteConsumerMetadata", consumer)
+	if m.DeleteConsumerMetadataFunc == nil {
+		return
+	}
+	return m.DeleteConsumerMetadataFunc(consumer)
+}
+
+func (m *Store) UpsertPairMetadata(pair string, tags []string, note string) (r0 error) {
+	m.record("UpsertPairMetadata", pair, tags, note)
+	if m.UpsertPairMetadataFunc == nil {
+		return
+	}
+	return m.UpsertPairMetadataFunc(pair, tags, note)
+}
+
+func (m *Store) DeletePairMetadata(pair string) (r0 error) {
+	m.record("DeletePairMetadata", pair)
+	if m.DeletePairMetadataFunc == nil {
+		return
+	}
+	return m.DeletePairMetadataFunc(pair)
+}
+
+func (m *Store) GetAllAdapterCredentials() (r0 []models.AdapterCredentials, r1 error) {
+	m.record("GetAllAdapterCredentials")
+	if m.GetAllAdapterCredentialsFunc == nil {
+		return
+	}
+	return m.GetAllAdapterCredentialsFunc()
+}
+
+func (m *Store) UpsertAdapterCredential(name string, value string, ref string, rotateAfterDays uint64, dailyQuota uint64) (r0 models.AdapterCredentials, r1 error) {
+	m.record("UpsertAdapterCredential", name, value, ref, rotateAfterDays, dailyQuota)
+	if m.UpsertAdapterCredentialFunc == nil {
+		return
+	}
+	return m.UpsertAdapterCredentialFunc(name, value, ref, rotateAfterDays, dailyQuota)
+}
+
+func (m *Store) AddAdapterCredentialUsage(name string, usageDay string, uses uint64, lastUsedAt int64) (r0 error) {
+	m.record("AddAdapterCredentialUsage", name, usageDay, uses, lastUsedAt)
+	if m.AddAdapterCredentialUsageFunc == nil {
+		return
+	}
+	return m.AddAdapterCredentialUsageFunc(name, usageDay, uses, lastUsedAt)
+}
+
+func (m *Store) DeleteAdapterCredential(name string) (r0 error) {
+	m.record("DeleteAdapterCredential", name)
+	if m.DeleteAdapterCredentialFunc == nil {
+		return
+	}
+	return m.DeleteAdapterCredentialFunc(name)
+}
+
+func (m *Store) GetDbSchemaVersion() (r0 uint64, r1 error) {
+	m.record("GetDbSchemaVersion")
+	if m.GetDbSchemaVersionFunc == nil {
+		return
+	}
+	return m.GetDbSchemaVersionFunc()
+}
+
+func (m *Store) Vacuum(ctx context.Context, tables []string) (r0 error) {
+	m.record("Vacuum", ctx, tables)
+	if m.VacuumFunc == nil {
+		return
+	}
+	return m.VacuumFunc(ctx, tables)
+}
+
+func (m *Store) NewLeaderLock(key int64) (r0 *database.LeaderLock, r1 error) {
+	m.record("NewLeaderLock", key)
+	if m.NewLeaderLockFunc == nil {
+		return
+	}
+	return m.NewLeaderLockFunc(key)
+}
+
+func (m *Store) Ping(ctx context.Context) (r0 error) {
+	m.record("Ping", ctx)
+	if m.PingFunc == nil {
+		return
+	}
+	return m.PingFunc(ctx)
+}
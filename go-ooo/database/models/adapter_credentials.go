@@ -0,0 +1,66 @@
+package models
+
+import "gorm.io/gorm"
+
+// AdapterCredentials holds an API key used by a data source adapter, e.g. a JSON feed or the FX
+// rates API. The key is either Value, encrypted if database encryption is enabled, or read from
+// Ref, an external secret reference. Version is incremented each time the key is rotated.
+// Usage is counted per UTC day, UsageDay, against DailyQuota
+type AdapterCredentials struct {
+	gorm.Model
+	Name            string `gorm:"uniqueIndex"`
+	Value           string
+	Ref             string
+	Version         uint64
+	RotatedAt       int64
+	RotateAfterDays uint64
+	DailyQuota      uint64
+	UsageDay        string
+	UsedToday       uint64
+	TotalUses       uint64
+	LastUsedAt      int64
+}
+
+func (AdapterCredentials) TableName() string {
+	return "adapter_credentials"
+}
+
+func (c AdapterCredentials) GetName() string {
+	return c.Name
+}
+
+func (c AdapterCredentials) GetRef() string {
+	return c.Ref
+}
+
+func (c AdapterCredentials) GetVersion() uint64 {
+	return c.Version
+}
+
+func (c AdapterCredentials) GetRotatedAt() int64 {
+	return c.RotatedAt
+}
+
+func (c AdapterCredentials) GetRotateAfterDays() uint64 {
+	return c.RotateAfterDays
+}
+
+func (c AdapterCredentials) GetDailyQuota() uint64 {
+	return c.DailyQuota
+}
+
+func (c AdapterCredentials) GetUsageDay() string {
+	return c.UsageDay
+}
+
+func (c AdapterCredentials) GetUsedToday() uint64 {
+	return c.UsedToday
+}
+
+func (c AdapterCredentials) GetTotalUses() uint64 {
+	return c.TotalUses
+}
+
+func (c AdapterCredentials) GetLastUsedAt() int64 {
+	return c.LastUsedAt
+}
@@ -0,0 +1,63 @@
+package models
+
+import "gorm.io/gorm"
+
+// Attestations are signed, off-chain records of the answer the provider
+// generated for a request, including the sources used to derive the price
+type Attestations struct {
+	gorm.Model
+	RequestId   string `gorm:"uniqueIndex"`
+	Endpoint    string `gorm:"index"`
+	Price       string
+	Timestamp   int64
+	Sources     string
+	Signer      string `gorm:"index"`
+	MessageHash string `gorm:"index"`
+	Signature   string
+	// IpfsCid - CID of the attestation published to IPFS, if publishing is enabled
+	IpfsCid string
+}
+
+func (Attestations) TableName() string {
+	return "attestations"
+}
+
+func (a Attestations) GetId() uint {
+	return a.ID
+}
+
+func (a Attestations) GetRequestId() string {
+	return a.RequestId
+}
+
+func (a Attestations) GetEndpoint() string {
+	return a.Endpoint
+}
+
+func (a Attestations) GetPrice() string {
+	return a.Price
+}
+
+func (a Attestations) GetTimestamp() int64 {
+	return a.Timestamp
+}
+
+func (a Attestations) GetSources() string {
+	return a.Sources
+}
+
+func (a Attestations) GetSigner() string {
+	return a.Signer
+}
+
+func (a Attestations) GetMessageHash() string {
+	return a.MessageHash
+}
+
+func (a Attestations) GetSignature() string {
+	return a.Signature
+}
+
+func (a Attestations) GetIpfsCid() string {
+	return a.IpfsCid
+}
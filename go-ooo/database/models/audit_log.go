@@ -0,0 +1,63 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	AUDIT_SOURCE_CLI       = "cli"       // via the service API, used by the CLI
+	AUDIT_SOURCE_ADMIN_API = "admin_api" // via the admin API
+	AUDIT_SOURCE_NODE      = "node"      // by the node itself, e.g. at the end of a maintenance window
+)
+
+// AuditLog records a mutating admin action - who requested it, with what parameters, and
+// whether it succeeded. The time of the action is CreatedAt
+type AuditLog struct {
+	gorm.Model
+	Actor      string `gorm:"index"`
+	Source     string
+	RemoteAddr string
+	Action     string `gorm:"index"`
+	RequestId  string `gorm:"index"`
+	Params     string
+	Success    bool
+	Error      string
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
+
+func (a AuditLog) GetId() uint {
+	return a.ID
+}
+
+func (a AuditLog) GetActor() string {
+	return a.Actor
+}
+
+func (a AuditLog) GetSource() string {
+	return a.Source
+}
+
+func (a AuditLog) GetRemoteAddr() string {
+	return a.RemoteAddr
+}
+
+func (a AuditLog) GetAction() string {
+	return a.Action
+}
+
+func (a AuditLog) GetRequestId() string {
+	return a.RequestId
+}
+
+func (a AuditLog) GetParams() string {
+	return a.Params
+}
+
+func (a AuditLog) GetSuccess() bool {
+	return a.Success
+}
+
+func (a AuditLog) GetError() string {
+	return a.Error
+}
@@ -0,0 +1,22 @@
+package models
+
+import "gorm.io/gorm"
+
+// ChainEvents is a log matching one of the chain.event_filters, recorded so that custom consumer
+// contract activity can be monitored from the node. Name is the filter's, Args the event's
+// decoded arguments as a JSON object, and Data the log's raw, hex encoded data
+type ChainEvents struct {
+	gorm.Model
+	Name        string `gorm:"index"`
+	Address     string `gorm:"index"`
+	Topic       string
+	BlockNumber uint64 `gorm:"index"`
+	TxHash      string `gorm:"uniqueIndex:idx_chain_events_log"`
+	LogIndex    uint   `gorm:"uniqueIndex:idx_chain_events_log"`
+	Args        string
+	Data        string
+}
+
+func (ChainEvents) TableName() string {
+	return "chain_events"
+}
@@ -12,6 +12,15 @@ const (
 	REQUEST_STATUS_TX_FAILED                 // Fulfilment Tx failed and not broadcast
 	REQUEST_STATUS_SUCCESS                   // Fulfilment Tx successful and confirmed in RandomnessRequestFulfilled event
 	REQUEST_STATUS_FULFILMENT_FAILED         // Fulfilment failed - too many failed attempts.
+	REQUEST_STATUS_DEAD                      // Retries exhausted - dead lettered until manually requeued
+	REQUEST_STATUS_TIMEOUT                   // Processing exceeded the job timeout - will be retried
+	REQUEST_STATUS_SKIPPED_LOW_FEE           // Request fee below the configured minimum - not fulfilled
+	REQUEST_STATUS_SKIPPED_RATE_LIMIT        // Consumer exceeded its fulfillment rate limit - not fulfilled
+	REQUEST_STATUS_DUPLICATE                 // Request already fulfilled on chain - fulfilment not sent
+	REQUEST_STATUS_REJECTED                  // Request endpoint failed validation at ingestion - not fulfilled
+	REQUEST_STATUS_SKIPPED_MANUAL            // Request skipped by the operator - not fulfilled
+	REQUEST_STATUS_SKIPPED_GAS_LIMIT         // Fulfilment, including the consumer's callback, needs more than the gas limit ceiling - not fulfilled
+	REQUEST_STATUS_SKIPPED_CATCHUP           // Request missed during downtime, older than the catch-up mode allows - not fulfilled
 )
 
 const (
@@ -21,6 +30,24 @@ const (
 	JOB_STATUS_FAIL           // global status for completely failed jobs
 )
 
+// IsFailedFinalRequestStatus returns true if the request status means the request
+// will not be fulfilled, and the job is no longer pending
+func IsFailedFinalRequestStatus(status int) bool {
+	switch status {
+	case REQUEST_STATUS_FULFILMENT_FAILED,
+		REQUEST_STATUS_DEAD,
+		REQUEST_STATUS_SKIPPED_LOW_FEE,
+		REQUEST_STATUS_SKIPPED_RATE_LIMIT,
+		REQUEST_STATUS_DUPLICATE,
+		REQUEST_STATUS_REJECTED,
+		REQUEST_STATUS_SKIPPED_MANUAL,
+		REQUEST_STATUS_SKIPPED_GAS_LIMIT,
+		REQUEST_STATUS_SKIPPED_CATCHUP:
+		return true
+	}
+	return false
+}
+
 type DataRequests struct {
 	gorm.Model
 	Consumer                    string `gorm:"index"`
@@ -36,15 +63,25 @@ type DataRequests struct {
 	Endpoint                    string
 	EndpointDecoded             string
 	PriceResult                 string
+	AnswerRounding              string // rounding mode the price result was scaled with, e.g. floor
+	SourcePolicy                string // consumer source policy the price result was aggregated with, e.g. dex
+	SourceTier                  string // source tier the price result was answered from, if jobs.source_tiers is set, e.g. secondary
 	LastFulfillSentBlockNumber  uint64 `gorm:"index"`
 	FulfillConfirmedBlockNumber uint64 `gorm:"index"`
 	FulfillTxHash               string `gorm:"index"`
 	FulfillGasUsed              uint64
 	FulfillGasPrice             uint64
 	FulfillmentAttempts         uint64 `gorm:"default:0"`
+	NextRetryAt                 int64  `gorm:"index"`
 	JobStatus                   int    `gorm:"index"`
 	RequestStatus               int    `gorm:"index"`
 	StatusReason                string
+	RejectionCode               string `gorm:"index"`
+	ErrorCode                   string `gorm:"index"` // errcodes class of the failure the request's status records, e.g. PRICE_OUT_OF_BOUNDS
+	// job timing, in unix milliseconds. The event is seen at CreatedAt
+	DataFetchedAt int64
+	TxSentAt      int64
+	TxMinedAt     int64 `gorm:"index"`
 }
 
 func (DataRequests) TableName() string {
@@ -131,6 +168,10 @@ func (d *DataRequests) GetFulfillmentAttempts() uint64 {
 	return d.FulfillmentAttempts
 }
 
+func (d *DataRequests) GetNextRetryAt() int64 {
+	return d.NextRetryAt
+}
+
 func (d *DataRequests) GetRequestStatus() int {
 	return d.RequestStatus
 }
@@ -160,6 +201,24 @@ func (d *DataRequests) GetRequestStatusString() string {
 		return "SUCCESS"
 	case REQUEST_STATUS_FULFILMENT_FAILED:
 		return "FULFILMENT FAILED"
+	case REQUEST_STATUS_DEAD:
+		return "DEAD"
+	case REQUEST_STATUS_TIMEOUT:
+		return "TIMEOUT"
+	case REQUEST_STATUS_SKIPPED_LOW_FEE:
+		return "SKIPPED LOW FEE"
+	case REQUEST_STATUS_SKIPPED_RATE_LIMIT:
+		return "SKIPPED RATE LIMIT"
+	case REQUEST_STATUS_DUPLICATE:
+		return "DUPLICATE"
+	case REQUEST_STATUS_REJECTED:
+		return "REJECTED"
+	case REQUEST_STATUS_SKIPPED_MANUAL:
+		return "SKIPPED MANUAL"
+	case REQUEST_STATUS_SKIPPED_GAS_LIMIT:
+		return "SKIPPED GAS LIMIT"
+	case REQUEST_STATUS_SKIPPED_CATCHUP:
+		return "SKIPPED CATCHUP"
 	}
 
 	return "UNKNOWN"
@@ -180,3 +239,35 @@ func (d *DataRequests) GetJobStatusString() string {
 func (d *DataRequests) GetStatusReason() string {
 	return d.StatusReason
 }
+
+func (d *DataRequests) GetRejectionCode() string {
+	return d.RejectionCode
+}
+
+func (d *DataRequests) GetErrorCode() string {
+	return d.ErrorCode
+}
+
+func (d *DataRequests) GetAnswerRounding() string {
+	return d.AnswerRounding
+}
+
+func (d *DataRequests) GetSourcePolicy() string {
+	return d.SourcePolicy
+}
+
+func (d *DataRequests) GetSourceTier() string {
+	return d.SourceTier
+}
+
+func (d *DataRequests) GetDataFetchedAt() int64 {
+	return d.DataFetchedAt
+}
+
+func (d *DataRequests) GetTxSentAt() int64 {
+	return d.TxSentAt
+}
+
+func (d *DataRequests) GetTxMinedAt() int64 {
+	return d.TxMinedAt
+}
@@ -14,6 +14,10 @@ type DexPairs struct {
 	T1Symbol        string `gorm:"index"`
 	ContractAddress string `gorm:"index"`
 	ReserveUsd      float64
+	// VolumeUsd24h is the pair's trade volume over the 24 hours to VolumeUpdatedAt, a unix
+	// timestamp which is 0 if the volume has never been synced
+	VolumeUsd24h    float64 `gorm:"column:volume_usd_24h"`
+	VolumeUpdatedAt int64
 }
 
 func (DexPairs) TableName() string {
@@ -39,3 +43,11 @@ func (d *DexPairs) GetT1DexTokenId() uint {
 func (d *DexPairs) GetContractAddress() string {
 	return d.ContractAddress
 }
+
+func (d *DexPairs) GetVolumeUsd24h() float64 {
+	return d.VolumeUsd24h
+}
+
+func (d *DexPairs) GetVolumeUpdatedAt() int64 {
+	return d.VolumeUpdatedAt
+}
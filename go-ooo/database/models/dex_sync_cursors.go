@@ -0,0 +1,29 @@
+package models
+
+import "gorm.io/gorm"
+
+// DexSyncCursors holds the subgraph block each DEX's pairs were last synced at, so that the next
+// sync only fetches the pairs changed since. FullSyncAt is the unix timestamp of the last sync
+// of every pair, which is 0 if there has been none
+type DexSyncCursors struct {
+	gorm.Model
+	DexName    string `gorm:"uniqueIndex"`
+	Block      uint64
+	FullSyncAt int64
+}
+
+func (DexSyncCursors) TableName() string {
+	return "dex_sync_cursors"
+}
+
+func (c DexSyncCursors) GetDexName() string {
+	return c.DexName
+}
+
+func (c DexSyncCursors) GetBlock() uint64 {
+	return c.Block
+}
+
+func (c DexSyncCursors) GetFullSyncAt() int64 {
+	return c.FullSyncAt
+}
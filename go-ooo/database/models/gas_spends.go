@@ -0,0 +1,65 @@
+package models
+
+import "gorm.io/gorm"
+
+// GasSpends is the fee market at a fulfillment tx's block, recorded when the tx is mined, so that
+// failed or slow fulfillments can be correlated with fee conditions. Fees are in wei. BaseFee is 0
+// before EIP-1559, and PriorityFee the tip actually paid above it. Blocks is the number of blocks
+// from the request to the fulfillment being mined
+type GasSpends struct {
+	gorm.Model
+	RequestId   string `gorm:"index"`
+	TxHash      string `gorm:"uniqueIndex"`
+	BlockNumber uint64
+	BlockTime   int64 `gorm:"index"`
+	BaseFee     uint64
+	PriorityFee uint64
+	GasPrice    uint64
+	GasUsed     uint64
+	Blocks      uint64
+	Success     bool
+}
+
+func (GasSpends) TableName() string {
+	return "gas_spends"
+}
+
+func (g GasSpends) GetRequestId() string {
+	return g.RequestId
+}
+
+func (g GasSpends) GetTxHash() string {
+	return g.TxHash
+}
+
+func (g GasSpends) GetBlockNumber() uint64 {
+	return g.BlockNumber
+}
+
+func (g GasSpends) GetBlockTime() int64 {
+	return g.BlockTime
+}
+
+func (g GasSpends) GetBaseFee() uint64 {
+	return g.BaseFee
+}
+
+func (g GasSpends) GetPriorityFee() uint64 {
+	return g.PriorityFee
+}
+
+func (g GasSpends) GetGasPrice() uint64 {
+	return g.GasPrice
+}
+
+func (g GasSpends) GetGasUsed() uint64 {
+	return g.GasUsed
+}
+
+func (g GasSpends) GetBlocks() uint64 {
+	return g.Blocks
+}
+
+func (g GasSpends) GetSuccess() bool {
+	return g.Success
+}
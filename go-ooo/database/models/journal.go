@@ -0,0 +1,74 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	JOURNAL_KIND_REQUEST_RECEIVED  = "request_received"  // DataRequested event received
+	JOURNAL_KIND_REQUEST_FULFILLED = "request_fulfilled" // RequestFulfilled event received
+	JOURNAL_KIND_TX_INTENT         = "tx_intent"         // fulfillment tx signed, about to be broadcast. Broadcast is set once sent
+	JOURNAL_KIND_TX_BROADCAST      = "tx_broadcast"      // fulfillment tx broadcast
+	JOURNAL_KIND_TX_FAILED         = "tx_failed"         // fulfillment tx could not be broadcast
+	JOURNAL_KIND_JOB_EVENT         = "job_event"         // job lifecycle event, e.g. skipped or failed
+)
+
+// JournalEntries is an append only, write-ahead record of received events and intended
+// transactions, used to reconstruct the state of requests after a crash
+type JournalEntries struct {
+	gorm.Model
+	Kind        string `gorm:"index"`
+	RequestId   string `gorm:"index"`
+	TxHash      string `gorm:"index"`
+	Nonce       uint64
+	RawTx       string
+	BlockNumber uint64
+	Data        string
+	Resolved    bool `gorm:"index"`
+	// Broadcast is set on a tx intent once the tx has been sent, and before the request is
+	// recorded as sent, so that an unresolved intent is known to be either computed but never
+	// sent, or sent with its outcome unknown
+	Broadcast bool
+}
+
+func (JournalEntries) TableName() string {
+	return "journal_entries"
+}
+
+func (j JournalEntries) GetId() uint {
+	return j.ID
+}
+
+func (j JournalEntries) GetKind() string {
+	return j.Kind
+}
+
+func (j JournalEntries) GetRequestId() string {
+	return j.RequestId
+}
+
+func (j JournalEntries) GetTxHash() string {
+	return j.TxHash
+}
+
+func (j JournalEntries) GetNonce() uint64 {
+	return j.Nonce
+}
+
+func (j JournalEntries) GetRawTx() string {
+	return j.RawTx
+}
+
+func (j JournalEntries) GetBlockNumber() uint64 {
+	return j.BlockNumber
+}
+
+func (j JournalEntries) GetData() string {
+	return j.Data
+}
+
+func (j JournalEntries) GetResolved() bool {
+	return j.Resolved
+}
+
+func (j JournalEntries) GetBroadcast() bool {
+	return j.Broadcast
+}
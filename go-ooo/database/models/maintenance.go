@@ -0,0 +1,44 @@
+package models
+
+import "gorm.io/gorm"
+
+// MaintenanceWindows records each period the node was put into maintenance mode, e.g. for planned
+// work, during which alerts are suppressed. The window starts at CreatedAt, and is active until
+// EndedAt is set, or Until if it is not 0. Times are unix timestamps
+type MaintenanceWindows struct {
+	gorm.Model
+	Note      string
+	StartedBy string
+	Until     int64
+	EndedAt   int64 `gorm:"index"`
+	EndedBy   string
+}
+
+func (MaintenanceWindows) TableName() string {
+	return "maintenance_windows"
+}
+
+func (m MaintenanceWindows) GetId() uint {
+	return m.ID
+}
+
+func (m MaintenanceWindows) GetNote() string {
+	return m.Note
+}
+
+func (m MaintenanceWindows) GetStartedBy() string {
+	return m.StartedBy
+}
+
+func (m MaintenanceWindows) GetUntil() int64 {
+	return m.Until
+}
+
+func (m MaintenanceWindows) GetEndedAt() int64 {
+	return m.EndedAt
+}
+
+// IsExpired returns true if the window was started with an end time, which is before now
+func (m MaintenanceWindows) IsExpired(now int64) bool {
+	return m.Until > 0 && now >= m.Until
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"strings"
+)
+
+// ConsumerMetadata holds the operator's tags and note for a consumer contract, e.g. the
+// customer or product line it belongs to. Tags are stored lower case, comma separated
+type ConsumerMetadata struct {
+	gorm.Model
+	Consumer string `gorm:"uniqueIndex"`
+	Tags     string
+	Note     string
+}
+
+func (ConsumerMetadata) TableName() string {
+	return "consumer_metadata"
+}
+
+func (m ConsumerMetadata) GetConsumer() string {
+	return m.Consumer
+}
+
+func (m ConsumerMetadata) GetTags() []string {
+	return splitTags(m.Tags)
+}
+
+func (m ConsumerMetadata) GetNote() string {
+	return m.Note
+}
+
+// PairMetadata holds the operator's tags and note for a pair, e.g. BTC.USD
+type PairMetadata struct {
+	gorm.Model
+	Pair string `gorm:"uniqueIndex"`
+	Tags string
+	Note string
+}
+
+func (PairMetadata) TableName() string {
+	return "pair_metadata"
+}
+
+func (m PairMetadata) GetPair() string {
+	return m.Pair
+}
+
+func (m PairMetadata) GetTags() []string {
+	return splitTags(m.Tags)
+}
+
+func (m PairMetadata) GetNote() string {
+	return m.Note
+}
+
+func splitTags(tags string) []string {
+	if tags == "" {
+		return []string{}
+	}
+	return strings.Split(tags, ",")
+}
@@ -0,0 +1,29 @@
+package models
+
+import "gorm.io/gorm"
+
+// MetricSnapshots holds the last saved value of one series of a counter metric, so that lifetime
+// totals survive restarts. Labels are the series' label pairs, sorted by name, e.g.
+// "consumer=0x...,pair=BTC.USD"
+type MetricSnapshots struct {
+	gorm.Model
+	Name   string `gorm:"uniqueIndex:idx_metric_snapshot_series"`
+	Labels string `gorm:"uniqueIndex:idx_metric_snapshot_series"`
+	Value  float64
+}
+
+func (MetricSnapshots) TableName() string {
+	return "metric_snapshots"
+}
+
+func (m MetricSnapshots) GetName() string {
+	return m.Name
+}
+
+func (m MetricSnapshots) GetLabels() string {
+	return m.Labels
+}
+
+func (m MetricSnapshots) GetValue() float64 {
+	return m.Value
+}
@@ -0,0 +1,56 @@
+package models
+
+import "gorm.io/gorm"
+
+// kinds of change recorded in the pair changelog
+const (
+	PAIR_CHANGE_ADDED               = "added"               // listed by Finchains, or a DEX pair discovered or configured
+	PAIR_CHANGE_DELISTED            = "delisted"            // no longer listed by Finchains
+	PAIR_CHANGE_BLOCKLISTED         = "blocklisted"         // matched by jobs.exclude_pairs
+	PAIR_CHANGE_UNBLOCKLISTED       = "unblocklisted"       // no longer matched by jobs.exclude_pairs
+	PAIR_CHANGE_LIQUIDITY_DROPPED   = "liquidity_dropped"   // a DEX pair's liquidity fell below the alert threshold
+	PAIR_CHANGE_LIQUIDITY_RECOVERED = "liquidity_recovered" // a DEX pair's liquidity recovered above the alert threshold
+	PAIR_CHANGE_SOURCE_EXCLUDED     = "source_excluded"     // a source not used for the pair after a permanent error
+	PAIR_CHANGE_SOURCE_UNAVAILABLE  = "source_unavailable"  // a source became unhealthy
+	PAIR_CHANGE_SOURCE_AVAILABLE    = "source_available"    // a source recovered
+)
+
+// PairChangeKinds are the kinds of change recorded in the pair changelog
+var PairChangeKinds = []string{
+	PAIR_CHANGE_ADDED, PAIR_CHANGE_DELISTED, PAIR_CHANGE_BLOCKLISTED, PAIR_CHANGE_UNBLOCKLISTED,
+	PAIR_CHANGE_LIQUIDITY_DROPPED, PAIR_CHANGE_LIQUIDITY_RECOVERED, PAIR_CHANGE_SOURCE_EXCLUDED,
+	PAIR_CHANGE_SOURCE_UNAVAILABLE, PAIR_CHANGE_SOURCE_AVAILABLE,
+}
+
+// PairChanges is the changelog of the pairs the node can answer - a change to the supported pairs,
+// the DEX pairs or a source's availability, recorded at CreatedAt. Pair is as the source names it,
+// e.g. BTC.USD for Finchains or XFUND-WETH for a DEX, a jobs.exclude_pairs pattern for blocklist
+// changes, or empty for a change to a source which affects every pair. Source is the DEX, data
+// source or Finchains endpoint changed, if any
+type PairChanges struct {
+	gorm.Model
+	Pair   string `gorm:"index"`
+	Change string `gorm:"index"`
+	Source string `gorm:"index"`
+	Reason string
+}
+
+func (PairChanges) TableName() string {
+	return "pair_changes"
+}
+
+func (p PairChanges) GetPair() string {
+	return p.Pair
+}
+
+func (p PairChanges) GetChange() string {
+	return p.Change
+}
+
+func (p PairChanges) GetSource() string {
+	return p.Source
+}
+
+func (p PairChanges) GetReason() string {
+	return p.Reason
+}
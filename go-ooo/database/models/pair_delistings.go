@@ -0,0 +1,46 @@
+package models
+
+import "gorm.io/gorm"
+
+// PairDelistings is a consumer which requested a pair in the lookback before Finchains stopped
+// listing it, recorded when the pair is removed from the supported pairs so that the operator can
+// warn the consumer. Requests is the number of requests for the pair in the lookback, and
+// LastRequestedAt, unix seconds, the latest. A delisted pair no consumer requested is recorded
+// with an empty Consumer. Notified is set once the delisting has been alerted
+type PairDelistings struct {
+	gorm.Model
+	Pair            string `gorm:"index"`
+	DelistedAt      int64  `gorm:"index"`
+	Consumer        string `gorm:"index"`
+	Requests        uint64
+	LastRequestedAt int64
+	Notified        bool `gorm:"index"`
+}
+
+func (PairDelistings) TableName() string {
+	return "pair_delistings"
+}
+
+func (p PairDelistings) GetPair() string {
+	return p.Pair
+}
+
+func (p PairDelistings) GetDelistedAt() int64 {
+	return p.DelistedAt
+}
+
+func (p PairDelistings) GetConsumer() string {
+	return p.Consumer
+}
+
+func (p PairDelistings) GetRequests() uint64 {
+	return p.Requests
+}
+
+func (p PairDelistings) GetLastRequestedAt() int64 {
+	return p.LastRequestedAt
+}
+
+func (p PairDelistings) GetNotified() bool {
+	return p.Notified
+}
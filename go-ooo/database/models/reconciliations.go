@@ -0,0 +1,40 @@
+package models
+
+import "gorm.io/gorm"
+
+// Reconciliations records a run of the end of day reconciliation of the db's view of requests
+// against the router's on-chain state. Blocks FromBlock to ToBlock were checked for fulfillments
+// and fee withdrawals. Withdrawable is the providers' on-chain fee balance at ToBlock, and
+// ExpectedWithdrawable the balance expected from the previous run's and the fees of the requests
+// fulfilled since, less withdrawals. Discrepancies is a JSON array of the differences found
+type Reconciliations struct {
+	gorm.Model
+	FromBlock            uint64
+	ToBlock              uint64 `gorm:"index"`
+	Checked              int
+	NumDiscrepancies     int
+	Fixed                int
+	Withdrawable         string
+	ExpectedWithdrawable string
+	Discrepancies        string
+}
+
+func (Reconciliations) TableName() string {
+	return "reconciliations"
+}
+
+func (r Reconciliations) GetFromBlock() uint64 {
+	return r.FromBlock
+}
+
+func (r Reconciliations) GetToBlock() uint64 {
+	return r.ToBlock
+}
+
+func (r Reconciliations) GetWithdrawable() string {
+	return r.Withdrawable
+}
+
+func (r Reconciliations) GetDiscrepancies() string {
+	return r.Discrepancies
+}
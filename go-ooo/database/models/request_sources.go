@@ -0,0 +1,56 @@
+package models
+
+import "gorm.io/gorm"
+
+// RequestSources records each source value fetched to answer a request, and how it was used.
+// Excluded is why the value did not contribute to the answer, e.g. outlier, or the validation
+// failure if the source's response was rejected
+type RequestSources struct {
+	gorm.Model
+	RequestId string `gorm:"index"`
+	Source    string
+	Value     float64
+	Deviation float64
+	Excluded  string
+	Weight    float64
+	LatencyMs int64
+	// PriceImpact is the percent price impact of the jobs.dex_impact_notional trade on the DEX
+	// pool the value came from, if it came from one
+	PriceImpact float64
+}
+
+func (RequestSources) TableName() string {
+	return "request_sources"
+}
+
+func (r RequestSources) GetRequestId() string {
+	return r.RequestId
+}
+
+func (r RequestSources) GetSource() string {
+	return r.Source
+}
+
+func (r RequestSources) GetValue() float64 {
+	return r.Value
+}
+
+func (r RequestSources) GetDeviation() float64 {
+	return r.Deviation
+}
+
+func (r RequestSources) GetExcluded() string {
+	return r.Excluded
+}
+
+func (r RequestSources) GetWeight() float64 {
+	return r.Weight
+}
+
+func (r RequestSources) GetLatencyMs() int64 {
+	return r.LatencyMs
+}
+
+func (r RequestSources) GetPriceImpact() float64 {
+	return r.PriceImpact
+}
@@ -0,0 +1,52 @@
+package models
+
+import "gorm.io/gorm"
+
+// StateSnapshots is the node's processing state, saved on a clean shutdown and verified the next
+// time a node starts processing, on this host or another. LastBlock is the last block of
+// NetworkId processed, Nonces a JSON object of each key's address to its next nonce, and
+// PendingTxs a JSON array of the fulfillment txs sent but not yet mined. VerifiedAt, unix
+// seconds, is 0 until the snapshot is verified, and Verification is then a JSON array of the
+// differences found
+type StateSnapshots struct {
+	gorm.Model
+	Host         string
+	NetworkId    int64
+	LastBlock    uint64
+	Nonces       string
+	PendingTxs   string
+	VerifiedAt   int64 `gorm:"index"`
+	Verification string
+}
+
+func (StateSnapshots) TableName() string {
+	return "state_snapshots"
+}
+
+func (s StateSnapshots) GetHost() string {
+	return s.Host
+}
+
+func (s StateSnapshots) GetNetworkId() int64 {
+	return s.NetworkId
+}
+
+func (s StateSnapshots) GetLastBlock() uint64 {
+	return s.LastBlock
+}
+
+func (s StateSnapshots) GetNonces() string {
+	return s.Nonces
+}
+
+func (s StateSnapshots) GetPendingTxs() string {
+	return s.PendingTxs
+}
+
+func (s StateSnapshots) GetVerifiedAt() int64 {
+	return s.VerifiedAt
+}
+
+func (s StateSnapshots) GetVerification() string {
+	return s.Verification
+}
@@ -0,0 +1,33 @@
+package models
+
+import "gorm.io/gorm"
+
+// SubgraphSpend is the number and cost of the queries made to a DEX's subgraph in a UTC month,
+// yyyy-mm, counted against jobs.subgraph_monthly_budget
+type SubgraphSpend struct {
+	gorm.Model
+	DexName string `gorm:"uniqueIndex:idx_subgraph_spend_dex_month"`
+	Month   string `gorm:"uniqueIndex:idx_subgraph_spend_dex_month"`
+	Queries uint64
+	Cost    float64
+}
+
+func (SubgraphSpend) TableName() string {
+	return "subgraph_spend"
+}
+
+func (s SubgraphSpend) GetDexName() string {
+	return s.DexName
+}
+
+func (s SubgraphSpend) GetMonth() string {
+	return s.Month
+}
+
+func (s SubgraphSpend) GetQueries() uint64 {
+	return s.Queries
+}
+
+func (s SubgraphSpend) GetCost() float64 {
+	return s.Cost
+}
@@ -0,0 +1,93 @@
+package models
+
+import "gorm.io/gorm"
+
+// VorRequests are randomness requests received from the VORCoordinator contract,
+// for our proving key. Request and job statuses share the DataRequests constants
+type VorRequests struct {
+	gorm.Model
+	RequestId                   string `gorm:"uniqueIndex"`
+	KeyHash                     string `gorm:"index"`
+	Sender                      string `gorm:"index"`
+	Seed                        string
+	Fee                         string
+	RequestBlockNumber          uint64 `gorm:"index"`
+	RequestBlockHash            string
+	RequestTxHash               string `gorm:"index"`
+	Randomness                  string
+	LastFulfillSentBlockNumber  uint64 `gorm:"index"`
+	FulfillConfirmedBlockNumber uint64 `gorm:"index"`
+	FulfillTxHash               string `gorm:"index"`
+	FulfillmentAttempts         uint64 `gorm:"default:0"`
+	JobStatus                   int    `gorm:"index"`
+	RequestStatus               int    `gorm:"index"`
+	StatusReason                string
+}
+
+func (VorRequests) TableName() string {
+	return "vor_requests"
+}
+
+func (v VorRequests) GetId() uint {
+	return v.ID
+}
+
+func (v VorRequests) GetRequestId() string {
+	return v.RequestId
+}
+
+func (v VorRequests) GetKeyHash() string {
+	return v.KeyHash
+}
+
+func (v VorRequests) GetSender() string {
+	return v.Sender
+}
+
+func (v VorRequests) GetSeed() string {
+	return v.Seed
+}
+
+func (v VorRequests) GetFee() string {
+	return v.Fee
+}
+
+func (v VorRequests) GetRequestBlockNumber() uint64 {
+	return v.RequestBlockNumber
+}
+
+func (v VorRequests) GetRequestBlockHash() string {
+	return v.RequestBlockHash
+}
+
+func (v VorRequests) GetRequestTxHash() string {
+	return v.RequestTxHash
+}
+
+func (v VorRequests) GetRandomness() string {
+	return v.Randomness
+}
+
+func (v VorRequests) GetLastFulfillSentBlockNumber() uint64 {
+	return v.LastFulfillSentBlockNumber
+}
+
+func (v VorRequests) GetFulfillTxHash() string {
+	return v.FulfillTxHash
+}
+
+func (v VorRequests) GetFulfillmentAttempts() uint64 {
+	return v.FulfillmentAttempts
+}
+
+func (v VorRequests) GetRequestStatus() int {
+	return v.RequestStatus
+}
+
+func (v VorRequests) GetJobStatus() int {
+	return v.JobStatus
+}
+
+func (v VorRequests) GetStatusReason() string {
+	return v.StatusReason
+}
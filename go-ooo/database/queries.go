@@ -2,7 +2,13 @@ package database
 
 import (
 	"fmt"
+	"github.com/spf13/viper"
+	"go-ooo/config"
 	"go-ooo/database/models"
+	"gorm.io/gorm"
+	"sort"
+	"strings"
+	"time"
 )
 
 /*
@@ -25,6 +31,17 @@ func (d *DB) FindByRequestId(requestId string) (models.DataRequests, error) {
 	return result, err
 }
 
+// FindByTxHash returns the request whose request tx, or any of whose fulfillment txs, including
+// reverted ones, has the hash txHash
+func (d *DB) FindByTxHash(txHash string) (models.DataRequests, error) {
+	result := models.DataRequests{}
+	err := d.Where("request_tx_hash = ? OR fulfill_tx_hash = ?", txHash, txHash).
+		Or("request_id IN (?)", d.Model(&models.GasSpends{}).Select("request_id").Where("tx_hash = ?", txHash)).
+		Or("request_id IN (?)", d.Model(&models.FailedFulfilment{}).Select("request_id").Where("tx_hash = ?", txHash)).
+		First(&result).Error
+	return result, err
+}
+
 func (d *DB) GetPendingJobs() ([]models.DataRequests, error) {
 	var jobs = []models.DataRequests{}
 	err := d.Where("job_status = ?",
@@ -32,6 +49,176 @@ func (d *DB) GetPendingJobs() ([]models.DataRequests, error) {
 	return jobs, err
 }
 
+// CountUnsentJobs returns the number of pending requests whose fulfillment tx has not been sent
+func (d *DB) CountUnsentJobs() (int64, error) {
+	var count int64
+	err := d.Model(&models.DataRequests{}).Where("job_status = ? AND request_status != ?",
+		models.JOB_STATUS_PENDING, models.REQUEST_STATUS_TX_SENT).Count(&count).Error
+	return count, err
+}
+
+// CountInFlightTxs returns the number of data and randomness requests whose fulfillment tx has been
+// broadcast, but not yet seen fulfilled on chain
+func (d *DB) CountInFlightTxs() (int64, error) {
+	var data, vor int64
+	err := d.Model(&models.DataRequests{}).Where("job_status = ? AND request_status = ?",
+		models.JOB_STATUS_PENDING, models.REQUEST_STATUS_TX_SENT).Count(&data).Error
+	if err != nil {
+		return 0, err
+	}
+	err = d.Model(&models.VorRequests{}).Where("job_status = ? AND request_status = ?",
+		models.JOB_STATUS_PENDING, models.REQUEST_STATUS_TX_SENT).Count(&vor).Error
+	return data + vor, err
+}
+
+// CountPendingJobsForProvider returns the number of pending requests addressed to provider
+func (d *DB) CountPendingJobsForProvider(provider string) (int64, error) {
+	var count int64
+	err := d.Model(&models.DataRequests{}).Where("job_status = ? AND provider = ?",
+		models.JOB_STATUS_PENDING, provider).Count(&count).Error
+	return count, err
+}
+
+// GetStuckJobs returns pending requests which have had the given status since before the given time
+func (d *DB) GetStuckJobs(status int, before time.Time) ([]models.DataRequests, error) {
+	var jobs = []models.DataRequests{}
+	err := d.Where("job_status = ? AND request_status = ? AND updated_at < ?",
+		models.JOB_STATUS_PENDING, status, before).Order(fmt.Sprintf("id %s", "asc")).Find(&jobs).Error
+	return jobs, err
+}
+
+// GetDeadJobs returns dead lettered requests, most recent first
+func (d *DB) GetDeadJobs(limit int) ([]models.DataRequests, error) {
+	var jobs = []models.DataRequests{}
+	q := d.Where("request_status = ?", models.REQUEST_STATUS_DEAD).Order(fmt.Sprintf("id %s", "desc"))
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	err := q.Find(&jobs).Error
+	return jobs, err
+}
+
+// GetLastFulfilledForPair returns the most recently fulfilled request for base/target
+func (d *DB) GetLastFulfilledForPair(base string, target string) (models.DataRequests, error) {
+	result := models.DataRequests{}
+	err := d.Where("request_status = ? AND endpoint_decoded LIKE ?", models.REQUEST_STATUS_SUCCESS,
+		fmt.Sprintf("%s.%s.%%", base, target)).Order(fmt.Sprintf("id %s", "desc")).First(&result).Error
+	return result, err
+}
+
+// GetRequestsForPairSince returns the requests received since since for any endpoint of the pair
+// base/target, oldest first
+func (d *DB) GetRequestsForPairSince(base string, target string, since time.Time) ([]models.DataRequests, error) {
+	var jobs []models.DataRequests
+	err := d.Where("endpoint_decoded LIKE ? AND created_at >= ?", fmt.Sprintf("%s.%s.%%", base, target), since).
+		Order("id asc").Find(&jobs).Error
+	return jobs, err
+}
+
+// JobFilter search parameters for SearchJobs. Nil and empty fields are not filtered on
+type JobFilter struct {
+	RequestStatus *int
+	JobStatus     *int
+	Consumer      string
+	Endpoint      string    // matches endpoints starting with
+	ErrorCode     string    // errcodes code of the failure recorded by the request's status
+	Since         time.Time // last updated at or after
+	// TaggedConsumers and TaggedPairs match requests from any of the consumers, or for any of
+	// the pairs, e.g. BTC.USD. Used to find the requests with a tag
+	TaggedConsumers []string
+	TaggedPairs     []string
+	Limit           int
+	Offset          int
+}
+
+// SearchJobs returns requests matching the filter, most recent first
+func (d *DB) SearchJobs(filter JobFilter) ([]models.DataRequests, error) {
+	var jobs = []models.DataRequests{}
+	q := d.Order(fmt.Sprintf("id %s", "desc"))
+	if filter.RequestStatus != nil {
+		q = q.Where("request_status = ?", *filter.RequestStatus)
+	}
+	if filter.JobStatus != nil {
+		q = q.Where("job_status = ?", *filter.JobStatus)
+	}
+	if filter.Consumer != "" {
+		q = q.Where("LOWER(consumer) = LOWER(?)", filter.Consumer)
+	}
+	if filter.Endpoint != "" {
+		q = q.Where("endpoint_decoded LIKE ?", filter.Endpoint+"%")
+	}
+	if filter.ErrorCode != "" {
+		q = q.Where("error_code = ?", filter.ErrorCode)
+	}
+	if !filter.Since.IsZero() {
+		q = q.Where("updated_at >= ?", filter.Since)
+	}
+	if len(filter.TaggedConsumers) > 0 || len(filter.TaggedPairs) > 0 {
+		conds := make([]string, 0, len(filter.TaggedPairs)+1)
+		args := make([]interface{}, 0, len(filter.TaggedPairs)+1)
+		if len(filter.TaggedConsumers) > 0 {
+			conds = append(conds, "consumer IN ?")
+			args = append(args, filter.TaggedConsumers)
+		}
+		for _, pair := range filter.TaggedPairs {
+			conds = append(conds, "endpoint_decoded LIKE ?")
+			args = append(args, pair+".%")
+		}
+		q = q.Where(strings.Join(conds, " OR "), args...)
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		q = q.Offset(filter.Offset)
+	}
+	err := q.Find(&jobs).Error
+	return jobs, err
+}
+
+// GetLatestFulfilledPerEndpoint returns the most recent successful request for each endpoint
+func (d *DB) GetLatestFulfilledPerEndpoint() ([]models.DataRequests, error) {
+	var jobs = []models.DataRequests{}
+	latest := d.Model(&models.DataRequests{}).Select("MAX(id)").
+		Where("request_status = ?", models.REQUEST_STATUS_SUCCESS).Group("endpoint_decoded")
+	err := d.Where("id IN (?)", latest).Find(&jobs).Error
+	return jobs, err
+}
+
+// GetFulfilledRequestsSince returns requests whose fulfillment was mined since the given time
+func (d *DB) GetFulfilledRequestsSince(since time.Time) ([]models.DataRequests, error) {
+	var jobs = []models.DataRequests{}
+	err := d.Where("request_status = ? AND tx_mined_at >= ?", models.REQUEST_STATUS_SUCCESS,
+		since.UnixNano()/int64(time.Millisecond)).Find(&jobs).Error
+	return jobs, err
+}
+
+// GetFulfilledRequestsBetween returns requests fulfilled between from and to. Requests fulfilled
+// before mined times were recorded are matched on their last update
+func (d *DB) GetFulfilledRequestsBetween(from time.Time, to time.Time) ([]models.DataRequests, error) {
+	var jobs = []models.DataRequests{}
+	err := d.Where("request_status = ? AND ((tx_mined_at > 0 AND tx_mined_at BETWEEN ? AND ?) OR (tx_mined_at = 0 AND updated_at BETWEEN ? AND ?))",
+		models.REQUEST_STATUS_SUCCESS,
+		from.UnixNano()/int64(time.Millisecond), to.UnixNano()/int64(time.Millisecond),
+		from, to).Find(&jobs).Error
+	return jobs, err
+}
+
+// GetRequestsReceivedBetween returns requests, in any status, received between from and to
+func (d *DB) GetRequestsReceivedBetween(from time.Time, to time.Time) ([]models.DataRequests, error) {
+	var jobs = []models.DataRequests{}
+	err := d.Where("created_at BETWEEN ? AND ?", from, to).Find(&jobs).Error
+	return jobs, err
+}
+
+// GetRecentAdhocEndpoints returns the distinct decoded endpoints of ad-hoc requests received since the given time
+func (d *DB) GetRecentAdhocEndpoints(since time.Time) ([]string, error) {
+	var endpoints []string
+	err := d.Model(&models.DataRequests{}).Where("is_adhoc = ? AND created_at > ?", true, since).
+		Distinct().Pluck("endpoint_decoded", &endpoints).Error
+	return endpoints, err
+}
+
 func (d *DB) GetLastXSuccessfulRequests(limit int, consumer string) ([]models.DataRequests, error) {
 	var requests = []models.DataRequests{}
 	var err error
@@ -65,6 +252,18 @@ func (d *DB) GetLeastGasUsed() (models.DataRequests, error) {
   SupportedPairs queries
 */
 
+func (d *DB) CountSupportedPairs() (int64, error) {
+	var count int64
+	err := d.Model(&models.SupportedPairs{}).Count(&count).Error
+	return count, err
+}
+
+func (d *DB) GetSupportedPairs() ([]models.SupportedPairs, error) {
+	var pairs = []models.SupportedPairs{}
+	err := d.Order("name asc").Find(&pairs).Error
+	return pairs, err
+}
+
 func (d *DB) PairIsSupportedByPairName(pair string) (models.SupportedPairs, error) {
 	supported := models.SupportedPairs{}
 	err := d.Where("name = ?", pair).First(&supported).Error
@@ -87,24 +286,273 @@ func (d *DB) PairsNoLongerSupported(pairs []string) ([]models.SupportedPairs, er
   DexPairs queries
 */
 
+// FindByDexPairName returns dexName's pair of base and target, in either order. If the symbols are
+// shared by several token contracts, the pair of the tokens ranked highest by RankDexTokensBySymbol
+// is returned. Otherwise, e.g. for pools of the same tokens with different fee tiers, the pair with
+// the most liquidity is returned
 func (d *DB) FindByDexPairName(base string, target string, dexName string) (models.DexPairs, error) {
 	pair := fmt.Sprintf("%s-%s", base, target)
 	pairRev := fmt.Sprintf("%s-%s", target, base)
-	result := models.DexPairs{}
+	var pairs []models.DexPairs
 	err := d.Where(
 		"(pair = ? OR pair = ?) AND dex_name = ?", pair, pairRev, dexName,
-	).Order("reserve_usd desc").First(&result).Error
-	return result, err
+	).Order("reserve_usd desc, id asc").Find(&pairs).Error
+	if err != nil {
+		return models.DexPairs{}, err
+	}
+	if len(pairs) == 0 {
+		return models.DexPairs{}, gorm.ErrRecordNotFound
+	}
+	if !pairsShareSymbols(pairs) {
+		return pairs[0], nil
+	}
+
+	ranks := make(map[uint]int)
+	for _, symbol := range []string{base, target} {
+		tokens, err := d.RankDexTokensBySymbol(symbol, dexName)
+		if err != nil {
+			return pairs[0], err
+		}
+		for i, t := range tokens {
+			ranks[t.ID] = i
+		}
+	}
+
+	rank := func(p models.DexPairs) int {
+		r := 0
+		for _, id := range []uint{p.T0DexTokenId, p.T1DexTokenId} {
+			if i, ok := ranks[id]; ok {
+				r += i
+			} else {
+				r += len(ranks)
+			}
+		}
+		return r
+	}
+
+	// pairs are in liquidity order, so the most liquid of equally ranked pairs is kept
+	best := pairs[0]
+	for _, p := range pairs[1:] {
+		if rank(p) < rank(best) {
+			best = p
+		}
+	}
+	return best, nil
+}
+
+// pairsShareSymbols returns true if pairs of the same symbols are of different token contracts
+func pairsShareSymbols(pairs []models.DexPairs) bool {
+	tokens := func(p models.DexPairs) [2]uint {
+		if p.T0DexTokenId > p.T1DexTokenId {
+			return [2]uint{p.T1DexTokenId, p.T0DexTokenId}
+		}
+		return [2]uint{p.T0DexTokenId, p.T1DexTokenId}
+	}
+	for _, p := range pairs[1:] {
+		if tokens(p) != tokens(pairs[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindDexPairByContract returns dexName's pair with the contract address
+func (d *DB) FindDexPairByContract(contractAddress string, dexName string) (models.DexPairs, error) {
+	var pair models.DexPairs
+	err := d.Where("contract_address = ? AND dex_name = ?", contractAddress, dexName).First(&pair).Error
+	return pair, err
+}
+
+func (d *DB) GetDexPairsByDexName(dexName string) ([]models.DexPairs, error) {
+	var pairs = []models.DexPairs{}
+	err := d.Where("dex_name = ?", dexName).Order("id asc").Find(&pairs).Error
+	return pairs, err
+}
+
+// GetDexSyncCursor returns the block dexName's pairs were last synced at. The cursor is empty if
+// the dex has never been synced
+func (d *DB) GetDexSyncCursor(dexName string) (models.DexSyncCursors, error) {
+	c := models.DexSyncCursors{}
+	err := d.Where("dex_name = ?", dexName).Limit(1).Find(&c).Error
+	return c, err
+}
+
+/*
+  GasSpends queries
+*/
+
+// GetGasSpendsBetween returns the fulfillment txs mined between the from and to times, oldest first
+func (d *DB) GetGasSpendsBetween(from time.Time, to time.Time) ([]models.GasSpends, error) {
+	var spends []models.GasSpends
+	err := d.Where("block_time >= ? AND block_time < ?", from.Unix(), to.Unix()).
+		Order("block_time asc").Find(&spends).Error
+	return spends, err
+}
+
+// GetGasSpendsForRequest returns the request's mined fulfillment txs, oldest first
+func (d *DB) GetGasSpendsForRequest(requestId string) ([]models.GasSpends, error) {
+	var spends []models.GasSpends
+	err := d.Where("request_id = ?", requestId).Order("block_number asc").Find(&spends).Error
+	return spends, err
+}
+
+/*
+  PairChanges queries
+*/
+
+// PairChangeFilter search parameters for GetPairChanges. Empty fields are not filtered on
+type PairChangeFilter struct {
+	// Pairs are upper case pair names, any of which is matched. Changes to every pair and
+	// blocklist patterns are matched too, to be filtered by the caller
+	Pairs   []string
+	Source  string
+	Changes []string
+	Since   time.Time // recorded at or after
+	Until   time.Time // recorded before
+}
+
+// GetPairChanges returns the pair changelog entries matching the filter, most recent first
+func (d *DB) GetPairChanges(filter PairChangeFilter) ([]models.PairChanges, error) {
+	var changes = []models.PairChanges{}
+	q := d.Order("id desc")
+	if len(filter.Pairs) > 0 {
+		q = q.Where("UPPER(pair) IN ? OR pair = ? OR pair LIKE ? OR pair LIKE ?", filter.Pairs, "", "%*%", "%?%")
+	}
+	if filter.Source != "" {
+		q = q.Where("LOWER(source) = ?", strings.ToLower(filter.Source))
+	}
+	if len(filter.Changes) > 0 {
+		q = q.Where("pair_changes.change IN ?", filter.Changes)
+	}
+	if !filter.Since.IsZero() {
+		q = q.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		q = q.Where("created_at < ?", filter.Until)
+	}
+	err := q.Find(&changes).Error
+	return changes, err
+}
+
+/*
+  PairDelistings queries
+*/
+
+// GetPairDelistings returns the consumers of the pairs delisted since since, newest delisting first
+func (d *DB) GetPairDelistings(since time.Time) ([]models.PairDelistings, error) {
+	var delistings []models.PairDelistings
+	err := d.Where("delisted_at >= ?", since.Unix()).Order("delisted_at desc, pair asc, requests desc").
+		Find(&delistings).Error
+	return delistings, err
+}
+
+// GetUnnotifiedPairDelistings returns the consumers of delisted pairs which have not been alerted,
+// oldest delisting first
+func (d *DB) GetUnnotifiedPairDelistings() ([]models.PairDelistings, error) {
+	var delistings []models.PairDelistings
+	err := d.Where("notified = ?", false).Order("delisted_at asc, pair asc, requests desc").
+		Find(&delistings).Error
+	return delistings, err
+}
+
+/*
+  SubgraphSpend queries
+*/
+
+// GetSubgraphSpend returns each DEX's subgraph query spend in month, a UTC yyyy-mm month
+func (d *DB) GetSubgraphSpend(month string) ([]models.SubgraphSpend, error) {
+	var spend []models.SubgraphSpend
+	err := d.Where("month = ?", month).Order("dex_name asc").Find(&spend).Error
+	return spend, err
 }
 
 /*
   DexTokens queries
 */
 
+// FindByDexTokenSymbol returns dexName's token for symbol. If several contracts share the symbol,
+// the token ranked highest by RankDexTokensBySymbol is returned
 func (d *DB) FindByDexTokenSymbol(symbol string, dexName string) (models.DexTokens, error) {
-	result := models.DexTokens{}
-	err := d.Where("token_symbol = ? AND dex_name = ?", symbol, dexName).First(&result).Error
-	return result, err
+	tokens, err := d.RankDexTokensBySymbol(symbol, dexName)
+	if err != nil {
+		return models.DexTokens{}, err
+	}
+	if len(tokens) == 0 {
+		return models.DexTokens{}, gorm.ErrRecordNotFound
+	}
+	return tokens[0], nil
+}
+
+// RankDexTokensBySymbol returns dexName's tokens for symbol, best first. Tokens whose contract is
+// pinned for the symbol in jobs.token_pins come first, then the rest by the cumulative liquidity,
+// then 24 hour volume, of their pairs on the DEX
+func (d *DB) RankDexTokensBySymbol(symbol string, dexName string) ([]models.DexTokens, error) {
+	var tokens = []models.DexTokens{}
+	err := d.Where("token_symbol = ? AND dex_name = ?", symbol, dexName).Order("id asc").Find(&tokens).Error
+	if err != nil || len(tokens) < 2 {
+		return tokens, err
+	}
+
+	ids := make([]uint, 0, len(tokens))
+	contractIds := make([]uint, 0, len(tokens))
+	for _, t := range tokens {
+		ids = append(ids, t.ID)
+		contractIds = append(contractIds, t.TokenContractsId)
+	}
+
+	var pairs []models.DexPairs
+	err = d.Where("dex_name = ? AND (t0_dex_token_id IN ? OR t1_dex_token_id IN ?)", dexName, ids, ids).Find(&pairs).Error
+	if err != nil {
+		return nil, err
+	}
+	liquidity := make(map[uint]float64)
+	volume := make(map[uint]float64)
+	for _, p := range pairs {
+		for _, id := range []uint{p.T0DexTokenId, p.T1DexTokenId} {
+			liquidity[id] += p.ReserveUsd
+			volume[id] += p.VolumeUsd24h
+		}
+	}
+
+	pinned := make(map[uint]bool)
+	if pins := tokenPins(symbol); len(pins) > 0 {
+		var contracts []models.TokenContracts
+		if err = d.Where("id IN ?", contractIds).Find(&contracts).Error; err != nil {
+			return nil, err
+		}
+		for _, c := range contracts {
+			if pins[strings.ToLower(c.ContractAddress)] {
+				pinned[c.ID] = true
+			}
+		}
+	}
+
+	sort.SliceStable(tokens, func(i, j int) bool {
+		a, b := tokens[i], tokens[j]
+		if pa, pb := pinned[a.TokenContractsId], pinned[b.TokenContractsId]; pa != pb {
+			return pa
+		}
+		if liquidity[a.ID] != liquidity[b.ID] {
+			return liquidity[a.ID] > liquidity[b.ID]
+		}
+		return volume[a.ID] > volume[b.ID]
+	})
+
+	return tokens, nil
+}
+
+// tokenPins returns the lower case contract addresses pinned for symbol in jobs.token_pins
+func tokenPins(symbol string) map[string]bool {
+	pins := make(map[string]bool)
+	for s, addresses := range viper.GetStringMapStringSlice(config.JobsTokenPins) {
+		if !strings.EqualFold(s, symbol) {
+			continue
+		}
+		for _, a := range addresses {
+			pins[strings.ToLower(a)] = true
+		}
+	}
+	return pins
 }
 
 func (d *DB) FindByDexTokenAll(symbol string, dexName string, tokenContractsId uint) (models.DexTokens, error) {
@@ -135,6 +583,265 @@ func (d *DB) FindTokenAddressByRowId(id uint) (string, error) {
 	return result.ContractAddress, err
 }
 
+/*
+  Attestations queries
+*/
+
+// GetAttestationsByRequestIds returns the attestations for the given requests
+func (d *DB) GetAttestationsByRequestIds(requestIds []string) ([]models.Attestations, error) {
+	var attestations = []models.Attestations{}
+	if len(requestIds) == 0 {
+		return attestations, nil
+	}
+	err := d.Where("request_id IN ?", requestIds).Find(&attestations).Error
+	return attestations, err
+}
+
+func (d *DB) GetAttestationByRequestId(requestId string) (models.Attestations, error) {
+	result := models.Attestations{}
+	err := d.Where("request_id = ?", requestId).First(&result).Error
+	return result, err
+}
+
+/*
+  VorRequests Queries
+*/
+
+func (d *DB) FindVorRequestByRequestId(requestId string) (models.VorRequests, error) {
+	result := models.VorRequests{}
+	err := d.Where("request_id = ?", requestId).First(&result).Error
+	return result, err
+}
+
+func (d *DB) GetPendingVorRequests() ([]models.VorRequests, error) {
+	var requests = []models.VorRequests{}
+	err := d.Where("job_status = ?",
+		models.JOB_STATUS_PENDING).Order(fmt.Sprintf("id %s", "asc")).Find(&requests).Error
+	return requests, err
+}
+
+/*
+  JournalEntries Queries
+*/
+
+// GetUnresolvedTxIntents returns fulfillment txs which were signed, but not confirmed as broadcast or failed
+func (d *DB) GetUnresolvedTxIntents() ([]models.JournalEntries, error) {
+	var entries = []models.JournalEntries{}
+	err := d.Where("kind = ? AND resolved = ?", models.JOURNAL_KIND_TX_INTENT, false).
+		Order(fmt.Sprintf("id %s", "asc")).Find(&entries).Error
+	return entries, err
+}
+
+// GetJournalEntriesForRequest returns the journal entries for a request, oldest first
+func (d *DB) GetJournalEntriesForRequest(requestId string) ([]models.JournalEntries, error) {
+	var entries = []models.JournalEntries{}
+	err := d.Where("request_id = ?", requestId).Order(fmt.Sprintf("id %s", "asc")).Find(&entries).Error
+	return entries, err
+}
+
+/*
+  AuditLog Queries
+*/
+
+// AuditFilter search parameters for SearchAuditLog. Empty fields are not filtered on
+type AuditFilter struct {
+	Actor     string
+	Action    string
+	RequestId string
+	Since     time.Time // recorded at or after
+	Limit     int
+	Offset    int
+}
+
+// SearchAuditLog returns audit log entries matching the filter, most recent first
+func (d *DB) SearchAuditLog(filter AuditFilter) ([]models.AuditLog, error) {
+	var entries = []models.AuditLog{}
+	q := d.Order(fmt.Sprintf("id %s", "desc"))
+	if filter.Actor != "" {
+		q = q.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		q = q.Where("action = ?", filter.Action)
+	}
+	if filter.RequestId != "" {
+		q = q.Where("request_id = ?", filter.RequestId)
+	}
+	if !filter.Since.IsZero() {
+		q = q.Where("created_at >= ?", filter.Since)
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		q = q.Offset(filter.Offset)
+	}
+	err := q.Find(&entries).Error
+	if err != nil {
+		return entries, err
+	}
+	for i := range entries {
+		if entries[i].Params, err = d.decrypt(entries[i].Params); err != nil {
+			return entries, err
+		}
+	}
+	return entries, nil
+}
+
+/*
+  ConsumerMetadata and PairMetadata Queries
+*/
+
+// GetConsumerMetadata returns the tags and note for a consumer. A consumer with none has no tags
+func (d *DB) GetConsumerMetadata(consumer string) (models.ConsumerMetadata, error) {
+	result := models.ConsumerMetadata{}
+	err := d.Where("consumer = ?", consumer).Limit(1).Find(&result).Error
+	if err != nil {
+		return result, err
+	}
+	result.Note, err = d.decrypt(result.Note)
+	return result, err
+}
+
+// GetPairMetadata returns the tags and note for a pair. A pair with none has no tags
+func (d *DB) GetPairMetadata(pair string) (models.PairMetadata, error) {
+	result := models.PairMetadata{}
+	err := d.Where("pair = ?", pair).Limit(1).Find(&result).Error
+	if err != nil {
+		return result, err
+	}
+	result.Note, err = d.decrypt(result.Note)
+	return result, err
+}
+
+// GetAllConsumerMetadata returns the tags and notes of every consumer which has them
+func (d *DB) GetAllConsumerMetadata() ([]models.ConsumerMetadata, error) {
+	var result = []models.ConsumerMetadata{}
+	err := d.Order("consumer asc").Find(&result).Error
+	if err != nil {
+		return result, err
+	}
+	for i := range result {
+		if result[i].Note, err = d.decrypt(result[i].Note); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// GetAllPairMetadata returns the tags and notes of every pair which has them
+func (d *DB) GetAllPairMetadata() ([]models.PairMetadata, error) {
+	var result = []models.PairMetadata{}
+	err := d.Order("pair asc").Find(&result).Error
+	if err != nil {
+		return result, err
+	}
+	for i := range result {
+		if result[i].Note, err = d.decrypt(result[i].Note); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+/*
+  MaintenanceWindows Queries
+*/
+
+// GetActiveMaintenanceWindow returns the maintenance window which has not been ended, which may
+// have passed its Until time. The window's ID is 0 if there is none
+func (d *DB) GetActiveMaintenanceWindow() (models.MaintenanceWindows, error) {
+	m := models.MaintenanceWindows{}
+	err := d.Where("ended_at = ?", 0).Order("id desc").Limit(1).Find(&m).Error
+	return m, err
+}
+
+/*
+  MetricSnapshots Queries
+*/
+
+// GetMetricSnapshots returns the last saved value of every counter series
+func (d *DB) GetMetricSnapshots() ([]models.MetricSnapshots, error) {
+	var result = []models.MetricSnapshots{}
+	err := d.Order("name asc, labels asc").Find(&result).Error
+	return result, err
+}
+
+/*
+  Reconciliations Queries
+*/
+
+// GetLatestReconciliation returns the last reconciliation run, with an ID of 0 if there has been none
+func (d *DB) GetLatestReconciliation() (models.Reconciliations, error) {
+	result := models.Reconciliations{}
+	err := d.Order("id desc").Limit(1).Find(&result).Error
+	return result, err
+}
+
+// GetReconciliations returns the last limit reconciliation runs, most recent first
+func (d *DB) GetReconciliations(limit int) ([]models.Reconciliations, error) {
+	var result = []models.Reconciliations{}
+	err := d.Order("id desc").Limit(limit).Find(&result).Error
+	return result, err
+}
+
+/*
+  StateSnapshots Queries
+*/
+
+// GetLatestStateSnapshot returns the last state snapshot saved, with an ID of 0 if there has been none
+func (d *DB) GetLatestStateSnapshot() (models.StateSnapshots, error) {
+	result := models.StateSnapshots{}
+	err := d.Order("id desc").Limit(1).Find(&result).Error
+	return result, err
+}
+
+/*
+  ChainEvents Queries
+*/
+
+// GetChainEvents returns the last limit events recorded for the chain.event_filters, most recent
+// first. If name is set, only that filter's events are returned
+func (d *DB) GetChainEvents(name string, limit int) ([]models.ChainEvents, error) {
+	var result = []models.ChainEvents{}
+	q := d.Order("block_number desc, log_index desc").Limit(limit)
+	if name != "" {
+		q = q.Where("name = ?", name)
+	}
+	err := q.Find(&result).Error
+	return result, err
+}
+
+/*
+  RequestSources Queries
+*/
+
+// GetRequestSources returns the source breakdown recorded for a request, in the order the
+// values were aggregated
+func (d *DB) GetRequestSources(requestId string) ([]models.RequestSources, error) {
+	var result = []models.RequestSources{}
+	err := d.Where("request_id = ?", requestId).Order("id asc").Find(&result).Error
+	return result, err
+}
+
+/*
+  AdapterCredentials Queries
+*/
+
+// GetAllAdapterCredentials returns every adapter credential, with stored values decrypted
+func (d *DB) GetAllAdapterCredentials() ([]models.AdapterCredentials, error) {
+	var result = []models.AdapterCredentials{}
+	err := d.Order("name asc").Find(&result).Error
+	if err != nil {
+		return result, err
+	}
+	for i := range result {
+		if result[i].Value, err = d.decrypt(result[i].Value); err != nil {
+			return result, fmt.Errorf("credential %s: %s", result[i].Name, err.Error())
+		}
+	}
+	return result, nil
+}
+
 /*
  VersionInfo queries
 */
@@ -144,3 +851,9 @@ func (d *DB) getCurrentDbSchemaVersion() (models.VersionInfo, error) {
 	err := d.Where("version_type = ?", models.VERSION_TYPE_DB_SCHEMA).First(&result).Error
 	return result, err
 }
+
+// GetDbSchemaVersion returns the schema version recorded in the database, without migrating it
+func (d *DB) GetDbSchemaVersion() (uint64, error) {
+	v, err := d.getCurrentDbSchemaVersion()
+	return v.CurrentVersion, err
+}
@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"go-ooo/database/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"time"
+)
+
+// MemoryStorage - database.storage value which keeps the sqlite database in memory, for tests
+// and throwaway dev nodes. Nothing is persisted once the node stops
+const MemoryStorage = ":memory:"
+
+// JobStore is the part of the database used by the request processing pipeline - ingesting
+// requests, fulfilling them and recording attestations, journal entries and VOR requests. It is
+// implemented by DB, so that the pipeline can be run against any database, such as one from
+// NewMemoryDb
+type JobStore interface {
+	GetLastBlockNumQueried() (models.ToBlocks, error)
+	InsertNewToBlock(toBlock uint64) error
+
+	FindByRequestId(requestId string) (models.DataRequests, error)
+	InsertNewRequest(provider string, consumer string, requestId string, endpoint string, endpointDecoded string,
+		txHash string, gasUsed uint64, gasPrice uint64, fee uint64, blockNumber uint64, isAdhoc bool) error
+	InsertNewRequests(requests []models.DataRequests) error
+	GetPendingJobs() ([]models.DataRequests, error)
+	GetStuckJobs(status int, before time.Time) ([]models.DataRequests, error)
+	GetFulfilledRequestsSince(since time.Time) ([]models.DataRequests, error)
+	GetRequestsReceivedBetween(from time.Time, to time.Time) ([]models.DataRequests, error)
+	CountUnsentJobs() (int64, error)
+	CountInFlightTxs() (int64, error)
+	UpdateRequestStatus(requestId string, status int, code string, reason string) error
+	UpdateRequestRetry(requestId string, status int, code string, reason string, nextRetryAt int64) error
+	UpdateRequestRejected(requestId string, code string, errorCode string, reason string) error
+	UpdateLastDataFetchBlockNumber(requestId string, blockNum uint64) error
+	UpdateDataFetched(requestId string, price string, rounding string, sourcePolicy string, sourceTier string) error
+	UpdateRepricedResult(requestId string, price string, rounding string, sourcePolicy string, sourceTier string) error
+	UpdateFulfillmentSent(requestId string, txHash string, blockNumber uint64) error
+	UpdateFulfillmentSuccess(requestId string, blockNumber uint64, txHash string, gasUsed uint64, gasPrice uint64) error
+	UpdateManualFulfillment(requestId string, price string, reason string) error
+	IncrementFulfillmentAttempts(requestId string) error
+	ResetRequestForReplay(requestId string) error
+	InsertNewFailedFulfilment(requestId string, txHash string, gasUsed uint64, gasPrice uint64, reason string) error
+
+	UpsertAttestation(requestId string, endpoint string, price string, timestamp int64,
+		sources string, signer string, messageHash string, signature string) error
+	GetAttestationByRequestId(requestId string) (models.Attestations, error)
+	GetAttestationsByRequestIds(requestIds []string) ([]models.Attestations, error)
+	UpdateAttestationCid(messageHash string, cid string) error
+
+	InsertJournalEntry(kind string, requestId string, txHash string, nonce uint64, rawTx string, blockNumber uint64, data string) error
+	GetUnresolvedTxIntents() ([]models.JournalEntries, error)
+	MarkTxIntentBroadcast(txHash string) error
+	ResolveTxIntent(txHash string) error
+
+	InsertNewVorRequest(requestId string, keyHash string, sender string, seed string, fee string,
+		blockNumber uint64, blockHash string, txHash string) error
+	FindVorRequestByRequestId(requestId string) (models.VorRequests, error)
+	GetPendingVorRequests() ([]models.VorRequests, error)
+	UpdateVorRequestStatus(requestId string, status int, reason string) error
+	UpdateVorFulfillmentSent(requestId string, txHash string, blockNumber uint64, randomness string) error
+	UpdateVorFulfillmentSuccess(requestId string, blockNumber uint64, txHash string, randomness string) error
+
+	GetConsumerMetadata(consumer string) (models.ConsumerMetadata, error)
+	GetPairMetadata(pair string) (models.PairMetadata, error)
+
+	GetMetricSnapshots() ([]models.MetricSnapshots, error)
+	SaveMetricSnapshots(snapshots []models.MetricSnapshots) error
+
+	SaveRequestSources(requestId string, sources []models.RequestSources) error
+
+	GetLatestReconciliation() (models.Reconciliations, error)
+	GetReconciliations(limit int) ([]models.Reconciliations, error)
+	InsertReconciliation(r models.Reconciliations) error
+
+	InsertChainEvent(e models.ChainEvents) error
+	DeleteChainEvent(txHash string, logIndex uint) error
+	GetChainEvents(name string, limit int) ([]models.ChainEvents, error)
+
+	InsertGasSpend(g models.GasSpends) error
+
+	GetLatestStateSnapshot() (models.StateSnapshots, error)
+	InsertStateSnapshot(s models.StateSnapshots) error
+	UpdateStateSnapshotVerified(id uint, verification string) error
+}
+
+// Store is the database as used by the rest of the node - the processing pipeline's JobStore,
+// plus the supported and DEX pairs the data sources use, and the stats, admin and audit queries
+// of the service. It is implemented by DB, and by mock.Store for tests which need no database
+type Store interface {
+	JobStore
+
+	CountPendingJobsForProvider(provider string) (int64, error)
+	GetDeadJobs(limit int) ([]models.DataRequests, error)
+	GetLastFulfilledForPair(base string, target string) (models.DataRequests, error)
+	GetLatestFulfilledPerEndpoint() ([]models.DataRequests, error)
+	SearchJobs(filter JobFilter) ([]models.DataRequests, error)
+	FindByTxHash(txHash string) (models.DataRequests, error)
+	GetFulfilledRequestsBetween(from time.Time, to time.Time) ([]models.DataRequests, error)
+	GetGasSpendsBetween(from time.Time, to time.Time) ([]models.GasSpends, error)
+	GetGasSpendsForRequest(requestId string) ([]models.GasSpends, error)
+	GetRecentAdhocEndpoints(since time.Time) ([]string, error)
+	GetLastXSuccessfulRequests(limit int, consumer string) ([]models.DataRequests, error)
+	GetMostGasUsed() (models.DataRequests, error)
+	GetLeastGasUsed() (models.DataRequests, error)
+	RequeueFailedRequest(requestId string) error
+	GetJournalEntriesForRequest(requestId string) ([]models.JournalEntries, error)
+	GetRequestSources(requestId string) ([]models.RequestSources, error)
+
+	CountSupportedPairs() (int64, error)
+	GetSupportedPairs() ([]models.SupportedPairs, error)
+	PairIsSupportedByPairName(pair string) (models.SupportedPairs, error)
+	PairIsSupportedByBaseAndTarget(base string, target string) (models.SupportedPairs, error)
+	PairsNoLongerSupported(pairs []string) ([]models.SupportedPairs, error)
+	GetRequestsForPairSince(base string, target string, since time.Time) ([]models.DataRequests, error)
+	GetPairDelistings(since time.Time) ([]models.PairDelistings, error)
+	GetUnnotifiedPairDelistings() ([]models.PairDelistings, error)
+	InsertPairDelistings(delistings []models.PairDelistings) error
+	MarkPairDelistingsNotified(ids []uint) error
+	GetPairChanges(filter PairChangeFilter) ([]models.PairChanges, error)
+	InsertPairChanges(changes []models.PairChanges) error
+	AddNewSupportedPair(name string, base string, target string) error
+	DeleteSupportedPair(pair models.SupportedPairs) error
+
+	FindByDexPairName(base string, target string, dexName string) (models.DexPairs, error)
+	FindDexPairByContract(contractAddress string, dexName string) (models.DexPairs, error)
+	GetDexPairsByDexName(dexName string) ([]models.DexPairs, error)
+	SyncDexPairs(dexName string, chain string, pairs []DexPairSync) error
+	GetDexSyncCursor(dexName string) (models.DexSyncCursors, error)
+	UpdateDexSyncCursor(dexName string, block uint64, full bool) error
+	GetSubgraphSpend(month string) ([]models.SubgraphSpend, error)
+	AddSubgraphSpend(dexName string, month string, queries uint64, cost float64) error
+	UpdateDexPairReserveUsd(contractAddress string, dexName string, reserveUsd float64) error
+	UpdateDexPairVolumeUsd(contractAddress string, dexName string, volumeUsd24h float64) error
+
+	InsertAuditLog(actor string, source string, remoteAddr string, action string, requestId string,
+		params string, success bool, errMsg string) error
+	SearchAuditLog(filter AuditFilter) ([]models.AuditLog, error)
+
+	GetActiveMaintenanceWindow() (models.MaintenanceWindows, error)
+	StartMaintenanceWindow(note string, startedBy string, until int64) (models.MaintenanceWindows, error)
+	EndMaintenanceWindow(endedBy string) error
+
+	GetAllConsumerMetadata() ([]models.ConsumerMetadata, error)
+	GetAllPairMetadata() ([]models.PairMetadata, error)
+	UpsertConsumerMetadata(consumer string, tags []string, note string) error
+	DeleteConsumerMetadata(consumer string) error
+	UpsertPairMetadata(pair string, tags []string, note string) error
+	DeletePairMetadata(pair string) error
+
+	GetAllAdapterCredentials() ([]models.AdapterCredentials, error)
+	UpsertAdapterCredential(name string, value string, ref string, rotateAfterDays uint64,
+		dailyQuota uint64) (models.AdapterCredentials, error)
+	AddAdapterCredentialUsage(name string, usageDay string, uses uint64, lastUsedAt int64) error
+	DeleteAdapterCredential(name string) error
+
+	GetDbSchemaVersion() (uint64, error)
+	Vacuum(ctx context.Context, tables []string) error
+	NewLeaderLock(key int64) (*LeaderLock, error)
+	Ping(ctx context.Context) error
+}
+
+var (
+	_ JobStore = (*DB)(nil)
+	_ Store    = (*DB)(nil)
+)
+
+// NewMemoryDb returns a migrated sqlite database held in memory, independent of the config, so
+// that tests can run the processing pipeline without a database server or files. Each call
+// returns a new, empty database
+func NewMemoryDb() (*DB, error) {
+	db, err := openSqlite(MemoryStorage, logger.Default.LogMode(logger.Silent))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = db.Migrate(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// openSqlite opens the sqlite database at storage. An in-memory database is private to the
+// connection it was opened on, so in-memory databases are limited to a single connection,
+// which every query shares
+func openSqlite(storage string, logger logger.Interface) (*DB, error) {
+	db, err := gorm.Open(sqlite.Open(storage), &gorm.Config{
+		Logger: logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if storage == MemoryStorage {
+		sqlDb, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDb.SetMaxOpenConns(1)
+		// the database is lost if its only connection is closed
+		sqlDb.SetConnMaxLifetime(0)
+		sqlDb.SetMaxIdleConns(1)
+	}
+
+	return &DB{DB: db}, nil
+}
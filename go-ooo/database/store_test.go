@@ -0,0 +1,54 @@
+package database
+
+import (
+	"go-ooo/database/models"
+	"testing"
+)
+
+func TestMemoryDbJobPipeline(t *testing.T) {
+	db, err := NewMemoryDb()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"0x01", "0x02"} {
+		err = db.InsertNewRequest("0xprovider", "0xconsumer", id, "0x4254432e5553442e5052", "BTC.USD.PR",
+			"0xrequesttx", 21000, 1, 100000000, 10, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	jobs, err := db.GetPendingJobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 || jobs[0].RequestId != "0x01" || jobs[1].RequestId != "0x02" {
+		t.Fatalf("pending jobs %+v, want 0x01 and 0x02 in order", jobs)
+	}
+
+	if err = db.UpdateFulfillmentSent("0x01", "0xfulfiltx", 11); err != nil {
+		t.Fatal(err)
+	}
+	req, err := db.FindByRequestId("0x01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.FulfillTxHash != "0xfulfiltx" || req.LastFulfillSentBlockNumber != 11 || req.TxSentAt == 0 {
+		t.Errorf("sent request has tx %q, block %d, sent at %d", req.FulfillTxHash, req.LastFulfillSentBlockNumber, req.TxSentAt)
+	}
+	if req.JobStatus != models.JOB_STATUS_PENDING {
+		t.Errorf("sent request's job status %d, want pending until fulfilled", req.JobStatus)
+	}
+
+	if err = db.UpdateFulfillmentSuccess("0x01", 12, "0xfulfiltx", 50000, 1); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err = db.GetPendingJobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].RequestId != "0x02" {
+		t.Errorf("pending jobs %+v after fulfilment, want only 0x02", jobs)
+	}
+}
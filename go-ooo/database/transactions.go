@@ -3,12 +3,20 @@ package database
 import (
 	"fmt"
 	"go-ooo/database/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"time"
 )
 
 /*
   DataRequests table
 */
 
+func nowMillis() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
 func (d *DB) InsertNewRequest(provider string,
 	consumer string, requestId string,
 	endpoint string, endpointDecoded string,
@@ -33,6 +41,19 @@ func (d *DB) InsertNewRequest(provider string,
 	return
 }
 
+// InsertNewRequests adds requests as new, pending jobs, in a single transaction
+func (d *DB) InsertNewRequests(requests []models.DataRequests) error {
+	if len(requests) == 0 {
+		return nil
+	}
+	for i := range requests {
+		requests[i].RequestStatus = models.REQUEST_STATUS_INITIALISED
+		requests[i].FulfillmentAttempts = 0
+		requests[i].JobStatus = models.JOB_STATUS_PENDING
+	}
+	return d.Omit("FulfilTx").CreateInBatches(requests, len(requests)).Error
+}
+
 func (d *DB) UpdateFulfillmentSuccess(requestId string, blockNumber uint64,
 	txHash string, gasUsed uint64, gasPrice uint64) error {
 
@@ -48,6 +69,10 @@ func (d *DB) UpdateFulfillmentSuccess(requestId string, blockNumber uint64,
 	req.FulfillTxHash = txHash
 	req.FulfillGasUsed = gasUsed
 	req.FulfillGasPrice = gasPrice
+	if req.TxMinedAt == 0 {
+		req.TxMinedAt = nowMillis()
+	}
+	req.ErrorCode = ""
 
 	err = d.Save(&req).Error
 
@@ -64,6 +89,7 @@ func (d *DB) UpdateFulfillmentSent(requestId string, txHash string, blockNumber
 
 	req.FulfillTxHash = txHash
 	req.LastFulfillSentBlockNumber = blockNumber
+	req.TxSentAt = nowMillis()
 
 	err = d.Save(&req).Error
 
@@ -84,7 +110,9 @@ func (d *DB) IncrementFulfillmentAttempts(requestId string) error {
 	return err
 }
 
-func (d *DB) UpdateRequestStatus(requestId string, status int, reason string) error {
+// UpdateRequestStatus sets a request's status, with the errcodes code of the failure it records,
+// or "" if it is not a failure
+func (d *DB) UpdateRequestStatus(requestId string, status int, code string, reason string) error {
 	req := models.DataRequests{}
 	err := d.Where("request_id = ?", requestId).First(&req).Error
 	if err != nil {
@@ -93,8 +121,9 @@ func (d *DB) UpdateRequestStatus(requestId string, status int, reason string) er
 
 	req.RequestStatus = status
 	req.StatusReason = reason
+	req.ErrorCode = code
 
-	if status == models.REQUEST_STATUS_FULFILMENT_FAILED {
+	if models.IsFailedFinalRequestStatus(status) {
 		req.JobStatus = models.JOB_STATUS_FAIL
 	}
 
@@ -103,6 +132,113 @@ func (d *DB) UpdateRequestStatus(requestId string, status int, reason string) er
 	return err
 }
 
+// UpdateRequestRejected marks a request as rejected at ingestion, with a machine readable code,
+// and the errcodes code of its class
+func (d *DB) UpdateRequestRejected(requestId string, code string, errorCode string, reason string) error {
+	req := models.DataRequests{}
+	err := d.Where("request_id = ?", requestId).First(&req).Error
+	if err != nil {
+		return err
+	}
+
+	req.RequestStatus = models.REQUEST_STATUS_REJECTED
+	req.JobStatus = models.JOB_STATUS_FAIL
+	req.RejectionCode = code
+	req.ErrorCode = errorCode
+	req.StatusReason = reason
+
+	err = d.Save(&req).Error
+
+	return err
+}
+
+// UpdateManualFulfillment sets an operator supplied value for a request, ready to be sent
+func (d *DB) UpdateManualFulfillment(requestId string, price string, reason string) error {
+	req := models.DataRequests{}
+	err := d.Where("request_id = ?", requestId).First(&req).Error
+	if err != nil {
+		return err
+	}
+
+	req.RequestStatus = models.REQUEST_STATUS_DATA_READY_TO_SEND
+	req.JobStatus = models.JOB_STATUS_PENDING
+	req.PriceResult = price
+	// supplied as the scaled integer, so no rounding was applied by the node
+	req.AnswerRounding = ""
+	req.SourcePolicy = ""
+	req.SourceTier = ""
+	req.StatusReason = reason
+	req.ErrorCode = ""
+	req.NextRetryAt = 0
+
+	err = d.Save(&req).Error
+
+	return err
+}
+
+// UpdateRequestRetry sets a failed request's status, the errcodes code of the failure, and the
+// earliest time it can be retried
+func (d *DB) UpdateRequestRetry(requestId string, status int, code string, reason string, nextRetryAt int64) error {
+	req := models.DataRequests{}
+	err := d.Where("request_id = ?", requestId).First(&req).Error
+	if err != nil {
+		return err
+	}
+
+	req.RequestStatus = status
+	req.StatusReason = reason
+	req.ErrorCode = code
+	req.NextRetryAt = nextRetryAt
+
+	err = d.Save(&req).Error
+
+	return err
+}
+
+// RequeueFailedRequest resets a failed, e.g. dead lettered, request so that it is processed again from scratch
+func (d *DB) RequeueFailedRequest(requestId string) error {
+	req := models.DataRequests{}
+	err := d.Where("request_id = ? AND job_status = ?", requestId, models.JOB_STATUS_FAIL).First(&req).Error
+	if err != nil {
+		return err
+	}
+
+	req.RequestStatus = models.REQUEST_STATUS_INITIALISED
+	req.JobStatus = models.JOB_STATUS_PENDING
+	req.StatusReason = ""
+	req.RejectionCode = ""
+	req.ErrorCode = ""
+	req.FulfillmentAttempts = 0
+	req.NextRetryAt = 0
+
+	err = d.Save(&req).Error
+
+	return err
+}
+
+// ResetRequestForReplay returns a request to the job queue, e.g. when a replay finds it is
+// recorded as fulfilled but is still pending on chain
+func (d *DB) ResetRequestForReplay(requestId string) error {
+	req := models.DataRequests{}
+	err := d.Where("request_id = ?", requestId).First(&req).Error
+	if err != nil {
+		return err
+	}
+
+	req.RequestStatus = models.REQUEST_STATUS_INITIALISED
+	req.JobStatus = models.JOB_STATUS_PENDING
+	req.StatusReason = "reset by replay"
+	req.ErrorCode = ""
+	req.FulfillmentAttempts = 0
+	req.NextRetryAt = 0
+	req.FulfillConfirmedBlockNumber = 0
+	req.TxMinedAt = 0
+
+	err = d.Save(&req).Error
+
+	return err
+}
+
 func (d *DB) UpdateJobStatus(requestId string, status int) error {
 	req := models.DataRequests{}
 	err := d.Where("request_id = ?", requestId).First(&req).Error
@@ -117,7 +253,9 @@ func (d *DB) UpdateJobStatus(requestId string, status int) error {
 	return err
 }
 
-func (d *DB) UpdateDataFetched(requestId string, price string) error {
+// UpdateDataFetched sets the request's price result, scaled with the rounding mode and aggregated
+// with the source policy from the source tier, ready to be sent
+func (d *DB) UpdateDataFetched(requestId string, price string, rounding string, sourcePolicy string, sourceTier string) error {
 	req := models.DataRequests{}
 	err := d.Where("request_id = ?", requestId).First(&req).Error
 	if err != nil {
@@ -126,6 +264,30 @@ func (d *DB) UpdateDataFetched(requestId string, price string) error {
 
 	req.RequestStatus = models.REQUEST_STATUS_DATA_READY_TO_SEND
 	req.PriceResult = price
+	req.AnswerRounding = rounding
+	req.SourcePolicy = sourcePolicy
+	req.SourceTier = sourceTier
+	req.DataFetchedAt = nowMillis()
+
+	err = d.Save(&req).Error
+
+	return err
+}
+
+// UpdateRepricedResult replaces the price of a request whose fulfillment tx is being replaced,
+// leaving its status as it is
+func (d *DB) UpdateRepricedResult(requestId string, price string, rounding string, sourcePolicy string, sourceTier string) error {
+	req := models.DataRequests{}
+	err := d.Where("request_id = ?", requestId).First(&req).Error
+	if err != nil {
+		return err
+	}
+
+	req.PriceResult = price
+	req.AnswerRounding = rounding
+	req.SourcePolicy = sourcePolicy
+	req.SourceTier = sourceTier
+	req.DataFetchedAt = nowMillis()
 
 	err = d.Save(&req).Error
 
@@ -176,6 +338,11 @@ func (d *DB) AddNewSupportedPair(name string, base string, target string) (err e
 	return
 }
 
+// DeleteSupportedPair permanently deletes the pair
+func (d *DB) DeleteSupportedPair(pair models.SupportedPairs) error {
+	return d.Unscoped().Delete(&pair).Error
+}
+
 /*
   FailedFulfillments table
 */
@@ -255,6 +422,194 @@ func (d *DB) InsertNewDexPair(t0Symbol string, t1Symbol string,
 	return data, err
 }
 
+// DexPairSync a pair found by DEX subgraph discovery, with its tokens
+type DexPairSync struct {
+	T0Symbol        string
+	T0Address       string
+	T1Symbol        string
+	T1Address       string
+	ContractAddress string
+	ReserveUsd      float64
+}
+
+// syncBatchSize - rows per INSERT, and values per IN clause, when syncing DEX pairs
+const syncBatchSize = 100
+
+// SyncDexPairs adds the pairs discovered on dexName, and their tokens, which are not yet in the
+// db, in a single transaction. Existing rows are looked up and new rows inserted in batches,
+// rather than one query per row. A pair contract already stored for the dex is left unchanged.
+// Pairs of different contracts with the same symbols, e.g. of a token and an impostor sharing its
+// symbol, are each stored, and told apart by FindByDexPairName
+func (d *DB) SyncDexPairs(dexName string, chain string, pairs []DexPairSync) error {
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	return d.Transaction(func(tx *gorm.DB) error {
+		// token contracts, keyed by symbol and address
+		contracts := make(map[string]*models.TokenContracts)
+		var contractOrder []string
+		var addresses []string
+		for _, p := range pairs {
+			for _, t := range [][2]string{{p.T0Symbol, p.T0Address}, {p.T1Symbol, p.T1Address}} {
+				key := t[0] + "/" + t[1]
+				if _, ok := contracts[key]; !ok {
+					contracts[key] = &models.TokenContracts{TokenSymbol: t[0], ContractAddress: t[1], Chain: chain}
+					contractOrder = append(contractOrder, key)
+					addresses = append(addresses, t[1])
+				}
+			}
+		}
+
+		for _, batch := range stringBatches(addresses) {
+			var existing []models.TokenContracts
+			if err := tx.Where("chain = ? AND contract_address IN ?", chain, batch).Order("id asc").Find(&existing).Error; err != nil {
+				return err
+			}
+			for i := range existing {
+				key := existing[i].TokenSymbol + "/" + existing[i].ContractAddress
+				if c, ok := contracts[key]; ok && c.ID == 0 {
+					*c = existing[i]
+				}
+			}
+		}
+
+		var newContracts []*models.TokenContracts
+		for _, key := range contractOrder {
+			if contracts[key].ID == 0 {
+				newContracts = append(newContracts, contracts[key])
+			}
+		}
+		if len(newContracts) > 0 {
+			if err := tx.CreateInBatches(newContracts, syncBatchSize).Error; err != nil {
+				return err
+			}
+		}
+
+		// dex tokens, keyed by symbol and token contract id
+		tokens := make(map[string]*models.DexTokens)
+		var tokenOrder []string
+		var contractIds []uint
+		for _, p := range pairs {
+			for _, t := range [][2]string{{p.T0Symbol, p.T0Address}, {p.T1Symbol, p.T1Address}} {
+				c := contracts[t[0]+"/"+t[1]]
+				key := fmt.Sprintf("%s/%d", t[0], c.ID)
+				if _, ok := tokens[key]; !ok {
+					tokens[key] = &models.DexTokens{DexName: dexName, TokenSymbol: t[0], TokenContractsId: c.ID, Chain: chain}
+					tokenOrder = append(tokenOrder, key)
+					contractIds = append(contractIds, c.ID)
+				}
+			}
+		}
+
+		for start := 0; start < len(contractIds); start += syncBatchSize {
+			end := start + syncBatchSize
+			if end > len(contractIds) {
+				end = len(contractIds)
+			}
+			var existing []models.DexTokens
+			if err := tx.Where("dex_name = ? AND token_contracts_id IN ?", dexName, contractIds[start:end]).
+				Order("id asc").Find(&existing).Error; err != nil {
+				return err
+			}
+			for i := range existing {
+				key := fmt.Sprintf("%s/%d", existing[i].TokenSymbol, existing[i].TokenContractsId)
+				if t, ok := tokens[key]; ok && t.ID == 0 {
+					*t = existing[i]
+				}
+			}
+		}
+
+		tokenId := func(symbol string, address string) uint {
+			return tokens[fmt.Sprintf("%s/%d", symbol, contracts[symbol+"/"+address].ID)].ID
+		}
+
+		var newTokens []*models.DexTokens
+		for _, key := range tokenOrder {
+			if tokens[key].ID == 0 {
+				newTokens = append(newTokens, tokens[key])
+			}
+		}
+		if len(newTokens) > 0 {
+			if err := tx.CreateInBatches(newTokens, syncBatchSize).Error; err != nil {
+				return err
+			}
+		}
+
+		// dex pairs, keyed by pair contract
+		var pairContracts []string
+		for _, p := range pairs {
+			pairContracts = append(pairContracts, p.ContractAddress)
+		}
+
+		stored := make(map[string]bool)
+		for _, batch := range stringBatches(pairContracts) {
+			var existing []string
+			if err := tx.Model(&models.DexPairs{}).Where("dex_name = ? AND contract_address IN ?", dexName, batch).
+				Pluck("contract_address", &existing).Error; err != nil {
+				return err
+			}
+			for _, address := range existing {
+				stored[address] = true
+			}
+		}
+
+		var newPairs []models.DexPairs
+		for _, p := range pairs {
+			name := fmt.Sprintf("%s-%s", p.T0Symbol, p.T1Symbol)
+			if stored[p.ContractAddress] {
+				continue
+			}
+			stored[p.ContractAddress] = true
+
+			newPairs = append(newPairs, models.DexPairs{
+				DexName:         dexName,
+				Pair:            name,
+				T0DexTokenId:    tokenId(p.T0Symbol, p.T0Address),
+				T1DexTokenId:    tokenId(p.T1Symbol, p.T1Address),
+				T0Symbol:        p.T0Symbol,
+				T1Symbol:        p.T1Symbol,
+				ContractAddress: p.ContractAddress,
+				ReserveUsd:      p.ReserveUsd,
+			})
+		}
+		if len(newPairs) > 0 {
+			if err := tx.CreateInBatches(newPairs, syncBatchSize).Error; err != nil {
+				return err
+			}
+
+			// recorded with the pairs, so that the changelog has every pair stored
+			changes := make([]models.PairChanges, 0, len(newPairs))
+			for _, p := range newPairs {
+				changes = append(changes, models.PairChanges{
+					Pair:   p.Pair,
+					Change: models.PAIR_CHANGE_ADDED,
+					Source: dexName,
+					Reason: fmt.Sprintf("pair contract %s added", p.ContractAddress),
+				})
+			}
+			if err := tx.CreateInBatches(changes, syncBatchSize).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// stringBatches splits values into batches of up to syncBatchSize
+func stringBatches(values []string) [][]string {
+	var batches [][]string
+	for start := 0; start < len(values); start += syncBatchSize {
+		end := start + syncBatchSize
+		if end > len(values) {
+			end = len(values)
+		}
+		batches = append(batches, values[start:end])
+	}
+	return batches
+}
+
 func (d *DB) UpdateOrInsertNewDexPair(t0Symbol string, t1Symbol string,
 	contractAddress string, dexName string, t0DbId uint, t1DbId uint) (err error) {
 
@@ -284,6 +639,22 @@ func (d *DB) UpdateOrInsertNewDexPair(t0Symbol string, t1Symbol string,
 	return
 }
 
+func (d *DB) UpdateDexPairReserveUsd(contractAddress string, dexName string, reserveUsd float64) error {
+	return d.Model(&models.DexPairs{}).
+		Where("contract_address = ? AND dex_name = ?", contractAddress, dexName).
+		Update("reserve_usd", reserveUsd).Error
+}
+
+// UpdateDexPairVolumeUsd sets a pair's 24 hour trade volume, as of now
+func (d *DB) UpdateDexPairVolumeUsd(contractAddress string, dexName string, volumeUsd24h float64) error {
+	return d.Model(&models.DexPairs{}).
+		Where("contract_address = ? AND dex_name = ?", contractAddress, dexName).
+		Updates(map[string]interface{}{
+			"volume_usd_24h":    volumeUsd24h,
+			"volume_updated_at": time.Now().Unix(),
+		}).Error
+}
+
 /*
   TokenContracts
 */
@@ -320,6 +691,142 @@ func (d *DB) InsertNewTokenContract(symbol string, contractAddress string, chain
 	return data, err
 }
 
+/*
+  Attestations
+*/
+
+// UpsertAttestation stores the attestation for a request. A request may be re-fetched after
+// a failure, so any previous attestation for the request is replaced by the latest answer
+func (d *DB) UpsertAttestation(requestId string, endpoint string, price string, timestamp int64,
+	sources string, signer string, messageHash string, signature string) error {
+
+	att := models.Attestations{}
+	_ = d.Where("request_id = ?", requestId).First(&att).Error
+
+	att.RequestId = requestId
+	att.Endpoint = endpoint
+	att.Price = price
+	att.Timestamp = timestamp
+	att.Sources = sources
+	att.Signer = signer
+	att.MessageHash = messageHash
+	att.Signature = signature
+	// the replaced attestation's CID is not that of this one
+	att.IpfsCid = ""
+
+	return d.Save(&att).Error
+}
+
+// UpdateAttestationCid records the IPFS CID of the attestation with messageHash. If the
+// attestation has since been replaced, nothing is updated
+func (d *DB) UpdateAttestationCid(messageHash string, cid string) error {
+	return d.Model(&models.Attestations{}).Where("message_hash = ?", messageHash).Update("ipfs_cid", cid).Error
+}
+
+/*
+  VorRequests table
+*/
+
+func (d *DB) InsertNewVorRequest(requestId string, keyHash string, sender string, seed string, fee string,
+	blockNumber uint64, blockHash string, txHash string) error {
+	return d.Create(&models.VorRequests{
+		RequestId:           requestId,
+		KeyHash:             keyHash,
+		Sender:              sender,
+		Seed:                seed,
+		Fee:                 fee,
+		RequestBlockNumber:  blockNumber,
+		RequestBlockHash:    blockHash,
+		RequestTxHash:       txHash,
+		RequestStatus:       models.REQUEST_STATUS_INITIALISED,
+		FulfillmentAttempts: 0,
+		JobStatus:           models.JOB_STATUS_PENDING,
+	}).Error
+}
+
+func (d *DB) UpdateVorFulfillmentSent(requestId string, txHash string, blockNumber uint64, randomness string) error {
+	req := models.VorRequests{}
+	err := d.Where("request_id = ?", requestId).First(&req).Error
+	if err != nil {
+		return err
+	}
+
+	req.RequestStatus = models.REQUEST_STATUS_TX_SENT
+	req.StatusReason = ""
+	req.FulfillTxHash = txHash
+	req.LastFulfillSentBlockNumber = blockNumber
+	req.Randomness = randomness
+	req.FulfillmentAttempts = req.FulfillmentAttempts + 1
+
+	return d.Save(&req).Error
+}
+
+func (d *DB) UpdateVorFulfillmentSuccess(requestId string, blockNumber uint64, txHash string, randomness string) error {
+	req := models.VorRequests{}
+	err := d.Where("request_id = ?", requestId).First(&req).Error
+	if err != nil {
+		return err
+	}
+
+	req.RequestStatus = models.REQUEST_STATUS_SUCCESS
+	req.JobStatus = models.JOB_STATUS_SUCCESS
+	req.FulfillConfirmedBlockNumber = blockNumber
+	req.FulfillTxHash = txHash
+	req.Randomness = randomness
+
+	return d.Save(&req).Error
+}
+
+func (d *DB) UpdateVorRequestStatus(requestId string, status int, reason string) error {
+	req := models.VorRequests{}
+	err := d.Where("request_id = ?", requestId).First(&req).Error
+	if err != nil {
+		return err
+	}
+
+	req.RequestStatus = status
+	req.StatusReason = reason
+
+	if status == models.REQUEST_STATUS_FULFILMENT_FAILED {
+		req.JobStatus = models.JOB_STATUS_FAIL
+	}
+
+	return d.Save(&req).Error
+}
+
+/*
+  JournalEntries table
+*/
+
+func (d *DB) InsertJournalEntry(kind string, requestId string, txHash string, nonce uint64,
+	rawTx string, blockNumber uint64, data string) error {
+	return d.Create(&models.JournalEntries{
+		Kind:        kind,
+		RequestId:   requestId,
+		TxHash:      txHash,
+		Nonce:       nonce,
+		RawTx:       rawTx,
+		BlockNumber: blockNumber,
+		Data:        data,
+		Resolved:    kind != models.JOURNAL_KIND_TX_INTENT,
+	}).Error
+}
+
+// MarkTxIntentBroadcast records that a tx intent's tx has been sent. The intent stays unresolved
+// until the request has been recorded as sent
+func (d *DB) MarkTxIntentBroadcast(txHash string) error {
+	return d.Model(&models.JournalEntries{}).
+		Where("kind = ? AND tx_hash = ?", models.JOURNAL_KIND_TX_INTENT, txHash).
+		Update("broadcast", true).Error
+}
+
+// ResolveTxIntent marks a tx intent as resolved, once the outcome of the broadcast is known
+func (d *DB) ResolveTxIntent(txHash string) error {
+	return d.Model(&models.JournalEntries{}).
+		Where("kind = ? AND tx_hash = ?", models.JOURNAL_KIND_TX_INTENT, txHash).
+		Update("resolved", true).Error
+}
+
 /*
  VersionInfo
 */
@@ -338,3 +845,340 @@ func (d *DB) setDbSchemaVersion(newVersion uint64) error {
 
 	return err
 }
+
+/*
+  AuditLog table
+*/
+
+func (d *DB) InsertAuditLog(actor string, source string, remoteAddr string, action string, requestId string,
+	params string, success bool, errMsg string) error {
+	params, err := d.encrypt(params)
+	if err != nil {
+		return err
+	}
+	return d.Create(&models.AuditLog{
+		Actor:      actor,
+		Source:     source,
+		RemoteAddr: remoteAddr,
+		Action:     action,
+		RequestId:  requestId,
+		Params:     params,
+		Success:    success,
+		Error:      errMsg,
+	}).Error
+}
+
+/*
+  ConsumerMetadata and PairMetadata tables
+*/
+
+// UpsertConsumerMetadata sets the tags and note for a consumer, replacing any already set
+func (d *DB) UpsertConsumerMetadata(consumer string, tags []string, note string) error {
+	m := models.ConsumerMetadata{}
+	err := d.Where("consumer = ?", consumer).Limit(1).Find(&m).Error
+	if err != nil {
+		return err
+	}
+	m.Consumer = consumer
+	m.Tags = strings.Join(tags, ",")
+	if m.Note, err = d.encrypt(note); err != nil {
+		return err
+	}
+	return d.Save(&m).Error
+}
+
+// DeleteConsumerMetadata permanently deletes the tags and note for a consumer
+func (d *DB) DeleteConsumerMetadata(consumer string) error {
+	return d.Unscoped().Where("consumer = ?", consumer).Delete(&models.ConsumerMetadata{}).Error
+}
+
+// UpsertPairMetadata sets the tags and note for a pair, replacing any already set
+func (d *DB) UpsertPairMetadata(pair string, tags []string, note string) error {
+	m := models.PairMetadata{}
+	err := d.Where("pair = ?", pair).Limit(1).Find(&m).Error
+	if err != nil {
+		return err
+	}
+	m.Pair = pair
+	m.Tags = strings.Join(tags, ",")
+	if m.Note, err = d.encrypt(note); err != nil {
+		return err
+	}
+	return d.Save(&m).Error
+}
+
+// DeletePairMetadata permanently deletes the tags and note for a pair
+func (d *DB) DeletePairMetadata(pair string) error {
+	return d.Unscoped().Where("pair = ?", pair).Delete(&models.PairMetadata{}).Error
+}
+
+/*
+  MaintenanceWindows table
+*/
+
+// StartMaintenanceWindow ends any active maintenance window, and starts a new one lasting until
+// the unix timestamp until, or until it is ended if until is 0
+func (d *DB) StartMaintenanceWindow(note string, startedBy string, until int64) (models.MaintenanceWindows, error) {
+	m := models.MaintenanceWindows{
+		Note:      note,
+		StartedBy: startedBy,
+		Until:     until,
+	}
+
+	err := d.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.MaintenanceWindows{}).Where("ended_at = ?", 0).
+			Updates(map[string]interface{}{"ended_at": time.Now().Unix(), "ended_by": startedBy}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&m).Error
+	})
+
+	return m, err
+}
+
+// EndMaintenanceWindow ends the active maintenance window, if there is one
+func (d *DB) EndMaintenanceWindow(endedBy string) error {
+	return d.Model(&models.MaintenanceWindows{}).Where("ended_at = ?", 0).
+		Updates(map[string]interface{}{"ended_at": time.Now().Unix(), "ended_by": endedBy}).Error
+}
+
+/*
+  MetricSnapshots table
+*/
+
+// SaveMetricSnapshots saves the value of each counter series, replacing any saved before
+func (d *DB) SaveMetricSnapshots(snapshots []models.MetricSnapshots) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	return d.Transaction(func(tx *gorm.DB) error {
+		for _, s := range snapshots {
+			m := models.MetricSnapshots{}
+			if err := tx.Where("name = ? AND labels = ?", s.Name, s.Labels).Limit(1).Find(&m).Error; err != nil {
+				return err
+			}
+			m.Name = s.Name
+			m.Labels = s.Labels
+			m.Value = s.Value
+			if err := tx.Save(&m).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SaveRequestSources replaces the source breakdown recorded for a request, e.g. from an earlier
+// fetch which was retried
+func (d *DB) SaveRequestSources(requestId string, sources []models.RequestSources) error {
+	return d.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("request_id = ?", requestId).Delete(&models.RequestSources{}).Error; err != nil {
+			return err
+		}
+		for i := range sources {
+			sources[i].RequestId = requestId
+		}
+		if len(sources) == 0 {
+			return nil
+		}
+		return tx.Create(&sources).Error
+	})
+}
+
+/*
+  AdapterCredentials table
+*/
+
+// UpsertAdapterCredential sets a credential's value or external reference, and its rotation and
+// quota settings. Changing the value or reference rotates the credential, incrementing its version
+func (d *DB) UpsertAdapterCredential(name string, value string, ref string, rotateAfterDays uint64,
+	dailyQuota uint64) (models.AdapterCredentials, error) {
+	m := models.AdapterCredentials{}
+	err := d.Where("name = ?", name).Limit(1).Find(&m).Error
+	if err != nil {
+		return m, err
+	}
+
+	current, err := d.decrypt(m.Value)
+	if err != nil {
+		return m, err
+	}
+	if m.ID == 0 || current != value || m.Ref != ref {
+		m.Version++
+		m.RotatedAt = time.Now().Unix()
+	}
+
+	m.Name = name
+	m.Ref = ref
+	m.RotateAfterDays = rotateAfterDays
+	m.DailyQuota = dailyQuota
+	if m.Value, err = d.encrypt(value); err != nil {
+		return m, err
+	}
+	if err = d.Save(&m).Error; err != nil {
+		return m, err
+	}
+
+	m.Value = value
+	return m, nil
+}
+
+// AddAdapterCredentialUsage adds uses of a credential on usageDay, a UTC yyyy-mm-dd date, to
+// its counters. Uses are added, rather than set, so that each instance of an HA pair can record
+// its own
+func (d *DB) AddAdapterCredentialUsage(name string, usageDay string, uses uint64, lastUsedAt int64) error {
+	return d.Transaction(func(tx *gorm.DB) error {
+		m := models.AdapterCredentials{}
+		if err := tx.Where("name = ?", name).Limit(1).Find(&m).Error; err != nil {
+			return err
+		}
+		if m.ID == 0 {
+			// deleted since it was used
+			return nil
+		}
+		if m.UsageDay != usageDay {
+			m.UsageDay = usageDay
+			m.UsedToday = 0
+		}
+		updates := map[string]interface{}{
+			"usage_day":  m.UsageDay,
+			"used_today": m.UsedToday + uses,
+			"total_uses": m.TotalUses + uses,
+		}
+		if lastUsedAt > m.LastUsedAt {
+			updates["last_used_at"] = lastUsedAt
+		}
+		return tx.Model(&m).UpdateColumns(updates).Error
+	})
+}
+
+// DeleteAdapterCredential permanently deletes a credential
+func (d *DB) DeleteAdapterCredential(name string) error {
+	return d.Unscoped().Where("name = ?", name).Delete(&models.AdapterCredentials{}).Error
+}
+
+/*
+  Reconciliations table
+*/
+
+// InsertReconciliation records a reconciliation run
+func (d *DB) InsertReconciliation(r models.Reconciliations) error {
+	return d.Create(&r).Error
+}
+
+/*
+  StateSnapshots table
+*/
+
+// InsertStateSnapshot records the node's state on shutdown
+func (d *DB) InsertStateSnapshot(s models.StateSnapshots) error {
+	return d.Create(&s).Error
+}
+
+// UpdateStateSnapshotVerified records the verification of a state snapshot on startup
+func (d *DB) UpdateStateSnapshotVerified(id uint, verification string) error {
+	return d.Model(&models.StateSnapshots{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"verified_at":  time.Now().Unix(),
+		"verification": verification,
+	}).Error
+}
+
+/*
+  ChainEvents table
+*/
+
+// InsertChainEvent records an event matching a chain.event_filters filter. Events already recorded,
+// e.g. seen by both the subscription and a backfill, are ignored
+func (d *DB) InsertChainEvent(e models.ChainEvents) error {
+	return d.Clauses(clause.OnConflict{DoNothing: true}).Create(&e).Error
+}
+
+// DeleteChainEvent permanently deletes an event, once its log has been removed by a reorg
+func (d *DB) DeleteChainEvent(txHash string, logIndex uint) error {
+	return d.Unscoped().Where("tx_hash = ? AND log_index = ?", txHash, logIndex).Delete(&models.ChainEvents{}).Error
+}
+
+/*
+  GasSpends table
+*/
+
+// InsertGasSpend records a fulfillment tx's gas spend. A tx already recorded, e.g. because its
+// fulfillment event was recovered, is ignored
+func (d *DB) InsertGasSpend(g models.GasSpends) error {
+	return d.Clauses(clause.OnConflict{DoNothing: true}).Create(&g).Error
+}
+
+/*
+  DexSyncCursors table
+*/
+
+// UpdateDexSyncCursor records that dexName's pairs have been synced up to the subgraph block, with
+// every pair fetched if full is true
+func (d *DB) UpdateDexSyncCursor(dexName string, block uint64, full bool) error {
+	c := models.DexSyncCursors{}
+	err := d.Where("dex_name = ?", dexName).Limit(1).Find(&c).Error
+	if err != nil {
+		return err
+	}
+	c.DexName = dexName
+	c.Block = block
+	if full {
+		c.FullSyncAt = time.Now().Unix()
+	}
+	return d.Save(&c).Error
+}
+
+/*
+  PairDelistings table
+*/
+
+// InsertPairDelistings records the consumers of a delisted pair
+func (d *DB) InsertPairDelistings(delistings []models.PairDelistings) error {
+	if len(delistings) == 0 {
+		return nil
+	}
+	return d.Create(&delistings).Error
+}
+
+// MarkPairDelistingsNotified records that the delistings with ids have been alerted
+func (d *DB) MarkPairDelistingsNotified(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return d.Model(&models.PairDelistings{}).Where("id IN ?", ids).Update("notified", true).Error
+}
+
+/*
+  PairChanges table
+*/
+
+// InsertPairChanges records changes to the pairs in the changelog
+func (d *DB) InsertPairChanges(changes []models.PairChanges) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return d.CreateInBatches(&changes, syncBatchSize).Error
+}
+
+/*
+  SubgraphSpend table
+*/
+
+// AddSubgraphSpend adds queries to dexName's subgraph, costing cost in total, to its spend in
+// month, a UTC yyyy-mm month. Spend is added, rather than set, so that each instance of an HA
+// pair can record its own
+func (d *DB) AddSubgraphSpend(dexName string, month string, queries uint64, cost float64) error {
+	return d.Transaction(func(tx *gorm.DB) error {
+		s := models.SubgraphSpend{}
+		if err := tx.Where("dex_name = ? AND month = ?", dexName, month).Limit(1).Find(&s).Error; err != nil {
+			return err
+		}
+		s.DexName = dexName
+		s.Month = month
+		s.Queries += queries
+		s.Cost += cost
+		return tx.Save(&s).Error
+	})
+}
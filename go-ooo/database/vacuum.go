@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultMaintenanceTables - tables vacuumed and analysed by the scheduled maintenance, if
+// database.maintenance_tables is not set. Rows in them are updated or added for every request
+var DefaultMaintenanceTables = []string{"data_requests", "journal_entries", "request_sources", "attestations",
+	"chain_events", "audit_log"}
+
+// MaintenanceTables returns database.maintenance_tables, or DefaultMaintenanceTables if it is not set
+func MaintenanceTables() []string {
+	if !viper.IsSet(config.DatabaseMaintenanceTables) {
+		return DefaultMaintenanceTables
+	}
+	return viper.GetStringSlice(config.DatabaseMaintenanceTables)
+}
+
+// Vacuum reclaims the space left by updated and deleted rows in tables, and refreshes the
+// statistics the query planner uses for them. On Postgres each table is vacuumed and analysed in
+// turn. Sqlite can only vacuum the whole database, which is done once before the tables are
+// analysed. Neither runs in a transaction, and sqlite's vacuum holds a write lock while it runs
+func (d *DB) Vacuum(ctx context.Context, tables []string) error {
+	db := d.WithContext(ctx)
+
+	if viper.GetString(config.DatabaseDialect) == "postgres" {
+		for _, table := range tables {
+			if err := db.Exec("VACUUM (ANALYZE) ?", clause.Table{Name: table}).Error; err != nil {
+				return fmt.Errorf("cannot vacuum %s: %s", table, err.Error())
+			}
+		}
+		return nil
+	}
+
+	if err := db.Exec("VACUUM").Error; err != nil {
+		return fmt.Errorf("cannot vacuum: %s", err.Error())
+	}
+	for _, table := range tables {
+		if err := db.Exec("ANALYZE ?", clause.Table{Name: table}).Error; err != nil {
+			return fmt.Errorf("cannot analyze %s: %s", table, err.Error())
+		}
+	}
+	return db.Exec("PRAGMA optimize").Error
+}
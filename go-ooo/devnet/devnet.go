@@ -0,0 +1,382 @@
+// Package devnet runs a local chain with the Router, a mock xFUND token and a mock consumer
+// deployed, and drives requests through it, so that the node can be tested end to end without
+// a public network
+package devnet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"go-ooo/ooo_router"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// AnvilKey is the private key of anvil's first default account. It is the default faucet key,
+// and funds the in-process simulated chain, so that contract addresses are the same on every fresh devnet
+const AnvilKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+// TokenDecimals - the mock xFUND token has the same decimals as xFUND
+const TokenDecimals = 9
+
+// txTimeout - how long to wait for a devnet tx to be mined
+const txTimeout = time.Minute
+
+// gasLimit - the in-process chain's block gas limit
+const gasLimit = 30000000
+
+// simulatedChainId - the in-process chain's id. The simulated backend always uses 1337
+var simulatedChainId = big.NewInt(1337)
+
+// pollInterval - how often WaitFulfilled checks for a fulfillment
+const pollInterval = time.Second
+
+// Config configures a devnet
+type Config struct {
+	// RpcUrl is an external dev chain to use, e.g. anvil. If empty, an in-process chain is started
+	RpcUrl string
+	// FaucetKey is the private key deploying and paying for requests. Defaults to AnvilKey
+	FaucetKey string
+	// Host, WsPort and HttpPort are where the in-process chain serves its RPCs
+	Host     string
+	WsPort   int
+	HttpPort int
+	// BlockTime is the in-process chain's block time in seconds. 0 mines a block for each tx
+	BlockTime uint64
+}
+
+// Devnet is a running local chain. Contract addresses are set by Deploy
+type Devnet struct {
+	WsUrl   string
+	HttpUrl string
+	ChainId *big.Int
+	Client  *ethclient.Client
+
+	Token    common.Address
+	Router   common.Address
+	Consumer common.Address
+
+	faucet   *ecdsa.PrivateKey
+	stop     func()
+	token    *MockToken
+	router   *ooo_router.OooRouter
+	consumer *MockConsumer
+}
+
+// Start starts an in-process chain, or connects to cfg.RpcUrl
+func Start(ctx context.Context, cfg Config) (*Devnet, error) {
+	key := cfg.FaucetKey
+	if key == "" {
+		key = AnvilKey
+	}
+	faucet, err := crypto.HexToECDSA(strings.TrimPrefix(key, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid faucet key: %s", err.Error())
+	}
+
+	d := &Devnet{faucet: faucet}
+
+	if cfg.RpcUrl != "" {
+		d.WsUrl = cfg.RpcUrl
+		d.HttpUrl = cfg.RpcUrl
+	} else if err := d.startSimulated(cfg); err != nil {
+		return nil, err
+	}
+
+	client, err := ethclient.DialContext(ctx, d.WsUrl)
+	if err != nil {
+		d.Close()
+		return nil, err
+	}
+	d.Client = client
+
+	if d.ChainId, err = client.ChainID(ctx); err != nil {
+		d.Close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// startSimulated starts a simulated chain with the faucet holding the genesis allocation, and
+// serves it over RPC
+func (d *Devnet) startSimulated(cfg Config) error {
+	balance := new(big.Int).Mul(big.NewInt(1000000), big.NewInt(params.Ether))
+	c := &simulatedChain{
+		backend:      backends.NewSimulatedBackend(core.GenesisAlloc{d.Faucet(): {Balance: balance}}, gasLimit),
+		chainId:      simulatedChainId,
+		commitEachTx: cfg.BlockTime == 0,
+	}
+
+	wsUrl, httpUrl, stop, err := c.serve(fmt.Sprintf("%s:%d", cfg.Host, cfg.WsPort), fmt.Sprintf("%s:%d", cfg.Host, cfg.HttpPort))
+	if err != nil {
+		_ = c.backend.Close()
+		return err
+	}
+	d.WsUrl = wsUrl
+	d.HttpUrl = httpUrl
+
+	quit := make(chan struct{})
+	d.stop = func() {
+		close(quit)
+		stop()
+		_ = c.backend.Close()
+	}
+
+	if cfg.BlockTime > 0 {
+		go func() {
+			ticker := time.NewTicker(time.Duration(cfg.BlockTime) * time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					c.backend.Commit()
+				case <-quit:
+					return
+				}
+			}
+		}()
+	}
+
+	return nil
+}
+
+// Close disconnects from the chain, and stops it if it is in-process
+func (d *Devnet) Close() {
+	if d.Client != nil {
+		d.Client.Close()
+	}
+	if d.stop != nil {
+		d.stop()
+	}
+}
+
+// Faucet returns the address paying for deployments and requests
+func (d *Devnet) Faucet() common.Address {
+	return crypto.PubkeyToAddress(d.faucet.PublicKey)
+}
+
+// Deploy deploys the mock xFUND token, the Router and the mock consumer, whose Router
+// allowance is set to tokens
+func (d *Devnet) Deploy(ctx context.Context, tokens *big.Int) error {
+	supply := new(big.Int).Mul(big.NewInt(1000000000), big.NewInt(1000000000))
+
+	err := d.send(ctx, d.faucet, func(opts *bind.TransactOpts) (tx *types.Transaction, err error) {
+		d.Token, tx, d.token, err = DeployMockToken(opts, d.Client, "xFUND", "xFUND", supply, TokenDecimals)
+		return tx, err
+	})
+	if err != nil {
+		return fmt.Errorf("cannot deploy token: %s", err.Error())
+	}
+
+	routerAbi, err := abi.JSON(strings.NewReader(ooo_router.OooRouterMetaData.ABI))
+	if err != nil {
+		return err
+	}
+	err = d.send(ctx, d.faucet, func(opts *bind.TransactOpts) (tx *types.Transaction, err error) {
+		d.Router, tx, _, err = bind.DeployContract(opts, routerAbi, common.FromHex(RouterBin), d.Client, d.Token)
+		return tx, err
+	})
+	if err != nil {
+		return fmt.Errorf("cannot deploy router: %s", err.Error())
+	}
+	if d.router, err = ooo_router.NewOooRouter(d.Router, d.Client); err != nil {
+		return err
+	}
+
+	err = d.send(ctx, d.faucet, func(opts *bind.TransactOpts) (tx *types.Transaction, err error) {
+		d.Consumer, tx, d.consumer, err = DeployMockConsumer(opts, d.Client, d.Router, d.Token)
+		return tx, err
+	})
+	if err != nil {
+		return fmt.Errorf("cannot deploy consumer: %s", err.Error())
+	}
+
+	err = d.send(ctx, d.faucet, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return d.token.Transfer(opts, d.Consumer, tokens)
+	})
+	if err != nil {
+		return fmt.Errorf("cannot fund consumer: %s", err.Error())
+	}
+
+	err = d.send(ctx, d.faucet, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return d.consumer.IncreaseRouterAllowance(opts, tokens)
+	})
+	if err != nil {
+		return fmt.Errorf("cannot set consumer allowance: %s", err.Error())
+	}
+
+	return nil
+}
+
+// Attach attaches to contracts deployed by Deploy on a devnet started by another process. They
+// are found from the faucet's first three deployments
+func (d *Devnet) Attach(ctx context.Context) error {
+	d.Token = crypto.CreateAddress(d.Faucet(), 0)
+	d.Router = crypto.CreateAddress(d.Faucet(), 1)
+	d.Consumer = crypto.CreateAddress(d.Faucet(), 2)
+
+	for name, address := range map[string]common.Address{"token": d.Token, "router": d.Router, "consumer": d.Consumer} {
+		code, err := d.Client.CodeAt(ctx, address, nil)
+		if err != nil {
+			return err
+		}
+		if len(code) == 0 {
+			return fmt.Errorf("no %s contract at %s - was the devnet deployed by the faucet's first txs?", name, address.Hex())
+		}
+	}
+
+	var err error
+	if d.token, err = NewMockToken(d.Token, d.Client); err != nil {
+		return err
+	}
+	if d.router, err = ooo_router.NewOooRouter(d.Router, d.Client); err != nil {
+		return err
+	}
+	d.consumer, err = NewMockConsumer(d.Consumer, d.Client)
+
+	return err
+}
+
+// RegisterProvider sends the provider's key gasFunds wei to pay for gas, and registers it on the
+// Router with fee, unless it is already registered
+func (d *Devnet) RegisterProvider(ctx context.Context, providerKey string, gasFunds *big.Int, fee *big.Int) (common.Address, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(providerKey, "0x"))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid provider key: %s", err.Error())
+	}
+	provider := crypto.PubkeyToAddress(key.PublicKey)
+
+	err = d.send(ctx, d.faucet, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		nonce, err := d.Client.PendingNonceAt(ctx, opts.From)
+		if err != nil {
+			return nil, err
+		}
+		tx := types.NewTx(&types.LegacyTx{Nonce: nonce, To: &provider, Value: gasFunds, Gas: 21000, GasPrice: opts.GasPrice})
+		if tx, err = opts.Signer(opts.From, tx); err != nil {
+			return nil, err
+		}
+		return tx, d.Client.SendTransaction(ctx, tx)
+	})
+	if err != nil {
+		return provider, fmt.Errorf("cannot fund provider: %s", err.Error())
+	}
+
+	registered, err := d.router.GetProviderMinFee(&bind.CallOpts{Context: ctx}, provider)
+	if err != nil {
+		return provider, err
+	}
+	if registered.Sign() > 0 {
+		return provider, nil
+	}
+
+	err = d.send(ctx, key, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return d.router.RegisterAsProvider(opts, fee)
+	})
+	if err != nil {
+		return provider, fmt.Errorf("cannot register provider: %s", err.Error())
+	}
+
+	return provider, nil
+}
+
+// Request sends a request for endpoint, e.g. BTC.USD.PR.AVC, from the mock consumer to
+// provider, returning its request ID
+func (d *Devnet) Request(ctx context.Context, provider common.Address, fee *big.Int, endpoint string) (common.Hash, error) {
+	if len(endpoint) > 32 {
+		return common.Hash{}, fmt.Errorf("endpoint %s is longer than 32 bytes", endpoint)
+	}
+	var data [32]byte
+	copy(data[:], endpoint)
+
+	var receipt *types.Receipt
+	err := d.send(ctx, d.faucet, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return d.consumer.GetData(opts, provider, fee, data)
+	}, &receipt)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	for _, l := range receipt.Logs {
+		if ev, err := d.consumer.ParseRequestedSomeData(*l); err == nil {
+			return ev.RequestId, nil
+		}
+	}
+
+	return common.Hash{}, errors.New("request sent, but no RequestedSomeData event was emitted")
+}
+
+// WaitFulfilled waits for the request to be fulfilled, returning the value the consumer received
+func (d *Devnet) WaitFulfilled(ctx context.Context, requestId common.Hash) (*big.Int, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("request %s not fulfilled: %s", requestId.Hex(), ctx.Err().Error())
+		}
+
+		it, err := d.consumer.FilterGotSomeData(&bind.FilterOpts{Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		for it.Next() {
+			if it.Event.RequestId == requestId {
+				it.Close()
+				return it.Event.Price, nil
+			}
+		}
+		it.Close()
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("request %s not fulfilled: %s", requestId.Hex(), ctx.Err().Error())
+		case <-ticker.C:
+		}
+	}
+}
+
+// send sends a tx signed with key, and waits for it to be mined successfully. The receipt is
+// stored in receipt, if given
+func (d *Devnet) send(ctx context.Context, key *ecdsa.PrivateKey, fn func(opts *bind.TransactOpts) (*types.Transaction, error), receipt ...**types.Receipt) error {
+	ctx, cancel := context.WithTimeout(ctx, txTimeout)
+	defer cancel()
+
+	opts, err := bind.NewKeyedTransactorWithChainID(key, d.ChainId)
+	if err != nil {
+		return err
+	}
+	opts.Context = ctx
+	if opts.GasPrice, err = d.Client.SuggestGasPrice(ctx); err != nil {
+		return err
+	}
+
+	tx, err := fn(opts)
+	if err != nil {
+		return err
+	}
+
+	r, err := bind.WaitMined(ctx, d.Client, tx)
+	if err != nil {
+		return fmt.Errorf("tx %s not mined: %s", tx.Hash().Hex(), err.Error())
+	}
+	if r.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("tx %s failed", tx.Hash().Hex())
+	}
+	if len(receipt) > 0 {
+		*receipt[0] = r
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package devnet_test
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/chain"
+	"go-ooo/config"
+	"go-ooo/credentials"
+	"go-ooo/database"
+	"go-ooo/devnet"
+	"go-ooo/service"
+	"go-ooo/signer"
+	"go-ooo/supervisor"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// providerKey - anvil's second default account, so that it is not the faucet
+const providerKey = "59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d"
+
+// fee - the provider's fee, in xFUND * 10^9
+const fee = 100000
+
+// TestFulfillment runs the node against an in-process devnet, and checks that requests sent from
+// the mock consumer are fulfilled on chain. It takes a few blocks, and is skipped by go test -short
+func TestFulfillment(t *testing.T) {
+	if testing.Short() {
+		t.Skip("e2e test skipped in short mode")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	d, err := devnet.Start(ctx, devnet.Config{Host: "127.0.0.1", BlockTime: 1})
+	if err != nil {
+		t.Fatalf("start devnet: %s", err)
+	}
+	defer d.Close()
+
+	if err = d.Deploy(ctx, big.NewInt(fee*100)); err != nil {
+		t.Fatalf("deploy: %s", err)
+	}
+	provider, err := d.RegisterProvider(ctx, providerKey, big.NewInt(params.Ether), big.NewInt(fee))
+	if err != nil {
+		t.Fatalf("register provider: %s", err)
+	}
+
+	srv := startNode(t, ctx, d)
+	defer srv.Stop()
+
+	requestIds := make([]common.Hash, 0, 3)
+	for i := 0; i < 3; i++ {
+		requestId, err := d.Request(ctx, provider, big.NewInt(fee), "BTC.USD.PR.AVC")
+		if err != nil {
+			t.Fatalf("request %d: %s", i+1, err)
+		}
+		requestIds = append(requestIds, requestId)
+	}
+
+	for _, requestId := range requestIds {
+		price, err := d.WaitFulfilled(ctx, requestId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if price.Sign() <= 0 {
+			t.Errorf("request %s fulfilled with %s", requestId.Hex(), price)
+		}
+	}
+}
+
+// startNode runs the node's service against the devnet, answering every request with mock
+// prices, with the provider's key as its oracle key
+func startNode(t *testing.T, ctx context.Context, d *devnet.Devnet) *service.Service {
+	t.Helper()
+
+	viper.Reset()
+	viper.Set(config.ChainEthWsHost, d.WsUrl)
+	viper.Set(config.ChainEthHttpHost, d.HttpUrl)
+	viper.Set(config.ChainContractAddress, d.Router.Hex())
+	viper.Set(config.ChainNetworkId, d.ChainId.Int64())
+	viper.Set(config.ChainFirstBlock, 1)
+	viper.Set(config.ChainGasLimit, 500000)
+	viper.Set(config.ChainMaxGasPrice, 150)
+	for _, k := range []string{config.SubChainEthHttpRpc, config.SubChainPolygonHttpRpc, config.SubChainBcsHttpRpc, config.SubChainXdaiHttpRpc} {
+		viper.Set(k, d.HttpUrl)
+	}
+	viper.Set(config.DatabaseDialect, "sqlite")
+	viper.Set(config.DatabaseStorage, ":memory:")
+	viper.Set(config.JobsMockSources, true)
+	viper.Set(config.JobsCheckDuration, 1)
+	viper.Set(config.FeaturesAdminApi, false)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+
+	db, err := database.NewDb()
+	if err != nil {
+		t.Fatalf("open database: %s", err)
+	}
+	if err = db.Migrate(); err != nil {
+		t.Fatalf("migrate database: %s", err)
+	}
+
+	oracle, err := signer.NewLocalSigner(providerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := credentials.New(db, logger, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err := service.NewService(ctx, logger, chain.Signers{Oracle: oracle}, db, "e2e-token",
+		supervisor.New(logger, nil), creds)
+	if err != nil {
+		t.Fatalf("start node: %s", err)
+	}
+	go srv.Run()
+
+	return srv
+}
@@ -0,0 +1,324 @@
+package devnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/montanaflynn/stats"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// load test failure modes
+const (
+	// LoadTestSendFailed - the request tx could not be sent, e.g. the RPC rejected it
+	LoadTestSendFailed = "send_failed"
+	// LoadTestNotMined - the request tx was not mined before the test ended
+	LoadTestNotMined = "not_mined"
+	// LoadTestReverted - the request tx was mined but failed, e.g. the consumer ran out of xFUND
+	LoadTestReverted = "reverted"
+	// LoadTestUnfulfilled - the request was made, but the node did not fulfil it before the test ended
+	LoadTestUnfulfilled = "unfulfilled"
+)
+
+// loadTestPollInterval - how often the load test checks for fulfillments. Shorter than
+// pollInterval, so that latencies are measured more closely
+const loadTestPollInterval = 250 * time.Millisecond
+
+// LoadTestConfig configures a load test
+type LoadTestConfig struct {
+	// Provider is the node's provider address requests are sent to
+	Provider common.Address
+	// Fee is the fee paid for each request
+	Fee *big.Int
+	// Endpoints are requested in turn, e.g. BTC.USD.PR.AVC
+	Endpoints []string
+	// Rate is the number of requests sent per second
+	Rate float64
+	// Duration is how long requests are sent for
+	Duration time.Duration
+	// Drain is how long to wait, once requests stop, for outstanding requests to be fulfilled
+	Drain time.Duration
+}
+
+// LoadTestLatency is the percentiles of a latency
+type LoadTestLatency struct {
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+	Max time.Duration `json:"max"`
+}
+
+// LoadTestProgress is the state of a running load test
+type LoadTestProgress struct {
+	Elapsed     time.Duration
+	Sent        int
+	Fulfilled   int
+	Outstanding int
+}
+
+// LoadTestResult summarises a load test
+type LoadTestResult struct {
+	// Elapsed is the time from the first request being sent to the last fulfillment, or the test ending
+	Elapsed time.Duration `json:"elapsed"`
+	// TargetRate is the requested rate, and SentRate the rate requests were actually sent at
+	TargetRate float64 `json:"target_rate"`
+	SentRate   float64 `json:"sent_rate"`
+	Sent       int     `json:"sent"`
+	Mined      int     `json:"mined"`
+	Fulfilled  int     `json:"fulfilled"`
+	// Throughput is fulfillments per second over Elapsed
+	Throughput float64 `json:"throughput"`
+	// MineLatency is from sending a request to its tx being mined, FulfilLatency from the request
+	// being mined to its fulfillment being seen, and TotalLatency the two together
+	MineLatency   LoadTestLatency `json:"mine_latency"`
+	FulfilLatency LoadTestLatency `json:"fulfil_latency"`
+	TotalLatency  LoadTestLatency `json:"total_latency"`
+	// Failures counts the requests failing in each failure mode, e.g. unfulfilled
+	Failures map[string]int `json:"failures"`
+	// LastErrors is the last error for each failure mode with one
+	LastErrors map[string]string `json:"last_errors"`
+}
+
+// loadTestRequest is a request sent by a load test
+type loadTestRequest struct {
+	sent      time.Time
+	mined     time.Time
+	requestId common.Hash
+}
+
+// LoadTest sends requests from the mock consumer at cfg.Rate for cfg.Duration, then waits up
+// to cfg.Drain for those outstanding to be fulfilled, and summarises the node's throughput and
+// latencies, and the requests which failed. Requests are sent without waiting for the previous
+// one to be mined, so the faucet must not send other txs during the test. progress, if not nil,
+// is called every few seconds
+func (d *Devnet) LoadTest(ctx context.Context, cfg LoadTestConfig, progress func(LoadTestProgress)) (LoadTestResult, error) {
+	res := LoadTestResult{
+		TargetRate: cfg.Rate,
+		Failures:   make(map[string]int),
+		LastErrors: make(map[string]string),
+	}
+
+	if cfg.Rate <= 0 {
+		return res, errors.New("rate must be more than 0")
+	}
+	if len(cfg.Endpoints) == 0 {
+		return res, errors.New("no endpoints to request")
+	}
+	payloads := make([][32]byte, len(cfg.Endpoints))
+	for i, endpoint := range cfg.Endpoints {
+		if len(endpoint) > 32 {
+			return res, fmt.Errorf("endpoint %s is longer than 32 bytes", endpoint)
+		}
+		copy(payloads[i][:], endpoint)
+	}
+
+	opts, err := bind.NewKeyedTransactorWithChainID(d.faucet, d.ChainId)
+	if err != nil {
+		return res, err
+	}
+	opts.Context = ctx
+	if opts.GasPrice, err = d.Client.SuggestGasPrice(ctx); err != nil {
+		return res, err
+	}
+	nonce, err := d.Client.PendingNonceAt(ctx, crypto.PubkeyToAddress(d.faucet.PublicKey))
+	if err != nil {
+		return res, err
+	}
+	fromBlock, err := d.Client.BlockNumber(ctx)
+	if err != nil {
+		return res, err
+	}
+
+	var mu sync.Mutex
+	var requests []*loadTestRequest
+	fulfilled := make(map[common.Hash]time.Time)
+	fail := func(mode string, err error) {
+		mu.Lock()
+		res.Failures[mode]++
+		res.LastErrors[mode] = err.Error()
+		mu.Unlock()
+	}
+
+	// outstanding requests are waited for until the drain ends
+	drainCtx, cancelDrain := context.WithTimeout(ctx, cfg.Duration+cfg.Drain)
+	defer cancelDrain()
+
+	var mining sync.WaitGroup
+	start := time.Now()
+	interval := time.Duration(float64(time.Second) / cfg.Rate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	scanTicker := time.NewTicker(loadTestPollInterval)
+	defer scanTicker.Stop()
+	progressTicker := time.NewTicker(5 * time.Second)
+	defer progressTicker.Stop()
+
+	// scan records when each fulfillment since the last scan was first seen
+	scan := func() error {
+		head, err := d.Client.BlockNumber(drainCtx)
+		if err != nil || head < fromBlock {
+			return err
+		}
+		it, err := d.consumer.FilterGotSomeData(&bind.FilterOpts{Start: fromBlock, End: &head, Context: drainCtx})
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		mu.Lock()
+		for it.Next() {
+			if _, ok := fulfilled[it.Event.RequestId]; !ok {
+				fulfilled[it.Event.RequestId] = now
+			}
+		}
+		mu.Unlock()
+		it.Close()
+		fromBlock = head + 1
+		return nil
+	}
+
+	// outstanding returns the number of requests sent but not yet fulfilled
+	outstanding := func() (int, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		done := 0
+		for _, r := range requests {
+			if _, ok := fulfilled[r.requestId]; ok && r.requestId != (common.Hash{}) {
+				done++
+			}
+		}
+		return len(requests) - done - res.Failures[LoadTestSendFailed] - res.Failures[LoadTestReverted], done
+	}
+
+	sending := true
+	sendTimer := time.NewTimer(cfg.Duration)
+	defer sendTimer.Stop()
+loop:
+	for {
+		select {
+		case <-drainCtx.Done():
+			break loop
+		case <-sendTimer.C:
+			sending = false
+			ticker.Stop()
+		case <-ticker.C:
+			// a tick may already be waiting when the ticker is stopped
+			if !sending {
+				continue
+			}
+			r := &loadTestRequest{sent: time.Now()}
+			opts.Nonce = new(big.Int).SetUint64(nonce)
+			tx, err := d.consumer.GetData(opts, cfg.Provider, cfg.Fee, payloads[len(requests)%len(payloads)])
+			mu.Lock()
+			requests = append(requests, r)
+			mu.Unlock()
+			if err != nil {
+				fail(LoadTestSendFailed, err)
+				continue
+			}
+			nonce++
+
+			mining.Add(1)
+			go func() {
+				defer mining.Done()
+				receipt, err := bind.WaitMined(drainCtx, d.Client, tx)
+				if err != nil {
+					fail(LoadTestNotMined, err)
+					return
+				}
+				if receipt.Status != types.ReceiptStatusSuccessful {
+					fail(LoadTestReverted, fmt.Errorf("tx %s failed", tx.Hash().Hex()))
+					return
+				}
+				for _, l := range receipt.Logs {
+					if ev, err := d.consumer.ParseRequestedSomeData(*l); err == nil {
+						mu.Lock()
+						r.mined = time.Now()
+						r.requestId = ev.RequestId
+						mu.Unlock()
+						return
+					}
+				}
+				fail(LoadTestReverted, fmt.Errorf("tx %s emitted no RequestedSomeData event", tx.Hash().Hex()))
+			}()
+		case <-scanTicker.C:
+			// a failed scan is retried on the next tick
+			if err := scan(); err != nil {
+				continue
+			}
+			if n, _ := outstanding(); !sending && n == 0 {
+				break loop
+			}
+		case <-progressTicker.C:
+			if progress != nil {
+				n, done := outstanding()
+				progress(LoadTestProgress{Elapsed: time.Since(start), Sent: len(requests), Fulfilled: done, Outstanding: n})
+			}
+		}
+	}
+	cancelDrain()
+	mining.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var mineLatencies, fulfilLatencies, totalLatencies []float64
+	last := start
+	for _, r := range requests {
+		if r.mined.IsZero() {
+			continue
+		}
+		res.Mined++
+		mineLatencies = append(mineLatencies, float64(r.mined.Sub(r.sent)))
+
+		at, ok := fulfilled[r.requestId]
+		if !ok {
+			res.Failures[LoadTestUnfulfilled]++
+			res.LastErrors[LoadTestUnfulfilled] = fmt.Sprintf("request %s not fulfilled", r.requestId.Hex())
+			continue
+		}
+		res.Fulfilled++
+		// fulfillments seen in the scan after the request was mined have a latency of 0
+		fulfilLatency := at.Sub(r.mined)
+		if fulfilLatency < 0 {
+			fulfilLatency = 0
+		}
+		fulfilLatencies = append(fulfilLatencies, float64(fulfilLatency))
+		totalLatencies = append(totalLatencies, float64(at.Sub(r.sent)))
+		if at.After(last) {
+			last = at
+		}
+	}
+
+	res.Sent = len(requests) - res.Failures[LoadTestSendFailed]
+	res.SentRate = float64(res.Sent) / cfg.Duration.Seconds()
+	res.Elapsed = time.Since(start)
+	if res.Failures[LoadTestUnfulfilled] == 0 && res.Fulfilled > 0 {
+		res.Elapsed = last.Sub(start)
+	}
+	if res.Elapsed > 0 {
+		res.Throughput = float64(res.Fulfilled) / res.Elapsed.Seconds()
+	}
+	res.MineLatency = loadTestLatency(mineLatencies)
+	res.FulfilLatency = loadTestLatency(fulfilLatencies)
+	res.TotalLatency = loadTestLatency(totalLatencies)
+
+	return res, nil
+}
+
+// loadTestLatency returns the percentiles of latencies, in nanoseconds
+func loadTestLatency(latencies []float64) LoadTestLatency {
+	if len(latencies) == 0 {
+		return LoadTestLatency{}
+	}
+	p50, _ := stats.Percentile(latencies, 50)
+	p90, _ := stats.Percentile(latencies, 90)
+	p99, _ := stats.Percentile(latencies, 99)
+	max, _ := stats.Max(latencies)
+	return LoadTestLatency{P50: time.Duration(p50), P90: time.Duration(p90), P99: time.Duration(p99), Max: time.Duration(max)}
+}
@@ -0,0 +1,619 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package devnet
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// MockConsumerMetaData contains all meta data concerning the MockConsumer contract.
+var MockConsumerMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_router\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"_xfund\",\"type\":\"address\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"address\",\"name\":\"router\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"requestId\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"price\",\"type\":\"uint256\"}],\"name\":\"GotSomeData\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"requestId\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"endpoint\",\"type\":\"bytes32\"}],\"name\":\"RequestedSomeData\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"getRouterAddress\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"price\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_price\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"_requestId\",\"type\":\"bytes32\"}],\"name\":\"rawReceiveData\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_amount\",\"type\":\"uint256\"}],\"name\":\"increaseRouterAllowance\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_price\",\"type\":\"uint256\"}],\"name\":\"setPrice\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_dataProvider\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"_fee\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"_data\",\"type\":\"bytes32\"}],\"name\":\"getData\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+	Bin: "0x60a060405234801561001057600080fd5b5060405161073f38038061073f83398101604081905261002f91610145565b81816001600160a01b0382166100965760405162461bcd60e51b815260206004820152602160248201527f726f757465722063616e6e6f7420626520746865207a65726f206164647265736044820152607360f81b60648201526084015b60405180910390fd5b6001600160a01b0381166100ec5760405162461bcd60e51b815260206004820181905260248201527f7866756e642063616e6e6f7420626520746865207a65726f2061646472657373604482015260640161008d565b600180546001600160a01b0319166001600160a01b03939093169290921790915560601b6001600160601b03191660805250506000600255610177565b80516001600160a01b038116811461014057600080fd5b919050565b60008060408385031215610157578182fd5b61016083610129565b915061016e60208401610129565b90509250929050565b60805160601c6105aa610195600039600061033801526105aa6000f3fe608060405234801561001057600080fd5b50600436106100625760003560e01c8063425c98ff146100675780636991cf891461007c57806391b7f5ed1461008f578063969890e4146100a2578063a035b1fe146100b5578063d54f7d5e146100d1575b600080fd5b61007a6100753660046104b8565b6100ec565b005b61007a61008a366004610517565b61013b565b61007a61009d366004610517565b600255565b61007a6100b036600461052f565b610150565b6100be60025481565b6040519081526020015b60405180910390f35b6001546040516001600160a01b0390911681526020016100c8565b60006100f98484846101b4565b60408051828152602081018590529192507f940649a72646f367cc5f0928e9ae66ebed0c8165825d12ac76cfb1cbbc8f6ee4910160405180910390a150505050565b6101448161030c565b61014d57600080fd5b50565b6001546001600160a01b031633146101a65760405162461bcd60e51b81526020600482015260146024820152731bdb9b1e48149bdd5d195c8818d85b8818d85b1b60621b60448201526064015b60405180910390fd5b6101b08282610408565b5050565b6001546001600160a01b03841660009081526020818152604080832054815130606090811b6bffffffffffffffffffffffff19908116838701528a821b8116603484015296901b9095166048860152605c850152607c80850186905281518086039091018152609c9094019052825192019190912081906001546040516001620fb3e960e11b031981526001600160a01b038881166004830152602482018890526044820187905292935091169063ffe0982e90606401602060405180830381600087803b15801561028557600080fd5b505af1158015610299573d6000803e3d6000fd5b505050506040513d601f19601f820116820180604052508101906102bd91906104f7565b6102c657600080fd5b6001600160a01b0385166000908152602081905260409020546102ea906001610452565b6001600160a01b03861660009081526020819052604090205590509392505050565b600154604051633950935160e01b81526001600160a01b039182166004820152602481018390526000917f00000000000000000000000000000000000000000000000000000000000000001690633950935190604401602060405180830381600087803b15801561037c57600080fd5b505af1158015610390573d6000803e3d6000fd5b505050506040513d601f19601f820116820180604052508101906103b491906104f7565b6104005760405162461bcd60e51b815260206004820152601c60248201527f6661696c656420746f20696e63726561736520616c6c6f77616e636500000000604482015260640161019d565b506001919050565b600282905560408051338152602081018390529081018390527f0b980b24034f1e472cda6b5ea63d119eee3c302e6596f92d49b2ba3667a10bf09060600160405180910390a15050565b60008061045f8385610550565b9050838110156104b15760405162461bcd60e51b815260206004820152601b60248201527f536166654d6174683a206164646974696f6e206f766572666c6f770000000000604482015260640161019d565b9392505050565b6000806000606084860312156104cc578283fd5b83356001600160a01b03811681146104e2578384fd5b95602085013595506040909401359392505050565b600060208284031215610508578081fd5b815180151581146104b1578182fd5b600060208284031215610528578081fd5b5035919050565b60008060408385031215610541578182fd5b50508035926020909101359150565b6000821982111561056f57634e487b7160e01b81526011600452602481fd5b50019056fea26469706673582212201cd7096802d6a8d2a891ec5395a5c484c13ff0fb8415d244f726d716b99eae2064736f6c63430008030033",
+}
+
+// MockConsumerABI is the input ABI used to generate the binding from.
+// Deprecated: Use MockConsumerMetaData.ABI instead.
+var MockConsumerABI = MockConsumerMetaData.ABI
+
+// MockConsumerBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use MockConsumerMetaData.Bin instead.
+var MockConsumerBin = MockConsumerMetaData.Bin
+
+// DeployMockConsumer deploys a new Ethereum contract, binding an instance of MockConsumer to it.
+func DeployMockConsumer(auth *bind.TransactOpts, backend bind.ContractBackend, _router common.Address, _xfund common.Address) (common.Address, *types.Transaction, *MockConsumer, error) {
+	parsed, err := MockConsumerMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(MockConsumerBin), backend, _router, _xfund)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &MockConsumer{MockConsumerCaller: MockConsumerCaller{contract: contract}, MockConsumerTransactor: MockConsumerTransactor{contract: contract}, MockConsumerFilterer: MockConsumerFilterer{contract: contract}}, nil
+}
+
+// MockConsumer is an auto generated Go binding around an Ethereum contract.
+type MockConsumer struct {
+	MockConsumerCaller     // Read-only binding to the contract
+	MockConsumerTransactor // Write-only binding to the contract
+	MockConsumerFilterer   // Log filterer for contract events
+}
+
+// MockConsumerCaller is an auto generated read-only Go binding around an Ethereum contract.
+type MockConsumerCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MockConsumerTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type MockConsumerTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MockConsumerFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type MockConsumerFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MockConsumerSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type MockConsumerSession struct {
+	Contract     *MockConsumer     // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// MockConsumerCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type MockConsumerCallerSession struct {
+	Contract *MockConsumerCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts       // Call options to use throughout this session
+}
+
+// MockConsumerTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type MockConsumerTransactorSession struct {
+	Contract     *MockConsumerTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts       // Transaction auth options to use throughout this session
+}
+
+// MockConsumerRaw is an auto generated low-level Go binding around an Ethereum contract.
+type MockConsumerRaw struct {
+	Contract *MockConsumer // Generic contract binding to access the raw methods on
+}
+
+// MockConsumerCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type MockConsumerCallerRaw struct {
+	Contract *MockConsumerCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// MockConsumerTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type MockConsumerTransactorRaw struct {
+	Contract *MockConsumerTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewMockConsumer creates a new instance of MockConsumer, bound to a specific deployed contract.
+func NewMockConsumer(address common.Address, backend bind.ContractBackend) (*MockConsumer, error) {
+	contract, err := bindMockConsumer(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &MockConsumer{MockConsumerCaller: MockConsumerCaller{contract: contract}, MockConsumerTransactor: MockConsumerTransactor{contract: contract}, MockConsumerFilterer: MockConsumerFilterer{contract: contract}}, nil
+}
+
+// NewMockConsumerCaller creates a new read-only instance of MockConsumer, bound to a specific deployed contract.
+func NewMockConsumerCaller(address common.Address, caller bind.ContractCaller) (*MockConsumerCaller, error) {
+	contract, err := bindMockConsumer(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &MockConsumerCaller{contract: contract}, nil
+}
+
+// NewMockConsumerTransactor creates a new write-only instance of MockConsumer, bound to a specific deployed contract.
+func NewMockConsumerTransactor(address common.Address, transactor bind.ContractTransactor) (*MockConsumerTransactor, error) {
+	contract, err := bindMockConsumer(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &MockConsumerTransactor{contract: contract}, nil
+}
+
+// NewMockConsumerFilterer creates a new log filterer instance of MockConsumer, bound to a specific deployed contract.
+func NewMockConsumerFilterer(address common.Address, filterer bind.ContractFilterer) (*MockConsumerFilterer, error) {
+	contract, err := bindMockConsumer(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &MockConsumerFilterer{contract: contract}, nil
+}
+
+// bindMockConsumer binds a generic wrapper to an already deployed contract.
+func bindMockConsumer(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(MockConsumerABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_MockConsumer *MockConsumerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _MockConsumer.Contract.MockConsumerCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_MockConsumer *MockConsumerRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _MockConsumer.Contract.MockConsumerTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_MockConsumer *MockConsumerRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _MockConsumer.Contract.MockConsumerTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_MockConsumer *MockConsumerCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _MockConsumer.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_MockConsumer *MockConsumerTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _MockConsumer.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_MockConsumer *MockConsumerTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _MockConsumer.Contract.contract.Transact(opts, method, params...)
+}
+
+// GetRouterAddress is a free data retrieval call binding the contract method 0xd54f7d5e.
+//
+// Solidity: function getRouterAddress() view returns(address)
+func (_MockConsumer *MockConsumerCaller) GetRouterAddress(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _MockConsumer.contract.Call(opts, &out, "getRouterAddress")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// GetRouterAddress is a free data retrieval call binding the contract method 0xd54f7d5e.
+//
+// Solidity: function getRouterAddress() view returns(address)
+func (_MockConsumer *MockConsumerSession) GetRouterAddress() (common.Address, error) {
+	return _MockConsumer.Contract.GetRouterAddress(&_MockConsumer.CallOpts)
+}
+
+// GetRouterAddress is a free data retrieval call binding the contract method 0xd54f7d5e.
+//
+// Solidity: function getRouterAddress() view returns(address)
+func (_MockConsumer *MockConsumerCallerSession) GetRouterAddress() (common.Address, error) {
+	return _MockConsumer.Contract.GetRouterAddress(&_MockConsumer.CallOpts)
+}
+
+// Price is a free data retrieval call binding the contract method 0xa035b1fe.
+//
+// Solidity: function price() view returns(uint256)
+func (_MockConsumer *MockConsumerCaller) Price(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _MockConsumer.contract.Call(opts, &out, "price")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// Price is a free data retrieval call binding the contract method 0xa035b1fe.
+//
+// Solidity: function price() view returns(uint256)
+func (_MockConsumer *MockConsumerSession) Price() (*big.Int, error) {
+	return _MockConsumer.Contract.Price(&_MockConsumer.CallOpts)
+}
+
+// Price is a free data retrieval call binding the contract method 0xa035b1fe.
+//
+// Solidity: function price() view returns(uint256)
+func (_MockConsumer *MockConsumerCallerSession) Price() (*big.Int, error) {
+	return _MockConsumer.Contract.Price(&_MockConsumer.CallOpts)
+}
+
+// GetData is a paid mutator transaction binding the contract method 0x425c98ff.
+//
+// Solidity: function getData(address _dataProvider, uint256 _fee, bytes32 _data) returns()
+func (_MockConsumer *MockConsumerTransactor) GetData(opts *bind.TransactOpts, _dataProvider common.Address, _fee *big.Int, _data [32]byte) (*types.Transaction, error) {
+	return _MockConsumer.contract.Transact(opts, "getData", _dataProvider, _fee, _data)
+}
+
+// GetData is a paid mutator transaction binding the contract method 0x425c98ff.
+//
+// Solidity: function getData(address _dataProvider, uint256 _fee, bytes32 _data) returns()
+func (_MockConsumer *MockConsumerSession) GetData(_dataProvider common.Address, _fee *big.Int, _data [32]byte) (*types.Transaction, error) {
+	return _MockConsumer.Contract.GetData(&_MockConsumer.TransactOpts, _dataProvider, _fee, _data)
+}
+
+// GetData is a paid mutator transaction binding the contract method 0x425c98ff.
+//
+// Solidity: function getData(address _dataProvider, uint256 _fee, bytes32 _data) returns()
+func (_MockConsumer *MockConsumerTransactorSession) GetData(_dataProvider common.Address, _fee *big.Int, _data [32]byte) (*types.Transaction, error) {
+	return _MockConsumer.Contract.GetData(&_MockConsumer.TransactOpts, _dataProvider, _fee, _data)
+}
+
+// IncreaseRouterAllowance is a paid mutator transaction binding the contract method 0x6991cf89.
+//
+// Solidity: function increaseRouterAllowance(uint256 _amount) returns()
+func (_MockConsumer *MockConsumerTransactor) IncreaseRouterAllowance(opts *bind.TransactOpts, _amount *big.Int) (*types.Transaction, error) {
+	return _MockConsumer.contract.Transact(opts, "increaseRouterAllowance", _amount)
+}
+
+// IncreaseRouterAllowance is a paid mutator transaction binding the contract method 0x6991cf89.
+//
+// Solidity: function increaseRouterAllowance(uint256 _amount) returns()
+func (_MockConsumer *MockConsumerSession) IncreaseRouterAllowance(_amount *big.Int) (*types.Transaction, error) {
+	return _MockConsumer.Contract.IncreaseRouterAllowance(&_MockConsumer.TransactOpts, _amount)
+}
+
+// IncreaseRouterAllowance is a paid mutator transaction binding the contract method 0x6991cf89.
+//
+// Solidity: function increaseRouterAllowance(uint256 _amount) returns()
+func (_MockConsumer *MockConsumerTransactorSession) IncreaseRouterAllowance(_amount *big.Int) (*types.Transaction, error) {
+	return _MockConsumer.Contract.IncreaseRouterAllowance(&_MockConsumer.TransactOpts, _amount)
+}
+
+// RawReceiveData is a paid mutator transaction binding the contract method 0x969890e4.
+//
+// Solidity: function rawReceiveData(uint256 _price, bytes32 _requestId) returns()
+func (_MockConsumer *MockConsumerTransactor) RawReceiveData(opts *bind.TransactOpts, _price *big.Int, _requestId [32]byte) (*types.Transaction, error) {
+	return _MockConsumer.contract.Transact(opts, "rawReceiveData", _price, _requestId)
+}
+
+// RawReceiveData is a paid mutator transaction binding the contract method 0x969890e4.
+//
+// Solidity: function rawReceiveData(uint256 _price, bytes32 _requestId) returns()
+func (_MockConsumer *MockConsumerSession) RawReceiveData(_price *big.Int, _requestId [32]byte) (*types.Transaction, error) {
+	return _MockConsumer.Contract.RawReceiveData(&_MockConsumer.TransactOpts, _price, _requestId)
+}
+
+// RawReceiveData is a paid mutator transaction binding the contract method 0x969890e4.
+//
+// Solidity: function rawReceiveData(uint256 _price, bytes32 _requestId) returns()
+func (_MockConsumer *MockConsumerTransactorSession) RawReceiveData(_price *big.Int, _requestId [32]byte) (*types.Transaction, error) {
+	return _MockConsumer.Contract.RawReceiveData(&_MockConsumer.TransactOpts, _price, _requestId)
+}
+
+// SetPrice is a paid mutator transaction binding the contract method 0x91b7f5ed.
+//
+// Solidity: function setPrice(uint256 _price) returns()
+func (_MockConsumer *MockConsumerTransactor) SetPrice(opts *bind.TransactOpts, _price *big.Int) (*types.Transaction, error) {
+	return _MockConsumer.contract.Transact(opts, "setPrice", _price)
+}
+
+// SetPrice is a paid mutator transaction binding the contract method 0x91b7f5ed.
+//
+// Solidity: function setPrice(uint256 _price) returns()
+func (_MockConsumer *MockConsumerSession) SetPrice(_price *big.Int) (*types.Transaction, error) {
+	return _MockConsumer.Contract.SetPrice(&_MockConsumer.TransactOpts, _price)
+}
+
+// SetPrice is a paid mutator transaction binding the contract method 0x91b7f5ed.
+//
+// Solidity: function setPrice(uint256 _price) returns()
+func (_MockConsumer *MockConsumerTransactorSession) SetPrice(_price *big.Int) (*types.Transaction, error) {
+	return _MockConsumer.Contract.SetPrice(&_MockConsumer.TransactOpts, _price)
+}
+
+// MockConsumerGotSomeDataIterator is returned from FilterGotSomeData and is used to iterate over the raw logs and unpacked data for GotSomeData events raised by the MockConsumer contract.
+type MockConsumerGotSomeDataIterator struct {
+	Event *MockConsumerGotSomeData // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *MockConsumerGotSomeDataIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(MockConsumerGotSomeData)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(MockConsumerGotSomeData)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *MockConsumerGotSomeDataIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *MockConsumerGotSomeDataIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// MockConsumerGotSomeData represents a GotSomeData event raised by the MockConsumer contract.
+type MockConsumerGotSomeData struct {
+	Router    common.Address
+	RequestId [32]byte
+	Price     *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterGotSomeData is a free log retrieval operation binding the contract event 0x0b980b24034f1e472cda6b5ea63d119eee3c302e6596f92d49b2ba3667a10bf0.
+//
+// Solidity: event GotSomeData(address router, bytes32 requestId, uint256 price)
+func (_MockConsumer *MockConsumerFilterer) FilterGotSomeData(opts *bind.FilterOpts) (*MockConsumerGotSomeDataIterator, error) {
+
+	logs, sub, err := _MockConsumer.contract.FilterLogs(opts, "GotSomeData")
+	if err != nil {
+		return nil, err
+	}
+	return &MockConsumerGotSomeDataIterator{contract: _MockConsumer.contract, event: "GotSomeData", logs: logs, sub: sub}, nil
+}
+
+// WatchGotSomeData is a free log subscription operation binding the contract event 0x0b980b24034f1e472cda6b5ea63d119eee3c302e6596f92d49b2ba3667a10bf0.
+//
+// Solidity: event GotSomeData(address router, bytes32 requestId, uint256 price)
+func (_MockConsumer *MockConsumerFilterer) WatchGotSomeData(opts *bind.WatchOpts, sink chan<- *MockConsumerGotSomeData) (event.Subscription, error) {
+
+	logs, sub, err := _MockConsumer.contract.WatchLogs(opts, "GotSomeData")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(MockConsumerGotSomeData)
+				if err := _MockConsumer.contract.UnpackLog(event, "GotSomeData", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseGotSomeData is a log parse operation binding the contract event 0x0b980b24034f1e472cda6b5ea63d119eee3c302e6596f92d49b2ba3667a10bf0.
+//
+// Solidity: event GotSomeData(address router, bytes32 requestId, uint256 price)
+func (_MockConsumer *MockConsumerFilterer) ParseGotSomeData(log types.Log) (*MockConsumerGotSomeData, error) {
+	event := new(MockConsumerGotSomeData)
+	if err := _MockConsumer.contract.UnpackLog(event, "GotSomeData", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// MockConsumerRequestedSomeDataIterator is returned from FilterRequestedSomeData and is used to iterate over the raw logs and unpacked data for RequestedSomeData events raised by the MockConsumer contract.
+type MockConsumerRequestedSomeDataIterator struct {
+	Event *MockConsumerRequestedSomeData // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *MockConsumerRequestedSomeDataIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(MockConsumerRequestedSomeData)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(MockConsumerRequestedSomeData)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *MockConsumerRequestedSomeDataIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *MockConsumerRequestedSomeDataIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// MockConsumerRequestedSomeData represents a RequestedSomeData event raised by the MockConsumer contract.
+type MockConsumerRequestedSomeData struct {
+	RequestId [32]byte
+	Endpoint  [32]byte
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterRequestedSomeData is a free log retrieval operation binding the contract event 0x940649a72646f367cc5f0928e9ae66ebed0c8165825d12ac76cfb1cbbc8f6ee4.
+//
+// Solidity: event RequestedSomeData(bytes32 requestId, bytes32 endpoint)
+func (_MockConsumer *MockConsumerFilterer) FilterRequestedSomeData(opts *bind.FilterOpts) (*MockConsumerRequestedSomeDataIterator, error) {
+
+	logs, sub, err := _MockConsumer.contract.FilterLogs(opts, "RequestedSomeData")
+	if err != nil {
+		return nil, err
+	}
+	return &MockConsumerRequestedSomeDataIterator{contract: _MockConsumer.contract, event: "RequestedSomeData", logs: logs, sub: sub}, nil
+}
+
+// WatchRequestedSomeData is a free log subscription operation binding the contract event 0x940649a72646f367cc5f0928e9ae66ebed0c8165825d12ac76cfb1cbbc8f6ee4.
+//
+// Solidity: event RequestedSomeData(bytes32 requestId, bytes32 endpoint)
+func (_MockConsumer *MockConsumerFilterer) WatchRequestedSomeData(opts *bind.WatchOpts, sink chan<- *MockConsumerRequestedSomeData) (event.Subscription, error) {
+
+	logs, sub, err := _MockConsumer.contract.WatchLogs(opts, "RequestedSomeData")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(MockConsumerRequestedSomeData)
+				if err := _MockConsumer.contract.UnpackLog(event, "RequestedSomeData", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseRequestedSomeData is a log parse operation binding the contract event 0x940649a72646f367cc5f0928e9ae66ebed0c8165825d12ac76cfb1cbbc8f6ee4.
+//
+// Solidity: event RequestedSomeData(bytes32 requestId, bytes32 endpoint)
+func (_MockConsumer *MockConsumerFilterer) ParseRequestedSomeData(log types.Log) (*MockConsumerRequestedSomeData, error) {
+	event := new(MockConsumerRequestedSomeData)
+	if err := _MockConsumer.contract.UnpackLog(event, "RequestedSomeData", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
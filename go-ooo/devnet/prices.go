@@ -0,0 +1,131 @@
+package devnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// priceDecimals - the stub serves prices with the same decimals as the Finchains API
+const priceDecimals = 18
+
+// PriceApi is a stub of the Finchains API, serving fixed prices, so that the node can fulfil
+// requests without access to the real API. The node's jobs.ooo_api_url should be set to Url
+type PriceApi struct {
+	Url string
+
+	mu     sync.RWMutex
+	prices map[string]*big.Int
+	server *http.Server
+}
+
+// pairResult and priceResult have the same json encoding as the Finchains API's responses
+type pairResult struct {
+	Name   string `json:"name"`
+	Base   string `json:"base"`
+	Target string `json:"target"`
+}
+
+type priceResult struct {
+	Base   string `json:"base"`
+	Target string `json:"target"`
+	Pair   string `json:"pair"`
+	Price  string `json:"price"`
+}
+
+// ServePrices starts a stub price API listening on listen, e.g. 127.0.0.1:8548
+func ServePrices(listen string) (*PriceApi, error) {
+	l, err := net.Listen("tcp", listen)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PriceApi{
+		Url:    fmt.Sprintf("http://%s", l.Addr().String()),
+		prices: make(map[string]*big.Int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pairs", p.servePairs)
+	mux.HandleFunc("/currency/", p.servePrice)
+	p.server = &http.Server{Handler: mux}
+
+	go func() {
+		_ = p.server.Serve(l)
+	}()
+
+	return p, nil
+}
+
+// SetPrice sets the price served for pair, e.g. BTC.USD, from a decimal string, e.g. 50000.5
+func (p *PriceApi) SetPrice(pair string, price string) error {
+	base, target, ok := splitPair(pair)
+	if !ok {
+		return fmt.Errorf("pair %s is not of the form BASE.TARGET", pair)
+	}
+
+	f, ok := new(big.Float).SetPrec(256).SetString(price)
+	if !ok {
+		return fmt.Errorf("price %s is not a number", price)
+	}
+	scaled, _ := f.Mul(f, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(priceDecimals), nil))).Int(nil)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prices[base+"."+target] = scaled
+
+	return nil
+}
+
+// Close stops the stub
+func (p *PriceApi) Close() {
+	_ = p.server.Close()
+}
+
+func (p *PriceApi) servePairs(w http.ResponseWriter, r *http.Request) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	res := make([]pairResult, 0, len(p.prices))
+	for pair := range p.prices {
+		base, target, _ := splitPair(pair)
+		res = append(res, pairResult{Name: pair, Base: base, Target: target})
+	}
+
+	writeJson(w, http.StatusOK, res)
+}
+
+// servePrice serves /currency/<pair>/<data type>, with the same price for every data type
+func (p *PriceApi) servePrice(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/currency/"), "/")
+
+	p.mu.RLock()
+	price, ok := p.prices[parts[0]]
+	p.mu.RUnlock()
+
+	if !ok {
+		writeJson(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("pair %s not found", parts[0])})
+		return
+	}
+
+	base, target, _ := splitPair(parts[0])
+	writeJson(w, http.StatusOK, priceResult{Base: base, Target: target, Pair: parts[0], Price: price.String()})
+}
+
+func splitPair(pair string) (string, string, bool) {
+	parts := strings.Split(strings.ToUpper(pair), ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,359 @@
+package devnet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"net"
+	"net/http"
+)
+
+// simulatedChain serves the subset of the eth JSON-RPC API used by the node and the devnet over
+// a simulated backend, so that the in-process chain can be used through ordinary RPC urls
+type simulatedChain struct {
+	backend *backends.SimulatedBackend
+	chainId *big.Int
+	// commitEachTx mines a block for each tx, instead of on a timer
+	commitEachTx bool
+}
+
+// callArgs has the same json encoding as ethclient's call and estimateGas arguments
+type callArgs struct {
+	From                 *common.Address `json:"from"`
+	To                   *common.Address `json:"to"`
+	Gas                  *hexutil.Uint64 `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big    `json:"value"`
+	Data                 *hexutil.Bytes  `json:"data"`
+	Input                *hexutil.Bytes  `json:"input"`
+}
+
+func (args callArgs) msg() ethereum.CallMsg {
+	msg := ethereum.CallMsg{
+		To:        args.To,
+		GasPrice:  (*big.Int)(args.GasPrice),
+		GasFeeCap: (*big.Int)(args.MaxFeePerGas),
+		GasTipCap: (*big.Int)(args.MaxPriorityFeePerGas),
+		Value:     (*big.Int)(args.Value),
+	}
+	if args.From != nil {
+		msg.From = *args.From
+	}
+	if args.Gas != nil {
+		msg.Gas = uint64(*args.Gas)
+	}
+	if args.Input != nil {
+		msg.Data = *args.Input
+	} else if args.Data != nil {
+		msg.Data = *args.Data
+	}
+	return msg
+}
+
+// serve serves the API over websockets on wsListen, and over http on httpListen, returning
+// the urls
+func (c *simulatedChain) serve(wsListen string, httpListen string) (string, string, func(), error) {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", &ethApi{c}); err != nil {
+		return "", "", nil, err
+	}
+	if err := srv.RegisterName("net", &netApi{c}); err != nil {
+		return "", "", nil, err
+	}
+
+	wsL, err := net.Listen("tcp", wsListen)
+	if err != nil {
+		return "", "", nil, err
+	}
+	httpL, err := net.Listen("tcp", httpListen)
+	if err != nil {
+		wsL.Close()
+		return "", "", nil, err
+	}
+
+	wsServer := &http.Server{Handler: srv.WebsocketHandler([]string{"*"})}
+	httpServer := &http.Server{Handler: srv}
+	go func() {
+		_ = wsServer.Serve(wsL)
+	}()
+	go func() {
+		_ = httpServer.Serve(httpL)
+	}()
+
+	stop := func() {
+		_ = wsServer.Close()
+		_ = httpServer.Close()
+		srv.Stop()
+	}
+
+	return fmt.Sprintf("ws://%s", wsL.Addr().String()), fmt.Sprintf("http://%s", httpL.Addr().String()), stop, nil
+}
+
+// blockNumber returns the backend's block number for n. The simulated backend only supports
+// the latest state, which it uses for pending too
+func blockNumber(n rpc.BlockNumber) *big.Int {
+	switch n {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		return nil
+	case rpc.EarliestBlockNumber:
+		return big.NewInt(0)
+	default:
+		return big.NewInt(n.Int64())
+	}
+}
+
+type netApi struct {
+	c *simulatedChain
+}
+
+func (api *netApi) Version() string {
+	return api.c.chainId.String()
+}
+
+type ethApi struct {
+	c *simulatedChain
+}
+
+func (api *ethApi) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(api.c.chainId)
+}
+
+func (api *ethApi) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(api.c.backend.Blockchain().CurrentBlock().NumberU64())
+}
+
+func (api *ethApi) GasPrice(ctx context.Context) (*hexutil.Big, error) {
+	price, err := api.c.backend.SuggestGasPrice(ctx)
+	return (*hexutil.Big)(price), err
+}
+
+func (api *ethApi) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	tip, err := api.c.backend.SuggestGasTipCap(ctx)
+	return (*hexutil.Big)(tip), err
+}
+
+func (api *ethApi) GetBalance(ctx context.Context, address common.Address, _ rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	balance, err := api.c.backend.BalanceAt(ctx, address, nil)
+	return (*hexutil.Big)(balance), err
+}
+
+func (api *ethApi) GetCode(ctx context.Context, address common.Address, _ rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	return api.c.backend.CodeAt(ctx, address, nil)
+}
+
+func (api *ethApi) GetTransactionCount(ctx context.Context, address common.Address, block rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	if n, ok := block.Number(); ok && n == rpc.PendingBlockNumber {
+		nonce, err := api.c.backend.PendingNonceAt(ctx, address)
+		return hexutil.Uint64(nonce), err
+	}
+	nonce, err := api.c.backend.NonceAt(ctx, address, nil)
+	return hexutil.Uint64(nonce), err
+}
+
+func (api *ethApi) Call(ctx context.Context, args callArgs, block rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	if n, ok := block.Number(); ok && n == rpc.PendingBlockNumber {
+		return api.c.backend.PendingCallContract(ctx, args.msg())
+	}
+	return api.c.backend.CallContract(ctx, args.msg(), nil)
+}
+
+func (api *ethApi) EstimateGas(ctx context.Context, args callArgs, _ *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	gas, err := api.c.backend.EstimateGas(ctx, args.msg())
+	return hexutil.Uint64(gas), err
+}
+
+func (api *ethApi) SendRawTransaction(ctx context.Context, input hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if err := api.c.backend.SendTransaction(ctx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	if api.c.commitEachTx {
+		api.c.backend.Commit()
+	}
+	return tx.Hash(), nil
+}
+
+func (api *ethApi) GetTransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return api.c.backend.TransactionReceipt(ctx, hash)
+}
+
+func (api *ethApi) GetTransactionByHash(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	tx, pending, err := api.c.backend.TransactionByHash(ctx, hash)
+	if err == ethereum.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var receipt *types.Receipt
+	if !pending {
+		if receipt, err = api.c.backend.TransactionReceipt(ctx, hash); err != nil {
+			return nil, err
+		}
+	}
+	return api.c.marshalTx(tx, receipt)
+}
+
+func (api *ethApi) GetBlockByNumber(ctx context.Context, number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	block, err := api.c.backend.BlockByNumber(ctx, blockNumber(number))
+	if err != nil {
+		return nil, nil
+	}
+	return api.c.marshalBlock(ctx, block, fullTx)
+}
+
+func (api *ethApi) GetBlockByHash(ctx context.Context, hash common.Hash, fullTx bool) (map[string]interface{}, error) {
+	block, err := api.c.backend.BlockByHash(ctx, hash)
+	if err != nil {
+		return nil, nil
+	}
+	return api.c.marshalBlock(ctx, block, fullTx)
+}
+
+func (api *ethApi) GetLogs(ctx context.Context, crit filters.FilterCriteria) ([]types.Log, error) {
+	logs, err := api.c.backend.FilterLogs(ctx, ethereum.FilterQuery(crit))
+	if logs == nil {
+		logs = []types.Log{}
+	}
+	return logs, err
+}
+
+// NewHeads serves eth_subscribe("newHeads")
+func (api *ethApi) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	heads := make(chan *types.Header, 16)
+	sub, err := api.c.backend.SubscribeNewHead(context.Background(), heads)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case h := <-heads:
+				_ = notifier.Notify(rpcSub.ID, h)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// Logs serves eth_subscribe("logs")
+func (api *ethApi) Logs(ctx context.Context, crit filters.FilterCriteria) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	logs := make(chan types.Log, 64)
+	sub, err := api.c.backend.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery(crit), logs)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case l := <-logs:
+				_ = notifier.Notify(rpcSub.ID, l)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// marshalTx encodes tx as eth_getTransactionByHash does, with its block if receipt is set
+func (c *simulatedChain) marshalTx(tx *types.Transaction, receipt *types.Receipt) (map[string]interface{}, error) {
+	res, err := toMap(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(c.chainId), tx)
+	if err != nil {
+		return nil, err
+	}
+	res["from"] = from
+	res["blockHash"] = nil
+	res["blockNumber"] = nil
+	res["transactionIndex"] = nil
+	if receipt != nil {
+		res["blockHash"] = receipt.BlockHash
+		res["blockNumber"] = (*hexutil.Big)(receipt.BlockNumber)
+		res["transactionIndex"] = hexutil.Uint(receipt.TransactionIndex)
+	}
+
+	return res, nil
+}
+
+// marshalBlock encodes block as eth_getBlockByNumber does
+func (c *simulatedChain) marshalBlock(ctx context.Context, block *types.Block, fullTx bool) (map[string]interface{}, error) {
+	res, err := toMap(block.Header())
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]interface{}, 0, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		if !fullTx {
+			txs = append(txs, tx.Hash())
+			continue
+		}
+		receipt, err := c.backend.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return nil, err
+		}
+		m, err := c.marshalTx(tx, receipt)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, m)
+	}
+
+	res["transactions"] = txs
+	res["uncles"] = []common.Hash{}
+	res["size"] = hexutil.Uint64(block.Size())
+
+	return res, nil
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var res map[string]interface{}
+	return res, json.Unmarshal(b, &res)
+}
@@ -0,0 +1,160 @@
+// Package errcodes is the taxonomy of the reasons a request can fail to be answered. Each class of
+// failure has a sentinel error, e.g. ErrPairUnsupported, with a stable code, e.g. PAIR_UNSUPPORTED,
+// which is stored against the job, logged as error_code and counted by the job failure metrics,
+// so that failures can be handled and alerted on by class rather than by matching messages.
+//
+// Errors are classified where they occur with Wrap or Errorf, which keep the original message,
+// and checked with errors.Is, or CodeOf for the code
+package errcodes
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code is the machine readable class of a failure
+type Code string
+
+// Failure codes. Stored against jobs, so must not be changed once released
+const (
+	// CodeUnknown - a failure not yet classified
+	CodeUnknown             Code = "UNKNOWN"
+	CodePairUnsupported     Code = "PAIR_UNSUPPORTED"
+	CodeInvalidEndpoint     Code = "INVALID_ENDPOINT"
+	CodeInsufficientSources Code = "INSUFFICIENT_SOURCES"
+	CodeSourceUnavailable   Code = "SOURCE_UNAVAILABLE"
+	CodeInvalidPrice        Code = "INVALID_PRICE"
+	CodePriceOutOfBounds    Code = "PRICE_OUT_OF_BOUNDS"
+	CodeConsensusDeviation  Code = "CONSENSUS_DEVIATION"
+	CodeFeeTooLow           Code = "FEE_TOO_LOW"
+	CodeRateLimited         Code = "RATE_LIMITED"
+	CodeGasCapExceeded      Code = "GAS_CAP_EXCEEDED"
+	CodeRequestExpired      Code = "REQUEST_EXPIRED"
+	CodeAlreadyFulfilled    Code = "ALREADY_FULFILLED"
+	CodeProviderRetired     Code = "PROVIDER_RETIRED"
+	CodeSkippedByOperator   Code = "SKIPPED_BY_OPERATOR"
+	CodeTxFailed            Code = "TX_FAILED"
+	CodeTxReverted          Code = "TX_REVERTED"
+	CodeJobTimeout          Code = "JOB_TIMEOUT"
+	CodeRetriesExhausted    Code = "RETRIES_EXHAUSTED"
+)
+
+// Error is the sentinel error of a class of failure
+type Error struct {
+	code Code
+	msg  string
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// ErrorCode returns the class's code
+func (e *Error) ErrorCode() Code {
+	return e.code
+}
+
+var (
+	ErrPairUnsupported     = register(CodePairUnsupported, "pair not supported")
+	ErrInvalidEndpoint     = register(CodeInvalidEndpoint, "invalid request endpoint")
+	ErrInsufficientSources = register(CodeInsufficientSources, "not enough valid source prices")
+	ErrSourceUnavailable   = register(CodeSourceUnavailable, "data source unavailable")
+	ErrInvalidPrice        = register(CodeInvalidPrice, "invalid price")
+	ErrPriceOutOfBounds    = register(CodePriceOutOfBounds, "price outside its bounds")
+	ErrConsensusDeviation  = register(CodeConsensusDeviation, "answer deviates from peer consensus")
+	ErrFeeTooLow           = register(CodeFeeTooLow, "fee too low")
+	ErrRateLimited         = register(CodeRateLimited, "consumer rate limit exceeded")
+	ErrGasCapExceeded      = register(CodeGasCapExceeded, "fulfillment gas over the gas limit ceiling")
+	ErrRequestExpired      = register(CodeRequestExpired, "request too old")
+	ErrAlreadyFulfilled    = register(CodeAlreadyFulfilled, "request already fulfilled on chain")
+	ErrProviderRetired     = register(CodeProviderRetired, "provider key retired")
+	ErrSkippedByOperator   = register(CodeSkippedByOperator, "skipped by operator")
+	ErrTxFailed            = register(CodeTxFailed, "fulfillment tx failed")
+	ErrTxReverted          = register(CodeTxReverted, "fulfillment tx reverted")
+	ErrJobTimeout          = register(CodeJobTimeout, "job timed out")
+	ErrRetriesExhausted    = register(CodeRetriesExhausted, "too many failed attempts")
+)
+
+var (
+	all    []*Error
+	byCode = make(map[Code]*Error)
+)
+
+func register(code Code, msg string) *Error {
+	e := &Error{code: code, msg: msg}
+	all = append(all, e)
+	byCode[code] = e
+	return e
+}
+
+// Codes returns every failure code, in the order they are declared
+func Codes() []Code {
+	codes := make([]Code, 0, len(all))
+	for _, e := range all {
+		codes = append(codes, e.code)
+	}
+	return codes
+}
+
+// Lookup returns the sentinel error for code, or nil if it is not a known code
+func Lookup(code Code) *Error {
+	return byCode[code]
+}
+
+// coder is implemented by errors which carry a failure code, e.g. the ingestion rejections
+type coder interface {
+	ErrorCode() Code
+}
+
+// codedError is an error classified as kind, keeping its own message
+type codedError struct {
+	kind *Error
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+func (e *codedError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *codedError) ErrorCode() Code {
+	return e.kind.code
+}
+
+// Wrap classifies err as kind, unless it has already been classified, so that the class given
+// closest to where the error occurred is kept. Returns nil if err is nil
+func Wrap(kind *Error, err error) error {
+	if err == nil {
+		return nil
+	}
+	var c coder
+	if errors.As(err, &c) {
+		return err
+	}
+	return &codedError{kind: kind, err: err}
+}
+
+// Errorf returns an error of class kind, with the formatted message
+func Errorf(kind *Error, format string, a ...interface{}) error {
+	return &codedError{kind: kind, err: fmt.Errorf(format, a...)}
+}
+
+// CodeOf returns the code of err's class, CodeUnknown if it has not been classified, or "" if
+// err is nil
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	var c coder
+	if errors.As(err, &c) {
+		return c.ErrorCode()
+	}
+	return CodeUnknown
+}
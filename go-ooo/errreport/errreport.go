@@ -0,0 +1,298 @@
+package errreport
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"go-ooo/redact"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// queueSize - events are dropped if this many are waiting to be delivered
+const queueSize = 64
+
+// contextFields log fields sent as event tags rather than extra data, so that events can be
+// searched and grouped by them
+var contextFields = []string{"package", "function", "action", "request_id", "requestId", "worker_id", "endpoint"}
+
+// Config configures error reporting. Reporting is disabled if Dsn is empty
+type Config struct {
+	// Dsn Sentry DSN, e.g. https://<key>@o0.ingest.sentry.io/<project>
+	Dsn         string
+	Environment string
+	Release     string
+	// RepeatThreshold number of times the same error must be logged within RepeatWindow
+	// before it is reported
+	RepeatThreshold int
+	RepeatWindow    time.Duration
+	Timeout         time.Duration
+}
+
+// Reporter sends panics, and errors which keep recurring, to Sentry. As a logrus hook it
+// sees every error logged by the node, with the fields - request id, function etc. - logged with it
+type Reporter struct {
+	cfg        Config
+	dsn        *dsn
+	client     *http.Client
+	logger     *logrus.Logger
+	serverName string
+	queue      chan *event
+
+	mu     sync.Mutex
+	counts map[string]*errorCount
+}
+
+// errorCount tracks occurrences of an error within the current repeat window
+type errorCount struct {
+	count       int
+	windowStart time.Time
+	reported    bool
+}
+
+// NewReporter returns a Reporter for the configured DSN, or nil, which reports nothing,
+// if the DSN is empty or invalid
+func NewReporter(logger *logrus.Logger, cfg Config) *Reporter {
+	if cfg.Dsn == "" {
+		return nil
+	}
+
+	d, err := parseDsn(cfg.Dsn)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"package":  "errreport",
+			"function": "NewReporter",
+		}).Warn(fmt.Sprintf("error reporting disabled: %s", err.Error()))
+		return nil
+	}
+
+	if cfg.RepeatThreshold <= 0 {
+		cfg.RepeatThreshold = 1
+	}
+	if cfg.RepeatWindow <= 0 {
+		cfg.RepeatWindow = 10 * time.Minute
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	serverName, _ := os.Hostname()
+
+	r := &Reporter{
+		cfg:        cfg,
+		dsn:        d,
+		client:     &http.Client{Timeout: cfg.Timeout},
+		logger:     logger,
+		serverName: serverName,
+		queue:      make(chan *event, queueSize),
+		counts:     make(map[string]*errorCount),
+	}
+
+	go r.run()
+
+	return r
+}
+
+// Levels implements logrus.Hook
+func (r *Reporter) Levels() []logrus.Level {
+	return []logrus.Level{logrus.ErrorLevel, logrus.FatalLevel, logrus.PanicLevel}
+}
+
+// Fire implements logrus.Hook. Recovered panics - errors logged with a stack - and fatal
+// errors are reported immediately. Other errors are reported once they have been logged
+// RepeatThreshold times within RepeatWindow, and then at most once per window
+func (r *Reporter) Fire(entry *logrus.Entry) error {
+	if r == nil {
+		return nil
+	}
+
+	stack, isPanic := entry.Data["stack"].(string)
+	fingerprint := errorFingerprint(entry)
+
+	var count int
+	if !isPanic && entry.Level == logrus.ErrorLevel {
+		var report bool
+		if count, report = r.countError(fingerprint, entry.Time); !report {
+			return nil
+		}
+	}
+
+	ev := r.newEvent(levelName(entry.Level), entry.Message)
+	ev.Logger = "logrus"
+	ev.Fingerprint = []string{fingerprint}
+
+	for k, v := range entry.Data {
+		if k == "stack" {
+			continue
+		}
+		if isContextField(k) {
+			ev.Tags[k] = fmt.Sprint(v)
+		} else {
+			ev.Extra[k] = v
+		}
+	}
+
+	if isPanic {
+		ev.Level = "fatal"
+		ev.Exception = &exceptions{Values: []exception{{
+			Type:  "panic",
+			Value: entry.Message,
+		}}}
+		ev.Extra["stack"] = stack
+	}
+
+	if count > 0 {
+		ev.Extra["occurrences"] = count
+		ev.Extra["window"] = r.cfg.RepeatWindow.String()
+	}
+
+	if entry.Level <= logrus.FatalLevel {
+		// the process is about to exit, so deliver before returning
+		if err := r.send(ev); err != nil {
+			r.logSendError(err)
+		}
+		return nil
+	}
+
+	r.enqueue(ev)
+
+	return nil
+}
+
+// ReportPanic reports a recovered panic, waiting for it to be delivered since the process
+// is usually about to crash. Must be called from the deferred function which recovered p
+func (r *Reporter) ReportPanic(p interface{}) {
+	if r == nil {
+		return
+	}
+
+	msg := redact.String(fmt.Sprint(p))
+	ev := r.newEvent("fatal", msg)
+	ev.Exception = &exceptions{Values: []exception{{
+		Type:       fmt.Sprintf("%T", p),
+		Value:      msg,
+		Stacktrace: &stacktrace{Frames: callerFrames(4)},
+	}}}
+
+	if err := r.send(ev); err != nil {
+		r.logSendError(err)
+	}
+}
+
+// countError records an occurrence of the error, returning the number of occurrences in
+// the current window and whether it should now be reported
+func (r *Reporter) countError(fingerprint string, at time.Time) (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.counts[fingerprint]
+	if !ok || at.Sub(c.windowStart) > r.cfg.RepeatWindow {
+		if !ok && len(r.counts) >= 1000 {
+			r.pruneLocked(at)
+		}
+		c = &errorCount{windowStart: at}
+		r.counts[fingerprint] = c
+	}
+
+	c.count++
+	if c.reported || c.count < r.cfg.RepeatThreshold {
+		return c.count, false
+	}
+
+	c.reported = true
+	return c.count, true
+}
+
+// pruneLocked removes counts whose window has passed. r.mu must be held
+func (r *Reporter) pruneLocked(now time.Time) {
+	for k, c := range r.counts {
+		if now.Sub(c.windowStart) > r.cfg.RepeatWindow {
+			delete(r.counts, k)
+		}
+	}
+}
+
+func (r *Reporter) enqueue(ev *event) {
+	select {
+	case r.queue <- ev:
+	default:
+		// never block logging on error reporting
+	}
+}
+
+func (r *Reporter) run() {
+	for ev := range r.queue {
+		if err := r.send(ev); err != nil {
+			r.logSendError(err)
+		}
+	}
+}
+
+// logSendError logs a failed delivery. Logged as a warning, so that it is not itself reported
+func (r *Reporter) logSendError(err error) {
+	r.logger.WithFields(logrus.Fields{
+		"package":  "errreport",
+		"function": "send",
+	}).Warn(fmt.Sprintf("failed to send error report: %s", err.Error()))
+}
+
+// errorFingerprint groups occurrences of the same error, by where it was logged and its message
+func errorFingerprint(entry *logrus.Entry) string {
+	parts := []string{entry.Message}
+	for _, k := range []string{"package", "function", "action"} {
+		if v, ok := entry.Data[k]; ok {
+			parts = append(parts, fmt.Sprint(v))
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+func isContextField(k string) bool {
+	for _, f := range contextFields {
+		if k == f {
+			return true
+		}
+	}
+	return false
+}
+
+func levelName(l logrus.Level) string {
+	switch l {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return "fatal"
+	case logrus.WarnLevel:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// callerFrames returns the calling goroutine's stack, outermost frame first as Sentry expects
+func callerFrames(skip int) []frame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var res []frame
+	for {
+		f, more := frames.Next()
+		res = append(res, frame{
+			Function: f.Function,
+			AbsPath:  f.File,
+			Lineno:   f.Line,
+			InApp:    strings.HasPrefix(f.Function, "go-ooo/"),
+		})
+		if !more {
+			break
+		}
+	}
+
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+
+	return res
+}
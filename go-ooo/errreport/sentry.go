@@ -0,0 +1,145 @@
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// sentryVersion - protocol version of the store endpoint
+const sentryVersion = 7
+
+// dsn is a parsed Sentry DSN - {scheme}://{public_key}[:{secret_key}]@{host}{path}/{project_id}
+type dsn struct {
+	storeUrl  string
+	publicKey string
+	secretKey string
+}
+
+func parseDsn(raw string) (*dsn, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dsn")
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid dsn scheme %q", u.Scheme)
+	}
+
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("dsn has no public key")
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	if i < 0 || path[i+1:] == "" {
+		return nil, fmt.Errorf("dsn has no project id")
+	}
+
+	secret, _ := u.User.Password()
+
+	return &dsn{
+		storeUrl:  fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, path[:i], path[i+1:]),
+		publicKey: u.User.Username(),
+		secretKey: secret,
+	}, nil
+}
+
+// event is a Sentry event, as accepted by the store endpoint
+type event struct {
+	EventId     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	Logger      string                 `json:"logger,omitempty"`
+	Message     string                 `json:"message"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Fingerprint []string               `json:"fingerprint,omitempty"`
+	Tags        map[string]string      `json:"tags"`
+	Extra       map[string]interface{} `json:"extra"`
+	Exception   *exceptions            `json:"exception,omitempty"`
+}
+
+type exceptions struct {
+	Values []exception `json:"values"`
+}
+
+type exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Stacktrace *stacktrace `json:"stacktrace,omitempty"`
+}
+
+type stacktrace struct {
+	Frames []frame `json:"frames"`
+}
+
+type frame struct {
+	Function string `json:"function"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+func (r *Reporter) newEvent(level string, message string) *event {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return &event{
+		EventId:     hex.EncodeToString(id),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Level:       level,
+		Platform:    "go",
+		Message:     message,
+		ServerName:  r.serverName,
+		Release:     r.cfg.Release,
+		Environment: r.cfg.Environment,
+		Tags:        make(map[string]string),
+		Extra:       make(map[string]interface{}),
+	}
+}
+
+func (r *Reporter) send(ev *event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", r.dsn.storeUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	auth := fmt.Sprintf("Sentry sentry_version=%d, sentry_client=go-ooo/%s, sentry_key=%s",
+		sentryVersion, r.cfg.Release, r.dsn.publicKey)
+	if r.dsn.secretKey != "" {
+		auth += ", sentry_secret=" + r.dsn.secretKey
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", auth)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sentry returned %s", resp.Status)
+	}
+
+	return nil
+}
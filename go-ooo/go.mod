@@ -3,20 +3,25 @@ module go-ooo
 go 1.16
 
 require (
-	github.com/cenkalti/backoff/v4 v4.1.2 // indirect
-	github.com/ethereum/go-ethereum v1.10.12 // indirect
-	github.com/labstack/echo/v4 v4.6.1 // indirect
-	github.com/miguelmota/go-solidity-sha3 v0.1.1 // indirect
-	github.com/montanaflynn/stats v0.6.6 // indirect
+	github.com/cenkalti/backoff/v4 v4.1.2
+	github.com/ethereum/go-ethereum v1.10.12
+	github.com/fsnotify/fsnotify v1.4.9
+	github.com/golang-jwt/jwt v3.2.2+incompatible
+	github.com/karalabe/usb v0.0.0-20211005121534-4c5740d64559
+	github.com/labstack/echo/v4 v4.6.1
+	github.com/miguelmota/go-solidity-sha3 v0.1.1
+	github.com/montanaflynn/stats v0.6.6
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/prometheus/client_golang v1.11.0 // indirect
-	github.com/sirupsen/logrus v1.8.1 // indirect
-	github.com/spf13/cobra v1.2.1 // indirect
-	github.com/spf13/viper v1.8.1 // indirect
-	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519 // indirect
+	github.com/prometheus/client_golang v1.11.0
+	github.com/sirupsen/logrus v1.8.1
+	github.com/spf13/cobra v1.2.1
+	github.com/spf13/viper v1.8.1
+	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519
 	golang.org/x/net v0.0.0-20211123203042-d83791d6bcd9 // indirect
-	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211 // indirect
-	gorm.io/driver/postgres v1.2.2 // indirect
-	gorm.io/driver/sqlite v1.2.4 // indirect
-	gorm.io/gorm v1.22.3 // indirect
+	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211
+	golang.org/x/time v0.0.0-20210220033141-f8bda1e9f3ba
+	gopkg.in/yaml.v2 v2.4.0
+	gorm.io/driver/postgres v1.2.2
+	gorm.io/driver/sqlite v1.2.4
+	gorm.io/gorm v1.22.3
 )
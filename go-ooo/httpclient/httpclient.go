@@ -0,0 +1,127 @@
+// Package httpclient provides the HTTP client used for the node's outbound requests to data
+// sources - Finchains, subgraphs, exchange APIs, json feeds and subchain RPCs - so that they
+// share one pool of keep-alive connections, and the timeouts and proxy in the [http] config
+package httpclient
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// defaults used for timeouts which are not set, e.g. in configs written by older versions
+const (
+	defaultTimeout               = 15 * time.Second
+	defaultDialTimeout           = 10 * time.Second
+	defaultTlsHandshakeTimeout   = 10 * time.Second
+	defaultResponseHeaderTimeout = 10 * time.Second
+	defaultKeepAlive             = 30 * time.Second
+	defaultIdleConnTimeout       = 90 * time.Second
+)
+
+// maxDrain - most of an unread response body read before closing it, so that its connection can
+// be reused. Connections with more left unread are closed
+const maxDrain = 64 << 10
+
+var (
+	transportOnce sync.Once
+	transport     *http.Transport
+)
+
+// Transport returns the transport shared by all outbound clients, built from the [http] config
+// the first time it is used
+func Transport() *http.Transport {
+	transportOnce.Do(func() {
+		transport = newTransport()
+	})
+	return transport
+}
+
+// Timeout returns http.timeout - how long a data source request may take, including reading
+// the response
+func Timeout() time.Duration {
+	return seconds(config.HttpTimeout, defaultTimeout)
+}
+
+// New returns a client using the shared transport, which gives up on a request, including
+// reading its response, after timeout. If timeout is 0, http.timeout is used
+func New(timeout time.Duration) *http.Client {
+	if timeout == 0 {
+		timeout = Timeout()
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: Transport(),
+	}
+}
+
+// Close reads the rest of up to 64KiB of body, then closes it, so that the connection is kept
+// alive for later requests, even if the body was not read
+func Close(body io.ReadCloser) {
+	_, _ = io.CopyN(ioutil.Discard, body, maxDrain)
+	_ = body.Close()
+}
+
+// ProxyUrl parses http.proxy. It returns nil if no proxy is configured, in which case the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used
+func ProxyUrl() (*url.URL, error) {
+	proxy := viper.GetString(config.HttpProxy)
+	if proxy == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%q is not an absolute url", proxy)
+	}
+
+	return u, nil
+}
+
+func newTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   seconds(config.HttpDialTimeout, defaultDialTimeout),
+		KeepAlive: seconds(config.HttpKeepAlive, defaultKeepAlive),
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if u, err := ProxyUrl(); err != nil {
+		// fail each request, rather than silently bypassing the proxy
+		proxy = func(*http.Request) (*url.URL, error) {
+			return nil, fmt.Errorf("invalid %s: %s", config.HttpProxy, err.Error())
+		}
+	} else if u != nil {
+		proxy = http.ProxyURL(u)
+	}
+
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   seconds(config.HttpTlsHandshakeTimeout, defaultTlsHandshakeTimeout),
+		ResponseHeaderTimeout: seconds(config.HttpResponseHeaderTimeout, defaultResponseHeaderTimeout),
+		ExpectContinueTimeout: time.Second,
+		IdleConnTimeout:       seconds(config.HttpIdleConnTimeout, defaultIdleConnTimeout),
+		MaxIdleConns:          viper.GetInt(config.HttpMaxIdleConns),
+		MaxIdleConnsPerHost:   viper.GetInt(config.HttpMaxIdleConnsPerHost),
+		MaxConnsPerHost:       viper.GetInt(config.HttpMaxConnsPerHost),
+	}
+}
+
+// seconds returns key as a duration, or def if it is not set
+func seconds(key string, def time.Duration) time.Duration {
+	if s := viper.GetInt64(key); s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	return def
+}
@@ -0,0 +1,179 @@
+// Package ipfs publishes documents, such as signed answer attestations, to IPFS through the HTTP
+// API of an IPFS node or pinning service, e.g. a local Kubo node or Infura
+package ipfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/sirupsen/logrus"
+	"go-ooo/httpclient"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// addPath - the IPFS HTTP API's add call. Documents are pinned, and addressed by v1 CIDs
+const addPath = "/api/v0/add?pin=true&cid-version=1"
+
+// queueSize - documents are dropped if this many are waiting to be published
+const queueSize = 256
+
+// Config is the IPFS HTTP API to publish to. Username and Password, if set, are sent as basic
+// auth, as pinning services such as Infura require
+type Config struct {
+	ApiUrl   string
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+// document is a queued document, and the key it is reported back with once published
+type document struct {
+	key  string
+	name string
+	data []byte
+}
+
+// Publisher adds documents to IPFS in the background, in the order they were queued, calling
+// onPublished with each one's CID
+type Publisher struct {
+	cfg         Config
+	client      *http.Client
+	queue       chan document
+	onPublished func(key string, cid string)
+	logger      *logrus.Logger
+	ctx         context.Context
+}
+
+// NewPublisher returns a Publisher, which publishes until ctx is done. If cfg.ApiUrl is empty,
+// nothing is published
+func NewPublisher(ctx context.Context, logger *logrus.Logger, cfg Config, onPublished func(key string, cid string)) *Publisher {
+	p := &Publisher{
+		cfg:         cfg,
+		client:      httpclient.New(cfg.Timeout),
+		queue:       make(chan document, queueSize),
+		onPublished: onPublished,
+		logger:      logger,
+		ctx:         ctx,
+	}
+
+	if p.Enabled() {
+		go p.run()
+	}
+
+	return p
+}
+
+// Enabled returns true if an IPFS API is configured
+func (p *Publisher) Enabled() bool {
+	return p != nil && p.cfg.ApiUrl != ""
+}
+
+// Publish queues data, named name, to be added to IPFS. It does not block
+func (p *Publisher) Publish(key string, name string, data []byte) {
+	if !p.Enabled() {
+		return
+	}
+
+	select {
+	case p.queue <- document{key: key, name: name, data: data}:
+	default:
+		p.logger.WithFields(logrus.Fields{
+			"package":  "ipfs",
+			"function": "Publish",
+			"key":      key,
+		}).Warn("ipfs queue full - document dropped")
+	}
+}
+
+func (p *Publisher) run() {
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case doc := <-p.queue:
+			p.publish(doc)
+		}
+	}
+}
+
+func (p *Publisher) publish(doc document) {
+	var cid string
+	add := func() error {
+		var err error
+		cid, err = p.Add(p.ctx, doc.name, doc.data)
+		return err
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.MaxElapsedTime = 2 * time.Minute
+
+	err := backoff.Retry(add, backoff.WithContext(b, p.ctx))
+	if err != nil {
+		p.logger.WithFields(logrus.Fields{
+			"package":  "ipfs",
+			"function": "publish",
+			"key":      doc.key,
+		}).Error(err.Error())
+		return
+	}
+
+	if p.onPublished != nil {
+		p.onPublished(doc.key, cid)
+	}
+}
+
+// Add adds data, as a file named name, to IPFS and pins it, returning its CID
+func (p *Publisher) Add(ctx context.Context, name string, data []byte) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return "", backoff.Permanent(err)
+	}
+	if _, err = part.Write(data); err != nil {
+		return "", backoff.Permanent(err)
+	}
+	if err = form.Close(); err != nil {
+		return "", backoff.Permanent(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(p.cfg.ApiUrl, "/")+addPath, &body)
+	if err != nil {
+		return "", backoff.Permanent(err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if p.cfg.Username != "" || p.cfg.Password != "" {
+		req.SetBasicAuth(p.cfg.Username, p.cfg.Password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer httpclient.Close(resp.Body)
+
+	if resp.StatusCode >= 500 {
+		return "", fmt.Errorf("ipfs api returned %s", resp.Status)
+	}
+	if resp.StatusCode >= 300 {
+		return "", backoff.Permanent(fmt.Errorf("ipfs api returned %s", resp.Status))
+	}
+
+	var added struct {
+		Hash string `json:"Hash"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&added); err != nil {
+		return "", err
+	}
+	if added.Hash == "" {
+		return "", errors.New("ipfs api returned no cid")
+	}
+
+	return added.Hash, nil
+}
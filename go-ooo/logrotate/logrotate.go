@@ -0,0 +1,183 @@
+package logrotate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// Writer is an io.Writer which writes to a file, rotating it once it reaches maxSize bytes.
+// Rotated files are renamed with a timestamp, optionally gzipped, and only the most recent
+// maxBackups are kept
+type Writer struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	compress   bool
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewWriter opens, or creates, the log file at path. maxSize <= 0 disables rotation and
+// maxBackups <= 0 keeps all rotated files
+func NewWriter(path string, maxSize int64, maxBackups int, compress bool) (*Writer, error) {
+	w := &Writer{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		compress:   compress,
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var rotateErr error
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		// a failed rotation leaves the current file open, so p is still written to it
+		rotateErr = w.rotate()
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	if err == nil {
+		err = rotateErr
+	}
+	return n, err
+}
+
+// Close closes the current log file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// rotate must be called with w.mu held. The current file is only closed once the new one is
+// open, so if rotation fails logging continues to the current file, under its own name
+func (w *Writer) rotate() error {
+	current := w.file
+
+	backup := fmt.Sprintf("%s.%s", w.path, time.Now().Format(backupTimeFormat))
+	if err := os.Rename(w.path, backup); err != nil {
+		return err
+	}
+
+	if err := w.open(); err != nil {
+		_ = os.Rename(backup, w.path)
+		return err
+	}
+	_ = current.Close()
+
+	// compress and prune in the background so that logging is not blocked
+	go w.cleanUp(backup)
+
+	return nil
+}
+
+func (w *Writer) cleanUp(backup string) {
+	if w.compress {
+		if err := compressFile(backup); err == nil {
+			_ = os.Remove(backup)
+		}
+	}
+
+	if w.maxBackups <= 0 {
+		return
+	}
+
+	matches, err := filepath.Glob(w.path + ".*")
+	if err != nil {
+		return
+	}
+
+	// only prune finished backups, not files still being compressed
+	var backups []string
+	for _, m := range matches {
+		if !strings.HasSuffix(m, ".tmp") {
+			backups = append(backups, m)
+		}
+	}
+
+	if len(backups) <= w.maxBackups {
+		return
+	}
+
+	// timestamps sort chronologically
+	sort.Strings(backups)
+	for _, old := range backups[:len(backups)-w.maxBackups] {
+		_ = os.Remove(old)
+	}
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	if err = dst.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, path+".gz")
+}
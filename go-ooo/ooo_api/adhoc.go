@@ -2,17 +2,26 @@ package ooo_api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/montanaflynn/stats"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database"
+	"go-ooo/errcodes"
+	"go-ooo/httpclient"
 	"go-ooo/utils"
 	"io/ioutil"
 	"math"
 	"math/big"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // MinLiquidity ToDo - make configurable in config.toml
@@ -23,8 +32,68 @@ const MinLiquidity = 30000
 // MinTxCount - min tx count a pair should have for the DEX pair search
 const MinTxCount = 250
 
-// currently supported DEXs for ad-hoc queries
+// GraphQlMaxEntities - max number of entities The Graph will return for a single collection query
+const GraphQlMaxEntities = 1000
+
+// GraphQlPairBatchSize - number of known pairs to refresh in a single batched subgraph query
+const GraphQlPairBatchSize = 100
+
+// getQlApis returns the DEXs queried for ad-hoc requests, with any subgraph url overrides applied.
+// A DEX whose url is overridden with an empty url is not queried
 func getQlApis() []map[string]string {
+	if !config.FeatureEnabled(config.FeaturesDex) {
+		return nil
+	}
+	return configuredQlApis()
+}
+
+// configuredQlApis returns the subgraph DEXes not disabled by jobs.subgraph_urls, whether or not
+// the DEX sources are enabled
+func configuredQlApis() []map[string]string {
+	overrides := viper.GetStringMapString(config.JobsSubgraphUrls)
+
+	var apis []map[string]string
+	for _, api := range defaultQlApis() {
+		if url, ok := overrides[api["name"]]; ok {
+			if url == "" {
+				continue
+			}
+			api["url"] = url
+		}
+		apis = append(apis, api)
+	}
+
+	return apis
+}
+
+// SubgraphDexNames returns the name of each DEX which can be queried for ad-hoc requests
+func SubgraphDexNames() []string {
+	var names []string
+	for _, api := range defaultQlApis() {
+		names = append(names, api["name"])
+	}
+	return names
+}
+
+// usesPools returns true if the DEX's subgraph has the Uniswap V3 schema, of pools and their total
+// value locked, rather than pairs and their reserves
+func usesPools(api map[string]string) bool {
+	return api["pairs_endpoint"] == "pools"
+}
+
+// dexUsesPools returns true if the named DEX's subgraph has the Uniswap V3 schema
+func dexUsesPools(dexName string) bool {
+	for _, api := range defaultQlApis() {
+		if api["name"] == dexName {
+			return usesPools(api)
+		}
+	}
+	return false
+}
+
+// currently supported DEXs for ad-hoc queries. Deployments of a DEX on other chains are named
+// for the DEX and the chain, e.g. sushiswap_polygon, and share its subgraph schema
+func defaultQlApis() []map[string]string {
 	return []map[string]string{
 		{
 			"name":              "shibaswap",
@@ -86,6 +155,30 @@ func getQlApis() []map[string]string {
 			"chain":             "polygon",
 			"blocks_in_one_min": "20",
 		},
+		{
+			"name":              "sushiswap_polygon",
+			"url":               "https://api.thegraph.com/subgraphs/name/sushiswap/matic-exchange",
+			"pairs_endpoint":    "pairs",
+			"pair_endpoint":     "pair",
+			"tokens_endpoint":   "tokens",
+			"token_order_by":    "txCount",
+			"pairs_order_by":    "reserveUSD",
+			"tx_count":          "txCount",
+			"chain":             "polygon",
+			"blocks_in_one_min": "30",
+		},
+		{
+			"name":              "uniswapv3_polygon",
+			"url":               "https://api.thegraph.com/subgraphs/name/ianlapham/uniswap-v3-polygon",
+			"pairs_endpoint":    "pools",
+			"pair_endpoint":     "pool",
+			"tokens_endpoint":   "tokens",
+			"token_order_by":    "txCount",
+			"pairs_order_by":    "totalValueLockedUSD",
+			"tx_count":          "txCount",
+			"chain":             "polygon",
+			"blocks_in_one_min": "30",
+		},
 		{
 			"name":              "pancakeswap",
 			"url":               "https://api.bscgraph.org/subgraphs/name/cakeswap",
@@ -98,6 +191,18 @@ func getQlApis() []map[string]string {
 			"chain":             "bsc",
 			"blocks_in_one_min": "20",
 		},
+		{
+			"name":              "sushiswap_bsc",
+			"url":               "https://api.thegraph.com/subgraphs/name/sushiswap/bsc-exchange",
+			"pairs_endpoint":    "pairs",
+			"pair_endpoint":     "pair",
+			"tokens_endpoint":   "tokens",
+			"token_order_by":    "txCount",
+			"pairs_order_by":    "reserveUSD",
+			"tx_count":          "txCount",
+			"chain":             "bsc",
+			"blocks_in_one_min": "20",
+		},
 		{
 			"name":              "honeyswap",
 			"url":               "https://api.thegraph.com/subgraphs/name/1hive/honeyswap-xdai",
@@ -132,22 +237,36 @@ func getChains() []string {
 	return chains
 }
 
-func (o *OOOApi) getCurrentBlockNumForChain(chain string) (uint64, error) {
+func (o *OOOApi) getSubchainClient(chain string) *ethclient.Client {
 	switch chain {
 	case "eth":
-		return o.subchainEthClient.BlockNumber(o.ctx)
+		return o.subchainEthClient
 	case "polygon":
-		return o.subchainPolygonClient.BlockNumber(o.ctx)
+		return o.subchainPolygonClient
 	case "bsc":
-		return o.subchainBscClient.BlockNumber(o.ctx)
+		return o.subchainBscClient
 	case "xdai":
-		return o.subchainXdaiClient.BlockNumber(o.ctx)
+		return o.subchainXdaiClient
 	}
 
-	return 0, nil
+	return nil
+}
+
+func (o *OOOApi) getCurrentBlockNumForChain(ctx context.Context, chain string) (uint64, error) {
+	client := o.getSubchainClient(chain)
+	if client == nil {
+		return 0, nil
+	}
+
+	return client.BlockNumber(ctx)
 }
 
 func (o *OOOApi) UpdateDexTokensAndPairs() {
+	if o.mock != nil {
+		// no data sources are queried in mock source mode
+		return
+	}
+
 	o.logger.WithFields(logrus.Fields{
 		"package":  "ooo_api",
 		"function": "UpdateDexTokensAndPairs",
@@ -160,69 +279,307 @@ func (o *OOOApi) UpdateDexTokensAndPairs() {
 	}
 }
 
+// updateAllTokensAndPairs syncs the DEX's pairs from its subgraph. Only the pairs changed since
+// the dex's sync cursor are fetched when syncing incrementally, and the cursor is moved to the
+// subgraph's indexed block once the sync is complete
 func (o *OOOApi) updateAllTokensAndPairs(api map[string]string) {
+	o.syncCustomPairs(api)
 
-	pairs := o.getPairsFromGraphQl(api, 0)
+	// the indexed block is read before the pairs, so that changes indexed during the sync are
+	// fetched again by the next one
+	indexedBlock, err := o.subgraphIndexedBlock(api)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "updateAllTokensAndPairs",
+			"dex":      api["name"],
+		}).Warn("cannot get subgraph indexed block, fetching every pair: ", err.Error())
+		indexedBlock = 0
+	}
+
+	changedSince := uint64(0)
+	if indexedBlock > 0 {
+		changedSince = o.dexSyncFrom(api["name"])
+	}
+
+	totalPairs, err := o.discoverPairs(api, changedSince)
+	if err != nil && changedSince > 0 {
+		// e.g. the subgraph's graph node does not support _change_block filters
+		o.logger.WithFields(logrus.Fields{
+			"package":       "ooo_api",
+			"function":      "updateAllTokensAndPairs",
+			"dex":           api["name"],
+			"changed_since": changedSince,
+		}).Warn("incremental pair discovery failed, fetching every pair: ", err.Error())
+		changedSince = 0
+		totalPairs, err = o.discoverPairs(api, 0)
+	}
+
+	if err != nil {
+		return
+	}
 
 	o.logger.WithFields(logrus.Fields{
-		"package":   "ooo_api",
-		"function":  "updateAllTokensAndPairs",
-		"dex":       api["name"],
-		"num_pairs": len(pairs),
-	}).Info("found pairs")
+		"package":       "ooo_api",
+		"function":      "updateAllTokensAndPairs",
+		"dex":           api["name"],
+		"total_pairs":   totalPairs,
+		"changed_since": changedSince,
+	}).Info("pair discovery complete")
 
-	o.updatePairsInDb(pairs, api["name"], api["chain"])
+	refreshed := o.refreshKnownPairs(api, changedSince)
 
-	skip := uint64(1000)
+	mode := dexSyncModeFull
+	if changedSince > 0 {
+		mode = dexSyncModeIncremental
+	}
+	dexSyncs.WithLabelValues(api["name"], mode).Inc()
+
+	if indexedBlock == 0 || !refreshed {
+		// the next sync starts from the same cursor
+		return
+	}
 
-	for len(pairs) == 1000 {
+	if err := o.db.UpdateDexSyncCursor(api["name"], indexedBlock, changedSince == 0); err != nil {
 		o.logger.WithFields(logrus.Fields{
 			"package":  "ooo_api",
 			"function": "updateAllTokensAndPairs",
 			"dex":      api["name"],
-		}).Info("pairs > 1000. Get next pairs")
+			"block":    indexedBlock,
+		}).Error(err.Error())
+	}
+}
 
-		pairs = o.getPairsFromGraphQl(api, skip)
-		skip += 1000
+// discoverPairs stores the DEX's pairs with enough liquidity and trades, changed at or after block
+// changedSince, or all of them if it is 0. Returns the number of pairs found
+func (o *OOOApi) discoverPairs(api map[string]string, changedSince uint64) (int, error) {
+
+	// The Graph caps each collection query at GraphQlMaxEntities. Paginate
+	// with an id cursor rather than skip, since skip is also capped and any
+	// pairs beyond the limit would otherwise be silently dropped.
+	lastId := ""
+	totalPairs := 0
+
+	for {
+		pairs, err := o.getPairsFromGraphQl(api, lastId, changedSince)
+
+		if err != nil {
+			o.logger.WithFields(logrus.Fields{
+				"package":       "ooo_api",
+				"function":      "discoverPairs",
+				"dex":           api["name"],
+				"last_id":       lastId,
+				"total_pairs":   totalPairs,
+				"changed_since": changedSince,
+			}).Error(fmt.Sprintf("pair discovery incomplete: %s", err.Error()))
+			return totalPairs, err
+		}
 
 		o.logger.WithFields(logrus.Fields{
 			"package":   "ooo_api",
-			"function":  "updateAllTokensAndPairs",
+			"function":  "discoverPairs",
 			"dex":       api["name"],
 			"num_pairs": len(pairs),
-		}).Info("found more pairs")
+			"last_id":   lastId,
+		}).Info("found pairs")
 
 		o.updatePairsInDb(pairs, api["name"], api["chain"])
+
+		totalPairs += len(pairs)
+
+		if len(pairs) < GraphQlMaxEntities {
+			return totalPairs, nil
+		}
+
+		lastId = pairs[len(pairs)-1].Id
 	}
 }
 
-func (o *OOOApi) getPairsFromGraphQl(api map[string]string, skip uint64) []GraphQlPairContent {
-	query := generatePairsListQuery(api["pairs_endpoint"], api["pairs_order_by"], api["tx_count"], skip)
+func (o *OOOApi) getPairsFromGraphQl(api map[string]string, lastId string, changedSince uint64) ([]GraphQlPairContent, error) {
+	query := generatePairsListQuery(api["pairs_endpoint"], api["pairs_order_by"], api["tx_count"], lastId, changedSince)
 
 	var decodedResponse GraphQlPairsResponse
 
-	o.runQuery(query, api["url"], &decodedResponse)
+	err := o.runQuery(query, api, QueryClassSync, &decodedResponse)
+
+	if err != nil {
+		return nil, err
+	}
 
 	pairs := decodedResponse.Data.Pairs
-	if api["name"] == "uniswapv3" {
+	if usesPools(api) {
 		pairs = decodedResponse.Data.Pools
 	}
 
-	return pairs
+	return pairs, nil
+}
+
+// refreshKnownPairs re-queries the liquidity of pairs already stored in the database,
+// batching GraphQlPairBatchSize pairs per subgraph request. Pairs which have since dropped
+// below MinLiquidity are not returned by the discovery query, so without this their stored
+// reserves would never be updated. If changedSince is not 0, only the pairs changed at or
+// after that block are returned, and updated. Returns false if any batch failed
+func (o *OOOApi) refreshKnownPairs(api map[string]string, changedSince uint64) bool {
+	knownPairs, err := o.db.GetDexPairsByDexName(api["name"])
+
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "refreshKnownPairs",
+			"dex":      api["name"],
+		}).Error(err.Error())
+		return false
+	}
+
+	// 24 hour volumes are skipped if the chain's block cannot be read
+	currentBlock, err := o.getCurrentBlockNumForChain(o.ctx, api["chain"])
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "refreshKnownPairs",
+			"dex":      api["name"],
+		}).Warn("cannot get current block, pair volumes not updated: ", err.Error())
+		currentBlock = 0
+	}
+
+	refreshed := true
+	for start := 0; start < len(knownPairs); start += GraphQlPairBatchSize {
+		end := start + GraphQlPairBatchSize
+		if end > len(knownPairs) {
+			end = len(knownPairs)
+		}
+
+		ids := make([]string, 0, end-start)
+		for _, p := range knownPairs[start:end] {
+			ids = append(ids, p.ContractAddress)
+		}
+
+		query := generatePairsByIdQuery(ids, api["pairs_endpoint"], api["pairs_order_by"], 0, changedSince)
+
+		var decodedResponse GraphQlPairsResponse
+
+		err = o.runQuery(query, api, QueryClassSync, &decodedResponse)
+
+		if err != nil {
+			o.logger.WithFields(logrus.Fields{
+				"package":  "ooo_api",
+				"function": "refreshKnownPairs",
+				"dex":      api["name"],
+				"batch":    start / GraphQlPairBatchSize,
+			}).Error(err.Error())
+			refreshed = false
+			continue
+		}
+
+		pairs := decodedResponse.Data.Pairs
+		if usesPools(api) {
+			pairs = decodedResponse.Data.Pools
+		}
+
+		for _, pair := range pairs {
+			dexReserveUSD := pair.ReserveUSD
+			if usesPools(api) {
+				dexReserveUSD = pair.TotalValueLockedUSD
+			}
+
+			reserve, err := utils.ParseBigFloat(dexReserveUSD)
+			if err != nil {
+				continue
+			}
+			reserveUsd, _ := reserve.Float64()
+
+			_ = o.db.UpdateDexPairReserveUsd(pair.Id, api["name"], reserveUsd)
+		}
+
+		if len(pairs) == 0 {
+			// none of the batch changed since the last sync
+			continue
+		}
+
+		// only the pairs returned, which are all of the batch unless syncing incrementally
+		returned := make([]string, 0, len(pairs))
+		for _, pair := range pairs {
+			returned = append(returned, pair.Id)
+		}
+		o.refreshPairVolumes(api, returned, pairs, currentBlock)
+	}
+
+	return refreshed
+}
+
+// refreshPairVolumes stores the 24 hour trade volume of pairs, the difference between their
+// cumulative volume now and at the block a day ago. Pairs created within the day have traded
+// their whole cumulative volume in it
+func (o *OOOApi) refreshPairVolumes(api map[string]string, ids []string, current []GraphQlPairContent, currentBlock uint64) {
+	blocksPerMin, err := strconv.Atoi(api["blocks_in_one_min"])
+	if err != nil {
+		blocksPerMin = 10
+	}
+	dayBlocks := uint64(blocksPerMin) * 60 * 24
+	if currentBlock <= dayBlocks {
+		// no block, or a chain younger than a day
+		return
+	}
+
+	query := generatePairsByIdQuery(ids, api["pairs_endpoint"], api["pairs_order_by"], currentBlock-dayBlocks, 0)
+
+	var decodedResponse GraphQlPairsResponse
+
+	if err = o.runQuery(query, api, QueryClassSync, &decodedResponse); err != nil {
+		// e.g. the subgraph has pruned the history
+		o.logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "refreshPairVolumes",
+			"dex":      api["name"],
+			"block":    currentBlock - dayBlocks,
+		}).Warn("cannot query pair volumes a day ago: ", err.Error())
+		return
+	}
+
+	dayAgo := decodedResponse.Data.Pairs
+	if usesPools(api) {
+		dayAgo = decodedResponse.Data.Pools
+	}
+
+	previous := make(map[string]float64)
+	for _, pair := range dayAgo {
+		if v, err := utils.ParseBigFloat(pair.VolumeUSD); err == nil {
+			previous[pair.Id], _ = v.Float64()
+		}
+	}
+
+	for _, pair := range current {
+		v, err := utils.ParseBigFloat(pair.VolumeUSD)
+		if err != nil {
+			continue
+		}
+		volume, _ := v.Float64()
+		volume -= previous[pair.Id]
+		if volume < 0 {
+			volume = 0
+		}
+
+		_ = o.db.UpdateDexPairVolumeUsd(pair.Id, api["name"], volume)
+	}
 }
 
 func (o *OOOApi) updatePairsInDb(pairs []GraphQlPairContent, dex, chain string) {
-	for _, pair := range pairs {
-		// pair.Token0.Id is the token's contract address
-		t0Db, _ := o.db.FindOrInsertNewTokenContract(pair.Token0.Symbol, pair.Token0.Id, chain)
-		t1Db, _ := o.db.FindOrInsertNewTokenContract(pair.Token1.Symbol, pair.Token1.Id, chain)
+	syncs := make([]database.DexPairSync, 0, len(pairs))
 
-		t0DtDb, _ := o.db.FindOrInsertNewDexToken(pair.Token0.Symbol, t0Db.ID, dex, chain)
-		t1DtDb, _ := o.db.FindOrInsertNewDexToken(pair.Token1.Symbol, t1Db.ID, dex, chain)
+	for _, pair := range pairs {
+		if err := validateGraphQlPairTokens(dex, pair); err != nil {
+			o.logger.WithFields(logrus.Fields{
+				"package":  "ooo_api",
+				"function": "updatePairsInDb",
+				"dex":      dex,
+				"pair":     pair.Id,
+			}).Warn(err.Error())
+			continue
+		}
 
 		dexReserveUSD := pair.ReserveUSD
 		// todo - betterize
-		if dex == "uniswapv3" {
+		if dexUsesPools(dex) {
 			dexReserveUSD = pair.TotalValueLockedUSD
 		}
 
@@ -230,75 +587,263 @@ func (o *OOOApi) updatePairsInDb(pairs []GraphQlPairContent, dex, chain string)
 		reserve, _ := utils.ParseBigFloat(dexReserveUSD)
 		reserveUsd, _ := reserve.Float64()
 
-		// todo - update latest liquidity
-		_, _ = o.db.FindOrInsertNewDexPair(pair.Token0.Symbol, pair.Token1.Symbol, pair.Id, dex, t0DtDb.ID, t1DtDb.ID, reserveUsd)
+		// pair.Token0.Id is the token's contract address
+		syncs = append(syncs, database.DexPairSync{
+			T0Symbol:        pair.Token0.Symbol,
+			T0Address:       pair.Token0.Id,
+			T1Symbol:        pair.Token1.Symbol,
+			T1Address:       pair.Token1.Id,
+			ContractAddress: pair.Id,
+			ReserveUsd:      reserveUsd,
+		})
+	}
+
+	// todo - update latest liquidity
+	if err := o.db.SyncDexPairs(dex, chain, syncs); err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":   "ooo_api",
+			"function":  "updatePairsInDb",
+			"dex":       dex,
+			"num_pairs": len(syncs),
+		}).Error(err.Error())
 	}
 }
 
-func (o *OOOApi) QueryAdhoc(endpoint string, requestId string) (string, error) {
+// QueryAdhoc calculates the price for an ad-hoc request from DEX subgraph data. The names
+// of the DEXs which contributed to the price are also returned
+func (o *OOOApi) QueryAdhoc(ctx context.Context, endpoint string, requestId string) (string, []string, error) {
+	return o.queryAdhoc(ctx, endpoint, requestId, nil)
+}
+
+func (o *OOOApi) queryAdhoc(ctx context.Context, endpoint string, requestId string, explain *PriceExplanation) (string, []string, error) {
 	qlApiUrls := getQlApis()
 
+	base, target, _, subtype, supp1, _, _, err := ParseEndpoint(endpoint)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	// BASE.TARGET.AD.AT.<unix timestamp> requests the price as of the timestamp,
+	// using subgraph snapshots at each chain's block for that time
+	historical := subtype == HistoricalSubType
+	var timestamp int64
+	if historical {
+		timestamp, err = parseHistoricalTimestamp(supp1)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
 	currentBlocks := make(map[string]uint64)
 	for _, api := range qlApiUrls {
 		if _, ok := currentBlocks[api["chain"]]; !ok {
-			currentBlock, _ := o.getCurrentBlockNumForChain(api["chain"])
+			currentBlock, _ := o.getCurrentBlockNumForChain(ctx, api["chain"])
+			if historical {
+				currentBlock, err = o.getBlockNumAtTimestamp(ctx, api["chain"], timestamp, currentBlock)
+				if err != nil {
+					o.logger.WithFields(logrus.Fields{
+						"package":   "ooo_api",
+						"function":  "QueryAdhoc",
+						"action":    "getBlockNumAtTimestamp",
+						"requestId": requestId,
+						"chain":     api["chain"],
+						"timestamp": timestamp,
+					}).Warn(err.Error())
+					currentBlock = 0
+				}
+			}
 			currentBlocks[api["chain"]] = currentBlock
 		}
 	}
 
-	base, target, _, _, _, _, _, err := ParseEndpoint(endpoint)
-
-	if err != nil {
-		return "", err
-	}
-
 	o.logger.WithFields(logrus.Fields{
-		"package":   "ooo_api",
-		"function":  "QueryAdhoc",
-		"action":    "ParseEndpoint",
-		"requestId": requestId,
-		"endpoint":  endpoint,
-		"base":      base,
-		"target":    target,
+		"package":    "ooo_api",
+		"function":   "QueryAdhoc",
+		"action":     "ParseEndpoint",
+		"requestId":  requestId,
+		"endpoint":   endpoint,
+		"base":       base,
+		"target":     target,
+		"historical": historical,
+		"timestamp":  timestamp,
 	}).Debug("AdHoc endpoint parsed")
 
 	var rawPrices []float64
-	var outliersRemoved []float64
-	priceCount := 0
-	total := big.NewInt(0)
+	// rawExact are rawPrices as exact rationals, in exact math mode
+	var rawExact []*big.Rat
+	// rawSources is the dex each raw price came from
+	var rawSources []string
+	// rawImpacts is the price impact on the pool each raw price came from, if known
+	var rawImpacts []float64
+	var rejections []error
+
+	// fiat targets are priced against stablecoins on the DEXs, and each stablecoin
+	// quote is then converted to the target currency through USD
+	dexTargets := []string{target}
+	fxRate := float64(1)
+	if IsFiatCurrency(target) {
+		dexTargets = UsdStablecoins
+		fxRate, err = o.quoteConversionRate(QuoteUsd, target, historical, timestamp)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	// quoteRates converts prices quoted in each of the dexTargets to the target. A
+	// stablecoin that cannot be converted, e.g. depegged, has its quotes rejected
+	quoteRates := map[string]float64{target: 1}
+	if IsFiatCurrency(target) {
+		for _, t := range dexTargets {
+			usdRate, err := o.usdRate(t, historical, timestamp)
+			if err != nil {
+				rejections = append(rejections, err)
+				continue
+			}
+			quoteRates[t] = usdRate * fxRate
+		}
+	}
+
+	var sources []string
 
 	for _, a := range qlApiUrls {
-		dexPrices := o.getPairPricesFromDex(base, target, a, currentBlocks[a["chain"]])
-		for _, price := range dexPrices {
-			if price != 0 {
-				rawPrices = append(rawPrices, price)
+		if !o.sourceAllowed(ctx, base, target, a["name"]) {
+			continue
+		}
+		if historical && currentBlocks[a["chain"]] == 0 {
+			// no block found for the timestamp on this chain
+			continue
+		}
+		dexHasPrices := false
+		for _, t := range dexTargets {
+			rate, ok := quoteRates[t]
+			if !ok {
+				continue
 			}
+			start := time.Now()
+			dexPrices, dexRejections := o.getPairPricesFromDex(ctx, base, t, a, currentBlocks[a["chain"]], historical)
+			explain.recordLatency(a["name"], start)
+			for _, p := range dexPrices {
+				if err := o.checkPriceBound(a["name"], base, target, p.value*rate); err != nil {
+					rejections = append(rejections, err)
+					continue
+				}
+				dexHasPrices = true
+				rawPrices = append(rawPrices, p.value*rate)
+				rawSources = append(rawSources, a["name"])
+				rawImpacts = append(rawImpacts, p.impact)
+				if o.exactMath {
+					rawExact = append(rawExact, new(big.Rat).Mul(p.exact, ratFromFloat(rate)))
+				}
+			}
+			rejections = append(rejections, dexRejections...)
+		}
+		if dexHasPrices {
+			sources = append(sources, a["name"])
 		}
 	}
 
+	// Uniswap V3 TWAPs, read on chain, are averaged alongside the subgraph prices
+	if o.sourceAllowed(ctx, base, target, TwapSourceName) {
+		twapHasPrices := false
+		for _, t := range dexTargets {
+			rate, ok := quoteRates[t]
+			if !ok {
+				continue
+			}
+			start := time.Now()
+			twapPrices, twapRejections := o.getTwapPrices(ctx, base, t, currentBlocks, historical)
+			explain.recordLatency(TwapSourceName, start)
+			for _, p := range twapPrices {
+				if err := o.checkPriceBound(TwapSourceName, base, target, p.value*rate); err != nil {
+					rejections = append(rejections, err)
+					continue
+				}
+				twapHasPrices = true
+				rawPrices = append(rawPrices, p.value*rate)
+				rawSources = append(rawSources, TwapSourceName)
+				rawImpacts = append(rawImpacts, p.impact)
+				if o.exactMath {
+					rawExact = append(rawExact, new(big.Rat).Mul(p.exact, ratFromFloat(rate)))
+				}
+			}
+			rejections = append(rejections, twapRejections...)
+		}
+		if twapHasPrices {
+			sources = append(sources, TwapSourceName)
+		}
+	}
+
+	if ctx.Err() != nil {
+		// the prices fetched before the request was cancelled are not aggregated
+		return "", nil, ctx.Err()
+	}
+
+	explain.setFxRate(fxRate)
+	explain.reject(rejections)
+
+	if len(rejections) > 0 {
+		o.logger.WithFields(logrus.Fields{
+			"package":       "ooo_api",
+			"function":      "QueryAdhoc",
+			"action":        "validate subgraph data",
+			"requestId":     requestId,
+			"num_accepted":  len(rawPrices),
+			"num_rejected":  len(rejections),
+			"rejected_data": summariseRejections(rejections),
+		}).Debug("subgraph data rejected")
+	}
+
+	if len(rawPrices) == 0 {
+		if len(rejections) > 0 {
+			return "", nil, errcodes.Errorf(errcodes.ErrInsufficientSources, "no valid prices: %s", summariseRejections(rejections))
+		}
+		return "", nil, errcodes.Errorf(errcodes.ErrInsufficientSources, "no valid prices: pair not found on any dex")
+	}
+
+	if o.exactMath {
+		return o.meanAdhocPricesExact(base, target, rawPrices, rawExact, rawSources, rawImpacts, sources, fxRate, explain)
+	}
+	return o.meanAdhocPrices(base, target, rawPrices, rawSources, rawImpacts, sources, fxRate, explain)
+}
+
+// meanAdhocPrices is queryAdhoc's mean of the DEX prices. Outliers are removed with the Chauvenet
+// criterion, and each remaining price scaled to the answer decimals before the mean is taken
+func (o *OOOApi) meanAdhocPrices(base string, target string, rawPrices []float64, rawSources []string,
+	rawImpacts []float64, sources []string, fxRate float64, explain *PriceExplanation) (string, []string, error) {
+
+	var outliersRemoved []float64
+	priceCount := 0
+	total := big.NewInt(0)
+
 	mean, err := stats.Mean(rawPrices)
 
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	stdDev, err := stats.StandardDeviation(rawPrices)
 
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	dMax := float64(3)
+	dMax := o.dMax
 	chauvenetUsed := false
+	explained := make([]ExplainedValue, len(rawPrices))
 
 	// remove outliers with Chauvenet Criterion, but only if stdDev > 0
 	// as some pair prices are too small to calculate stdDev
-	for _, p := range rawPrices {
+	for i, p := range rawPrices {
+		explained[i] = ExplainedValue{Source: rawSources[i], Value: p, PriceImpact: rawImpacts[i]}
 		if stdDev > 0 {
 			chauvenetUsed = true
 			d := math.Abs(p-mean) / stdDev
+			explained[i].Deviation = d
 			if dMax > d {
 				outliersRemoved = append(outliersRemoved, p)
+			} else {
+				explained[i].Excluded = ExcludedOutlier
 			}
 		} else {
 			// prices are too small to use Chauvenet Criterion
@@ -307,20 +852,47 @@ func (o *OOOApi) QueryAdhoc(endpoint string, requestId string) (string, error) {
 	}
 
 	// calculate mean from data set with outliers removed
-	for _, o := range outliersRemoved {
-		p := big.NewFloat(o)
-		wei := utils.EtherToWei(p)
-		if wei.Cmp(big.NewInt(0)) > 0 {
-			total = new(big.Int).Add(total, wei)
-			priceCount++
+	belowPrecision := 0
+	for _, price := range outliersRemoved {
+		scaled, err := utils.ScaleToDecimalsRounded(big.NewFloat(price), o.answerDecimals, o.answerRounding)
+		if err != nil {
+			if errors.Is(err, utils.ErrBelowPrecision) {
+				belowPrecision++
+				markExplainedValue(explained, price, ExcludedBelowPrecision)
+				continue
+			}
+			// never submit a truncated number
+			return "", nil, fmt.Errorf("cannot scale price %v to %d decimals: %s", price, o.answerDecimals, err.Error())
 		}
+		total = new(big.Int).Add(total, scaled)
+		priceCount++
+	}
+
+	// every remaining price has an equal share of the mean
+	for i := range explained {
+		if explained[i].Excluded == "" && priceCount > 0 {
+			explained[i].Weight = 1 / float64(priceCount)
+		}
+	}
+	explain.addValues(explained)
+	explain.setStats(mean, stdDev, dMax)
+	if chauvenetUsed {
+		explain.setMethod("mean of DEX prices, outliers removed with the Chauvenet criterion")
+	} else {
+		explain.setMethod("mean of DEX prices, no outliers removed as the standard deviation is 0")
 	}
 
 	if total.Cmp(big.NewInt(0)) <= 0 {
-		return "", errors.New("cannot calculate mean, price is zero")
+		if belowPrecision > 0 {
+			return "", nil, errcodes.Errorf(errcodes.ErrInvalidPrice, "cannot calculate mean, prices are below %d decimals precision", o.answerDecimals)
+		}
+		return "", nil, errcodes.Errorf(errcodes.ErrInvalidPrice, "cannot calculate mean, price is zero")
 	}
 
-	meanPrice := new(big.Int).Div(total, big.NewInt(int64(priceCount)))
+	meanPrice, err := utils.ScaleRatToDecimalsRounded(new(big.Rat).SetFrac(total, big.NewInt(int64(priceCount))), 0, o.answerRounding)
+	if err != nil {
+		return "", nil, err
+	}
 
 	o.logger.WithFields(logrus.Fields{
 		"package":            "ooo_api",
@@ -333,36 +905,51 @@ func (o *OOOApi) QueryAdhoc(endpoint string, requestId string) (string, error) {
 		"raw_prices_mean":    mean,
 		"raw_std_dev":        stdDev,
 		"final_wei_mean":     meanPrice.String(),
+		"answer_decimals":    o.answerDecimals,
+		"below_precision":    belowPrecision,
 		"chauvenet_used":     chauvenetUsed,
+		"fx_rate":            fxRate,
 	}).Debug("price stats")
 
-	return meanPrice.String(), nil
+	return meanPrice.String(), sources, nil
 }
 
-func (o *OOOApi) processPriceData(base string, target string, dexName string, pair GraphQlPairContent) float64 {
+// dexPrice - a validated subgraph price. exact is only set in exact math mode. impact is the
+// percent price impact of a jobs.dex_impact_notional trade on the pool, if known
+type dexPrice struct {
+	value  float64
+	exact  *big.Rat
+	impact float64
+}
+
+// processPriceData validates a single subgraph pair snapshot and returns the price for base/target.
+// A non-nil error is returned if the snapshot is rejected, e.g. with less than minLiquidity USD of
+// liquidity, and is recorded by the caller.
+func (o *OOOApi) processPriceData(base string, target string, dexName string, pair GraphQlPairContent, minLiquidity float64) (dexPrice, error) {
 	price := float64(0)
 
 	// check reserve USD and reject if < MIN_LIQUIDITY
 	dexRes := pair.ReserveUSD
 	// todo - betterize
-	if dexName == "uniswapv3" {
+	if dexUsesPools(dexName) {
 		dexRes = pair.TotalValueLockedUSD
 	}
 
-	limit := big.NewFloat(MinLiquidity)
+	limit := big.NewFloat(minLiquidity)
 
-	reserve, err := utils.ParseBigFloat(dexRes)
+	reserve, token0Price, token1Price, err := validateGraphQlPairPrices(dexName, pair, dexRes)
 
 	if err != nil {
 		o.logger.WithFields(logrus.Fields{
 			"package":  "ooo_api",
 			"function": "processPriceData",
+			"action":   "validate subgraph data",
 			"dex":      dexName,
 			"base":     base,
 			"target":   target,
 			"dexRes":   dexRes,
-		}).Error(err.Error())
-		return price
+		}).Debug(err.Error())
+		return dexPrice{}, err
 	}
 
 	if reserve.Cmp(limit) == -1 {
@@ -375,63 +962,102 @@ func (o *OOOApi) processPriceData(base string, target string, dexName string, pa
 			"reserve":  reserve.String(),
 			"price":    price,
 		}).Warn("low liquidity")
-		return price
-	}
-
-	priceBf := big.NewFloat(MinLiquidity)
-
-	if base == pair.Token0.Symbol && target == pair.Token1.Symbol {
-		priceBf, err = utils.ParseBigFloat(pair.Token1Price)
-	} else {
-		priceBf, err = utils.ParseBigFloat(pair.Token0Price)
+		return dexPrice{}, newValidationError(dexName, "liquidity", "below minimum")
 	}
 
+	reserveUsd, _ := reserve.Float64()
+	impact, err := checkDexPriceImpact(dexName, reserveUsd)
 	if err != nil {
 		o.logger.WithFields(logrus.Fields{
 			"package":  "ooo_api",
 			"function": "processPriceData",
-			"action":   "utils.ParseBigFloat",
 			"dex":      dexName,
 			"base":     base,
 			"target":   target,
-			"price":    price,
-		}).Error(err)
-		return price
+			"reserve":  reserve.String(),
+			"impact":   impact,
+		}).Warn("high price impact")
+		return dexPrice{}, err
+	}
+
+	priceBf := token0Price
+	priceStr := pair.Token0Price
+
+	if base == pair.Token0.Symbol && target == pair.Token1.Symbol {
+		priceBf = token1Price
+		priceStr = pair.Token1Price
 	}
 
 	price, _ = priceBf.Float64()
 
-	return price
+	if o.exactMath {
+		// the float is only used for explanations and logs
+		exact, ok := ratFromDecimal(priceStr, priceBf)
+		if !ok {
+			return dexPrice{}, newValidationError(dexName, "price", "out of range")
+		}
+		return dexPrice{value: price, exact: exact, impact: impact}, nil
+	}
+
+	if math.IsInf(price, 0) || price == 0 {
+		return dexPrice{}, newValidationError(dexName, "price", "out of float64 range")
+	}
+
+	return dexPrice{value: price, impact: impact}, nil
 }
 
-func (o *OOOApi) getPairPricesFromDex(base string, target string, api map[string]string, currentBlock uint64) []float64 {
+// getPairPricesFromDex returns validated pair prices for the 10 minutes up to currentBlock. If historical is
+// true, the most recent snapshot is also taken at currentBlock rather than the latest indexed block
+func (o *OOOApi) getPairPricesFromDex(ctx context.Context, base string, target string, api map[string]string, currentBlock uint64, historical bool) ([]dexPrice, []error) {
 
-	var prices []float64
+	var prices []dexPrice
+	var rejections []error
 	// check DB for pair contract address
-	dbPairRes, _ := o.db.FindByDexPairName(base, target, api["name"])
+	dbPairRes, custom := o.findDexPair(base, target, api["name"])
 
 	if dbPairRes.ID != 0 {
-		pairPricesRes := o.getRecentPairPrices(dbPairRes.ContractAddress, api, currentBlock)
-		price0 := o.processPriceData(base, target, api["name"], pairPricesRes.P0)
-		prices = append(prices, price0)
-		price1 := o.processPriceData(base, target, api["name"], pairPricesRes.P1)
-		prices = append(prices, price1)
-		price2 := o.processPriceData(base, target, api["name"], pairPricesRes.P2)
-		prices = append(prices, price2)
-		price3 := o.processPriceData(base, target, api["name"], pairPricesRes.P3)
-		prices = append(prices, price3)
-		price4 := o.processPriceData(base, target, api["name"], pairPricesRes.P4)
-		prices = append(prices, price4)
-		price5 := o.processPriceData(base, target, api["name"], pairPricesRes.P5)
-		prices = append(prices, price5)
-		price6 := o.processPriceData(base, target, api["name"], pairPricesRes.P6)
-		prices = append(prices, price6)
-		price7 := o.processPriceData(base, target, api["name"], pairPricesRes.P7)
-		prices = append(prices, price7)
-		price8 := o.processPriceData(base, target, api["name"], pairPricesRes.P8)
-		prices = append(prices, price8)
-		price9 := o.processPriceData(base, target, api["name"], pairPricesRes.P9)
-		prices = append(prices, price9)
+		// pairs whose volume has not been synced yet are used
+		minVolume := viper.GetFloat64(config.JobsMinVolume24h)
+		if minVolume > 0 && dbPairRes.GetVolumeUpdatedAt() != 0 && dbPairRes.GetVolumeUsd24h() < minVolume {
+			o.logger.WithFields(logrus.Fields{
+				"package":  "ooo_api",
+				"function": "getPairPricesFromDex",
+				"dex":      api["name"],
+				"base":     base,
+				"target":   target,
+				"volume":   dbPairRes.GetVolumeUsd24h(),
+			}).Warn("low 24h volume")
+			rejections = append(rejections, newValidationError(api["name"], "volume", "below minimum"))
+			return prices, rejections
+		}
+
+		var pairPricesRes GraphQlAliasedPairPrices
+		err := o.querySource(ctx, api["name"], base, target, func() (err error) {
+			pairPricesRes, err = o.getRecentPairPrices(ctx, dbPairRes.ContractAddress, api, currentBlock, historical)
+			return err
+		})
+		if err != nil {
+			rejections = append(rejections, newValidationError(api["name"], "response", "query failed"))
+			return prices, rejections
+		}
+		minLiquidity := float64(MinLiquidity)
+		if custom != nil && custom.MinLiquidity > 0 {
+			minLiquidity = custom.MinLiquidity
+		}
+		for _, snapshot := range pairPricesRes.All() {
+			if custom != nil {
+				if snapshot, err = relabelCustomPair(custom, snapshot); err != nil {
+					rejections = append(rejections, err)
+					continue
+				}
+			}
+			price, err := o.processPriceData(base, target, api["name"], snapshot, minLiquidity)
+			if err != nil {
+				rejections = append(rejections, err)
+				continue
+			}
+			prices = append(prices, price)
+		}
 	} else {
 		o.logger.WithFields(logrus.Fields{
 			"package":  "ooo_api",
@@ -442,10 +1068,10 @@ func (o *OOOApi) getPairPricesFromDex(base string, target string, api map[string
 		}).Error("pair not found in database for this dex")
 	}
 
-	return prices
+	return prices, rejections
 }
 
-func (o *OOOApi) getRecentPairPrices(pairAddress string, api map[string]string, currentBlock uint64) GraphQlAliasedPairPrices {
+func (o *OOOApi) getRecentPairPrices(ctx context.Context, pairAddress string, api map[string]string, currentBlock uint64, historical bool) (GraphQlAliasedPairPrices, error) {
 	o.logger.WithFields(logrus.Fields{
 		"package":       "ooo_api",
 		"function":      "getKnownPairPrice",
@@ -455,33 +1081,93 @@ func (o *OOOApi) getRecentPairPrices(pairAddress string, api map[string]string,
 		"current_block": currentBlock,
 	}).Debug()
 
+	query := generatePairPricesQuery(pairAddress, api["pair_endpoint"], api["pairs_order_by"], apiBlocksPerMin(api), currentBlock, historical)
+
+	if subgraphBatchWindow() > 0 && subgraphBatchSize() > 1 {
+		jsonValue, _ := json.Marshal(query)
+		var decodedResponse GraphQlPairPricesResponse
+		if body, ok := o.subgraphCache.get(api["url"], currentBlock, string(jsonValue)); ok {
+			err := o.decodeQueryResponse(body, &decodedResponse)
+			return decodedResponse.Data, err
+		}
+		return o.queryPairPricesBatched(ctx, pairAddress, jsonValue, api, currentBlock, historical)
+	}
+
+	var decodedResponse GraphQlPairPricesResponse
+
+	err := o.runQueryAtBlock(ctx, query, api, currentBlock, &decodedResponse)
+
+	return decodedResponse.Data, err
+
+}
+
+// apiBlocksPerMin returns the subgraph's chain's blocks in a minute, the interval between the
+// price snapshots queried
+func apiBlocksPerMin(api map[string]string) uint64 {
 	blocksPerMin, err := strconv.Atoi(api["blocks_in_one_min"])
 	if err != nil {
 		blocksPerMin = 10
 	}
+	return uint64(blocksPerMin)
+}
 
-	query := generatePairPricesQuery(pairAddress, api["pair_endpoint"], api["pairs_order_by"], uint64(blocksPerMin), currentBlock)
-
-	var decodedResponse GraphQlPairPricesResponse
+// runQuery will run the subgraph query, counting it against the budget for its class. GraphQL
+// level errors returned by the subgraph are treated as a failed query, since the data may be partial
+func (o *OOOApi) runQuery(query interface{}, api map[string]string, class string, decodedResponse interface{}) error {
+	jsonValue, _ := json.Marshal(query)
 
-	o.runQuery(query, api["url"], &decodedResponse)
+	if err := o.chargeSubgraphQuery(api["name"], class); err != nil {
+		return err
+	}
 
-	return decodedResponse.Data
+	body, err := o.postQuery(o.ctx, jsonValue, api["url"])
+	if err != nil {
+		return err
+	}
 
+	return o.decodeQueryResponse(body, decodedResponse)
 }
 
-// runQuery will run the subgraph query
-func (o *OOOApi) runQuery(query interface{}, url string, decodedResponse interface{}) {
+// runQueryAtBlock runs a price query whose result only depends on the chain block it is made
+// at, such as pair price snapshots. Responses are cached until a query is made to the subgraph
+// at a later block. Only queries not answered from the cache are counted against the budget
+func (o *OOOApi) runQueryAtBlock(ctx context.Context, query interface{}, api map[string]string, block uint64, decodedResponse interface{}) error {
 	jsonValue, _ := json.Marshal(query)
+	url := api["url"]
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonValue))
+	body, ok := o.subgraphCache.get(url, block, string(jsonValue))
+	if !ok {
+		if err := o.chargeSubgraphQuery(api["name"], QueryClassPrice); err != nil {
+			return err
+		}
+		var err error
+		body, err = o.postQuery(ctx, jsonValue, url)
+		if err != nil {
+			return err
+		}
+		o.subgraphCache.put(url, block, string(jsonValue), body)
+	}
 
+	return o.decodeQueryResponse(body, decodedResponse)
+}
+
+// postQuery posts the JSON encoded query to the subgraph, returning the response body if the
+// query succeeded
+func (o *OOOApi) postQuery(ctx context.Context, jsonValue []byte, url string) ([]byte, error) {
+
+	// e.g. a Graph gateway url's api key
+	url, err := o.credentials.Expand(url)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonValue))
 	if err != nil {
 		o.logger.WithFields(logrus.Fields{
 			"package":  "ooo_api",
 			"function": "runQuery",
 		}).Error(err.Error())
-		return
+		return nil, err
 	}
 
 	resp, err := o.client.Do(req)
@@ -491,39 +1177,56 @@ func (o *OOOApi) runQuery(query interface{}, url string, decodedResponse interfa
 			"package":  "ooo_api",
 			"function": "runQuery",
 		}).Error(err.Error())
-		return
+		return nil, err
+	}
+
+	defer httpclient.Close(resp.Body)
+
+	if resp.StatusCode != 200 {
+		err = newHttpStatusError(resp)
+		o.logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "runQuery",
+		}).Error(err)
+		return nil, err
 	}
 
-	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
 
-	if resp.StatusCode == 200 {
-		body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "runQuery",
+		}).Error(err.Error())
+		return nil, err
+	}
 
-		if err != nil {
-			o.logger.WithFields(logrus.Fields{
-				"package":  "ooo_api",
-				"function": "runQuery",
-			}).Error(err.Error())
-			return
-		}
+	var errResponse GraphQlErrorResponse
+	if err = json.Unmarshal(body, &errResponse); err == nil && len(errResponse.Errors) > 0 {
+		err = fmt.Errorf("subgraph query error: %s", errResponse.Errors[0].Message)
+		o.logger.WithFields(logrus.Fields{
+			"package":    "ooo_api",
+			"function":   "runQuery",
+			"num_errors": len(errResponse.Errors),
+		}).Error(err.Error())
+		return nil, err
+	}
 
-		err = json.Unmarshal(body, &decodedResponse)
+	return body, nil
+}
 
-		if err != nil {
-			o.logger.WithFields(logrus.Fields{
-				"package":  "ooo_api",
-				"function": "runQuery",
-			}).Error(err.Error())
-			return
-		}
-	} else {
+func (o *OOOApi) decodeQueryResponse(body []byte, decodedResponse interface{}) error {
+	err := json.Unmarshal(body, &decodedResponse)
+
+	if err != nil {
 		o.logger.WithFields(logrus.Fields{
 			"package":  "ooo_api",
 			"function": "runQuery",
-		}).Error(fmt.Errorf("non-200 OK status code: %v", resp.Status))
-		return
+		}).Error(err.Error())
+		return err
 	}
-	return
+
+	return nil
 }
 
 // generateTokenQuery uses a fuzzy token query to get token ids from symbol names
@@ -551,29 +1254,35 @@ func generateTokenQuery(symbol string, tokensEndpoint string, tokenOrderBy strin
 	return jsonData
 }
 
-func generatePairsListQuery(pairEndpoint, pairOrderBy, txCount string, skip uint64) map[string]string {
+// generatePairsListQuery generates a single page of the pair discovery query. Pages are
+// ordered by id, and lastId is used as the cursor for the next page. If changedSince is not 0,
+// only pairs changed at or after that block are returned
+func generatePairsListQuery(pairEndpoint, pairOrderBy, txCount string, lastId string, changedSince uint64) map[string]string {
 
 	txCountFilter := ""
 	if txCount != "" {
-		txCountFilter = fmt.Sprintf(`txCount_gt: "%d"`, MinTxCount)
+		txCountFilter = fmt.Sprintf(`txCount_gt: "%d",`, MinTxCount)
 	}
-	skipFilter := ""
-	if skip > 0 {
-		skipFilter = fmt.Sprintf(`skip: %d,`, skip)
+	cursorFilter := ""
+	if lastId != "" {
+		cursorFilter = fmt.Sprintf(`id_gt: "%s",`, lastId)
+	}
+	if changedSince > 0 {
+		cursorFilter += changeBlockFilter(changedSince)
 	}
 
 	jsonData := map[string]string{
 		"query": fmt.Sprintf(`
             {
 	            %s(
-	                first: 1000,
-                    %s
-	                orderBy: %s,
-	                orderDirection: desc,
+	                first: %d,
+	                orderBy: id,
+	                orderDirection: asc,
                     where :
                      {
                           %s_gt: "%d",
                           %s
+                          %s
                      }
 	            ) 
                 {
@@ -600,7 +1309,60 @@ func generatePairsListQuery(pairEndpoint, pairOrderBy, txCount string, skip uint
                          __typename
 	                 }
 	            }
-	        }`, pairEndpoint, skipFilter, pairOrderBy, pairOrderBy, MinLiquidity, txCountFilter, pairOrderBy, txCount),
+	        }`, pairEndpoint, GraphQlMaxEntities, pairOrderBy, MinLiquidity, txCountFilter, cursorFilter, pairOrderBy, txCount),
+	}
+
+	return jsonData
+}
+
+// generatePairsByIdQuery generates a batched query for the state of multiple known pairs, at
+// block, or the latest indexed block if block is 0. If changedSince is not 0, only pairs changed
+// at or after that block are returned
+func generatePairsByIdQuery(pairAddresses []string, pairEndpoint string, pairOrderBy string, block uint64, changedSince uint64) map[string]string {
+
+	quoted := make([]string, 0, len(pairAddresses))
+	for _, a := range pairAddresses {
+		quoted = append(quoted, fmt.Sprintf(`"%s"`, a))
+	}
+
+	blockFilter := ""
+	if block > 0 {
+		blockFilter = fmt.Sprintf(`block: { number: %d },`, block)
+	}
+
+	changedFilter := ""
+	if changedSince > 0 {
+		changedFilter = changeBlockFilter(changedSince)
+	}
+
+	jsonData := map[string]string{
+		"query": fmt.Sprintf(`
+            {
+	            %s(
+	                first: %d,
+	                %s
+                    where :
+                     {
+                          id_in: [%s]
+                          %s
+                     }
+	            ) 
+                {
+                     id
+                     %s
+                     volumeUSD
+                     token0Price
+                     token1Price
+                     token0 {
+                         id
+                         symbol
+                     }
+                     token1 {
+                         id
+                         symbol
+                     }
+	            }
+	        }`, pairEndpoint, GraphQlMaxEntities, blockFilter, strings.Join(quoted, ", "), changedFilter, pairOrderBy),
 	}
 
 	return jsonData
@@ -671,7 +1433,23 @@ func generateKnownPairQuery(pairAddress string, pairEndpoint string, pairOrderBy
 	return jsonData
 }
 
-func generatePairPricesQuery(pairAddress string, pairEndpoint string, pairOrderBy string, blocksPerMin, currentBlock uint64) map[string]string {
+func generatePairPricesQuery(pairAddress string, pairEndpoint string, pairOrderBy string, blocksPerMin, currentBlock uint64, historical bool) map[string]string {
+
+	jsonData := map[string]string{
+		"query": fmt.Sprintf(`
+            {
+	            %s
+	        }
+        `, pairPricesSelections(pairAddress, pairEndpoint, pairOrderBy, blocksPerMin, currentBlock, historical, "")),
+	}
+
+	return jsonData
+}
+
+// pairPricesSelections returns the aliased selections of a pair's price snapshots, p0 to p9, one
+// a minute back from currentBlock. The aliases are prefixed with prefix, so that the snapshots of
+// several pairs can be selected in one query
+func pairPricesSelections(pairAddress string, pairEndpoint string, pairOrderBy string, blocksPerMin, currentBlock uint64, historical bool, prefix string) string {
 
 	baseQuery := fmt.Sprintf(`
 id
@@ -689,62 +1467,19 @@ id
                     token1Price
                     %s`, pairOrderBy)
 
-	p0Q := fmt.Sprintf(`%s(id: "%s") {
-                     %s
-                }`, pairEndpoint, pairAddress, baseQuery)
-
-	p1Q := fmt.Sprintf(`%s(id: "%s", block: { number: %d }) {
+	selections := make([]string, 0, 10)
+	for i := uint64(0); i < 10; i++ {
+		q := fmt.Sprintf(`%sp%d: %s(id: "%s", block: { number: %d }) {
                      %s
-                }`, pairEndpoint, pairAddress, currentBlock-blocksPerMin, baseQuery)
-
-	p2Q := fmt.Sprintf(`%s(id: "%s", block: { number: %d }) {
+                }`, prefix, i, pairEndpoint, pairAddress, currentBlock-(blocksPerMin*i), baseQuery)
+		if i == 0 && !historical {
+			// the latest snapshot is not pinned, so that it is as fresh as the subgraph
+			q = fmt.Sprintf(`%sp0: %s(id: "%s") {
                      %s
-                }`, pairEndpoint, pairAddress, currentBlock-(blocksPerMin*2), baseQuery)
-
-	p3Q := fmt.Sprintf(`%s(id: "%s", block: { number: %d }) {
-                     %s
-                }`, pairEndpoint, pairAddress, currentBlock-(blocksPerMin*3), baseQuery)
-
-	p4Q := fmt.Sprintf(`%s(id: "%s", block: { number: %d }) {
-                     %s
-                }`, pairEndpoint, pairAddress, currentBlock-(blocksPerMin*4), baseQuery)
-
-	p5Q := fmt.Sprintf(`%s(id: "%s", block: { number: %d }) {
-                     %s
-                }`, pairEndpoint, pairAddress, currentBlock-(blocksPerMin*5), baseQuery)
-
-	p6Q := fmt.Sprintf(`%s(id: "%s", block: { number: %d }) {
-                     %s
-                }`, pairEndpoint, pairAddress, currentBlock-(blocksPerMin*6), baseQuery)
-
-	p7Q := fmt.Sprintf(`%s(id: "%s", block: { number: %d }) {
-                     %s
-                }`, pairEndpoint, pairAddress, currentBlock-(blocksPerMin*7), baseQuery)
-
-	p8Q := fmt.Sprintf(`%s(id: "%s", block: { number: %d }) {
-                     %s
-                }`, pairEndpoint, pairAddress, currentBlock-(blocksPerMin*8), baseQuery)
-
-	p9Q := fmt.Sprintf(`%s(id: "%s", block: { number: %d }) {
-                     %s
-                }`, pairEndpoint, pairAddress, currentBlock-(blocksPerMin*9), baseQuery)
-
-	jsonData := map[string]string{
-		"query": fmt.Sprintf(`
-            {
-	            p0: %s,
-                p1: %s,
-                p2: %s,
-                p3: %s,
-                p4: %s,
-                p5: %s,
-                p6: %s,
-                p7: %s,
-                p8: %s,
-                p9: %s
-	        }
-        `, p0Q, p1Q, p2Q, p3Q, p4Q, p5Q, p6Q, p7Q, p8Q, p9Q),
+                }`, prefix, pairEndpoint, pairAddress, baseQuery)
+		}
+		selections = append(selections, q)
 	}
 
-	return jsonData
+	return strings.Join(selections, ",\n                ")
 }
@@ -6,24 +6,96 @@ import (
 	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"go-ooo/config"
+	"go-ooo/credentials"
 	"go-ooo/database"
+	"go-ooo/database/models"
+	"go-ooo/errcodes"
+	"go-ooo/httpclient"
+	"go-ooo/supervisor"
+	"go-ooo/utils"
 	"gorm.io/gorm"
-	"io/ioutil"
+	"math/big"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// DefaultAnswerDecimals - decimals used for submitted answers, unless configured otherwise
+const DefaultAnswerDecimals = 18
+
+// DefaultAdhocDMax - Chauvenet criterion dMax used to remove ad-hoc price outliers
+const DefaultAdhocDMax = 3
+
+// FinchainsDecimals - decimals of prices returned by the Finchains API
+const FinchainsDecimals = 18
+
 type OOOApi struct {
-	baseURL string
-	client  *http.Client
-	db      *database.DB
-	logger  *logrus.Logger
-	ctx     context.Context
+	finchains *finchainsEndpoints
+	forex     *forexRates
+	// USD rates of the stablecoins fiat prices are quoted in on DEXs
+	stablecoins *stablecoinRates
+	client      *http.Client
+
+	// per-pair source overrides, hot-reloaded
+	pairSources *pairSources
+
+	// low liquidity alerting for actively answered DEX pairs
+	liquidity *liquidityMonitor
+
+	// operator configured generic JSON feeds, keyed by upper case name
+	jsonFeeds map[string]JsonFeed
+
+	// operator configured absolute price bounds, keyed by upper case BASE.TARGET
+	priceBounds map[string]PriceBound
+
+	// operator configured Uniswap V3 pools read for their TWAP, and the result of each pool's
+	// latest read
+	twap       *twapSource
+	twapHealth *sourceHealthResults
+
+	// operator defined custom pairs, keyed by dex and pair in both directions, and in pair order
+	customPairs    map[string]CustomPair
+	customPairList []CustomPair
+
+	// answers every request with mock prices instead of querying data sources, if enabled
+	mock *mockSources
+
+	// shares upstream fetches between concurrent requests for the same endpoint
+	coalescer *fetchCoalescer
+
+	// results of the last subgraph health check
+	subgraphHealth *sourceHealthResults
+
+	// subgraph responses for the current block
+	subgraphCache *subgraphCache
+
+	// combines price queries for different pairs to the same subgraph
+	subgraphBatcher *subgraphBatcher
+
+	// cost of subgraph queries this month, against the monthly budget
+	subgraphBudget *subgraphBudget
+
+	// decimals used to scale submitted answers, how digits beyond them are
+	// rounded, and the Chauvenet dMax used to remove outliers from ad-hoc prices
+	answerDecimals uint
+	answerRounding string
+	dMax           float64
+
+	// aggregate with exact rationals rather than float64, if enabled
+	exactMath bool
+
+	// API keys referenced by adapter config as {credential:NAME}
+	credentials *credentials.Manager
+
+	db         database.Store
+	logger     *logrus.Logger
+	ctx        context.Context
+	supervisor *supervisor.Supervisor
 
 	// Only used to grab the latest block numbers for Ad-Hoc
 	// queries, so we can query historical data in subgraphs
@@ -33,40 +105,135 @@ type OOOApi struct {
 	subchainXdaiClient    *ethclient.Client
 }
 
-func NewApi(ctx context.Context, db *database.DB, logger *logrus.Logger) (*OOOApi, error) {
+// NewApi returns the data source adapters. creds holds the API keys adapter config references, and
+// may be nil if none are needed, e.g. for benchmarks. sup runs the fetches and queries which outlive
+// the request starting them
+func NewApi(ctx context.Context, db database.Store, logger *logrus.Logger, creds *credentials.Manager, sup *supervisor.Supervisor) (*OOOApi, error) {
+
+	answerDecimals := uint(DefaultAnswerDecimals)
+	if viper.IsSet(config.JobsAnswerDecimals) {
+		answerDecimals = viper.GetUint(config.JobsAnswerDecimals)
+	}
+
+	if answerDecimals > utils.MaxUint256Decimals {
+		return nil, fmt.Errorf("%s must be <= %d", config.JobsAnswerDecimals, utils.MaxUint256Decimals)
+	}
+
+	answerRounding := utils.RoundFloor
+	if viper.GetString(config.JobsAnswerRounding) != "" {
+		answerRounding = strings.ToLower(viper.GetString(config.JobsAnswerRounding))
+	}
+
+	dMax := float64(DefaultAdhocDMax)
+	if viper.GetFloat64(config.JobsAdhocDMax) > 0 {
+		dMax = viper.GetFloat64(config.JobsAdhocDMax)
+	}
+
+	pairSources, err := newPairSources(viper.GetString(config.JobsPairSourcesFile), logger)
 
-	subchainEthClient, err := ethclient.Dial(viper.GetString(config.SubChainEthHttpRpc))
+	if err != nil {
+		return nil, fmt.Errorf("cannot load pair source overrides: %s", err.Error())
+	}
+
+	jsonFeeds, err := loadJsonFeeds()
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot load json feeds: %s", err.Error())
+	}
+
+	twapPools, err := LoadTwapPools()
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot load twap pools: %s", err.Error())
+	}
+	if !config.FeatureEnabled(config.FeaturesDex) {
+		// TWAP pools are DEX sources
+		twapPools = nil
+	}
+
+	customPairs, err := LoadCustomPairs()
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot load custom pairs: %s", err.Error())
+	}
+
+	priceBounds, err := loadPriceBounds()
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot load price bounds: %s", err.Error())
+	}
+
+	var mock *mockSources
+	if viper.GetBool(config.JobsMockSources) {
+		mock, err = newMockSources(viper.GetString(config.JobsMockPricesFile), logger)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load mock prices: %s", err.Error())
+		}
+		logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "NewApi",
+			"file":     viper.GetString(config.JobsMockPricesFile),
+		}).Warn("mock source mode enabled - requests are answered with mock prices")
+	}
+
+	subchainEthClient, err := dialSubchain(viper.GetString(config.SubChainEthHttpRpc))
 
 	if err != nil {
 		return nil, err
 	}
 
-	subchainPolygonClient, err := ethclient.Dial(viper.GetString(config.SubChainPolygonHttpRpc))
+	subchainPolygonClient, err := dialSubchain(viper.GetString(config.SubChainPolygonHttpRpc))
 
 	if err != nil {
 		return nil, err
 	}
 
-	subchainBscClient, err := ethclient.Dial(viper.GetString(config.SubChainBcsHttpRpc))
+	subchainBscClient, err := dialSubchain(viper.GetString(config.SubChainBcsHttpRpc))
 
 	if err != nil {
 		return nil, err
 	}
 
-	subchainXdaiClient, err := ethclient.Dial(viper.GetString(config.SubChainXdaiHttpRpc))
+	subchainXdaiClient, err := dialSubchain(viper.GetString(config.SubChainXdaiHttpRpc))
 
 	if err != nil {
 		return nil, err
 	}
 
 	return &OOOApi{
-		baseURL: viper.GetString(config.JobsOooApiUrl),
+		finchains: newFinchainsEndpoints(
+			viper.GetString(config.JobsOooApiUrl),
+			viper.GetString(config.JobsOooApiUrlSecondary),
+		),
+		forex:           newForexRates(viper.GetString(config.JobsForexApiUrl)),
+		stablecoins:     newStablecoinRates(),
+		pairSources:     pairSources,
+		mock:            mock,
+		liquidity:       newLiquidityMonitor(viper.GetFloat64(config.JobsLiquidityAlertThreshold)),
+		jsonFeeds:       jsonFeeds,
+		priceBounds:     priceBounds,
+		twap:            newTwapSource(twapPools),
+		customPairs:     newCustomPairs(customPairs),
+		customPairList:  customPairs,
+		twapHealth:      newSourceHealthResults(),
+		coalescer:       newFetchCoalescer(ctx, time.Duration(viper.GetInt64(config.JobsCoalesceWindow))*time.Second, sup),
+		subgraphHealth:  newSourceHealthResults(),
+		subgraphCache:   newSubgraphCache(),
+		subgraphBatcher: newSubgraphBatcher(),
+		subgraphBudget:  newSubgraphBudget(),
+		answerDecimals:  answerDecimals,
+		answerRounding:  answerRounding,
+		dMax:            dMax,
+		exactMath:       viper.GetBool(config.JobsExactMath),
 		client: &http.Client{
-			Timeout: 15 * time.Second,
+			Timeout:   httpclient.Timeout(),
+			Transport: instrumentedTransport{next: httpclient.Transport()},
 		},
+		credentials:           creds,
 		db:                    db,
 		logger:                logger,
 		ctx:                   ctx,
+		supervisor:            sup,
 		subchainEthClient:     subchainEthClient,
 		subchainPolygonClient: subchainPolygonClient,
 		subchainBscClient:     subchainBscClient,
@@ -74,6 +241,46 @@ func NewApi(ctx context.Context, db *database.DB, logger *logrus.Logger) (*OOOAp
 	}, nil
 }
 
+// dialSubchain connects to a subchain RPC, over the shared data source transport for http urls
+func dialSubchain(rawUrl string) (*ethclient.Client, error) {
+	if strings.HasPrefix(rawUrl, "http://") || strings.HasPrefix(rawUrl, "https://") {
+		client, err := rpc.DialHTTPWithClient(rawUrl, httpclient.New(0))
+		if err != nil {
+			return nil, err
+		}
+		return ethclient.NewClient(client), nil
+	}
+	return ethclient.Dial(rawUrl)
+}
+
+// ReloadConfig applies settings which can change without a restart - the liquidity alert
+// threshold, and the pair source overrides, which are also reloaded when their file changes
+func (o *OOOApi) ReloadConfig() error {
+	threshold := viper.GetFloat64(config.JobsLiquidityAlertThreshold)
+	if threshold <= 0 {
+		threshold = MinLiquidity
+	}
+	o.liquidity.mu.Lock()
+	o.liquidity.threshold = threshold
+	o.liquidity.mu.Unlock()
+
+	o.recordBlocklistChanges()
+
+	if o.mock != nil && o.mock.v != nil {
+		if err := o.mock.load(); err != nil {
+			return fmt.Errorf("cannot reload mock prices: %s", err.Error())
+		}
+	}
+
+	if o.pairSources.v == nil {
+		return nil
+	}
+	if err := o.pairSources.load(); err != nil {
+		return fmt.Errorf("cannot reload pair source overrides: %s", err.Error())
+	}
+	return nil
+}
+
 // Request Format BASE.TARGET.TYPE.SUBTYPE[[.SUPP1][.SUPP2][.SUPP3]]
 // BASE: base currency, e.g. BTC, ETH etc.
 // TARGET: target currency, e.g. GBP, USD
@@ -92,6 +299,9 @@ func NewApi(ctx context.Context, db *database.DB, logger *logrus.Logger) (*OOOAp
 // BTC.GBP.PR.LAT - latest BTC/GBP price submitted to Finchains - latest exchange to submit price (not always the same exchange)
 // BTC.GBP.PR.AVI.24H - average BTC/GBP price, calculated from all supported exchanges over the last 24 hours, removing outliers
 // BTC.GBP.PR.AVC.24H.3 - average BTC/GBP price, calculated from all supported exchanges over the last 24 hours, removing outliers
+// BTC.USD.PR.AT.1640995200 - average BTC/USD close price of the 1 minute CEX candles for the unix timestamp
+// XFUND.ETH.AD.AT.1640995200 - ad-hoc XFUND/ETH price from DEX subgraph snapshots as of the unix timestamp
+// EUR.USD.JS.ECBFX - value extracted from the operator configured generic JSON feed named ECBFX
 
 func (o *OOOApi) buildQuery(endpoint string) (string, error) {
 
@@ -117,7 +327,7 @@ func (o *OOOApi) buildQuery(endpoint string) (string, error) {
 	// check supported
 	supported, _ := o.db.PairIsSupportedByBaseAndTarget(base, target)
 	if supported.ID == 0 {
-		return "", errors.New("pair not currently supported")
+		return "", errcodes.Errorf(errcodes.ErrPairUnsupported, "pair not currently supported")
 	}
 
 	pair := supported.GetName()
@@ -133,7 +343,7 @@ func (o *OOOApi) buildQuery(endpoint string) (string, error) {
 		}
 		break
 	default:
-		return "", errors.New("query type not currently supported")
+		return "", errcodes.Errorf(errcodes.ErrInvalidEndpoint, "query type not currently supported")
 	}
 
 	uri := fmt.Sprintf("%s/%s/%s", apiEndpont, pair, dataType)
@@ -141,7 +351,17 @@ func (o *OOOApi) buildQuery(endpoint string) (string, error) {
 	return uri, nil
 }
 
-func (o *OOOApi) QueryFinchainsEndpoint(endpoint string, requestId string) (string, error) {
+func (o *OOOApi) QueryFinchainsEndpoint(ctx context.Context, endpoint string, requestId string) (string, error) {
+	base, target, _, _, _, _, _, err := ParseEndpoint(endpoint)
+
+	if err != nil {
+		return "", err
+	}
+
+	if !o.sourceAllowed(ctx, base, target, FinchainsSourceName) {
+		return "", errcodes.Errorf(errcodes.ErrSourceUnavailable, "source %s disabled for pair %s.%s", FinchainsSourceName, base, target)
+	}
+
 	// check valid
 
 	uri, err := o.buildQuery(endpoint)
@@ -159,56 +379,64 @@ func (o *OOOApi) QueryFinchainsEndpoint(endpoint string, requestId string) (stri
 		"uri":       uri,
 	}).Debug("OoO API query built")
 
-	req, err := http.NewRequest("GET", fmt.Sprint(o.baseURL, "/", uri), nil)
+	var result OoOAPIPriceQueryResult
 
+	err = o.querySource(ctx, FinchainsSourceName, base, target, func() error {
+		body, err := o.finchainsGet(ctx, uri)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(body, &result)
+	})
 	if err != nil {
 		return "", err
 	}
 
-	resp, err := o.client.Do(req)
-
+	err = validateFinchainsPriceResult(result, base, target)
 	if err != nil {
 		return "", err
 	}
 
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-
+	price, err := utils.RescaleDecimals(result.Price, FinchainsDecimals, o.answerDecimals)
 	if err != nil {
 		return "", err
 	}
 
-	var result OoOAPIPriceQueryResult
-
-	err = json.Unmarshal(body, &result)
-	if err != nil {
+	if err := o.checkPriceBound(FinchainsSourceName, base, target, o.answerToFloat(price)); err != nil {
 		return "", err
 	}
 
-	return result.Price, nil
+	return price, nil
+}
+
+// answerToFloat converts a price scaled to the answer decimals to a float
+func (o *OOOApi) answerToFloat(price string) float64 {
+	answer, ok := new(big.Int).SetString(price, 10)
+	if !ok {
+		return 0
+	}
+	f, _ := new(big.Float).Quo(
+		new(big.Float).SetPrec(256).SetInt(answer),
+		new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(o.answerDecimals)), nil)),
+	).Float64()
+	return f
 }
 
 func (o *OOOApi) UpdateSupportedPairs() {
+	// pairs are blocklisted whichever source answers them
+	o.recordBlocklistChanges()
+
+	if o.mock != nil || !config.FeatureEnabled(config.FeaturesCex) {
+		// no data sources are queried in mock source mode, and supported pairs come from Finchains
+		return
+	}
 
 	o.logger.WithFields(logrus.Fields{
 		"package":  "ooo_api",
 		"function": "UpdateSupportedPairs",
 	}).Info("begin update supported pairs")
 
-	req, err := http.NewRequest("GET", fmt.Sprint(o.baseURL, "/pairs"), nil)
-
-	if err != nil {
-		o.logger.WithFields(logrus.Fields{
-			"package":  "ooo_api",
-			"function": "UpdateSupportedPairs",
-			"action":   "generate http request",
-		}).Error(err.Error())
-
-		return
-	}
-
-	resp, err := o.client.Do(req)
+	body, err := o.finchainsGet(o.ctx, "pairs")
 
 	if err != nil {
 
@@ -221,9 +449,6 @@ func (o *OOOApi) UpdateSupportedPairs() {
 		return
 	}
 
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-
 	var result []OoOAPIPairsResult
 
 	err = json.Unmarshal(body, &result)
@@ -239,9 +464,20 @@ func (o *OOOApi) UpdateSupportedPairs() {
 	currentPairs := make([]string, 0, len(result))
 
 	for _, p := range result {
+		if err := validateFinchainsPair(p); err != nil {
+			o.logger.WithFields(logrus.Fields{
+				"package":  "ooo_api",
+				"function": "UpdateSupportedPairs",
+				"action":   "validate pair",
+				"pair":     p.Name,
+			}).Warn(err.Error())
+			continue
+		}
 		dbRes, _ := o.db.PairIsSupportedByPairName(p.Name)
 		if dbRes.ID == 0 {
-			_ = o.db.AddNewSupportedPair(p.Name, p.Base, p.Target)
+			if err := o.db.AddNewSupportedPair(p.Name, p.Base, p.Target); err == nil {
+				o.recordPairChange(p.Name, models.PAIR_CHANGE_ADDED, SourceKindFinchains, "listed by Finchains")
+			}
 		}
 		currentPairs = append(currentPairs, p.Name)
 	}
@@ -267,11 +503,33 @@ func (o *OOOApi) UpdateSupportedPairs() {
 			"pair":     p.Name,
 		}).Info("pair no longer supported")
 
+		// before the pair is deleted, so that its consumers are alerted even if deleting fails
+		o.recordDelisting(p)
+
 		// delete permanently
-		o.db.Unscoped().Delete(&p)
+		if err := o.db.DeleteSupportedPair(p); err != nil {
+			o.logger.WithFields(logrus.Fields{
+				"package":  "ooo_api",
+				"function": "UpdateSupportedPairs",
+				"action":   "delete pair",
+				"pair":     p.Name,
+			}).Error(err.Error())
+			continue
+		}
+		o.recordPairChange(p.Name, models.PAIR_CHANGE_DELISTED, SourceKindFinchains, "no longer listed by Finchains")
 	}
 }
 
+// AnswerDecimals returns the decimals answers are scaled to
+func (o *OOOApi) AnswerDecimals() uint {
+	return o.answerDecimals
+}
+
+// AnswerRounding returns the rounding mode applied when answers are scaled to AnswerDecimals
+func (o *OOOApi) AnswerRounding() string {
+	return o.answerRounding
+}
+
 func IsAdhoc(endpoint string) (bool, error) {
 	_, _, qType, _, _, _, _, err := ParseEndpoint(endpoint)
 
@@ -291,7 +549,7 @@ func ParseEndpoint(endpoint string) (base string, target string, qType string,
 	ep := strings.Split(endpoint, ".")
 
 	if len(ep) < 3 {
-		err = errors.New(fmt.Sprintf("incorrect endpoint format: %s", endpoint))
+		err = errcodes.Errorf(errcodes.ErrInvalidEndpoint, "incorrect endpoint format: %s", endpoint)
 		return
 	}
 
@@ -315,6 +573,17 @@ func ParseEndpoint(endpoint string) (base string, target string, qType string,
 	return
 }
 
+// SplitPair splits a pair, e.g. BTC.USD, BTC-USD or BTC/USD, into its upper cased base and target
+func SplitPair(pair string) (string, string, bool) {
+	parts := strings.FieldsFunc(strings.ToUpper(pair), func(r rune) bool {
+		return r == '.' || r == '-' || r == '/'
+	})
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
 func cleanseTime(tm string) string {
 	switch tm {
 	case "5M":
@@ -375,7 +644,7 @@ func getPriceSubType(subtype string, supp1 string, supp2 string) (string, error)
 		qStr = "latest_one"
 		break
 	default:
-		return "", errors.New("unsupported sub type for PR")
+		return "", errcodes.Errorf(errcodes.ErrInvalidEndpoint, "unsupported sub type for PR")
 	}
 
 	return qStr, nil
@@ -426,6 +695,6 @@ func getExchange(ex string) (string, error) {
 	case "UN3":
 		return "uniswapv3", nil
 	default:
-		return "", errors.New("exchange not currently supported")
+		return "", errcodes.Errorf(errcodes.ErrInvalidEndpoint, "exchange not currently supported")
 	}
 }
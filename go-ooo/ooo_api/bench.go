@@ -0,0 +1,229 @@
+package ooo_api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/montanaflynn/stats"
+	"github.com/sirupsen/logrus"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BenchSample is the outcome of a single timed fetch of a pair's price from a source
+type BenchSample struct {
+	Source  string
+	Kind    string
+	Pair    string
+	Round   int
+	Latency time.Duration
+	Price   float64
+	Err     error
+}
+
+// BenchSummary is a source's latency, error rate and price dispersion over all the pairs and
+// rounds benchmarked. Dispersion is the mean absolute deviation, as a percentage, of the source's
+// prices from the median of all sources' prices for the same pair and round
+type BenchSummary struct {
+	Source        string
+	Kind          string
+	Samples       int
+	Errors        int
+	P50           time.Duration
+	P95           time.Duration
+	DispersionPct float64
+	// LastError is the most recent fetch error, if any
+	LastError string
+}
+
+// BenchSources fetches the current price of base/target once from every data source - each
+// Finchains endpoint, DEX subgraph, exchange kline API and json feed - timing each fetch. Pair
+// source overrides are ignored, so that sources which are disabled can also be compared
+func (o *OOOApi) BenchSources(base string, target string, round int) []BenchSample {
+	base = strings.ToUpper(base)
+	target = strings.ToUpper(target)
+	pair := fmt.Sprintf("%s.%s", base, target)
+
+	var samples []BenchSample
+	sample := func(source string, kind string, fetch func() (float64, error)) {
+		start := time.Now()
+		price, err := fetch()
+		samples = append(samples, BenchSample{
+			Source:  source,
+			Kind:    kind,
+			Pair:    pair,
+			Round:   round,
+			Latency: time.Since(start),
+			Price:   price,
+			Err:     err,
+		})
+	}
+
+	uri, err := o.buildQuery(fmt.Sprintf("%s.PR.AVC", pair))
+	for i, baseURL := range o.finchains.urls() {
+		name := FinchainsSourceName
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d", FinchainsSourceName, i+1)
+		}
+		baseURL := baseURL
+		sample(name, SourceKindFinchains, func() (float64, error) {
+			if err != nil {
+				return 0, err
+			}
+			return o.benchFinchains(baseURL, uri, base, target)
+		})
+	}
+
+	// block numbers and FX rates are fetched up front, so they are not included in the DEX timings
+	fxRate := float64(1)
+	var fxErr error
+	dexTargets := []string{target}
+	if IsFiatCurrency(target) {
+		dexTargets = UsdStablecoins
+		fxRate, fxErr = o.GetForexRate("USD", target)
+	}
+	currentBlocks := make(map[string]uint64)
+	for _, chain := range getChains() {
+		currentBlocks[chain], _ = o.getCurrentBlockNumForChain(o.ctx, chain)
+	}
+
+	for _, api := range getQlApis() {
+		api := api
+		sample(api["name"], SourceKindSubgraph, func() (float64, error) {
+			if fxErr != nil {
+				return 0, fxErr
+			}
+			var prices []float64
+			var rejections []error
+			for _, t := range dexTargets {
+				dexPrices, dexRejections := o.getPairPricesFromDex(o.ctx, base, t, api, currentBlocks[api["chain"]], false)
+				for _, p := range dexPrices {
+					prices = append(prices, p.value)
+				}
+				rejections = append(rejections, dexRejections...)
+			}
+			if len(prices) == 0 {
+				if len(rejections) > 0 {
+					return 0, fmt.Errorf("no valid prices: %s", summariseRejections(rejections))
+				}
+				return 0, errors.New("pair not found on dex")
+			}
+			mean, err := stats.Mean(prices)
+			return mean * fxRate, err
+		})
+	}
+
+	// the last closed candle
+	ts := time.Now().Unix() - 2*klineInterval
+	for _, k := range getKlineSources() {
+		k := k
+		sample(k.name, SourceKindHttp, func() (float64, error) {
+			return k.fetch(o.ctx, o, base, target, ts)
+		})
+	}
+
+	names := make([]string, 0, len(o.jsonFeeds))
+	for name := range o.jsonFeeds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		feed := o.jsonFeeds[name]
+		sample(jsonFeedSourceName(feed), SourceKindHttp, func() (float64, error) {
+			price, err := o.fetchJsonFeed(o.ctx, feed, base, target, "bench")
+			if err != nil {
+				return 0, err
+			}
+			return o.answerToFloat(price), nil
+		})
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":     "ooo_api",
+		"function":    "BenchSources",
+		"pair":        pair,
+		"round":       round,
+		"num_samples": len(samples),
+	}).Debug("sources benchmarked")
+
+	return samples
+}
+
+func (o *OOOApi) benchFinchains(baseURL string, uri string, base string, target string) (float64, error) {
+	body, err := o.finchainsGetFrom(o.ctx, baseURL, uri)
+	if err != nil {
+		return 0, err
+	}
+
+	var result OoOAPIPriceQueryResult
+	if err = json.Unmarshal(body, &result); err != nil {
+		return 0, err
+	}
+	if err = validateFinchainsPriceResult(result, base, target); err != nil {
+		return 0, err
+	}
+
+	price, _ := new(big.Float).SetString(result.Price)
+	price.Quo(price, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(FinchainsDecimals), nil)))
+	f, _ := price.Float64()
+	return f, nil
+}
+
+// SummariseBench returns a summary of each source's samples, ordered by source name
+func SummariseBench(samples []BenchSample) []BenchSummary {
+	// the median of all sources' prices for each pair and round, used to measure dispersion
+	medians := make(map[string]float64)
+	prices := make(map[string][]float64)
+	for _, s := range samples {
+		if s.Err == nil && s.Price > 0 {
+			key := fmt.Sprintf("%s/%d", s.Pair, s.Round)
+			prices[key] = append(prices[key], s.Price)
+		}
+	}
+	for key, p := range prices {
+		medians[key], _ = stats.Median(p)
+	}
+
+	bySource := make(map[string][]BenchSample)
+	for _, s := range samples {
+		bySource[s.Source] = append(bySource[s.Source], s)
+	}
+
+	res := make([]BenchSummary, 0, len(bySource))
+	for source, ss := range bySource {
+		summary := BenchSummary{Source: source, Kind: ss[0].Kind, Samples: len(ss)}
+
+		latencies := make([]float64, 0, len(ss))
+		var deviations []float64
+		for _, s := range ss {
+			latencies = append(latencies, float64(s.Latency))
+			if s.Err != nil {
+				summary.Errors++
+				summary.LastError = s.Err.Error()
+				continue
+			}
+			if median := medians[fmt.Sprintf("%s/%d", s.Pair, s.Round)]; median > 0 {
+				deviations = append(deviations, math.Abs(s.Price-median)/median*100)
+			}
+		}
+
+		p50, _ := stats.Percentile(latencies, 50)
+		p95, _ := stats.Percentile(latencies, 95)
+		summary.P50 = time.Duration(p50)
+		summary.P95 = time.Duration(p95)
+		if len(deviations) > 0 {
+			summary.DispersionPct, _ = stats.Mean(deviations)
+		}
+
+		res = append(res, summary)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Source < res[j].Source
+	})
+
+	return res
+}
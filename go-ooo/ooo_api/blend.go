@@ -0,0 +1,136 @@
+package ooo_api
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"math"
+	"math/big"
+	"time"
+)
+
+// ExcludedDeviation - reason DEX values are not blended with a Finchains price they differ too much from
+const ExcludedDeviation = "deviates from finchains"
+
+// defaultBlendDexWeight - share of a blended answer taken from the DEX mean, if jobs.blend_dex_weight is not set
+const defaultBlendDexWeight = 0.5
+
+// blendDexWeight returns the share of a blended answer taken from the DEX mean
+func blendDexWeight() float64 {
+	if !viper.IsSet(config.JobsBlendDexWeight) {
+		return defaultBlendDexWeight
+	}
+	w := viper.GetFloat64(config.JobsBlendDexWeight)
+	if w <= 0 || w > 1 {
+		return defaultBlendDexWeight
+	}
+	return w
+}
+
+// queryFinchainsBlended answers a Finchains endpoint. If jobs.blend_dex_sources is enabled and
+// the pair's symbols are also listed on a DEX, the Finchains price is blended with the ad-hoc
+// DEX mean for the pair, weighted by jobs.blend_dex_weight. The Finchains price is used alone
+// if the pair is not on any DEX, the DEX query fails, or the two differ by more than
+// jobs.blend_max_deviation percent
+func (o *OOOApi) queryFinchainsBlended(ctx context.Context, endpoint string, requestId string, explain *PriceExplanation) (string, []string, error) {
+	start := time.Now()
+	price, err := o.QueryFinchainsEndpoint(ctx, endpoint, requestId)
+	elapsed := time.Since(start)
+	if err != nil || !viper.GetBool(config.JobsBlendDexSources) {
+		return price, []string{FinchainsSourceName}, err
+	}
+
+	base, target, _, _, _, _, _, err := ParseEndpoint(endpoint)
+	if err != nil || !o.hasDexEquivalent(base, target) {
+		return price, []string{FinchainsSourceName}, nil
+	}
+
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":   "ooo_api",
+		"function":  "queryFinchainsBlended",
+		"requestId": requestId,
+		"endpoint":  endpoint,
+	})
+
+	finchainsValue := ExplainedValue{Source: FinchainsSourceName, Value: o.answerToFloat(price), Weight: 1, Latency: elapsed}
+
+	dexExplain := explain.sub()
+	dexPrice, dexSources, err := o.queryAdhoc(ctx, fmt.Sprintf("%s.%s.AD", base, target), requestId, dexExplain)
+	if err != nil {
+		logger.WithField("error", err.Error()).Debug("no DEX price to blend, using finchains alone")
+		return price, []string{FinchainsSourceName}, nil
+	}
+
+	finchainsAnswer, ok1 := new(big.Int).SetString(price, 10)
+	dexAnswer, ok2 := new(big.Int).SetString(dexPrice, 10)
+	if !ok1 || !ok2 || finchainsAnswer.Sign() <= 0 {
+		return price, []string{FinchainsSourceName}, nil
+	}
+
+	deviation, _ := new(big.Rat).SetFrac(new(big.Int).Sub(dexAnswer, finchainsAnswer), finchainsAnswer).Float64()
+	deviation = math.Abs(deviation) * 100
+	maxDeviation := viper.GetFloat64(config.JobsBlendMaxDeviation)
+	if maxDeviation > 0 && deviation > maxDeviation {
+		logger.WithFields(logrus.Fields{
+			"finchains":     price,
+			"dex":           dexPrice,
+			"deviation_pct": deviation,
+		}).Warn("DEX price deviates from finchains, using finchains alone")
+		explain.mergeBlend(finchainsValue, dexExplain, 0, ExcludedDeviation)
+		explain.setMethod("single source (%s), aggregated upstream - DEX mean deviates %.2f%%, max %g%%",
+			FinchainsSourceName, deviation, maxDeviation)
+		return price, []string{FinchainsSourceName}, nil
+	}
+
+	// weighted with exact rationals, since both answers are already scaled integers
+	w := blendDexWeight()
+	weight := new(big.Rat).SetFloat64(w)
+	blended := new(big.Rat).Mul(new(big.Rat).SetInt(dexAnswer), weight)
+	blended.Add(blended, new(big.Rat).Mul(new(big.Rat).SetInt(finchainsAnswer), new(big.Rat).Sub(big.NewRat(1, 1), weight)))
+	answer := new(big.Int).Quo(blended.Num(), blended.Denom())
+
+	explain.mergeBlend(finchainsValue, dexExplain, w, "")
+	explain.setMethod("blend of %s, %g%%, and %s", FinchainsSourceName, (1-w)*100, dexExplain.methodOrEmpty())
+
+	logger.WithFields(logrus.Fields{
+		"finchains":     price,
+		"dex":           dexPrice,
+		"dex_weight":    w,
+		"deviation_pct": deviation,
+		"blended":       answer.String(),
+	}).Debug("blended finchains and DEX prices")
+
+	return answer.String(), append([]string{FinchainsSourceName}, dexSources...), nil
+}
+
+// hasDexEquivalent returns true if a DEX allowed for the pair lists it, in either direction, or
+// against a USD stablecoin for fiat targets, or a TWAP pool is configured for it
+func (o *OOOApi) hasDexEquivalent(base string, target string) bool {
+	dexTargets := []string{target}
+	if IsFiatCurrency(target) {
+		dexTargets = UsdStablecoins
+	}
+
+	for _, a := range getQlApis() {
+		if !o.pairSources.allowed(base, target, a["name"]) {
+			continue
+		}
+		for _, t := range dexTargets {
+			if pair, _ := o.findDexPair(base, t, a["name"]); pair.ID != 0 {
+				return true
+			}
+		}
+	}
+
+	if o.pairSources.allowed(base, target, TwapSourceName) {
+		for _, t := range dexTargets {
+			if len(o.twap.poolsFor(base, t)) > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
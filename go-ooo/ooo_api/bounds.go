@@ -0,0 +1,116 @@
+package ooo_api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/errcodes"
+	"strings"
+)
+
+// PriceBound is an operator configured absolute range a pair's price must be within, e.g.
+//
+//	[[jobs.price_bounds]]
+//	pair = "BTC.USD"
+//	min = 1000
+//	max = 10000000
+//
+// Source values outside the range are discarded before aggregation, and an answer outside it is
+// never submitted. A limit of 0 is not checked
+type PriceBound struct {
+	Pair string  `mapstructure:"pair" json:"pair"`
+	Min  float64 `mapstructure:"min" json:"min"`
+	Max  float64 `mapstructure:"max" json:"max"`
+}
+
+// contains returns true if value is within the bound
+func (b PriceBound) contains(value float64) bool {
+	return (b.Min <= 0 || value >= b.Min) && (b.Max <= 0 || value <= b.Max)
+}
+
+func (b PriceBound) String() string {
+	switch {
+	case b.Min <= 0:
+		return fmt.Sprintf("<= %g", b.Max)
+	case b.Max <= 0:
+		return fmt.Sprintf(">= %g", b.Min)
+	}
+	return fmt.Sprintf("%g - %g", b.Min, b.Max)
+}
+
+// LoadPriceBounds reads and validates the configured per-pair price bounds
+func LoadPriceBounds() ([]PriceBound, error) {
+	var bounds []PriceBound
+	var err error
+	if raw, ok := viper.Get(config.JobsPriceBounds).(string); ok {
+		// set by an environment variable, as a JSON array of bounds
+		err = json.Unmarshal([]byte(raw), &bounds)
+	} else {
+		err = viper.UnmarshalKey(config.JobsPriceBounds, &bounds)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make(map[string]bool)
+	for i, b := range bounds {
+		base, target, ok := SplitPair(b.Pair)
+		if !ok {
+			return nil, fmt.Errorf("price bound %d: pair %q must be of the form BASE.TARGET", i+1, b.Pair)
+		}
+		pair := fmt.Sprintf("%s.%s", base, target)
+		if pairs[pair] {
+			return nil, fmt.Errorf("duplicate price bound for %s", pair)
+		}
+		pairs[pair] = true
+
+		if b.Min < 0 || b.Max < 0 {
+			return nil, fmt.Errorf("price bound %s: min and max must not be negative", pair)
+		}
+		if b.Min <= 0 && b.Max <= 0 {
+			return nil, fmt.Errorf("price bound %s: requires a min or max", pair)
+		}
+		if b.Max > 0 && b.Min > b.Max {
+			return nil, fmt.Errorf("price bound %s: min %g is above max %g", pair, b.Min, b.Max)
+		}
+	}
+
+	return bounds, nil
+}
+
+// loadPriceBounds returns the configured price bounds, keyed by upper case BASE.TARGET
+func loadPriceBounds() (map[string]PriceBound, error) {
+	bounds, err := LoadPriceBounds()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]PriceBound, len(bounds))
+	for _, b := range bounds {
+		base, target, _ := SplitPair(b.Pair)
+		res[fmt.Sprintf("%s.%s", base, target)] = b
+	}
+	return res, nil
+}
+
+// checkPriceBound returns a ValidationError if value, a price of base in target from source, is
+// outside the pair's bound
+func (o *OOOApi) checkPriceBound(source string, base string, target string, value float64) error {
+	b, ok := o.priceBounds[strings.ToUpper(fmt.Sprintf("%s.%s", base, target))]
+	if !ok || b.contains(value) {
+		return nil
+	}
+	return newValidationError(source, "price", fmt.Sprintf("%g outside bound %s", value, b))
+}
+
+// CheckAnswerBound returns an error if the answer for the endpoint, scaled to the answer
+// decimals, is outside the pair's bound. Each source value is already bound, so an answer
+// outside it means the aggregation cannot be trusted
+func (o *OOOApi) CheckAnswerBound(endpoint string, price string) error {
+	base, target, _, _, _, _, _, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return err
+	}
+	return errcodes.Wrap(errcodes.ErrPriceOutOfBounds, o.checkPriceBound("result", base, target, o.answerToFloat(price)))
+}
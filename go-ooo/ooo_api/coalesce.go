@@ -0,0 +1,257 @@
+package ooo_api
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"go-ooo/config"
+	"go-ooo/errcodes"
+	"go-ooo/supervisor"
+	"go-ooo/tracing"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fetchResult is the result of an upstream fetch for an endpoint
+type fetchResult struct {
+	price    string
+	sources  []string
+	err      error
+	explain  *PriceExplanation
+	finished time.Time
+}
+
+// fetchCall is an in-progress, or recently finished, upstream fetch
+type fetchCall struct {
+	done   chan struct{}
+	result fetchResult
+	// waiters is the number of requests waiting for the fetch. It is cancelled once none are left
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// fetchCoalescer shares a single upstream fetch between concurrent requests for the same
+// endpoint. Successful results are also shared with requests arriving within window
+// of the fetch finishing
+type fetchCoalescer struct {
+	mu     sync.Mutex
+	calls  map[string]*fetchCall
+	window time.Duration
+	// ctx is the parent of each fetch's context, since a fetch outlives the request starting it
+	// if other requests are still waiting for it
+	ctx        context.Context
+	supervisor *supervisor.Supervisor
+}
+
+func newFetchCoalescer(ctx context.Context, window time.Duration, sup *supervisor.Supervisor) *fetchCoalescer {
+	return &fetchCoalescer{
+		calls:      make(map[string]*fetchCall),
+		window:     window,
+		ctx:        ctx,
+		supervisor: sup,
+	}
+}
+
+// do runs fetch for key, unless a fetch for key is already in progress or recently finished,
+// in which case that result is returned. shared is true if the result came from another call.
+// If ctx is done before the result is ready, ctx's error is returned, and the fetch is cancelled
+// if no other requests are waiting for it. The fetch is traced as part of the span held in ctx,
+// but is not cancelled with ctx
+func (c *fetchCoalescer) do(ctx context.Context, key string, fetch func(ctx context.Context) (string, []string, *PriceExplanation, error)) (fetchResult, bool) {
+	c.mu.Lock()
+	c.prune()
+	if call, ok := c.calls[key]; ok {
+		call.waiters++
+		c.mu.Unlock()
+		return c.wait(ctx, key, call), true
+	}
+
+	fetchCtx, cancel := context.WithCancel(tracing.WithSpan(c.ctx, tracing.FromContext(ctx)))
+	call := &fetchCall{done: make(chan struct{}), waiters: 1, cancel: cancel}
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	c.supervisor.Go("coalesced_fetch", func() {
+		result := fetchResult{err: errcodes.Errorf(errcodes.ErrSourceUnavailable, "fetch for %s panicked", key)}
+		defer func() {
+			// set and close even if fetch panics, so that the waiters are not left blocked
+			cancel()
+
+			c.mu.Lock()
+			result.finished = time.Now()
+			call.result = result
+			if (result.err != nil || c.window <= 0) && c.calls[key] == call {
+				// never share errors with later requests
+				delete(c.calls, key)
+			}
+			c.prune()
+			c.mu.Unlock()
+			close(call.done)
+		}()
+
+		price, sources, explain, err := fetch(fetchCtx)
+		result = fetchResult{price: price, sources: sources, explain: explain, err: err}
+	})
+
+	return c.wait(ctx, key, call), false
+}
+
+// wait waits for the call's result, or for ctx to be done. The last waiter to give up
+// cancels the fetch
+func (c *fetchCoalescer) wait(ctx context.Context, key string, call *fetchCall) fetchResult {
+	select {
+	case <-call.done:
+		return call.result
+	case <-ctx.Done():
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	call.waiters--
+	if call.waiters == 0 && call.result.finished.IsZero() {
+		call.cancel()
+		if c.calls[key] == call {
+			// later requests start a fetch of their own
+			delete(c.calls, key)
+		}
+	}
+
+	return fetchResult{err: ctx.Err()}
+}
+
+// prune removes finished calls older than the window. Must be called with mu held
+func (c *fetchCoalescer) prune() {
+	cutoff := time.Now().Add(-c.window)
+	for k, call := range c.calls {
+		if !call.result.finished.IsZero() && call.result.finished.Before(cutoff) {
+			delete(c.calls, k)
+		}
+	}
+}
+
+// QueryEndpoint routes the endpoint to the relevant data source(s), returning the price scaled
+// to the answer decimals and the sources that contributed. Concurrent requests for the same
+// endpoint share a single upstream fetch
+func (o *OOOApi) QueryEndpoint(ctx context.Context, endpoint string, requestId string) (string, []string, error) {
+	price, sources, _, err := o.QueryEndpointExplained(ctx, endpoint, requestId, SourcePolicyAny)
+	return price, sources, err
+}
+
+// QueryEndpointExplained is QueryEndpoint, also returning the breakdown of each source's value,
+// weight and latency the price was aggregated from, using only the sources policy allows.
+// Requests sharing a fetch share its breakdown
+func (o *OOOApi) QueryEndpointExplained(ctx context.Context, endpoint string, requestId string, policy string) (string, []string, *PriceExplanation, error) {
+	key := strings.ToUpper(endpoint)
+	if policy != SourcePolicyAny {
+		// answers from different sources must not be shared
+		key = fmt.Sprintf("%s|%s", key, policy)
+	}
+
+	ctx, span := tracing.StartSpan(ctx, "fetch")
+	defer span.End()
+	span.SetAttribute("endpoint", endpoint)
+	span.SetAttribute("source_policy", policy)
+
+	result, shared := o.coalescer.do(ctx, key, func(ctx context.Context) (string, []string, *PriceExplanation, error) {
+		e := &PriceExplanation{
+			Endpoint:       endpoint,
+			AnswerDecimals: o.answerDecimals,
+			AnswerRounding: o.answerRounding,
+			SourcePolicy:   policy,
+		}
+		start := time.Now()
+		price, sources, err := o.queryEndpointTiered(ctx, endpoint, requestId, policy, e)
+		// errors not classified where they occurred are from the sources, e.g. a subgraph error
+		err = errcodes.Wrap(errcodes.ErrSourceUnavailable, err)
+		if err != nil {
+			e.Error = err.Error()
+		} else {
+			o.explainAnswer(e, price, sources, time.Since(start))
+		}
+		return price, sources, e, err
+	})
+
+	span.SetAttribute("coalesced", shared)
+	span.SetAttribute("sources", strings.Join(result.sources, ","))
+	span.SetAttribute("num_sources", len(result.sources))
+	span.SetError(result.err)
+
+	if shared {
+		o.logger.WithFields(logrus.Fields{
+			"package":   "ooo_api",
+			"function":  "QueryEndpoint",
+			"requestId": requestId,
+			"endpoint":  endpoint,
+		}).Debug("shared upstream fetch with concurrent request")
+	}
+
+	return result.price, result.sources, result.explain, result.err
+}
+
+// queryEndpoint fetches the endpoint from the data source(s) policy allows. If explain is not nil,
+// the values fetched and how they were aggregated are recorded in it
+func (o *OOOApi) queryEndpoint(ctx context.Context, endpoint string, requestId string, policy string, explain *PriceExplanation) (string, []string, error) {
+	if o.mock != nil {
+		return o.queryMock(endpoint, requestId)
+	}
+
+	isAdHoc, err := IsAdhoc(endpoint)
+	if err != nil {
+		return "", nil, err
+	}
+
+	isHistorical, _ := IsHistorical(endpoint)
+	isJsonFeed, _ := IsJsonFeed(endpoint)
+
+	if isAdHoc {
+		if policy == SourcePolicyCex {
+			return "", nil, sourcePolicyError(policy, endpoint)
+		}
+		return o.queryAdhoc(ctx, endpoint, requestId, explain)
+	} else if isJsonFeed {
+		// operator JSON feeds are neither CEX nor DEX sources
+		if policy != SourcePolicyAny {
+			return "", nil, sourcePolicyError(policy, endpoint)
+		}
+		return o.QueryJsonFeed(ctx, endpoint, requestId)
+	} else if isHistorical {
+		// klines only come from centralised exchanges
+		if policy == SourcePolicyDex {
+			return "", nil, sourcePolicyError(policy, endpoint)
+		}
+		return o.queryHistoricalKlines(ctx, endpoint, requestId, explain)
+	}
+
+	switch policy {
+	case SourcePolicyCex:
+		if !sourceEnabled(FinchainsSourceName) {
+			return "", nil, errcodes.Errorf(errcodes.ErrSourceUnavailable, "%s requires CEX sources, disabled by %s", policy, config.FeaturesCex)
+		}
+		// never blended with DEX prices
+		price, err := o.QueryFinchainsEndpoint(ctx, endpoint, requestId)
+		return price, []string{FinchainsSourceName}, err
+	case SourcePolicyDex:
+		// answered from the pair's DEX mean alone, as if fully blended
+		base, target, _, _, _, _, _, err := ParseEndpoint(endpoint)
+		if err != nil {
+			return "", nil, err
+		}
+		if !o.hasDexEquivalent(base, target) {
+			return "", nil, sourcePolicyError(policy, endpoint)
+		}
+		return o.queryAdhoc(ctx, fmt.Sprintf("%s.%s.AD", base, target), requestId, explain)
+	}
+
+	if !sourceInTier(ctx, FinchainsSourceName) || !sourceEnabled(FinchainsSourceName) {
+		// a source tier without finchains, or a node without CEX sources, answers from its DEX
+		// sources alone
+		base, target, _, _, _, _, _, err := ParseEndpoint(endpoint)
+		if err != nil {
+			return "", nil, err
+		}
+		return o.queryAdhoc(ctx, fmt.Sprintf("%s.%s.AD", base, target), requestId, explain)
+	}
+
+	return o.queryFinchainsBlended(ctx, endpoint, requestId, explain)
+}
@@ -0,0 +1,116 @@
+package ooo_api
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"go-ooo/supervisor"
+	"go-ooo/tracing"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestCoalescerFetchKeepsSpanNotCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	tracer := tracing.NewTracer(ctx, logger, tracing.Config{Endpoint: "http://127.0.0.1:1/v1/traces"})
+
+	reqCtx, span := tracer.StartRequestSpan(context.Background(), "0x01", "job")
+	reqCtx, cancelReq := context.WithCancel(reqCtx)
+	defer cancelReq()
+
+	c := newFetchCoalescer(ctx, 0, supervisor.New(logger, nil))
+	release := make(chan struct{})
+	type observed struct {
+		span *tracing.Span
+		err  error
+	}
+	fetched := make(chan observed, 1)
+	fetch := func(fetchCtx context.Context) (string, []string, *PriceExplanation, error) {
+		<-release
+		fetched <- observed{span: tracing.FromContext(fetchCtx), err: fetchCtx.Err()}
+		return "1", nil, nil, nil
+	}
+
+	first := make(chan fetchResult, 1)
+	go func() {
+		r, _ := c.do(reqCtx, "BTC.USD", fetch)
+		first <- r
+	}()
+	waitForWaiters(t, c, "BTC.USD", 1)
+	second := make(chan fetchResult, 1)
+	go func() {
+		r, _ := c.do(context.Background(), "BTC.USD", fetch)
+		second <- r
+	}()
+	waitForWaiters(t, c, "BTC.USD", 2)
+
+	// the request which started the fetch gives up, while another still waits for it
+	cancelReq()
+	if r := <-first; r.err != context.Canceled {
+		t.Fatalf("cancelled request got %v, want %v", r.err, context.Canceled)
+	}
+	close(release)
+
+	got := <-fetched
+	if got.span != span {
+		t.Error("fetch is not traced as part of the request's span")
+	}
+	if got.err != nil {
+		t.Errorf("fetch cancelled with the request which started it: %s", got.err)
+	}
+	if r := <-second; r.err != nil || r.price != "1" {
+		t.Errorf("waiting request got %q, %v", r.price, r.err)
+	}
+}
+
+func TestCoalescerFetchPanicReleasesWaiters(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	c := newFetchCoalescer(context.Background(), time.Minute, supervisor.New(logger, nil))
+
+	panics := func(ctx context.Context) (string, []string, *PriceExplanation, error) {
+		panic("upstream")
+	}
+	done := make(chan fetchResult, 1)
+	go func() {
+		r, _ := c.do(context.Background(), "BTC.USD", panics)
+		done <- r
+	}()
+
+	select {
+	case r := <-done:
+		if r.err == nil {
+			t.Fatal("panicked fetch returned no error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request still waiting for panicked fetch")
+	}
+
+	// the error is not shared, so the next request fetches again
+	r, shared := c.do(context.Background(), "BTC.USD", func(ctx context.Context) (string, []string, *PriceExplanation, error) {
+		return "1", nil, nil, nil
+	})
+	if shared || r.err != nil || r.price != "1" {
+		t.Errorf("request after panic got %q, %v, shared %t", r.price, r.err, shared)
+	}
+}
+
+func waitForWaiters(t *testing.T, c *fetchCoalescer, key string, n int) {
+	t.Helper()
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(time.Millisecond) {
+		c.mu.Lock()
+		call, ok := c.calls[key]
+		waiters := 0
+		if ok {
+			waiters = call.waiters
+		}
+		c.mu.Unlock()
+		if waiters == n {
+			return
+		}
+	}
+	t.Fatalf("%d requests not waiting for %s", n, key)
+}
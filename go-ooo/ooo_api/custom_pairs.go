@@ -0,0 +1,195 @@
+package ooo_api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database"
+	"go-ooo/database/models"
+	"sort"
+	"strings"
+)
+
+// CustomPair is an operator defined DEX pair, mapped to a pair or pool contract and its token
+// contracts on a subgraph DEX, e.g.
+//
+//	[[jobs.custom_pairs]]
+//	pair = "FOO.ETH"
+//	dex = "uniswapv3"
+//	contract = "0x..."
+//	base_token = "0x..."
+//	target_token = "0x..."
+//	min_liquidity = 5000
+//
+// It answers ad-hoc requests for the pair, and the inverse pair, from the DEX before pair
+// discovery finds it, and in place of any discovered pairs of the same symbols. The token
+// symbols on the DEX need not match the pair's, e.g. WETH for ETH. min_liquidity, if set, is the
+// USD liquidity below which the contract's prices are not used, in place of MinLiquidity
+type CustomPair struct {
+	Pair         string  `mapstructure:"pair" json:"pair"`
+	Dex          string  `mapstructure:"dex" json:"dex"`
+	Contract     string  `mapstructure:"contract" json:"contract"`
+	BaseToken    string  `mapstructure:"base_token" json:"base_token"`
+	TargetToken  string  `mapstructure:"target_token" json:"target_token"`
+	MinLiquidity float64 `mapstructure:"min_liquidity" json:"min_liquidity,omitempty"`
+}
+
+// LoadCustomPairs reads and validates the operator defined custom pairs, sorted by pair then dex
+func LoadCustomPairs() ([]CustomPair, error) {
+	var pairs []CustomPair
+	var err error
+	if raw, ok := viper.Get(config.JobsCustomPairs).(string); ok {
+		// set by an environment variable, as a JSON array of pairs
+		err = json.Unmarshal([]byte(raw), &pairs)
+	} else {
+		err = viper.UnmarshalKey(config.JobsCustomPairs, &pairs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for i, p := range pairs {
+		if _, _, ok := SplitPair(p.Pair); !ok {
+			return nil, fmt.Errorf("custom pair %d: pair %q must be of the form BASE.TARGET", i+1, p.Pair)
+		}
+		if !isSubgraphDex(strings.ToLower(p.Dex)) {
+			return nil, fmt.Errorf("custom pair %s: dex %q must be one of %s", p.Pair, p.Dex, strings.Join(SubgraphDexNames(), ", "))
+		}
+		for field, address := range map[string]string{"contract": p.Contract, "base_token": p.BaseToken, "target_token": p.TargetToken} {
+			if !common.IsHexAddress(address) {
+				return nil, fmt.Errorf("custom pair %s: %s %q must be an address", p.Pair, field, address)
+			}
+		}
+		if strings.EqualFold(p.BaseToken, p.TargetToken) {
+			return nil, fmt.Errorf("custom pair %s: base_token and target_token must differ", p.Pair)
+		}
+		if p.MinLiquidity < 0 {
+			return nil, fmt.Errorf("custom pair %s: min_liquidity %g must not be negative", p.Pair, p.MinLiquidity)
+		}
+
+		p.Pair = strings.ToUpper(p.Pair)
+		p.Dex = strings.ToLower(p.Dex)
+		// subgraph ids are lower case addresses
+		p.Contract = strings.ToLower(p.Contract)
+		p.BaseToken = strings.ToLower(p.BaseToken)
+		p.TargetToken = strings.ToLower(p.TargetToken)
+
+		base, target, _ := SplitPair(p.Pair)
+		for _, key := range []string{customPairKey(p.Dex, base, target), customPairKey(p.Dex, target, base)} {
+			if seen[key] {
+				return nil, fmt.Errorf("custom pair %s: more than one custom pair for %s on %s", p.Pair, p.Pair, p.Dex)
+			}
+			seen[key] = true
+		}
+		pairs[i] = p
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Pair != pairs[j].Pair {
+			return pairs[i].Pair < pairs[j].Pair
+		}
+		return pairs[i].Dex < pairs[j].Dex
+	})
+
+	return pairs, nil
+}
+
+// newCustomPairs returns the custom pairs keyed by dex and pair, and by dex and inverse pair
+func newCustomPairs(pairs []CustomPair) map[string]CustomPair {
+	res := make(map[string]CustomPair)
+	for _, p := range pairs {
+		base, target, _ := SplitPair(p.Pair)
+		res[customPairKey(p.Dex, base, target)] = p
+		res[customPairKey(p.Dex, target, base)] = p
+	}
+	return res
+}
+
+func customPairKey(dexName string, base string, target string) string {
+	return strings.ToLower(dexName) + "|" + strings.ToUpper(base+"."+target)
+}
+
+func isSubgraphDex(name string) bool {
+	for _, n := range SubgraphDexNames() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CustomPairs returns the operator defined custom pairs, by pair then dex
+func (o *OOOApi) CustomPairs() []CustomPair {
+	return append([]CustomPair{}, o.customPairList...)
+}
+
+// findDexPair returns the DEX pair used to answer ad-hoc requests for base/target on dexName - the
+// custom pair's contract if the operator defined one, which is also returned, otherwise the
+// discovered pair. The pair's ID is 0 if there is none
+func (o *OOOApi) findDexPair(base string, target string, dexName string) (models.DexPairs, *CustomPair) {
+	custom, ok := o.customPairs[customPairKey(dexName, base, target)]
+	if !ok {
+		pair, _ := o.db.FindByDexPairName(base, target, dexName)
+		return pair, nil
+	}
+
+	// stored by syncCustomPairs
+	pair, _ := o.db.FindDexPairByContract(custom.Contract, dexName)
+	return pair, &custom
+}
+
+// syncCustomPairs stores the custom pairs on the DEX, so that their liquidity and volume are
+// synced along with the discovered pairs. Contracts already discovered are left as they are
+func (o *OOOApi) syncCustomPairs(api map[string]string) {
+	var syncs []database.DexPairSync
+	for _, p := range o.customPairList {
+		if p.Dex != api["name"] {
+			continue
+		}
+		base, target, _ := SplitPair(p.Pair)
+		sync := database.DexPairSync{
+			T0Symbol:        base,
+			T0Address:       p.BaseToken,
+			T1Symbol:        target,
+			T1Address:       p.TargetToken,
+			ContractAddress: p.Contract,
+		}
+		// pair contracts order their tokens by address
+		if p.TargetToken < p.BaseToken {
+			sync.T0Symbol, sync.T0Address, sync.T1Symbol, sync.T1Address = target, p.TargetToken, base, p.BaseToken
+		}
+		syncs = append(syncs, sync)
+	}
+
+	if err := o.db.SyncDexPairs(api["name"], api["chain"], syncs); err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":   "ooo_api",
+			"function":  "syncCustomPairs",
+			"dex":       api["name"],
+			"num_pairs": len(syncs),
+		}).Error(err.Error())
+	}
+}
+
+// relabelCustomPair sets the symbols of a custom pair's snapshot to those of the pair, matching
+// its tokens by contract address, since a long tail token's symbol on the DEX may not be the one
+// requested, or may be shared by other tokens
+func relabelCustomPair(custom *CustomPair, snapshot GraphQlPairContent) (GraphQlPairContent, error) {
+	base, target, _ := SplitPair(custom.Pair)
+	t0, t1 := strings.ToLower(snapshot.Token0.Id), strings.ToLower(snapshot.Token1.Id)
+
+	switch {
+	case t0 == custom.BaseToken && t1 == custom.TargetToken:
+		snapshot.Token0.Symbol, snapshot.Token1.Symbol = base, target
+	case t0 == custom.TargetToken && t1 == custom.BaseToken:
+		snapshot.Token0.Symbol, snapshot.Token1.Symbol = target, base
+	default:
+		return snapshot, newValidationError(custom.Dex, "tokens", "not the custom pair's token contracts")
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,79 @@
+package ooo_api
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultDelistingLookback - days of requests checked for a delisted pair's consumers, if
+// jobs.delisting_lookback is not set
+const DefaultDelistingLookback = 30
+
+// DelistingLookback returns how far back requests are checked for a delisted pair's consumers
+func DelistingLookback() time.Duration {
+	days := DefaultDelistingLookback
+	if viper.IsSet(config.JobsDelistingLookback) {
+		days = viper.GetInt(config.JobsDelistingLookback)
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// recordDelisting records the consumers which requested the pair, which Finchains no longer
+// lists, within the lookback, so that they are alerted and listed by the admin API
+func (o *OOOApi) recordDelisting(pair models.SupportedPairs) {
+	base := strings.ToUpper(pair.GetBase())
+	target := strings.ToUpper(pair.GetTarget())
+	name := base + "." + target
+	now := time.Now()
+
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":  "ooo_api",
+		"function": "recordDelisting",
+		"pair":     name,
+	})
+
+	requests, err := o.db.GetRequestsForPairSince(base, target, now.Add(-DelistingLookback()))
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+
+	byConsumer := make(map[string]*models.PairDelistings)
+	for _, r := range requests {
+		d, ok := byConsumer[r.GetConsumer()]
+		if !ok {
+			d = &models.PairDelistings{Pair: name, DelistedAt: now.Unix(), Consumer: r.GetConsumer()}
+			byConsumer[r.GetConsumer()] = d
+		}
+		d.Requests++
+		if r.CreatedAt.Unix() > d.LastRequestedAt {
+			d.LastRequestedAt = r.CreatedAt.Unix()
+		}
+	}
+
+	delistings := make([]models.PairDelistings, 0, len(byConsumer))
+	for _, d := range byConsumer {
+		delistings = append(delistings, *d)
+	}
+	sort.Slice(delistings, func(i, j int) bool {
+		return delistings[i].Requests > delistings[j].Requests
+	})
+	if len(delistings) == 0 {
+		// still listed, and alerted, as delisted
+		delistings = append(delistings, models.PairDelistings{Pair: name, DelistedAt: now.Unix()})
+	}
+
+	if err = o.db.InsertPairDelistings(delistings); err != nil {
+		logger.Error(err.Error())
+		return
+	}
+
+	if len(byConsumer) > 0 {
+		logger.WithField("consumers", len(byConsumer)).Warn("delisted pair was recently requested")
+	}
+}
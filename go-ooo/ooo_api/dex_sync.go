@@ -0,0 +1,87 @@
+package ooo_api
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/schedule"
+	"time"
+)
+
+// defaultDexFullSyncInterval - seconds between syncs of every DEX pair, if
+// jobs.dex_full_sync_interval is not set
+const defaultDexFullSyncInterval = 86400
+
+// DEX pair sync modes, for the ooo_dex_syncs_total metric
+const (
+	dexSyncModeFull        = "full"
+	dexSyncModeIncremental = "incremental"
+)
+
+var dexSyncs = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ooo_dex_syncs_total",
+	Help: "Number of DEX pair syncs from subgraphs, by dex and mode - full or incremental",
+}, []string{"dex", "mode"})
+
+// changeBlockFilter returns the subgraph where filter for entities changed at or after block
+func changeBlockFilter(block uint64) string {
+	return fmt.Sprintf(`_change_block: { number_gte: %d },`, block)
+}
+
+// subgraphIndexedBlock returns the latest block the DEX's subgraph has indexed
+func (o *OOOApi) subgraphIndexedBlock(api map[string]string) (uint64, error) {
+	query := map[string]string{
+		"query": "{ _meta { block { number } } }",
+	}
+
+	var decodedResponse GraphQlMetaResponse
+	if err := o.runQuery(query, api, QueryClassSync, &decodedResponse); err != nil {
+		return 0, err
+	}
+
+	return decodedResponse.Data.Meta.Block.Number, nil
+}
+
+// dexSyncFrom returns the subgraph block to fetch the dex's changed pairs from, or 0 to fetch
+// every pair - if incremental sync is disabled, the dex has never been fully synced, or its
+// last full sync was more than jobs.dex_full_sync_interval ago. A full sync which is due while
+// its maintenance windows are closed is deferred, syncing incrementally until one opens
+func (o *OOOApi) dexSyncFrom(dexName string) uint64 {
+	if viper.IsSet(config.JobsDexIncrementalSync) && !viper.GetBool(config.JobsDexIncrementalSync) {
+		return 0
+	}
+
+	cursor, err := o.db.GetDexSyncCursor(dexName)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "dexSyncFrom",
+			"dex":      dexName,
+		}).Warn("cannot get sync cursor, fetching every pair: ", err.Error())
+		return 0
+	}
+
+	if cursor.GetBlock() == 0 || cursor.GetFullSyncAt() == 0 {
+		return 0
+	}
+
+	interval := int64(defaultDexFullSyncInterval)
+	if viper.IsSet(config.JobsDexFullSyncInterval) {
+		interval = viper.GetInt64(config.JobsDexFullSyncInterval)
+	}
+	if interval > 0 && time.Since(time.Unix(cursor.GetFullSyncAt(), 0)) >= time.Duration(interval)*time.Second {
+		if schedule.Allow(schedule.TaskDexFullSync) {
+			return 0
+		}
+		o.logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "dexSyncFrom",
+			"dex":      dexName,
+		}).Debug("full sync deferred to the next maintenance window")
+	}
+
+	return cursor.GetBlock()
+}
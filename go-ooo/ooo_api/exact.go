@@ -0,0 +1,184 @@
+package ooo_api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"go-ooo/errcodes"
+	"go-ooo/utils"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MaxExactPriceExp - largest binary exponent, either way, of a price accepted in exact math mode,
+// about 1e±1233. Subgraph data is not trusted, and an exact rational of a price such as 1e999999999
+// would not fit in memory
+const MaxExactPriceExp = 4096
+
+// exactStats - the mean and population variance of prices, as exact rationals. Used instead of
+// float64 stats when jobs.exact_math is enabled
+type exactStats struct {
+	mean     *big.Rat
+	variance *big.Rat
+}
+
+func newExactStats(values []*big.Rat) exactStats {
+	n := new(big.Rat).SetInt64(int64(len(values)))
+
+	sum := new(big.Rat)
+	for _, v := range values {
+		sum.Add(sum, v)
+	}
+	mean := new(big.Rat).Quo(sum, n)
+
+	variance := new(big.Rat)
+	for _, v := range values {
+		d := new(big.Rat).Sub(v, mean)
+		variance.Add(variance, d.Mul(d, d))
+	}
+	variance.Quo(variance, n)
+
+	return exactStats{mean: mean, variance: variance}
+}
+
+// withinDMax returns true if v is less than dMax standard deviations from the mean. The squares
+// are compared, (v - mean)^2 < dMax^2 * variance, so that no square root is taken
+func (s exactStats) withinDMax(v *big.Rat, dMax *big.Rat) bool {
+	d := new(big.Rat).Sub(v, s.mean)
+	d.Mul(d, d)
+	limit := new(big.Rat).Mul(dMax, dMax)
+	limit.Mul(limit, s.variance)
+	return d.Cmp(limit) < 0
+}
+
+// deviation returns how many standard deviations v is from the mean, approximately, for
+// explanations and logs
+func (s exactStats) deviation(v *big.Rat) float64 {
+	d := new(big.Rat).Sub(v, s.mean)
+	d.Mul(d, d).Quo(d, s.variance)
+	f, _ := d.Float64()
+	return math.Sqrt(f)
+}
+
+// floats returns the mean and standard deviation, approximately, for explanations and logs
+func (s exactStats) floats() (float64, float64) {
+	mean, _ := s.mean.Float64()
+	variance, _ := s.variance.Float64()
+	return mean, math.Sqrt(variance)
+}
+
+// ratFromFloat returns the shortest decimal representation of f as an exact rational, so that
+// binary floating point noise is not introduced, as for utils.ScaleToDecimals
+func ratFromFloat(f float64) *big.Rat {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+// ratFromDecimal parses a validated decimal string, such as a subgraph token price, as an
+// exact rational. value is the string as parsed to a big.Float, which bounds its exponent
+func ratFromDecimal(value string, parsed *big.Float) (*big.Rat, bool) {
+	if exp := parsed.MantExp(nil); exp > MaxExactPriceExp || exp < -MaxExactPriceExp {
+		return nil, false
+	}
+	return new(big.Rat).SetString(strings.TrimSpace(value))
+}
+
+// meanAdhocPricesExact is queryAdhoc's mean of the DEX prices in exact math mode. Outliers are
+// removed with the Chauvenet criterion, and the mean taken and scaled to the answer decimals,
+// using exact rationals throughout. rawPrices, the prices as floats, are only used for the
+// explanation and logs
+func (o *OOOApi) meanAdhocPricesExact(base string, target string, rawPrices []float64, rawExact []*big.Rat,
+	rawSources []string, rawImpacts []float64, sources []string, fxRate float64, explain *PriceExplanation) (string, []string, error) {
+
+	st := newExactStats(rawExact)
+	mean, stdDev := st.floats()
+	dMax := ratFromFloat(o.dMax)
+
+	// as for float math, outliers are only removed if the standard deviation is > 0
+	chauvenetUsed := st.variance.Sign() > 0
+	explained := make([]ExplainedValue, len(rawExact))
+
+	total := new(big.Rat)
+	priceCount := 0
+	removed := 0
+	belowPrecision := 0
+
+	for i, p := range rawExact {
+		explained[i] = ExplainedValue{Source: rawSources[i], Value: rawPrices[i], PriceImpact: rawImpacts[i]}
+		if chauvenetUsed {
+			explained[i].Deviation = st.deviation(p)
+			if !st.withinDMax(p, dMax) {
+				explained[i].Excluded = ExcludedOutlier
+				removed++
+				continue
+			}
+		}
+
+		// prices lost entirely at the answer precision are left out of the mean
+		if _, err := utils.ScaleRatToDecimalsRounded(p, o.answerDecimals, o.answerRounding); err != nil {
+			if errors.Is(err, utils.ErrBelowPrecision) {
+				belowPrecision++
+				explained[i].Excluded = ExcludedBelowPrecision
+				continue
+			}
+			// never submit a truncated number
+			return "", nil, fmt.Errorf("cannot scale price %s to %d decimals: %s", p.FloatString(int(o.answerDecimals)),
+				o.answerDecimals, err.Error())
+		}
+
+		total.Add(total, p)
+		priceCount++
+	}
+
+	// every remaining price has an equal share of the mean
+	for i := range explained {
+		if explained[i].Excluded == "" && priceCount > 0 {
+			explained[i].Weight = 1 / float64(priceCount)
+		}
+	}
+	explain.addValues(explained)
+	explain.setStats(mean, stdDev, o.dMax)
+	if chauvenetUsed {
+		explain.setMethod("mean of DEX prices, outliers removed with the Chauvenet criterion, in exact math")
+	} else {
+		explain.setMethod("mean of DEX prices, no outliers removed as the standard deviation is 0, in exact math")
+	}
+
+	if total.Sign() <= 0 {
+		if belowPrecision > 0 {
+			return "", nil, errcodes.Errorf(errcodes.ErrInvalidPrice, "cannot calculate mean, prices are below %d decimals precision", o.answerDecimals)
+		}
+		return "", nil, errcodes.Errorf(errcodes.ErrInvalidPrice, "cannot calculate mean, price is zero")
+	}
+
+	meanRat := new(big.Rat).Quo(total, new(big.Rat).SetInt64(int64(priceCount)))
+	meanPrice, err := utils.ScaleRatToDecimalsRounded(meanRat, o.answerDecimals, o.answerRounding)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot scale mean to %d decimals: %s", o.answerDecimals, err.Error())
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":            "ooo_api",
+		"function":           "QueryAdhoc",
+		"base":               base,
+		"target":             target,
+		"num_prices_raw":     len(rawExact),
+		"num_prices_chauv":   len(rawExact) - removed,
+		"num_prices_removed": removed,
+		"raw_prices_mean":    mean,
+		"raw_std_dev":        stdDev,
+		"final_wei_mean":     meanPrice.String(),
+		"answer_decimals":    o.answerDecimals,
+		"below_precision":    belowPrecision,
+		"chauvenet_used":     chauvenetUsed,
+		"fx_rate":            fxRate,
+		"exact_math":         true,
+	}).Debug("price stats")
+
+	return meanPrice.String(), sources, nil
+}
@@ -0,0 +1,210 @@
+package ooo_api
+
+import (
+	"github.com/sirupsen/logrus"
+	"go-ooo/utils"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func testApi(decimals uint, rounding string, exact bool) *OOOApi {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	return &OOOApi{
+		answerDecimals: decimals,
+		answerRounding: rounding,
+		dMax:           DefaultAdhocDMax,
+		exactMath:      exact,
+		logger:         logger,
+	}
+}
+
+// meanBoth returns the mean of prices by the float64 and the exact path, and their explanations
+func meanBoth(t *testing.T, o *OOOApi, prices []float64) (string, string, *PriceExplanation, *PriceExplanation, error, error) {
+	t.Helper()
+	exact := make([]*big.Rat, len(prices))
+	sources := make([]string, len(prices))
+	impacts := make([]float64, len(prices))
+	for i, p := range prices {
+		exact[i] = ratFromFloat(p)
+		sources[i] = "dex"
+	}
+
+	floatExplain := &PriceExplanation{}
+	exactExplain := &PriceExplanation{}
+	floatMean, _, floatErr := o.meanAdhocPrices("BASE", "TARGET", prices, sources, impacts, nil, 1, floatExplain)
+	exactMean, _, exactErr := o.meanAdhocPricesExact("BASE", "TARGET", prices, exact, sources, impacts, nil, 1, exactExplain)
+	return floatMean, exactMean, floatExplain, exactExplain, floatErr, exactErr
+}
+
+// nearDMax returns true if any price is so close to dMax standard deviations from the mean that
+// float64 rounding could decide whether it is an outlier differently to exact math
+func nearDMax(e *PriceExplanation) bool {
+	for _, v := range e.Values {
+		if math.Abs(v.Deviation-e.DMax) < 1e-6 {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMeanAdhocPricesExactMatchesFloat(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	modes := []string{utils.RoundFloor, utils.RoundHalfEven, utils.RoundCeiling}
+
+	for i := 0; i < 2000; i++ {
+		decimals := uint(rng.Intn(19))
+		mode := modes[rng.Intn(len(modes))]
+		o := testApi(decimals, mode, false)
+
+		// prices from 1e-6 to 1e12, a few percent apart, with an occasional outlier
+		scale := math.Pow(10, float64(rng.Intn(19)-6))
+		prices := make([]float64, 1+rng.Intn(20))
+		for j := range prices {
+			prices[j] = scale * (1 + rng.Float64()*0.05)
+			if rng.Intn(10) == 0 {
+				prices[j] *= 3
+			}
+		}
+
+		floatMean, exactMean, floatExplain, exactExplain, floatErr, exactErr := meanBoth(t, o, prices)
+		if nearDMax(floatExplain) {
+			continue
+		}
+		if (floatErr == nil) != (exactErr == nil) {
+			t.Fatalf("%v at %d decimals %s: float error %v, exact error %v", prices, decimals, mode, floatErr, exactErr)
+		}
+		if floatErr != nil {
+			continue
+		}
+
+		for j := range floatExplain.Values {
+			if floatExplain.Values[j].Excluded != exactExplain.Values[j].Excluded {
+				t.Fatalf("%v: price %d excluded %q by float math, %q by exact math", prices, j,
+					floatExplain.Values[j].Excluded, exactExplain.Values[j].Excluded)
+			}
+		}
+
+		// the float path rounds each price before taking the mean, the exact path only the mean,
+		// so they may differ by one unit at the answer precision
+		f, _ := new(big.Int).SetString(floatMean, 10)
+		e, _ := new(big.Int).SetString(exactMean, 10)
+		if d := new(big.Int).Sub(f, e); d.CmpAbs(big.NewInt(1)) > 0 {
+			t.Fatalf("%v at %d decimals %s: float mean %s, exact mean %s", prices, decimals, mode, floatMean, exactMean)
+		}
+	}
+}
+
+// exactMeanAt returns the mean of prices scaled to decimals, floored, computed independently
+func exactMeanAt(prices []*big.Rat, decimals uint) string {
+	sum := new(big.Rat)
+	for _, p := range prices {
+		sum.Add(sum, p)
+	}
+	sum.Quo(sum, new(big.Rat).SetInt64(int64(len(prices))))
+	sum.Mul(sum, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	return new(big.Int).Quo(sum.Num(), sum.Denom()).String()
+}
+
+func TestMeanAdhocPricesExactExtremes(t *testing.T) {
+	ten := func(exp int64) *big.Int {
+		return new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil)
+	}
+	// a price from the reserves of a pool with a very large supply token, e.g. 1e40 + 1 tokens
+	// of 18 decimals against 1 token
+	hugeSupply := new(big.Rat).SetFrac(new(big.Int).Add(ten(58), big.NewInt(1)), ten(18))
+	// and its inverse, far below 1 wei at 18 decimals
+	tinyPrice := new(big.Rat).Inv(hugeSupply)
+
+	tests := []struct {
+		name     string
+		decimals uint
+		prices   []*big.Rat
+		ok       bool
+	}{
+		{"18 decimals", 18, []*big.Rat{big.NewRat(3, 2), big.NewRat(7, 4), big.NewRat(1, 3)}, true},
+		{"huge supply at 18 decimals", 18, []*big.Rat{hugeSupply, hugeSupply}, true},
+		{"huge supply at 18 decimals overflows", 18, []*big.Rat{new(big.Rat).Mul(hugeSupply, new(big.Rat).SetInt(ten(20)))}, false},
+		{"sub-wei at 18 decimals", 18, []*big.Rat{tinyPrice}, false},
+		{"0 decimals", 0, []*big.Rat{big.NewRat(123456, 10), big.NewRat(123457, 10)}, true},
+		{"sub-wei at 0 decimals", 0, []*big.Rat{big.NewRat(4, 10)}, false},
+		{"huge supply at 0 decimals", 0, []*big.Rat{new(big.Rat).Mul(hugeSupply, new(big.Rat).SetInt(ten(18)))}, true},
+		{"77 decimals", 77, []*big.Rat{big.NewRat(1, 1), big.NewRat(1, 1)}, true},
+		{"77 decimals fraction", 77, []*big.Rat{big.NewRat(1, 3), big.NewRat(2, 3)}, true},
+		{"77 decimals overflows", 77, []*big.Rat{big.NewRat(2, 1)}, false},
+		{"sub-precision at 77 decimals", 77, []*big.Rat{tinyPrice}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := testApi(tt.decimals, utils.RoundFloor, true)
+			floats := make([]float64, len(tt.prices))
+			sources := make([]string, len(tt.prices))
+			for i, p := range tt.prices {
+				floats[i], _ = p.Float64()
+				sources[i] = "dex"
+			}
+
+			got, _, err := o.meanAdhocPricesExact("BASE", "TARGET", floats, tt.prices, sources, make([]float64, len(tt.prices)),
+				nil, 1, &PriceExplanation{})
+			if !tt.ok {
+				if err == nil {
+					t.Fatalf("got %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+			if want := exactMeanAt(tt.prices, tt.decimals); got != want {
+				t.Errorf("got %s, want exactly %s", got, want)
+			}
+			if len(got) > 78 || strings.HasPrefix(got, "-") {
+				t.Errorf("%s is not a uint256", got)
+			}
+		})
+	}
+}
+
+func TestWithinDMax(t *testing.T) {
+	// mean 2, variance 2/3, so 3 is 1.22 standard deviations from the mean
+	st := newExactStats([]*big.Rat{big.NewRat(1, 1), big.NewRat(2, 1), big.NewRat(3, 1)})
+	if st.mean.Cmp(big.NewRat(2, 1)) != 0 || st.variance.Cmp(big.NewRat(2, 3)) != 0 {
+		t.Fatalf("mean %s, variance %s", st.mean, st.variance)
+	}
+	if !st.withinDMax(big.NewRat(3, 1), big.NewRat(5, 4)) {
+		t.Error("3 not within 1.25 standard deviations")
+	}
+	if st.withinDMax(big.NewRat(3, 1), big.NewRat(6, 5)) {
+		t.Error("3 within 1.2 standard deviations")
+	}
+
+	// mean 2, standard deviation 2, so 4 is exactly 1 standard deviation from the mean, and not
+	// within it
+	limit := newExactStats([]*big.Rat{big.NewRat(0, 1), big.NewRat(4, 1)})
+	if limit.withinDMax(big.NewRat(4, 1), big.NewRat(1, 1)) {
+		t.Error("4 within exactly its 1 standard deviation")
+	}
+	if !limit.withinDMax(big.NewRat(399999999999, 100000000000), big.NewRat(1, 1)) {
+		t.Error("3.99999999999 not within 1 standard deviation")
+	}
+
+	// prices from a huge supply token differ in the 40th significant digit, beyond float64's
+	// precision, and are still compared exactly
+	base := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(40), nil))
+	one := big.NewRat(1, 1)
+	huge := newExactStats([]*big.Rat{base, new(big.Rat).Add(base, one), new(big.Rat).Add(base, big.NewRat(2, 1))})
+	if huge.variance.Sign() == 0 {
+		t.Fatal("variance of distinct huge prices is 0")
+	}
+	if !huge.withinDMax(new(big.Rat).Add(base, big.NewRat(2, 1)), big.NewRat(5, 4)) {
+		t.Error("huge price not within 1.25 standard deviations")
+	}
+	if huge.withinDMax(new(big.Rat).Add(base, big.NewRat(3, 1)), big.NewRat(5, 4)) {
+		t.Error("huge outlier within 1.25 standard deviations")
+	}
+}
@@ -0,0 +1,252 @@
+package ooo_api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// reasons a value does not contribute to an explained price
+const (
+	ExcludedOutlier        = "outlier"
+	ExcludedBelowPrecision = "below precision"
+)
+
+// ExplainedValue is a single value fetched from a source while aggregating a price
+type ExplainedValue struct {
+	Source string
+	Value  float64
+	// Deviation is the value's distance from the mean in standard deviations, if outliers
+	// were removed with the Chauvenet criterion
+	Deviation float64
+	// Excluded is why the value was discarded, if it was
+	Excluded string
+	// Weight is the value's share of the final answer. Discarded values have a weight of 0
+	Weight float64
+	// Latency is how long fetching from the source took, in total if it was queried more than once
+	Latency time.Duration
+	// PriceImpact is the percent a jobs.dex_impact_notional trade would move a DEX pool's price,
+	// for values from DEX pools
+	PriceImpact float64
+}
+
+// PriceExplanation is a breakdown of how the answer for an endpoint was aggregated
+type PriceExplanation struct {
+	Endpoint string
+	Method   string
+	Values   []ExplainedValue
+	Rejected []string
+	// Rejections are the source responses which failed validation, with Excluded the reason
+	Rejections     []ExplainedValue
+	Mean           float64
+	StdDev         float64
+	DMax           float64
+	FxRate         float64
+	Answer         string
+	AnswerDecimals uint
+	AnswerRounding string
+	// SourcePolicy is the consumer source policy the sources were limited by
+	SourcePolicy string
+	// SourceTier is the jobs.source_tiers tier whose sources answered, if tiers are configured.
+	// Anything but primary is a degraded answer
+	SourceTier string
+	Error      string
+
+	latency map[string]time.Duration
+}
+
+// the explanation methods are no-ops on a nil *PriceExplanation, so that the query functions
+// can record their workings unconditionally
+
+func (e *PriceExplanation) addValues(values []ExplainedValue) {
+	if e == nil {
+		return
+	}
+	for _, v := range values {
+		v.Latency = e.latency[v.Source]
+		e.Values = append(e.Values, v)
+	}
+}
+
+func (e *PriceExplanation) reject(rejections []error) {
+	if e == nil {
+		return
+	}
+	for _, r := range rejections {
+		e.Rejected = append(e.Rejected, r.Error())
+
+		var v ValidationError
+		if errors.As(r, &v) {
+			e.Rejections = append(e.Rejections, ExplainedValue{
+				Source:   v.Source,
+				Excluded: fmt.Sprintf("invalid %s: %s", v.Field, v.Reason),
+				Latency:  e.latency[v.Source],
+			})
+		}
+	}
+}
+
+// recordLatency adds the time taken by a fetch from source since start
+func (e *PriceExplanation) recordLatency(source string, start time.Time) {
+	if e == nil {
+		return
+	}
+	if e.latency == nil {
+		e.latency = make(map[string]time.Duration)
+	}
+	e.latency[source] += time.Since(start)
+}
+
+func (e *PriceExplanation) setMethod(format string, a ...interface{}) {
+	if e != nil {
+		e.Method = fmt.Sprintf(format, a...)
+	}
+}
+
+func (e *PriceExplanation) setStats(mean float64, stdDev float64, dMax float64) {
+	if e != nil {
+		e.Mean = mean
+		e.StdDev = stdDev
+		e.DMax = dMax
+	}
+}
+
+func (e *PriceExplanation) setFxRate(fxRate float64) {
+	if e != nil {
+		e.FxRate = fxRate
+	}
+}
+
+// sub returns an explanation for a part of the endpoint's aggregation, merged into e afterwards
+func (e *PriceExplanation) sub() *PriceExplanation {
+	if e == nil {
+		return nil
+	}
+	return &PriceExplanation{Endpoint: e.Endpoint, AnswerDecimals: e.AnswerDecimals, AnswerRounding: e.AnswerRounding, SourcePolicy: e.SourcePolicy}
+}
+
+// mergeTier records the rejections of a source tier's attempt to answer, explained by tier, and
+// its values and aggregation if it answered
+func (e *PriceExplanation) mergeTier(tier *PriceExplanation, name string, answered bool) {
+	if e == nil || tier == nil {
+		return
+	}
+	e.Rejected = append(e.Rejected, tier.Rejected...)
+	e.Rejections = append(e.Rejections, tier.Rejections...)
+	if !answered {
+		return
+	}
+	e.Values = append(e.Values, tier.Values...)
+	e.Method = tier.Method
+	e.setStats(tier.Mean, tier.StdDev, tier.DMax)
+	e.setFxRate(tier.FxRate)
+	e.SourceTier = name
+}
+
+func (e *PriceExplanation) methodOrEmpty() string {
+	if e == nil {
+		return ""
+	}
+	return e.Method
+}
+
+// mergeBlend records a Finchains price blended with the DEX aggregation explained by dex, whose
+// values' weights are scaled to dexWeight. If excluded is set, the DEX values are discarded for
+// that reason and the Finchains price is the whole answer
+func (e *PriceExplanation) mergeBlend(finchains ExplainedValue, dex *PriceExplanation, dexWeight float64, excluded string) {
+	if e == nil || dex == nil {
+		return
+	}
+	if excluded != "" {
+		dexWeight = 0
+	}
+	finchains.Weight = 1 - dexWeight
+	e.Values = append(e.Values, finchains)
+	for _, v := range dex.Values {
+		v.Weight *= dexWeight
+		if excluded != "" && v.Excluded == "" {
+			v.Excluded = excluded
+		}
+		e.Values = append(e.Values, v)
+	}
+	e.Rejected = append(e.Rejected, dex.Rejected...)
+	e.Rejections = append(e.Rejections, dex.Rejections...)
+	e.setStats(dex.Mean, dex.StdDev, dex.DMax)
+	e.setFxRate(dex.FxRate)
+}
+
+// ExplainEndpoint runs the full fetch and aggregation for the endpoint now, without the
+// coalescer's shared results, returning each source's value, those discarded and the answer.
+// Nothing is stored or submitted
+func (o *OOOApi) ExplainEndpoint(endpoint string) PriceExplanation {
+	e := &PriceExplanation{
+		Endpoint:       endpoint,
+		AnswerDecimals: o.answerDecimals,
+		AnswerRounding: o.answerRounding,
+	}
+
+	start := time.Now()
+	price, sources, err := o.queryEndpointTiered(o.ctx, endpoint, "explain", SourcePolicyAny, e)
+	if err != nil {
+		e.Error = err.Error()
+		return *e
+	}
+	o.explainAnswer(e, price, sources, time.Since(start))
+
+	return *e
+}
+
+// ExplainSingleSource returns the breakdown of a price which came from a single source outside
+// the node's aggregation, e.g. a value supplied by the operator
+func (o *OOOApi) ExplainSingleSource(endpoint string, price string, source string) *PriceExplanation {
+	e := &PriceExplanation{
+		Endpoint:       endpoint,
+		AnswerDecimals: o.answerDecimals,
+		AnswerRounding: o.answerRounding,
+	}
+	o.explainAnswer(e, price, []string{source}, 0)
+	return e
+}
+
+// explainAnswer records the answer, and the single source it came from if it was not
+// aggregated by the node, taking elapsed to fetch
+func (o *OOOApi) explainAnswer(e *PriceExplanation, price string, sources []string, elapsed time.Duration) {
+	e.Answer = price
+
+	if len(e.Values) == 0 {
+		// single source answers are not aggregated by the node
+		source := strings.Join(sources, ",")
+		e.Values = []ExplainedValue{{Source: source, Value: o.answerToFloat(price), Weight: 1, Latency: elapsed}}
+		e.setMethod("single source (%s)", source)
+		if source == FinchainsSourceName {
+			e.setMethod("single source (%s), aggregated upstream", source)
+		}
+	}
+}
+
+// DefaultPairEndpoint returns the endpoint explained for a pair when none is given - the
+// Finchains average for supported pairs, and the DEX ad-hoc price otherwise
+func (o *OOOApi) DefaultPairEndpoint(base string, target string) string {
+	base = strings.ToUpper(base)
+	target = strings.ToUpper(target)
+
+	if o.mock == nil {
+		supported, err := o.db.PairIsSupportedByBaseAndTarget(base, target)
+		if err != nil || supported.ID == 0 {
+			return fmt.Sprintf("%s.%s.AD", base, target)
+		}
+	}
+
+	return fmt.Sprintf("%s.%s.PR.AVC", base, target)
+}
+
+// markExplainedValue excludes the first included value equal to value
+func markExplainedValue(values []ExplainedValue, value float64, reason string) {
+	for i := range values {
+		if values[i].Excluded == "" && values[i].Value == value {
+			values[i].Excluded = reason
+			return
+		}
+	}
+}
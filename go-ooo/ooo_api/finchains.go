@@ -0,0 +1,199 @@
+package ooo_api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"go-ooo/config"
+	"go-ooo/httpclient"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// finchainsEndpoint is a single configured Finchains API base URL,
+// and its last known health state
+type finchainsEndpoint struct {
+	baseURL     string
+	healthy     bool
+	lastChecked time.Time
+	lastError   string
+}
+
+// finchainsEndpoints holds the primary and any secondary Finchains API
+// base URLs. Queries are sent to the first healthy endpoint, in priority order,
+// so the primary is always preferred once it has recovered.
+type finchainsEndpoints struct {
+	mu        sync.RWMutex
+	endpoints []*finchainsEndpoint
+}
+
+func newFinchainsEndpoints(urls ...string) *finchainsEndpoints {
+	f := &finchainsEndpoints{}
+	for _, u := range urls {
+		if u == "" {
+			continue
+		}
+		f.endpoints = append(f.endpoints, &finchainsEndpoint{
+			baseURL: u,
+			healthy: true,
+		})
+	}
+	return f
+}
+
+// ordered returns the endpoints to try for a query - healthy endpoints first,
+// in priority order, followed by unhealthy endpoints as a last resort
+func (f *finchainsEndpoints) ordered() []*finchainsEndpoint {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	healthy := make([]*finchainsEndpoint, 0, len(f.endpoints))
+	unhealthy := make([]*finchainsEndpoint, 0, len(f.endpoints))
+	for _, e := range f.endpoints {
+		if e.healthy {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// urls returns the endpoints' base URLs, in priority order
+func (f *finchainsEndpoints) urls() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	urls := make([]string, 0, len(f.endpoints))
+	for _, e := range f.endpoints {
+		urls = append(urls, e.baseURL)
+	}
+	return urls
+}
+
+func (f *finchainsEndpoints) setHealth(e *finchainsEndpoint, healthy bool, err error) (changed bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	changed = e.healthy != healthy
+	e.healthy = healthy
+	e.lastChecked = time.Now()
+	e.lastError = ""
+	if err != nil {
+		e.lastError = err.Error()
+	}
+	return
+}
+
+// finchainsGet will run a GET request for the uri against the Finchains API,
+// failing over to the secondary endpoint(s) if the request fails
+func (o *OOOApi) finchainsGet(ctx context.Context, uri string) ([]byte, error) {
+	endpoints := o.finchains.ordered()
+
+	if len(endpoints) == 0 {
+		return nil, errors.New("no finchains api url configured")
+	}
+
+	var lastErr error
+	for _, e := range endpoints {
+		body, err := o.finchainsGetFrom(ctx, e.baseURL, uri)
+		if err == nil {
+			if o.finchains.setHealth(e, true, nil) {
+				o.logger.WithFields(logrus.Fields{
+					"package":  "ooo_api",
+					"function": "finchainsGet",
+					"url":      e.baseURL,
+				}).Info("finchains endpoint recovered")
+			}
+			return body, nil
+		}
+
+		if ctx.Err() != nil {
+			// cancelled, rather than the endpoint failing
+			return nil, ctx.Err()
+		}
+
+		if SourceErrorClass(err) == SourceErrorPermanent {
+			// every endpoint would give the same answer
+			return nil, err
+		}
+
+		lastErr = err
+
+		if o.finchains.setHealth(e, false, err) {
+			o.logger.WithFields(logrus.Fields{
+				"package":  "ooo_api",
+				"function": "finchainsGet",
+				"url":      e.baseURL,
+			}).Warn(fmt.Sprintf("finchains endpoint failed, failing over: %s", err.Error()))
+		}
+	}
+
+	return nil, lastErr
+}
+
+func (o *OOOApi) finchainsGetFrom(ctx context.Context, baseURL string, uri string) ([]byte, error) {
+	baseURL, err := o.credentials.Expand(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprint(baseURL, "/", uri), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := o.client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer httpclient.Close(resp.Body)
+
+	// a 404 is the pair not being listed, rather than the endpoint failing
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusNotFound {
+		return nil, newHttpStatusError(resp)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// CheckFinchainsHealth checks each configured Finchains endpoint, so that a failed
+// endpoint can be brought back into use once it has recovered
+func (o *OOOApi) CheckFinchainsHealth() {
+	if o.mock != nil || !config.FeatureEnabled(config.FeaturesCex) {
+		// no data sources are queried in mock source mode, or with the CEX sources disabled
+		return
+	}
+
+	o.finchains.mu.RLock()
+	endpoints := make([]*finchainsEndpoint, len(o.finchains.endpoints))
+	copy(endpoints, o.finchains.endpoints)
+	o.finchains.mu.RUnlock()
+
+	for _, e := range endpoints {
+		_, err := o.finchainsGetFrom(o.ctx, e.baseURL, "pairs")
+
+		if o.finchains.setHealth(e, err == nil, err) {
+			o.recordSourceAvailability("", e.baseURL, err)
+			if err == nil {
+				o.logger.WithFields(logrus.Fields{
+					"package":  "ooo_api",
+					"function": "CheckFinchainsHealth",
+					"url":      e.baseURL,
+				}).Info("finchains endpoint healthy")
+			} else {
+				o.logger.WithFields(logrus.Fields{
+					"package":  "ooo_api",
+					"function": "CheckFinchainsHealth",
+					"url":      e.baseURL,
+				}).Warn(fmt.Sprintf("finchains endpoint unhealthy: %s", err.Error()))
+			}
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package ooo_api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"go-ooo/httpclient"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ForexCacheDuration - how long fetched FX rates are used before being refreshed
+const ForexCacheDuration = 10 * time.Minute
+
+// UsdStablecoins are used as USD proxies when pricing a token against fiat on DEXs.
+// Conversion from USD to any other fiat currency uses FX rates.
+var UsdStablecoins = []string{"USDC", "USDT", "DAI"}
+
+// fiatCurrencies are the ISO 4217 codes which can be used as ad-hoc targets
+var fiatCurrencies = map[string]bool{
+	"USD": true, "EUR": true, "GBP": true, "JPY": true, "CHF": true,
+	"AUD": true, "CAD": true, "CNY": true, "HKD": true, "SGD": true,
+	"KRW": true, "INR": true, "BRL": true, "MXN": true, "NZD": true,
+	"SEK": true, "NOK": true, "DKK": true, "PLN": true, "ZAR": true,
+	"TRY": true,
+}
+
+// IsFiatCurrency returns true if the symbol is a supported fiat currency
+func IsFiatCurrency(symbol string) bool {
+	return fiatCurrencies[strings.ToUpper(symbol)]
+}
+
+// ForexRatesResponse is the format returned by ECB/openexchangerates style APIs, for example
+// https://api.frankfurter.app/latest?from=USD or https://openexchangerates.org/api/latest.json
+type ForexRatesResponse struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+type forexRates struct {
+	mu        sync.Mutex
+	url       string
+	base      string
+	rates     map[string]float64
+	fetchedAt time.Time
+}
+
+func newForexRates(url string) *forexRates {
+	return &forexRates{
+		url: url,
+	}
+}
+
+// GetForexRate returns the FX rate to convert an amount in from into to
+func (o *OOOApi) GetForexRate(from string, to string) (float64, error) {
+	from = strings.ToUpper(from)
+	to = strings.ToUpper(to)
+
+	if from == to {
+		return 1, nil
+	}
+
+	o.forex.mu.Lock()
+	defer o.forex.mu.Unlock()
+
+	if o.forex.url == "" {
+		return 0, errors.New("no forex api url configured")
+	}
+
+	if o.forex.rates == nil || time.Since(o.forex.fetchedAt) > ForexCacheDuration {
+		err := o.fetchForexRates()
+		if err != nil {
+			// fall back to the last known rates if we have them
+			if o.forex.rates == nil {
+				return 0, err
+			}
+			o.logger.WithFields(logrus.Fields{
+				"package":    "ooo_api",
+				"function":   "GetForexRate",
+				"fetched_at": o.forex.fetchedAt,
+			}).Warn(fmt.Sprintf("using cached forex rates: %s", err.Error()))
+		}
+	}
+
+	fromRate, ok := o.forex.rates[from]
+	if !ok {
+		return 0, fmt.Errorf("no forex rate for %s", from)
+	}
+	toRate, ok := o.forex.rates[to]
+	if !ok {
+		return 0, fmt.Errorf("no forex rate for %s", to)
+	}
+
+	return toRate / fromRate, nil
+}
+
+// fetchForexRates must be called with o.forex.mu held
+func (o *OOOApi) fetchForexRates() error {
+	url, err := o.credentials.Expand(o.forex.url)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+
+	if err != nil {
+		return err
+	}
+
+	resp, err := o.client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer httpclient.Close(resp.Body)
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("non-200 OK status code: %v", resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return err
+	}
+
+	var result ForexRatesResponse
+
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return err
+	}
+
+	if result.Base == "" {
+		return newValidationError("forex", "base", "missing")
+	}
+
+	rates := make(map[string]float64)
+	for c, r := range result.Rates {
+		if r <= 0 || math.IsNaN(r) || math.IsInf(r, 0) {
+			continue
+		}
+		rates[strings.ToUpper(c)] = r
+	}
+	rates[strings.ToUpper(result.Base)] = 1
+
+	o.forex.base = strings.ToUpper(result.Base)
+	o.forex.rates = rates
+	o.forex.fetchedAt = time.Now()
+
+	o.logger.WithFields(logrus.Fields{
+		"package":   "ooo_api",
+		"function":  "fetchForexRates",
+		"base":      o.forex.base,
+		"num_rates": len(rates),
+	}).Debug("forex rates updated")
+
+	return nil
+}
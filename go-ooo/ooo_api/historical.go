@@ -0,0 +1,454 @@
+package ooo_api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/montanaflynn/stats"
+	"github.com/sirupsen/logrus"
+	"go-ooo/config"
+	"go-ooo/errcodes"
+	"go-ooo/httpclient"
+	"go-ooo/utils"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoricalSubType - request subtype for "price as of timestamp" requests, e.g.
+// BTC.USD.PR.AT.1640995200 or BTC.USD.AD.AT.1640995200
+const HistoricalSubType = "AT"
+
+// klineInterval - candle interval, in seconds, used for historical CEX prices
+const klineInterval = 60
+
+// klineSource is a CEX kline (candle) endpoint, used to get historical prices. fetch
+// returns the close price of the candle which opened at or immediately before ts
+type klineSource struct {
+	name  string
+	fetch func(ctx context.Context, o *OOOApi, base string, target string, ts int64) (float64, error)
+}
+
+// currently supported CEXs for historical queries
+func getKlineSources() []klineSource {
+	if !config.FeatureEnabled(config.FeaturesCex) {
+		return nil
+	}
+	return allKlineSources()
+}
+
+// allKlineSources returns the exchanges klines can be fetched from, whether or not the CEX
+// sources are enabled
+func allKlineSources() []klineSource {
+	return []klineSource{
+		{name: "binance", fetch: fetchBinanceKline},
+		{name: "bitstamp", fetch: fetchBitstampKline},
+		{name: "gdax", fetch: fetchCoinbaseKline},
+	}
+}
+
+// IsHistorical returns true if the endpoint requests the price as of a timestamp
+func IsHistorical(endpoint string) (bool, error) {
+	_, _, _, subtype, supp1, _, _, err := ParseEndpoint(endpoint)
+
+	if err != nil {
+		return false, err
+	}
+
+	if subtype != HistoricalSubType {
+		return false, nil
+	}
+
+	_, err = parseHistoricalTimestamp(supp1)
+
+	return err == nil, err
+}
+
+// HistoricalEndpoint returns the endpoint requesting the price the endpoint asks for, as of ts.
+// Price requests are answered with the exchange kline mean, and ad-hoc requests from subgraph
+// snapshots, so the modifiers of price requests, e.g. the dMax of AVC, do not carry over.
+// JSON feeds have no history, and endpoints which are already historical are unchanged
+func HistoricalEndpoint(endpoint string, ts int64) (string, error) {
+	base, target, qType, subtype, _, _, _, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	if subtype == HistoricalSubType {
+		return endpoint, nil
+	}
+
+	switch qType {
+	case "PR", "AD":
+		return fmt.Sprintf("%s.%s.%s.%s.%d", base, target, qType, HistoricalSubType, ts), nil
+	case JsonFeedType:
+		return "", errors.New("json feeds have no historical data")
+	default:
+		return "", fmt.Errorf("type %s not supported", qType)
+	}
+}
+
+func parseHistoricalTimestamp(ts string) (int64, error) {
+	timestamp, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp: %s", ts)
+	}
+
+	// the candle/snapshots for the timestamp need to have closed
+	if timestamp <= 0 || timestamp+klineInterval > time.Now().Unix() {
+		return 0, fmt.Errorf("timestamp must be in the past: %s", ts)
+	}
+
+	return timestamp, nil
+}
+
+// getBlockNumAtTimestamp binary searches the chain for the last block mined at or before ts
+func (o *OOOApi) getBlockNumAtTimestamp(ctx context.Context, chain string, ts int64, currentBlock uint64) (uint64, error) {
+	client := o.getSubchainClient(chain)
+	if client == nil || currentBlock == 0 {
+		return 0, fmt.Errorf("no rpc client for chain %s", chain)
+	}
+
+	blockTime := func(num uint64) (int64, error) {
+		header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(num))
+		if err != nil {
+			return 0, err
+		}
+		return int64(header.Time), nil
+	}
+
+	firstTime, err := blockTime(1)
+	if err != nil {
+		return 0, err
+	}
+	if ts < firstTime {
+		return 0, fmt.Errorf("timestamp %d is before chain %s genesis", ts, chain)
+	}
+
+	low, high := uint64(1), currentBlock
+	for low < high {
+		mid := low + (high-low+1)/2
+		t, err := blockTime(mid)
+		if err != nil {
+			return 0, err
+		}
+		if t <= ts {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+
+	return low, nil
+}
+
+// GetHistoricalForexRate returns the FX rate for the day of ts. The configured forex
+// api url must be a Frankfurter style ".../latest" url.
+func (o *OOOApi) GetHistoricalForexRate(from string, to string, ts int64) (float64, error) {
+	from = strings.ToUpper(from)
+	to = strings.ToUpper(to)
+
+	if from == to {
+		return 1, nil
+	}
+
+	if !strings.Contains(o.forex.url, "/latest") {
+		return 0, errors.New("forex api url does not support historical rates")
+	}
+
+	date := time.Unix(ts, 0).UTC().Format("2006-01-02")
+	url := strings.Replace(o.forex.url, "/latest", "/"+date, 1)
+
+	body, err := o.klineGet(o.ctx, url)
+	if err != nil {
+		return 0, err
+	}
+
+	var result ForexRatesResponse
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	fromRate := float64(1)
+	if from != strings.ToUpper(result.Base) {
+		fromRate = result.Rates[from]
+	}
+	toRate := float64(1)
+	if to != strings.ToUpper(result.Base) {
+		toRate = result.Rates[to]
+	}
+
+	if fromRate <= 0 || toRate <= 0 {
+		return 0, fmt.Errorf("no forex rate for %s/%s on %s", from, to, date)
+	}
+
+	return toRate / fromRate, nil
+}
+
+// QueryHistoricalKlines returns the mean close price as of the endpoint's timestamp, from CEX kline endpoints,
+// along with the names of the exchanges that contributed
+func (o *OOOApi) QueryHistoricalKlines(ctx context.Context, endpoint string, requestId string) (string, []string, error) {
+	return o.queryHistoricalKlines(ctx, endpoint, requestId, nil)
+}
+
+func (o *OOOApi) queryHistoricalKlines(ctx context.Context, endpoint string, requestId string, explain *PriceExplanation) (string, []string, error) {
+	base, target, _, subtype, supp1, _, _, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if subtype != HistoricalSubType {
+		return "", nil, errors.New("not a historical price request")
+	}
+
+	ts, err := parseHistoricalTimestamp(supp1)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var prices []float64
+	var sources []string
+	var rejections []error
+
+	for _, k := range getKlineSources() {
+		if !o.sourceAllowed(ctx, base, target, k.name) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return "", nil, ctx.Err()
+		}
+		start := time.Now()
+		var price float64
+		err := o.querySource(ctx, k.name, base, target, func() (err error) {
+			price, err = k.fetch(ctx, o, base, target, ts)
+			return err
+		})
+		explain.recordLatency(k.name, start)
+		if err != nil {
+			rejections = append(rejections, newValidationError(k.name, "kline", err.Error()))
+			continue
+		}
+		if err := o.checkPriceBound(k.name, base, target, price); err != nil {
+			rejections = append(rejections, err)
+			continue
+		}
+		prices = append(prices, price)
+		sources = append(sources, k.name)
+	}
+
+	explain.reject(rejections)
+
+	if len(rejections) > 0 {
+		o.logger.WithFields(logrus.Fields{
+			"package":       "ooo_api",
+			"function":      "QueryHistoricalKlines",
+			"requestId":     requestId,
+			"num_accepted":  len(prices),
+			"num_rejected":  len(rejections),
+			"rejected_data": summariseRejections(rejections),
+		}).Debug("kline data rejected")
+	}
+
+	if len(prices) == 0 {
+		if len(rejections) == 0 {
+			return "", nil, errcodes.Errorf(errcodes.ErrInsufficientSources, "no valid prices: no sources enabled for pair")
+		}
+		return "", nil, errcodes.Errorf(errcodes.ErrInsufficientSources, "no valid prices: %s", summariseRejections(rejections))
+	}
+
+	mean, err := stats.Mean(prices)
+	if err != nil {
+		return "", nil, err
+	}
+
+	explained := make([]ExplainedValue, len(prices))
+	for i, p := range prices {
+		explained[i] = ExplainedValue{Source: sources[i], Value: p, Weight: 1 / float64(len(prices))}
+	}
+	explain.addValues(explained)
+	explain.setStats(mean, 0, 0)
+	explain.setMethod("mean of exchange kline close prices at %d", ts)
+
+	var scaled *big.Int
+	if o.exactMath {
+		// the mean of the closes as quoted, rather than of their float64 values
+		exact := make([]*big.Rat, len(prices))
+		for i, p := range prices {
+			exact[i] = ratFromFloat(p)
+		}
+		scaled, err = utils.ScaleRatToDecimalsRounded(newExactStats(exact).mean, o.answerDecimals, o.answerRounding)
+	} else {
+		scaled, err = utils.ScaleToDecimalsRounded(big.NewFloat(mean), o.answerDecimals, o.answerRounding)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot scale price %v to %d decimals: %s", mean, o.answerDecimals, err.Error())
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":   "ooo_api",
+		"function":  "QueryHistoricalKlines",
+		"requestId": requestId,
+		"base":      base,
+		"target":    target,
+		"timestamp": ts,
+		"mean":      mean,
+		"sources":   strings.Join(sources, ","),
+	}).Debug("historical price stats")
+
+	return scaled.String(), sources, nil
+}
+
+func (o *OOOApi) klineGet(ctx context.Context, url string) ([]byte, error) {
+	// the historical FX url is derived from the forex api url, which may reference a credential
+	url, err := o.credentials.Expand(url)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer httpclient.Close(resp.Body)
+
+	if resp.StatusCode != 200 {
+		return nil, newHttpStatusError(resp)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+func parseKlineClose(closePrice string) (float64, error) {
+	price, err := strconv.ParseFloat(closePrice, 64)
+	if err != nil || price <= 0 {
+		return 0, fmt.Errorf("invalid close price: %s", closePrice)
+	}
+	return price, nil
+}
+
+// checkKlineOpenTime ensures the exchange returned the candle for ts, not a later one
+func checkKlineOpenTime(openTime int64, ts int64) error {
+	if openTime > ts || ts-openTime >= klineInterval {
+		return fmt.Errorf("no candle for timestamp %d", ts)
+	}
+	return nil
+}
+
+func fetchBinanceKline(ctx context.Context, o *OOOApi, base string, target string, ts int64) (float64, error) {
+	// binance has no fiat USD markets
+	if target == "USD" {
+		target = "USDT"
+	}
+
+	start := (ts - ts%klineInterval) * 1000
+	url := fmt.Sprintf("https://api.binance.com/api/v3/klines?symbol=%s%s&interval=1m&startTime=%d&limit=1", base, target, start)
+
+	body, err := o.klineGet(ctx, url)
+	if err != nil {
+		return 0, err
+	}
+
+	// [[open time, open, high, low, close, ...]]
+	var result [][]interface{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(result) == 0 || len(result[0]) < 5 {
+		return 0, fmt.Errorf("no candle for timestamp %d", ts)
+	}
+
+	openTime, ok := result[0][0].(float64)
+	if !ok {
+		return 0, errors.New("invalid open time")
+	}
+	if err = checkKlineOpenTime(int64(openTime)/1000, ts); err != nil {
+		return 0, err
+	}
+
+	closePrice, ok := result[0][4].(string)
+	if !ok {
+		return 0, errors.New("invalid close price")
+	}
+
+	return parseKlineClose(closePrice)
+}
+
+func fetchBitstampKline(ctx context.Context, o *OOOApi, base string, target string, ts int64) (float64, error) {
+	start := ts - ts%klineInterval
+	url := fmt.Sprintf("https://www.bitstamp.net/api/v2/ohlc/%s%s/?step=%d&limit=1&start=%d",
+		strings.ToLower(base), strings.ToLower(target), klineInterval, start)
+
+	body, err := o.klineGet(ctx, url)
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		Data struct {
+			Ohlc []struct {
+				Timestamp string `json:"timestamp"`
+				Close     string `json:"close"`
+			} `json:"ohlc"`
+		} `json:"data"`
+	}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(result.Data.Ohlc) == 0 {
+		return 0, fmt.Errorf("no candle for timestamp %d", ts)
+	}
+
+	openTime, err := strconv.ParseInt(result.Data.Ohlc[0].Timestamp, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid open time")
+	}
+	if err = checkKlineOpenTime(openTime, ts); err != nil {
+		return 0, err
+	}
+
+	return parseKlineClose(result.Data.Ohlc[0].Close)
+}
+
+func fetchCoinbaseKline(ctx context.Context, o *OOOApi, base string, target string, ts int64) (float64, error) {
+	start := ts - ts%klineInterval
+	url := fmt.Sprintf("https://api.exchange.coinbase.com/products/%s-%s/candles?granularity=%d&start=%d&end=%d",
+		base, target, klineInterval, start, start+klineInterval)
+
+	body, err := o.klineGet(ctx, url)
+	if err != nil {
+		return 0, err
+	}
+
+	// [[time, low, high, open, close, volume]], most recent first
+	var result [][]float64
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, candle := range result {
+		if len(candle) < 5 {
+			continue
+		}
+		if checkKlineOpenTime(int64(candle[0]), ts) == nil {
+			return parseKlineClose(strconv.FormatFloat(candle[4], 'f', -1, 64))
+		}
+	}
+
+	return 0, fmt.Errorf("no candle for timestamp %d", ts)
+}
@@ -0,0 +1,236 @@
+package ooo_api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/httpclient"
+	"go-ooo/utils"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+// JsonFeedType - request type for generic JSON feeds, e.g. EUR.USD.JS.ECBFX
+// where ECBFX is the name of a configured feed
+const JsonFeedType = "JS"
+
+// JsonFeed is an operator configured REST endpoint, from which a single numeric value is
+// extracted. {base} and {target} in the url are replaced with the request's base and target, e.g.
+//
+//	[[jobs.json_feeds]]
+//	name = "ECBFX"
+//	url = "https://api.example.com/rates/{base}?symbols={target}"
+//	path = "rates.{target}"
+//	multiplier = "1"
+//	quote = "USDC"
+//	decimals = 6
+//	[jobs.json_feeds.headers]
+//	X-Api-Key = "{credential:ecbfx}"
+//
+// {credential:NAME} in the url or headers is replaced with the adapter credential NAME, set with
+// 'go-ooo credentials set', so that the key need not be in the config file. The extracted value is divided by 10^decimals
+// (default 0) if it is in base units, multiplied by multiplier (default 1), converted from quote,
+// if set, to the request's target through USD, then scaled to the answer decimals
+type JsonFeed struct {
+	Name       string            `mapstructure:"name"`
+	Url        string            `mapstructure:"url"`
+	Path       string            `mapstructure:"path"`
+	Multiplier string            `mapstructure:"multiplier"`
+	Headers    map[string]string `mapstructure:"headers"`
+	Quote      string            `mapstructure:"quote"`
+	Decimals   uint              `mapstructure:"decimals"`
+}
+
+func loadJsonFeeds() (map[string]JsonFeed, error) {
+	var feeds []JsonFeed
+	var err error
+	if raw, ok := viper.Get(config.JobsJsonFeeds).(string); ok {
+		// set by an environment variable, as a JSON array of feeds
+		err = json.Unmarshal([]byte(raw), &feeds)
+	} else {
+		err = viper.UnmarshalKey(config.JobsJsonFeeds, &feeds)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]JsonFeed)
+	for _, f := range feeds {
+		name := strings.ToUpper(f.Name)
+		if name == "" || f.Url == "" || f.Path == "" {
+			return nil, fmt.Errorf("json feed requires name, url and path")
+		}
+		if _, ok := res[name]; ok {
+			return nil, fmt.Errorf("duplicate json feed %s", name)
+		}
+		if _, err := parseJsonPath(f.Path); err != nil {
+			return nil, fmt.Errorf("json feed %s: %s", name, err.Error())
+		}
+		if f.Multiplier == "" {
+			f.Multiplier = "1"
+		}
+		if _, err := validateDecimalString(name, "multiplier", f.Multiplier, false); err != nil {
+			return nil, err
+		}
+		if f.Quote != "" && !strings.EqualFold(f.Quote, QuoteUsd) && !IsUsdStablecoin(f.Quote) && !IsFiatCurrency(f.Quote) {
+			return nil, fmt.Errorf("json feed %s: quote %s is not USD, a fiat currency or a USD stablecoin", name, f.Quote)
+		}
+		res[name] = f
+	}
+
+	return res, nil
+}
+
+// IsJsonFeed returns true if the endpoint requests a value from a generic JSON feed
+func IsJsonFeed(endpoint string) (bool, error) {
+	_, _, qType, _, _, _, _, err := ParseEndpoint(endpoint)
+
+	if err != nil {
+		return false, err
+	}
+
+	return qType == JsonFeedType, nil
+}
+
+// QueryJsonFeed calls the configured JSON feed for the endpoint and returns the extracted value,
+// scaled to the answer decimals, and the source name
+func (o *OOOApi) QueryJsonFeed(ctx context.Context, endpoint string, requestId string) (string, []string, error) {
+	base, target, qType, feedName, _, _, _, err := ParseEndpoint(endpoint)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if qType != JsonFeedType {
+		return "", nil, fmt.Errorf("not a json feed request")
+	}
+
+	feed, ok := o.jsonFeeds[strings.ToUpper(feedName)]
+	if !ok {
+		return "", nil, fmt.Errorf("json feed %s not configured", feedName)
+	}
+
+	sourceName := jsonFeedSourceName(feed)
+
+	if !o.sourceAllowed(ctx, base, target, sourceName) {
+		return "", nil, fmt.Errorf("source %s disabled for pair %s.%s", sourceName, base, target)
+	}
+
+	var price string
+	err = o.querySource(ctx, sourceName, base, target, func() (err error) {
+		price, err = o.fetchJsonFeed(ctx, feed, base, target, requestId)
+		return err
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return price, []string{sourceName}, nil
+}
+
+// jsonFeedSourceName returns the feed's source name, as used in pair source overrides
+func jsonFeedSourceName(feed JsonFeed) string {
+	return fmt.Sprintf("json:%s", strings.ToLower(feed.Name))
+}
+
+// fetchJsonFeed calls the feed for base/target and returns the extracted value, scaled to
+// the answer decimals
+func (o *OOOApi) fetchJsonFeed(ctx context.Context, feed JsonFeed, base string, target string, requestId string) (string, error) {
+	sourceName := jsonFeedSourceName(feed)
+
+	replacer := strings.NewReplacer("{base}", base, "{target}", target)
+	url := replacer.Replace(feed.Url)
+	segments, _ := parseJsonPath(replacer.Replace(feed.Path))
+
+	o.logger.WithFields(logrus.Fields{
+		"package":   "ooo_api",
+		"function":  "QueryJsonFeed",
+		"requestId": requestId,
+		"feed":      feed.Name,
+		"url":       url,
+	}).Debug("query json feed")
+
+	// credentials are expanded after logging the url, so that only their names are logged
+	url, headers, err := o.credentials.ExpandRequest(url, feed.Headers)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer httpclient.Close(resp.Body)
+
+	if resp.StatusCode != 200 {
+		return "", newHttpStatusError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	err = decoder.Decode(&doc)
+	if err != nil {
+		return "", err
+	}
+
+	v, err := extractJsonPath(doc, segments)
+	if err != nil {
+		return "", schemaError{newValidationError(sourceName, "path", err.Error())}
+	}
+
+	valueStr, err := jsonValueToString(v)
+	if err != nil {
+		return "", newValidationError(sourceName, "value", err.Error())
+	}
+
+	value, err := validateDecimalString(sourceName, "value", valueStr, false)
+	if err != nil {
+		return "", err
+	}
+
+	value = fromBaseUnits(value, feed.Decimals)
+	multiplier, _ := utils.ParseBigFloat(feed.Multiplier)
+	value = new(big.Float).SetPrec(value.Prec()).Mul(value, multiplier)
+
+	if feed.Quote != "" {
+		rate, err := o.quoteConversionRate(feed.Quote, target, false, 0)
+		if err != nil {
+			return "", newValidationError(sourceName, "quote", err.Error())
+		}
+		value = new(big.Float).SetPrec(value.Prec()).Mul(value, big.NewFloat(rate))
+	}
+
+	f, _ := value.Float64()
+	if err := o.checkPriceBound(sourceName, base, target, f); err != nil {
+		return "", err
+	}
+
+	scaled, err := utils.ScaleToDecimalsRounded(value, o.answerDecimals, o.answerRounding)
+	if err != nil {
+		return "", fmt.Errorf("cannot scale value %s to %d decimals: %s", valueStr, o.answerDecimals, err.Error())
+	}
+
+	return scaled.String(), nil
+}
@@ -0,0 +1,121 @@
+package ooo_api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseJsonPath splits a simple JSONPath/gjson style expression into its segments.
+// Supported forms are dot separated keys and array indices, e.g.
+//
+//	data.price
+//	$.data.quotes[0].price
+//	data.quotes.0.price
+//
+// A dot in a key can be escaped with a backslash, e.g. rates.BTC\.USD
+func parseJsonPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+
+	if path == "" {
+		return nil, fmt.Errorf("empty json path")
+	}
+
+	var segments []string
+	var current strings.Builder
+
+	flush := func() error {
+		if current.Len() == 0 {
+			return fmt.Errorf("invalid json path: %s", path)
+		}
+		segments = append(segments, current.String())
+		current.Reset()
+		return nil
+	}
+
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '\\' && i+1 < len(path):
+			i++
+			current.WriteByte(path[i])
+		case c == '.':
+			// a '.' directly after an index, e.g. [0].price, is a separator only
+			if current.Len() == 0 && i > 0 && path[i-1] == ']' {
+				continue
+			}
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case c == '[':
+			if current.Len() > 0 {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path: %s", path)
+			}
+			idx := path[i+1 : i+end]
+			if _, err := strconv.Atoi(idx); err != nil {
+				return nil, fmt.Errorf("invalid array index in json path: %s", path)
+			}
+			segments = append(segments, idx)
+			i += end
+		default:
+			current.WriteByte(c)
+		}
+	}
+
+	if current.Len() > 0 {
+		segments = append(segments, current.String())
+	} else if len(path) > 0 && path[len(path)-1] == '.' {
+		return nil, fmt.Errorf("invalid json path: %s", path)
+	}
+
+	return segments, nil
+}
+
+// extractJsonPath returns the value at path in the decoded JSON document. The document
+// should be decoded using json.Decoder.UseNumber so that numeric precision is retained
+func extractJsonPath(doc interface{}, segments []string) (interface{}, error) {
+	current := doc
+
+	for i, seg := range segments {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			v, ok := node[seg]
+			if !ok {
+				return nil, fmt.Errorf("key %s not found", strings.Join(segments[:i+1], "."))
+			}
+			current = v
+		case []interface{}:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, fmt.Errorf("index %s out of range", strings.Join(segments[:i+1], "."))
+			}
+			current = node[idx]
+		default:
+			return nil, fmt.Errorf("cannot traverse %s", strings.Join(segments[:i+1], "."))
+		}
+	}
+
+	return current, nil
+}
+
+// jsonValueToString converts an extracted numeric value to its decimal string
+func jsonValueToString(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case json.Number:
+		return val.String(), nil
+	case string:
+		return strings.TrimSpace(val), nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("value is not numeric: %v", v)
+	}
+}
@@ -0,0 +1,212 @@
+package ooo_api
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"go-ooo/database/models"
+	"go-ooo/utils"
+	"sync"
+	"time"
+)
+
+// LiquidityActivePairsWindow - ad-hoc pairs requested within this window are monitored
+const LiquidityActivePairsWindow = 24 * time.Hour
+
+var (
+	pairLiquidityGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ooo_dex_pair_liquidity_usd",
+		Help: "Liquidity, in USD, of actively answered DEX pairs",
+	}, []string{"dex", "pair"})
+
+	pairLiquidityAlerts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_dex_pair_liquidity_alerts_total",
+		Help: "Number of times an actively answered DEX pair's liquidity fell below the alert threshold",
+	}, []string{"dex", "pair"})
+)
+
+// liquidityMonitor tracks which actively answered pairs are currently below the
+// alert threshold, so that the operator is alerted once when liquidity collapses
+type liquidityMonitor struct {
+	mu        sync.Mutex
+	threshold float64
+	low       map[string]bool
+}
+
+func newLiquidityMonitor(threshold float64) *liquidityMonitor {
+	if threshold <= 0 {
+		threshold = MinLiquidity
+	}
+	return &liquidityMonitor{
+		threshold: threshold,
+		low:       make(map[string]bool),
+	}
+}
+
+// CheckActivePairLiquidity re-checks the liquidity of the DEX pairs used to answer recent
+// ad-hoc requests, and alerts if any have fallen below the configured threshold
+func (o *OOOApi) CheckActivePairLiquidity() {
+	if o.mock != nil {
+		// no data sources are queried in mock source mode
+		return
+	}
+
+	endpoints, err := o.db.GetRecentAdhocEndpoints(time.Now().Add(-LiquidityActivePairsWindow))
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "CheckActivePairLiquidity",
+			"action":   "get recent ad-hoc endpoints",
+		}).Error(err.Error())
+		return
+	}
+
+	if len(endpoints) == 0 {
+		return
+	}
+
+	for _, api := range getQlApis() {
+		activePairs := o.getActiveDexPairs(endpoints, api["name"])
+		if len(activePairs) == 0 {
+			continue
+		}
+
+		ids := make([]string, 0, len(activePairs))
+		for id := range activePairs {
+			ids = append(ids, id)
+		}
+
+		reserves, err := o.getPairReserves(ids, api)
+		if err != nil {
+			o.logger.WithFields(logrus.Fields{
+				"package":  "ooo_api",
+				"function": "CheckActivePairLiquidity",
+				"dex":      api["name"],
+			}).Error(err.Error())
+			continue
+		}
+
+		for id, pair := range activePairs {
+			reserveUsd, ok := reserves[id]
+			if !ok {
+				// no longer indexed by the subgraph
+				reserveUsd = 0
+			}
+			_ = o.db.UpdateDexPairReserveUsd(id, api["name"], reserveUsd)
+			o.recordPairLiquidity(api["name"], pair, reserveUsd)
+		}
+	}
+}
+
+// getActiveDexPairs returns the DEX pairs, keyed by contract address, that would be used
+// to answer the given ad-hoc endpoints
+func (o *OOOApi) getActiveDexPairs(endpoints []string, dexName string) map[string]models.DexPairs {
+	pairs := make(map[string]models.DexPairs)
+
+	for _, ep := range endpoints {
+		base, target, _, _, _, _, _, err := ParseEndpoint(ep)
+		if err != nil {
+			continue
+		}
+		if !o.pairSources.allowed(base, target, dexName) {
+			continue
+		}
+
+		targets := []string{target}
+		if IsFiatCurrency(target) {
+			targets = UsdStablecoins
+		}
+
+		for _, t := range targets {
+			p, _ := o.findDexPair(base, t, dexName)
+			if p.ID != 0 {
+				pairs[p.ContractAddress] = p
+			}
+		}
+	}
+
+	return pairs
+}
+
+func (o *OOOApi) getPairReserves(ids []string, api map[string]string) (map[string]float64, error) {
+	reserves := make(map[string]float64)
+
+	for start := 0; start < len(ids); start += GraphQlPairBatchSize {
+		end := start + GraphQlPairBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		query := generatePairsByIdQuery(ids[start:end], api["pairs_endpoint"], api["pairs_order_by"], 0, 0)
+
+		var decodedResponse GraphQlPairsResponse
+
+		err := o.runQuery(query, api, QueryClassSync, &decodedResponse)
+		if err != nil {
+			return nil, err
+		}
+
+		pairs := decodedResponse.Data.Pairs
+		if usesPools(api) {
+			pairs = decodedResponse.Data.Pools
+		}
+
+		for _, pair := range pairs {
+			dexReserveUSD := pair.ReserveUSD
+			if usesPools(api) {
+				dexReserveUSD = pair.TotalValueLockedUSD
+			}
+
+			reserve, err := utils.ParseBigFloat(dexReserveUSD)
+			if err != nil {
+				continue
+			}
+			reserves[pair.Id], _ = reserve.Float64()
+		}
+	}
+
+	return reserves, nil
+}
+
+func (o *OOOApi) recordPairLiquidity(dexName string, pair models.DexPairs, reserveUsd float64) {
+	pairName := pair.GetPair()
+	key := fmt.Sprintf("%s:%s", dexName, pair.GetContractAddress())
+
+	pairLiquidityGauge.WithLabelValues(dexName, pairName).Set(reserveUsd)
+
+	o.liquidity.mu.Lock()
+	wasLow := o.liquidity.low[key]
+	threshold := o.liquidity.threshold
+	isLow := reserveUsd < threshold
+	o.liquidity.low[key] = isLow
+	o.liquidity.mu.Unlock()
+
+	if isLow && !wasLow {
+		pairLiquidityAlerts.WithLabelValues(dexName, pairName).Inc()
+		o.logger.WithFields(logrus.Fields{
+			"package":       "ooo_api",
+			"function":      "recordPairLiquidity",
+			"dex":           dexName,
+			"pair":          pairName,
+			"pair_address":  pair.GetContractAddress(),
+			"previous_usd":  pair.ReserveUsd,
+			"liquidity_usd": reserveUsd,
+			"threshold_usd": threshold,
+		}).Warn("ALERT: liquidity for actively answered pair fell below threshold")
+		o.recordPairChange(pairName, models.PAIR_CHANGE_LIQUIDITY_DROPPED, dexName,
+			fmt.Sprintf("liquidity of pair contract %s fell to $%.2f, below $%.2f", pair.GetContractAddress(), reserveUsd, threshold))
+	} else if !isLow && wasLow {
+		o.logger.WithFields(logrus.Fields{
+			"package":       "ooo_api",
+			"function":      "recordPairLiquidity",
+			"dex":           dexName,
+			"pair":          pairName,
+			"pair_address":  pair.GetContractAddress(),
+			"liquidity_usd": reserveUsd,
+			"threshold_usd": threshold,
+		}).Info("liquidity for pair recovered above threshold")
+		o.recordPairChange(pairName, models.PAIR_CHANGE_LIQUIDITY_RECOVERED, dexName,
+			fmt.Sprintf("liquidity of pair contract %s recovered to $%.2f, above $%.2f", pair.GetContractAddress(), reserveUsd, threshold))
+	}
+}
@@ -0,0 +1,60 @@
+package ooo_api
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	sourceFetchLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ooo_source_fetch_latency_seconds",
+		Help:    "Time taken by upstream data source requests, by host",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"host", "status"})
+
+	sourceFetchErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_source_fetch_errors_total",
+		Help: "Number of upstream data source requests which failed or returned a non-200 status, by host",
+	}, []string{"host"})
+)
+
+// instrumentedTransport records the latency and outcome of every upstream data source request
+type instrumentedTransport struct {
+	next http.RoundTripper
+}
+
+func (t instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	host := req.URL.Host
+	if err != nil && req.Context().Err() != nil {
+		// the request was cancelled or expired, which says nothing about the source's health
+		sourceFetchLatency.WithLabelValues(host, "cancelled").Observe(time.Since(start).Seconds())
+		return resp, err
+	}
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+
+	sourceFetchLatency.WithLabelValues(host, status).Observe(time.Since(start).Seconds())
+	if err != nil || resp.StatusCode != http.StatusOK {
+		sourceFetchErrors.WithLabelValues(host).Inc()
+	}
+
+	if err != nil {
+		hostHealth.record(host, SourceKindHttp, err)
+	} else if resp.StatusCode != http.StatusOK {
+		hostHealth.record(host, SourceKindHttp, fmt.Errorf("non-200 OK status code: %v", resp.Status))
+	} else {
+		hostHealth.record(host, SourceKindHttp, nil)
+	}
+
+	return resp, err
+}
@@ -0,0 +1,212 @@
+package ooo_api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/utils"
+	"hash/fnv"
+	"strings"
+	"sync"
+)
+
+// MockSourceName - source name reported for answers from mock sources
+const MockSourceName = "mock"
+
+// mockScriptError - a scripted price which fails the fetch, to exercise retries and alerts
+const mockScriptError = "error"
+
+// MockPrice sets the price answered for a pair in mock source mode, e.g.
+//
+//	default = "1"
+//
+//	[[pairs]]
+//	pair = "BTC.USD"
+//	price = "50000"
+//
+//	[[pairs]]
+//	pair = "ETH.USD"
+//	prices = ["3000", "3010", "error", "2990"]
+//
+// Scripted prices are answered in turn, one per fetch, repeating from the first once all have
+// been answered. "error" fails the fetch. Pairs which are not listed are answered with default,
+// or a price derived from the pair's name if it is not set, so answers are always deterministic
+type MockPrice struct {
+	Pair   string   `mapstructure:"pair"`
+	Price  string   `mapstructure:"price"`
+	Prices []string `mapstructure:"prices"`
+}
+
+type mockPricesFile struct {
+	Default string      `mapstructure:"default"`
+	Pairs   []MockPrice `mapstructure:"pairs"`
+}
+
+// mockSources answers every request with mock prices instead of querying any data source.
+// The prices file is reloaded whenever it changes
+type mockSources struct {
+	mu      sync.Mutex
+	def     string
+	scripts map[string][]string
+	next    map[string]int
+	v       *viper.Viper
+	logger  *logrus.Logger
+}
+
+func newMockSources(file string, logger *logrus.Logger) (*mockSources, error) {
+	m := &mockSources{
+		scripts: make(map[string][]string),
+		next:    make(map[string]int),
+		logger:  logger,
+	}
+
+	if file == "" {
+		return m, nil
+	}
+
+	m.v = viper.New()
+	m.v.SetConfigFile(file)
+
+	err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	m.v.OnConfigChange(func(e fsnotify.Event) {
+		if err := m.load(); err != nil {
+			// keep the previous prices if the new file is invalid
+			m.logger.WithFields(logrus.Fields{
+				"package":  "ooo_api",
+				"function": "mockSources.OnConfigChange",
+				"file":     e.Name,
+			}).Error(err.Error())
+			return
+		}
+		m.logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "mockSources.OnConfigChange",
+			"file":     e.Name,
+		}).Info("mock prices reloaded")
+	})
+	m.v.WatchConfig()
+
+	return m, nil
+}
+
+func (m *mockSources) load() error {
+	err := m.v.ReadInConfig()
+	if err != nil {
+		return err
+	}
+
+	var f mockPricesFile
+	err = m.v.Unmarshal(&f)
+	if err != nil {
+		return err
+	}
+
+	if f.Default != "" {
+		if _, err := utils.ParseBigFloat(f.Default); err != nil {
+			return fmt.Errorf("invalid default mock price %s", f.Default)
+		}
+	}
+
+	scripts := make(map[string][]string)
+	for _, p := range f.Pairs {
+		if p.Pair == "" {
+			return errors.New("mock price missing pair")
+		}
+		script := p.Prices
+		if p.Price != "" {
+			script = []string{p.Price}
+		}
+		if len(script) == 0 {
+			return fmt.Errorf("mock price for %s has no price or prices", p.Pair)
+		}
+		for _, s := range script {
+			if _, err := utils.ParseBigFloat(s); err != nil && !strings.EqualFold(s, mockScriptError) {
+				return fmt.Errorf("invalid mock price %s for %s", s, p.Pair)
+			}
+		}
+		scripts[strings.ToUpper(p.Pair)] = script
+	}
+
+	m.mu.Lock()
+	m.def = f.Default
+	m.scripts = scripts
+	m.next = make(map[string]int)
+	m.mu.Unlock()
+
+	return nil
+}
+
+// price returns the next mock price for base/target, scaled to decimals with rounding
+func (m *mockSources) price(base string, target string, decimals uint, rounding string) (string, error) {
+	pair := strings.ToUpper(fmt.Sprintf("%s.%s", base, target))
+
+	m.mu.Lock()
+	price := m.def
+	if script, ok := m.scripts[pair]; ok {
+		i := m.next[pair]
+		price = script[i%len(script)]
+		m.next[pair] = i + 1
+	}
+	m.mu.Unlock()
+
+	if strings.EqualFold(price, mockScriptError) {
+		return "", fmt.Errorf("scripted mock error for %s", pair)
+	}
+	if price == "" {
+		price = derivedMockPrice(pair)
+	}
+
+	f, err := utils.ParseBigFloat(price)
+	if err != nil {
+		return "", err
+	}
+	scaled, err := utils.ScaleToDecimalsRounded(f, decimals, rounding)
+	if err != nil {
+		return "", err
+	}
+
+	return scaled.String(), nil
+}
+
+// derivedMockPrice returns a price between 1 and 10000 derived from the pair's name
+func derivedMockPrice(pair string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(pair))
+	cents := 100 + h.Sum32()%999900
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+// MockSourcesEnabled returns true if requests are answered from mock sources
+func (o *OOOApi) MockSourcesEnabled() bool {
+	return o.mock != nil
+}
+
+// queryMock answers the endpoint from the mock sources. Every request type is answered with
+// the pair's mock price
+func (o *OOOApi) queryMock(endpoint string, requestId string) (string, []string, error) {
+	base, target, _, _, _, _, _, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return "", nil, err
+	}
+
+	price, err := o.mock.price(base, target, o.answerDecimals, o.answerRounding)
+	if err != nil {
+		return "", nil, err
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":   "ooo_api",
+		"function":  "queryMock",
+		"requestId": requestId,
+		"endpoint":  endpoint,
+		"price":     price,
+	}).Debug("answered from mock sources")
+
+	return price, []string{MockSourceName}, nil
+}
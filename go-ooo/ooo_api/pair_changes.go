@@ -0,0 +1,84 @@
+package ooo_api
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database"
+	"go-ooo/database/models"
+	"sort"
+	"strings"
+)
+
+// recordPairChange adds a change to the pairs to the changelog
+func (o *OOOApi) recordPairChange(pair string, change string, source string, reason string) {
+	err := o.db.InsertPairChanges([]models.PairChanges{{
+		Pair:   pair,
+		Change: change,
+		Source: source,
+		Reason: reason,
+	}})
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "recordPairChange",
+			"pair":     pair,
+			"change":   change,
+		}).Error(err.Error())
+	}
+}
+
+// recordSourceAvailability adds a source becoming unavailable, with err, or available again if
+// err is nil, to the changelog. The change affects every pair, or only pair if it is not empty
+func (o *OOOApi) recordSourceAvailability(pair string, source string, err error) {
+	if err != nil {
+		o.recordPairChange(pair, models.PAIR_CHANGE_SOURCE_UNAVAILABLE, source, err.Error())
+		return
+	}
+	o.recordPairChange(pair, models.PAIR_CHANGE_SOURCE_AVAILABLE, source, "healthy")
+}
+
+// recordBlocklistChanges adds the jobs.exclude_pairs patterns added or removed since the changelog
+// last recorded them, on start up and when the config is reloaded
+func (o *OOOApi) recordBlocklistChanges() {
+	rows, err := o.db.GetPairChanges(database.PairChangeFilter{
+		Changes: []string{models.PAIR_CHANGE_BLOCKLISTED, models.PAIR_CHANGE_UNBLOCKLISTED},
+	})
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "recordBlocklistChanges",
+		}).Error(err.Error())
+		return
+	}
+
+	// most recent first, so the first change seen for a pattern is its current state
+	blocklisted := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, r := range rows {
+		if seen[r.GetPair()] {
+			continue
+		}
+		seen[r.GetPair()] = true
+		blocklisted[r.GetPair()] = r.GetChange() == models.PAIR_CHANGE_BLOCKLISTED
+	}
+
+	current := make(map[string]bool)
+	for _, pattern := range viper.GetStringSlice(config.JobsExcludePairs) {
+		pattern = strings.ToUpper(pattern)
+		current[pattern] = true
+		if !blocklisted[pattern] {
+			o.recordPairChange(pattern, models.PAIR_CHANGE_BLOCKLISTED, "", "added to "+config.JobsExcludePairs)
+		}
+	}
+	removed := make([]string, 0)
+	for pattern, b := range blocklisted {
+		if b && !current[pattern] {
+			removed = append(removed, pattern)
+		}
+	}
+	sort.Strings(removed)
+	for _, pattern := range removed {
+		o.recordPairChange(pattern, models.PAIR_CHANGE_UNBLOCKLISTED, "", "removed from "+config.JobsExcludePairs)
+	}
+}
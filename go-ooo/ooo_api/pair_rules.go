@@ -0,0 +1,52 @@
+package ooo_api
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"path"
+	"strings"
+)
+
+// ValidatePairPattern checks a jobs.serve_pairs or jobs.exclude_pairs entry is a pair, or a
+// BASE.TARGET pattern using * and ? wildcards
+func ValidatePairPattern(pattern string) error {
+	parts := strings.Split(pattern, ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("%q must be of the form BASE.TARGET, e.g. BTC.USD, BTC.* or *.USD", pattern)
+	}
+	if _, err := path.Match(strings.ToUpper(pattern), ""); err != nil {
+		return fmt.Errorf("%q: %s", pattern, err.Error())
+	}
+	return nil
+}
+
+func matchPairPatterns(patterns []string, pair string) (string, bool) {
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToUpper(p), pair); ok {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// checkPairServed rejects pairs the operator has chosen not to answer - those not matched by
+// jobs.serve_pairs, if set, or matched by jobs.exclude_pairs. Both are read per request, so a
+// config reload applies them to new requests
+func checkPairServed(base, target string) *RequestRejection {
+	pair := base + "." + target
+
+	if pattern, ok := matchPairPatterns(viper.GetStringSlice(config.JobsExcludePairs), pair); ok {
+		return newRequestRejection(RejectPairNotServed, "pair %s is excluded by %s %q", pair, config.JobsExcludePairs, pattern)
+	}
+
+	serve := viper.GetStringSlice(config.JobsServePairs)
+	if len(serve) == 0 {
+		return nil
+	}
+	if _, ok := matchPairPatterns(serve, pair); !ok {
+		return newRequestRejection(RejectPairNotServed, "pair %s is not in %s", pair, config.JobsServePairs)
+	}
+
+	return nil
+}
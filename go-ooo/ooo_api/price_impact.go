@@ -0,0 +1,47 @@
+package ooo_api
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+)
+
+// defaultDexImpactNotional - USD size of the trade whose price impact is estimated for each DEX
+// price, if jobs.dex_impact_notional is not set
+const defaultDexImpactNotional = 10000
+
+// dexPriceImpact returns the percent by which selling notional USD into a pool holding reserveUsd
+// of liquidity would move its price from the mid price. Pools are treated as constant product
+// pools, each side holding half the liquidity, which overstates the impact on concentrated
+// liquidity pools trading in range
+func dexPriceImpact(reserveUsd float64, notional float64) float64 {
+	if notional <= 0 {
+		return 0
+	}
+	if reserveUsd <= 0 {
+		return 100
+	}
+	// the output price of x*y=k, for dx in, is y/(x+dx) against the mid price y/x
+	side := reserveUsd / 2
+	return notional / (side + notional) * 100
+}
+
+// checkDexPriceImpact returns the price impact of a jobs.dex_impact_notional trade on a pool
+// holding reserveUsd of liquidity, and a validation error if it is over jobs.dex_max_price_impact,
+// since a shallow pool's mid price is not a price anyone could trade at. 0 disables the exclusion
+func checkDexPriceImpact(dexName string, reserveUsd float64) (float64, error) {
+	notional := float64(defaultDexImpactNotional)
+	if viper.IsSet(config.JobsDexImpactNotional) {
+		notional = viper.GetFloat64(config.JobsDexImpactNotional)
+	}
+
+	impact := dexPriceImpact(reserveUsd, notional)
+
+	maxImpact := viper.GetFloat64(config.JobsDexMaxPriceImpact)
+	if maxImpact > 0 && impact > maxImpact {
+		return impact, newValidationError(dexName, "price impact",
+			fmt.Sprintf("%.2f%% for a %g USD trade, over the %g%% maximum", impact, notional, maxImpact))
+	}
+
+	return impact, nil
+}
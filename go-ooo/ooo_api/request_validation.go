@@ -0,0 +1,154 @@
+package ooo_api
+
+import (
+	"fmt"
+	"go-ooo/errcodes"
+	"regexp"
+	"strings"
+)
+
+// Machine readable codes stored against requests rejected at ingestion
+const (
+	RejectInvalidFormat       = "INVALID_FORMAT"
+	RejectUnsupportedType     = "UNSUPPORTED_TYPE"
+	RejectUnsupportedSubtype  = "UNSUPPORTED_SUBTYPE"
+	RejectUnsupportedModifier = "UNSUPPORTED_MODIFIER"
+	RejectUnsupportedPair     = "UNSUPPORTED_PAIR"
+	RejectInvalidTimestamp    = "INVALID_TIMESTAMP"
+	RejectUnknownJsonFeed     = "UNKNOWN_JSON_FEED"
+	RejectPairNotServed       = "PAIR_NOT_SERVED"
+)
+
+// MaxEndpointLength - endpoints are sent to the router as bytes32
+const MaxEndpointLength = 32
+
+var (
+	// base and target tokens may be mixed case, e.g. stETH and cDAI. The other parts are upper case
+	endpointTokenRegex = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+	endpointPartRegex  = regexp.MustCompile(`^[A-Z0-9]+$`)
+)
+
+// RequestRejection is returned when a request endpoint cannot be answered
+type RequestRejection struct {
+	Code   string
+	Reason string
+}
+
+func (r RequestRejection) Error() string {
+	return fmt.Sprintf("%s: %s", r.Code, r.Reason)
+}
+
+// ErrorCode returns the errcodes class of the rejection - an unsupported pair, or an endpoint
+// which cannot be answered
+func (r RequestRejection) ErrorCode() errcodes.Code {
+	switch r.Code {
+	case RejectUnsupportedPair, RejectPairNotServed:
+		return errcodes.CodePairUnsupported
+	}
+	return errcodes.CodeInvalidEndpoint
+}
+
+func (r RequestRejection) Is(target error) bool {
+	return target == errcodes.Lookup(r.ErrorCode())
+}
+
+func newRequestRejection(code string, format string, a ...interface{}) *RequestRejection {
+	return &RequestRejection{
+		Code:   code,
+		Reason: fmt.Sprintf(format, a...),
+	}
+}
+
+// ValidateRequestEndpoint checks the format, type, pair and modifiers of a request endpoint
+// at ingestion. Returns nil if the request can be processed
+func (o *OOOApi) ValidateRequestEndpoint(endpoint string) *RequestRejection {
+	if len(endpoint) == 0 || len(endpoint) > MaxEndpointLength {
+		return newRequestRejection(RejectInvalidFormat, "endpoint must be 1 - %d bytes", MaxEndpointLength)
+	}
+
+	parts := strings.Split(endpoint, ".")
+	if len(parts) < 3 || len(parts) > 7 {
+		return newRequestRejection(RejectInvalidFormat, "expected BASE.TARGET.TYPE[.SUBTYPE[.SUPP1[.SUPP2[.SUPP3]]]]")
+	}
+
+	for i, p := range parts {
+		if i < 2 {
+			if !endpointTokenRegex.MatchString(p) {
+				return newRequestRejection(RejectInvalidFormat, "part %d must be alphanumeric", i+1)
+			}
+		} else if !endpointPartRegex.MatchString(p) {
+			return newRequestRejection(RejectInvalidFormat, "part %d must be upper case alphanumeric", i+1)
+		}
+	}
+
+	base, target, qType, subtype, supp1, supp2, _, _ := ParseEndpoint(endpoint)
+
+	switch qType {
+	case "PR":
+		if rejection := checkPairServed(base, target); rejection != nil {
+			return rejection
+		}
+		return o.validatePriceRequest(base, target, subtype, supp1, supp2)
+	case "AD":
+		if rejection := checkPairServed(base, target); rejection != nil {
+			return rejection
+		}
+		return validateAdhocRequest(subtype, supp1)
+	case JsonFeedType:
+		if _, ok := o.jsonFeeds[subtype]; !ok {
+			return newRequestRejection(RejectUnknownJsonFeed, "json feed %s not configured", subtype)
+		}
+		return nil
+	default:
+		return newRequestRejection(RejectUnsupportedType, "type %s not supported", qType)
+	}
+}
+
+func (o *OOOApi) validatePriceRequest(base, target, subtype, supp1, supp2 string) *RequestRejection {
+	if subtype == HistoricalSubType {
+		if _, err := parseHistoricalTimestamp(supp1); err != nil {
+			return newRequestRejection(RejectInvalidTimestamp, err.Error())
+		}
+		return nil
+	}
+
+	if _, err := getPriceSubType(subtype, supp1, supp2); err != nil {
+		return newRequestRejection(RejectUnsupportedSubtype, "sub type %s not supported for PR", subtype)
+	}
+
+	if supp1 != "" && cleanseTime(supp1) != supp1 {
+		return newRequestRejection(RejectUnsupportedModifier, "time period %s not supported", supp1)
+	}
+
+	if subtype == "AVC" && supp2 != "" {
+		if _, err := cleanseDMax(supp2); err != nil {
+			return newRequestRejection(RejectUnsupportedModifier, "dMax %s not supported", supp2)
+		}
+	}
+
+	// the supported pairs list is loaded asynchronously on start up, so only
+	// reject if it has been populated. Mock sources answer every pair
+	numPairs, err := o.db.CountSupportedPairs()
+	if o.mock == nil && err == nil && numPairs > 0 {
+		supported, _ := o.db.PairIsSupportedByBaseAndTarget(base, target)
+		if supported.ID == 0 {
+			return newRequestRejection(RejectUnsupportedPair, "pair %s.%s not supported", base, target)
+		}
+	}
+
+	return nil
+}
+
+func validateAdhocRequest(subtype, supp1 string) *RequestRejection {
+	switch subtype {
+	case "":
+		return nil
+	case HistoricalSubType:
+		if _, err := parseHistoricalTimestamp(supp1); err != nil {
+			return newRequestRejection(RejectInvalidTimestamp, err.Error())
+		}
+		return nil
+	default:
+		return newRequestRejection(RejectUnsupportedSubtype, "sub type %s not supported for AD", subtype)
+	}
+}
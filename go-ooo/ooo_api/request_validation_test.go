@@ -0,0 +1,57 @@
+package ooo_api
+
+import (
+	"go-ooo/database/mock"
+	"go-ooo/database/models"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestValidateRequestEndpointFormat(t *testing.T) {
+	o := &OOOApi{}
+	tests := map[string]string{
+		"stETH.ETH.AD":      "",
+		"wstETH.renBTC.AD":  "",
+		"cDAI.USD.AD":       "",
+		"BTC.USD.ad":        RejectInvalidFormat,
+		"stETH.ETH.AD.hist": RejectInvalidFormat,
+		"st-ETH.ETH.AD":     RejectInvalidFormat,
+		"BTC.USD":           RejectInvalidFormat,
+	}
+	for endpoint, want := range tests {
+		got := ""
+		if r := o.ValidateRequestEndpoint(endpoint); r != nil {
+			got = r.Code
+		}
+		if got != want {
+			t.Errorf("%s: got %q, want %q", endpoint, got, want)
+		}
+	}
+}
+
+func TestValidatePriceRequestSupportedPairs(t *testing.T) {
+	db := &mock.Store{
+		CountSupportedPairsFunc: func() (int64, error) {
+			return 1, nil
+		},
+		PairIsSupportedByBaseAndTargetFunc: func(base, target string) (models.SupportedPairs, error) {
+			if base == "BTC" && target == "USD" {
+				return models.SupportedPairs{Model: gorm.Model{ID: 1}}, nil
+			}
+			return models.SupportedPairs{}, nil
+		},
+	}
+	o := &OOOApi{db: db}
+
+	if r := o.ValidateRequestEndpoint("BTC.USD.PR.AVG"); r != nil {
+		t.Errorf("supported pair rejected: %s", r)
+	}
+	if r := o.ValidateRequestEndpoint("DOGE.USD.PR.AVG"); r == nil || r.Code != RejectUnsupportedPair {
+		t.Errorf("unsupported pair got %v, want %s", r, RejectUnsupportedPair)
+	}
+	// pairs are only looked up once the supported pairs list is loaded
+	db.CountSupportedPairsFunc = nil
+	if r := o.ValidateRequestEndpoint("DOGE.USD.PR.AVG"); r != nil {
+		t.Errorf("pair rejected before supported pairs loaded: %s", r)
+	}
+}
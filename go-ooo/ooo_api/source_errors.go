@@ -0,0 +1,273 @@
+package ooo_api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database/models"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// classes of data source error
+const (
+	// SourceErrorTransient - the source may answer if asked again, e.g. a DNS failure, timeout
+	// or 5xx response. Retried up to jobs.source_retries times
+	SourceErrorTransient = "transient"
+	// SourceErrorPermanent - the source cannot answer for the pair, e.g. a 404 because it does not
+	// list the pair, or a response not matching the expected schema. The source is excluded for
+	// the pair for jobs.source_exclude_duration
+	SourceErrorPermanent = "permanent"
+)
+
+const (
+	// defaultSourceRetries - retries of a transient source error, if jobs.source_retries is not set
+	defaultSourceRetries = 2
+	// defaultSourceExcludeDuration - seconds a permanent source error excludes the source for the
+	// pair, if jobs.source_exclude_duration is not set
+	defaultSourceExcludeDuration = 3600
+	// sourceRetryBackoff - delay before the first retry of a transient source error. Doubled on
+	// each retry
+	sourceRetryBackoff = 250 * time.Millisecond
+)
+
+var (
+	sourceErrorsClassified = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_source_errors_total",
+		Help: "Number of data source errors, by source and class - transient, permanent or unclassified",
+	}, []string{"source", "class"})
+
+	sourceRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_source_retries_total",
+		Help: "Number of retries of data source queries after transient errors, by source",
+	}, []string{"source"})
+)
+
+// httpStatusError is a data source's response with a status other than 200 OK
+type httpStatusError struct {
+	code   int
+	status string
+}
+
+func (e httpStatusError) Error() string {
+	return fmt.Sprintf("non-200 OK status code: %v", e.status)
+}
+
+func newHttpStatusError(resp *http.Response) error {
+	return httpStatusError{code: resp.StatusCode, status: resp.Status}
+}
+
+// schemaError is a data source response which does not have the expected shape
+type schemaError struct {
+	err error
+}
+
+func (e schemaError) Error() string {
+	return e.err.Error()
+}
+
+func (e schemaError) Unwrap() error {
+	return e.err
+}
+
+// SourceErrorClass returns whether a data source error is transient or permanent, or "" if it is
+// neither, e.g. a value out of range, in which case it is not retried and the source is not excluded
+func SourceErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var statusErr httpStatusError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.code == http.StatusTooManyRequests || statusErr.code >= 500:
+			return SourceErrorTransient
+		case statusErr.code == http.StatusBadRequest || statusErr.code == http.StatusNotFound ||
+			statusErr.code == http.StatusGone || statusErr.code == http.StatusUnprocessableEntity:
+			return SourceErrorPermanent
+		}
+		return ""
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var schemaErr schemaError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.As(err, &schemaErr) {
+		return SourceErrorPermanent
+	}
+
+	// DNS failures, refused connections and timeouts, including the http client's
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded) {
+		return SourceErrorTransient
+	}
+
+	return ""
+}
+
+// sourceExclusion is a source excluded for a pair after a permanent error
+type sourceExclusion struct {
+	pair   string
+	source string
+	reason string
+	until  time.Time
+}
+
+// SourceExclusion is a source not used for a pair until Until, because of a permanent error
+type SourceExclusion struct {
+	Pair   string
+	Source string
+	Reason string
+	Until  time.Time
+}
+
+func sourceExclusionKey(base string, target string, source string) string {
+	return strings.ToUpper(base+"."+target) + "|" + strings.ToLower(source)
+}
+
+// exclude stops source being used for base/target for duration
+func (p *pairSources) exclude(base string, target string, source string, reason string, duration time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.excluded[sourceExclusionKey(base, target, source)] = sourceExclusion{
+		pair:   strings.ToUpper(base + "." + target),
+		source: source,
+		reason: reason,
+		until:  time.Now().Add(duration),
+	}
+}
+
+// isExcluded returns true if source has been excluded for base/target after a permanent error.
+// Must be called with mu held
+func (p *pairSources) isExcluded(base string, target string, source string) bool {
+	e, ok := p.excluded[sourceExclusionKey(base, target, source)]
+	return ok && time.Now().Before(e.until)
+}
+
+// SourceExclusions returns the sources currently excluded for a pair after a permanent error,
+// by pair then source
+func (o *OOOApi) SourceExclusions() []SourceExclusion {
+	o.pairSources.mu.Lock()
+	defer o.pairSources.mu.Unlock()
+
+	res := make([]SourceExclusion, 0, len(o.pairSources.excluded))
+	for key, e := range o.pairSources.excluded {
+		if !time.Now().Before(e.until) {
+			delete(o.pairSources.excluded, key)
+			continue
+		}
+		res = append(res, SourceExclusion{Pair: e.pair, Source: e.source, Reason: e.reason, Until: e.until})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Pair != res[j].Pair {
+			return res[i].Pair < res[j].Pair
+		}
+		return res[i].Source < res[j].Source
+	})
+
+	return res
+}
+
+// ClearSourceExclusions lets excluded sources be used again, e.g. once the cause of their
+// permanent errors has been fixed. Empty pair or source match every pair or source. Returns the
+// number of exclusions cleared
+func (o *OOOApi) ClearSourceExclusions(pair string, source string) int {
+	o.pairSources.mu.Lock()
+	defer o.pairSources.mu.Unlock()
+
+	n := 0
+	for key, e := range o.pairSources.excluded {
+		if (pair == "" || strings.EqualFold(pair, e.pair)) && (source == "" || strings.EqualFold(source, e.source)) {
+			delete(o.pairSources.excluded, key)
+			n++
+		}
+	}
+	return n
+}
+
+// querySource runs fetch, source's query for base/target, retrying it with backoff if it fails
+// with a transient error, up to jobs.source_retries times. If it fails with a permanent error,
+// source is excluded for the pair for jobs.source_exclude_duration, so later requests do not ask
+// it again
+func (o *OOOApi) querySource(ctx context.Context, source string, base string, target string, fetch func() error) error {
+	retries := defaultSourceRetries
+	if viper.IsSet(config.JobsSourceRetries) {
+		retries = viper.GetInt(config.JobsSourceRetries)
+	}
+
+	backoff := sourceRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := fetch()
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		class := SourceErrorClass(err)
+		label := class
+		if label == "" {
+			label = "unclassified"
+		}
+		sourceErrorsClassified.WithLabelValues(source, label).Inc()
+
+		if class == SourceErrorPermanent {
+			o.excludeSource(source, base, target, err)
+			return err
+		}
+		if class != SourceErrorTransient || attempt >= retries {
+			return err
+		}
+
+		o.logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "querySource",
+			"source":   source,
+			"pair":     base + "." + target,
+			"attempt":  attempt + 1,
+		}).Debug("transient source error, retrying: ", err.Error())
+		sourceRetries.WithLabelValues(source).Inc()
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// excludeSource excludes source for base/target after a permanent error, unless exclusions are
+// disabled by setting jobs.source_exclude_duration to 0
+func (o *OOOApi) excludeSource(source string, base string, target string, err error) {
+	seconds := int64(defaultSourceExcludeDuration)
+	if viper.IsSet(config.JobsSourceExcludeDuration) {
+		seconds = viper.GetInt64(config.JobsSourceExcludeDuration)
+	}
+	if seconds <= 0 {
+		return
+	}
+
+	duration := time.Duration(seconds) * time.Second
+	o.pairSources.exclude(base, target, source, err.Error(), duration)
+
+	o.logger.WithFields(logrus.Fields{
+		"package":  "ooo_api",
+		"function": "excludeSource",
+		"source":   source,
+		"pair":     base + "." + target,
+		"duration": duration.String(),
+	}).Warn("permanent source error, excluding source for pair: ", err.Error())
+
+	o.recordPairChange(strings.ToUpper(base+"."+target), models.PAIR_CHANGE_SOURCE_EXCLUDED, source,
+		fmt.Sprintf("%s - excluded for %s", err.Error(), duration.String()))
+}
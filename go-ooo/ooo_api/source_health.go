@@ -0,0 +1,192 @@
+package ooo_api
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"go-ooo/database/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// kinds of upstream data source
+const (
+	SourceKindFinchains = "finchains"
+	SourceKindSubgraph  = "subgraph"
+	SourceKindHttp      = "http"
+)
+
+// SourceHealth is the last known state of an upstream data source
+type SourceHealth struct {
+	Name        string
+	Kind        string
+	Healthy     bool
+	LastError   string
+	LastChecked time.Time
+}
+
+// sourceHealthResults holds the outcome of the latest check or request for each source
+type sourceHealthResults struct {
+	mu      sync.RWMutex
+	results map[string]SourceHealth
+}
+
+func newSourceHealthResults() *sourceHealthResults {
+	return &sourceHealthResults{results: make(map[string]SourceHealth)}
+}
+
+// record sets the outcome for a source, returning true if its health changed - it became healthy
+// or unhealthy, or was unhealthy when first recorded
+func (r *sourceHealthResults) record(name string, kind string, err error) (changed bool) {
+	h := SourceHealth{
+		Name:        name,
+		Kind:        kind,
+		Healthy:     err == nil,
+		LastChecked: time.Now(),
+	}
+	if err != nil {
+		h.LastError = err.Error()
+	}
+
+	r.mu.Lock()
+	prev, ok := r.results[name]
+	r.results[name] = h
+	r.mu.Unlock()
+
+	return (ok && prev.Healthy != h.Healthy) || (!ok && !h.Healthy)
+}
+
+func (r *sourceHealthResults) all() []SourceHealth {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]SourceHealth, 0, len(r.results))
+	for _, h := range r.results {
+		res = append(res, h)
+	}
+	return res
+}
+
+// hostHealth - outcome of the latest request to each upstream host, recorded by instrumentedTransport
+var hostHealth = newSourceHealthResults()
+
+// SourceHealth returns the health of the Finchains endpoints, from the periodic health check, the
+// DEX subgraphs, from the last CheckSubgraphHealth, each TWAP pool, from its most recent read, and
+// each host queried for price data, from its most recent request
+func (o *OOOApi) SourceHealth() []SourceHealth {
+	var res []SourceHealth
+
+	o.finchains.mu.RLock()
+	for _, e := range o.finchains.endpoints {
+		res = append(res, SourceHealth{
+			Name:        e.baseURL,
+			Kind:        SourceKindFinchains,
+			Healthy:     e.healthy,
+			LastError:   e.lastError,
+			LastChecked: e.lastChecked,
+		})
+	}
+	o.finchains.mu.RUnlock()
+
+	subgraphs := o.subgraphHealth.all()
+	sort.Slice(subgraphs, func(i, j int) bool {
+		return subgraphs[i].Name < subgraphs[j].Name
+	})
+	res = append(res, subgraphs...)
+
+	pools := o.twapHealth.all()
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].Name < pools[j].Name
+	})
+	res = append(res, pools...)
+
+	hosts := hostHealth.all()
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].Name < hosts[j].Name
+	})
+
+	return append(res, hosts...)
+}
+
+// PairCoverage returns the sources which can answer requests for base/target once any pair source
+// overrides are applied, and the DEX pairs which would be used for ad-hoc requests, keyed by DEX
+func (o *OOOApi) PairCoverage(base string, target string, supported bool) ([]string, map[string]models.DexPairs) {
+	var sources []string
+	dexPairs := make(map[string]models.DexPairs)
+
+	if supported && sourceEnabled(FinchainsSourceName) && o.pairSources.allowed(base, target, FinchainsSourceName) {
+		sources = append(sources, FinchainsSourceName)
+	}
+
+	for _, k := range getKlineSources() {
+		if o.pairSources.allowed(base, target, k.name) {
+			sources = append(sources, k.name)
+		}
+	}
+
+	targets := []string{target}
+	if IsFiatCurrency(target) {
+		targets = UsdStablecoins
+	}
+
+	for _, api := range getQlApis() {
+		if !o.pairSources.allowed(base, target, api["name"]) {
+			continue
+		}
+		for _, t := range targets {
+			p, _ := o.findDexPair(base, t, api["name"])
+			if p.ID == 0 {
+				continue
+			}
+			if existing, ok := dexPairs[api["name"]]; !ok || p.ReserveUsd > existing.ReserveUsd {
+				dexPairs[api["name"]] = p
+			}
+		}
+		if _, ok := dexPairs[api["name"]]; ok {
+			sources = append(sources, api["name"])
+		}
+	}
+
+	if o.pairSources.allowed(base, target, TwapSourceName) {
+		for _, t := range targets {
+			if len(o.twap.poolsFor(base, t)) > 0 {
+				sources = append(sources, TwapSourceName)
+				break
+			}
+		}
+	}
+
+	return sources, dexPairs
+}
+
+// RefreshPairs resyncs the supported pairs from Finchains, then refreshes DEX tokens and
+// pairs in the background, since that can take several minutes
+func (o *OOOApi) RefreshPairs() error {
+	if o.mock != nil {
+		// no data sources are queried in mock source mode
+		return nil
+	}
+
+	numBefore, _ := o.db.CountSupportedPairs()
+
+	o.UpdateSupportedPairs()
+
+	numAfter, err := o.db.CountSupportedPairs()
+	if err != nil {
+		return err
+	}
+	if numAfter == 0 {
+		return fmt.Errorf("no supported pairs after refresh - check the finchains api")
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":      "ooo_api",
+		"function":     "RefreshPairs",
+		"pairs_before": numBefore,
+		"pairs_after":  numAfter,
+	}).Info("supported pairs refreshed")
+
+	o.supervisor.Go("dex_pair_sync", o.UpdateDexTokensAndPairs)
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package ooo_api
+
+import (
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/errcodes"
+	"strings"
+)
+
+// consumer source policies, for jobs.consumer_source_policies
+const (
+	// SourcePolicyAny answers the consumer's requests from every source the endpoint supports
+	SourcePolicyAny = "any"
+	// SourcePolicyCex answers the consumer's requests from centralised exchange data only - the
+	// Finchains API and exchange klines
+	SourcePolicyCex = "cex"
+	// SourcePolicyDex answers the consumer's requests from DEX data only - subgraphs and TWAPs
+	SourcePolicyDex = "dex"
+)
+
+// SourcePolicies are the valid consumer source policies
+var SourcePolicies = []string{SourcePolicyAny, SourcePolicyCex, SourcePolicyDex}
+
+// ConsumerSourcePolicy returns the source policy the operator configured for the consumer
+// contract in jobs.consumer_source_policies, or SourcePolicyAny if there is none
+func ConsumerSourcePolicy(consumer string) string {
+	policy := viper.GetStringMapString(config.JobsConsumerSourcePolicies)[strings.ToLower(consumer)]
+	if policy == "" {
+		return SourcePolicyAny
+	}
+	return strings.ToLower(policy)
+}
+
+// sourcePolicyError is returned for an endpoint which can only be answered from sources the
+// policy does not allow
+func sourcePolicyError(policy string, endpoint string) error {
+	return errcodes.Errorf(errcodes.ErrPairUnsupported, "source policy %s does not allow the sources for %s", policy, endpoint)
+}
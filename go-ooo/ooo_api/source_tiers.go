@@ -0,0 +1,129 @@
+package ooo_api
+
+import (
+	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/errcodes"
+	"strings"
+)
+
+// source tiers, for jobs.source_tiers
+const (
+	// SourceTierPrimary sources answer every request they can. Sources not put in a tier are primary
+	SourceTierPrimary = "primary"
+	// SourceTierSecondary sources answer requests the primary sources could not
+	SourceTierSecondary = "secondary"
+	// SourceTierEmergency sources answer requests neither the primary nor secondary sources could
+	SourceTierEmergency = "emergency"
+)
+
+// SourceTiers are the valid source tiers, in the order they are fallen back through
+var SourceTiers = []string{SourceTierPrimary, SourceTierSecondary, SourceTierEmergency}
+
+var answerSourceTiers = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ooo_answer_source_tier_total",
+	Help: "Number of prices aggregated with jobs.source_tiers set, by the tier answering them - primary, secondary, emergency, or failed if none could",
+}, []string{"tier"})
+
+type sourceTierKey struct{}
+
+// withSourceTier limits the sources queried with ctx to those in tier
+func withSourceTier(ctx context.Context, tier string) context.Context {
+	return context.WithValue(ctx, sourceTierKey{}, tier)
+}
+
+// SourceTierOf returns the tier the operator put source in with jobs.source_tiers
+func SourceTierOf(source string) string {
+	for tier, sources := range viper.GetStringMapStringSlice(config.JobsSourceTiers) {
+		for _, s := range sources {
+			if strings.EqualFold(s, source) {
+				return strings.ToLower(tier)
+			}
+		}
+	}
+	return SourceTierPrimary
+}
+
+// sourceInTier returns true if source may be queried with ctx - always, unless ctx is limited to
+// another tier
+func sourceInTier(ctx context.Context, source string) bool {
+	tier, ok := ctx.Value(sourceTierKey{}).(string)
+	return !ok || SourceTierOf(source) == tier
+}
+
+// sourceAllowed returns true if source can be used to answer requests for base/target, queried
+// with ctx
+func (o *OOOApi) sourceAllowed(ctx context.Context, base string, target string, source string) bool {
+	return sourceEnabled(source) && sourceInTier(ctx, source) && o.pairSources.allowed(base, target, source)
+}
+
+// configuredSourceTiers returns the tiers to fall back through, those with sources in
+// jobs.source_tiers after the primary tier, or nil if tiers are not configured
+func configuredSourceTiers() []string {
+	configured := viper.GetStringMapStringSlice(config.JobsSourceTiers)
+	if len(configured) == 0 {
+		return nil
+	}
+
+	tiers := []string{SourceTierPrimary}
+	for _, tier := range SourceTiers[1:] {
+		for t, sources := range configured {
+			if strings.EqualFold(t, tier) && len(sources) > 0 {
+				tiers = append(tiers, tier)
+				break
+			}
+		}
+	}
+	return tiers
+}
+
+// queryEndpointTiered is queryEndpoint, querying only the primary sources first if
+// jobs.source_tiers is set. If they cannot answer, the secondary sources are queried, then the
+// emergency sources. The tier answering is recorded in explain, with the rejections of the tiers
+// which could not
+func (o *OOOApi) queryEndpointTiered(ctx context.Context, endpoint string, requestId string, policy string, explain *PriceExplanation) (string, []string, error) {
+	tiers := configuredSourceTiers()
+	if tiers == nil {
+		return o.queryEndpoint(ctx, endpoint, requestId, policy, explain)
+	}
+
+	var failures []string
+	// the tiers' failure is classified as the primary tier's, e.g. an unsupported pair
+	kind := errcodes.ErrInsufficientSources
+	for _, tier := range tiers {
+		tierExplain := explain.sub()
+		price, sources, err := o.queryEndpoint(withSourceTier(ctx, tier), endpoint, requestId, policy, tierExplain)
+		explain.mergeTier(tierExplain, tier, err == nil)
+		if err == nil {
+			answerSourceTiers.WithLabelValues(tier).Inc()
+			if tier != SourceTierPrimary {
+				o.logger.WithFields(logrus.Fields{
+					"package":   "ooo_api",
+					"function":  "queryEndpointTiered",
+					"requestId": requestId,
+					"endpoint":  endpoint,
+					"tier":      tier,
+					"failures":  strings.Join(failures, "; "),
+				}).Warn("answered from degraded source tier")
+			}
+			return price, sources, nil
+		}
+		if ctx.Err() != nil {
+			return "", nil, ctx.Err()
+		}
+		if len(failures) == 0 {
+			if k := errcodes.Lookup(errcodes.CodeOf(err)); k != nil {
+				kind = k
+			}
+		}
+		failures = append(failures, fmt.Sprintf("%s: %s", tier, err.Error()))
+	}
+
+	answerSourceTiers.WithLabelValues("failed").Inc()
+	return "", nil, errcodes.Errorf(kind, "no source tier could answer - %s", strings.Join(failures, "; "))
+}
@@ -0,0 +1,204 @@
+package ooo_api
+
+import (
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"strings"
+	"sync"
+)
+
+// FinchainsSourceName - source name used for Finchains API queries in pair source overrides
+const FinchainsSourceName = "finchains"
+
+// PairSourceOverride restricts the sources used to answer requests for a pair, e.g.
+//
+//	[[pairs]]
+//	pair = "BTC.USD"
+//	include = ["binance", "bitstamp"]
+//
+//	[[pairs]]
+//	pair = "XFUND.ETH"
+//	exclude = ["shibaswap"]
+//
+// If include is set, only those sources are used. Sources in exclude are never used
+type PairSourceOverride struct {
+	Pair    string   `mapstructure:"pair"`
+	Include []string `mapstructure:"include"`
+	Exclude []string `mapstructure:"exclude"`
+}
+
+type pairSourcesFile struct {
+	Pairs []PairSourceOverride `mapstructure:"pairs"`
+}
+
+// pairSources holds the per-pair source overrides, which are reloaded
+// whenever the overrides file changes, and the sources excluded for a pair
+// after a permanent error
+type pairSources struct {
+	mu        sync.RWMutex
+	overrides map[string]PairSourceOverride
+	excluded  map[string]sourceExclusion
+	v         *viper.Viper
+	logger    *logrus.Logger
+}
+
+func newPairSources(file string, logger *logrus.Logger) (*pairSources, error) {
+	p := &pairSources{
+		overrides: make(map[string]PairSourceOverride),
+		excluded:  make(map[string]sourceExclusion),
+		logger:    logger,
+	}
+
+	if file == "" {
+		return p, nil
+	}
+
+	p.v = viper.New()
+	p.v.SetConfigFile(file)
+
+	err := p.load()
+	if err != nil {
+		return nil, err
+	}
+
+	p.v.OnConfigChange(func(e fsnotify.Event) {
+		if err := p.load(); err != nil {
+			// keep the previous overrides if the new file is invalid
+			p.logger.WithFields(logrus.Fields{
+				"package":  "ooo_api",
+				"function": "pairSources.OnConfigChange",
+				"file":     e.Name,
+			}).Error(err.Error())
+			return
+		}
+		p.logger.WithFields(logrus.Fields{
+			"package":  "ooo_api",
+			"function": "pairSources.OnConfigChange",
+			"file":     e.Name,
+		}).Info("pair source overrides reloaded")
+	})
+	p.v.WatchConfig()
+
+	return p, nil
+}
+
+func (p *pairSources) load() error {
+	err := p.v.ReadInConfig()
+	if err != nil {
+		return err
+	}
+
+	var f pairSourcesFile
+	err = p.v.Unmarshal(&f)
+	if err != nil {
+		return err
+	}
+
+	known := knownSourceNames()
+	overrides := make(map[string]PairSourceOverride)
+
+	for _, o := range f.Pairs {
+		if o.Pair == "" {
+			return fmt.Errorf("pair source override missing pair")
+		}
+		for _, s := range append(append([]string{}, o.Include...), o.Exclude...) {
+			if !known[strings.ToLower(s)] {
+				p.logger.WithFields(logrus.Fields{
+					"package":  "ooo_api",
+					"function": "pairSources.load",
+					"pair":     o.Pair,
+					"source":   s,
+				}).Warn("unknown source in pair source override")
+			}
+		}
+		overrides[strings.ToUpper(o.Pair)] = o
+	}
+
+	p.mu.Lock()
+	p.overrides = overrides
+	p.mu.Unlock()
+
+	return nil
+}
+
+// PairSourceOverrides returns the current per-pair source overrides
+func (o *OOOApi) PairSourceOverrides() map[string]PairSourceOverride {
+	o.pairSources.mu.RLock()
+	defer o.pairSources.mu.RUnlock()
+
+	res := make(map[string]PairSourceOverride, len(o.pairSources.overrides))
+	for pair, override := range o.pairSources.overrides {
+		res[pair] = override
+	}
+	return res
+}
+
+// allowed returns true if source can be used to answer requests for base/target
+func (p *pairSources) allowed(base string, target string, source string) bool {
+	p.mu.RLock()
+	o, ok := p.overrides[strings.ToUpper(fmt.Sprintf("%s.%s", base, target))]
+	excluded := p.isExcluded(base, target, source)
+	p.mu.RUnlock()
+
+	if excluded {
+		return false
+	}
+
+	if !ok {
+		return true
+	}
+
+	for _, s := range o.Exclude {
+		if strings.EqualFold(s, source) {
+			return false
+		}
+	}
+
+	if len(o.Include) == 0 {
+		return true
+	}
+
+	for _, s := range o.Include {
+		if strings.EqualFold(s, source) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sourceEnabled returns false if source belongs to a subsystem disabled by the features config -
+// the CEX sources, or the DEX sources
+func sourceEnabled(source string) bool {
+	if strings.EqualFold(source, FinchainsSourceName) {
+		return config.FeatureEnabled(config.FeaturesCex)
+	}
+	for _, k := range allKlineSources() {
+		if strings.EqualFold(source, k.name) {
+			return config.FeatureEnabled(config.FeaturesCex)
+		}
+	}
+	if strings.EqualFold(source, TwapSourceName) || isSubgraphDex(strings.ToLower(source)) {
+		return config.FeatureEnabled(config.FeaturesDex)
+	}
+	return true
+}
+
+func knownSourceNames() map[string]bool {
+	known := map[string]bool{FinchainsSourceName: true, TwapSourceName: true}
+	// sources disabled by the features config are still known
+	for _, a := range configuredQlApis() {
+		known[a["name"]] = true
+	}
+	for _, k := range allKlineSources() {
+		known[k.name] = true
+	}
+	feeds, _ := loadJsonFeeds()
+	for _, f := range feeds {
+		known[jsonFeedSourceName(f)] = true
+	}
+	return known
+}
@@ -0,0 +1,256 @@
+package ooo_api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/errcodes"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultSubgraphBatchWindow - milliseconds a pair price query waits for others to the same
+	// subgraph to batch with, if jobs.subgraph_batch_window is not set
+	defaultSubgraphBatchWindow = 25
+	// defaultSubgraphBatchSize - pairs queried in one batched document, if jobs.subgraph_batch_size
+	// is not set. Each pair selects 10 snapshots
+	defaultSubgraphBatchSize = 10
+)
+
+var subgraphBatchPairs = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "ooo_subgraph_batch_pairs",
+	Help:    "Number of pairs whose prices were queried in each subgraph price query, by DEX",
+	Buckets: []float64{1, 2, 5, 10, 20, 50},
+}, []string{"dex"})
+
+// pairPricesCall is a pair's price query, waiting to be sent in a batch
+type pairPricesCall struct {
+	pairAddress string
+	// query is the pair's own price query, which its response is cached under
+	query  []byte
+	done   chan struct{}
+	prices GraphQlAliasedPairPrices
+	err    error
+	// finished is set once done is closed, by the goroutine sending the batch
+	finished bool
+}
+
+// finish releases the requests waiting for the call's result
+func (c *pairPricesCall) finish() {
+	c.finished = true
+	close(c.done)
+}
+
+// pairPricesBatch is the pair price queries to a subgraph at a block, collected during the
+// batch window
+type pairPricesBatch struct {
+	key        string
+	api        map[string]string
+	block      uint64
+	historical bool
+	calls      []*pairPricesCall
+	timer      *time.Timer
+}
+
+// subgraphBatcher combines the price queries for different pairs made to the same subgraph at the
+// same block, e.g. by requests received in one block, into one GraphQL document of aliased
+// selections, rather than a round trip per pair
+type subgraphBatcher struct {
+	mu   sync.Mutex
+	open map[string]*pairPricesBatch
+}
+
+func newSubgraphBatcher() *subgraphBatcher {
+	return &subgraphBatcher{open: make(map[string]*pairPricesBatch)}
+}
+
+func subgraphBatchWindow() time.Duration {
+	window := int64(defaultSubgraphBatchWindow)
+	if viper.IsSet(config.JobsSubgraphBatchWindow) {
+		window = viper.GetInt64(config.JobsSubgraphBatchWindow)
+	}
+	return time.Duration(window) * time.Millisecond
+}
+
+func subgraphBatchSize() int {
+	if viper.IsSet(config.JobsSubgraphBatchSize) {
+		return viper.GetInt(config.JobsSubgraphBatchSize)
+	}
+	return defaultSubgraphBatchSize
+}
+
+// queryPairPricesBatched adds the pair's price query to the open batch for the subgraph and
+// block, sent once the batch window has passed or the batch is full, and waits for its result
+func (o *OOOApi) queryPairPricesBatched(ctx context.Context, pairAddress string, query []byte, api map[string]string, block uint64, historical bool) (GraphQlAliasedPairPrices, error) {
+	key := fmt.Sprintf("%s|%d|%t", api["url"], block, historical)
+	b := o.subgraphBatcher
+
+	b.mu.Lock()
+	batch, ok := b.open[key]
+	if !ok {
+		batch = &pairPricesBatch{key: key, api: api, block: block, historical: historical}
+		b.open[key] = batch
+		batch.timer = time.AfterFunc(subgraphBatchWindow(), func() {
+			if b.close(batch) {
+				o.supervisor.Do("subgraph_batch", func() { o.sendPairPricesBatch(batch) })
+			}
+		})
+	}
+
+	var call *pairPricesCall
+	for _, c := range batch.calls {
+		// concurrent requests for the same pair share its query
+		if c.pairAddress == pairAddress {
+			call = c
+			break
+		}
+	}
+	if call == nil {
+		call = &pairPricesCall{pairAddress: pairAddress, query: query, done: make(chan struct{})}
+		batch.calls = append(batch.calls, call)
+	}
+	// the batch is closed as it fills, under the same lock, so that no other query is added to it
+	full := len(batch.calls) >= subgraphBatchSize() && b.closeLocked(batch)
+	b.mu.Unlock()
+
+	if full {
+		batch.timer.Stop()
+		o.supervisor.Go("subgraph_batch", func() { o.sendPairPricesBatch(batch) })
+	}
+
+	select {
+	case <-call.done:
+		return call.prices, call.err
+	case <-ctx.Done():
+		return GraphQlAliasedPairPrices{}, ctx.Err()
+	}
+}
+
+// close stops any more queries being added to the batch, returning false if it already was
+func (b *subgraphBatcher) close(batch *pairPricesBatch) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closeLocked(batch)
+}
+
+// closeLocked is close, called with mu held
+func (b *subgraphBatcher) closeLocked(batch *pairPricesBatch) bool {
+	if b.open[batch.key] != batch {
+		return false
+	}
+	delete(b.open, batch.key)
+	return true
+}
+
+// sendPairPricesBatch queries the prices of the batch's pairs in one document, each pair's
+// snapshots aliased by its position in the batch, and caches each pair's part of the response as
+// the response to its own query. If the batched query fails with other than a transient error,
+// each pair is queried on its own, so that a pair the subgraph rejects does not fail the others
+func (o *OOOApi) sendPairPricesBatch(batch *pairPricesBatch) {
+	defer func() {
+		// no request is left waiting if the batch panics or returns before a call's result is set
+		for _, call := range batch.calls {
+			if !call.finished {
+				call.err = errcodes.Errorf(errcodes.ErrSourceUnavailable, "batched price query for pair %s did not complete", call.pairAddress)
+				call.finish()
+			}
+		}
+	}()
+
+	api := batch.api
+	subgraphBatchPairs.WithLabelValues(api["name"]).Observe(float64(len(batch.calls)))
+
+	if len(batch.calls) == 1 {
+		o.sendPairPricesAlone(batch, batch.calls[0])
+		return
+	}
+
+	blocksPerMin := apiBlocksPerMin(api)
+	selections := make([]string, 0, len(batch.calls))
+	for i, call := range batch.calls {
+		selections = append(selections, pairPricesSelections(call.pairAddress, api["pair_endpoint"], api["pairs_order_by"],
+			blocksPerMin, batch.block, batch.historical, batchAlias(i)))
+	}
+	query := map[string]string{
+		"query": fmt.Sprintf(`
+            {
+	            %s
+	        }
+        `, strings.Join(selections, ",\n                ")),
+	}
+
+	var decodedResponse struct {
+		Data map[string]GraphQlPairContent
+	}
+	err := o.runQuery(query, api, QueryClassPrice, &decodedResponse)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"package":   "ooo_api",
+			"function":  "sendPairPricesBatch",
+			"dex":       api["name"],
+			"block":     batch.block,
+			"num_pairs": len(batch.calls),
+		}).Warn("batched price query failed: ", err.Error())
+
+		for _, call := range batch.calls {
+			if SourceErrorClass(err) == SourceErrorTransient {
+				call.err = err
+				call.finish()
+				continue
+			}
+			o.sendPairPricesAlone(batch, call)
+		}
+		return
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"package":   "ooo_api",
+		"function":  "sendPairPricesBatch",
+		"dex":       api["name"],
+		"block":     batch.block,
+		"num_pairs": len(batch.calls),
+	}).Debug("batched price query")
+
+	for i, call := range batch.calls {
+		call.prices = aliasedPairPrices(decodedResponse.Data, batchAlias(i))
+		if body, err := json.Marshal(GraphQlPairPricesResponse{Data: call.prices}); err == nil {
+			o.subgraphCache.put(api["url"], batch.block, string(call.query), body)
+		}
+		call.finish()
+	}
+}
+
+// sendPairPricesAlone runs a batched pair's own price query
+func (o *OOOApi) sendPairPricesAlone(batch *pairPricesBatch, call *pairPricesCall) {
+	var decodedResponse GraphQlPairPricesResponse
+	call.err = o.runQueryAtBlock(o.ctx, json.RawMessage(call.query), batch.api, batch.block, &decodedResponse)
+	call.prices = decodedResponse.Data
+	call.finish()
+}
+
+func batchAlias(i int) string {
+	return fmt.Sprintf("b%d_", i)
+}
+
+// aliasedPairPrices returns the pair's snapshots from a batched response, aliased with prefix
+func aliasedPairPrices(data map[string]GraphQlPairContent, prefix string) GraphQlAliasedPairPrices {
+	return GraphQlAliasedPairPrices{
+		P0: data[prefix+"p0"],
+		P1: data[prefix+"p1"],
+		P2: data[prefix+"p2"],
+		P3: data[prefix+"p3"],
+		P4: data[prefix+"p4"],
+		P5: data[prefix+"p5"],
+		P6: data[prefix+"p6"],
+		P7: data[prefix+"p7"],
+		P8: data[prefix+"p8"],
+		P9: data[prefix+"p9"],
+	}
+}
@@ -0,0 +1,235 @@
+package ooo_api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	go_ooo_types "go-ooo/types"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// subgraph query classes, budgeted separately so that price queries are throttled last
+const (
+	// QueryClassPrice - queries for pair prices, answering requests
+	QueryClassPrice = "price"
+	// QueryClassSync - token and pair discovery, liquidity and health checks
+	QueryClassSync = "sync"
+)
+
+// defaultSubgraphSyncBudgetShare - percent of jobs.subgraph_monthly_budget sync queries may use,
+// if jobs.subgraph_sync_budget_share is not set
+const defaultSubgraphSyncBudgetShare = 80
+
+// errSubgraphBudget is returned for queries not made because the budget for their class is spent
+var errSubgraphBudget = errors.New("subgraph query budget spent")
+
+var (
+	subgraphQueryCost = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_subgraph_query_cost_total",
+		Help: "Cost of the queries made to each DEX's subgraph, in jobs.subgraph_query_cost units",
+	}, []string{"dex", "class"})
+
+	subgraphQueriesThrottled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ooo_subgraph_queries_throttled_total",
+		Help: "Number of subgraph queries not made because the monthly budget for their class was spent",
+	}, []string{"dex", "class"})
+
+	subgraphBudgetSpent = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ooo_subgraph_budget_spent",
+		Help: "Cost of the subgraph queries made this UTC month, by every instance sharing the database",
+	})
+)
+
+// subgraphSpend is the queries made to a subgraph, and their cost
+type subgraphSpend struct {
+	queries uint64
+	cost    float64
+}
+
+// subgraphBudget tracks the cost of subgraph queries each UTC month against the monthly budget.
+// Costs are saved to the database by FlushSubgraphSpend, so that the budget holds across restarts and is
+// shared by HA instances
+type subgraphBudget struct {
+	mu sync.Mutex
+	// month is the UTC yyyy-mm month spent is for
+	month string
+	// spent is the month's cost saved to the database at the last flush, plus that pending
+	spent float64
+	// pending is the spend since the last flush, by month then dex
+	pending map[string]map[string]subgraphSpend
+	// throttled is true for each class while its queries are throttled
+	throttled map[string]bool
+}
+
+func newSubgraphBudget() *subgraphBudget {
+	return &subgraphBudget{
+		pending:   make(map[string]map[string]subgraphSpend),
+		throttled: make(map[string]bool),
+	}
+}
+
+func budgetMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// subgraphQueryCostFor returns the cost of a query to the DEX's subgraph - its
+// jobs.subgraph_query_costs entry, or jobs.subgraph_query_cost
+func subgraphQueryCostFor(dexName string) float64 {
+	key := fmt.Sprintf("%s.%s", config.JobsSubgraphQueryCosts, strings.ToLower(dexName))
+	if viper.IsSet(key) {
+		return viper.GetFloat64(key)
+	}
+	return viper.GetFloat64(config.JobsSubgraphQueryCost)
+}
+
+// subgraphClassBudget returns the share of jobs.subgraph_monthly_budget queries of class may
+// spend. Sync queries stop at jobs.subgraph_sync_budget_share percent of it, leaving the rest for
+// price queries. 0 is unlimited
+func subgraphClassBudget(class string) float64 {
+	budget := viper.GetFloat64(config.JobsSubgraphMonthlyBudget)
+	if budget <= 0 || class == QueryClassPrice {
+		return budget
+	}
+
+	share := float64(defaultSubgraphSyncBudgetShare)
+	if viper.IsSet(config.JobsSubgraphSyncBudgetShare) {
+		share = viper.GetFloat64(config.JobsSubgraphSyncBudgetShare)
+	}
+	return budget * share / 100
+}
+
+// chargeSubgraphQuery counts a query of class to the DEX's subgraph against the budget, returning
+// errSubgraphBudget, without counting it, if the budget for the class is spent
+func (o *OOOApi) chargeSubgraphQuery(dexName string, class string) error {
+	cost := subgraphQueryCostFor(dexName)
+	budget := subgraphClassBudget(class)
+	month := budgetMonth(time.Now())
+
+	b := o.subgraphBudget
+	b.mu.Lock()
+
+	if b.month != month {
+		// a new month's budget
+		b.month = month
+		b.spent = 0
+		b.throttled = make(map[string]bool)
+	}
+
+	if budget > 0 && cost > 0 && b.spent+cost > budget {
+		wasThrottled := b.throttled[class]
+		b.throttled[class] = true
+		spent := b.spent
+		b.mu.Unlock()
+
+		subgraphQueriesThrottled.WithLabelValues(dexName, class).Inc()
+		if !wasThrottled {
+			o.logger.WithFields(logrus.Fields{
+				"package":  "ooo_api",
+				"function": "chargeSubgraphQuery",
+				"class":    class,
+				"spent":    spent,
+				"budget":   budget,
+				"month":    month,
+			}).Warn(fmt.Sprintf("subgraph budget for %s queries spent - throttled until the budget is raised or the month ends", class))
+		}
+		return errSubgraphBudget
+	}
+
+	b.spent += cost
+	if b.pending[month] == nil {
+		b.pending[month] = make(map[string]subgraphSpend)
+	}
+	p := b.pending[month][dexName]
+	p.queries++
+	p.cost += cost
+	b.pending[month][dexName] = p
+	b.throttled[class] = false
+	spent := b.spent
+	b.mu.Unlock()
+
+	subgraphQueryCost.WithLabelValues(dexName, class).Add(cost)
+	subgraphBudgetSpent.Set(spent)
+
+	return nil
+}
+
+// FlushSubgraphSpend saves the subgraph spend counted since the last flush, then reloads the
+// month's total, so that the budget also counts other instances' queries
+func (o *OOOApi) FlushSubgraphSpend() {
+	logger := o.logger.WithFields(logrus.Fields{
+		"package":  "ooo_api",
+		"function": "FlushSubgraphSpend",
+	})
+
+	b := o.subgraphBudget
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = make(map[string]map[string]subgraphSpend)
+	b.mu.Unlock()
+
+	for month, dexes := range pending {
+		for dexName, s := range dexes {
+			if err := o.db.AddSubgraphSpend(dexName, month, s.queries, s.cost); err != nil {
+				logger.WithField("dex", dexName).Error("cannot save subgraph spend: ", err.Error())
+			}
+		}
+	}
+
+	month := budgetMonth(time.Now())
+	rows, err := o.db.GetSubgraphSpend(month)
+	if err != nil {
+		logger.Error("cannot load subgraph spend: ", err.Error())
+		return
+	}
+
+	spent := float64(0)
+	for _, r := range rows {
+		spent += r.GetCost()
+	}
+
+	b.mu.Lock()
+	if b.month != month {
+		b.month = month
+		b.throttled = make(map[string]bool)
+	}
+	// spend counted since the pending spend was taken is not yet saved
+	for _, s := range b.pending[month] {
+		spent += s.cost
+	}
+	b.spent = spent
+	b.mu.Unlock()
+
+	subgraphBudgetSpent.Set(spent)
+}
+
+// SubgraphBudgetStatus returns this month's subgraph spend against the budget
+func (o *OOOApi) SubgraphBudgetStatus() go_ooo_types.SubgraphBudget {
+	b := o.subgraphBudget
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	res := go_ooo_types.SubgraphBudget{
+		Month:     b.month,
+		Budget:    viper.GetFloat64(config.JobsSubgraphMonthlyBudget),
+		Spent:     b.spent,
+		Throttled: []string{},
+	}
+	if res.Month == "" {
+		res.Month = budgetMonth(time.Now())
+	}
+	for class, throttled := range b.throttled {
+		if throttled {
+			res.Throttled = append(res.Throttled, class)
+		}
+	}
+	sort.Strings(res.Throttled)
+
+	return res
+}
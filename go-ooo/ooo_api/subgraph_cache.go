@@ -0,0 +1,110 @@
+package ooo_api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go-ooo/cache"
+	"sync"
+	"time"
+)
+
+const (
+	// subgraphCacheMaxEntries - responses cached per subgraph for its current block
+	subgraphCacheMaxEntries = 1024
+	// subgraphSharedCacheTtl - time a response is kept in the shared cache, long enough for the
+	// other nodes to answer requests at the same block with it
+	subgraphSharedCacheTtl = 2 * time.Minute
+)
+
+var subgraphCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ooo_subgraph_cache_requests_total",
+	Help: "Number of block pinned subgraph queries answered from the cache (hit), the shared cache (shared_hit) or the subgraph (miss)",
+}, []string{"result"})
+
+// subgraphCache caches subgraph responses for the chain block they were queried at, so that
+// requests answered within the same block share a single query per pair. A subgraph's cached
+// responses are dropped as soon as it is queried at a later block. If cache.redis_url is set,
+// responses are also shared with the other nodes, which are asked for those not cached locally
+type subgraphCache struct {
+	mu       sync.Mutex
+	heads    map[string]uint64
+	response map[string]map[string][]byte
+	shared   *cache.Client
+}
+
+func newSubgraphCache() *subgraphCache {
+	return &subgraphCache{
+		heads:    make(map[string]uint64),
+		response: make(map[string]map[string][]byte),
+		shared:   cache.Shared(),
+	}
+}
+
+// sharedKey returns the shared cache key for a query. Responses are pinned to the block they were
+// queried at, so are the same whichever node made the query
+func sharedKey(url string, block uint64, query string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%s", url, block, query)))
+	return "subgraph:" + hex.EncodeToString(sum[:])
+}
+
+// get returns the cached response to query made to the subgraph at url at block
+func (c *subgraphCache) get(url string, block uint64, query string) ([]byte, bool) {
+	c.mu.Lock()
+	if block > c.heads[url] {
+		// the head has advanced - earlier responses may be stale
+		c.heads[url] = block
+		delete(c.response, url)
+	}
+
+	body, ok := c.response[url][query]
+	ok = ok && block == c.heads[url]
+	c.mu.Unlock()
+
+	if ok {
+		subgraphCacheRequests.WithLabelValues("hit").Inc()
+		return body, true
+	}
+
+	if c.shared.Enabled() {
+		// a shared cache error is treated as a miss - the subgraph is queried instead
+		if body, ok, _ = c.shared.Get(context.Background(), sharedKey(url, block, query)); ok {
+			subgraphCacheRequests.WithLabelValues("shared_hit").Inc()
+			c.putLocal(url, block, query, body)
+			return body, true
+		}
+	}
+
+	subgraphCacheRequests.WithLabelValues("miss").Inc()
+	return nil, false
+}
+
+// put caches the response to query made to the subgraph at url at block. Responses for blocks
+// before the subgraph's latest queried block are not cached locally
+func (c *subgraphCache) put(url string, block uint64, query string, body []byte) {
+	c.putLocal(url, block, query, body)
+	if c.shared.Enabled() {
+		_ = c.shared.Set(context.Background(), sharedKey(url, block, query), body, subgraphSharedCacheTtl)
+	}
+}
+
+func (c *subgraphCache) putLocal(url string, block uint64, query string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if block != c.heads[url] {
+		return
+	}
+
+	if _, ok := c.response[url]; !ok {
+		c.response[url] = make(map[string][]byte)
+	}
+	if len(c.response[url]) >= subgraphCacheMaxEntries {
+		return
+	}
+
+	c.response[url][query] = body
+}
@@ -0,0 +1,69 @@
+package ooo_api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// subgraphMaxLagMins - a subgraph is unhealthy if its indexed block is more than this many
+// minutes behind its chain
+const subgraphMaxLagMins = 30
+
+// CheckSubgraphHealth queries each DEX subgraph's indexing status, and returns an error for each
+// subgraph which cannot be queried, has indexing errors or has fallen behind its chain. The
+// results are kept for SourceHealth
+func (o *OOOApi) CheckSubgraphHealth() map[string]error {
+	if o.mock != nil {
+		// no data sources are queried in mock source mode
+		return map[string]error{}
+	}
+
+	res := make(map[string]error)
+
+	for _, api := range getQlApis() {
+		err := o.checkSubgraph(api)
+		if errors.Is(err, errSubgraphBudget) {
+			// not checked, rather than unhealthy
+			continue
+		}
+		res[api["name"]] = err
+		if o.subgraphHealth.record(api["name"], SourceKindSubgraph, err) {
+			o.recordSourceAvailability("", api["name"], err)
+		}
+	}
+
+	return res
+}
+
+func (o *OOOApi) checkSubgraph(api map[string]string) error {
+	query := map[string]string{
+		"query": "{ _meta { block { number } hasIndexingErrors } }",
+	}
+
+	var decodedResponse GraphQlMetaResponse
+	err := o.runQuery(query, api, QueryClassSync, &decodedResponse)
+	if err != nil {
+		return err
+	}
+
+	meta := decodedResponse.Data.Meta
+	if meta.HasIndexingErrors {
+		return fmt.Errorf("subgraph has indexing errors")
+	}
+
+	currentBlock, err := o.getCurrentBlockNumForChain(o.ctx, api["chain"])
+	if err != nil || currentBlock == 0 {
+		// can't compare without a chain client
+		return nil
+	}
+
+	blocksInOneMin, _ := strconv.ParseUint(api["blocks_in_one_min"], 10, 64)
+	maxLag := blocksInOneMin * subgraphMaxLagMins
+	if maxLag > 0 && currentBlock > meta.Block.Number && currentBlock-meta.Block.Number > maxLag {
+		return fmt.Errorf("subgraph at block %d is %d blocks behind %s", meta.Block.Number,
+			currentBlock-meta.Block.Number, api["chain"])
+	}
+
+	return nil
+}
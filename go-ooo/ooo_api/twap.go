@@ -0,0 +1,304 @@
+package ooo_api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TwapSourceName - source name used for Uniswap V3 TWAP reads in pair source overrides
+const TwapSourceName = "uniswapv3_twap"
+
+// SourceKindOnChain - kind of source read directly from a chain's contracts
+const SourceKindOnChain = "onchain"
+
+// DefaultTwapWindow - seconds the TWAP is averaged over, if a pool does not set window
+const DefaultTwapWindow = 1800
+
+// the parts of the Uniswap V3 pool and ERC20 ABIs the TWAP source needs
+const (
+	uniswapV3PoolAbi = `[{"inputs":[{"internalType":"uint32[]","name":"secondsAgos","type":"uint32[]"}],"name":"observe","outputs":[{"internalType":"int56[]","name":"tickCumulatives","type":"int56[]"},{"internalType":"uint160[]","name":"secondsPerLiquidityCumulativeX128s","type":"uint160[]"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"token0","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"token1","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"}]`
+	erc20MetadataAbi = `[{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"type":"function"},{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"type":"function"}]`
+)
+
+// wrappedNativeSymbols - wrapped native tokens, which pools hold in place of the native token
+// requests are for, e.g. WETH for ETH
+var wrappedNativeSymbols = map[string]string{
+	"WETH":   "ETH",
+	"WMATIC": "MATIC",
+	"WBNB":   "BNB",
+	"WXDAI":  "XDAI",
+}
+
+// TwapPool is an operator configured Uniswap V3 pool, whose built in price oracle is read with
+// observe() to answer ad-hoc requests for its pair, alongside the DEX subgraphs, e.g.
+//
+//	[[jobs.twap_pools]]
+//	pair = "XFUND.ETH"
+//	chain = "eth"
+//	pool = "0x..."
+//	window = 1800
+//
+// The price is the time weighted average over the last window seconds, which is resistant to
+// manipulation within a block and does not depend on subgraph indexing. A pool answers requests
+// for its pair and the inverse pair. The pool's cardinality must hold observations for the window
+type TwapPool struct {
+	Pair   string `mapstructure:"pair" json:"pair"`
+	Chain  string `mapstructure:"chain" json:"chain"`
+	Pool   string `mapstructure:"pool" json:"pool"`
+	Window int64  `mapstructure:"window" json:"window"`
+}
+
+// twapTokens - a pool's tokens, read once since they never change
+type twapTokens struct {
+	symbol0   string
+	symbol1   string
+	decimals0 uint8
+	decimals1 uint8
+}
+
+// twapSource holds the configured pools, keyed by pair and inverse pair, and their tokens
+type twapSource struct {
+	pools map[string][]TwapPool
+
+	mu     sync.Mutex
+	tokens map[string]twapTokens
+}
+
+// LoadTwapPools reads and validates the configured Uniswap V3 TWAP pools
+func LoadTwapPools() ([]TwapPool, error) {
+	var pools []TwapPool
+	var err error
+	if raw, ok := viper.Get(config.JobsTwapPools).(string); ok {
+		// set by an environment variable, as a JSON array of pools
+		err = json.Unmarshal([]byte(raw), &pools)
+	} else {
+		err = viper.UnmarshalKey(config.JobsTwapPools, &pools)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	chains := make(map[string]bool)
+	for _, c := range getChains() {
+		chains[c] = true
+	}
+
+	for i, p := range pools {
+		if _, _, ok := SplitPair(p.Pair); !ok {
+			return nil, fmt.Errorf("twap pool %d: pair %q must be of the form BASE.TARGET", i+1, p.Pair)
+		}
+		if !chains[strings.ToLower(p.Chain)] {
+			return nil, fmt.Errorf("twap pool %s: chain %q must be one of %s", p.Pair, p.Chain, strings.Join(getChains(), ", "))
+		}
+		if !common.IsHexAddress(p.Pool) {
+			return nil, fmt.Errorf("twap pool %s: pool %q must be an address", p.Pair, p.Pool)
+		}
+		if p.Window < 0 || p.Window > math.MaxUint32 {
+			return nil, fmt.Errorf("twap pool %s: window %d must be 0 - %d seconds", p.Pair, p.Window, uint32(math.MaxUint32))
+		}
+		if p.Window == 0 {
+			p.Window = DefaultTwapWindow
+		}
+		p.Pair = strings.ToUpper(p.Pair)
+		p.Chain = strings.ToLower(p.Chain)
+		pools[i] = p
+	}
+
+	return pools, nil
+}
+
+func newTwapSource(pools []TwapPool) *twapSource {
+	t := &twapSource{
+		pools:  make(map[string][]TwapPool),
+		tokens: make(map[string]twapTokens),
+	}
+	for _, p := range pools {
+		base, target, _ := SplitPair(p.Pair)
+		t.pools[base+"."+target] = append(t.pools[base+"."+target], p)
+		t.pools[target+"."+base] = append(t.pools[target+"."+base], p)
+	}
+	return t
+}
+
+// poolsFor returns the pools which can answer requests for base/target
+func (t *twapSource) poolsFor(base string, target string) []TwapPool {
+	return t.pools[strings.ToUpper(base+"."+target)]
+}
+
+// getTwapPrices returns the TWAP of each pool configured for base/target. If historical is true,
+// the TWAP is read as of the block in blocks for the pool's chain, which needs an archive node
+func (o *OOOApi) getTwapPrices(ctx context.Context, base string, target string, blocks map[string]uint64, historical bool) ([]dexPrice, []error) {
+	var prices []dexPrice
+	var rejections []error
+
+	for _, p := range o.twap.poolsFor(base, target) {
+		var block *big.Int
+		if historical {
+			if blocks[p.Chain] == 0 {
+				// no block found for the timestamp on this chain
+				continue
+			}
+			block = new(big.Int).SetUint64(blocks[p.Chain])
+		}
+
+		price, err := o.readTwap(ctx, p, base, target, block)
+		if ctx.Err() != nil {
+			rejections = append(rejections, newValidationError(TwapSourceName, "observe", ctx.Err().Error()))
+			break
+		}
+		if o.twapHealth.record(fmt.Sprintf("%s:%s", p.Chain, p.Pool), SourceKindOnChain, err) {
+			o.recordSourceAvailability(p.Pair, fmt.Sprintf("%s:%s:%s", TwapSourceName, p.Chain, p.Pool), err)
+		}
+		if err != nil {
+			o.logger.WithFields(logrus.Fields{
+				"package":  "ooo_api",
+				"function": "getTwapPrices",
+				"pair":     p.Pair,
+				"chain":    p.Chain,
+				"pool":     p.Pool,
+			}).Warn(err.Error())
+			rejections = append(rejections, newValidationError(TwapSourceName, "observe", err.Error()))
+			continue
+		}
+
+		if math.IsInf(price, 0) || math.IsNaN(price) || price == 0 {
+			rejections = append(rejections, newValidationError(TwapSourceName, "price", "out of float64 range"))
+			continue
+		}
+
+		res := dexPrice{value: price}
+		if o.exactMath {
+			res.exact = ratFromFloat(price)
+		}
+		prices = append(prices, res)
+	}
+
+	return prices, rejections
+}
+
+// readTwap returns the pool's time weighted average price of base in target over its window,
+// as of block, or the latest block if block is nil
+func (o *OOOApi) readTwap(ctx context.Context, p TwapPool, base string, target string, block *big.Int) (float64, error) {
+	client := o.getSubchainClient(p.Chain)
+	if client == nil {
+		return 0, fmt.Errorf("no rpc configured for chain %s", p.Chain)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: block}
+
+	poolAbi, err := abi.JSON(strings.NewReader(uniswapV3PoolAbi))
+	if err != nil {
+		return 0, err
+	}
+	pool := bind.NewBoundContract(common.HexToAddress(p.Pool), poolAbi, client, client, client)
+
+	tokens, err := o.twapPoolTokens(opts, p, pool)
+	if err != nil {
+		return 0, err
+	}
+
+	baseIs0 := twapSymbolMatches(tokens.symbol0, base) && twapSymbolMatches(tokens.symbol1, target)
+	if !baseIs0 && !(twapSymbolMatches(tokens.symbol1, base) && twapSymbolMatches(tokens.symbol0, target)) {
+		return 0, fmt.Errorf("pool tokens %s/%s do not match %s/%s", tokens.symbol0, tokens.symbol1, base, target)
+	}
+
+	var out []interface{}
+	err = pool.Call(opts, &out, "observe", []uint32{uint32(p.Window), 0})
+	if err != nil {
+		// "OLD" - the pool's observations do not go back as far as the window
+		return 0, fmt.Errorf("observe failed: %s", err.Error())
+	}
+
+	tickCumulatives := *abi.ConvertType(out[0], new([]*big.Int)).(*[]*big.Int)
+	if len(tickCumulatives) != 2 {
+		return 0, fmt.Errorf("observe returned %d tick cumulatives", len(tickCumulatives))
+	}
+
+	// the mean tick, rounded down as in Uniswap's OracleLibrary
+	delta := new(big.Int).Sub(tickCumulatives[1], tickCumulatives[0])
+	tick := new(big.Int).Div(delta, big.NewInt(p.Window)).Int64()
+
+	// price of token0 in token1, adjusted for the tokens' decimals
+	price := math.Pow(1.0001, float64(tick)) * baseUnitsRatio(tokens.decimals0, tokens.decimals1)
+	if !baseIs0 {
+		price = 1 / price
+	}
+
+	return price, nil
+}
+
+// twapPoolTokens returns the symbols and decimals of the pool's tokens
+func (o *OOOApi) twapPoolTokens(opts *bind.CallOpts, p TwapPool, pool *bind.BoundContract) (twapTokens, error) {
+	key := p.Chain + ":" + strings.ToLower(p.Pool)
+
+	o.twap.mu.Lock()
+	tokens, ok := o.twap.tokens[key]
+	o.twap.mu.Unlock()
+	if ok {
+		return tokens, nil
+	}
+
+	client := o.getSubchainClient(p.Chain)
+	erc20Abi, err := abi.JSON(strings.NewReader(erc20MetadataAbi))
+	if err != nil {
+		return tokens, err
+	}
+
+	// token metadata is read at the latest block, since the pool may not exist at an old one
+	latest := &bind.CallOpts{Context: opts.Context}
+
+	var symbols [2]string
+	var decimals [2]uint8
+	for i, method := range []string{"token0", "token1"} {
+		var out []interface{}
+		if err = pool.Call(latest, &out, method); err != nil {
+			return tokens, fmt.Errorf("cannot read pool %s: %s", method, err.Error())
+		}
+		address := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+		token := bind.NewBoundContract(address, erc20Abi, client, client, client)
+		out = nil
+		if err = token.Call(latest, &out, "symbol"); err != nil {
+			return tokens, fmt.Errorf("cannot read %s symbol: %s", address.Hex(), err.Error())
+		}
+		symbols[i] = strings.ToUpper(*abi.ConvertType(out[0], new(string)).(*string))
+
+		out = nil
+		if err = token.Call(latest, &out, "decimals"); err != nil {
+			return tokens, fmt.Errorf("cannot read %s decimals: %s", address.Hex(), err.Error())
+		}
+		decimals[i] = *abi.ConvertType(out[0], new(uint8)).(*uint8)
+	}
+
+	tokens = twapTokens{
+		symbol0:   symbols[0],
+		symbol1:   symbols[1],
+		decimals0: decimals[0],
+		decimals1: decimals[1],
+	}
+
+	o.twap.mu.Lock()
+	o.twap.tokens[key] = tokens
+	o.twap.mu.Unlock()
+
+	return tokens, nil
+}
+
+// twapSymbolMatches returns true if a pool token's symbol is symbol, or wraps it
+func twapSymbolMatches(tokenSymbol string, symbol string) bool {
+	return tokenSymbol == symbol || wrappedNativeSymbols[tokenSymbol] == symbol
+}
@@ -59,6 +59,11 @@ type GraphQlAliasedPairPrices struct {
 	P9 GraphQlPairContent `json:"p9,omitempty"`
 }
 
+// All returns the aliased pair snapshots, most recent first
+func (g GraphQlAliasedPairPrices) All() []GraphQlPairContent {
+	return []GraphQlPairContent{g.P0, g.P1, g.P2, g.P3, g.P4, g.P5, g.P6, g.P7, g.P8, g.P9}
+}
+
 type GraphQlPairPricesResponse struct {
 	Data GraphQlAliasedPairPrices
 }
@@ -79,3 +84,22 @@ type GraphQlPair struct {
 type GraphQlPairResponse struct {
 	Data GraphQlPair
 }
+
+type GraphQlError struct {
+	Message string `json:"message"`
+}
+
+type GraphQlErrorResponse struct {
+	Errors []GraphQlError `json:"errors,omitempty"`
+}
+
+type GraphQlMetaResponse struct {
+	Data struct {
+		Meta struct {
+			Block struct {
+				Number uint64 `json:"number"`
+			} `json:"block"`
+			HasIndexingErrors bool `json:"hasIndexingErrors"`
+		} `json:"_meta"`
+	} `json:"data"`
+}
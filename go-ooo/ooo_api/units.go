@@ -0,0 +1,179 @@
+package ooo_api
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QuoteUsd - the currency USD stablecoin and fiat quotes are converted through
+const QuoteUsd = "USD"
+
+// stablecoin USD rate sources, for jobs.stablecoin_rates
+const (
+	// StablecoinRatesPar values every USD stablecoin at exactly 1 USD
+	StablecoinRatesPar = "par"
+	// StablecoinRatesMarket values USD stablecoins at their Finchains USD price
+	StablecoinRatesMarket = "market"
+)
+
+// defaultStablecoinMaxDepeg - percent a stablecoin may trade away from 1 USD before its quotes
+// are rejected, if jobs.stablecoin_max_depeg is not set
+const defaultStablecoinMaxDepeg = 2
+
+// stablecoinRates caches the USD rate of each stablecoin, for ForexCacheDuration
+type stablecoinRates struct {
+	mu        sync.Mutex
+	rates     map[string]float64
+	fetchedAt map[string]time.Time
+}
+
+func newStablecoinRates() *stablecoinRates {
+	return &stablecoinRates{
+		rates:     make(map[string]float64),
+		fetchedAt: make(map[string]time.Time),
+	}
+}
+
+// IsUsdStablecoin returns true if the symbol is one of the UsdStablecoins
+func IsUsdStablecoin(symbol string) bool {
+	for _, s := range UsdStablecoins {
+		if strings.EqualFold(s, symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+// stablecoinMaxDepeg returns the max percent a stablecoin may trade away from 1 USD. 0 disables the check
+func stablecoinMaxDepeg() float64 {
+	if !viper.IsSet(config.JobsStablecoinMaxDepeg) {
+		return defaultStablecoinMaxDepeg
+	}
+	return viper.GetFloat64(config.JobsStablecoinMaxDepeg)
+}
+
+// StablecoinUsdRate returns the value of one unit of the stablecoin in USD - 1 when
+// jobs.stablecoin_rates is par, otherwise its Finchains USD price. Historical requests, and
+// current ones if the price cannot be fetched and none is cached, use par. It returns an error
+// if the rate is further than jobs.stablecoin_max_depeg percent from 1
+func (o *OOOApi) StablecoinUsdRate(symbol string, historical bool) (float64, error) {
+	symbol = strings.ToUpper(symbol)
+	rate := float64(1)
+	if !historical && !strings.EqualFold(viper.GetString(config.JobsStablecoinRates), StablecoinRatesPar) {
+		rate = o.marketStablecoinRate(symbol)
+	}
+
+	if maxDepeg := stablecoinMaxDepeg(); maxDepeg > 0 {
+		if depeg := math.Abs(rate-1) * 100; depeg > maxDepeg {
+			return 0, newValidationError(symbol, "usd rate", fmt.Sprintf("%g deviates %.2f%% from 1, max %g%%", rate, depeg, maxDepeg))
+		}
+	}
+
+	return rate, nil
+}
+
+// marketStablecoinRate returns the stablecoin's Finchains USD price, refreshed every
+// ForexCacheDuration. The last known price is used if a refresh fails, or 1 if there is none
+func (o *OOOApi) marketStablecoinRate(symbol string) float64 {
+	o.stablecoins.mu.Lock()
+	defer o.stablecoins.mu.Unlock()
+
+	rate, cached := o.stablecoins.rates[symbol]
+	if cached && time.Since(o.stablecoins.fetchedAt[symbol]) <= ForexCacheDuration {
+		return rate
+	}
+
+	endpoint := fmt.Sprintf("%s.%s.PR.AVC", symbol, QuoteUsd)
+	answer, err := o.QueryFinchainsEndpoint(o.ctx, endpoint, "")
+	if err == nil {
+		rate = o.answerToFloat(answer)
+		if rate <= 0 {
+			err = fmt.Errorf("%s price is %s", endpoint, answer)
+		}
+	}
+
+	if err != nil {
+		if !cached {
+			rate = 1
+		}
+		o.logger.WithFields(logrus.Fields{
+			"package":    "ooo_api",
+			"function":   "marketStablecoinRate",
+			"stablecoin": symbol,
+			"rate":       rate,
+		}).Warn(fmt.Sprintf("cannot fetch stablecoin usd rate: %s", err.Error()))
+	} else {
+		o.logger.WithFields(logrus.Fields{
+			"package":    "ooo_api",
+			"function":   "marketStablecoinRate",
+			"stablecoin": symbol,
+			"rate":       rate,
+		}).Debug("stablecoin usd rate updated")
+	}
+
+	// a failed fetch is retried once the cache expires, rather than for every price
+	o.stablecoins.rates[symbol] = rate
+	o.stablecoins.fetchedAt[symbol] = time.Now()
+
+	return rate
+}
+
+// usdRate returns the value of one unit of currency in USD. Quotes in other tokens, e.g. ETH,
+// cannot be converted, and return an error
+func (o *OOOApi) usdRate(currency string, historical bool, timestamp int64) (float64, error) {
+	switch {
+	case strings.EqualFold(currency, QuoteUsd):
+		return 1, nil
+	case IsUsdStablecoin(currency):
+		return o.StablecoinUsdRate(currency, historical)
+	case IsFiatCurrency(currency):
+		if historical {
+			return o.GetHistoricalForexRate(currency, QuoteUsd, timestamp)
+		}
+		return o.GetForexRate(currency, QuoteUsd)
+	}
+	return 0, fmt.Errorf("cannot convert %s quotes to %s", strings.ToUpper(currency), QuoteUsd)
+}
+
+// quoteConversionRate returns the rate converting a price quoted in from into one quoted in to.
+// Fiat currencies and USD stablecoins are converted through USD, with FX rates and
+// StablecoinUsdRate, so that prices quoted in different stablecoins can be aggregated without
+// basis error. Any other pair of currencies can only be converted if they are the same
+func (o *OOOApi) quoteConversionRate(from string, to string, historical bool, timestamp int64) (float64, error) {
+	if strings.EqualFold(from, to) {
+		return 1, nil
+	}
+
+	fromUsd, err := o.usdRate(from, historical, timestamp)
+	if err != nil {
+		return 0, err
+	}
+	toUsd, err := o.usdRate(to, historical, timestamp)
+	if err != nil {
+		return 0, err
+	}
+
+	return fromUsd / toUsd, nil
+}
+
+// fromBaseUnits converts an amount in a token's base units, e.g. wei, to whole tokens
+func fromBaseUnits(amount *big.Float, decimals uint) *big.Float {
+	if decimals == 0 {
+		return amount
+	}
+	unit := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	return new(big.Float).SetPrec(amount.Prec()).Quo(amount, unit)
+}
+
+// baseUnitsRatio returns the factor converting a ratio of base unit amounts of tokens with
+// decimals0 and decimals1 decimals, e.g. a pool price, to a ratio of whole tokens
+func baseUnitsRatio(decimals0 uint8, decimals1 uint8) float64 {
+	return math.Pow10(int(decimals0) - int(decimals1))
+}
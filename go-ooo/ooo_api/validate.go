@@ -0,0 +1,162 @@
+package ooo_api
+
+import (
+	"fmt"
+	"go-ooo/errcodes"
+	"go-ooo/utils"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// ValidationError is returned when data from an upstream source does not match
+// the expected schema. Rejected data never enters price aggregation.
+type ValidationError struct {
+	Source string
+	Field  string
+	Reason string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: invalid %s: %s", e.Source, e.Field, e.Reason)
+}
+
+func newValidationError(source, field, reason string) ValidationError {
+	return ValidationError{
+		Source: source,
+		Field:  field,
+		Reason: reason,
+	}
+}
+
+// validateDecimalString checks the value is a finite decimal number, and
+// that it is > 0, or >= 0 if allowZero is set.
+func validateDecimalString(source, field, value string, allowZero bool) (*big.Float, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, newValidationError(source, field, "missing")
+	}
+
+	f, err := utils.ParseBigFloat(value)
+	if err != nil {
+		return nil, newValidationError(source, field, fmt.Sprintf("not a number (%s)", value))
+	}
+
+	if f.IsInf() {
+		return nil, newValidationError(source, field, "infinite")
+	}
+
+	switch f.Sign() {
+	case -1:
+		return nil, newValidationError(source, field, fmt.Sprintf("negative (%s)", value))
+	case 0:
+		if !allowZero {
+			return nil, newValidationError(source, field, "zero")
+		}
+	}
+
+	return f, nil
+}
+
+// ValidatePriceResult checks a final price, as submitted on-chain, is a positive base 10 integer
+func ValidatePriceResult(price string) error {
+	if price == "" {
+		return errcodes.Wrap(errcodes.ErrInvalidPrice, newValidationError("result", "price", "missing"))
+	}
+
+	p, ok := new(big.Int).SetString(price, 10)
+
+	if !ok {
+		return errcodes.Wrap(errcodes.ErrInvalidPrice, newValidationError("result", "price", fmt.Sprintf("not an integer (%s)", price)))
+	}
+
+	if p.Sign() <= 0 {
+		return errcodes.Wrap(errcodes.ErrInvalidPrice, newValidationError("result", "price", fmt.Sprintf("must be > 0 (%s)", price)))
+	}
+
+	if p.Cmp(utils.MaxUint256) > 0 {
+		return errcodes.Wrap(errcodes.ErrInvalidPrice, newValidationError("result", "price", "overflows uint256"))
+	}
+
+	return nil
+}
+
+func validateFinchainsPriceResult(result OoOAPIPriceQueryResult, base, target string) error {
+	if result.Base != "" && !strings.EqualFold(result.Base, base) {
+		return newValidationError("finchains", "base", fmt.Sprintf("expected %s, got %s", base, result.Base))
+	}
+
+	if result.Target != "" && !strings.EqualFold(result.Target, target) {
+		return newValidationError("finchains", "target", fmt.Sprintf("expected %s, got %s", target, result.Target))
+	}
+
+	err := ValidatePriceResult(result.Price)
+	if err != nil {
+		return newValidationError("finchains", "price", err.(ValidationError).Reason)
+	}
+
+	return nil
+}
+
+func validateFinchainsPair(pair OoOAPIPairsResult) error {
+	if pair.Name == "" {
+		return newValidationError("finchains", "pair name", "missing")
+	}
+	if pair.Base == "" {
+		return newValidationError("finchains", "pair base", "missing")
+	}
+	if pair.Target == "" {
+		return newValidationError("finchains", "pair target", "missing")
+	}
+	return nil
+}
+
+// validateGraphQlPairTokens checks the identifying fields of a subgraph pair/pool
+func validateGraphQlPairTokens(dexName string, pair GraphQlPairContent) error {
+	if pair.Id == "" {
+		return newValidationError(dexName, "pair id", "missing")
+	}
+	if pair.Token0.Id == "" || pair.Token0.Symbol == "" {
+		return newValidationError(dexName, "token0", "missing id or symbol")
+	}
+	if pair.Token1.Id == "" || pair.Token1.Symbol == "" {
+		return newValidationError(dexName, "token1", "missing id or symbol")
+	}
+	return nil
+}
+
+// validateGraphQlPairPrices checks a subgraph pair/pool snapshot is usable for price aggregation,
+// returning the parsed liquidity and token prices
+func validateGraphQlPairPrices(dexName string, pair GraphQlPairContent, liquidity string) (reserve, token0Price, token1Price *big.Float, err error) {
+	if err = validateGraphQlPairTokens(dexName, pair); err != nil {
+		return
+	}
+
+	if reserve, err = validateDecimalString(dexName, "liquidity", liquidity, true); err != nil {
+		return
+	}
+
+	if token0Price, err = validateDecimalString(dexName, "token0Price", pair.Token0Price, false); err != nil {
+		return
+	}
+
+	token1Price, err = validateDecimalString(dexName, "token1Price", pair.Token1Price, false)
+
+	return
+}
+
+// summariseRejections generates a short, stable summary of why data was rejected,
+// suitable for storing as a request's status reason
+func summariseRejections(rejections []error) string {
+	counts := make(map[string]int)
+	for _, r := range rejections {
+		counts[r.Error()]++
+	}
+
+	reasons := make([]string, 0, len(counts))
+	for r, c := range counts {
+		reasons = append(reasons, fmt.Sprintf("%s (x%d)", r, c))
+	}
+	sort.Strings(reasons)
+
+	return strings.Join(reasons, "; ")
+}
@@ -0,0 +1,446 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package price_feed
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// PriceFeedMetaData contains all meta data concerning the PriceFeed contract.
+var PriceFeedMetaData = &bind.MetaData{
+	ABI: "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"int256\",\"name\":\"current\",\"type\":\"int256\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"roundId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"updatedAt\",\"type\":\"uint256\"}],\"name\":\"AnswerUpdated\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"decimals\",\"outputs\":[{\"internalType\":\"uint8\",\"name\":\"\",\"type\":\"uint8\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"latestRoundData\",\"outputs\":[{\"internalType\":\"uint80\",\"name\":\"roundId\",\"type\":\"uint80\"},{\"internalType\":\"int256\",\"name\":\"answer\",\"type\":\"int256\"},{\"internalType\":\"uint256\",\"name\":\"startedAt\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"updatedAt\",\"type\":\"uint256\"},{\"internalType\":\"uint80\",\"name\":\"answeredInRound\",\"type\":\"uint80\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"int256\",\"name\":\"_answer\",\"type\":\"int256\"}],\"name\":\"updateAnswer\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+}
+
+// PriceFeedABI is the input ABI used to generate the binding from.
+// Deprecated: Use PriceFeedMetaData.ABI instead.
+var PriceFeedABI = PriceFeedMetaData.ABI
+
+// PriceFeed is an auto generated Go binding around an Ethereum contract.
+type PriceFeed struct {
+	PriceFeedCaller     // Read-only binding to the contract
+	PriceFeedTransactor // Write-only binding to the contract
+	PriceFeedFilterer   // Log filterer for contract events
+}
+
+// PriceFeedCaller is an auto generated read-only Go binding around an Ethereum contract.
+type PriceFeedCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PriceFeedTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type PriceFeedTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PriceFeedFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type PriceFeedFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PriceFeedSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type PriceFeedSession struct {
+	Contract     *PriceFeed        // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// PriceFeedCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type PriceFeedCallerSession struct {
+	Contract *PriceFeedCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts    // Call options to use throughout this session
+}
+
+// PriceFeedTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type PriceFeedTransactorSession struct {
+	Contract     *PriceFeedTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts    // Transaction auth options to use throughout this session
+}
+
+// PriceFeedRaw is an auto generated low-level Go binding around an Ethereum contract.
+type PriceFeedRaw struct {
+	Contract *PriceFeed // Generic contract binding to access the raw methods on
+}
+
+// PriceFeedCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type PriceFeedCallerRaw struct {
+	Contract *PriceFeedCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// PriceFeedTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type PriceFeedTransactorRaw struct {
+	Contract *PriceFeedTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewPriceFeed creates a new instance of PriceFeed, bound to a specific deployed contract.
+func NewPriceFeed(address common.Address, backend bind.ContractBackend) (*PriceFeed, error) {
+	contract, err := bindPriceFeed(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &PriceFeed{PriceFeedCaller: PriceFeedCaller{contract: contract}, PriceFeedTransactor: PriceFeedTransactor{contract: contract}, PriceFeedFilterer: PriceFeedFilterer{contract: contract}}, nil
+}
+
+// NewPriceFeedCaller creates a new read-only instance of PriceFeed, bound to a specific deployed contract.
+func NewPriceFeedCaller(address common.Address, caller bind.ContractCaller) (*PriceFeedCaller, error) {
+	contract, err := bindPriceFeed(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PriceFeedCaller{contract: contract}, nil
+}
+
+// NewPriceFeedTransactor creates a new write-only instance of PriceFeed, bound to a specific deployed contract.
+func NewPriceFeedTransactor(address common.Address, transactor bind.ContractTransactor) (*PriceFeedTransactor, error) {
+	contract, err := bindPriceFeed(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PriceFeedTransactor{contract: contract}, nil
+}
+
+// NewPriceFeedFilterer creates a new log filterer instance of PriceFeed, bound to a specific deployed contract.
+func NewPriceFeedFilterer(address common.Address, filterer bind.ContractFilterer) (*PriceFeedFilterer, error) {
+	contract, err := bindPriceFeed(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &PriceFeedFilterer{contract: contract}, nil
+}
+
+// bindPriceFeed binds a generic wrapper to an already deployed contract.
+func bindPriceFeed(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(PriceFeedABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_PriceFeed *PriceFeedRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _PriceFeed.Contract.PriceFeedCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_PriceFeed *PriceFeedRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _PriceFeed.Contract.PriceFeedTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_PriceFeed *PriceFeedRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _PriceFeed.Contract.PriceFeedTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_PriceFeed *PriceFeedCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _PriceFeed.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_PriceFeed *PriceFeedTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _PriceFeed.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_PriceFeed *PriceFeedTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _PriceFeed.Contract.contract.Transact(opts, method, params...)
+}
+
+// Decimals is a free data retrieval call binding the contract method 0x313ce567.
+//
+// Solidity: function decimals() view returns(uint8)
+func (_PriceFeed *PriceFeedCaller) Decimals(opts *bind.CallOpts) (uint8, error) {
+	var out []interface{}
+	err := _PriceFeed.contract.Call(opts, &out, "decimals")
+
+	if err != nil {
+		return *new(uint8), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(uint8)).(*uint8)
+
+	return out0, err
+
+}
+
+// Decimals is a free data retrieval call binding the contract method 0x313ce567.
+//
+// Solidity: function decimals() view returns(uint8)
+func (_PriceFeed *PriceFeedSession) Decimals() (uint8, error) {
+	return _PriceFeed.Contract.Decimals(&_PriceFeed.CallOpts)
+}
+
+// Decimals is a free data retrieval call binding the contract method 0x313ce567.
+//
+// Solidity: function decimals() view returns(uint8)
+func (_PriceFeed *PriceFeedCallerSession) Decimals() (uint8, error) {
+	return _PriceFeed.Contract.Decimals(&_PriceFeed.CallOpts)
+}
+
+// LatestRoundData is a free data retrieval call binding the contract method 0xfeaf968c.
+//
+// Solidity: function latestRoundData() view returns(uint80 roundId, int256 answer, uint256 startedAt, uint256 updatedAt, uint80 answeredInRound)
+func (_PriceFeed *PriceFeedCaller) LatestRoundData(opts *bind.CallOpts) (struct {
+	RoundId         *big.Int
+	Answer          *big.Int
+	StartedAt       *big.Int
+	UpdatedAt       *big.Int
+	AnsweredInRound *big.Int
+}, error) {
+	var out []interface{}
+	err := _PriceFeed.contract.Call(opts, &out, "latestRoundData")
+
+	outstruct := new(struct {
+		RoundId         *big.Int
+		Answer          *big.Int
+		StartedAt       *big.Int
+		UpdatedAt       *big.Int
+		AnsweredInRound *big.Int
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.RoundId = *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	outstruct.Answer = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	outstruct.StartedAt = *abi.ConvertType(out[2], new(*big.Int)).(**big.Int)
+	outstruct.UpdatedAt = *abi.ConvertType(out[3], new(*big.Int)).(**big.Int)
+	outstruct.AnsweredInRound = *abi.ConvertType(out[4], new(*big.Int)).(**big.Int)
+
+	return *outstruct, err
+
+}
+
+// LatestRoundData is a free data retrieval call binding the contract method 0xfeaf968c.
+//
+// Solidity: function latestRoundData() view returns(uint80 roundId, int256 answer, uint256 startedAt, uint256 updatedAt, uint80 answeredInRound)
+func (_PriceFeed *PriceFeedSession) LatestRoundData() (struct {
+	RoundId         *big.Int
+	Answer          *big.Int
+	StartedAt       *big.Int
+	UpdatedAt       *big.Int
+	AnsweredInRound *big.Int
+}, error) {
+	return _PriceFeed.Contract.LatestRoundData(&_PriceFeed.CallOpts)
+}
+
+// LatestRoundData is a free data retrieval call binding the contract method 0xfeaf968c.
+//
+// Solidity: function latestRoundData() view returns(uint80 roundId, int256 answer, uint256 startedAt, uint256 updatedAt, uint80 answeredInRound)
+func (_PriceFeed *PriceFeedCallerSession) LatestRoundData() (struct {
+	RoundId         *big.Int
+	Answer          *big.Int
+	StartedAt       *big.Int
+	UpdatedAt       *big.Int
+	AnsweredInRound *big.Int
+}, error) {
+	return _PriceFeed.Contract.LatestRoundData(&_PriceFeed.CallOpts)
+}
+
+// UpdateAnswer is a paid mutator transaction binding the contract method 0xa87a20ce.
+//
+// Solidity: function updateAnswer(int256 _answer) returns()
+func (_PriceFeed *PriceFeedTransactor) UpdateAnswer(opts *bind.TransactOpts, _answer *big.Int) (*types.Transaction, error) {
+	return _PriceFeed.contract.Transact(opts, "updateAnswer", _answer)
+}
+
+// UpdateAnswer is a paid mutator transaction binding the contract method 0xa87a20ce.
+//
+// Solidity: function updateAnswer(int256 _answer) returns()
+func (_PriceFeed *PriceFeedSession) UpdateAnswer(_answer *big.Int) (*types.Transaction, error) {
+	return _PriceFeed.Contract.UpdateAnswer(&_PriceFeed.TransactOpts, _answer)
+}
+
+// UpdateAnswer is a paid mutator transaction binding the contract method 0xa87a20ce.
+//
+// Solidity: function updateAnswer(int256 _answer) returns()
+func (_PriceFeed *PriceFeedTransactorSession) UpdateAnswer(_answer *big.Int) (*types.Transaction, error) {
+	return _PriceFeed.Contract.UpdateAnswer(&_PriceFeed.TransactOpts, _answer)
+}
+
+// PriceFeedAnswerUpdatedIterator is returned from FilterAnswerUpdated and is used to iterate over the raw logs and unpacked data for AnswerUpdated events raised by the PriceFeed contract.
+type PriceFeedAnswerUpdatedIterator struct {
+	Event *PriceFeedAnswerUpdated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *PriceFeedAnswerUpdatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(PriceFeedAnswerUpdated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(PriceFeedAnswerUpdated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *PriceFeedAnswerUpdatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *PriceFeedAnswerUpdatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// PriceFeedAnswerUpdated represents a AnswerUpdated event raised by the PriceFeed contract.
+type PriceFeedAnswerUpdated struct {
+	Current   *big.Int
+	RoundId   *big.Int
+	UpdatedAt *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterAnswerUpdated is a free log retrieval operation binding the contract event 0x0559884fd3a460db3073b7fc896cc77986f16e378210ded43186175bf646fc5f.
+//
+// Solidity: event AnswerUpdated(int256 indexed current, uint256 indexed roundId, uint256 updatedAt)
+func (_PriceFeed *PriceFeedFilterer) FilterAnswerUpdated(opts *bind.FilterOpts, current []*big.Int, roundId []*big.Int) (*PriceFeedAnswerUpdatedIterator, error) {
+
+	var currentRule []interface{}
+	for _, currentItem := range current {
+		currentRule = append(currentRule, currentItem)
+	}
+	var roundIdRule []interface{}
+	for _, roundIdItem := range roundId {
+		roundIdRule = append(roundIdRule, roundIdItem)
+	}
+
+	logs, sub, err := _PriceFeed.contract.FilterLogs(opts, "AnswerUpdated", currentRule, roundIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &PriceFeedAnswerUpdatedIterator{contract: _PriceFeed.contract, event: "AnswerUpdated", logs: logs, sub: sub}, nil
+}
+
+// WatchAnswerUpdated is a free log subscription operation binding the contract event 0x0559884fd3a460db3073b7fc896cc77986f16e378210ded43186175bf646fc5f.
+//
+// Solidity: event AnswerUpdated(int256 indexed current, uint256 indexed roundId, uint256 updatedAt)
+func (_PriceFeed *PriceFeedFilterer) WatchAnswerUpdated(opts *bind.WatchOpts, sink chan<- *PriceFeedAnswerUpdated, current []*big.Int, roundId []*big.Int) (event.Subscription, error) {
+
+	var currentRule []interface{}
+	for _, currentItem := range current {
+		currentRule = append(currentRule, currentItem)
+	}
+	var roundIdRule []interface{}
+	for _, roundIdItem := range roundId {
+		roundIdRule = append(roundIdRule, roundIdItem)
+	}
+
+	logs, sub, err := _PriceFeed.contract.WatchLogs(opts, "AnswerUpdated", currentRule, roundIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(PriceFeedAnswerUpdated)
+				if err := _PriceFeed.contract.UnpackLog(event, "AnswerUpdated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseAnswerUpdated is a log parse operation binding the contract event 0x0559884fd3a460db3073b7fc896cc77986f16e378210ded43186175bf646fc5f.
+//
+// Solidity: event AnswerUpdated(int256 indexed current, uint256 indexed roundId, uint256 updatedAt)
+func (_PriceFeed *PriceFeedFilterer) ParseAnswerUpdated(log types.Log) (*PriceFeedAnswerUpdated, error) {
+	event := new(PriceFeedAnswerUpdated)
+	if err := _PriceFeed.contract.UnpackLog(event, "AnswerUpdated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
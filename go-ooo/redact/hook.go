@@ -0,0 +1,31 @@
+package redact
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook masks secrets in log entries' messages and fields. It must be added to a logger before
+// hooks which send entries elsewhere, e.g. error reporting
+type Hook struct{}
+
+func (Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (Hook) Fire(entry *logrus.Entry) error {
+	entry.Message = String(entry.Message)
+
+	for k, v := range entry.Data {
+		switch val := v.(type) {
+		case string:
+			entry.Data[k] = String(val)
+		case error:
+			entry.Data[k] = Error(val)
+		case fmt.Stringer:
+			entry.Data[k] = String(val.String())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package redact
+
+import (
+	"github.com/spf13/viper"
+	"strings"
+	"testing"
+)
+
+func TestIsSecretKey(t *testing.T) {
+	tests := []struct {
+		key    string
+		secret bool
+	}{
+		{"database.password", true},
+		{"database.encryption_key", true},
+		{"database.encryption_previous_keys", true},
+		{"cache.redis_password", true},
+		{"ipfs.password", true},
+		{"vault.token", true},
+		{"vault.secret_id", true},
+		{"signer.aws_secret_access_key", true},
+		{"signer.aws_session_token", true},
+		{"admin_api.tokens", true},
+		{"admin_api.jwt_secret", true},
+		{"webhooks.secret", true},
+		{"consensus.token", true},
+		{"alerts.telegram_bot_token", true},
+		{"alerts.slack_webhook_url", true},
+		{"alerts.webhook_url", true},
+		{"alerts.webhook_secret", true},
+		{"alerts.pagerduty_routing_key", true},
+		{"alerts.smtp_password", true},
+		{"tracing.headers", true},
+		{"error_reporting.sentry_dsn", true},
+		{"profiles.sepolia.database.password", true},
+		{"Database.Password", true},
+		// table entries are matched by their own key
+		{"api_key", true},
+		{"secret", true},
+
+		// paths, names and addresses which only contain a secret's name
+		{"jobs.token_pins", false},
+		{"keystorage.password_file", false},
+		{"keystorage.password_env", false},
+		{"keystorage.password_command", false},
+		{"vault.password_field", false},
+		{"vault.secret_path", false},
+		{"vault.secret_id_file", false},
+		{"signer.aws_kms_key_id", false},
+		{"admin_api.tls_key_file", false},
+		{"ha.lock_key", false},
+		{"database.storage", false},
+		{"jobs.ooo_api_url", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsSecretKey(tt.key); got != tt.secret {
+			t.Errorf("IsSecretKey(%q) = %v, want %v", tt.key, got, tt.secret)
+		}
+	}
+}
+
+func TestAddConfigSecrets(t *testing.T) {
+	pin := "0x6B175474E89094C44Da98b954EedeAC495271d0F"
+	v := viper.New()
+	v.Set("database.password", "hunter2hunter2")
+	v.Set("keystorage.password_file", "/etc/go-ooo/password-file")
+	v.Set("vault.secret_path", "secret/data/go-ooo")
+	v.Set("jobs.token_pins", map[string]string{"DAI": pin})
+	AddConfigSecrets(v)
+
+	s := String("connecting with hunter2hunter2, password from /etc/go-ooo/password-file, vault path secret/data/go-ooo, DAI pinned to " + pin)
+	if strings.Contains(s, "hunter2hunter2") {
+		t.Errorf("database.password not masked: %s", s)
+	}
+	for _, public := range []string{"/etc/go-ooo/password-file", "secret/data/go-ooo", pin} {
+		if !strings.Contains(s, public) {
+			t.Errorf("%q masked: %s", public, s)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Package schedule confines heavy background tasks - the reconciliation, the DEX pairs' full
+// subgraph resync and the database vacuum - to configured maintenance windows, so that they do
+// not compete with live fulfillment during the hours most requests are received. A task which
+// falls due outside its windows is deferred until the next one opens. Tasks run as scheduled by
+// their own config if no window applies to them
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"strings"
+	"time"
+)
+
+// heavy tasks which can be confined to maintenance windows
+const (
+	TaskReconcile   = "reconcile"
+	TaskDexFullSync = "dex_full_sync"
+	TaskDbVacuum    = "db_vacuum"
+)
+
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+var taskDeferred = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "ooo_heavy_task_deferred",
+	Help: "1 while a heavy background task is due but waiting for one of its maintenance windows to open, otherwise 0",
+}, []string{"task"})
+
+// Tasks returns the names of the tasks which can be confined to maintenance windows
+func Tasks() []string {
+	return []string{TaskReconcile, TaskDexFullSync, TaskDbVacuum}
+}
+
+// Window is a period, in UTC, during which heavy tasks may run, e.g.
+//
+//	[[schedule.maintenance_windows]]
+//	days = ["sat", "sun"]
+//	start = "01:00"
+//	end = "05:00"
+//	tasks = ["reconcile", "dex_full_sync"]
+//
+// A window ending at or before its start ends the next day. Days default to every day, and
+// tasks to every task
+type Window struct {
+	Days  []string `mapstructure:"days" json:"days"`
+	Start string   `mapstructure:"start" json:"start"`
+	End   string   `mapstructure:"end" json:"end"`
+	Tasks []string `mapstructure:"tasks" json:"tasks"`
+
+	days  [7]bool
+	start int
+	end   int
+}
+
+// Load reads and validates the configured maintenance windows
+func Load() ([]Window, error) {
+	var windows []Window
+	var err error
+	if raw, ok := viper.Get(config.ScheduleMaintenanceWindows).(string); ok {
+		// set by an environment variable, as a JSON array of windows
+		err = json.Unmarshal([]byte(raw), &windows)
+	} else {
+		err = viper.UnmarshalKey(config.ScheduleMaintenanceWindows, &windows)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range windows {
+		w := &windows[i]
+		if w.start, err = parseClock(w.Start); err != nil {
+			return nil, fmt.Errorf("maintenance window %d: start %s", i+1, err.Error())
+		}
+		if w.end, err = parseClock(w.End); err != nil {
+			return nil, fmt.Errorf("maintenance window %d: end %s", i+1, err.Error())
+		}
+
+		for _, day := range w.Days {
+			d := dayIndex(day)
+			if d < 0 {
+				return nil, fmt.Errorf("maintenance window %d: day %q must be one of %s", i+1, day, strings.Join(weekdays, ", "))
+			}
+			w.days[d] = true
+		}
+		if len(w.Days) == 0 {
+			for d := range w.days {
+				w.days[d] = true
+			}
+		}
+
+		for _, task := range w.Tasks {
+			if !validTask(task) {
+				return nil, fmt.Errorf("maintenance window %d: task %q must be one of %s", i+1, task, strings.Join(Tasks(), ", "))
+			}
+		}
+	}
+
+	return windows, nil
+}
+
+// parseClock returns the minutes after midnight of a HH:MM time
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q must be a time of the form HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// dayIndex returns the time.Weekday of a day, e.g. "mon" or "monday", or -1 if it is not a day
+func dayIndex(day string) int {
+	day = strings.ToLower(day)
+	for i, d := range weekdays {
+		if day == d || day == strings.ToLower(time.Weekday(i).String()) {
+			return i
+		}
+	}
+	return -1
+}
+
+func validTask(task string) bool {
+	for _, t := range Tasks() {
+		if t == task {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesTo returns true if the window confines task
+func (w Window) appliesTo(task string) bool {
+	if len(w.Tasks) == 0 {
+		return true
+	}
+	for _, t := range w.Tasks {
+		if t == task {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns true if the window is open at t
+func (w Window) contains(t time.Time) bool {
+	t = t.UTC()
+	minute := t.Hour()*60 + t.Minute()
+	day := int(t.Weekday())
+
+	if w.start < w.end {
+		return w.days[day] && minute >= w.start && minute < w.end
+	}
+	// spans midnight - open from the start on one of its days, until the end the next day
+	return (w.days[day] && minute >= w.start) || (w.days[(day+6)%7] && minute < w.end)
+}
+
+// closesAt returns when the window, open at t, closes
+func (w Window) closesAt(t time.Time) time.Time {
+	t = t.UTC()
+	end := time.Date(t.Year(), t.Month(), t.Day(), 0, w.end, 0, 0, time.UTC)
+	if w.start >= w.end && t.Hour()*60+t.Minute() >= w.start {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// Confined returns true if any maintenance window applies to task
+func Confined(task string) bool {
+	// validated when the config is loaded
+	windows, _ := Load()
+	for _, w := range windows {
+		if w.appliesTo(task) {
+			return true
+		}
+	}
+	return false
+}
+
+// Open returns true if task may run at t - one of its maintenance windows is open, or none
+// applies to it
+func Open(task string, t time.Time) bool {
+	windows, _ := Load()
+	confined := false
+	for _, w := range windows {
+		if !w.appliesTo(task) {
+			continue
+		}
+		if w.contains(t) {
+			return true
+		}
+		confined = true
+	}
+	return !confined
+}
+
+// ClosesAt returns when the last of task's maintenance windows open at t closes, so that a run
+// started in a window can be stopped before it overruns into peak hours, and false if none is open
+func ClosesAt(task string, t time.Time) (time.Time, bool) {
+	windows, _ := Load()
+	var closes time.Time
+	for _, w := range windows {
+		if w.appliesTo(task) && w.contains(t) {
+			if end := w.closesAt(t); end.After(closes) {
+				closes = end
+			}
+		}
+	}
+	return closes, !closes.IsZero()
+}
+
+// DueDaily returns true if task, run daily at the UTC hour and last run at lastRun, should run
+// now. A task no window applies to runs during the hour, as scheduled by its own config. A
+// confined task is due from the hour until it next runs, and runs once one of its windows is open
+func DueDaily(task string, hour int, lastRun time.Time) bool {
+	now := time.Now().UTC()
+	if !Confined(task) {
+		return now.Hour() == hour
+	}
+
+	dueAt := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if dueAt.After(now) {
+		dueAt = dueAt.AddDate(0, 0, -1)
+	}
+	if !lastRun.Before(dueAt) {
+		return false
+	}
+	return Allow(task)
+}
+
+// Allow is called when task is due, and returns true if it may run now. Otherwise the task is
+// recorded as deferred until one of its windows opens
+func Allow(task string) bool {
+	if Open(task, time.Now()) {
+		taskDeferred.WithLabelValues(task).Set(0)
+		return true
+	}
+	taskDeferred.WithLabelValues(task).Set(1)
+	return false
+}
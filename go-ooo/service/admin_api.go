@@ -0,0 +1,544 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database"
+	"go-ooo/database/models"
+	"go-ooo/errcodes"
+	"go-ooo/ooo_api"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// adminApiPrefix - the path the admin API's routes are served at
+const adminApiPrefix = "/api/v1"
+
+// initAdminApi serves the admin API on a local port, so that operators can manage
+// the node without access to the host's database or CLI
+func (s *Service) initAdminApi() {
+	port := viper.GetInt(config.AdminApiPort)
+	if port == 0 || !config.FeatureEnabled(config.FeaturesAdminApi) {
+		return
+	}
+
+	listen := fmt.Sprintf("%s:%d", viper.GetString(config.AdminApiHost), port)
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "initAdminApi",
+		"listen":   listen,
+		"tls":      viper.GetString(config.AdminApiTlsCertFile) != "",
+		"mtls":     viper.GetString(config.AdminApiTlsClientCaFile) != "",
+	}).Info("initialise admin api")
+
+	s.adminEcho.HideBanner = true
+	s.adminEcho.Use(middleware.Recover())
+	s.adminEcho.Use(redactErrors)
+
+	if viper.GetBool(config.AdminApiDashboard) {
+		s.initDashboard()
+	}
+
+	s.adminRoutes(s.adminEcho.Group(adminApiPrefix, s.adminAuth()...))
+
+	tlsConfig, err := adminTlsConfig()
+	switch {
+	case err != nil:
+	case tlsConfig != nil:
+		s.adminEcho.TLSServer.Addr = listen
+		s.adminEcho.TLSServer.TLSConfig = tlsConfig
+		err = s.adminEcho.StartServer(s.adminEcho.TLSServer)
+	default:
+		err = s.adminEcho.Start(listen)
+	}
+	if err != nil && err != http.ErrServerClosed {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "initAdminApi",
+		}).Error(err.Error())
+	}
+}
+
+// adminRoutes mounts the admin API's routes. They are served at adminApiPrefix on the admin API,
+// and on the service port for the CLI, with the same paths, methods and scope checks
+func (s *Service) adminRoutes(g *echo.Group) {
+	g.GET("/status", s.GetStatus)
+	g.GET("/version", s.GetVersion)
+	g.GET("/config", s.GetConfig)
+	g.POST("/config/reload", s.ReloadConfigHandler)
+	g.GET("/audit", s.GetAuditLog)
+	g.GET("/pairs", s.GetPairs)
+	g.POST("/pairs/refresh", s.RefreshPairs)
+	g.GET("/pairs/delisted", s.GetPairDelistings)
+	g.GET("/pairs/history", s.GetPairHistory)
+	g.GET("/sources", s.GetSourceHealth)
+	g.GET("/sources/exclusions", s.GetSourceExclusions)
+	g.DELETE("/sources/exclusions", s.ClearSourceExclusions)
+	g.GET("/price", s.ExplainPrice)
+	g.GET("/lint", s.LintEndpoint)
+	g.GET("/latency", s.GetLatencyReport)
+	g.GET("/report", s.GetEarningsReport)
+	g.GET("/gas", s.GetGasSeries)
+	g.GET("/answers/:id", s.GetAnswer)
+	g.GET("/consumers", s.GetConsumersUsage)
+	g.GET("/sla", s.GetSlaReport)
+	g.GET("/consumers/:consumer", s.GetConsumerUsage)
+	g.GET("/xfund/allowances", s.GetXfundAllowances)
+	g.GET("/fees/recommendation", s.GetFeeRecommendation)
+	g.GET("/tags", s.GetMetadata)
+	g.PUT("/tags/consumers/:consumer", s.SetConsumerMetadata)
+	g.DELETE("/tags/consumers/:consumer", s.DeleteConsumerMetadata)
+	g.PUT("/tags/pairs/:pair", s.SetPairMetadata)
+	g.DELETE("/tags/pairs/:pair", s.DeletePairMetadata)
+	g.GET("/credentials", s.GetCredentials)
+	g.PUT("/credentials/:name", s.SetCredential)
+	g.DELETE("/credentials/:name", s.DeleteCredential)
+	g.GET("/reconciliations", s.GetReconciliations)
+	g.GET("/state_snapshot", s.GetStateSnapshot)
+	g.GET("/chain_events", s.GetChainEvents)
+	g.POST("/reconcile", s.Reconcile)
+	g.GET("/log/level", s.GetLogLevel)
+	g.PUT("/log/level", s.SetLogLevel)
+	g.GET("/maintenance", s.GetMaintenance)
+	g.POST("/maintenance", s.StartMaintenance)
+	g.DELETE("/maintenance", s.EndMaintenance)
+	g.GET("/paused", s.AdminPauseTask("query_paused"))
+	g.POST("/pause", s.AdminPauseTask("pause"))
+	g.POST("/resume", s.AdminPauseTask("resume"))
+
+	g.GET("/jobs", s.SearchJobs)
+	g.GET("/jobs/dead", s.GetDeadJobs)
+	g.GET("/jobs/events", s.StreamJobEvents)
+	g.GET("/jobs/:request_id", s.GetRequest)
+	g.GET("/jobs/:request_id/journal", s.GetJournal)
+	g.GET("/jobs/:request_id/attestation", s.GetAttestation)
+	g.GET("/jobs/:request_id/sources", s.GetRequestSources)
+	g.POST("/jobs/:request_id/requeue", s.RequeueJob)
+	g.POST("/jobs/:request_id/fulfill", s.ForceFulfillJob)
+	g.POST("/jobs/:request_id/skip", s.SkipJob)
+
+	g.GET("/ledger/pending", s.GetLedgerPending)
+	g.POST("/ledger/pending/:id/approve", s.ApproveLedgerTx(true))
+	g.POST("/ledger/pending/:id/reject", s.ApproveLedgerTx(false))
+}
+
+// GetXfundAllowances returns the oracle key's xFUND allowance for each monitored spender
+func (s *Service) GetXfundAllowances(c echo.Context) error {
+	allowances, err := s.oooRouterService.XfundAllowances()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, allowances)
+}
+
+// GetFeeRecommendation returns the provider fee recommended from the gas cost of recent
+// fulfillments, without changing the fee
+func (s *Service) GetFeeRecommendation(c echo.Context) error {
+	rec, err := s.oooRouterService.RecommendFee(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusServiceUnavailable, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, rec)
+}
+
+// GetStatus returns a summary of the node's state - chain sync, balances, pending jobs and source health
+func (s *Service) GetStatus(c echo.Context) error {
+	status, err := s.oooRouterService.NodeStatus()
+	status.Leader = s.isLeader()
+	status.Sources = s.sourceHealth()
+	if budget := s.oooApi.SubgraphBudgetStatus(); budget.Budget > 0 || budget.Spent > 0 {
+		status.SubgraphBudget = &budget
+	}
+	if w, active := s.maintenance.get(); active {
+		m := maintenanceStatus(w)
+		status.Maintenance = &m
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, status)
+}
+
+// GetPairs lists the supported pairs and any with source overrides or custom pairs, along with the sources which
+// cover each, the last fulfilled price and DEX liquidity. The pair query param, e.g. BTC.USD,
+// returns a single pair
+func (s *Service) GetPairs(c echo.Context) error {
+	pairs, err := s.db.GetSupportedPairs()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	filter := strings.ToUpper(c.QueryParam("pair"))
+	overrides := s.oooApi.PairSourceOverrides()
+	statuses := make(map[string]go_ooo_types.PairStatus)
+
+	for _, p := range pairs {
+		name := fmt.Sprintf("%s.%s", strings.ToUpper(p.GetBase()), strings.ToUpper(p.GetTarget()))
+		statuses[name] = go_ooo_types.PairStatus{Pair: name, Supported: true}
+	}
+
+	for name, o := range overrides {
+		ps, ok := statuses[name]
+		if !ok {
+			ps = go_ooo_types.PairStatus{Pair: name}
+		}
+		ps.Include = o.Include
+		ps.Exclude = o.Exclude
+		statuses[name] = ps
+	}
+
+	for _, cp := range s.oooApi.CustomPairs() {
+		ps, ok := statuses[cp.Pair]
+		if !ok {
+			ps = go_ooo_types.PairStatus{Pair: cp.Pair}
+		}
+		ps.CustomDexes = append(ps.CustomDexes, cp.Dex)
+		statuses[cp.Pair] = ps
+	}
+
+	if filter != "" {
+		ps, ok := statuses[filter]
+		if !ok {
+			ps = go_ooo_types.PairStatus{Pair: filter}
+		}
+		statuses = map[string]go_ooo_types.PairStatus{filter: ps}
+	}
+
+	res := make([]go_ooo_types.PairStatus, 0, len(statuses))
+	for _, ps := range statuses {
+		res = append(res, s.pairCoverage(ps))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Pair < res[j].Pair
+	})
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (s *Service) pairCoverage(ps go_ooo_types.PairStatus) go_ooo_types.PairStatus {
+	parts := strings.SplitN(ps.Pair, ".", 2)
+	if len(parts) != 2 {
+		return ps
+	}
+	base, target := parts[0], parts[1]
+
+	sources, dexPairs := s.oooApi.PairCoverage(base, target, ps.Supported)
+	ps.Sources = sources
+
+	for dex, p := range dexPairs {
+		ps.Liquidity = append(ps.Liquidity, go_ooo_types.DexLiquidity{
+			Dex:          dex,
+			PairAddress:  p.GetContractAddress(),
+			ReserveUsd:   p.ReserveUsd,
+			Volume24hUsd: p.GetVolumeUsd24h(),
+		})
+	}
+	sort.Slice(ps.Liquidity, func(i, j int) bool {
+		return ps.Liquidity[i].ReserveUsd > ps.Liquidity[j].ReserveUsd
+	})
+
+	if last, err := s.db.GetLastFulfilledForPair(base, target); err == nil {
+		ps.LastPrice = last.GetPriceResult()
+		ps.LastPriceEndpoint = last.GetEndpointDecoded()
+		ps.LastPriceAt = last.UpdatedAt.Unix()
+	}
+
+	return ps
+}
+
+// RefreshPairs forces a resync of the supported pairs and DEX pairs
+func (s *Service) RefreshPairs(c echo.Context) error {
+	if !s.isLeader() {
+		return c.JSON(http.StatusServiceUnavailable, "standby instance - refresh pairs on the leader")
+	}
+
+	err := s.oooApi.RefreshPairs()
+	s.audit(c, "refresh_pairs", "", nil, err)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, "supported pairs refreshed. DEX pairs are refreshing in the background")
+}
+
+// ExplainPrice fetches and aggregates a price now, without submitting it, returning each source's
+// value, those discarded as outliers, the weights and the answer. The endpoint query param is
+// explained if set, otherwise the pair's default endpoint, given with the pair param, e.g. ETH.USD
+func (s *Service) ExplainPrice(c echo.Context) error {
+	endpoint := strings.ToUpper(c.QueryParam("endpoint"))
+	if endpoint == "" {
+		base, target, ok := ooo_api.SplitPair(c.QueryParam("pair"))
+		if !ok {
+			return c.JSON(http.StatusBadRequest, "pair must be of the form BASE.TARGET, or give an endpoint")
+		}
+		endpoint = s.oooApi.DefaultPairEndpoint(base, target)
+	}
+
+	if rejection := s.oooApi.ValidateRequestEndpoint(endpoint); rejection != nil {
+		return c.JSON(http.StatusBadRequest, fmt.Sprintf("%s: %s", endpoint, rejection.Error()))
+	}
+
+	e := s.oooApi.ExplainEndpoint(endpoint)
+
+	res := go_ooo_types.PriceExplanation{
+		Endpoint:       e.Endpoint,
+		Method:         e.Method,
+		Values:         make([]go_ooo_types.ExplainedValue, 0, len(e.Values)),
+		Rejected:       e.Rejected,
+		Mean:           e.Mean,
+		StdDev:         e.StdDev,
+		DMax:           e.DMax,
+		FxRate:         e.FxRate,
+		Answer:         e.Answer,
+		AnswerDecimals: e.AnswerDecimals,
+		AnswerRounding: e.AnswerRounding,
+		SourceTier:     e.SourceTier,
+		Error:          e.Error,
+	}
+	for _, v := range e.Values {
+		res.Values = append(res.Values, go_ooo_types.ExplainedValue{
+			Source:      v.Source,
+			Value:       v.Value,
+			Deviation:   v.Deviation,
+			Excluded:    v.Excluded,
+			Weight:      v.Weight,
+			LatencyMs:   v.Latency.Milliseconds(),
+			PriceImpact: v.PriceImpact,
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetSourceHealth returns the last known health of each upstream data source
+func (s *Service) GetSourceHealth(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.sourceHealth())
+}
+
+func (s *Service) sourceHealth() []go_ooo_types.SourceHealth {
+	health := s.oooApi.SourceHealth()
+
+	res := make([]go_ooo_types.SourceHealth, 0, len(health))
+	for _, h := range health {
+		sh := go_ooo_types.SourceHealth{
+			Name:      h.Name,
+			Kind:      h.Kind,
+			Healthy:   h.Healthy,
+			LastError: h.LastError,
+		}
+		if !h.LastChecked.IsZero() {
+			sh.LastChecked = h.LastChecked.Unix()
+		}
+		res = append(res, sh)
+	}
+
+	return res
+}
+
+// GetSourceExclusions lists the sources not being used for a pair after a permanent error
+func (s *Service) GetSourceExclusions(c echo.Context) error {
+	exclusions := s.oooApi.SourceExclusions()
+
+	res := make([]go_ooo_types.SourceExclusion, 0, len(exclusions))
+	for _, e := range exclusions {
+		res = append(res, go_ooo_types.SourceExclusion{
+			Pair:   e.Pair,
+			Source: e.Source,
+			Reason: e.Reason,
+			Until:  e.Until.Unix(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+type sourceExclusionsAudit struct {
+	Pair    string `json:"pair,omitempty"`
+	Source  string `json:"source,omitempty"`
+	Cleared int    `json:"cleared"`
+}
+
+// ClearSourceExclusions lets excluded sources be used again. The pair and source query params
+// limit it to a pair or source, otherwise every exclusion is cleared
+func (s *Service) ClearSourceExclusions(c echo.Context) error {
+	pair := strings.ToUpper(c.QueryParam("pair"))
+	source := c.QueryParam("source")
+
+	n := s.oooApi.ClearSourceExclusions(pair, source)
+	s.audit(c, "clear_source_exclusions", "", sourceExclusionsAudit{Pair: pair, Source: source, Cleared: n}, nil)
+
+	return c.JSON(http.StatusOK, fmt.Sprintf("source exclusions cleared: %d", n))
+}
+
+// AdminPauseTask runs a pause, resume or query_paused admin task, with the scope taken from the query string
+func (s *Service) AdminPauseTask(task string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		s.adminTasks <- go_ooo_types.AdminTask{
+			Task:  task,
+			Scope: c.QueryParam("scope"),
+		}
+
+		tr := <-s.adminTasksResp
+		s.auditAdminTask(c, tr)
+		if tr.Success {
+			return c.JSON(http.StatusOK, tr)
+		}
+		return c.JSON(http.StatusInternalServerError, tr.Error)
+	}
+}
+
+// StreamJobEvents streams job lifecycle events to the client as server-sent events until it disconnects
+func (s *Service) StreamJobEvents(c echo.Context) error {
+	events, unsubscribe := s.oooRouterService.SubscribeJobEvents()
+	defer unsubscribe()
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-s.ctx.Done():
+			return nil
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Event, data); err != nil {
+				return nil
+			}
+			w.Flush()
+		}
+	}
+}
+
+// SearchJobs lists jobs, with the operator's tags for each job's consumer and pair, filtered by
+// the status, job_status, error_code, consumer, endpoint, tag and since (hours) query params. A
+// tag matches jobs whose consumer or pair has it. Use limit and offset to page through results
+func (s *Service) SearchJobs(c echo.Context) error {
+	filter := database.JobFilter{
+		Consumer: c.QueryParam("consumer"),
+		Endpoint: c.QueryParam("endpoint"),
+		Limit:    100,
+	}
+
+	if code := errcodes.Code(strings.ToUpper(c.QueryParam("error_code"))); code != "" {
+		if errcodes.Lookup(code) == nil && code != errcodes.CodeUnknown {
+			return c.JSON(http.StatusBadRequest, fmt.Sprintf("unknown error_code %s", code))
+		}
+		filter.ErrorCode = string(code)
+	}
+
+	if status := c.QueryParam("status"); status != "" {
+		requestStatus, ok := parseRequestStatus(status)
+		if !ok {
+			return c.JSON(http.StatusBadRequest, fmt.Sprintf("unknown status %s", status))
+		}
+		filter.RequestStatus = &requestStatus
+	}
+
+	if status := c.QueryParam("job_status"); status != "" {
+		jobStatus, ok := parseJobStatus(status)
+		if !ok {
+			return c.JSON(http.StatusBadRequest, fmt.Sprintf("unknown job_status %s", status))
+		}
+		filter.JobStatus = &jobStatus
+	}
+
+	if since, err := strconv.Atoi(c.QueryParam("since")); err == nil && since > 0 {
+		filter.Since = time.Now().Add(-time.Duration(since) * time.Hour)
+	}
+
+	if limit, err := strconv.Atoi(c.QueryParam("limit")); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	if offset, err := strconv.Atoi(c.QueryParam("offset")); err == nil && offset > 0 {
+		filter.Offset = offset
+	}
+
+	idx, err := s.loadMetadata()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	if tag := strings.ToLower(c.QueryParam("tag")); tag != "" {
+		filter.TaggedConsumers, filter.TaggedPairs = idx.tagged(tag)
+		if len(filter.TaggedConsumers) == 0 && len(filter.TaggedPairs) == 0 {
+			return c.JSON(http.StatusOK, []go_ooo_types.JobSummary{})
+		}
+	}
+
+	jobs, err := s.db.SearchJobs(filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]go_ooo_types.JobSummary, 0, len(jobs))
+	for _, j := range jobs {
+		res = append(res, go_ooo_types.JobSummary{
+			RequestId:           j.GetRequestId(),
+			Consumer:            j.GetConsumer(),
+			Endpoint:            j.GetEndpointDecoded(),
+			RequestBlockNumber:  j.GetRequestBlockNumber(),
+			RequestStatus:       j.GetRequestStatusString(),
+			JobStatus:           j.GetJobStatusString(),
+			FulfillmentAttempts: j.GetFulfillmentAttempts(),
+			StatusReason:        j.GetStatusReason(),
+			ErrorCode:           j.GetErrorCode(),
+			UpdatedAt:           j.UpdatedAt.Unix(),
+			ConsumerTags:        idx.consumerTags(j.GetConsumer()),
+			PairTags:            idx.pairTags(reportPair(j)),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// parseRequestStatus accepts request status names as returned by the API, e.g. "TX FAILED", "tx_failed"
+func parseRequestStatus(name string) (int, bool) {
+	name = normaliseStatusName(name)
+	for status := models.REQUEST_STATUS_UNKNOWN; status <= models.REQUEST_STATUS_SKIPPED_CATCHUP; status++ {
+		req := models.DataRequests{RequestStatus: status}
+		if normaliseStatusName(req.GetRequestStatusString()) == name {
+			return status, true
+		}
+	}
+	return 0, false
+}
+
+func parseJobStatus(name string) (int, bool) {
+	name = normaliseStatusName(name)
+	for status := models.JOB_STATUS_UNKNOWN; status <= models.JOB_STATUS_FAIL; status++ {
+		req := models.DataRequests{JobStatus: status}
+		if normaliseStatusName(req.GetJobStatusString()) == name {
+			return status, true
+		}
+	}
+	return 0, false
+}
+
+func normaliseStatusName(name string) string {
+	return strings.ToUpper(strings.NewReplacer("_", " ", "-", " ").Replace(strings.TrimSpace(name)))
+}
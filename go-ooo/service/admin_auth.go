@@ -0,0 +1,182 @@
+package service
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// admin API token scopes
+const (
+	AdminScopeRead     = "read"     // GET requests only
+	AdminScopeOperator = "operator" // all requests, e.g. pause, requeue and withdraw
+)
+
+// adminCredentialKey - the echo context key holding the request's adminCredential
+const adminCredentialKey = "admin_credential"
+
+// AdminToken is an admin_api.tokens entry
+type AdminToken struct {
+	Key   string `mapstructure:"key" json:"key"`
+	Scope string `mapstructure:"scope" json:"scope"`
+}
+
+// adminCredential is what an admin API request was authenticated with
+type adminCredential struct {
+	name  string
+	scope string
+}
+
+// AdminTokens returns the configured admin API tokens, by name
+func AdminTokens() (map[string]AdminToken, error) {
+	tokens := make(map[string]AdminToken)
+	var err error
+	if raw, ok := viper.Get(config.AdminApiTokens).(string); ok {
+		// set by an environment variable, as a JSON object
+		err = json.Unmarshal([]byte(raw), &tokens)
+	} else {
+		err = viper.UnmarshalKey(config.AdminApiTokens, &tokens)
+	}
+	return tokens, err
+}
+
+// adminAuth authenticates admin API requests with the keystore password, an admin_api.tokens
+// key or a JWT, then checks the credential's scope allows the request
+func (s *Service) adminAuth() []echo.MiddlewareFunc {
+	keyAuth := middleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
+		cred, ok := s.authenticateAdmin(key)
+		if ok {
+			c.Set(adminCredentialKey, cred)
+		}
+		return ok, nil
+	})
+
+	scope := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cred, _ := c.Get(adminCredentialKey).(adminCredential)
+			method := c.Request().Method
+			if cred.scope != AdminScopeOperator && method != http.MethodGet && method != http.MethodHead {
+				return c.JSON(http.StatusForbidden, fmt.Sprintf("%s has the %s scope - %s needs the %s scope",
+					cred.name, cred.scope, c.Path(), AdminScopeOperator))
+			}
+			return next(c)
+		}
+	}
+
+	return []echo.MiddlewareFunc{keyAuth, scope}
+}
+
+// loopbackOnly rejects requests which don't come from the node's own host
+func loopbackOnly(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		host, _, err := net.SplitHostPort(c.Request().RemoteAddr)
+		if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+			return c.JSON(http.StatusForbidden, "the admin API requires a client certificate - send remote requests to it")
+		}
+		return next(c)
+	}
+}
+
+func (s *Service) authenticateAdmin(key string) (adminCredential, bool) {
+	if subtle.ConstantTimeCompare([]byte(key), []byte(s.authToken)) == 1 {
+		return adminCredential{name: "keystore password", scope: AdminScopeOperator}, true
+	}
+
+	tokens, _ := AdminTokens()
+	for name, t := range tokens {
+		if t.Key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(t.Key)) == 1 {
+			return adminCredential{name: "token " + name, scope: strings.ToLower(t.Scope)}, true
+		}
+	}
+
+	secret := viper.GetString(config.AdminApiJwtSecret)
+	if secret == "" || strings.Count(key, ".") != 2 {
+		return adminCredential{}, false
+	}
+
+	cred, err := parseAdminJwt(key, secret)
+	return cred, err == nil
+}
+
+// parseAdminJwt verifies an HS256 signed JWT, returning its subject and scope
+func parseAdminJwt(token string, secret string) (adminCredential, error) {
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return adminCredential{}, err
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok || !parsed.Valid {
+		return adminCredential{}, errors.New("invalid token")
+	}
+	// tokens must expire
+	if _, ok := claims["exp"]; !ok {
+		return adminCredential{}, errors.New("token has no exp claim")
+	}
+
+	scope, _ := claims["scope"].(string)
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		sub = "unknown"
+	}
+
+	return adminCredential{name: "jwt " + sub, scope: strings.ToLower(scope)}, nil
+}
+
+// adminTlsConfig returns the admin API's TLS config, or nil if it is served over plain HTTP
+func adminTlsConfig() (*tls.Config, error) {
+	certFile := viper.GetString(config.AdminApiTlsCertFile)
+	keyFile := viper.GetString(config.AdminApiTlsKeyFile)
+	caFile := viper.GetString(config.AdminApiTlsClientCaFile)
+
+	if certFile == "" && keyFile == "" {
+		if caFile != "" {
+			return nil, fmt.Errorf("%s needs %s and %s", config.AdminApiTlsClientCaFile, config.AdminApiTlsCertFile,
+				config.AdminApiTlsKeyFile)
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load %s and %s: %s", config.AdminApiTlsCertFile, config.AdminApiTlsKeyFile,
+			err.Error())
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s: %s", config.AdminApiTlsClientCaFile, err.Error())
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s has no PEM certificates", config.AdminApiTlsClientCaFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
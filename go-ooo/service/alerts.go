@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/spf13/viper"
+	"go-ooo/alerts"
+	"go-ooo/config"
+	"math/big"
+	"time"
+)
+
+// checkAlerts checks for conditions which need an operator's attention, and sends an
+// alert for each. Failed fulfillments are alerted as they happen
+func (s *Service) checkAlerts() {
+	alerter := s.oooRouterService.Alerter()
+	if !alerter.Enabled() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
+	defer cancel()
+
+	if _, err := s.checkRpc(ctx); err != nil {
+		alerter.Alert(alerts.AlertRpcDown, "", fmt.Sprintf("eth node unreachable: %s", err.Error()))
+		// the remaining checks need the eth node
+		return
+	}
+	alerter.Resolve(alerts.AlertRpcDown, "")
+
+	if balance, err := s.checkWalletBalance(ctx); err != nil {
+		if balance != "" {
+			alerter.Alert(alerts.AlertLowBalance, "", err.Error())
+		}
+	} else {
+		alerter.Resolve(alerts.AlertLowBalance, "")
+	}
+
+	s.checkGasBudget(alerter)
+	s.checkCredentials(alerter)
+	s.checkDelistings(alerter)
+	s.oooRouterService.CheckPairHeartbeats()
+}
+
+// checkSubgraphs checks the health of the DEX subgraphs, which is reported by the pairs
+// status, and alerts for any which are unhealthy
+func (s *Service) checkSubgraphs() {
+	results := s.oooApi.CheckSubgraphHealth()
+
+	alerter := s.oooRouterService.Alerter()
+	if !s.isLeader() || !alerter.Enabled() {
+		return
+	}
+
+	for name, err := range results {
+		if err != nil {
+			alerter.Alert(alerts.AlertSubgraphUnhealthy, name, fmt.Sprintf("%s: %s", name, err.Error()))
+		} else {
+			alerter.Resolve(alerts.AlertSubgraphUnhealthy, name)
+		}
+	}
+}
+
+// checkCredentials alerts for adapter credentials which are due to be rotated, or have used
+// their daily quota
+func (s *Service) checkCredentials(alerter *alerts.Alerter) {
+	for _, c := range s.credentials.List() {
+		if c.RotationDue {
+			alerter.Alert(alerts.AlertCredentialRotation, c.Name, fmt.Sprintf("adapter credential %s was last rotated %s, rotate every %d days",
+				c.Name, time.Unix(c.RotatedAt, 0).UTC().Format(time.RFC3339), c.RotateAfterDays))
+		} else {
+			alerter.Resolve(alerts.AlertCredentialRotation, c.Name)
+		}
+
+		if c.DailyQuota > 0 && c.UsedToday >= c.DailyQuota {
+			alerter.Alert(alerts.AlertCredentialQuota, c.Name, fmt.Sprintf("adapter credential %s has used its daily quota of %d - adapters using it fail until 00:00 UTC",
+				c.Name, c.DailyQuota))
+		} else {
+			alerter.Resolve(alerts.AlertCredentialQuota, c.Name)
+		}
+	}
+}
+
+// checkGasBudget alerts if more than the configured gas budget has been spent on fulfillments
+// in the last 24 hours
+func (s *Service) checkGasBudget(alerter *alerts.Alerter) {
+	budgetEth := viper.GetFloat64(config.AlertsGasBudget)
+	if budgetEth <= 0 {
+		return
+	}
+
+	jobs, err := s.db.GetFulfilledRequestsSince(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		return
+	}
+
+	spent := big.NewInt(0)
+	for _, j := range jobs {
+		cost := new(big.Int).Mul(new(big.Int).SetUint64(j.GetFulfillGasUsed()), new(big.Int).SetUint64(j.GetFulfillGasPrice()))
+		spent.Add(spent, cost)
+	}
+
+	budget, _ := new(big.Float).Mul(big.NewFloat(budgetEth), big.NewFloat(params.Ether)).Int(nil)
+
+	if spent.Cmp(budget) > 0 {
+		spentEth := new(big.Float).Quo(new(big.Float).SetInt(spent), big.NewFloat(params.Ether))
+		alerter.Alert(alerts.AlertGasBudgetExceeded, "", fmt.Sprintf("%s ETH spent on fulfillment gas in the last 24 hours, budget %v ETH",
+			spentEth.Text('f', 6), budgetEth))
+	} else {
+		alerter.Resolve(alerts.AlertGasBudgetExceeded, "")
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"go-ooo/database/models"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// answerIdPattern matches a request id or tx hash, both 32 bytes of hex, with or without 0x
+var answerIdPattern = regexp.MustCompile(`^(0x)?[0-9a-fA-F]{64}$`)
+
+// GetAnswer returns the stored record of a request and its answer, given the request id, or the
+// hash of the request tx or of any of its fulfillment txs
+func (s *Service) GetAnswer(c echo.Context) error {
+	record, status, err := s.answerRecord(c.Param("id"))
+	if err != nil {
+		return c.JSON(status, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, record)
+}
+
+// GetPublicAnswer is GetAnswer for the public price API, so that consumer developers can look up
+// their own requests. Status reasons are operator diagnostics, and are not published
+func (s *Service) GetPublicAnswer(c echo.Context) error {
+	record, status, err := s.answerRecord(c.Param("id"))
+	if err != nil {
+		return c.JSON(status, err.Error())
+	}
+
+	record.StatusReason = ""
+	return c.JSON(http.StatusOK, record)
+}
+
+// answerRecord looks id up as a request id, then as a tx hash, and returns everything stored about
+// the request, or the http status to return with the error
+func (s *Service) answerRecord(id string) (go_ooo_types.AnswerRecord, int, error) {
+	if !answerIdPattern.MatchString(id) {
+		return go_ooo_types.AnswerRecord{}, http.StatusBadRequest, fmt.Errorf("%q is not a request id or tx hash", id)
+	}
+
+	// request ids are stored without 0x, and tx hashes with it
+	hash := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(id, "0x"), "0X"))
+	req, err := s.db.FindByRequestId(hash)
+	if err != nil {
+		req, err = s.db.FindByTxHash("0x" + hash)
+	}
+	if err != nil {
+		return go_ooo_types.AnswerRecord{}, http.StatusNotFound, fmt.Errorf("no request or tx %s found", id)
+	}
+
+	// a request may have no attestation, sources or mined txs yet
+	att, _ := s.db.GetAttestationByRequestId(req.GetRequestId())
+	sources, _ := s.requestSources(req.GetRequestId())
+	spends, _ := s.db.GetGasSpendsForRequest(req.GetRequestId())
+
+	return go_ooo_types.AnswerRecord{
+		RequestId:      req.GetRequestId(),
+		Consumer:       req.GetConsumer(),
+		Provider:       req.GetProvider(),
+		Endpoint:       req.GetEndpointDecoded(),
+		IsAdhoc:        req.GetIsAdHoc(),
+		RequestStatus:  req.GetRequestStatusString(),
+		StatusReason:   req.GetStatusReason(),
+		RejectionCode:  req.GetRejectionCode(),
+		ErrorCode:      req.GetErrorCode(),
+		Value:          req.GetPriceResult(),
+		AnswerRounding: req.GetAnswerRounding(),
+		SourcePolicy:   req.GetSourcePolicy(),
+		SourceTier:     req.GetSourceTier(),
+		Sources:        sources,
+		AttestationCid: att.GetIpfsCid(),
+		Timings:        answerTimings(req),
+		Gas:            answerGas(req, spends),
+	}, http.StatusOK, nil
+}
+
+func answerTimings(req models.DataRequests) go_ooo_types.AnswerTimings {
+	t := go_ooo_types.AnswerTimings{
+		RequestBlock:  req.GetRequestBlockNumber(),
+		FetchBlock:    req.GetLastDataFetchBlockNumber(),
+		SentBlock:     req.GetLastFulfillSentBlockNumber(),
+		FulfillBlock:  req.GetFulfillBlockNumber(),
+		RequestSeenAt: req.CreatedAt.UnixNano() / 1e6,
+		DataFetchedAt: req.GetDataFetchedAt(),
+		TxSentAt:      req.GetTxSentAt(),
+		TxMinedAt:     req.GetTxMinedAt(),
+	}
+	if t.TxMinedAt > 0 {
+		t.TotalMs = t.TxMinedAt - t.RequestSeenAt
+	}
+	return t
+}
+
+func answerGas(req models.DataRequests, spends []models.GasSpends) go_ooo_types.AnswerGas {
+	g := go_ooo_types.AnswerGas{
+		Fee:                 req.GetFee(),
+		RequestTxHash:       req.GetRequestTxHash(),
+		RequestGasUsed:      req.GetRequestGasUsed(),
+		RequestGasPrice:     req.GetRequestGasPrice(),
+		FulfillTxHash:       req.GetFulfillTxHash(),
+		FulfillGasUsed:      req.GetFulfillGasUsed(),
+		FulfillGasPrice:     req.GetFulfillGasPrice(),
+		FulfillmentAttempts: req.GetFulfillmentAttempts(),
+	}
+	for _, sp := range spends {
+		g.FulfillmentTxs = append(g.FulfillmentTxs, go_ooo_types.AnswerTx{
+			TxHash:      sp.GetTxHash(),
+			BlockNumber: sp.GetBlockNumber(),
+			BlockTime:   sp.GetBlockTime(),
+			GasUsed:     sp.GetGasUsed(),
+			GasPrice:    sp.GetGasPrice(),
+			BaseFee:     sp.GetBaseFee(),
+			PriorityFee: sp.GetPriorityFee(),
+			Success:     sp.GetSuccess(),
+		})
+	}
+	return g
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"go-ooo/database"
+	"go-ooo/database/models"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// mutatingAdminTasks admin tasks which change node or contract state, and so are audited
+var mutatingAdminTasks = map[string]bool{
+	"register":         true,
+	"set_fee":          true,
+	"set_granular_fee": true,
+	"withdraw":         true,
+	"pause":            true,
+	"resume":           true,
+	"vor_register":     true,
+	"xfund_approve":    true,
+}
+
+// audit records a mutating admin action in the audit log. actionErr is the action's outcome.
+// Failing to record the action is logged, but does not fail the action
+func (s *Service) audit(c echo.Context, action string, requestId string, params interface{}, actionErr error) {
+	source := models.AUDIT_SOURCE_CLI
+	if c.Echo() == s.adminEcho {
+		source = models.AUDIT_SOURCE_ADMIN_API
+	}
+
+	actor := auditActor(c)
+
+	paramsJson := ""
+	if params != nil {
+		b, err := json.Marshal(params)
+		if err == nil {
+			paramsJson = string(b)
+		}
+	}
+
+	errMsg := ""
+	if actionErr != nil {
+		errMsg = actionErr.Error()
+	}
+
+	err := s.db.InsertAuditLog(actor, source, c.RealIP(), action, requestId, paramsJson, actionErr == nil, errMsg)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"package":    "service",
+			"function":   "audit",
+			"action":     action,
+			"actor":      actor,
+			"request_id": requestId,
+		}).Error(err.Error())
+	}
+}
+
+// auditActor returns who sent the request, for the audit log
+func auditActor(c echo.Context) string {
+	actor := c.Request().Header.Get(go_ooo_types.AuditActorHeader)
+	// admin API requests also record the credential they were authorised with, as the header is
+	// set by the client
+	if cred, ok := c.Get(adminCredentialKey).(adminCredential); ok {
+		if actor == "" {
+			actor = cred.name
+		} else {
+			actor = fmt.Sprintf("%s (%s)", actor, cred.name)
+		}
+	}
+	if actor == "" {
+		actor = "unknown"
+	}
+	return actor
+}
+
+// auditAdminTask records an admin task in the audit log, if it is one which changes state
+func (s *Service) auditAdminTask(c echo.Context, tr go_ooo_types.AdminTaskResponse) {
+	if !mutatingAdminTasks[tr.Task] {
+		return
+	}
+
+	var err error
+	if !tr.Success {
+		err = errors.New(tr.Error)
+	}
+
+	s.audit(c, tr.Task, "", tr.AdminTask, err)
+}
+
+// GetAuditLog returns audit log entries, most recent first, optionally filtered by actor, action,
+// request id and the number of hours since the action
+func (s *Service) GetAuditLog(c echo.Context) error {
+	filter := database.AuditFilter{
+		Actor:     c.QueryParam("actor"),
+		Action:    c.QueryParam("action"),
+		RequestId: c.QueryParam("request_id"),
+		Limit:     100,
+	}
+
+	if since, err := strconv.Atoi(c.QueryParam("since")); err == nil && since > 0 {
+		filter.Since = time.Now().Add(-time.Duration(since) * time.Hour)
+	}
+
+	if limit, err := strconv.Atoi(c.QueryParam("limit")); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	if offset, err := strconv.Atoi(c.QueryParam("offset")); err == nil && offset > 0 {
+		filter.Offset = offset
+	}
+
+	entries, err := s.db.SearchAuditLog(filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]go_ooo_types.AuditLogEntry, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, go_ooo_types.AuditLogEntry{
+			Id:         e.GetId(),
+			Actor:      e.GetActor(),
+			Source:     e.GetSource(),
+			RemoteAddr: e.GetRemoteAddr(),
+			Action:     e.GetAction(),
+			RequestId:  e.GetRequestId(),
+			Params:     e.GetParams(),
+			Success:    e.GetSuccess(),
+			Error:      e.GetError(),
+			CreatedAt:  e.CreatedAt.Unix(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+// defaultChainEventsLimit - chain events returned, if the limit query param is not set
+const defaultChainEventsLimit = 50
+
+// GetChainEvents returns the most recent events recorded for the chain.event_filters, up to the
+// limit query param, optionally only those of the filter given by the name query param
+func (s *Service) GetChainEvents(c echo.Context) error {
+	limit := defaultChainEventsLimit
+	if l := c.QueryParam("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, "limit must be a positive integer")
+		}
+		limit = n
+	}
+
+	events, err := s.oooRouterService.GetChainEvents(c.QueryParam("name"), limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, events)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/redact"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// config setting sources, in the order they take precedence
+const (
+	ConfigSourceEnv     = "env"
+	ConfigSourceProfile = "profile"
+	ConfigSourceFile    = "file"
+	ConfigSourceDefault = "default"
+)
+
+// EffectiveConfig returns the running value of each config key, after profile and environment
+// variable overrides and defaults, with secrets redacted. If prefix is set, only keys starting
+// with it are returned
+func EffectiveConfig(prefix string) go_ooo_types.EffectiveConfig {
+	prefix = strings.ToLower(prefix)
+	profile := viper.GetString(config.Profile)
+
+	res := go_ooo_types.EffectiveConfig{
+		ConfigFile: viper.ConfigFileUsed(),
+		Profile:    profile,
+		Settings:   []go_ooo_types.ConfigSetting{},
+	}
+
+	// viper only reports whether top level keys are in the config, so it is read again to find
+	// the nested keys it sets
+	fileConfig := viper.New()
+	if viper.ConfigFileUsed() != "" {
+		fileConfig.SetConfigFile(viper.ConfigFileUsed())
+		_ = fileConfig.ReadInConfig()
+	}
+
+	keys := viper.AllKeys()
+	sort.Strings(keys)
+	for _, k := range keys {
+		// the selected profile's values are reported under the keys they set
+		if strings.HasPrefix(k, config.Profiles+".") || !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		res.Settings = append(res.Settings, go_ooo_types.ConfigSetting{
+			Key:    k,
+			Value:  redactConfigValue(k, viper.Get(k)),
+			Source: configSource(fileConfig, k, profile),
+		})
+	}
+
+	return res
+}
+
+// configSource returns where key's running value was set. fileConfig holds just the config file
+func configSource(fileConfig *viper.Viper, key string, profile string) string {
+	if _, ok := os.LookupEnv(config.EnvVar(key)); ok {
+		return ConfigSourceEnv
+	}
+	if profile != "" && fileConfig.IsSet(config.ProfileKey(profile, key)) {
+		return ConfigSourceProfile
+	}
+	if fileConfig.IsSet(key) {
+		return ConfigSourceFile
+	}
+	return ConfigSourceDefault
+}
+
+// redactConfigValue masks the value of keys holding secrets, and secrets in other values, e.g. an
+// API key in an RPC url. Tables are redacted by entry
+func redactConfigValue(key string, value interface{}) interface{} {
+	if redact.IsSecretKey(key) {
+		if value == nil || value == "" {
+			return value
+		}
+		return redact.Mask
+	}
+
+	switch v := value.(type) {
+	case string:
+		return redact.String(v)
+	case []string:
+		res := make([]string, len(v))
+		for i, s := range v {
+			res[i] = redact.String(s)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, e := range v {
+			res[i] = redactConfigValue(key, e)
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			res[k] = redactConfigValue(k, e)
+		}
+		return res
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			ks := fmt.Sprint(k)
+			res[ks] = redactConfigValue(ks, e)
+		}
+		return res
+	}
+	return value
+}
+
+// GetConfig returns the running config, with secrets redacted, so that operators can check the
+// settings the node is using. The prefix query parameter, e.g. jobs., limits the keys returned
+func (s *Service) GetConfig(c echo.Context) error {
+	return c.JSON(http.StatusOK, EffectiveConfig(c.QueryParam("prefix")))
+}
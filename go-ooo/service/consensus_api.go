@@ -0,0 +1,71 @@
+package service
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/consensus"
+	"net/http"
+	"time"
+)
+
+// initConsensusApi serves the node's signed observations to its consensus peers, authenticated by
+// consensus.token
+func (s *Service) initConsensusApi() {
+	port := viper.GetInt(config.ConsensusPort)
+	if port == 0 {
+		return
+	}
+
+	listen := fmt.Sprintf("%s:%d", viper.GetString(config.ConsensusHost), port)
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "initConsensusApi",
+		"listen":   listen,
+	}).Info("initialise consensus api")
+
+	token := viper.GetString(config.ConsensusToken)
+
+	s.consensusEcho.HideBanner = true
+	s.consensusEcho.Server.ReadTimeout = 10 * time.Second
+	s.consensusEcho.Server.WriteTimeout = 30 * time.Second
+	s.consensusEcho.Use(middleware.Recover())
+	s.consensusEcho.Use(middleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
+		return token != "" && subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1, nil
+	}))
+
+	s.consensusEcho.GET(consensus.ObservationsPath, s.GetObservation)
+
+	err := s.consensusEcho.Start(listen)
+	if err != nil && err != http.ErrServerClosed {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "initConsensusApi",
+		}).Error(err.Error())
+	}
+}
+
+// GetObservation returns the node's signed observation of the endpoint query param
+func (s *Service) GetObservation(c echo.Context) error {
+	endpoint := c.QueryParam("endpoint")
+	if endpoint == "" {
+		return c.JSON(http.StatusBadRequest, "endpoint is required")
+	}
+
+	ob, err := s.oooRouterService.Consensus().Observation(c.Request().Context(), endpoint)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "GetObservation",
+			"endpoint": endpoint,
+		}).Warn(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, ob)
+}
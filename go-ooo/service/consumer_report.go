@@ -0,0 +1,255 @@
+package service
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/labstack/echo/v4"
+	"go-ooo/database/models"
+	go_ooo_types "go-ooo/types"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GetConsumersUsage summarises each consumer's requests received between the from and to unix
+// timestamps - volume, fulfillment rate, and the fees paid. Consumers paying the most are first.
+// If the tag param is given, only consumers with the tag are included
+func (s *Service) GetConsumersUsage(c echo.Context) error {
+	from, to, ok := reportPeriod(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, "from must be before to")
+	}
+
+	jobs, err := s.db.GetRequestsReceivedBetween(from, to)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	idx, err := s.loadMetadata()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	tag := strings.ToLower(c.QueryParam("tag"))
+
+	consumers, totalFees := usageByConsumer(jobs)
+
+	report := go_ooo_types.ConsumerUsageReport{
+		From:      from.Unix(),
+		To:        to.Unix(),
+		Consumers: make([]go_ooo_types.ConsumerUsage, 0, len(consumers)),
+	}
+	for consumer, acc := range consumers {
+		if tag != "" && !inTags(tag, idx.consumerTags(consumer)) {
+			continue
+		}
+		report.Consumers = append(report.Consumers, acc.usage(consumer, totalFees, idx))
+	}
+
+	sort.Slice(report.Consumers, func(i, j int) bool {
+		a, b := report.Consumers[i], report.Consumers[j]
+		if a.FeesXfund != b.FeesXfund {
+			return a.FeesXfund > b.FeesXfund
+		}
+		if a.NumRequests != b.NumRequests {
+			return a.NumRequests > b.NumRequests
+		}
+		return a.Consumer < b.Consumer
+	})
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// GetConsumerUsage summarises a consumer's requests received between the from and to unix
+// timestamps, with the pairs it requested and its daily request volume
+func (s *Service) GetConsumerUsage(c echo.Context) error {
+	if !common.IsHexAddress(c.Param("consumer")) {
+		return c.JSON(http.StatusBadRequest, "consumer must be an address")
+	}
+	consumer := common.HexToAddress(c.Param("consumer")).Hex()
+
+	from, to, ok := reportPeriod(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, "from must be before to")
+	}
+
+	jobs, err := s.db.GetRequestsReceivedBetween(from, to)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	consumers, totalFees := usageByConsumer(jobs)
+	acc, ok := consumers[consumer]
+	if !ok {
+		return c.JSON(http.StatusNotFound, "no requests from consumer in period")
+	}
+
+	idx, err := s.loadMetadata()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, go_ooo_types.ConsumerUsageDetail{
+		From:          from.Unix(),
+		To:            to.Unix(),
+		ConsumerUsage: acc.usage(consumer, totalFees, idx),
+		Pairs:         acc.pairRows(),
+		Daily:         acc.dailyRows(),
+	})
+}
+
+type usageCount struct {
+	requests  uint64
+	fulfilled uint64
+	fees      *big.Int
+}
+
+func (u *usageCount) add(job models.DataRequests) {
+	u.requests++
+	if job.GetRequestStatus() == models.REQUEST_STATUS_SUCCESS {
+		u.fulfilled++
+		u.fees.Add(u.fees, new(big.Int).SetUint64(job.GetFee()))
+	}
+}
+
+func (u *usageCount) row(key string) go_ooo_types.ConsumerUsageRow {
+	return go_ooo_types.ConsumerUsageRow{
+		Key:          key,
+		NumRequests:  u.requests,
+		NumFulfilled: u.fulfilled,
+		FeesXfund:    xfundFloat(u.fees),
+	}
+}
+
+func newUsageCount() *usageCount {
+	return &usageCount{fees: big.NewInt(0)}
+}
+
+type usageAcc struct {
+	usageCount
+	failed uint64
+	first  time.Time
+	last   time.Time
+	pairs  map[string]*usageCount
+	daily  map[string]*usageCount
+}
+
+func (a *usageAcc) add(job models.DataRequests) {
+	a.usageCount.add(job)
+	if models.IsFailedFinalRequestStatus(job.GetRequestStatus()) {
+		a.failed++
+	}
+
+	if a.first.IsZero() || job.CreatedAt.Before(a.first) {
+		a.first = job.CreatedAt
+	}
+	if job.CreatedAt.After(a.last) {
+		a.last = job.CreatedAt
+	}
+
+	pair := reportPair(job)
+	if _, ok := a.pairs[pair]; !ok {
+		a.pairs[pair] = newUsageCount()
+	}
+	a.pairs[pair].add(job)
+
+	day := job.CreatedAt.UTC().Format("2006-01-02")
+	if _, ok := a.daily[day]; !ok {
+		a.daily[day] = newUsageCount()
+	}
+	a.daily[day].add(job)
+}
+
+func (a *usageAcc) usage(consumer string, totalFees *big.Int, idx metadataIndex) go_ooo_types.ConsumerUsage {
+	usage := go_ooo_types.ConsumerUsage{
+		Consumer:       consumer,
+		NumRequests:    a.requests,
+		NumFulfilled:   a.fulfilled,
+		NumFailed:      a.failed,
+		NumPending:     a.requests - a.fulfilled - a.failed,
+		FeesXfund:      xfundFloat(a.fees),
+		FirstRequestAt: a.first.Unix(),
+		LastRequestAt:  a.last.Unix(),
+		Tags:           idx.consumerTags(consumer),
+	}
+	if m, ok := idx.consumers[consumer]; ok {
+		usage.Note = m.GetNote()
+	}
+
+	if finished := a.fulfilled + a.failed; finished > 0 {
+		usage.FulfillmentRate = float64(a.fulfilled) / float64(finished)
+	}
+	if a.fulfilled > 0 {
+		usage.AvgFeeXfund = usage.FeesXfund / float64(a.fulfilled)
+	}
+	if totalFees.Sign() > 0 {
+		usage.RevenueShare, _ = new(big.Float).Quo(new(big.Float).SetInt(a.fees), new(big.Float).SetInt(totalFees)).Float64()
+	}
+
+	return usage
+}
+
+// pairRows returns a row for each pair requested, most requested first
+func (a *usageAcc) pairRows() []go_ooo_types.ConsumerUsageRow {
+	rows := make([]go_ooo_types.ConsumerUsageRow, 0, len(a.pairs))
+	for pair, u := range a.pairs {
+		rows = append(rows, u.row(pair))
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].NumRequests != rows[j].NumRequests {
+			return rows[i].NumRequests > rows[j].NumRequests
+		}
+		return rows[i].Key < rows[j].Key
+	})
+
+	return rows
+}
+
+// dailyRows returns a row for each day requests were received, oldest first
+func (a *usageAcc) dailyRows() []go_ooo_types.ConsumerUsageRow {
+	rows := make([]go_ooo_types.ConsumerUsageRow, 0, len(a.daily))
+	for day, u := range a.daily {
+		rows = append(rows, u.row(day))
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Key < rows[j].Key
+	})
+
+	return rows
+}
+
+func newUsageAcc() *usageAcc {
+	return &usageAcc{
+		usageCount: *newUsageCount(),
+		pairs:      make(map[string]*usageCount),
+		daily:      make(map[string]*usageCount),
+	}
+}
+
+// usageByConsumer groups jobs by consumer, returning the fees paid by all consumers
+func usageByConsumer(jobs []models.DataRequests) (map[string]*usageAcc, *big.Int) {
+	consumers := make(map[string]*usageAcc)
+	total := big.NewInt(0)
+
+	for _, j := range jobs {
+		if _, ok := consumers[j.GetConsumer()]; !ok {
+			consumers[j.GetConsumer()] = newUsageAcc()
+		}
+		consumers[j.GetConsumer()].add(j)
+
+		if j.GetRequestStatus() == models.REQUEST_STATUS_SUCCESS {
+			total.Add(total, new(big.Int).SetUint64(j.GetFee()))
+		}
+	}
+
+	return consumers, total
+}
+
+// xfundFloat converts an amount of xFUND in its smallest unit to xFUND. xFUND has 9 decimals
+func xfundFloat(amount *big.Int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), big.NewFloat(params.GWei)).Float64()
+	return f
+}
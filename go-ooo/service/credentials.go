@@ -0,0 +1,73 @@
+package service
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"go-ooo/credentials"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+	"strings"
+)
+
+// credentialAudit is recorded in the audit log for changes to adapter credentials. The value is
+// never recorded, only whether one was set
+type credentialAudit struct {
+	Name            string `json:"name"`
+	Ref             string `json:"ref,omitempty"`
+	ValueSet        bool   `json:"value_set,omitempty"`
+	RotateAfterDays uint64 `json:"rotate_after_days,omitempty"`
+	DailyQuota      uint64 `json:"daily_quota,omitempty"`
+	Version         uint64 `json:"version,omitempty"`
+}
+
+// GetCredentials lists the adapter credentials, with their rotation and usage, but not their values
+func (s *Service) GetCredentials(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.credentials.List())
+}
+
+// SetCredential sets the value or external reference of the adapter credential name param,
+// rotating it if either has changed
+func (s *Service) SetCredential(c echo.Context) error {
+	name := strings.ToLower(c.Param("name"))
+	if err := credentials.ValidateName(name); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	var request go_ooo_types.AdapterCredentialRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	request.Value = strings.TrimSpace(request.Value)
+	request.Ref = strings.TrimSpace(request.Ref)
+
+	res, err := s.credentials.Set(name, request)
+	s.audit(c, "set_credential", "", credentialAudit{
+		Name:            name,
+		Ref:             request.Ref,
+		ValueSet:        request.Value != "",
+		RotateAfterDays: request.RotateAfterDays,
+		DailyQuota:      request.DailyQuota,
+		Version:         res.Version,
+	}, err)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// DeleteCredential deletes the adapter credential name param
+func (s *Service) DeleteCredential(c echo.Context) error {
+	name := strings.ToLower(c.Param("name"))
+	if err := credentials.ValidateName(name); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	err := s.credentials.Delete(name)
+	s.audit(c, "delete_credential", "", credentialAudit{Name: name}, err)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, "credential deleted: "+name)
+}
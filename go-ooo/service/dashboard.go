@@ -0,0 +1,23 @@
+package service
+
+import (
+	"embed"
+	"github.com/labstack/echo/v4"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// initDashboard serves the read-only status dashboard on the admin API. The static files are
+// served without auth - the dashboard asks for the admin token, and uses it to call the admin API
+func (s *Service) initDashboard() {
+	files, _ := fs.Sub(dashboardFiles, "dashboard")
+	fileServer := http.StripPrefix("/dashboard/", http.FileServer(http.FS(files)))
+
+	s.adminEcho.GET("/dashboard", func(c echo.Context) error {
+		return c.Redirect(http.StatusMovedPermanently, "/dashboard/")
+	})
+	s.adminEcho.GET("/dashboard/*", echo.WrapHandler(fileServer))
+}
@@ -0,0 +1,262 @@
+// Read-only status dashboard. All data comes from the admin API, authenticated with the
+// admin token, which is kept in session storage only.
+(function () {
+  "use strict";
+
+  var REFRESH_MS = 15000;
+  var TOKEN_KEY = "go-ooo-admin-token";
+  var timer = null;
+
+  function $(id) {
+    return document.getElementById(id);
+  }
+
+  function api(path) {
+    return fetch("/api/v1" + path, {
+      headers: { "Authorization": "Bearer " + sessionStorage.getItem(TOKEN_KEY) }
+    }).then(function (resp) {
+      if (resp.status === 401 || resp.status === 400) {
+        throw new Error("unauthorised");
+      }
+      if (!resp.ok) {
+        throw new Error(path + ": " + resp.status + " " + resp.statusText);
+      }
+      return resp.json();
+    });
+  }
+
+  function text(value) {
+    return document.createTextNode(value === undefined || value === null ? "" : String(value));
+  }
+
+  function cell(value, className) {
+    var td = document.createElement("td");
+    if (className) {
+      td.className = className;
+    }
+    td.appendChild(text(value));
+    return td;
+  }
+
+  function fillTable(id, rows, columns) {
+    var tbody = $(id);
+    tbody.textContent = "";
+    if (!rows || rows.length === 0) {
+      var tr = document.createElement("tr");
+      var td = cell("none");
+      td.colSpan = columns.length;
+      tr.appendChild(td);
+      tbody.appendChild(tr);
+      return;
+    }
+    rows.forEach(function (row) {
+      var tr = document.createElement("tr");
+      columns.forEach(function (col) {
+        var c = col(row);
+        tr.appendChild(cell(c[0], c[1]));
+      });
+      tbody.appendChild(tr);
+    });
+  }
+
+  function fillList(id, items) {
+    var dl = $(id);
+    dl.textContent = "";
+    items.forEach(function (item) {
+      var dt = document.createElement("dt");
+      dt.appendChild(text(item[0]));
+      var dd = document.createElement("dd");
+      dd.appendChild(text(item[1]));
+      dl.appendChild(dt);
+      dl.appendChild(dd);
+    });
+  }
+
+  function time(unix) {
+    if (!unix) {
+      return "never";
+    }
+    return new Date(unix * 1000).toISOString().replace("T", " ").replace(/\.\d+Z$/, "Z");
+  }
+
+  function short(hash) {
+    if (!hash || hash.length <= 14) {
+      return hash;
+    }
+    return hash.slice(0, 8) + "…" + hash.slice(-4);
+  }
+
+  function ether(wei) {
+    if (!wei) {
+      return "";
+    }
+    return (Number(wei) / 1e18).toFixed(6) + " ETH";
+  }
+
+  function xfund(amount) {
+    if (!amount) {
+      return "";
+    }
+    return (Number(amount) / 1e9).toFixed(4) + " xFUND";
+  }
+
+  function renderMaintenance(m) {
+    var banner = $("maintenance");
+    banner.hidden = !m;
+    if (!m) {
+      return;
+    }
+    var msg = "Maintenance mode since " + time(m.started_at) + " by " + m.started_by;
+    if (m.until) {
+      msg += ", until " + time(m.until);
+    }
+    if (m.note) {
+      msg += " - " + m.note;
+    }
+    banner.textContent = msg + ". Alerts are suppressed.";
+  }
+
+  function renderStatus(s) {
+    renderMaintenance(s.maintenance);
+    fillList("status", [
+      ["Version", s.version],
+      ["Leader", s.leader ? "yes" : "standby"],
+      ["Paused", s.paused || "no"],
+      ["Oracle", s.oracle_address],
+      ["Current block", s.current_block],
+      ["Last processed block", s.last_block],
+      ["Block lag", s.current_block - s.last_block],
+      ["Pending jobs", s.pending_jobs],
+      ["Wallet balance", ether(s.wallet_balance)],
+      ["xFUND balance", xfund(s.xfund_balance)],
+      ["Withdrawable fees", xfund(s.withdrawable_fees)],
+      ["Workers", s.workers],
+      ["VOR", s.vor_enabled ? "enabled" : "disabled"]
+    ]);
+  }
+
+  function renderEarnings(r) {
+    fillList("earnings", [
+      ["Fulfilled", r.total.num_fulfilled],
+      ["Fees", r.total.fees_xfund.toFixed(4) + " xFUND"],
+      ["Gas spent", r.total.gas_spent_eth.toFixed(6) + " ETH"]
+    ]);
+    renderChart($("earnings-chart"), r.daily || []);
+  }
+
+  // renderChart draws daily fees and gas as paired bars, each series scaled to its own maximum
+  function renderChart(el, days) {
+    var ns = "http://www.w3.org/2000/svg";
+    var svg = document.createElementNS(ns, "svg");
+    svg.setAttribute("viewBox", "0 0 300 100");
+    svg.setAttribute("preserveAspectRatio", "none");
+
+    var maxFees = Math.max.apply(null, days.map(function (d) { return d.fees_xfund; }).concat([0]));
+    var maxGas = Math.max.apply(null, days.map(function (d) { return d.gas_spent_eth; }).concat([0]));
+    var width = days.length > 0 ? 300 / days.length : 300;
+
+    days.forEach(function (d, i) {
+      [["fees", d.fees_xfund, maxFees], ["gas", d.gas_spent_eth, maxGas]].forEach(function (s, j) {
+        var h = s[2] > 0 ? (s[1] / s[2]) * 95 : 0;
+        var rect = document.createElementNS(ns, "rect");
+        rect.setAttribute("class", s[0]);
+        rect.setAttribute("x", String(i * width + j * width * 0.45));
+        rect.setAttribute("y", String(100 - h));
+        rect.setAttribute("width", String(width * 0.4));
+        rect.setAttribute("height", String(h));
+        var title = document.createElementNS(ns, "title");
+        title.appendChild(text(d.key + ": " + s[1] + (s[0] === "fees" ? " xFUND" : " ETH")));
+        rect.appendChild(title);
+        svg.appendChild(rect);
+      });
+    });
+
+    el.textContent = "";
+    el.appendChild(svg);
+  }
+
+  function renderJobs(fulfilled, failed) {
+    fillTable("fulfillments", fulfilled, [
+      function (j) { return [short(j.request_id), "mono"]; },
+      function (j) { return [j.endpoint]; },
+      function (j) { return [short(j.consumer), "mono"]; },
+      function (j) { return [j.request_status, "ok"]; },
+      function (j) { return [time(j.updated_at)]; }
+    ]);
+    fillTable("failed", failed, [
+      function (j) { return [short(j.request_id), "mono"]; },
+      function (j) { return [j.endpoint]; },
+      function (j) { return [j.request_status, "fail"]; },
+      function (j) { return [j.status_reason]; },
+      function (j) { return [time(j.updated_at)]; }
+    ]);
+  }
+
+  function renderSources(sources) {
+    fillTable("sources", sources, [
+      function (s) { return [s.name, "mono"]; },
+      function (s) { return [s.kind]; },
+      function (s) { return [s.healthy ? "yes" : "no", s.healthy ? "ok" : "fail"]; },
+      function (s) { return [time(s.last_checked)]; },
+      function (s) { return [s.last_error, "error"]; }
+    ]);
+  }
+
+  function refresh() {
+    var from = Math.floor(Date.now() / 1000) - 30 * 24 * 3600;
+
+    Promise.all([
+      api("/status"),
+      api("/report?from=" + from),
+      api("/jobs?status=success&limit=20"),
+      api("/jobs?job_status=fail&limit=20"),
+      api("/sources")
+    ]).then(function (res) {
+      renderStatus(res[0]);
+      renderEarnings(res[1]);
+      renderJobs(res[2], res[3]);
+      renderSources(res[4]);
+      $("updated").textContent = "updated " + new Date().toLocaleTimeString();
+    }).catch(function (err) {
+      if (err.message === "unauthorised") {
+        showLogin("invalid admin token");
+        return;
+      }
+      $("updated").textContent = "update failed: " + err.message;
+    });
+  }
+
+  function showLogin(error) {
+    clearInterval(timer);
+    sessionStorage.removeItem(TOKEN_KEY);
+    $("dashboard").hidden = true;
+    $("logout").hidden = true;
+    $("login").hidden = false;
+    $("login-error").textContent = error || "";
+  }
+
+  function showDashboard() {
+    $("login").hidden = true;
+    $("dashboard").hidden = false;
+    $("logout").hidden = false;
+    refresh();
+    timer = setInterval(refresh, REFRESH_MS);
+  }
+
+  $("login").addEventListener("submit", function (e) {
+    e.preventDefault();
+    sessionStorage.setItem(TOKEN_KEY, $("token").value);
+    $("token").value = "";
+    showDashboard();
+  });
+
+  $("logout").addEventListener("click", function () {
+    showLogin();
+  });
+
+  if (sessionStorage.getItem(TOKEN_KEY)) {
+    showDashboard();
+  } else {
+    showLogin();
+  }
+})();
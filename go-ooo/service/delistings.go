@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"go-ooo/alerts"
+	"go-ooo/database/models"
+	"go-ooo/ooo_api"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDelistingAlertConsumers - consumers named in a pair delisted alert. The rest are counted
+const maxDelistingAlertConsumers = 10
+
+// GetPairDelistings lists the pairs Finchains has stopped listing, newest first, with the
+// consumers which requested each in the lookback before it was delisted. The since query param,
+// a unix timestamp, defaults to jobs.delisting_lookback ago
+func (s *Service) GetPairDelistings(c echo.Context) error {
+	since := time.Now().Add(-ooo_api.DelistingLookback())
+	if c.QueryParam("since") != "" {
+		t, err := strconv.ParseInt(c.QueryParam("since"), 10, 64)
+		if err != nil || t < 0 {
+			return c.JSON(http.StatusBadRequest, "since must be a unix timestamp")
+		}
+		since = time.Unix(t, 0)
+	}
+
+	rows, err := s.db.GetPairDelistings(since)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	delistings, err := s.groupDelistings(rows)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, delistings)
+}
+
+// groupDelistings groups the consumers of delisted pairs by delisting, keeping their order, and
+// labels each consumer with its tags and note
+func (s *Service) groupDelistings(rows []models.PairDelistings) ([]go_ooo_types.PairDelisting, error) {
+	metadata, err := s.db.GetAllConsumerMetadata()
+	if err != nil {
+		return nil, err
+	}
+	byConsumer := make(map[string]models.ConsumerMetadata, len(metadata))
+	for _, m := range metadata {
+		byConsumer[strings.ToLower(m.Consumer)] = m
+	}
+
+	res := make([]go_ooo_types.PairDelisting, 0)
+	index := make(map[string]int)
+	for _, r := range rows {
+		key := fmt.Sprintf("%s:%d", r.GetPair(), r.GetDelistedAt())
+		i, ok := index[key]
+		if !ok {
+			i = len(res)
+			index[key] = i
+			res = append(res, go_ooo_types.PairDelisting{
+				Pair:       r.GetPair(),
+				DelistedAt: r.GetDelistedAt(),
+				Consumers:  []go_ooo_types.DelistedPairConsumer{},
+				Notified:   true,
+			})
+		}
+		res[i].Notified = res[i].Notified && r.GetNotified()
+		if r.GetConsumer() == "" {
+			continue
+		}
+		m := byConsumer[strings.ToLower(r.GetConsumer())]
+		res[i].Consumers = append(res[i].Consumers, go_ooo_types.DelistedPairConsumer{
+			Consumer:        r.GetConsumer(),
+			Requests:        r.GetRequests(),
+			LastRequestedAt: r.GetLastRequestedAt(),
+			Tags:            m.GetTags(),
+			Note:            m.GetNote(),
+		})
+	}
+
+	return res, nil
+}
+
+// checkDelistings alerts for each delisted pair not yet alerted, naming the consumers which
+// recently requested it, so that the operator can warn them
+func (s *Service) checkDelistings(alerter *alerts.Alerter) {
+	rows, err := s.db.GetUnnotifiedPairDelistings()
+	if err == nil && len(rows) > 0 {
+		var delistings []go_ooo_types.PairDelisting
+		delistings, err = s.groupDelistings(rows)
+		if err == nil {
+			for _, d := range delistings {
+				alerter.Alert(alerts.AlertPairDelisted, d.Pair, delistingAlertMessage(d))
+			}
+
+			ids := make([]uint, 0, len(rows))
+			for _, r := range rows {
+				ids = append(ids, r.ID)
+			}
+			err = s.db.MarkPairDelistingsNotified(ids)
+		}
+	}
+
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "checkDelistings",
+		}).Error(err.Error())
+	}
+}
+
+func delistingAlertMessage(d go_ooo_types.PairDelisting) string {
+	days := int(ooo_api.DelistingLookback().Hours() / 24)
+	if len(d.Consumers) == 0 {
+		return fmt.Sprintf("%s is no longer listed by Finchains - not requested in the last %d days", d.Pair, days)
+	}
+
+	names := make([]string, 0, maxDelistingAlertConsumers)
+	for i, c := range d.Consumers {
+		if i == maxDelistingAlertConsumers {
+			names = append(names, fmt.Sprintf("and %d more", len(d.Consumers)-i))
+			break
+		}
+		name := fmt.Sprintf("%s (%d requests)", c.Consumer, c.Requests)
+		if len(c.Tags) > 0 {
+			name = fmt.Sprintf("%s [%s] (%d requests)", c.Consumer, strings.Join(c.Tags, ","), c.Requests)
+		}
+		names = append(names, name)
+	}
+
+	return fmt.Sprintf("%s is no longer listed by Finchains - requested by %d consumers in the last %d days: %s",
+		d.Pair, len(d.Consumers), days, strings.Join(names, ", "))
+}
@@ -10,6 +10,8 @@ import (
 	"go-ooo/config"
 	go_ooo_types "go-ooo/types"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 func (s *Service) initEcho() {
@@ -19,14 +21,26 @@ func (s *Service) initEcho() {
 	}).Info("initialise echo")
 
 	s.echoService.Use(middleware.Recover())
-	s.echoService.Use(middleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
+	s.echoService.Use(redactErrors)
+	// the upstream admin and analytics task endpoints, authorised by the keystore token only
+	keystoreAuth := middleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
 		return key == s.authToken, nil
-	}))
+	})
+	s.echoService.POST("/admin", s.AddAdminTask, keystoreAuth)
+	s.echoService.POST("/analytics", s.AddAnalyticsTask, keystoreAuth)
 
-	s.echoService.POST("/admin", s.AddAdminTask)
-	s.echoService.POST("/analytics", s.AddAnalyticsTask)
+	// the admin API's routes, for the CLI. Where the admin API requires client certificates, only
+	// local requests are accepted here, so that remote clients can't bypass mTLS
+	auth := s.adminAuth()
+	if viper.GetString(config.AdminApiTlsClientCaFile) != "" {
+		auth = append([]echo.MiddlewareFunc{loopbackOnly}, auth...)
+	}
+	s.adminRoutes(s.echoService.Group(adminApiPrefix, auth...))
 
-	s.echoService.Logger.Fatal(s.echoService.Start(fmt.Sprintf("%s:%d", viper.GetString(config.ServeHost), viper.GetInt(config.ServePort))))
+	err := s.echoService.Start(fmt.Sprintf("%s:%d", viper.GetString(config.ServeHost), viper.GetInt(config.ServePort)))
+	if err != nil && err != http.ErrServerClosed {
+		s.echoService.Logger.Fatal(err)
+	}
 }
 
 func (s *Service) AddAdminTask(c echo.Context) error {
@@ -49,6 +63,7 @@ func (s *Service) AddAdminTask(c echo.Context) error {
 	for {
 		select {
 		case tr := <-s.adminTasksResp:
+			s.auditAdminTask(c, tr)
 			if tr.Success {
 				return c.JSON(http.StatusOK, tr)
 			}
@@ -81,3 +96,207 @@ func (s *Service) AddAnalyticsTask(c echo.Context) error {
 		}
 	}
 }
+
+func (s *Service) GetAttestation(c echo.Context) error {
+	requestId := c.Param("request_id")
+
+	att, err := s.db.GetAttestationByRequestId(requestId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, fmt.Sprintf("no attestation for request %s", requestId))
+	}
+
+	return c.JSON(http.StatusOK, go_ooo_types.Attestation{
+		RequestId:   att.GetRequestId(),
+		Endpoint:    att.GetEndpoint(),
+		Price:       att.GetPrice(),
+		Timestamp:   att.GetTimestamp(),
+		Sources:     att.GetSources(),
+		Signer:      att.GetSigner(),
+		MessageHash: att.GetMessageHash(),
+		Signature:   att.GetSignature(),
+		IpfsCid:     att.GetIpfsCid(),
+	})
+}
+
+func (s *Service) GetRequest(c echo.Context) error {
+	requestId := c.Param("request_id")
+
+	req, err := s.db.FindByRequestId(requestId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, fmt.Sprintf("request %s not found", requestId))
+	}
+
+	// the job's attestation, if it has one, holds its IPFS CID
+	att, _ := s.db.GetAttestationByRequestId(requestId)
+	sources, _ := s.requestSources(requestId)
+
+	return c.JSON(http.StatusOK, go_ooo_types.RequestInfo{
+		RequestId:           req.GetRequestId(),
+		Consumer:            req.GetConsumer(),
+		Endpoint:            req.GetEndpointDecoded(),
+		Fee:                 req.GetFee(),
+		RequestStatus:       req.GetRequestStatusString(),
+		JobStatus:           req.GetJobStatusString(),
+		StatusReason:        req.GetStatusReason(),
+		RejectionCode:       req.GetRejectionCode(),
+		ErrorCode:           req.GetErrorCode(),
+		PriceResult:         req.GetPriceResult(),
+		AnswerRounding:      req.GetAnswerRounding(),
+		SourcePolicy:        req.GetSourcePolicy(),
+		SourceTier:          req.GetSourceTier(),
+		FulfillmentAttempts: req.GetFulfillmentAttempts(),
+		FulfillTxHash:       req.GetFulfillTxHash(),
+		AttestationCid:      att.GetIpfsCid(),
+		Sources:             sources,
+	})
+}
+
+// GetRequestSources returns each source value fetched to answer the request, its weight in
+// the answer, why it was excluded if it was, and how long the fetch took
+func (s *Service) GetRequestSources(c echo.Context) error {
+	requestId := c.Param("request_id")
+
+	if _, err := s.db.FindByRequestId(requestId); err != nil {
+		return c.JSON(http.StatusNotFound, fmt.Sprintf("request %s not found", requestId))
+	}
+
+	sources, err := s.requestSources(requestId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, sources)
+}
+
+func (s *Service) requestSources(requestId string) ([]go_ooo_types.RequestSource, error) {
+	rows, err := s.db.GetRequestSources(requestId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]go_ooo_types.RequestSource, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, go_ooo_types.RequestSource{
+			Source:      r.GetSource(),
+			Value:       r.GetValue(),
+			Deviation:   r.GetDeviation(),
+			Excluded:    r.GetExcluded(),
+			Weight:      r.GetWeight(),
+			LatencyMs:   r.GetLatencyMs(),
+			PriceImpact: r.GetPriceImpact(),
+		})
+	}
+	return res, nil
+}
+
+func (s *Service) GetJournal(c echo.Context) error {
+	requestId := c.Param("request_id")
+
+	entries, err := s.db.GetJournalEntriesForRequest(requestId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]go_ooo_types.JournalEntry, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, go_ooo_types.JournalEntry{
+			Kind:        e.GetKind(),
+			TxHash:      e.GetTxHash(),
+			Nonce:       e.GetNonce(),
+			BlockNumber: e.GetBlockNumber(),
+			Data:        e.GetData(),
+			Resolved:    e.GetResolved(),
+			Broadcast:   e.GetBroadcast(),
+			CreatedAt:   e.CreatedAt.Unix(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (s *Service) GetLatencyReport(c echo.Context) error {
+	hours, _ := strconv.Atoi(c.QueryParam("hours"))
+	if hours <= 0 {
+		hours = 24
+	}
+
+	report, err := s.oooRouterService.LatencyReport(time.Now().Add(-time.Duration(hours) * time.Hour))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+func (s *Service) GetDeadJobs(c echo.Context) error {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	jobs, err := s.db.GetDeadJobs(limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]go_ooo_types.DeadJob, 0, len(jobs))
+	for _, j := range jobs {
+		res = append(res, go_ooo_types.DeadJob{
+			RequestId:           j.GetRequestId(),
+			Consumer:            j.GetConsumer(),
+			Endpoint:            j.GetEndpointDecoded(),
+			RequestBlockNumber:  j.GetRequestBlockNumber(),
+			FulfillmentAttempts: j.GetFulfillmentAttempts(),
+			StatusReason:        j.GetStatusReason(),
+			ErrorCode:           j.GetErrorCode(),
+			UpdatedAt:           j.UpdatedAt.Unix(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (s *Service) RequeueJob(c echo.Context) error {
+	requestId := c.Param("request_id")
+
+	err := s.db.RequeueFailedRequest(requestId)
+	s.audit(c, "requeue", requestId, nil, err)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, fmt.Sprintf("no failed job for request %s", requestId))
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"package":    "service",
+		"function":   "RequeueJob",
+		"request_id": requestId,
+	}).Info("failed job requeued")
+
+	return c.JSON(http.StatusOK, fmt.Sprintf("request %s requeued", requestId))
+}
+
+func (s *Service) ForceFulfillJob(c echo.Context) error {
+	requestId := c.Param("request_id")
+
+	var request go_ooo_types.ManualFulfillment
+	err := json.NewDecoder(c.Request().Body).Decode(&request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	err = s.oooRouterService.ForceFulfill(requestId, request.Value)
+	s.audit(c, "force_fulfill", requestId, request, err)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, fmt.Sprintf("request %s will be fulfilled with %s", requestId, request.Value))
+}
+
+func (s *Service) SkipJob(c echo.Context) error {
+	requestId := c.Param("request_id")
+
+	err := s.oooRouterService.SkipJob(requestId)
+	s.audit(c, "skip", requestId, nil, err)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, fmt.Sprintf("request %s skipped", requestId))
+}
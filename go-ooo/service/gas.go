@@ -0,0 +1,105 @@
+package service
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/labstack/echo/v4"
+	"github.com/montanaflynn/stats"
+	"go-ooo/database/models"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultGasSeriesInterval - seconds in each point of the gas series, if the interval query
+	// param is not set
+	defaultGasSeriesInterval = 3600
+	// minGasSeriesInterval - the shortest interval, in seconds
+	minGasSeriesInterval = 60
+	// maxGasSeriesPoints - the most intervals a gas series can be split into
+	maxGasSeriesPoints = 5000
+)
+
+// GetGasSeries returns the base and priority fees paid by fulfillment txs mined between the from
+// and to unix timestamps, which default to the last 30 days, in points of interval seconds, along
+// with the number fulfilled and failed in each
+func (s *Service) GetGasSeries(c echo.Context) error {
+	from, to, ok := reportPeriod(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, "from must be before to")
+	}
+
+	interval := int64(defaultGasSeriesInterval)
+	if i := c.QueryParam("interval"); i != "" {
+		n, err := strconv.ParseInt(i, 10, 64)
+		if err != nil || n < minGasSeriesInterval {
+			return c.JSON(http.StatusBadRequest, fmt.Sprintf("interval must be at least %d seconds", minGasSeriesInterval))
+		}
+		interval = n
+	}
+	if (to.Unix()-from.Unix())/interval > maxGasSeriesPoints {
+		return c.JSON(http.StatusBadRequest, fmt.Sprintf("period must be at most %d intervals", maxGasSeriesPoints))
+	}
+
+	spends, err := s.db.GetGasSpendsBetween(from, to)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, buildGasSeries(spends, from.Unix(), to.Unix(), interval))
+}
+
+// buildGasSeries groups spends, ordered by block time, into points of interval seconds from from
+func buildGasSeries(spends []models.GasSpends, from int64, to int64, interval int64) go_ooo_types.GasSeries {
+	series := go_ooo_types.GasSeries{
+		From:     from,
+		To:       to,
+		Interval: interval,
+		Points:   []go_ooo_types.GasSeriesPoint{},
+	}
+
+	for i := 0; i < len(spends); {
+		start := from + (spends[i].GetBlockTime()-from)/interval*interval
+		j := i
+		for j < len(spends) && spends[j].GetBlockTime() < start+interval {
+			j++
+		}
+		series.Points = append(series.Points, gasSeriesPoint(start, spends[i:j]))
+		i = j
+	}
+
+	return series
+}
+
+func gasSeriesPoint(start int64, spends []models.GasSpends) go_ooo_types.GasSeriesPoint {
+	p := go_ooo_types.GasSeriesPoint{Start: start}
+
+	var baseFees, priorityFees, gasPrices []float64
+	blocks := uint64(0)
+	for _, g := range spends {
+		if g.GetSuccess() {
+			p.Fulfilled++
+		} else {
+			p.Failed++
+		}
+		baseFees = append(baseFees, float64(g.GetBaseFee())/params.GWei)
+		priorityFees = append(priorityFees, float64(g.GetPriorityFee())/params.GWei)
+		gasPrices = append(gasPrices, float64(g.GetGasPrice())/params.GWei)
+		blocks += g.GetBlocks()
+	}
+
+	p.BaseFee = gasFeeStats(baseFees)
+	p.PriorityFee = gasFeeStats(priorityFees)
+	p.GasPrice = gasFeeStats(gasPrices)
+	p.MeanBlocks = float64(blocks) / float64(len(spends))
+
+	return p
+}
+
+func gasFeeStats(fees []float64) go_ooo_types.GasFeeStats {
+	min, _ := stats.Min(fees)
+	median, _ := stats.Median(fees)
+	max, _ := stats.Max(fees)
+	return go_ooo_types.GasFeeStats{Min: min, Median: median, Max: max}
+}
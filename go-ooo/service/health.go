@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/spf13/viper"
+	"go-ooo/chain"
+	"go-ooo/config"
+	go_ooo_types "go-ooo/types"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+const (
+	healthStatusOk   = "ok"
+	healthStatusFail = "fail"
+)
+
+type healthCheck func(ctx context.Context) (string, error)
+
+// healthHandler runs each check and returns 200 if all pass, otherwise 503
+func (s *Service) healthHandler(checks map[string]healthCheck, order []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		res := go_ooo_types.HealthResponse{Status: healthStatusOk}
+
+		for _, name := range order {
+			value, err := checks[name](ctx)
+			check := go_ooo_types.HealthCheck{
+				Name:   name,
+				Status: healthStatusOk,
+				Value:  value,
+			}
+			if err != nil {
+				check.Status = healthStatusFail
+				check.Error = err.Error()
+				res.Status = healthStatusFail
+			}
+			res.Checks = append(res.Checks, check)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if res.Status != healthStatusOk {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}
+}
+
+func (s *Service) checkDb(ctx context.Context) (string, error) {
+	return "", s.db.Ping(ctx)
+}
+
+func (s *Service) checkRpc(ctx context.Context) (string, error) {
+	blockNum, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d", blockNum), nil
+}
+
+// checkWalletBalance checks each key the node sends txs with can pay for them. The value is the
+// oracle key's balance
+func (s *Service) checkWalletBalance(ctx context.Context) (string, error) {
+	keys, err := s.oooRouterService.KeyBalances(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	minBalance, _ := new(big.Float).Mul(
+		big.NewFloat(viper.GetFloat64(config.HealthMinBalance)),
+		big.NewFloat(params.Ether),
+	).Int(nil)
+
+	for _, key := range keys {
+		balance, _ := new(big.Int).SetString(key.WalletBalance, 10)
+		if balance.Cmp(minBalance) >= 0 {
+			continue
+		}
+		if key.Role == chain.KeyRoleOracle {
+			return key.WalletBalance, fmt.Errorf("balance %s wei below minimum %s wei", key.WalletBalance, minBalance.String())
+		}
+		return keys[0].WalletBalance, fmt.Errorf("%s key %s balance %s wei below minimum %s wei", key.Role,
+			key.Address, key.WalletBalance, minBalance.String())
+	}
+	return keys[0].WalletBalance, nil
+}
+
+func (s *Service) checkBlockLag(ctx context.Context) (string, error) {
+	lag, err := s.oooRouterService.BlockLag(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	maxLag := time.Duration(viper.GetInt64(config.HealthMaxBlockLag)) * time.Second
+	if maxLag > 0 && lag > maxLag {
+		return lag.String(), fmt.Errorf("block lag %s exceeds %s", lag.String(), maxLag.String())
+	}
+	return lag.String(), nil
+}
+
+// checkClockSkew checks the host clock agrees with the chain's block timestamps, since staleness
+// checks and TWAP windows are timed by it
+func (s *Service) checkClockSkew(ctx context.Context) (string, error) {
+	skew, ok := s.oooRouterService.ClockSkew()
+	if !ok {
+		// measured once the chain head has moved between checks
+		return "unknown", nil
+	}
+
+	maxSkew := chain.MaxClockSkew()
+	if maxSkew > 0 && (skew > maxSkew || skew < -maxSkew) {
+		return skew.String(), fmt.Errorf("clock skew %s exceeds %s", skew.String(), maxSkew.String())
+	}
+	return skew.String(), nil
+}
+
+// initHealth registers /healthz, which checks the node can reach its database and eth node, and
+// /readyz, which also checks the wallet can pay for fulfillments, the eth node is in sync and the
+// host clock agrees with the chain
+func (s *Service) initHealth(mux *http.ServeMux) {
+	checks := map[string]healthCheck{
+		"database":       s.checkDb,
+		"rpc":            s.checkRpc,
+		"wallet_balance": s.checkWalletBalance,
+		"block_lag":      s.checkBlockLag,
+		"clock_skew":     s.checkClockSkew,
+	}
+
+	mux.HandleFunc("/healthz", s.healthHandler(checks, []string{"database", "rpc"}))
+	mux.HandleFunc("/readyz", s.healthHandler(checks, []string{"database", "rpc", "wallet_balance", "block_lag", "clock_skew"}))
+}
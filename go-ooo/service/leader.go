@@ -0,0 +1,114 @@
+package service
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+func leaderCheckInterval() time.Duration {
+	interval := viper.GetInt64(config.HaCheckInterval)
+	if interval <= 0 {
+		interval = 5
+	}
+	return time.Duration(interval) * time.Second
+}
+
+func (s *Service) isLeader() bool {
+	return atomic.LoadUint32(&s.leader) == 1
+}
+
+func (s *Service) initLeaderElection() error {
+	if !viper.GetBool(config.HaEnabled) {
+		return nil
+	}
+
+	lock, err := s.db.NewLeaderLock(viper.GetInt64(config.HaLockKey))
+	if err != nil {
+		return err
+	}
+
+	s.leaderLock = lock
+	return nil
+}
+
+// checkLeadership takes over as leader if the lock is free, or shuts down if leadership
+// has been lost, so that two instances never submit transactions at the same time
+func (s *Service) checkLeadership() {
+	if s.leaderLock == nil {
+		return
+	}
+
+	if s.isLeader() {
+		err := s.leaderLock.Check(s.ctx)
+		if err == nil {
+			return
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "checkLeadership",
+		}).Error("leader lock lost - shutting down: " + err.Error())
+
+		s.Stop()
+		os.Exit(1)
+	}
+
+	acquired, err := s.leaderLock.TryAcquire(s.ctx)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "checkLeadership",
+			"action":   "acquire leader lock",
+		}).Error(err.Error())
+		return
+	}
+
+	if !acquired {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "checkLeadership",
+		}).Debug("standby - leader lock held by another instance")
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "checkLeadership",
+	}).Info("acquired leader lock - becoming leader")
+
+	s.becomeLeader()
+}
+
+// becomeLeader catches up on anything missed while on standby, then starts processing events
+func (s *Service) becomeLeader() {
+	atomic.StoreUint32(&s.leader, 1)
+
+	// update supported pairs from the Finchains API
+	s.supervisor.Go("pair_sync", s.syncPairs)
+
+	// take over the lifetime totals saved by the previous leader
+	s.oooRouterService.RestoreMetrics()
+
+	// pick up from the last block we know about to process
+	// any historical events missed. This will run and complete
+	// before the event subscriptions initialise in order to
+	// process any potentially missed and/or processed requests
+	// the previous leader's shutdown state is checked before anything is resent
+	s.oooRouterService.VerifyStateSnapshot()
+	s.oooRouterService.ReconcileJournal()
+	s.oooRouterService.GetHistoricalEvents()
+
+	go s.supervisor.Run(s.ctx, "event_watcher", s.oooRouterService.RunEventWatchers)
+
+	if s.oooRouterService.VorEnabled() {
+		go s.supervisor.Run(s.ctx, "vor_event_watcher", s.oooRouterService.RunVorEventWatchers)
+	}
+
+	if s.oooRouterService.EventFiltersEnabled() {
+		go s.supervisor.Run(s.ctx, "event_filter_watcher", s.oooRouterService.RunEventFilterWatchers)
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// GetLedgerPending lists fulfillment txs held for approval because their gas price is above the
+// Ledger auto approve threshold
+func (s *Service) GetLedgerPending(c echo.Context) error {
+	if s.ledger == nil {
+		return c.JSON(http.StatusNotFound, "fulfillments are not signed with a ledger")
+	}
+	return c.JSON(http.StatusOK, s.ledger.Pending())
+}
+
+// ApproveLedgerTx approves or rejects a held tx. Approved txs must still be confirmed on the device
+func (s *Service) ApproveLedgerTx(approved bool) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if s.ledger == nil {
+			return c.JSON(http.StatusNotFound, "fulfillments are not signed with a ledger")
+		}
+
+		id := c.Param("id")
+		action := "ledger_approve"
+		if !approved {
+			action = "ledger_reject"
+		}
+
+		err := s.ledger.Approve(id, approved)
+		s.audit(c, action, "", map[string]string{"id": id}, err)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, err.Error())
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "ApproveLedgerTx",
+			"id":       id,
+			"approved": approved,
+		}).Info("ledger tx approval")
+
+		if approved {
+			return c.JSON(http.StatusOK, fmt.Sprintf("ledger tx %s approved - confirm it on the device", id))
+		}
+		return c.JSON(http.StatusOK, fmt.Sprintf("ledger tx %s rejected", id))
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/labstack/echo/v4"
+	"go-ooo/errcodes"
+	"go-ooo/ooo_api"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+	"strings"
+)
+
+// endpoint kinds reported by LintEndpoint
+const (
+	endpointKindPrice      = "price"
+	endpointKindAdhoc      = "ad-hoc"
+	endpointKindHistorical = "historical"
+	endpointKindJsonFeed   = "json feed"
+)
+
+// LintEndpoint checks a prospective request endpoint, given with the endpoint query param, e.g.
+// BTC.USD.PR.AVC, and reports whether this node would serve it, and if so which sources would be
+// used, and an example of the current value - so consumers can check an endpoint before paying
+// for a request. The endpoint is checked as given, so lower case parts are reported
+func (s *Service) LintEndpoint(c echo.Context) error {
+	endpoint := c.QueryParam("endpoint")
+	if endpoint == "" {
+		return c.JSON(http.StatusBadRequest, "endpoint is required, e.g. ?endpoint=BTC.USD.PR.AVC")
+	}
+
+	res := go_ooo_types.EndpointLint{
+		Endpoint:       endpoint,
+		AnswerDecimals: s.oooApi.AnswerDecimals(),
+	}
+
+	if len(endpoint) <= ooo_api.MaxEndpointLength {
+		res.Data = common.BytesToHash(common.RightPadBytes([]byte(endpoint), 32)).Hex()
+	}
+
+	if base, target, _, _, _, _, _, err := ooo_api.ParseEndpoint(endpoint); err == nil {
+		res.Pair = base + "." + target
+		res.Kind = endpointKind(endpoint)
+	}
+
+	if rejection := s.oooApi.ValidateRequestEndpoint(endpoint); rejection != nil {
+		res.RejectionCode = rejection.Code
+		res.ErrorCode = string(rejection.ErrorCode())
+		res.Reason = rejection.Reason
+		if strings.ToUpper(endpoint) != endpoint && s.oooApi.ValidateRequestEndpoint(strings.ToUpper(endpoint)) == nil {
+			res.Reason = fmt.Sprintf("%s - endpoints are case sensitive, try %s", res.Reason, strings.ToUpper(endpoint))
+		}
+		return c.JSON(http.StatusOK, res)
+	}
+
+	answer, sources, err := s.oooApi.QueryEndpoint(c.Request().Context(), endpoint, "")
+	if err != nil {
+		res.Error = err.Error()
+		res.ErrorCode = string(errcodes.CodeOf(err))
+		return c.JSON(http.StatusOK, res)
+	}
+
+	res.Servable = true
+	res.Sources = sources
+	res.Answer = answer
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func endpointKind(endpoint string) string {
+	if isJsonFeed, _ := ooo_api.IsJsonFeed(endpoint); isJsonFeed {
+		return endpointKindJsonFeed
+	}
+	if isHistorical, _ := ooo_api.IsHistorical(endpoint); isHistorical {
+		return endpointKindHistorical
+	}
+	if isAdhoc, _ := ooo_api.IsAdhoc(endpoint); isAdhoc {
+		return endpointKindAdhoc
+	}
+	return endpointKindPrice
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+)
+
+func (s *Service) GetLogLevel(c echo.Context) error {
+	return c.JSON(http.StatusOK, go_ooo_types.LogLevel{Level: s.logger.GetLevel().String()})
+}
+
+// SetLogLevel changes the log level without restarting, e.g. to enable debug logging during an incident
+func (s *Service) SetLogLevel(c echo.Context) error {
+	var request go_ooo_types.LogLevel
+	err := json.NewDecoder(c.Request().Body).Decode(&request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	level, err := logrus.ParseLevel(request.Level)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "SetLogLevel",
+		"from":     s.logger.GetLevel().String(),
+		"to":       level.String(),
+	}).Warn("log level changed")
+
+	s.logger.SetLevel(level)
+	s.audit(c, "set_log_level", "", go_ooo_types.LogLevel{Level: level.String()}, nil)
+
+	return c.JSON(http.StatusOK, go_ooo_types.LogLevel{Level: level.String()})
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"go-ooo/alerts"
+	"go-ooo/database/models"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maintenanceAlertKey - key of the AlertMaintenance alert, of which there is only ever one
+const maintenanceAlertKey = "node"
+
+var maintenanceMode = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "ooo_maintenance_mode",
+	Help: "1 while the node is in maintenance mode, e.g. for planned work, otherwise 0. Alert rules can be silenced while it is 1",
+})
+
+// maintenanceState is the active maintenance window, cached from the database
+type maintenanceState struct {
+	mu     sync.RWMutex
+	window models.MaintenanceWindows
+}
+
+// get returns the active maintenance window, and false if there is none
+func (m *maintenanceState) get() (models.MaintenanceWindows, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.window, m.window.ID != 0
+}
+
+func (m *maintenanceState) set(w models.MaintenanceWindows) {
+	m.mu.Lock()
+	m.window = w
+	m.mu.Unlock()
+}
+
+// maintenanceHook annotates every log entry with maintenance=true while the node is in
+// maintenance mode, so that entries logged during planned work can be filtered out
+type maintenanceHook struct {
+	state *maintenanceState
+}
+
+func (h maintenanceHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h maintenanceHook) Fire(e *logrus.Entry) error {
+	if _, active := h.state.get(); active {
+		e.Data["maintenance"] = true
+	}
+	return nil
+}
+
+// applyMaintenance makes w the active maintenance window, or ends maintenance mode if w is empty
+func (s *Service) applyMaintenance(w models.MaintenanceWindows) {
+	s.maintenance.set(w)
+	active := w.ID != 0
+	if active {
+		maintenanceMode.Set(1)
+	} else {
+		maintenanceMode.Set(0)
+	}
+	s.oooRouterService.Alerter().SetMaintenance(active)
+}
+
+// checkMaintenance reloads the active maintenance window from the database, e.g. one started via
+// another instance, and ends it once it has expired. Expired windows are only ended by the leader,
+// and treated as ended by standby instances
+func (s *Service) checkMaintenance() {
+	w, err := s.db.GetActiveMaintenanceWindow()
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "checkMaintenance",
+		}).Error(err.Error())
+		return
+	}
+
+	if w.ID != 0 && w.IsExpired(time.Now().Unix()) {
+		if s.isLeader() {
+			s.endMaintenance(w, "expired")
+			return
+		}
+		w = models.MaintenanceWindows{}
+	}
+
+	prev, wasActive := s.maintenance.get()
+	s.applyMaintenance(w)
+
+	if prev.ID != w.ID {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "checkMaintenance",
+			"active":   w.ID != 0,
+			"previous": wasActive,
+			"note":     w.GetNote(),
+		}).Info("maintenance mode changed")
+	}
+}
+
+// endMaintenance ends the maintenance window w, resolving its alert. Windows which expire are
+// recorded in the audit log as ended by the node
+func (s *Service) endMaintenance(w models.MaintenanceWindows, endedBy string) error {
+	err := s.db.EndMaintenanceWindow(endedBy)
+
+	if endedBy == "expired" {
+		params, _ := json.Marshal(maintenanceStatus(w))
+		errMsg := ""
+		if err != nil {
+			errMsg = err.Error()
+		}
+		if auditErr := s.db.InsertAuditLog("node", models.AUDIT_SOURCE_NODE, "", "maintenance_end", "",
+			string(params), err == nil, errMsg); auditErr != nil {
+			s.logger.WithFields(logrus.Fields{
+				"package":  "service",
+				"function": "endMaintenance",
+			}).Error(auditErr.Error())
+		}
+	}
+
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "endMaintenance",
+		}).Error(err.Error())
+		return err
+	}
+
+	s.applyMaintenance(models.MaintenanceWindows{})
+	s.oooRouterService.Alerter().Resolve(alerts.AlertMaintenance, maintenanceAlertKey)
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "endMaintenance",
+		"note":     w.GetNote(),
+		"ended_by": endedBy,
+		"started":  w.CreatedAt.UTC().Format(time.RFC3339),
+	}).Warn("maintenance mode ended")
+
+	return nil
+}
+
+func maintenanceStatus(w models.MaintenanceWindows) go_ooo_types.MaintenanceStatus {
+	if w.ID == 0 {
+		return go_ooo_types.MaintenanceStatus{}
+	}
+	return go_ooo_types.MaintenanceStatus{
+		Active:    true,
+		Note:      w.GetNote(),
+		StartedBy: w.GetStartedBy(),
+		StartedAt: w.CreatedAt.Unix(),
+		Until:     w.GetUntil(),
+	}
+}
+
+// GetMaintenance returns the node's maintenance mode
+func (s *Service) GetMaintenance(c echo.Context) error {
+	w, _ := s.maintenance.get()
+	return c.JSON(http.StatusOK, maintenanceStatus(w))
+}
+
+// StartMaintenance puts the node into maintenance mode, e.g. for planned work, replacing any
+// maintenance already in progress. Alerts are suppressed, and logs annotated, until it is ended
+// or its duration has passed
+func (s *Service) StartMaintenance(c echo.Context) error {
+	var request go_ooo_types.MaintenanceRequest
+	err := json.NewDecoder(c.Request().Body).Decode(&request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	until := int64(0)
+	if request.Duration != "" {
+		d, err := time.ParseDuration(request.Duration)
+		if err != nil || d <= 0 {
+			return c.JSON(http.StatusBadRequest, fmt.Sprintf("invalid duration %q, e.g. 2h", request.Duration))
+		}
+		until = time.Now().Add(d).Unix()
+	}
+
+	actor := auditActor(c)
+	w, err := s.db.StartMaintenanceWindow(request.Note, actor, until)
+	s.audit(c, "maintenance_start", "", request, err)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	s.applyMaintenance(w)
+
+	s.logger.WithFields(logrus.Fields{
+		"package":    "service",
+		"function":   "StartMaintenance",
+		"note":       request.Note,
+		"duration":   request.Duration,
+		"started_by": actor,
+	}).Warn("maintenance mode started")
+
+	msg := fmt.Sprintf("maintenance started by %s", actor)
+	if until > 0 {
+		msg += fmt.Sprintf(" until %s", time.Unix(until, 0).UTC().Format(time.RFC3339))
+	}
+	if request.Note != "" {
+		msg += ": " + request.Note
+	}
+	s.oooRouterService.Alerter().Alert(alerts.AlertMaintenance, maintenanceAlertKey, msg)
+
+	return c.JSON(http.StatusOK, maintenanceStatus(w))
+}
+
+// EndMaintenance ends maintenance mode. Alerts whose conditions persist are sent by the next check
+func (s *Service) EndMaintenance(c echo.Context) error {
+	// read from the database, in case maintenance was started via another instance
+	w, err := s.db.GetActiveMaintenanceWindow()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	if w.ID == 0 {
+		return c.JSON(http.StatusBadRequest, "the node is not in maintenance mode")
+	}
+
+	err = s.endMaintenance(w, auditActor(c))
+	s.audit(c, "maintenance_end", "", maintenanceStatus(w), err)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, maintenanceStatus(models.MaintenanceWindows{}))
+}
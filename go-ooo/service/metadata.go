@@ -0,0 +1,264 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/labstack/echo/v4"
+	"go-ooo/database/models"
+	"go-ooo/ooo_api"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// limits on the metadata an operator can attach to a consumer or pair
+const (
+	maxTags       = 16
+	maxTagLength  = 32
+	maxNoteLength = 500
+)
+
+var tagRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]*$`)
+
+// normaliseTags lower cases, de-duplicates and sorts tags, returning an error for any which are
+// not valid
+func normaliseTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool)
+	res := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		if len(t) > maxTagLength || !tagRegex.MatchString(t) {
+			return nil, fmt.Errorf("tag %q must be 1 - %d lower case letters, digits, or _ . : - characters", t, maxTagLength)
+		}
+		seen[t] = true
+		res = append(res, t)
+	}
+
+	if len(res) > maxTags {
+		return nil, fmt.Errorf("at most %d tags can be set", maxTags)
+	}
+
+	sort.Strings(res)
+	return res, nil
+}
+
+// metadataIndex is the tags and notes of every consumer and pair, loaded once per report or
+// job listing
+type metadataIndex struct {
+	consumers map[string]models.ConsumerMetadata
+	pairs     map[string]models.PairMetadata
+}
+
+func (s *Service) loadMetadata() (metadataIndex, error) {
+	idx := metadataIndex{
+		consumers: make(map[string]models.ConsumerMetadata),
+		pairs:     make(map[string]models.PairMetadata),
+	}
+
+	consumers, err := s.db.GetAllConsumerMetadata()
+	if err != nil {
+		return idx, err
+	}
+	for _, m := range consumers {
+		idx.consumers[m.GetConsumer()] = m
+	}
+
+	pairs, err := s.db.GetAllPairMetadata()
+	if err != nil {
+		return idx, err
+	}
+	for _, m := range pairs {
+		idx.pairs[m.GetPair()] = m
+	}
+
+	return idx, nil
+}
+
+func (idx metadataIndex) consumerTags(consumer string) []string {
+	if m, ok := idx.consumers[consumer]; ok {
+		return m.GetTags()
+	}
+	return nil
+}
+
+func (idx metadataIndex) pairTags(pair string) []string {
+	if m, ok := idx.pairs[pair]; ok {
+		return m.GetTags()
+	}
+	return nil
+}
+
+// tagged returns the consumers and pairs with tag
+func (idx metadataIndex) tagged(tag string) ([]string, []string) {
+	var consumers, pairs []string
+	for consumer, m := range idx.consumers {
+		if inTags(tag, m.GetTags()) {
+			consumers = append(consumers, consumer)
+		}
+	}
+	for pair, m := range idx.pairs {
+		if inTags(tag, m.GetTags()) {
+			pairs = append(pairs, pair)
+		}
+	}
+	return consumers, pairs
+}
+
+func inTags(tag string, tags []string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMetadata returns the tags and notes of every consumer and pair which has them
+func (s *Service) GetMetadata(c echo.Context) error {
+	idx, err := s.loadMetadata()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	res := go_ooo_types.MetadataList{
+		Consumers: make([]go_ooo_types.ConsumerMetadata, 0, len(idx.consumers)),
+		Pairs:     make([]go_ooo_types.PairMetadata, 0, len(idx.pairs)),
+	}
+	for _, m := range idx.consumers {
+		res.Consumers = append(res.Consumers, go_ooo_types.ConsumerMetadata{
+			Consumer: m.GetConsumer(),
+			Metadata: go_ooo_types.Metadata{Tags: m.GetTags(), Note: m.GetNote()},
+		})
+	}
+	for _, m := range idx.pairs {
+		res.Pairs = append(res.Pairs, go_ooo_types.PairMetadata{
+			Pair:     m.GetPair(),
+			Metadata: go_ooo_types.Metadata{Tags: m.GetTags(), Note: m.GetNote()},
+		})
+	}
+
+	sort.Slice(res.Consumers, func(i, j int) bool {
+		return res.Consumers[i].Consumer < res.Consumers[j].Consumer
+	})
+	sort.Slice(res.Pairs, func(i, j int) bool {
+		return res.Pairs[i].Pair < res.Pairs[j].Pair
+	})
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// decodeMetadata reads and validates the tags and note in a request body
+func decodeMetadata(c echo.Context) (go_ooo_types.Metadata, error) {
+	var request go_ooo_types.Metadata
+	if err := json.NewDecoder(c.Request().Body).Decode(&request); err != nil {
+		return request, err
+	}
+
+	tags, err := normaliseTags(request.Tags)
+	if err != nil {
+		return request, err
+	}
+	request.Tags = tags
+
+	request.Note = strings.TrimSpace(request.Note)
+	if len(request.Note) > maxNoteLength {
+		return request, fmt.Errorf("note must be at most %d bytes", maxNoteLength)
+	}
+
+	return request, nil
+}
+
+func consumerParam(c echo.Context) (string, bool) {
+	if !common.IsHexAddress(c.Param("consumer")) {
+		return "", false
+	}
+	return common.HexToAddress(c.Param("consumer")).Hex(), true
+}
+
+func pairParam(c echo.Context) (string, bool) {
+	base, target, ok := ooo_api.SplitPair(c.Param("pair"))
+	if !ok {
+		return "", false
+	}
+	return base + "." + target, true
+}
+
+// SetConsumerMetadata replaces the tags and note of the consumer param
+func (s *Service) SetConsumerMetadata(c echo.Context) error {
+	consumer, ok := consumerParam(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, "consumer must be an address")
+	}
+
+	request, err := decodeMetadata(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	err = s.db.UpsertConsumerMetadata(consumer, request.Tags, request.Note)
+	s.audit(c, "set_consumer_metadata", "", go_ooo_types.ConsumerMetadata{Consumer: consumer, Metadata: request}, err)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, go_ooo_types.ConsumerMetadata{Consumer: consumer, Metadata: request})
+}
+
+// DeleteConsumerMetadata removes the tags and note of the consumer param
+func (s *Service) DeleteConsumerMetadata(c echo.Context) error {
+	consumer, ok := consumerParam(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, "consumer must be an address")
+	}
+
+	err := s.db.DeleteConsumerMetadata(consumer)
+	s.audit(c, "delete_consumer_metadata", "", go_ooo_types.ConsumerMetadata{Consumer: consumer}, err)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, "metadata deleted for "+consumer)
+}
+
+// SetPairMetadata replaces the tags and note of the pair param, e.g. BTC.USD
+func (s *Service) SetPairMetadata(c echo.Context) error {
+	pair, ok := pairParam(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, "pair must be of the form BASE.TARGET")
+	}
+
+	request, err := decodeMetadata(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	err = s.db.UpsertPairMetadata(pair, request.Tags, request.Note)
+	s.audit(c, "set_pair_metadata", "", go_ooo_types.PairMetadata{Pair: pair, Metadata: request}, err)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, go_ooo_types.PairMetadata{Pair: pair, Metadata: request})
+}
+
+// DeletePairMetadata removes the tags and note of the pair param
+func (s *Service) DeletePairMetadata(c echo.Context) error {
+	pair, ok := pairParam(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, "pair must be of the form BASE.TARGET")
+	}
+
+	err := s.db.DeletePairMetadata(pair)
+	s.audit(c, "delete_pair_metadata", "", go_ooo_types.PairMetadata{Pair: pair}, err)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, "metadata deleted for "+pair)
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"go-ooo/database"
+	"go-ooo/database/models"
+	"go-ooo/ooo_api"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// defaultPairHistoryLimit - changes returned if the limit param is not given
+const defaultPairHistoryLimit = 100
+
+// GetPairHistory returns the changelog of the supported pairs, the DEX pairs and the sources'
+// availability between the from and to unix timestamps, most recent first, up to the limit
+// param. If the pair param, BASE.TARGET, is given, only changes to the pair, as any source names
+// it, blocklist patterns matching it and changes to sources affecting every pair are returned.
+// The source and change params filter on the source and kind of change
+func (s *Service) GetPairHistory(c echo.Context) error {
+	from, to, ok := reportPeriod(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, "from must be before to")
+	}
+
+	filter := database.PairChangeFilter{
+		Source: c.QueryParam("source"),
+		Since:  from,
+		Until:  to,
+	}
+
+	if change := strings.ToLower(c.QueryParam("change")); change != "" {
+		known := false
+		for _, kind := range models.PairChangeKinds {
+			known = known || kind == change
+		}
+		if !known {
+			return c.JSON(http.StatusBadRequest, fmt.Sprintf("change must be one of %s", strings.Join(models.PairChangeKinds, ", ")))
+		}
+		filter.Changes = []string{change}
+	}
+
+	var base, target string
+	if pair := c.QueryParam("pair"); pair != "" {
+		if base, target, ok = ooo_api.SplitPair(pair); !ok {
+			return c.JSON(http.StatusBadRequest, "pair must be of the form BASE.TARGET")
+		}
+		// Finchains pairs are BASE.TARGET, and DEX pairs are named by their tokens, in either order
+		filter.Pairs = []string{base + "." + target, base + "-" + target, target + "-" + base}
+	}
+
+	limit := defaultPairHistoryLimit
+	if l := c.QueryParam("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, "limit must be a positive integer")
+		}
+		limit = n
+	}
+
+	rows, err := s.db.GetPairChanges(filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]go_ooo_types.PairChange, 0)
+	for _, r := range rows {
+		if len(res) == limit {
+			break
+		}
+		if base != "" && strings.ContainsAny(r.GetPair(), "*?") {
+			// a blocklist pattern, returned if it matches the pair
+			if matched, _ := path.Match(strings.ToUpper(r.GetPair()), base+"."+target); !matched {
+				continue
+			}
+		}
+		res = append(res, go_ooo_types.PairChange{
+			Pair:      r.GetPair(),
+			Change:    r.GetChange(),
+			Source:    r.GetSource(),
+			Reason:    r.GetReason(),
+			ChangedAt: r.CreatedAt.Unix(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
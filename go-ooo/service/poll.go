@@ -0,0 +1,66 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"time"
+)
+
+var jobCheckInterval = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "ooo_job_check_interval_seconds",
+	Help: "Current interval between job queue checks",
+})
+
+// jobPoll adapts the interval between job queue checks to request activity. The queue is
+// checked every jobs.check_duration seconds while there are pending jobs, and the interval
+// doubles on each idle check, up to jobs.check_duration_max seconds. New requests reset it
+type jobPoll struct {
+	min     time.Duration
+	max     time.Duration
+	current time.Duration
+}
+
+func newJobPoll() *jobPoll {
+	min := time.Duration(viper.GetInt64(config.JobsCheckDuration)) * time.Second
+	if min <= 0 {
+		min = 30 * time.Second
+	}
+
+	// adaptive polling is disabled unless the max is above the min
+	max := time.Duration(viper.GetInt64(config.JobsCheckDurationMax)) * time.Second
+	if max < min {
+		max = min
+	}
+
+	jobCheckInterval.Set(min.Seconds())
+
+	return &jobPoll{min: min, max: max, current: min}
+}
+
+// next returns the interval until the next check, and whether it has changed. busy is true if
+// there is pending work
+func (p *jobPoll) next(busy bool) (time.Duration, bool) {
+	prev := p.current
+
+	if busy {
+		p.current = p.min
+	} else if p.current < p.max {
+		p.current *= 2
+		if p.current > p.max {
+			p.current = p.max
+		}
+	}
+
+	jobCheckInterval.Set(p.current.Seconds())
+
+	return p.current, p.current != prev
+}
+
+// adjustJobTicker resets the job ticker to the next poll interval, if it has changed
+func (s *Service) adjustJobTicker(busy bool) {
+	if interval, changed := s.jobPoll.next(busy); changed {
+		s.jobTicker.Reset(interval)
+	}
+}
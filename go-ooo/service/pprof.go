@@ -0,0 +1,45 @@
+package service
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"net/http"
+	"net/http/pprof"
+)
+
+// initPprof serves the pprof debug endpoints on localhost, if enabled, e.g.
+//
+//	curl http://localhost:6060/debug/pprof/heap > heap.out
+//	curl http://localhost:6060/debug/pprof/goroutine > goroutine.out
+//	curl http://localhost:6060/debug/pprof/profile?seconds=30 > cpu.out
+//	go tool pprof -http=:8080 heap.out
+func (s *Service) initPprof() {
+	if !viper.GetBool(config.PprofEnabled) {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	listen := fmt.Sprintf("127.0.0.1:%d", viper.GetInt(config.PprofPort))
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "initPprof",
+		"listen":   listen,
+	}).Warn("pprof debug endpoint enabled")
+
+	err := http.ListenAndServe(listen, mux)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "initPprof",
+		}).Error(err.Error())
+	}
+}
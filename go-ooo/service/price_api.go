@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/cache"
+	"go-ooo/config"
+	"go-ooo/ooo_api"
+	go_ooo_types "go-ooo/types"
+	"golang.org/x/time/rate"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaults used if the price API is enabled in configs written by older versions, without its
+// cache and rate limit settings
+const (
+	defaultPriceApiCacheTtl  = 10
+	defaultPriceApiRateLimit = 5
+	defaultPriceApiRateBurst = 10
+)
+
+// priceApiSharedKey - the shared cache key of the latest prices, if cache.redis_url is set
+const priceApiSharedKey = "price_api:prices"
+
+// sharedPrices is the latest prices as held in the shared cache, with when they were read from the
+// database, so that they are not cached for longer than price_api.cache_ttl by the nodes sharing them
+type sharedPrices struct {
+	FetchedAt int64                      `json:"fetched_at"`
+	Prices    []go_ooo_types.PublicPrice `json:"prices"`
+}
+
+// priceCache holds the latest prices served by the price API, so that public requests are
+// answered from memory, and the database is read at most once per price_api.cache_ttl
+type priceCache struct {
+	mu        sync.Mutex
+	fetchedAt time.Time
+	prices    []go_ooo_types.PublicPrice
+	byPair    map[string]go_ooo_types.PublicPrice
+}
+
+// initPriceApi serves the public, read-only price API, which publishes the latest price the node
+// has fulfilled for each pair, as an off-chain feed from the same pipeline as its answers, and
+// lets consumer developers check whether the node can serve a request endpoint, and look up how
+// their requests were answered
+func (s *Service) initPriceApi() {
+	port := viper.GetInt(config.PriceApiPort)
+	if port == 0 {
+		return
+	}
+
+	listen := fmt.Sprintf("%s:%d", viper.GetString(config.PriceApiHost), port)
+
+	s.logger.WithFields(logrus.Fields{
+		"package":    "service",
+		"function":   "initPriceApi",
+		"listen":     listen,
+		"cache_ttl":  priceApiCacheTtl(),
+		"rate_limit": priceApiSetting(config.PriceApiRateLimit, defaultPriceApiRateLimit),
+	}).Info("initialise price api")
+
+	s.priceEcho.HideBanner = true
+	s.priceEcho.Server.ReadTimeout = 10 * time.Second
+	s.priceEcho.Server.WriteTimeout = 10 * time.Second
+	s.priceEcho.IPExtractor = echo.ExtractIPDirect()
+	if viper.GetBool(config.PriceApiTrustProxy) {
+		s.priceEcho.IPExtractor = echo.ExtractIPFromXFFHeader()
+	}
+
+	s.priceEcho.Use(middleware.Recover())
+	s.priceEcho.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins: []string{"*"},
+		AllowMethods: []string{http.MethodGet},
+	}))
+	if limit := priceApiSetting(config.PriceApiRateLimit, defaultPriceApiRateLimit); limit > 0 {
+		// a burst below 1 would refuse every request
+		burst := int(priceApiSetting(config.PriceApiRateBurst, defaultPriceApiRateBurst))
+		if burst < 1 {
+			burst = 1
+		}
+		var store middleware.RateLimiterStore = middleware.NewRateLimiterMemoryStoreWithConfig(
+			middleware.RateLimiterMemoryStoreConfig{
+				Rate:      rate.Limit(limit),
+				Burst:     burst,
+				ExpiresIn: 3 * time.Minute,
+			})
+		if shared := cache.Shared(); shared.Enabled() {
+			store = newSharedRateLimiterStore(shared, limit, burst, store)
+		}
+		s.priceEcho.Use(middleware.RateLimiter(store))
+	}
+
+	s.priceEcho.GET("/v1/prices", s.GetPublicPrices)
+	s.priceEcho.GET("/v1/prices/:pair", s.GetPublicPrice)
+	s.priceEcho.GET("/v1/lint", s.LintEndpoint)
+	s.priceEcho.GET("/v1/answers/:id", s.GetPublicAnswer)
+
+	err := s.priceEcho.Start(listen)
+	if err != nil && err != http.ErrServerClosed {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "initPriceApi",
+		}).Error(err.Error())
+	}
+}
+
+// GetPublicPrices returns the latest fulfilled price of every pair, ordered by pair
+func (s *Service) GetPublicPrices(c echo.Context) error {
+	prices, _, err := s.latestPrices()
+	if err != nil {
+		return c.JSON(http.StatusServiceUnavailable, "prices are unavailable")
+	}
+
+	setPriceCacheControl(c)
+	return c.JSON(http.StatusOK, prices)
+}
+
+// GetPublicPrice returns the latest fulfilled price of the pair param, e.g. BTC.USD
+func (s *Service) GetPublicPrice(c echo.Context) error {
+	base, target, ok := ooo_api.SplitPair(c.Param("pair"))
+	if !ok {
+		return c.JSON(http.StatusBadRequest, "pair must be of the form BASE.TARGET")
+	}
+
+	_, byPair, err := s.latestPrices()
+	if err != nil {
+		return c.JSON(http.StatusServiceUnavailable, "prices are unavailable")
+	}
+
+	price, ok := byPair[base+"."+target]
+	if !ok {
+		return c.JSON(http.StatusNotFound, "no price for "+base+"."+target)
+	}
+
+	setPriceCacheControl(c)
+	return c.JSON(http.StatusOK, price)
+}
+
+// latestPrices returns the cached prices, reading them from the shared cache, if configured, or the
+// database if they are older than price_api.cache_ttl
+func (s *Service) latestPrices() ([]go_ooo_types.PublicPrice, map[string]go_ooo_types.PublicPrice, error) {
+	s.priceCache.mu.Lock()
+	defer s.priceCache.mu.Unlock()
+
+	ttl := time.Duration(priceApiCacheTtl()) * time.Second
+	if s.priceCache.byPair != nil && time.Since(s.priceCache.fetchedAt) < ttl {
+		return s.priceCache.prices, s.priceCache.byPair, nil
+	}
+
+	shared := cache.Shared()
+	if body, ok, _ := shared.Get(context.Background(), priceApiSharedKey); ok {
+		var cached sharedPrices
+		if json.Unmarshal(body, &cached) == nil && time.Since(time.Unix(0, cached.FetchedAt)) < ttl {
+			s.setPriceCache(cached.Prices, time.Unix(0, cached.FetchedAt))
+			return s.priceCache.prices, s.priceCache.byPair, nil
+		}
+	}
+
+	jobs, err := s.db.GetLatestFulfilledPerEndpoint()
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "latestPrices",
+		}).Error(err.Error())
+		return nil, nil, err
+	}
+
+	decimals := s.oooApi.AnswerDecimals()
+	byPair := make(map[string]go_ooo_types.PublicPrice)
+	ids := make(map[string]uint)
+
+	for _, j := range jobs {
+		base, target, qType, subtype, _, _, _, err := ooo_api.ParseEndpoint(j.GetEndpointDecoded())
+		// only current prices - not historical prices or json feed values
+		if err != nil || (qType != "PR" && qType != "AD") || subtype == ooo_api.HistoricalSubType {
+			continue
+		}
+
+		pair := strings.ToUpper(base) + "." + strings.ToUpper(target)
+		if id, ok := ids[pair]; ok && id > j.ID {
+			continue
+		}
+		ids[pair] = j.ID
+
+		byPair[pair] = go_ooo_types.PublicPrice{
+			Pair:      pair,
+			Price:     j.GetPriceResult(),
+			Decimals:  decimals,
+			Endpoint:  j.GetEndpointDecoded(),
+			RequestId: j.GetRequestId(),
+			TxHash:    j.GetFulfillTxHash(),
+			UpdatedAt: j.UpdatedAt.Unix(),
+		}
+	}
+
+	prices := make([]go_ooo_types.PublicPrice, 0, len(byPair))
+	for _, p := range byPair {
+		prices = append(prices, p)
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Pair < prices[j].Pair
+	})
+
+	fetchedAt := time.Now()
+	s.setPriceCache(prices, fetchedAt)
+	if shared.Enabled() && ttl > 0 {
+		if body, err := json.Marshal(sharedPrices{FetchedAt: fetchedAt.UnixNano(), Prices: prices}); err == nil {
+			_ = shared.Set(context.Background(), priceApiSharedKey, body, ttl)
+		}
+	}
+
+	return prices, byPair, nil
+}
+
+// setPriceCache caches prices, ordered by pair. Must be called with s.priceCache.mu held
+func (s *Service) setPriceCache(prices []go_ooo_types.PublicPrice, fetchedAt time.Time) {
+	byPair := make(map[string]go_ooo_types.PublicPrice, len(prices))
+	for _, p := range prices {
+		byPair[p.Pair] = p
+	}
+
+	s.priceCache.prices = prices
+	s.priceCache.byPair = byPair
+	s.priceCache.fetchedAt = fetchedAt
+}
+
+// sharedRateLimiterStore counts the price API's requests per client in the shared cache, so that
+// a client is limited across every replica behind the load balancer rather than by each. Each
+// client may make burst requests per window of burst/rate seconds. If the shared cache cannot be
+// reached, this node's own limiter is used
+type sharedRateLimiterStore struct {
+	shared   *cache.Client
+	window   time.Duration
+	burst    int64
+	fallback middleware.RateLimiterStore
+}
+
+func newSharedRateLimiterStore(shared *cache.Client, limit float64, burst int, fallback middleware.RateLimiterStore) *sharedRateLimiterStore {
+	window := time.Duration(float64(burst) / limit * float64(time.Second))
+	if window < time.Second {
+		window = time.Second
+	}
+	return &sharedRateLimiterStore{
+		shared:   shared,
+		window:   window,
+		burst:    int64(burst),
+		fallback: fallback,
+	}
+}
+
+// Allow counts the request against the client's shared count, returning false if it is over the limit
+func (r *sharedRateLimiterStore) Allow(identifier string) (bool, error) {
+	current := time.Now().UnixNano() / int64(r.window)
+	key := fmt.Sprintf("ratelimit:price_api:%s:%d", identifier, current)
+	n, err := r.shared.Incr(context.Background(), key, r.window)
+	if err != nil {
+		return r.fallback.Allow(identifier)
+	}
+	return n <= r.burst, nil
+}
+
+func setPriceCacheControl(c echo.Context) {
+	c.Response().Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", priceApiCacheTtl()))
+}
+
+func priceApiCacheTtl() int64 {
+	return int64(priceApiSetting(config.PriceApiCacheTtl, defaultPriceApiCacheTtl))
+}
+
+// priceApiSetting returns key, or def if it is not in the config
+func priceApiSetting(key string, def float64) float64 {
+	if !viper.IsSet(key) {
+		return def
+	}
+	return viper.GetFloat64(key)
+}
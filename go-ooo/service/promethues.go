@@ -1,12 +1,6 @@
 package service
 
-// add:
-// _ "net/http/pprof"
-// to import statement for profiling
-// curl http://localhost:9000/debug/pprof/heap > heap.out
-// curl http://localhost:9000/debug/pprof/goroutine > goroutine.out
-// go tool pprof -http=:8080 heap.out
-// go tool pprof heap.out
+// set pprof.enabled = true in the config to serve pprof debug endpoints on localhost. See initPprof
 
 import (
 	"fmt"
@@ -30,7 +24,10 @@ func (s *Service) initPrometheus() {
 
 	srvUp.Set(1)
 
-	http.Handle("/metrics", promhttp.Handler())
+	// own mux, so that the pprof handlers registered on the default mux are never exposed here
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	s.initHealth(mux)
 
 	promListen := fmt.Sprintf(":%s", viper.GetString(config.PrometheusPort))
 
@@ -40,5 +37,5 @@ func (s *Service) initPrometheus() {
 		"listen":   promListen,
 	}).Info("initialise prometheus")
 
-	http.ListenAndServe(promListen, nil)
+	http.ListenAndServe(promListen, mux)
 }
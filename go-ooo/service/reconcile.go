@@ -0,0 +1,67 @@
+package service
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+// defaultReconciliationsLimit - reconciliation reports returned, if the limit query param is not set
+const defaultReconciliationsLimit = 10
+
+type reconcileRequest struct {
+	Fix bool `json:"fix"`
+}
+
+// GetReconciliations returns the most recent reconciliation reports, up to the limit query param
+func (s *Service) GetReconciliations(c echo.Context) error {
+	limit := defaultReconciliationsLimit
+	if l := c.QueryParam("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, "limit must be a positive integer")
+		}
+		limit = n
+	}
+
+	reports, err := s.oooRouterService.GetReconciliations(limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, reports)
+}
+
+// GetStateSnapshot returns the state saved on the last clean shutdown, and its verification
+func (s *Service) GetStateSnapshot(c echo.Context) error {
+	snapshot, err := s.oooRouterService.GetStateSnapshot()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	if snapshot.SavedAt == 0 {
+		return c.JSON(http.StatusNotFound, "no state snapshot saved")
+	}
+	return c.JSON(http.StatusOK, snapshot)
+}
+
+// Reconcile runs a reconciliation of the db against on-chain state now, correcting the
+// requests found if fix is set in the body, and returns the report
+func (s *Service) Reconcile(c echo.Context) error {
+	if !s.isLeader() {
+		return c.JSON(http.StatusServiceUnavailable, "standby instance - reconcile on the leader")
+	}
+
+	var request reconcileRequest
+	if c.Request().ContentLength != 0 {
+		if err := json.NewDecoder(c.Request().Body).Decode(&request); err != nil {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	report, err := s.oooRouterService.Reconcile(request.Fix)
+	s.audit(c, "reconcile", "", request, err)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-ooo/redact"
+	"net/http"
+)
+
+// redactErrors masks secrets in error responses, since they often include an upstream
+// error, e.g. from an RPC or the database, which may quote its url or DSN
+func redactErrors(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		res := c.Response()
+		res.Writer = &redactWriter{ResponseWriter: res.Writer}
+
+		err := next(c)
+		if he, ok := err.(*echo.HTTPError); ok {
+			if msg, ok := he.Message.(string); ok {
+				he.Message = redact.String(msg)
+			}
+		}
+		return err
+	}
+}
+
+// redactWriter masks secrets in the body of responses with an error status
+type redactWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *redactWriter) WriteHeader(code int) {
+	w.status = code
+	if code >= http.StatusBadRequest {
+		// the body may shrink when masked
+		w.Header().Del(echo.HeaderContentLength)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *redactWriter) Write(b []byte) (int, error) {
+	if w.status < http.StatusBadRequest {
+		return w.ResponseWriter.Write(b)
+	}
+	if _, err := w.ResponseWriter.Write([]byte(redact.String(string(b)))); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *redactWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
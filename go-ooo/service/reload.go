@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/redact"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// restartRequiredConfig - config sections and keys which are only read when the node starts,
+// e.g. connections, keys and listeners. Changes to them are reported, but not applied, until
+// the node is restarted
+var restartRequiredConfig = []string{
+	"chain.", "database.", "keystorage.", "signer.", "vault.", "serve.", "admin_api.", "price_api.", "prometheus.",
+	"pprof.", "ha.", "tracing.", "error_reporting.", "subchain.", "update_check.", "http.", "push_feeds.", "ipfs.", "cache.", "consensus.", "features.",
+	config.LogFormat, config.LogFile, config.LogMaxSize, config.LogMaxBackups, config.LogCompress,
+	config.JobsWorkers, config.JobsCheckDuration, config.JobsCheckDurationMax, config.JobsPairSourcesFile, config.JobsJsonFeeds, config.JobsTwapPools,
+	config.JobsOooApiUrl, config.JobsOooApiUrlSecondary, config.JobsForexApiUrl, config.JobsAnswerDecimals, config.JobsAnswerRounding,
+	config.JobsAdhocDMax, config.JobsExactMath, config.JobsCoalesceWindow, config.JobsMockSources, config.JobsMockPricesFile, config.Profile,
+	config.JobsPriceBounds, config.JobsCustomPairs,
+}
+
+func restartRequired(key string) bool {
+	for _, k := range restartRequiredConfig {
+		if key == k || (strings.HasSuffix(k, ".") && strings.HasPrefix(key, k)) ||
+			strings.HasPrefix(key, k+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// ReloadConfig re-reads the config file, without restarting or dropping in-flight work. Settings
+// read each time they are used, e.g. fee thresholds and retry settings, change straight away, and
+// those the service holds, e.g. alerts and webhooks, are re-applied. Settings which need a restart
+// keep their running values. If the file cannot be read, the running config is kept
+func (s *Service) ReloadConfig() (go_ooo_types.ConfigReload, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	res := go_ooo_types.ConfigReload{
+		Changed:         []string{},
+		RestartRequired: []string{},
+	}
+
+	if viper.ConfigFileUsed() == "" {
+		return res, errors.New("no config file in use")
+	}
+
+	// the running profile is kept, even if the file selects another
+	profile := viper.GetString(config.Profile)
+
+	fileConfig := viper.New()
+	fileConfig.SetConfigFile(viper.ConfigFileUsed())
+	config.BindEnv(fileConfig)
+	if err := config.ReadInConfig(fileConfig, profile); err != nil {
+		return res, fmt.Errorf("cannot read %s: %s", viper.ConfigFileUsed(), err.Error())
+	}
+	redact.AddConfigSecrets(fileConfig)
+
+	keys := make(map[string]bool)
+	for _, k := range viper.AllKeys() {
+		keys[k] = true
+	}
+	for _, k := range fileConfig.AllKeys() {
+		keys[k] = true
+	}
+
+	running := make(map[string]interface{})
+	for k := range keys {
+		// changes to profiles are reported as changes to the keys they set
+		if strings.HasPrefix(k, config.Profiles+".") || reflect.DeepEqual(viper.Get(k), fileConfig.Get(k)) {
+			continue
+		}
+		if restartRequired(k) {
+			running[k] = viper.Get(k)
+			res.RestartRequired = append(res.RestartRequired, k)
+		} else {
+			res.Changed = append(res.Changed, k)
+		}
+	}
+	sort.Strings(res.Changed)
+	sort.Strings(res.RestartRequired)
+
+	if err := config.ReadInConfig(viper.GetViper(), profile); err != nil {
+		return res, fmt.Errorf("cannot read %s: %s", viper.ConfigFileUsed(), err.Error())
+	}
+	// keep the running values until the node is restarted
+	for k, v := range running {
+		viper.Set(k, v)
+	}
+
+	for _, k := range res.Changed {
+		if k == config.LogLevel {
+			level, err := logrus.ParseLevel(viper.GetString(config.LogLevel))
+			if err != nil {
+				level = logrus.InfoLevel
+			}
+			s.logger.SetLevel(level)
+		}
+	}
+
+	err := s.oooRouterService.ReloadConfig()
+
+	s.logger.WithFields(logrus.Fields{
+		"package":          "service",
+		"function":         "ReloadConfig",
+		"changed":          strings.Join(res.Changed, ","),
+		"restart_required": strings.Join(res.RestartRequired, ","),
+	}).Info("config reloaded")
+
+	if len(res.RestartRequired) > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "ReloadConfig",
+		}).Warn(fmt.Sprintf("restart the node to apply changes to %s", strings.Join(res.RestartRequired, ", ")))
+	}
+
+	return res, err
+}
+
+// ReloadConfigHandler reloads the config file, as on SIGHUP
+func (s *Service) ReloadConfigHandler(c echo.Context) error {
+	res, err := s.ReloadConfig()
+	s.audit(c, "reload_config", "", res, err)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, res)
+}
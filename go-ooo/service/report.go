@@ -0,0 +1,180 @@
+package service
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/labstack/echo/v4"
+	"go-ooo/database/models"
+	"go-ooo/ooo_api"
+	go_ooo_types "go-ooo/types"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// GetEarningsReport summarises fees earned and gas spent on fulfillments between the from and to
+// unix timestamps, overall and by pair and consumer, with the operator's tags for each. If
+// xfund_price (in ETH) is given, the net margin is also calculated
+func (s *Service) GetEarningsReport(c echo.Context) error {
+	from, to, ok := reportPeriod(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, "from must be before to")
+	}
+
+	xfundPrice, _ := strconv.ParseFloat(c.QueryParam("xfund_price"), 64)
+
+	jobs, err := s.db.GetFulfilledRequestsBetween(from, to)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	idx, err := s.loadMetadata()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	report := buildEarningsReport(jobs, from, to, xfundPrice)
+	for i := range report.Pairs {
+		report.Pairs[i].Tags = idx.pairTags(report.Pairs[i].Key)
+	}
+	for i := range report.Consumers {
+		report.Consumers[i].Tags = idx.consumerTags(report.Consumers[i].Key)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// reportPeriod returns the from and to unix timestamp query params, defaulting to the 30 days up
+// to now. ok is false if from is not before to
+func reportPeriod(c echo.Context) (from time.Time, to time.Time, ok bool) {
+	to = time.Now()
+	if t, err := strconv.ParseInt(c.QueryParam("to"), 10, 64); err == nil && t > 0 {
+		to = time.Unix(t, 0)
+	}
+
+	from = to.Add(-30 * 24 * time.Hour)
+	if f, err := strconv.ParseInt(c.QueryParam("from"), 10, 64); err == nil && f > 0 {
+		from = time.Unix(f, 0)
+	}
+
+	return from, to, from.Before(to)
+}
+
+type earningsAcc struct {
+	num  uint64
+	fees *big.Int
+	gas  *big.Int
+}
+
+func (a *earningsAcc) add(job models.DataRequests) {
+	a.num++
+	a.fees.Add(a.fees, new(big.Int).SetUint64(job.GetFee()))
+	a.gas.Add(a.gas, new(big.Int).Mul(
+		new(big.Int).SetUint64(job.GetFulfillGasUsed()),
+		new(big.Int).SetUint64(job.GetFulfillGasPrice()),
+	))
+}
+
+func (a *earningsAcc) row(key string, xfundPrice float64) go_ooo_types.EarningsReportRow {
+	fees := xfundFloat(a.fees)
+	gas, _ := new(big.Float).Quo(new(big.Float).SetInt(a.gas), big.NewFloat(params.Ether)).Float64()
+
+	row := go_ooo_types.EarningsReportRow{
+		Key:          key,
+		NumFulfilled: a.num,
+		FeesXfund:    fees,
+		GasSpentEth:  gas,
+	}
+
+	if xfundPrice > 0 {
+		row.FeesEth = fees * xfundPrice
+		row.NetMarginEth = row.FeesEth - gas
+	}
+
+	return row
+}
+
+func newEarningsAcc() *earningsAcc {
+	return &earningsAcc{fees: big.NewInt(0), gas: big.NewInt(0)}
+}
+
+func buildEarningsReport(jobs []models.DataRequests, from time.Time, to time.Time, xfundPrice float64) go_ooo_types.EarningsReport {
+	total := newEarningsAcc()
+	pairs := make(map[string]*earningsAcc)
+	consumers := make(map[string]*earningsAcc)
+	daily := make(map[string]*earningsAcc)
+
+	for _, j := range jobs {
+		total.add(j)
+
+		pair := reportPair(j)
+		if _, ok := pairs[pair]; !ok {
+			pairs[pair] = newEarningsAcc()
+		}
+		pairs[pair].add(j)
+
+		if _, ok := consumers[j.GetConsumer()]; !ok {
+			consumers[j.GetConsumer()] = newEarningsAcc()
+		}
+		consumers[j.GetConsumer()].add(j)
+
+		day := fulfilledAt(j).UTC().Format("2006-01-02")
+		if _, ok := daily[day]; !ok {
+			daily[day] = newEarningsAcc()
+		}
+		daily[day].add(j)
+	}
+
+	days := earningsRows(daily, xfundPrice)
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Key < days[j].Key
+	})
+
+	return go_ooo_types.EarningsReport{
+		From:          from.Unix(),
+		To:            to.Unix(),
+		XfundPriceEth: xfundPrice,
+		Total:         total.row("", xfundPrice),
+		Pairs:         earningsRows(pairs, xfundPrice),
+		Consumers:     earningsRows(consumers, xfundPrice),
+		Daily:         days,
+	}
+}
+
+// reportPair returns the pair a request is for, e.g. BTC.USD, or its endpoint if it cannot be
+// parsed
+func reportPair(job models.DataRequests) string {
+	pair := job.GetEndpointDecoded()
+	if base, target, _, _, _, _, _, err := ooo_api.ParseEndpoint(pair); err == nil {
+		pair = fmt.Sprintf("%s.%s", base, target)
+	}
+	return pair
+}
+
+// fulfilledAt returns when a fulfillment was mined, or for requests fulfilled before mined
+// times were recorded, when it was last updated
+func fulfilledAt(job models.DataRequests) time.Time {
+	if job.GetTxMinedAt() > 0 {
+		return time.Unix(0, job.GetTxMinedAt()*int64(time.Millisecond))
+	}
+	return job.UpdatedAt
+}
+
+// earningsRows returns a row for each key, most fulfillments first
+func earningsRows(accs map[string]*earningsAcc, xfundPrice float64) []go_ooo_types.EarningsReportRow {
+	rows := make([]go_ooo_types.EarningsReportRow, 0, len(accs))
+	for key, acc := range accs {
+		rows = append(rows, acc.row(key, xfundPrice))
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].NumFulfilled != rows[j].NumFulfilled {
+			return rows[i].NumFulfilled > rows[j].NumFulfilled
+		}
+		return rows[i].Key < rows[j].Key
+	})
+
+	return rows
+}
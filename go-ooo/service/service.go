@@ -6,9 +6,12 @@ import (
 	"github.com/spf13/viper"
 	"go-ooo/chain"
 	"go-ooo/config"
+	"go-ooo/credentials"
 	"go-ooo/database"
 	"go-ooo/ooo_api"
 	go_ooo_types "go-ooo/types"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -16,6 +19,8 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"go-ooo/ooo_router"
+	"go-ooo/signer"
+	"go-ooo/supervisor"
 )
 
 type Service struct {
@@ -23,14 +28,30 @@ type Service struct {
 	client            *ethclient.Client
 	contractInstance  *ooo_router.OooRouter
 	logger            *logrus.Logger
-	db                *database.DB
+	db                database.Store
 	ctx               context.Context
 	jobTicker         *time.Ticker // periodic jobTicker
+	jobPoll           *jobPoll
 	updatePairsTicker *time.Ticker
+	apiHealthTicker   *time.Ticker
+	liquidityTicker   *time.Ticker
+	watchdogTicker    *time.Ticker
+	pushFeedTicker    *time.Ticker
+	leaderTicker      *time.Ticker
+	snapshotTicker    *time.Ticker
 	oooRouterService  *chain.OoORouterService
 
+	// active/standby leader election. leaderLock is nil if HA mode is disabled
+	leaderLock *database.LeaderLock
+	leader     uint32
+
 	echoService *echo.Echo
-	oooApi      *ooo_api.OOOApi
+	adminEcho   *echo.Echo
+	priceEcho   *echo.Echo
+	priceCache  priceCache
+	// serves the node's observations to its consensus peers
+	consensusEcho *echo.Echo
+	oooApi        *ooo_api.OOOApi
 
 	adminTasks     chan go_ooo_types.AdminTask
 	adminTasksResp chan go_ooo_types.AdminTaskResponse
@@ -40,10 +61,32 @@ type Service struct {
 	analyticsTasksResp chan go_ooo_types.AnalyticsTaskResponse
 
 	authToken string
+
+	// result of the last update check, if enabled
+	updateCheck *updateCheck
+
+	// serialises config reloads
+	reloadMu sync.Mutex
+
+	// ledger is set if fulfillments are signed with a Ledger, for approving held txs
+	ledger *signer.LedgerSigner
+
+	// recovers and reports panics in the service's goroutines, restarting long-lived ones
+	supervisor *supervisor.Supervisor
+
+	// API keys used by the data source adapters
+	credentials *credentials.Manager
+
+	// the active maintenance window, if the node is in maintenance mode
+	maintenance *maintenanceState
+
+	// unix time the database was last vacuumed, and 1 while it is being vacuumed
+	dbVacuumedAt int64
+	dbVacuuming  int32
 }
 
-func NewService(ctx context.Context, logger *logrus.Logger, oraclePrivateKey []byte,
-	db *database.DB, authToken string) (*Service, error) {
+func NewService(ctx context.Context, logger *logrus.Logger, signers chain.Signers, db database.Store,
+	authToken string, sup *supervisor.Supervisor, creds *credentials.Manager) (*Service, error) {
 	contractAddress := common.HexToAddress(viper.GetString(config.ChainContractAddress))
 	client, err := ethclient.Dial(viper.GetString(config.ChainEthWsHost))
 
@@ -51,34 +94,26 @@ func NewService(ctx context.Context, logger *logrus.Logger, oraclePrivateKey []b
 		return nil, err
 	}
 
-	var pollInterval = time.Duration(30)
-	checkDuration := viper.GetInt64(config.JobsCheckDuration)
-	if checkDuration != 0 {
-		pollInterval = time.Duration(checkDuration)
-	}
-
-	if err != nil {
-		return nil, err
-	}
+	jobPoll := newJobPoll()
 
 	oooRouterInstance, err := ooo_router.NewOooRouter(contractAddress, client)
 	if err != nil {
 		return nil, err
 	}
 
-	oooApi, err := ooo_api.NewApi(ctx, db, logger)
+	oooApi, err := ooo_api.NewApi(ctx, db, logger, creds, sup)
 
 	if err != nil {
 		return nil, err
 	}
 
-	oooRouterService, err := chain.NewOoORouter(ctx, logger, client, oooRouterInstance, contractAddress, oraclePrivateKey, db, oooApi)
+	oooRouterService, err := chain.NewOoORouter(ctx, logger, client, oooRouterInstance, contractAddress, signers, db, oooApi, sup)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &Service{
+	s := &Service{
 		ctx:              ctx,
 		client:           client,
 		contractAddress:  contractAddress,
@@ -86,64 +121,166 @@ func NewService(ctx context.Context, logger *logrus.Logger, oraclePrivateKey []b
 		logger:           logger,
 		db:               db,
 		// https://stackoverflow.com/questions/16903348/scheduled-polling-task-in-go
-		jobTicker:          time.NewTicker(time.Second * pollInterval),
+		jobTicker:          time.NewTicker(jobPoll.min),
+		jobPoll:            jobPoll,
 		updatePairsTicker:  time.NewTicker(time.Minute * 30),
+		apiHealthTicker:    time.NewTicker(time.Minute),
+		liquidityTicker:    time.NewTicker(time.Minute * 10),
+		watchdogTicker:     time.NewTicker(time.Minute),
+		pushFeedTicker:     time.NewTicker(pushFeedCheckInterval()),
+		leaderTicker:       time.NewTicker(leaderCheckInterval()),
+		snapshotTicker:     newSnapshotTicker(),
 		oooRouterService:   oooRouterService,
+		supervisor:         sup,
+		credentials:        creds,
 		adminTasks:         make(chan go_ooo_types.AdminTask),
 		adminTasksResp:     make(chan go_ooo_types.AdminTaskResponse),
 		analyticsTasks:     make(chan go_ooo_types.AnalyticsTask),
 		analyticsTasksResp: make(chan go_ooo_types.AnalyticsTaskResponse),
 		echoService:        echo.New(),
+		adminEcho:          echo.New(),
+		priceEcho:          echo.New(),
+		consensusEcho:      echo.New(),
 		oooApi:             oooApi,
 		authToken:          authToken,
-	}, nil
+		maintenance:        &maintenanceState{},
+	}
+
+	// maintenance mode persists across restarts, e.g. during the planned work
+	logger.AddHook(maintenanceHook{state: s.maintenance})
+	s.checkMaintenance()
+
+	// this month's subgraph spend, so that the budget holds across restarts
+	oooApi.FlushSubgraphSpend()
+
+	if viper.GetBool(config.UpdateCheckEnabled) {
+		s.updateCheck = &updateCheck{}
+	}
+
+	if ledger, ok := signers.Oracle.(*signer.LedgerSigner); ok {
+		s.ledger = ledger
+	}
+
+	err = s.initLeaderElection()
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
 }
 
 func (s *Service) Run() {
 
-	go func(s *Service) {
-		s.initEcho()
-	}(s)
+	if disabled := config.DisabledFeatures(); len(disabled) > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "Run",
+			"features": strings.Join(disabled, ", "),
+		}).Info("subsystems disabled by features config")
+	}
 
-	go func(s *Service) {
-		s.initPrometheus()
-	}(s)
+	go s.supervisor.Run(s.ctx, "api", s.initEcho)
+	go s.supervisor.Run(s.ctx, "prometheus", s.initPrometheus)
+	go s.supervisor.Run(s.ctx, "admin_api", s.initAdminApi)
+	go s.supervisor.Run(s.ctx, "price_api", s.initPriceApi)
+	go s.supervisor.Run(s.ctx, "consensus_api", s.initConsensusApi)
+	go s.supervisor.Run(s.ctx, "pprof", s.initPprof)
 
-	// update supported pairs from the Finchains API
-	go func(s *Service) {
-		s.oooApi.UpdateSupportedPairs()
-		s.oooApi.UpdateDexTokensAndPairs()
-	}(s)
+	if s.updateCheck != nil {
+		go s.supervisor.Run(s.ctx, "update_check", s.runUpdateCheck)
+	}
 
-	// pick up from the last block we know about to process
-	// any historical events missed. This will run and complete
-	// before the event subscriptions initialise in order to
-	// process any potentially missed and/or processed requests
-	s.oooRouterService.GetHistoricalEvents()
+	if s.leaderLock == nil {
+		s.becomeLeader()
+	} else {
+		// standby until the leader lock can be acquired
+		s.checkLeadership()
+	}
 
-	go func(s *Service) {
-		s.oooRouterService.RunEventWatchers()
-	}(s)
+	s.supervisor.Run(s.ctx, "poller", s.poll)
+}
 
+// poll runs the job poller and the sync schedulers, and serialises admin and analytics tasks
+func (s *Service) poll() {
 	for {
 		select {
+		case <-s.leaderTicker.C:
+			s.checkLeadership()
 		case <-s.jobTicker.C:
-			s.oooRouterService.ProcessPendingJobQueue()
+			if s.isLeader() {
+				s.oooRouterService.CheckBackpressure()
+				s.oooRouterService.CheckRouterConditions(false)
+				pending := s.oooRouterService.ProcessPendingJobQueue()
+				pending += s.oooRouterService.ProcessPendingVorRequests()
+				s.adjustJobTicker(pending > 0)
+			}
+		case <-s.oooRouterService.JobActivity():
+			s.adjustJobTicker(true)
 		case <-s.updatePairsTicker.C:
-			go func(s *Service) {
-				s.oooApi.UpdateSupportedPairs()
-				s.oooApi.UpdateDexTokensAndPairs()
-			}(s)
+			if s.isLeader() {
+				s.supervisor.Go("pair_sync", s.syncPairs)
+			}
+		case <-s.apiHealthTicker.C:
+			s.supervisor.Go("finchains_health", s.oooApi.CheckFinchainsHealth)
+			s.supervisor.Go("chain_metrics", s.oooRouterService.UpdateChainMetrics)
+			s.supervisor.Go("subgraph_health", s.checkSubgraphs)
+			s.supervisor.Go("credential_usage", s.credentials.Flush)
+			s.supervisor.Go("subgraph_spend", s.oooApi.FlushSubgraphSpend)
+			if s.isLeader() {
+				s.supervisor.Go("alerts", s.checkAlerts)
+				s.supervisor.Go("fee_schedule", s.oooRouterService.CheckFeeSchedule)
+			}
+		case <-s.liquidityTicker.C:
+			if s.isLeader() {
+				s.supervisor.Go("liquidity", s.oooApi.CheckActivePairLiquidity)
+			}
+		case <-s.watchdogTicker.C:
+			s.supervisor.Go("maintenance", s.checkMaintenance)
+			if s.isLeader() {
+				s.supervisor.Go("watchdog", s.oooRouterService.RunStuckJobWatchdog)
+				s.supervisor.Go("reconcile", s.oooRouterService.ReconcileIfDue)
+				s.supervisor.Go("db_vacuum", s.VacuumDbIfDue)
+			}
+		case <-s.pushFeedTicker.C:
+			if s.isLeader() && s.oooRouterService.PushFeedsEnabled() {
+				s.supervisor.Go("push_feeds", s.oooRouterService.CheckPushFeeds)
+			}
+		case <-s.snapshotTicker.C:
+			if s.isLeader() {
+				s.supervisor.Go("metric_snapshot", s.oooRouterService.SnapshotMetrics)
+			}
 		case t := <-s.analyticsTasks:
 			s.analyticsTasksResp <- s.ProcessAnalyticsTask(t)
 		case t := <-s.adminTasks:
 			// At any time we can process a request to add a new admin task
 			// such as changing fees etc.
+			if !s.isLeader() {
+				s.adminTasksResp <- go_ooo_types.AdminTaskResponse{
+					AdminTask: t,
+					Error:     "standby instance - send admin tasks to the leader",
+				}
+				continue
+			}
 			s.adminTasksResp <- s.oooRouterService.ProcessAdminTask(t)
 		}
 	}
 }
 
+// syncPairs updates the supported pairs from the Finchains API, then the DEX tokens and pairs
+func (s *Service) syncPairs() {
+	s.oooApi.UpdateSupportedPairs()
+	s.oooApi.UpdateDexTokensAndPairs()
+}
+
+// Replay re-processes events from fromBlock and reconciles request statuses, without starting the service
+func (s *Service) Replay(fromBlock uint64) (chain.ReplaySummary, error) {
+	return s.oooRouterService.Replay(fromBlock)
+}
+
+func (s *Service) Backtest(fromBlock uint64, toBlock uint64) (chain.BacktestSummary, error) {
+	return s.oooRouterService.Backtest(fromBlock, toBlock)
+}
+
 func (s *Service) Stop() {
 	// clean up and shut down
 	s.logger.WithFields(logrus.Fields{
@@ -160,6 +297,55 @@ func (s *Service) Stop() {
 
 	s.updatePairsTicker.Stop()
 
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "Stop",
+	}).Info("shutting down apiHealthTicker")
+
+	s.apiHealthTicker.Stop()
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "Stop",
+	}).Info("shutting down liquidityTicker")
+
+	s.liquidityTicker.Stop()
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "Stop",
+	}).Info("shutting down watchdogTicker")
+
+	s.watchdogTicker.Stop()
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "Stop",
+	}).Info("shutting down pushFeedTicker")
+
+	s.pushFeedTicker.Stop()
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "Stop",
+	}).Info("shutting down leaderTicker")
+
+	s.leaderTicker.Stop()
+	s.snapshotTicker.Stop()
+
+	// usage counted since the last flush is saved, so that quotas hold across restarts
+	s.credentials.Flush()
+	s.oooApi.FlushSubgraphSpend()
+
+	if s.isLeader() && chain.MetricsSnapshotInterval() > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "Stop",
+		}).Info("saving metric snapshots")
+
+		s.oooRouterService.SnapshotMetrics()
+	}
+
 	s.logger.WithFields(logrus.Fields{
 		"package":  "service",
 		"function": "Stop",
@@ -167,6 +353,16 @@ func (s *Service) Stop() {
 
 	s.oooRouterService.Shutdown()
 
+	if s.isLeader() {
+		// for the next node to start processing, on this host or another, to verify
+		s.oooRouterService.SaveStateSnapshot()
+	}
+
+	if s.leaderLock != nil {
+		// hand over to the standby as soon as possible
+		_ = s.leaderLock.Release(s.ctx)
+	}
+
 	s.logger.WithFields(logrus.Fields{
 		"package":  "service",
 		"function": "Stop",
@@ -189,4 +385,66 @@ func (s *Service) Stop() {
 			"function": "Stop",
 		}).Error(err.Error())
 	}
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "Stop",
+	}).Info("shutting down admin api")
+
+	err = s.adminEcho.Close()
+
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "Stop",
+		}).Error(err.Error())
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "Stop",
+	}).Info("shutting down price api")
+
+	err = s.priceEcho.Close()
+
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "Stop",
+		}).Error(err.Error())
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "Stop",
+	}).Info("shutting down consensus api")
+
+	err = s.consensusEcho.Close()
+
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "Stop",
+		}).Error(err.Error())
+	}
+}
+
+// newSnapshotTicker returns the ticker for saving the lifetime counters. It never fires if
+// snapshots are disabled
+func newSnapshotTicker() *time.Ticker {
+	interval := chain.MetricsSnapshotInterval()
+	if interval <= 0 {
+		t := time.NewTicker(time.Hour)
+		t.Stop()
+		return t
+	}
+	return time.NewTicker(interval)
+}
+
+func pushFeedCheckInterval() time.Duration {
+	interval := viper.GetInt64(config.PushFeedsCheckInterval)
+	if interval <= 0 {
+		interval = 60
+	}
+	return time.Duration(interval) * time.Second
 }
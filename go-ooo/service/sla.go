@@ -0,0 +1,207 @@
+package service
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/labstack/echo/v4"
+	"go-ooo/chain"
+	"go-ooo/database/models"
+	go_ooo_types "go-ooo/types"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GetSlaReport returns each consumer's service level for the requests received between the from
+// and to unix timestamps - the percentage fulfilled on time, the average latency and why the rest
+// were not. Requests are on time if fulfilled within the deadline_blocks param, which defaults to
+// the blocks after which a request is abandoned. If the consumer param is given, only that
+// consumer is reported, and if the tag param is, only consumers with the tag
+func (s *Service) GetSlaReport(c echo.Context) error {
+	from, to, ok := reportPeriod(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, "from must be before to")
+	}
+
+	deadline := uint64(chain.RequestMaxAge)
+	if d := c.QueryParam("deadline_blocks"); d != "" {
+		n, err := strconv.ParseUint(d, 10, 64)
+		if err != nil || n == 0 {
+			return c.JSON(http.StatusBadRequest, "deadline_blocks must be a positive integer")
+		}
+		deadline = n
+	}
+
+	consumer := c.QueryParam("consumer")
+	if consumer != "" {
+		if !common.IsHexAddress(consumer) {
+			return c.JSON(http.StatusBadRequest, "consumer must be an address")
+		}
+		consumer = common.HexToAddress(consumer).Hex()
+	}
+
+	jobs, err := s.db.GetRequestsReceivedBetween(from, to)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	idx, err := s.loadMetadata()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	tag := strings.ToLower(c.QueryParam("tag"))
+
+	total := newSlaAcc()
+	consumers := make(map[string]*slaAcc)
+	for _, job := range jobs {
+		if consumer != "" && job.GetConsumer() != consumer {
+			continue
+		}
+		if tag != "" && !inTags(tag, idx.consumerTags(job.GetConsumer())) {
+			continue
+		}
+		if _, ok := consumers[job.GetConsumer()]; !ok {
+			consumers[job.GetConsumer()] = newSlaAcc()
+		}
+		consumers[job.GetConsumer()].add(job, deadline)
+		total.add(job, deadline)
+	}
+
+	report := go_ooo_types.SlaReport{
+		From:           from.Unix(),
+		To:             to.Unix(),
+		DeadlineBlocks: deadline,
+		Total:          total.sla(""),
+		Consumers:      make([]go_ooo_types.ConsumerSla, 0, len(consumers)),
+	}
+	for address, acc := range consumers {
+		report.Consumers = append(report.Consumers, acc.sla(address))
+	}
+
+	// worst service first
+	sort.Slice(report.Consumers, func(i, j int) bool {
+		a, b := report.Consumers[i], report.Consumers[j]
+		if a.OnTimePercent != b.OnTimePercent {
+			return a.OnTimePercent < b.OnTimePercent
+		}
+		if a.NumRequests != b.NumRequests {
+			return a.NumRequests > b.NumRequests
+		}
+		return a.Consumer < b.Consumer
+	})
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// slaExcluded returns true if a request was not fulfilled for a reason of the consumer's own, so
+// does not count against the service level
+func slaExcluded(status int) bool {
+	switch status {
+	case models.REQUEST_STATUS_SKIPPED_LOW_FEE,
+		models.REQUEST_STATUS_SKIPPED_RATE_LIMIT,
+		models.REQUEST_STATUS_REJECTED,
+		models.REQUEST_STATUS_SKIPPED_GAS_LIMIT,
+		models.REQUEST_STATUS_DUPLICATE:
+		return true
+	}
+	return false
+}
+
+type slaAcc struct {
+	requests  uint64
+	onTime    uint64
+	late      uint64
+	failed    uint64
+	excluded  uint64
+	latencyMs int64
+	numTimed  int64
+	blocks    uint64
+	numBlocks uint64
+	reasons   map[string]*go_ooo_types.SlaFailureReason
+}
+
+func (a *slaAcc) add(job models.DataRequests, deadline uint64) {
+	a.requests++
+
+	status := job.GetRequestStatus()
+	switch {
+	case status == models.REQUEST_STATUS_SUCCESS:
+		if job.GetTxMinedAt() > 0 {
+			a.latencyMs += job.GetTxMinedAt() - job.CreatedAt.UnixNano()/1e6
+			a.numTimed++
+		}
+
+		fulfillBlock := job.GetFulfillBlockNumber()
+		// manual fulfillments record no fulfillment block, so cannot be shown to be on time
+		if fulfillBlock == 0 || fulfillBlock < job.GetRequestBlockNumber() {
+			a.late++
+			break
+		}
+		blocks := fulfillBlock - job.GetRequestBlockNumber()
+		a.blocks += blocks
+		a.numBlocks++
+		if blocks <= deadline {
+			a.onTime++
+		} else {
+			a.late++
+		}
+	case slaExcluded(status):
+		a.excluded++
+		a.reason(job, true)
+	case models.IsFailedFinalRequestStatus(status):
+		a.failed++
+		a.reason(job, false)
+	}
+}
+
+func (a *slaAcc) reason(job models.DataRequests, excluded bool) {
+	reason := job.GetRequestStatusString()
+	if job.GetRejectionCode() != "" {
+		reason += ": " + job.GetRejectionCode()
+	} else if job.GetErrorCode() != "" {
+		reason += ": " + job.GetErrorCode()
+	}
+	if _, ok := a.reasons[reason]; !ok {
+		a.reasons[reason] = &go_ooo_types.SlaFailureReason{Reason: reason, Excluded: excluded}
+	}
+	a.reasons[reason].Count++
+}
+
+func (a *slaAcc) sla(consumer string) go_ooo_types.ConsumerSla {
+	sla := go_ooo_types.ConsumerSla{
+		Consumer:       consumer,
+		NumRequests:    a.requests,
+		NumOnTime:      a.onTime,
+		NumLate:        a.late,
+		NumFailed:      a.failed,
+		NumExcluded:    a.excluded,
+		NumPending:     a.requests - a.onTime - a.late - a.failed - a.excluded,
+		FailureReasons: make([]go_ooo_types.SlaFailureReason, 0, len(a.reasons)),
+	}
+
+	if counted := a.onTime + a.late + a.failed; counted > 0 {
+		sla.OnTimePercent = float64(a.onTime) / float64(counted) * 100
+	}
+	if a.numTimed > 0 {
+		sla.AvgLatencyMs = a.latencyMs / a.numTimed
+	}
+	if a.numBlocks > 0 {
+		sla.AvgLatencyBlocks = float64(a.blocks) / float64(a.numBlocks)
+	}
+
+	for _, r := range a.reasons {
+		sla.FailureReasons = append(sla.FailureReasons, *r)
+	}
+	sort.Slice(sla.FailureReasons, func(i, j int) bool {
+		if sla.FailureReasons[i].Count != sla.FailureReasons[j].Count {
+			return sla.FailureReasons[i].Count > sla.FailureReasons[j].Count
+		}
+		return sla.FailureReasons[i].Reason < sla.FailureReasons[j].Reason
+	})
+
+	return sla
+}
+
+func newSlaAcc() *slaAcc {
+	return &slaAcc{reasons: make(map[string]*go_ooo_types.SlaFailureReason)}
+}
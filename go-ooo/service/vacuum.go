@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/config"
+	"go-ooo/database"
+	"go-ooo/schedule"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// dbVacuumMinInterval - the nightly vacuum is skipped if the database was vacuumed more recently
+const dbVacuumMinInterval = 12 * time.Hour
+
+// dbVacuumTimeout - time allowed for vacuuming every table
+const dbVacuumTimeout = time.Hour
+
+var (
+	dbVacuumDuration = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ooo_db_vacuum_duration_seconds",
+		Help: "Time taken by the last scheduled vacuum and analyze of the database",
+	})
+
+	dbVacuumLastSuccess = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ooo_db_vacuum_last_success_timestamp",
+		Help: "Unix time the database was last vacuumed and analysed successfully",
+	})
+
+	dbVacuumErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "ooo_db_vacuum_errors_total",
+		Help: "Number of scheduled vacuums of the database which failed",
+	})
+)
+
+// VacuumDbIfDue vacuums and analyses database.maintenance_tables, if it is
+// database.maintenance_hour and they have not been vacuumed in the last 12 hours. If the vacuum is
+// confined to maintenance windows, a vacuum due at the hour waits for the next window
+func (s *Service) VacuumDbIfDue() {
+	hour := viper.GetInt(config.DatabaseMaintenanceHour)
+	if !viper.IsSet(config.DatabaseMaintenanceHour) || hour < 0 {
+		return
+	}
+
+	vacuumedAt := time.Unix(atomic.LoadInt64(&s.dbVacuumedAt), 0)
+	if time.Since(vacuumedAt) < dbVacuumMinInterval {
+		return
+	}
+	if !schedule.DueDaily(schedule.TaskDbVacuum, hour, vacuumedAt) {
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&s.dbVacuuming, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&s.dbVacuuming, 0)
+
+	tables := database.MaintenanceTables()
+	logger := s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "VacuumDbIfDue",
+		"tables":   strings.Join(tables, ","),
+	})
+	logger.Info("vacuuming database")
+
+	deadline := time.Now().Add(dbVacuumTimeout)
+	if closes, ok := schedule.ClosesAt(schedule.TaskDbVacuum, time.Now()); ok && closes.Before(deadline) {
+		// stopped at the end of its maintenance window rather than overrunning it
+		deadline = closes
+	}
+	ctx, cancel := context.WithDeadline(s.ctx, deadline)
+	defer cancel()
+
+	start := time.Now()
+	err := s.db.Vacuum(ctx, tables)
+	dbVacuumDuration.Set(time.Since(start).Seconds())
+	// a failed run is not retried until the next night, as it would likely fail again
+	atomic.StoreInt64(&s.dbVacuumedAt, start.Unix())
+
+	if err != nil {
+		dbVacuumErrors.Inc()
+		logger.Error(err.Error())
+		return
+	}
+
+	dbVacuumLastSuccess.Set(float64(time.Now().Unix()))
+	logger.WithField("took", time.Since(start).Round(time.Millisecond).String()).Info("database vacuumed")
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go-ooo/alerts"
+	"go-ooo/config"
+	"go-ooo/httpclient"
+	go_ooo_types "go-ooo/types"
+	"go-ooo/version"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// updateCheck holds the result of the last check for a newer release
+type updateCheck struct {
+	mu        sync.Mutex
+	latest    version.Release
+	checkedAt time.Time
+	err       error
+}
+
+// runUpdateCheck checks the release endpoint for a newer version of go-ooo on start, then at
+// the configured interval
+func (s *Service) runUpdateCheck() {
+	interval := time.Duration(viper.GetInt64(config.UpdateCheckInterval)) * time.Hour
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.checkForUpdate()
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) checkForUpdate() {
+	url := viper.GetString(config.UpdateCheckUrl)
+	if url == "" {
+		url = version.DefaultReleaseUrl
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
+	defer cancel()
+
+	latest, err := version.LatestRelease(ctx, httpclient.New(0), url)
+
+	s.updateCheck.mu.Lock()
+	s.updateCheck.checkedAt = time.Now()
+	s.updateCheck.err = err
+	if err == nil {
+		s.updateCheck.latest = latest
+	}
+	s.updateCheck.mu.Unlock()
+
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"package":  "service",
+			"function": "checkForUpdate",
+			"url":      url,
+		}).Warn(fmt.Sprintf("update check failed: %s", err.Error()))
+		return
+	}
+
+	alerter := s.oooRouterService.Alerter()
+
+	if !version.IsNewer(latest.Version, version.Version) {
+		alerter.Resolve(alerts.AlertOutdatedVersion, "")
+		return
+	}
+
+	msg := fmt.Sprintf("go-ooo v%s is outdated - v%s has been released: %s", version.Version, latest.Version, latest.Url)
+
+	s.logger.WithFields(logrus.Fields{
+		"package":  "service",
+		"function": "checkForUpdate",
+		"current":  version.Version,
+		"latest":   latest.Version,
+	}).Warn(msg)
+
+	alerter.Alert(alerts.AlertOutdatedVersion, "", msg)
+}
+
+// GetVersion returns the node's build info, and whether a newer version has been released
+func (s *Service) GetVersion(c echo.Context) error {
+	res := go_ooo_types.VersionResponse{
+		Info: version.NewInfo(),
+	}
+
+	if s.updateCheck != nil {
+		res.UpdateCheckEnabled = true
+
+		s.updateCheck.mu.Lock()
+		if !s.updateCheck.checkedAt.IsZero() {
+			res.CheckedAt = s.updateCheck.checkedAt.Unix()
+		}
+		if s.updateCheck.err != nil {
+			res.CheckError = s.updateCheck.err.Error()
+		}
+		if s.updateCheck.latest.Version != "" {
+			res.LatestVersion = s.updateCheck.latest.Version
+			res.ReleaseUrl = s.updateCheck.latest.Url
+			res.UpdateAvailable = version.IsNewer(s.updateCheck.latest.Version, res.Version)
+		}
+		s.updateCheck.mu.Unlock()
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,261 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	imdsUrl            = "http://169.254.169.254"
+	ecsCredentialsHost = "http://169.254.170.2"
+	// credentialsExpiryMargin - instance credentials are refreshed this long before they expire
+	credentialsExpiryMargin = 5 * time.Minute
+)
+
+// awsCredentials for signing requests to AWS
+type awsCredentials struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+	// Expiration zero for static credentials
+	Expiration time.Time
+}
+
+// awsCredentialsProvider resolves credentials from, in order: the configured static keys, the
+// standard AWS_* environment variables, the ECS task role, or the EC2 instance role via IMDSv2
+type awsCredentialsProvider struct {
+	static awsCredentials
+	client *http.Client
+
+	mu     sync.Mutex
+	cached *awsCredentials
+}
+
+func newAwsCredentialsProvider(accessKeyId string, secretAccessKey string, sessionToken string,
+	client *http.Client) *awsCredentialsProvider {
+	p := &awsCredentialsProvider{client: client}
+
+	switch {
+	case accessKeyId != "" && secretAccessKey != "":
+		p.static = awsCredentials{AccessKeyId: accessKeyId, SecretAccessKey: secretAccessKey, SessionToken: sessionToken}
+	case os.Getenv("AWS_ACCESS_KEY_ID") != "" && os.Getenv("AWS_SECRET_ACCESS_KEY") != "":
+		p.static = awsCredentials{
+			AccessKeyId:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+	}
+
+	return p
+}
+
+func (p *awsCredentialsProvider) get(ctx context.Context) (awsCredentials, error) {
+	if p.static.AccessKeyId != "" {
+		return p.static, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cached != nil && time.Now().Add(credentialsExpiryMargin).Before(p.cached.Expiration) {
+		return *p.cached, nil
+	}
+
+	var creds awsCredentials
+	var err error
+	if uri := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); uri != "" {
+		creds, err = p.fetchRoleCredentials(ctx, ecsCredentialsHost+uri, "")
+	} else {
+		creds, err = p.instanceRoleCredentials(ctx)
+	}
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("no aws credentials configured, and none available from the instance role: %s", err.Error())
+	}
+
+	p.cached = &creds
+	return creds, nil
+}
+
+// instanceRoleCredentials gets the EC2 instance role's credentials from IMDSv2
+func (p *awsCredentialsProvider) instanceRoleCredentials(ctx context.Context) (awsCredentials, error) {
+	req, err := http.NewRequestWithContext(ctx, "PUT", imdsUrl+"/latest/api/token", nil)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+
+	token, err := p.do(req)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+
+	rolesUrl := imdsUrl + "/latest/meta-data/iam/security-credentials/"
+	req, err = http.NewRequestWithContext(ctx, "GET", rolesUrl, nil)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", string(token))
+
+	roles, err := p.do(req)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	role := strings.TrimSpace(strings.SplitN(string(roles), "\n", 2)[0])
+	if role == "" {
+		return awsCredentials{}, fmt.Errorf("instance has no iam role")
+	}
+
+	return p.fetchRoleCredentials(ctx, rolesUrl+role, string(token))
+}
+
+func (p *awsCredentialsProvider) fetchRoleCredentials(ctx context.Context, url string, imdsToken string) (awsCredentials, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	if imdsToken != "" {
+		req.Header.Set("X-aws-ec2-metadata-token", imdsToken)
+	}
+
+	body, err := p.do(req)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+
+	var res struct {
+		AccessKeyId     string
+		SecretAccessKey string
+		Token           string
+		Expiration      time.Time
+	}
+	if err = json.Unmarshal(body, &res); err != nil {
+		return awsCredentials{}, err
+	}
+	if res.AccessKeyId == "" {
+		return awsCredentials{}, fmt.Errorf("no credentials returned by %s", url)
+	}
+
+	return awsCredentials{
+		AccessKeyId:     res.AccessKeyId,
+		SecretAccessKey: res.SecretAccessKey,
+		SessionToken:    res.Token,
+		Expiration:      res.Expiration,
+	}, nil
+}
+
+func (p *awsCredentialsProvider) do(req *http.Request) ([]byte, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", req.URL.Path, resp.Status)
+	}
+	return body, nil
+}
+
+// signAwsRequest adds AWS Signature Version 4 headers to req, whose body is body
+func signAwsRequest(req *http.Request, body []byte, creds awsCredentials, region string, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSha256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSha256(key, region)
+	key = hmacSha256(key, service)
+	key = hmacSha256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyId, scope, signedHeaders, signature))
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// awsError is the error body returned by AWS JSON APIs
+type awsError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+func parseAwsError(status string, body []byte) error {
+	var e awsError
+	if err := json.Unmarshal(bytes.TrimSpace(body), &e); err != nil || e.Type == "" {
+		return fmt.Errorf("aws returned %s", status)
+	}
+	// __type may be namespaced, e.g. com.amazon.coral.service#AccessDeniedException
+	if i := strings.LastIndex(e.Type, "#"); i >= 0 {
+		e.Type = e.Type[i+1:]
+	}
+	return fmt.Errorf("%s: %s", e.Type, e.Message)
+}
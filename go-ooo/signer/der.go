@@ -0,0 +1,75 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+)
+
+// secp256k1Oid curve OID in a key's SubjectPublicKeyInfo
+var secp256k1Oid = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+// secp256k1HalfN - signatures with s above this are malleable, and rejected by Ethereum
+var secp256k1HalfN = new(big.Int).Rsh(crypto.S256().Params().N, 1)
+
+// parseSecp256k1PublicKey parses a DER encoded SubjectPublicKeyInfo, as returned by remote
+// signers. x509 can't be used as it doesn't support secp256k1
+func parseSecp256k1PublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var spki struct {
+		Algorithm struct {
+			Algorithm asn1.ObjectIdentifier
+			Curve     asn1.ObjectIdentifier
+		}
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(der, &spki); err != nil {
+		return nil, fmt.Errorf("cannot decode public key: %s", err.Error())
+	}
+	if !spki.Algorithm.Curve.Equal(secp256k1Oid) {
+		return nil, fmt.Errorf("public key is not on secp256k1")
+	}
+
+	return crypto.UnmarshalPubkey(spki.PublicKey.Bytes)
+}
+
+// derToEthSignature converts a DER encoded ECDSA signature of hash into Ethereum's
+// [R || S || V] format: s is normalised to the lower half of the curve order, and V found by
+// recovering the public key
+func derToEthSignature(hash []byte, der []byte, publicKey *ecdsa.PublicKey) ([]byte, error) {
+	var rs struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(der, &rs); err != nil {
+		return nil, fmt.Errorf("cannot decode signature: %s", err.Error())
+	}
+
+	s := rs.S
+	if s.Cmp(secp256k1HalfN) > 0 {
+		s = new(big.Int).Sub(crypto.S256().Params().N, s)
+	}
+
+	sig := make([]byte, 65)
+	rs.R.FillBytes(sig[0:32])
+	s.FillBytes(sig[32:64])
+
+	return sig, setRecoveryId(hash, sig, publicKey)
+}
+
+// setRecoveryId sets V in a [R || S || V] signature of hash to the value which recovers
+// publicKey
+func setRecoveryId(hash []byte, sig []byte, publicKey *ecdsa.PublicKey) error {
+	want := crypto.FromECDSAPub(publicKey)
+	for v := byte(0); v < 2; v++ {
+		sig[64] = v
+		recovered, err := crypto.Ecrecover(hash, sig)
+		if err == nil && bytes.Equal(recovered, want) {
+			return nil
+		}
+	}
+
+	return errors.New("signature does not recover to the key's public key")
+}
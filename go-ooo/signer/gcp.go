@@ -0,0 +1,190 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gcpMetadataTokenUrl = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	gcpDefaultTokenUrl  = "https://oauth2.googleapis.com/token"
+	gcpCloudKmsScope    = "https://www.googleapis.com/auth/cloudkms"
+)
+
+// gcpServiceAccount is the part of a service account key file needed to get access tokens
+type gcpServiceAccount struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyId string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenUri     string `json:"token_uri"`
+}
+
+// gcpTokenSource gets OAuth2 access tokens from, in order: the configured service account key
+// file, the file named by GOOGLE_APPLICATION_CREDENTIALS, or the GCE metadata server
+type gcpTokenSource struct {
+	client  *http.Client
+	account *gcpServiceAccount
+	key     *rsa.PrivateKey
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func newGcpTokenSource(credentialsFile string, client *http.Client) (*gcpTokenSource, error) {
+	ts := &gcpTokenSource{client: client}
+
+	if credentialsFile == "" {
+		credentialsFile = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if credentialsFile == "" {
+		return ts, nil
+	}
+
+	data, err := ioutil.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var account gcpServiceAccount
+	if err = json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %s", credentialsFile, err.Error())
+	}
+	if account.Type != "service_account" {
+		return nil, fmt.Errorf("%s is not a service account key", credentialsFile)
+	}
+	if account.TokenUri == "" {
+		account.TokenUri = gcpDefaultTokenUrl
+	}
+
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("%s has no private key", credentialsFile)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse private key in %s: %s", credentialsFile, err.Error())
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key in %s is not an RSA key", credentialsFile)
+	}
+
+	ts.account = &account
+	ts.key = key
+
+	return ts, nil
+}
+
+// get returns a cached access token, fetching a new one if it is about to expire
+func (ts *gcpTokenSource) get(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.token != "" && time.Now().Add(credentialsExpiryMargin).Before(ts.expires) {
+		return ts.token, nil
+	}
+
+	var req *http.Request
+	var err error
+	if ts.account != nil {
+		req, err = ts.serviceAccountTokenRequest(ctx)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, "GET", gcpMetadataTokenUrl, nil)
+		if err == nil {
+			req.Header.Set("Metadata-Flavor", "Google")
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := ts.client.Do(req)
+	if err != nil {
+		if ts.account == nil {
+			return "", fmt.Errorf("no gcp credentials configured, and the metadata server is unavailable: %s", err.Error())
+		}
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("cannot get gcp access token: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var res struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err = json.Unmarshal(body, &res); err != nil {
+		return "", err
+	}
+	if res.AccessToken == "" {
+		return "", errors.New("no gcp access token returned")
+	}
+
+	ts.token = res.AccessToken
+	ts.expires = time.Now().Add(time.Duration(res.ExpiresIn) * time.Second)
+
+	return ts.token, nil
+}
+
+// serviceAccountTokenRequest exchanges a JWT signed with the service account's key for an
+// access token
+func (ts *gcpTokenSource) serviceAccountTokenRequest(ctx context.Context) (*http.Request, error) {
+	now := time.Now()
+
+	header, _ := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": ts.account.PrivateKeyId,
+	})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   ts.account.ClientEmail,
+		"scope": gcpCloudKmsScope,
+		"aud":   ts.account.TokenUri,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, ts.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(sig)},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", ts.account.TokenUri, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return req, nil
+}
@@ -0,0 +1,201 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	gcpKmsDefaultEndpoint = "https://cloudkms.googleapis.com"
+	// gcpKmsAlgorithm - Cloud KMS keys must be secp256k1, the curve Ethereum uses
+	gcpKmsAlgorithm = "EC_SIGN_SECP256K1_SHA256"
+)
+
+// GcpKmsConfig configures the Google Cloud KMS signer backend
+type GcpKmsConfig struct {
+	// KeyVersion full resource name of an EC_SIGN_SECP256K1_SHA256 key version -
+	// projects/*/locations/*/keyRings/*/cryptoKeys/*/cryptoKeyVersions/*
+	KeyVersion string
+	// CredentialsFile optional service account key file. If unset, GOOGLE_APPLICATION_CREDENTIALS
+	// or the GCE metadata server is used
+	CredentialsFile string
+	// Endpoint optional, e.g. a Private Service Connect endpoint
+	Endpoint string
+	Timeout  time.Duration
+}
+
+// gcpKmsSigner signs with an asymmetric key held in Cloud KMS, or a Cloud HSM key. The private
+// key never leaves KMS
+type gcpKmsSigner struct {
+	cfg       GcpKmsConfig
+	client    *http.Client
+	tokens    *gcpTokenSource
+	ctx       context.Context
+	publicKey *ecdsa.PublicKey
+	address   common.Address
+}
+
+// NewGcpKmsSigner returns a Signer for the configured Cloud KMS key version, fetching its public
+// key to derive the oracle's address
+func NewGcpKmsSigner(ctx context.Context, cfg GcpKmsConfig) (Signer, error) {
+	if cfg.KeyVersion == "" {
+		return nil, errors.New("no cloud kms key version configured")
+	}
+	if !strings.Contains(cfg.KeyVersion, "/cryptoKeyVersions/") {
+		return nil, fmt.Errorf("%s is not a key version resource name", cfg.KeyVersion)
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = gcpKmsDefaultEndpoint
+	}
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	tokens, err := newGcpTokenSource(cfg.CredentialsFile, client)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &gcpKmsSigner{
+		cfg:    cfg,
+		client: client,
+		tokens: tokens,
+		ctx:    ctx,
+	}
+
+	publicKey, err := g.getPublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("cloud kms key %s: %s", cfg.KeyVersion, err.Error())
+	}
+
+	g.publicKey = publicKey
+	g.address = crypto.PubkeyToAddress(*publicKey)
+
+	return g, nil
+}
+
+func (g *gcpKmsSigner) Address() common.Address {
+	return g.address
+}
+
+func (g *gcpKmsSigner) PublicKey() *ecdsa.PublicKey {
+	return g.publicKey
+}
+
+func (g *gcpKmsSigner) SignText(text []byte) ([]byte, error) {
+	return g.SignHash(accounts.TextHash(text))
+}
+
+func (g *gcpKmsSigner) PrivateKey() (*ecdsa.PrivateKey, error) {
+	return nil, ErrNoPrivateKey
+}
+
+// SignHash has Cloud KMS sign the hash, and converts the signature to Ethereum's format. The
+// hash is passed as the sha256 digest - KMS signs the 32 bytes as given
+func (g *gcpKmsSigner) SignHash(hash []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("hash is required to be exactly 32 bytes (%d)", len(hash))
+	}
+
+	var res struct {
+		Signature []byte `json:"signature"`
+	}
+	err := g.call("POST", ":asymmetricSign", map[string]interface{}{
+		"digest": map[string][]byte{"sha256": hash},
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return derToEthSignature(hash, res.Signature, g.publicKey)
+}
+
+func (g *gcpKmsSigner) getPublicKey() (*ecdsa.PublicKey, error) {
+	var res struct {
+		Pem       string `json:"pem"`
+		Algorithm string `json:"algorithm"`
+	}
+	if err := g.call("GET", "/publicKey", nil, &res); err != nil {
+		return nil, err
+	}
+
+	if res.Algorithm != gcpKmsAlgorithm {
+		return nil, fmt.Errorf("key algorithm is %s, must be %s", res.Algorithm, gcpKmsAlgorithm)
+	}
+
+	block, _ := pem.Decode([]byte(res.Pem))
+	if block == nil {
+		return nil, errors.New("cannot decode public key pem")
+	}
+
+	return parseSecp256k1PublicKey(block.Bytes)
+}
+
+// call makes an authenticated request to the Cloud KMS REST API for the key version
+func (g *gcpKmsSigner) call(method string, suffix string, params interface{}, out interface{}) error {
+	ctx, cancel := context.WithTimeout(g.ctx, g.cfg.Timeout)
+	defer cancel()
+
+	token, err := g.tokens.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	if params != nil {
+		if body, err = json.Marshal(params); err != nil {
+			return err
+		}
+	}
+
+	url := fmt.Sprintf("%s/v1/%s%s", g.cfg.Endpoint, g.cfg.KeyVersion, suffix)
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if params != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error struct {
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(respBody, &e) == nil && e.Error.Message != "" {
+			return fmt.Errorf("%s: %s", e.Error.Status, e.Error.Message)
+		}
+		return fmt.Errorf("cloud kms returned %s", resp.Status)
+	}
+
+	return json.Unmarshal(respBody, out)
+}
@@ -0,0 +1,180 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// kmsKeySpec - KMS keys must be secp256k1, the curve Ethereum uses
+const kmsKeySpec = "ECC_SECG_P256K1"
+
+// KmsConfig configures the AWS KMS signer backend
+type KmsConfig struct {
+	// KeyId key id, ARN or alias of an ECC_SECG_P256K1 SIGN_VERIFY key
+	KeyId  string
+	Region string
+	// Endpoint optional, e.g. a VPC endpoint. Defaults to the public KMS endpoint for Region
+	Endpoint string
+	// AccessKeyId, SecretAccessKey and SessionToken are optional. If unset, credentials are taken
+	// from the environment or the instance/task role
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+	Timeout         time.Duration
+}
+
+// kmsSigner signs with an asymmetric key held in AWS KMS. The private key never leaves KMS
+type kmsSigner struct {
+	cfg       KmsConfig
+	client    *http.Client
+	creds     *awsCredentialsProvider
+	ctx       context.Context
+	publicKey *ecdsa.PublicKey
+	address   common.Address
+}
+
+// NewKmsSigner returns a Signer for the configured KMS key, fetching its public key to derive
+// the oracle's address
+func NewKmsSigner(ctx context.Context, cfg KmsConfig) (Signer, error) {
+	if cfg.KeyId == "" {
+		return nil, errors.New("no kms key id configured")
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("no aws region configured")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com/", cfg.Region)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	k := &kmsSigner{
+		cfg:    cfg,
+		client: client,
+		creds:  newAwsCredentialsProvider(cfg.AccessKeyId, cfg.SecretAccessKey, cfg.SessionToken, client),
+		ctx:    ctx,
+	}
+
+	publicKey, err := k.getPublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("kms key %s: %s", cfg.KeyId, err.Error())
+	}
+
+	k.publicKey = publicKey
+	k.address = crypto.PubkeyToAddress(*publicKey)
+
+	return k, nil
+}
+
+func (k *kmsSigner) Address() common.Address {
+	return k.address
+}
+
+func (k *kmsSigner) PublicKey() *ecdsa.PublicKey {
+	return k.publicKey
+}
+
+func (k *kmsSigner) SignText(text []byte) ([]byte, error) {
+	return k.SignHash(accounts.TextHash(text))
+}
+
+func (k *kmsSigner) PrivateKey() (*ecdsa.PrivateKey, error) {
+	return nil, ErrNoPrivateKey
+}
+
+// SignHash has KMS sign the hash, and converts the signature to Ethereum's format
+func (k *kmsSigner) SignHash(hash []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("hash is required to be exactly 32 bytes (%d)", len(hash))
+	}
+
+	var res struct {
+		Signature []byte
+	}
+	err := k.call("TrentService.Sign", map[string]interface{}{
+		"KeyId":            k.cfg.KeyId,
+		"Message":          hash,
+		"MessageType":      "DIGEST",
+		"SigningAlgorithm": "ECDSA_SHA_256",
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return derToEthSignature(hash, res.Signature, k.publicKey)
+}
+
+func (k *kmsSigner) getPublicKey() (*ecdsa.PublicKey, error) {
+	var res struct {
+		PublicKey []byte
+		KeySpec   string
+		KeyUsage  string
+	}
+	err := k.call("TrentService.GetPublicKey", map[string]interface{}{"KeyId": k.cfg.KeyId}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.KeySpec != kmsKeySpec {
+		return nil, fmt.Errorf("key spec is %s, must be %s", res.KeySpec, kmsKeySpec)
+	}
+	if res.KeyUsage != "SIGN_VERIFY" {
+		return nil, fmt.Errorf("key usage is %s, must be SIGN_VERIFY", res.KeyUsage)
+	}
+
+	return parseSecp256k1PublicKey(res.PublicKey)
+}
+
+// call makes a signed request to the KMS JSON API
+func (k *kmsSigner) call(target string, params interface{}, out interface{}) error {
+	ctx, cancel := context.WithTimeout(k.ctx, k.cfg.Timeout)
+	defer cancel()
+
+	creds, err := k.creds.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", k.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	signAwsRequest(req, body, creds, k.cfg.Region, "kms", time.Now())
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return parseAwsError(resp.Status, respBody)
+	}
+
+	return json.Unmarshal(respBody, out)
+}
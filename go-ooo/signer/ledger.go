@@ -0,0 +1,356 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/sirupsen/logrus"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrLedgerHashSigning - the Ledger Ethereum app only signs transactions and messages it can
+// display, never bare hashes
+var ErrLedgerHashSigning = errors.New("the ledger cannot sign bare hashes")
+
+// LedgerConfig configures the Ledger signer backend
+type LedgerConfig struct {
+	// Path BIP-32 derivation path of the oracle's account. Defaults to m/44'/60'/0'/0/0
+	Path string
+	// AutoApproveGasPrice enables approval mode: txs with a gas price at or below this are sent
+	// straight to the device for confirmation, and txs above it are held until approved with
+	// Approve. nil or zero sends every tx straight to the device
+	AutoApproveGasPrice *big.Int
+	// ApprovalTimeout how long a held tx waits for approval before it fails. Defaults to 60 seconds
+	ApprovalTimeout time.Duration
+	Logger          *logrus.Logger
+}
+
+// LedgerPendingTx is a tx held for approval because its gas price is above the auto approve
+// threshold
+type LedgerPendingTx struct {
+	Id       string `json:"id"`
+	To       string `json:"to"`
+	Nonce    uint64 `json:"nonce"`
+	Gas      uint64 `json:"gas"`
+	GasPrice string `json:"gas_price"`
+	MaxFee   string `json:"max_fee"`
+	Created  int64  `json:"created"`
+
+	approved chan bool
+}
+
+// LedgerSigner signs with a key held on a Ledger hardware wallet. Every tx and message must be
+// confirmed on the device, so someone needs to be with it while the oracle runs
+type LedgerSigner struct {
+	cfg    LedgerConfig
+	path   accounts.DerivationPath
+	logger *logrus.Logger
+
+	// mu serialises use of the device, which handles one request at a time
+	mu        sync.Mutex
+	device    *ledgerDevice
+	publicKey *ecdsa.PublicKey
+	address   common.Address
+
+	pendingMu sync.Mutex
+	pending   map[string]*LedgerPendingTx
+	nextId    uint64
+}
+
+// NewLedgerSigner opens the connected Ledger and gets the address of the account at the
+// configured derivation path. The Ethereum app must be open on the device
+func NewLedgerSigner(cfg LedgerConfig) (*LedgerSigner, error) {
+	if cfg.Path == "" {
+		cfg.Path = accounts.DefaultBaseDerivationPath.String()
+	}
+	path, err := accounts.ParseDerivationPath(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ledger derivation path %s: %s", cfg.Path, err.Error())
+	}
+	if cfg.ApprovalTimeout <= 0 {
+		cfg.ApprovalTimeout = 60 * time.Second
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.StandardLogger()
+	}
+
+	l := &LedgerSigner{
+		cfg:     cfg,
+		path:    path,
+		logger:  cfg.Logger,
+		pending: make(map[string]*LedgerPendingTx),
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err = l.connect(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// connect opens the device, and checks it still holds the oracle's key if it was reconnected
+func (l *LedgerSigner) connect() error {
+	device, err := openLedger()
+	if err != nil {
+		return err
+	}
+
+	version, err := device.appVersion()
+	if err != nil {
+		device.close()
+		return err
+	}
+
+	publicKey, address, err := device.derive(l.path)
+	if err != nil {
+		device.close()
+		return err
+	}
+	if l.publicKey != nil && address != l.address {
+		device.close()
+		return fmt.Errorf("the connected ledger's address %s is not the oracle's address %s", address.Hex(), l.address.Hex())
+	}
+
+	l.device = device
+	l.publicKey = publicKey
+	l.address = address
+
+	l.logger.WithFields(logrus.Fields{
+		"package":     "signer",
+		"function":    "connect",
+		"app_version": version,
+		"path":        l.path.String(),
+		"address":     address.Hex(),
+	}).Info("connected to ledger")
+
+	return nil
+}
+
+// withDevice runs fn with the device, reconnecting first if it was disconnected. The device
+// is closed if fn fails with a transport error, so that it is reopened on the next call
+func (l *LedgerSigner) withDevice(fn func(d *ledgerDevice) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.device == nil {
+		if err := l.connect(); err != nil {
+			return err
+		}
+	}
+
+	err := fn(l.device)
+	var statusErr ledgerStatusError
+	if err != nil && !errors.As(err, &statusErr) {
+		l.device.close()
+		l.device = nil
+	}
+	return err
+}
+
+// Close releases the device
+func (l *LedgerSigner) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.device != nil {
+		l.device.close()
+		l.device = nil
+	}
+}
+
+func (l *LedgerSigner) Address() common.Address {
+	return l.address
+}
+
+func (l *LedgerSigner) PublicKey() *ecdsa.PublicKey {
+	return l.publicKey
+}
+
+func (l *LedgerSigner) PrivateKey() (*ecdsa.PrivateKey, error) {
+	return nil, ErrNoPrivateKey
+}
+
+func (l *LedgerSigner) SignHash(hash []byte) ([]byte, error) {
+	return nil, ErrLedgerHashSigning
+}
+
+// SignText has the device sign text as an Ethereum signed message. The device shows the
+// message, which for fulfillments is the 32 byte request hash, to be confirmed
+func (l *LedgerSigner) SignText(text []byte) ([]byte, error) {
+	payload := ledgerPath(l.path)
+	payload = append(payload, make([]byte, 4)...)
+	binary.BigEndian.PutUint32(payload[len(payload)-4:], uint32(len(text)))
+	payload = append(payload, text...)
+
+	l.logger.WithFields(logrus.Fields{
+		"package":  "signer",
+		"function": "SignText",
+		"message":  common.Bytes2Hex(text),
+	}).Warn("confirm signing the message on the ledger")
+
+	var reply []byte
+	err := l.withDevice(func(d *ledgerDevice) error {
+		var err error
+		reply, err = d.sign(ledgerInsSignPersonal, payload)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the device returns [V || R || S]. V is 27 or 28, but is recovered rather than trusted
+	sig := append(reply[1:], 0)
+	if err = setRecoveryId(accounts.TextHash(text), sig, l.publicKey); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// SignTx has the device sign a legacy, EIP-155 tx after it is confirmed on the device. In
+// approval mode, txs with a gas price above the threshold must first be approved
+func (l *LedgerSigner) SignTx(tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	if tx.Type() != types.LegacyTxType {
+		return nil, fmt.Errorf("the ledger signer only supports legacy txs, not type %d", tx.Type())
+	}
+
+	fields := logrus.Fields{
+		"package":   "signer",
+		"function":  "SignTx",
+		"to":        tx.To().Hex(),
+		"nonce":     tx.Nonce(),
+		"gas":       tx.Gas(),
+		"gas_price": fmt.Sprintf("%s gwei", weiToUnits(tx.GasPrice(), params.GWei)),
+		"max_fee":   fmt.Sprintf("%s ETH", weiToUnits(tx.Cost(), params.Ether)),
+	}
+
+	threshold := l.cfg.AutoApproveGasPrice
+	if threshold != nil && threshold.Sign() > 0 && tx.GasPrice().Cmp(threshold) > 0 {
+		if err := l.awaitApproval(tx, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	txRlp, err := rlp.EncodeToBytes([]interface{}{
+		tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), chainId, uint(0), uint(0),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l.logger.WithFields(fields).Warn("confirm the transaction on the ledger")
+
+	var reply []byte
+	err = l.withDevice(func(d *ledgerDevice) error {
+		var err error
+		reply, err = d.sign(ledgerInsSignTx, append(ledgerPath(l.path), txRlp...))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the device returns V as the low byte of chainId * 2 + 35 + parity, which is ambiguous for
+	// large chain ids, so the parity is found from the sender instead
+	txSigner := types.NewEIP155Signer(chainId)
+	sig := append(reply[1:], 0)
+	for v := byte(0); v < 2; v++ {
+		sig[64] = v
+		signed, err := tx.WithSignature(txSigner, sig)
+		if err != nil {
+			return nil, err
+		}
+		if sender, err := types.Sender(txSigner, signed); err == nil && sender == l.address {
+			return signed, nil
+		}
+	}
+
+	return nil, errors.New("ledger signature does not recover to the oracle's address")
+}
+
+// awaitApproval holds tx until it is approved or rejected, or the approval timeout passes
+func (l *LedgerSigner) awaitApproval(tx *types.Transaction, fields logrus.Fields) error {
+	l.pendingMu.Lock()
+	l.nextId++
+	p := &LedgerPendingTx{
+		Id:       strconv.FormatUint(l.nextId, 10),
+		To:       tx.To().Hex(),
+		Nonce:    tx.Nonce(),
+		Gas:      tx.Gas(),
+		GasPrice: tx.GasPrice().String(),
+		MaxFee:   tx.Cost().String(),
+		Created:  time.Now().Unix(),
+		approved: make(chan bool, 1),
+	}
+	l.pending[p.Id] = p
+	l.pendingMu.Unlock()
+
+	defer func() {
+		l.pendingMu.Lock()
+		delete(l.pending, p.Id)
+		l.pendingMu.Unlock()
+	}()
+
+	l.logger.WithFields(fields).WithFields(logrus.Fields{
+		"approval_id": p.Id,
+		"threshold":   fmt.Sprintf("%s gwei", weiToUnits(l.cfg.AutoApproveGasPrice, params.GWei)),
+	}).Warn(fmt.Sprintf("gas price is above the auto approve threshold - approve with 'go-ooo admin ledger approve %s'", p.Id))
+
+	select {
+	case approved := <-p.approved:
+		if !approved {
+			return fmt.Errorf("ledger tx %s was rejected", p.Id)
+		}
+		return nil
+	case <-time.After(l.cfg.ApprovalTimeout):
+		return fmt.Errorf("ledger tx %s was not approved within %s", p.Id, l.cfg.ApprovalTimeout.String())
+	}
+}
+
+// Pending returns the txs held for approval, oldest first
+func (l *LedgerSigner) Pending() []LedgerPendingTx {
+	l.pendingMu.Lock()
+	defer l.pendingMu.Unlock()
+
+	pending := make([]LedgerPendingTx, 0, len(l.pending))
+	for _, p := range l.pending {
+		pending = append(pending, *p)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		a, _ := strconv.ParseUint(pending[i].Id, 10, 64)
+		b, _ := strconv.ParseUint(pending[j].Id, 10, 64)
+		return a < b
+	})
+	return pending
+}
+
+// Approve releases a held tx to be confirmed on the device, or rejects it if approved is false
+func (l *LedgerSigner) Approve(id string, approved bool) error {
+	l.pendingMu.Lock()
+	defer l.pendingMu.Unlock()
+
+	p, ok := l.pending[id]
+	if !ok {
+		return fmt.Errorf("no ledger tx %s pending approval", id)
+	}
+	delete(l.pending, id)
+	p.approved <- approved
+
+	return nil
+}
+
+func weiToUnits(wei *big.Int, unit float64) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(unit)).Text('f', 6)
+}
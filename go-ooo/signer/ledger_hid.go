@@ -0,0 +1,217 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/karalabe/usb"
+	"io"
+)
+
+// Ledger Ethereum app APDUs - https://github.com/LedgerHQ/app-ethereum/blob/master/doc/ethapp.adoc
+const (
+	ledgerVendorId  = 0x2c97
+	ledgerUsagePage = 0xffa0
+
+	ledgerCla             = 0xe0
+	ledgerInsGetAddress   = 0x02
+	ledgerInsSignTx       = 0x04
+	ledgerInsGetConfig    = 0x06
+	ledgerInsSignPersonal = 0x08
+
+	ledgerP1First = 0x00
+	ledgerP1More  = 0x80
+
+	// ledgerMaxChunk - the most data a single APDU can carry
+	ledgerMaxChunk = 255
+)
+
+// ledgerStatusError is a non-success status word returned by the device
+type ledgerStatusError uint16
+
+func (e ledgerStatusError) Error() string {
+	switch e {
+	case 0x6985:
+		return "rejected on the ledger"
+	case 0x6a80:
+		return "the ledger refused the data - enable blind signing in the Ethereum app's settings"
+	case 0x6d00, 0x6e00, 0x6511, 0x6e01:
+		return "the Ethereum app is not open on the ledger"
+	case 0x5515, 0x6b0c:
+		return "the ledger is locked"
+	default:
+		return fmt.Sprintf("ledger returned status 0x%04x", uint16(e))
+	}
+}
+
+// ledgerDevice talks to the Ethereum app on a Ledger over USB HID
+type ledgerDevice struct {
+	device usb.Device
+}
+
+// openLedger opens the first Ledger found
+func openLedger() (*ledgerDevice, error) {
+	if !usb.Supported() {
+		return nil, errors.New("usb is not supported on this platform")
+	}
+
+	infos, err := usb.EnumerateHid(ledgerVendorId, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, info := range infos {
+		// Windows and macOS report the usage page, Linux the interface
+		if info.UsagePage != ledgerUsagePage && info.Interface != 0 {
+			continue
+		}
+		device, err := info.Open()
+		if err != nil {
+			return nil, fmt.Errorf("cannot open ledger: %s", err.Error())
+		}
+		return &ledgerDevice{device: device}, nil
+	}
+
+	return nil, errors.New("no ledger found - is it connected and unlocked?")
+}
+
+func (d *ledgerDevice) close() {
+	_ = d.device.Close()
+}
+
+// appVersion returns the version of the Ethereum app open on the device
+func (d *ledgerDevice) appVersion() (string, error) {
+	reply, err := d.exchange(ledgerInsGetConfig, 0, 0, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(reply) != 4 {
+		return "", errors.New("invalid app configuration reply")
+	}
+	return fmt.Sprintf("%d.%d.%d", reply[1], reply[2], reply[3]), nil
+}
+
+// derive returns the public key and address of the account at path
+func (d *ledgerDevice) derive(path accounts.DerivationPath) (*ecdsa.PublicKey, common.Address, error) {
+	reply, err := d.exchange(ledgerInsGetAddress, 0, 0, ledgerPath(path))
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+
+	// [pub key length][pub key][address length][hex address]
+	if len(reply) < 1 || len(reply) < 1+int(reply[0])+1 {
+		return nil, common.Address{}, errors.New("invalid address reply")
+	}
+	publicKey, err := crypto.UnmarshalPubkey(reply[1 : 1+int(reply[0])])
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	reply = reply[1+int(reply[0]):]
+	if len(reply) < 1+int(reply[0]) || !common.IsHexAddress(string(reply[1:1+int(reply[0])])) {
+		return nil, common.Address{}, errors.New("invalid address reply")
+	}
+
+	address := common.HexToAddress(string(reply[1 : 1+int(reply[0])]))
+	if address != crypto.PubkeyToAddress(*publicKey) {
+		return nil, common.Address{}, errors.New("ledger address does not match its public key")
+	}
+
+	return publicKey, address, nil
+}
+
+// sign streams payload to the device in chunks, and returns the signature once it has been
+// confirmed on the device, as [V || R || S]
+func (d *ledgerDevice) sign(ins byte, payload []byte) ([]byte, error) {
+	var reply []byte
+	p1 := byte(ledgerP1First)
+	for len(payload) > 0 {
+		chunk := ledgerMaxChunk
+		if len(payload) < chunk {
+			chunk = len(payload)
+		}
+		var err error
+		if reply, err = d.exchange(ins, p1, 0, payload[:chunk]); err != nil {
+			return nil, err
+		}
+		payload = payload[chunk:]
+		p1 = ledgerP1More
+	}
+
+	if len(reply) != crypto.SignatureLength {
+		return nil, errors.New("ledger reply lacks a signature")
+	}
+	return reply, nil
+}
+
+// exchange sends an APDU and returns the reply data. APDUs are framed in 64 byte HID reports:
+// [channel 0x0101][tag 0x05][sequence uint16] followed, in the first report, by the APDU length
+func (d *ledgerDevice) exchange(ins byte, p1 byte, p2 byte, data []byte) ([]byte, error) {
+	apdu := make([]byte, 2, 7+len(data))
+	binary.BigEndian.PutUint16(apdu, uint16(5+len(data)))
+	apdu = append(apdu, ledgerCla, ins, p1, p2, byte(len(data)))
+	apdu = append(apdu, data...)
+
+	header := []byte{0x01, 0x01, 0x05, 0x00, 0x00}
+	report := make([]byte, 0, 64)
+	for seq := 0; len(apdu) > 0; seq++ {
+		report = append(report[:0], header...)
+		binary.BigEndian.PutUint16(report[3:], uint16(seq))
+
+		n := cap(report) - len(report)
+		if n > len(apdu) {
+			n = len(apdu)
+		}
+		report = append(report, apdu[:n]...)
+		apdu = apdu[n:]
+
+		if _, err := d.device.Write(report); err != nil {
+			return nil, err
+		}
+	}
+
+	var reply []byte
+	report = report[:64]
+	for seq := 0; ; seq++ {
+		if _, err := io.ReadFull(d.device, report); err != nil {
+			return nil, err
+		}
+		if report[0] != 0x01 || report[1] != 0x01 || report[2] != 0x05 ||
+			int(binary.BigEndian.Uint16(report[3:5])) != seq {
+			return nil, errors.New("invalid reply header from ledger")
+		}
+
+		payload := report[5:]
+		if seq == 0 {
+			reply = make([]byte, 0, binary.BigEndian.Uint16(report[5:7]))
+			payload = report[7:]
+		}
+		if left := cap(reply) - len(reply); left > len(payload) {
+			reply = append(reply, payload...)
+		} else {
+			reply = append(reply, payload[:left]...)
+			break
+		}
+	}
+
+	if len(reply) < 2 {
+		return nil, errors.New("short reply from ledger")
+	}
+	if status := binary.BigEndian.Uint16(reply[len(reply)-2:]); status != 0x9000 {
+		return nil, ledgerStatusError(status)
+	}
+	return reply[:len(reply)-2], nil
+}
+
+// ledgerPath encodes a derivation path as [length][index uint32]...
+func ledgerPath(path accounts.DerivationPath) []byte {
+	b := make([]byte, 1+4*len(path))
+	b[0] = byte(len(path))
+	for i, component := range path {
+		binary.BigEndian.PutUint32(b[1+4*i:], component)
+	}
+	return b
+}
@@ -0,0 +1,112 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go-ooo/utils"
+	"math/big"
+)
+
+// signer backends
+const (
+	BackendKeystore = "keystore" // key decrypted from the keystore file
+	BackendAwsKms   = "aws_kms"  // key held in AWS KMS, which never leaves it
+	BackendGcpKms   = "gcp_kms"  // key held in Google Cloud KMS or Cloud HSM
+	BackendVault    = "vault"    // key read from HashiCorp Vault on start, held in memory
+	BackendLedger   = "ledger"   // key held on a Ledger hardware wallet, txs confirmed on the device
+
+	// BackendPkcs11 is not supported, and is rejected with a pointer to the HSM-backed KMS backends
+	BackendPkcs11 = "pkcs11"
+)
+
+// ErrNoPrivateKey is returned by PrivateKey for backends which don't hold the key locally
+var ErrNoPrivateKey = errors.New("private key is not available from this signer backend")
+
+// Signer signs with the oracle's key
+type Signer interface {
+	// Address returns the oracle's address, derived from the signer's public key
+	Address() common.Address
+	// PublicKey returns the signer's public key
+	PublicKey() *ecdsa.PublicKey
+	// SignHash signs a 32 byte hash, returning a 65 byte [R || S || V] signature in the same
+	// format as crypto.Sign, with V 0 or 1
+	SignHash(hash []byte) ([]byte, error)
+	// SignText signs text as an Ethereum signed message, i.e. the hash of
+	// "\x19Ethereum Signed Message:\n" + len(text) + text, in the same format as SignHash
+	SignText(text []byte) ([]byte, error)
+	// PrivateKey returns the private key, if the backend holds it locally. Needed for VOR
+	// proofs, which can't be generated by a remote signer
+	PrivateKey() (*ecdsa.PrivateKey, error)
+}
+
+// TxSigner is implemented by signers which must see the whole transaction to sign it, e.g.
+// hardware wallets which display it for confirmation, rather than signing its hash
+type TxSigner interface {
+	SignTx(tx *types.Transaction, chainId *big.Int) (*types.Transaction, error)
+}
+
+// localSigner signs with a private key held in memory
+type localSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewLocalSigner returns a Signer for a hex encoded private key
+func NewLocalSigner(privateKeyHex string) (Signer, error) {
+	key, err := crypto.HexToECDSA(utils.RemoveHexPrefix(privateKeyHex))
+	if err != nil {
+		return nil, err
+	}
+	return &localSigner{key: key}, nil
+}
+
+func (l *localSigner) Address() common.Address {
+	return crypto.PubkeyToAddress(l.key.PublicKey)
+}
+
+func (l *localSigner) PublicKey() *ecdsa.PublicKey {
+	return &l.key.PublicKey
+}
+
+func (l *localSigner) SignHash(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, l.key)
+}
+
+func (l *localSigner) SignText(text []byte) ([]byte, error) {
+	return l.SignHash(accounts.TextHash(text))
+}
+
+func (l *localSigner) PrivateKey() (*ecdsa.PrivateKey, error) {
+	return l.key, nil
+}
+
+// NewTransactOpts returns transact opts which sign transactions for chainId with s
+func NewTransactOpts(s Signer, chainId *big.Int) (*bind.TransactOpts, error) {
+	if chainId == nil || chainId.Sign() <= 0 {
+		return nil, errors.New("invalid chain id")
+	}
+
+	txSigner := types.LatestSignerForChainID(chainId)
+	from := s.Address()
+
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			if ts, ok := s.(TxSigner); ok {
+				return ts.SignTx(tx, chainId)
+			}
+			sig, err := s.SignHash(txSigner.Hash(tx).Bytes())
+			if err != nil {
+				return nil, err
+			}
+			return tx.WithSignature(txSigner, sig)
+		},
+	}, nil
+}
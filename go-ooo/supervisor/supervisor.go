@@ -0,0 +1,108 @@
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"go-ooo/errreport"
+	"go-ooo/redact"
+	"runtime/debug"
+	"time"
+)
+
+// restart backoff for goroutines which keep panicking. A goroutine which ran for longer than
+// maxRestartDelay before panicking is restarted after minRestartDelay again
+const (
+	minRestartDelay = time.Second
+	maxRestartDelay = time.Minute
+)
+
+var goroutinePanics = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ooo_goroutine_panics_total",
+	Help: "The total number of panics recovered, by goroutine",
+}, []string{"goroutine"})
+
+// Supervisor runs the node's long-lived goroutines - the job poller, workers, event watchers
+// and sync schedulers - recovering and reporting panics, so that a single panic does not
+// silently stop a subsystem. Recovered panics are logged with their stack, counted, and sent
+// to the error reporter if one is configured
+type Supervisor struct {
+	logger   *logrus.Logger
+	reporter *errreport.Reporter
+}
+
+// New returns a Supervisor logging to logger. reporter may be nil, in which case panics are not
+// reported to Sentry
+func New(logger *logrus.Logger, reporter *errreport.Reporter) *Supervisor {
+	return &Supervisor{
+		logger:   logger,
+		reporter: reporter,
+	}
+}
+
+// Run runs fn, restarting it if it panics, until it returns or ctx is done. Restarts back off
+// from one second to a minute while fn keeps panicking
+func (s *Supervisor) Run(ctx context.Context, name string, fn func()) {
+	delay := minRestartDelay
+
+	for {
+		started := time.Now()
+		if !s.Do(name, fn) {
+			return
+		}
+
+		if time.Since(started) > maxRestartDelay {
+			delay = minRestartDelay
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"package":    "supervisor",
+			"function":   "Run",
+			"goroutine":  name,
+			"restart_in": delay.String(),
+		}).Warn("restarting goroutine after panic")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > maxRestartDelay {
+			delay = maxRestartDelay
+		}
+	}
+}
+
+// Go runs fn once in a new goroutine, recovering a panic. It is for work which is started again
+// on the next tick of a scheduler anyway, e.g. health checks
+func (s *Supervisor) Go(name string, fn func()) {
+	go s.Do(name, fn)
+}
+
+// Do runs fn in the calling goroutine, returning true if it panicked
+func (s *Supervisor) Do(name string, fn func()) (panicked bool) {
+	defer func() {
+		if p := recover(); p != nil {
+			panicked = true
+			p = redact.Panic(p)
+
+			goroutinePanics.WithLabelValues(name).Inc()
+
+			s.logger.WithFields(logrus.Fields{
+				"package":   "supervisor",
+				"function":  "Do",
+				"goroutine": name,
+				"stack":     redact.String(string(debug.Stack())),
+			}).Error(fmt.Sprintf("recovered from panic: %v", p))
+
+			s.reporter.ReportPanic(p)
+		}
+	}()
+
+	fn()
+	return false
+}
@@ -0,0 +1,230 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// queueSize - spans are dropped if this many are waiting to be exported
+	queueSize = 4096
+	// maxBatchSize - max spans per export request
+	maxBatchSize = 512
+	// flushInterval - how often queued spans are exported
+	flushInterval = 5 * time.Second
+)
+
+// OTLP/HTTP JSON encoding of ExportTraceServiceRequest
+
+type otlpExportRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceId           string         `json:"traceId"`
+	SpanId            string         `json:"spanId"`
+	ParentSpanId      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// spanKindInternal - all go-ooo spans are internal operations
+const spanKindInternal = 1
+
+func stringAttr(key string, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: &value}}
+}
+
+func toAnyValue(v interface{}) otlpAnyValue {
+	switch val := v.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &val}
+	case bool:
+		return otlpAnyValue{BoolValue: &val}
+	case int:
+		s := strconv.FormatInt(int64(val), 10)
+		return otlpAnyValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(val, 10)
+		return otlpAnyValue{IntValue: &s}
+	case uint64:
+		s := strconv.FormatUint(val, 10)
+		return otlpAnyValue{IntValue: &s}
+	case float64:
+		return otlpAnyValue{DoubleValue: &val}
+	default:
+		s := fmt.Sprint(val)
+		return otlpAnyValue{StringValue: &s}
+	}
+}
+
+func (s *Span) toOtlp() otlpSpan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	span := otlpSpan{
+		TraceId:           hex.EncodeToString(s.traceId[:]),
+		SpanId:            hex.EncodeToString(s.spanId[:]),
+		Name:              s.name,
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Status:            otlpStatus{Code: s.status, Message: s.statusMsg},
+	}
+
+	if s.parentId != [8]byte{} {
+		span.ParentSpanId = hex.EncodeToString(s.parentId[:])
+	}
+
+	if span.Status.Code == statusUnset {
+		span.Status.Code = statusOk
+	}
+
+	for k, v := range s.attributes {
+		span.Attributes = append(span.Attributes, otlpKeyValue{Key: k, Value: toAnyValue(v)})
+	}
+
+	return span
+}
+
+func (t *Tracer) run() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, maxBatchSize)
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			// export whatever is already queued before shutting down
+			for {
+				select {
+				case s := <-t.queue:
+					batch = append(batch, s)
+					if len(batch) == maxBatchSize {
+						t.export(batch)
+						batch = batch[:0]
+					}
+				default:
+					t.export(batch)
+					return
+				}
+			}
+		case s := <-t.queue:
+			batch = append(batch, s)
+			if len(batch) == maxBatchSize {
+				t.export(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			t.export(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (t *Tracer) export(batch []*Span) {
+	if len(batch) == 0 {
+		return
+	}
+
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, s := range batch {
+		spans = append(spans, s.toOtlp())
+	}
+
+	body, err := json.Marshal(otlpExportRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: []otlpKeyValue{
+				stringAttr("service.name", t.cfg.ServiceName),
+			}},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "go-ooo"},
+				Spans: spans,
+			}},
+		}},
+	})
+	if err != nil {
+		return
+	}
+
+	// the service context may already be done during shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), t.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", t.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		t.logExportError(len(spans), err)
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		t.logExportError(len(spans), err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		t.logExportError(len(spans), fmt.Errorf("collector returned %s", resp.Status))
+	}
+}
+
+func (t *Tracer) logExportError(numSpans int, err error) {
+	t.logger.WithFields(logrus.Fields{
+		"package":   "tracing",
+		"function":  "export",
+		"num_spans": numSpans,
+	}).Warn(fmt.Sprintf("failed to export spans: %s", err.Error()))
+}
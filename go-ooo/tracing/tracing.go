@@ -0,0 +1,209 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"github.com/sirupsen/logrus"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// span status codes, as defined by OTLP
+const (
+	statusUnset = 0
+	statusOk    = 1
+	statusError = 2
+)
+
+// Config configures the OTLP exporter. Tracing is disabled if Endpoint is empty
+type Config struct {
+	// Endpoint OTLP/HTTP traces endpoint, e.g. http://localhost:4318/v1/traces
+	Endpoint    string
+	Headers     map[string]string
+	ServiceName string
+	// SampleRatio fraction of requests traced, 0 - 1. Sampling is by request, so a request's
+	// spans are either all exported or all dropped
+	SampleRatio float64
+	Timeout     time.Duration
+}
+
+// Tracer records spans and exports them in batches to an OTLP/HTTP collector
+type Tracer struct {
+	cfg      Config
+	client   *http.Client
+	queue    chan *Span
+	logger   *logrus.Logger
+	ctx      context.Context
+	sampleLt uint64
+}
+
+// Span is a single timed operation. All methods are safe to call on a nil Span, which
+// is returned when tracing is disabled or the trace is not sampled
+type Span struct {
+	tracer   *Tracer
+	traceId  [16]byte
+	spanId   [8]byte
+	parentId [8]byte
+	name     string
+	start    time.Time
+	end      time.Time
+
+	mu         sync.Mutex
+	attributes map[string]interface{}
+	status     int
+	statusMsg  string
+	ended      bool
+}
+
+type spanKey struct{}
+
+// NewTracer returns a Tracer exporting to the configured endpoint. Export runs until ctx is
+// done. Returns nil, which records nothing, if no endpoint is configured
+func NewTracer(ctx context.Context, logger *logrus.Logger, cfg Config) *Tracer {
+	if cfg.Endpoint == "" {
+		return nil
+	}
+
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "go-ooo"
+	}
+	if cfg.SampleRatio <= 0 || cfg.SampleRatio > 1 {
+		cfg.SampleRatio = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	t := &Tracer{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		queue:    make(chan *Span, queueSize),
+		logger:   logger,
+		ctx:      ctx,
+		sampleLt: uint64(cfg.SampleRatio * math.MaxUint64),
+	}
+
+	if cfg.SampleRatio == 1 {
+		t.sampleLt = math.MaxUint64
+	}
+
+	go t.run()
+
+	return t
+}
+
+// StartRequestSpan starts a span in the trace for requestId. The trace id is derived from the
+// request id, so that spans recorded as the request is received, processed and confirmed - even
+// across restarts and retries - are all part of one trace. If ctx holds a span in the same trace,
+// the new span is its child
+func (t *Tracer) StartRequestSpan(ctx context.Context, requestId string, name string) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	sum := sha256.Sum256([]byte(requestId))
+	var traceId [16]byte
+	copy(traceId[:], sum[:16])
+
+	if binary.BigEndian.Uint64(traceId[8:]) > t.sampleLt {
+		return ctx, nil
+	}
+
+	s := t.newSpan(traceId, name)
+	if parent := FromContext(ctx); parent != nil && parent.traceId == traceId {
+		s.parentId = parent.spanId
+	}
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// StartSpan starts a child of the span held in ctx. If ctx does not hold a span, nothing is recorded
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	parent := FromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+
+	s := parent.tracer.newSpan(parent.traceId, name)
+	s.parentId = parent.spanId
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// WithSpan returns a copy of ctx holding s, so that spans started from it are children of s,
+// e.g. for work which outlives the request which started it. If s is nil, ctx is returned
+func WithSpan(ctx context.Context, s *Span) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// FromContext returns the span held in ctx, or nil
+func FromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+func (t *Tracer) newSpan(traceId [16]byte, name string) *Span {
+	s := &Span{
+		tracer:     t,
+		traceId:    traceId,
+		name:       name,
+		start:      time.Now(),
+		attributes: make(map[string]interface{}),
+	}
+	_, _ = rand.Read(s.spanId[:])
+	return s
+}
+
+// SetAttribute adds a string, bool, integer or float attribute to the span. Any other
+// type is recorded as its string representation
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attributes[key] = value
+	s.mu.Unlock()
+}
+
+// SetError marks the span as failed. A nil err is ignored
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.status = statusError
+	s.statusMsg = err.Error()
+	s.mu.Unlock()
+}
+
+// End records the span's end time and queues it for export. Only the first call has any effect
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	select {
+	case s.tracer.queue <- s:
+	default:
+		// never block the pipeline on tracing
+	}
+}
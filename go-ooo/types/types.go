@@ -1,9 +1,12 @@
 package types
 
+import "go-ooo/version"
+
 type AdminTask struct {
-	Task         string // register/withdraw/set_fee/set_granular_fee
+	Task         string // register/withdraw/set_fee/set_granular_fee/pause/resume/xfund_approve
 	FeeOrAmount  uint64 // new fee or amount to withdraw
-	ToOrConsumer string // address withdrawing to, or contract address for granular fee
+	ToOrConsumer string // address withdrawing to, contract address for granular fee, or xfund spender
+	Scope        string // ingestion, fulfillment or all, for pause/resume
 }
 
 type AdminTaskResponse struct {
@@ -68,6 +71,137 @@ type AnalyticsData struct {
 	SuggestedFee         float64       `json:"suggested_fee"`
 }
 
+// EarningsReportRow summarises fulfillments for a pair, consumer or overall. Fees are in
+// xFUND, costs in ETH. NetMarginEth is only set if an xFUND price was given
+type EarningsReportRow struct {
+	Key          string  `json:"key,omitempty"`
+	NumFulfilled uint64  `json:"num_fulfilled"`
+	FeesXfund    float64 `json:"fees_xfund"`
+	FeesEth      float64 `json:"fees_eth"`
+	GasSpentEth  float64 `json:"gas_spent_eth"`
+	NetMarginEth float64 `json:"net_margin_eth"`
+	// Tags are the operator's tags for the pair or consumer
+	Tags []string `json:"tags,omitempty"`
+}
+
+type EarningsReport struct {
+	From          int64               `json:"from"`
+	To            int64               `json:"to"`
+	XfundPriceEth float64             `json:"xfund_price_eth"`
+	Total         EarningsReportRow   `json:"total"`
+	Pairs         []EarningsReportRow `json:"pairs"`
+	Consumers     []EarningsReportRow `json:"consumers"`
+	// Daily is keyed by UTC date, YYYY-MM-DD, oldest first
+	Daily []EarningsReportRow `json:"daily"`
+}
+
+// GasFeeStats is the spread of a fee, in gwei, across the fulfillment txs in a GasSeriesPoint
+type GasFeeStats struct {
+	Min    float64 `json:"min"`
+	Median float64 `json:"median"`
+	Max    float64 `json:"max"`
+}
+
+// GasSeriesPoint summarises the fulfillment txs mined in the interval starting at Start, a unix
+// timestamp. MeanBlocks is the mean number of blocks from request to fulfillment
+type GasSeriesPoint struct {
+	Start       int64       `json:"start"`
+	Fulfilled   uint64      `json:"fulfilled"`
+	Failed      uint64      `json:"failed"`
+	BaseFee     GasFeeStats `json:"base_fee"`
+	PriorityFee GasFeeStats `json:"priority_fee"`
+	GasPrice    GasFeeStats `json:"gas_price"`
+	MeanBlocks  float64     `json:"mean_blocks"`
+}
+
+// GasSeries is the fee market seen by fulfillment txs between From and To, in Interval second
+// points, oldest first. Intervals with no fulfillment txs are left out
+type GasSeries struct {
+	From     int64            `json:"from"`
+	To       int64            `json:"to"`
+	Interval int64            `json:"interval"`
+	Points   []GasSeriesPoint `json:"points"`
+}
+
+// ConsumerUsage summarises the requests a consumer sent between From and To of a report. Only
+// fulfilled requests earn fees. FulfillmentRate is the fraction of finished requests which were
+// fulfilled, and RevenueShare the fraction of all consumers' fees this consumer paid
+type ConsumerUsage struct {
+	Consumer        string  `json:"consumer"`
+	NumRequests     uint64  `json:"num_requests"`
+	NumFulfilled    uint64  `json:"num_fulfilled"`
+	NumFailed       uint64  `json:"num_failed"`
+	NumPending      uint64  `json:"num_pending"`
+	FulfillmentRate float64 `json:"fulfillment_rate"`
+	FeesXfund       float64 `json:"fees_xfund"`
+	AvgFeeXfund     float64 `json:"avg_fee_xfund"`
+	RevenueShare    float64 `json:"revenue_share"`
+	FirstRequestAt  int64   `json:"first_request_at"`
+	LastRequestAt   int64   `json:"last_request_at"`
+	// Tags and Note are the operator's metadata for the consumer
+	Tags []string `json:"tags,omitempty"`
+	Note string   `json:"note,omitempty"`
+}
+
+// SlaReport is each consumer's service level for the requests it sent between From and To. A
+// request is on time if it was fulfilled within DeadlineBlocks of the block it was made in
+type SlaReport struct {
+	From           int64         `json:"from"`
+	To             int64         `json:"to"`
+	DeadlineBlocks uint64        `json:"deadline_blocks"`
+	Total          ConsumerSla   `json:"total"`
+	Consumers      []ConsumerSla `json:"consumers"`
+}
+
+// ConsumerSla is a consumer's service level. Requests not fulfilled for reasons of the consumer's
+// own, such as a fee below the minimum or an invalid endpoint, are excluded, as are those still
+// pending. OnTimePercent is the percentage of the rest which were fulfilled on time. Latencies
+// are averaged over fulfilled requests, from the request being seen to its fulfillment being mined
+type ConsumerSla struct {
+	Consumer         string             `json:"consumer,omitempty"`
+	NumRequests      uint64             `json:"num_requests"`
+	NumOnTime        uint64             `json:"num_on_time"`
+	NumLate          uint64             `json:"num_late"`
+	NumFailed        uint64             `json:"num_failed"`
+	NumExcluded      uint64             `json:"num_excluded"`
+	NumPending       uint64             `json:"num_pending"`
+	OnTimePercent    float64            `json:"on_time_percent"`
+	AvgLatencyMs     int64              `json:"avg_latency_ms"`
+	AvgLatencyBlocks float64            `json:"avg_latency_blocks"`
+	FailureReasons   []SlaFailureReason `json:"failure_reasons"`
+}
+
+// SlaFailureReason counts the requests which failed, or were excluded, with a final status, or
+// rejection code for rejected requests
+type SlaFailureReason struct {
+	Reason   string `json:"reason"`
+	Count    uint64 `json:"count"`
+	Excluded bool   `json:"excluded"`
+}
+
+// ConsumerUsageRow counts a consumer's requests for a pair or day
+type ConsumerUsageRow struct {
+	Key          string  `json:"key"`
+	NumRequests  uint64  `json:"num_requests"`
+	NumFulfilled uint64  `json:"num_fulfilled"`
+	FeesXfund    float64 `json:"fees_xfund"`
+}
+
+type ConsumerUsageReport struct {
+	From      int64           `json:"from"`
+	To        int64           `json:"to"`
+	Consumers []ConsumerUsage `json:"consumers"`
+}
+
+type ConsumerUsageDetail struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+	ConsumerUsage
+	Pairs []ConsumerUsageRow `json:"pairs"`
+	// Daily is keyed by UTC date, YYYY-MM-DD, oldest first
+	Daily []ConsumerUsageRow `json:"daily"`
+}
+
 type AnalyticsResult struct {
 	AnalyticsData
 	SimValues SimValues       `json:"simulation_values"`
@@ -88,3 +222,580 @@ type Prices struct {
 type CoinGeckoResponse struct {
 	Xfund Prices `json:"xfund"`
 }
+
+type Attestation struct {
+	RequestId   string `json:"request_id"`
+	Endpoint    string `json:"endpoint"`
+	Price       string `json:"price"`
+	Timestamp   int64  `json:"timestamp"`
+	Sources     string `json:"sources"`
+	Signer      string `json:"signer"`
+	MessageHash string `json:"message_hash"`
+	Signature   string `json:"signature"`
+	IpfsCid     string `json:"ipfs_cid,omitempty"`
+}
+
+type DeadJob struct {
+	RequestId           string `json:"request_id"`
+	Consumer            string `json:"consumer"`
+	Endpoint            string `json:"endpoint"`
+	RequestBlockNumber  uint64 `json:"request_block_number"`
+	FulfillmentAttempts uint64 `json:"fulfillment_attempts"`
+	StatusReason        string `json:"status_reason"`
+	ErrorCode           string `json:"error_code,omitempty"`
+	UpdatedAt           int64  `json:"updated_at"`
+}
+
+type JournalEntry struct {
+	Kind        string `json:"kind"`
+	TxHash      string `json:"tx_hash"`
+	Nonce       uint64 `json:"nonce"`
+	BlockNumber uint64 `json:"block_number"`
+	Data        string `json:"data"`
+	Resolved    bool   `json:"resolved"`
+	Broadcast   bool   `json:"broadcast,omitempty"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// AuditActorHeader request header identifying who is performing an admin action, for the audit log
+const AuditActorHeader = "X-Go-Ooo-Actor"
+
+type AuditLogEntry struct {
+	Id         uint   `json:"id"`
+	Actor      string `json:"actor"`
+	Source     string `json:"source"`
+	RemoteAddr string `json:"remote_addr"`
+	Action     string `json:"action"`
+	RequestId  string `json:"request_id,omitempty"`
+	Params     string `json:"params,omitempty"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+type RequestInfo struct {
+	RequestId           string `json:"request_id"`
+	Consumer            string `json:"consumer"`
+	Endpoint            string `json:"endpoint"`
+	Fee                 uint64 `json:"fee"`
+	RequestStatus       string `json:"request_status"`
+	JobStatus           string `json:"job_status"`
+	StatusReason        string `json:"status_reason"`
+	RejectionCode       string `json:"rejection_code,omitempty"`
+	ErrorCode           string `json:"error_code,omitempty"`
+	PriceResult         string `json:"price_result,omitempty"`
+	AnswerRounding      string `json:"answer_rounding,omitempty"`
+	SourcePolicy        string `json:"source_policy,omitempty"`
+	SourceTier          string `json:"source_tier,omitempty"`
+	FulfillmentAttempts uint64 `json:"fulfillment_attempts"`
+	FulfillTxHash       string `json:"fulfill_tx_hash,omitempty"`
+	AttestationCid      string `json:"attestation_cid,omitempty"`
+	// Sources is the breakdown of the source values the price was aggregated from
+	Sources []RequestSource `json:"sources,omitempty"`
+}
+
+// AnswerRecord is everything stored about a request and its answer - the endpoint, the value
+// computed and the source values it was aggregated from, how long each stage took, and its fee
+// and gas. It is looked up by request id or by the hash of the request's tx or a fulfillment tx
+type AnswerRecord struct {
+	RequestId      string          `json:"request_id"`
+	Consumer       string          `json:"consumer"`
+	Provider       string          `json:"provider"`
+	Endpoint       string          `json:"endpoint"`
+	IsAdhoc        bool            `json:"is_adhoc"`
+	RequestStatus  string          `json:"request_status"`
+	StatusReason   string          `json:"status_reason,omitempty"`
+	RejectionCode  string          `json:"rejection_code,omitempty"`
+	ErrorCode      string          `json:"error_code,omitempty"`
+	Value          string          `json:"value,omitempty"`
+	AnswerRounding string          `json:"answer_rounding,omitempty"`
+	SourcePolicy   string          `json:"source_policy,omitempty"`
+	SourceTier     string          `json:"source_tier,omitempty"`
+	Sources        []RequestSource `json:"sources"`
+	AttestationCid string          `json:"attestation_cid,omitempty"`
+	Timings        AnswerTimings   `json:"timings"`
+	Gas            AnswerGas       `json:"gas"`
+}
+
+// AnswerTimings are when each stage of a request was reached, in unix milliseconds, with the
+// blocks it was requested and fulfilled in. Stages not reached are 0
+type AnswerTimings struct {
+	RequestBlock  uint64 `json:"request_block"`
+	FetchBlock    uint64 `json:"fetch_block,omitempty"`
+	SentBlock     uint64 `json:"sent_block,omitempty"`
+	FulfillBlock  uint64 `json:"fulfill_block,omitempty"`
+	RequestSeenAt int64  `json:"request_seen_at"`
+	DataFetchedAt int64  `json:"data_fetched_at,omitempty"`
+	TxSentAt      int64  `json:"tx_sent_at,omitempty"`
+	TxMinedAt     int64  `json:"tx_mined_at,omitempty"`
+	// TotalMs is from the request being seen to its fulfillment being mined
+	TotalMs int64 `json:"total_ms,omitempty"`
+}
+
+// AnswerGas is a request's fee, in xFUND's smallest unit, with the gas of its request tx and of
+// each of its mined fulfillment txs, in wei
+type AnswerGas struct {
+	Fee                 uint64     `json:"fee"`
+	RequestTxHash       string     `json:"request_tx_hash"`
+	RequestGasUsed      uint64     `json:"request_gas_used"`
+	RequestGasPrice     uint64     `json:"request_gas_price"`
+	FulfillTxHash       string     `json:"fulfill_tx_hash,omitempty"`
+	FulfillGasUsed      uint64     `json:"fulfill_gas_used,omitempty"`
+	FulfillGasPrice     uint64     `json:"fulfill_gas_price,omitempty"`
+	FulfillmentAttempts uint64     `json:"fulfillment_attempts"`
+	FulfillmentTxs      []AnswerTx `json:"fulfillment_txs,omitempty"`
+}
+
+// AnswerTx is a mined fulfillment tx, with the fee market of its block
+type AnswerTx struct {
+	TxHash      string `json:"tx_hash"`
+	BlockNumber uint64 `json:"block_number"`
+	BlockTime   int64  `json:"block_time"`
+	GasUsed     uint64 `json:"gas_used"`
+	GasPrice    uint64 `json:"gas_price"`
+	BaseFee     uint64 `json:"base_fee"`
+	PriorityFee uint64 `json:"priority_fee"`
+	Success     bool   `json:"success"`
+}
+
+// RequestSource is a source value fetched to answer a request. Excluded is why it did not
+// contribute to the answer, e.g. outlier, or why the source's response was rejected
+type RequestSource struct {
+	Source    string  `json:"source"`
+	Value     float64 `json:"value"`
+	Deviation float64 `json:"deviation,omitempty"`
+	Excluded  string  `json:"excluded,omitempty"`
+	Weight    float64 `json:"weight"`
+	LatencyMs int64   `json:"latency_ms"`
+	// PriceImpact is the percent a trade of jobs.dex_impact_notional USD would move the price of
+	// the DEX pool the value came from
+	PriceImpact float64 `json:"price_impact,omitempty"`
+}
+
+type LogLevel struct {
+	Level string `json:"level"`
+}
+
+// MaintenanceRequest starts maintenance mode. Duration, e.g. 2h, ends it automatically, and if
+// empty it lasts until ended
+type MaintenanceRequest struct {
+	Note     string `json:"note"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// MaintenanceStatus is the node's maintenance mode. Times are unix timestamps, and Until is 0 if
+// the maintenance lasts until ended
+type MaintenanceStatus struct {
+	Active    bool   `json:"active"`
+	Note      string `json:"note,omitempty"`
+	StartedBy string `json:"started_by,omitempty"`
+	StartedAt int64  `json:"started_at,omitempty"`
+	Until     int64  `json:"until,omitempty"`
+}
+
+type ManualFulfillment struct {
+	Value string `json:"value"` // value to submit, scaled to the answer decimals
+}
+
+type LatencyPercentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+}
+
+// LatencyStats fulfillment latency, in seconds, for each stage of processing
+type LatencyStats struct {
+	Count        int                `json:"count"`
+	WithinSloPct float64            `json:"within_slo_pct"`
+	Fetch        LatencyPercentiles `json:"fetch"`
+	Send         LatencyPercentiles `json:"send"`
+	Mine         LatencyPercentiles `json:"mine"`
+	Total        LatencyPercentiles `json:"total"`
+}
+
+type LatencyReport struct {
+	Since      int64                   `json:"since"`
+	SloSeconds int64                   `json:"slo_seconds"`
+	Overall    LatencyStats            `json:"overall"`
+	Pairs      map[string]LatencyStats `json:"pairs"`
+	Sources    map[string]LatencyStats `json:"sources"`
+}
+
+type JobSummary struct {
+	RequestId           string `json:"request_id"`
+	Consumer            string `json:"consumer"`
+	Endpoint            string `json:"endpoint"`
+	RequestBlockNumber  uint64 `json:"request_block_number"`
+	RequestStatus       string `json:"request_status"`
+	JobStatus           string `json:"job_status"`
+	FulfillmentAttempts uint64 `json:"fulfillment_attempts"`
+	StatusReason        string `json:"status_reason"`
+	ErrorCode           string `json:"error_code,omitempty"`
+	UpdatedAt           int64  `json:"updated_at"`
+	// ConsumerTags and PairTags are the operator's tags for the request's consumer and pair
+	ConsumerTags []string `json:"consumer_tags,omitempty"`
+	PairTags     []string `json:"pair_tags,omitempty"`
+}
+
+// Metadata is the operator's tags and note for a consumer or pair, used to organise traffic by
+// customer or product line
+type Metadata struct {
+	Tags []string `json:"tags"`
+	Note string   `json:"note,omitempty"`
+}
+
+type ConsumerMetadata struct {
+	Consumer string `json:"consumer"`
+	Metadata
+}
+
+type PairMetadata struct {
+	Pair string `json:"pair"`
+	Metadata
+}
+
+type MetadataList struct {
+	Consumers []ConsumerMetadata `json:"consumers"`
+	Pairs     []PairMetadata     `json:"pairs"`
+}
+
+// AdapterCredentialRequest sets an adapter credential. Exactly one of Value, the key itself, and
+// Ref, an external secret reference, e.g. env:CMC_API_KEY, file:/run/secrets/cmc or
+// vault:cmc_api_key, is given
+type AdapterCredentialRequest struct {
+	Value           string `json:"value,omitempty"`
+	Ref             string `json:"ref,omitempty"`
+	RotateAfterDays uint64 `json:"rotate_after_days,omitempty"`
+	DailyQuota      uint64 `json:"daily_quota,omitempty"`
+}
+
+// AdapterCredential describes an adapter credential, without its value
+type AdapterCredential struct {
+	Name            string `json:"name"`
+	Ref             string `json:"ref,omitempty"`
+	Version         uint64 `json:"version"`
+	RotatedAt       int64  `json:"rotated_at"`
+	RotateAfterDays uint64 `json:"rotate_after_days,omitempty"`
+	RotationDue     bool   `json:"rotation_due,omitempty"`
+	DailyQuota      uint64 `json:"daily_quota,omitempty"`
+	UsedToday       uint64 `json:"used_today"`
+	TotalUses       uint64 `json:"total_uses"`
+	LastUsedAt      int64  `json:"last_used_at,omitempty"`
+	// the last error resolving the credential's reference, if any
+	Error string `json:"error,omitempty"`
+}
+
+type PairStatus struct {
+	Pair      string   `json:"pair"`
+	Supported bool     `json:"supported"`
+	Include   []string `json:"include,omitempty"`
+	Exclude   []string `json:"exclude,omitempty"`
+	// DEXes the operator mapped the pair to a contract on, with jobs.custom_pairs
+	CustomDexes []string `json:"custom_dexes,omitempty"`
+	// sources able to answer requests for the pair, after overrides
+	Sources []string `json:"sources"`
+	// the value and endpoint of the pair's most recent fulfillment
+	LastPrice         string         `json:"last_price,omitempty"`
+	LastPriceEndpoint string         `json:"last_price_endpoint,omitempty"`
+	LastPriceAt       int64          `json:"last_price_at,omitempty"`
+	Liquidity         []DexLiquidity `json:"liquidity,omitempty"`
+}
+
+// PublicPrice is a pair's most recently fulfilled price, as served by the public price API
+type PublicPrice struct {
+	Pair string `json:"pair"`
+	// Price is the answer submitted on chain, scaled to Decimals
+	Price     string `json:"price"`
+	Decimals  uint   `json:"decimals"`
+	Endpoint  string `json:"endpoint"`
+	RequestId string `json:"request_id"`
+	TxHash    string `json:"tx_hash"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// DexLiquidity is the liquidity of the DEX pair used to answer ad-hoc requests for a pair
+type DexLiquidity struct {
+	Dex         string  `json:"dex"`
+	PairAddress string  `json:"pair_address"`
+	ReserveUsd  float64 `json:"reserve_usd"`
+	// Volume24hUsd is only set once the pair's volume has been synced
+	Volume24hUsd float64 `json:"volume_24h_usd,omitempty"`
+}
+
+// VersionResponse is the node's build info, and the result of the last update check if enabled
+type VersionResponse struct {
+	version.Info
+	UpdateCheckEnabled bool   `json:"update_check_enabled"`
+	LatestVersion      string `json:"latest_version,omitempty"`
+	ReleaseUrl         string `json:"release_url,omitempty"`
+	UpdateAvailable    bool   `json:"update_available"`
+	CheckedAt          int64  `json:"checked_at,omitempty"`
+	CheckError         string `json:"check_error,omitempty"`
+}
+
+// ExplainedValue is a value fetched from a source while aggregating a price. Excluded is why it
+// was discarded, e.g. outlier, if it was
+type ExplainedValue struct {
+	Source    string  `json:"source"`
+	Value     float64 `json:"value"`
+	Deviation float64 `json:"deviation,omitempty"`
+	Excluded  string  `json:"excluded,omitempty"`
+	Weight    float64 `json:"weight"`
+	LatencyMs int64   `json:"latency_ms"`
+	// PriceImpact is the percent a trade of jobs.dex_impact_notional USD would move the price of
+	// the DEX pool the value came from
+	PriceImpact float64 `json:"price_impact,omitempty"`
+}
+
+// PriceExplanation is the breakdown of a price fetched and aggregated on demand, without being submitted
+type PriceExplanation struct {
+	Endpoint       string           `json:"endpoint"`
+	Method         string           `json:"method"`
+	Values         []ExplainedValue `json:"values"`
+	Rejected       []string         `json:"rejected,omitempty"`
+	Mean           float64          `json:"mean,omitempty"`
+	StdDev         float64          `json:"std_dev,omitempty"`
+	DMax           float64          `json:"d_max,omitempty"`
+	FxRate         float64          `json:"fx_rate,omitempty"`
+	Answer         string           `json:"answer"`
+	AnswerDecimals uint             `json:"answer_decimals"`
+	AnswerRounding string           `json:"answer_rounding,omitempty"`
+	SourceTier     string           `json:"source_tier,omitempty"`
+	Error          string           `json:"error,omitempty"`
+}
+
+// EndpointLint reports whether the node can serve a prospective request endpoint. Data is the
+// bytes32 value to send as the request's data, and Answer an example of the current value
+type EndpointLint struct {
+	Endpoint       string   `json:"endpoint"`
+	Data           string   `json:"data,omitempty"`
+	Servable       bool     `json:"servable"`
+	Kind           string   `json:"kind,omitempty"`
+	Pair           string   `json:"pair,omitempty"`
+	RejectionCode  string   `json:"rejection_code,omitempty"`
+	ErrorCode      string   `json:"error_code,omitempty"`
+	Reason         string   `json:"reason,omitempty"`
+	Sources        []string `json:"sources,omitempty"`
+	Answer         string   `json:"answer,omitempty"`
+	AnswerDecimals uint     `json:"answer_decimals"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// FeeRecommendation is the provider fee recommended from the mean gas cost of recent
+// fulfillments. Fees are in xFUND's smallest unit - 9 decimals, and costs in wei
+type FeeRecommendation struct {
+	CurrentFee     string  `json:"current_fee"`
+	RecommendedFee string  `json:"recommended_fee"`
+	Change         float64 `json:"change_percent"`
+	Samples        int     `json:"samples"`
+	Window         int64   `json:"window"`
+	MeanGasUsed    uint64  `json:"mean_gas_used"`
+	MeanGasPrice   string  `json:"mean_gas_price"`
+	MeanCost       string  `json:"mean_cost"`
+	XfundPrice     string  `json:"xfund_price"`
+	Margin         float64 `json:"margin"`
+	// Bounded is set if the recommendation was raised to min_fee, or lowered to max_fee
+	Bounded bool `json:"bounded,omitempty"`
+}
+
+// SubgraphBudget is the cost of the subgraph queries made in the UTC month, yyyy-mm, against the
+// monthly budget, which is 0 if unlimited. Throttled are the query classes, price or sync, whose
+// budget is spent
+type SubgraphBudget struct {
+	Month     string   `json:"month"`
+	Budget    float64  `json:"budget"`
+	Spent     float64  `json:"spent"`
+	Throttled []string `json:"throttled"`
+}
+
+type SourceHealth struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Healthy     bool   `json:"healthy"`
+	LastError   string `json:"last_error,omitempty"`
+	LastChecked int64  `json:"last_checked"`
+}
+
+type SourceExclusion struct {
+	Pair   string `json:"pair"`
+	Source string `json:"source"`
+	Reason string `json:"reason"`
+	Until  int64  `json:"until"`
+}
+
+// PairDelisting is a pair Finchains stopped listing at DelistedAt, with the consumers which
+// requested it in the lookback before, most requests first. Notified is true once it has been alerted
+type PairDelisting struct {
+	Pair       string                 `json:"pair"`
+	DelistedAt int64                  `json:"delisted_at"`
+	Consumers  []DelistedPairConsumer `json:"consumers"`
+	Notified   bool                   `json:"notified"`
+}
+
+// DelistedPairConsumer is a consumer's requests for a delisted pair, with its operator tags and note
+type DelistedPairConsumer struct {
+	Consumer        string   `json:"consumer"`
+	Requests        uint64   `json:"requests"`
+	LastRequestedAt int64    `json:"last_requested_at"`
+	Tags            []string `json:"tags,omitempty"`
+	Note            string   `json:"note,omitempty"`
+}
+
+// PairChange is a change to the pairs the node can answer, recorded at ChangedAt, unix seconds.
+// Pair is empty for a change to a source which affects every pair
+type PairChange struct {
+	Pair      string `json:"pair"`
+	Change    string `json:"change"`
+	Source    string `json:"source,omitempty"`
+	Reason    string `json:"reason"`
+	ChangedAt int64  `json:"changed_at"`
+}
+
+type NodeStatus struct {
+	Version       string `json:"version"`
+	OracleAddress string `json:"oracle_address"`
+	// RetiringAddress and RetiringUntil are set while a key rotation is in progress
+	RetiringAddress string `json:"retiring_address,omitempty"`
+	RetiringUntil   int64  `json:"retiring_until,omitempty"`
+	ContractAddress string `json:"contract_address"`
+	Leader          bool   `json:"leader"`
+	Paused          string `json:"paused"`
+	// Maintenance is set while the node is in maintenance mode
+	Maintenance   *MaintenanceStatus `json:"maintenance,omitempty"`
+	CurrentBlock  uint64             `json:"current_block"`
+	LastBlock     uint64             `json:"last_block"`
+	PendingJobs   int64              `json:"pending_jobs"`
+	WalletBalance string             `json:"wallet_balance"`
+	// XfundBalance and WithdrawableFees are in xFUND's smallest unit - 9 decimals
+	XfundBalance     string `json:"xfund_balance"`
+	WithdrawableFees string `json:"withdrawable_fees"`
+	// Keys is each key the node sends txs with, if there is more than the oracle key
+	Keys       []KeyBalance   `json:"keys,omitempty"`
+	Workers    int            `json:"workers"`
+	VorEnabled bool           `json:"vor_enabled"`
+	Sources    []SourceHealth `json:"sources"`
+	// SubgraphBudget is set if subgraph queries have a cost
+	SubgraphBudget *SubgraphBudget `json:"subgraph_budget,omitempty"`
+	// Totals are since the node was first run, restored from the database on restart
+	Totals LifetimeTotals `json:"totals"`
+}
+
+// LifetimeTotals are the node's lifetime fulfillment count, fees earned in xFUND's smallest
+// unit, and gas used and its cost in wei, by confirmed fulfillment txs
+type LifetimeTotals struct {
+	Fulfillments uint64 `json:"fulfillments"`
+	FeesEarned   string `json:"fees_earned"`
+	GasUsed      string `json:"gas_used"`
+	GasCost      string `json:"gas_cost"`
+}
+
+// KeyBalance is the balance of one of the keys the node sends txs with. Role is oracle, retiring
+// or vor
+type KeyBalance struct {
+	Role          string `json:"role"`
+	Address       string `json:"address"`
+	WalletBalance string `json:"wallet_balance"`
+	XfundBalance  string `json:"xfund_balance"`
+}
+
+// XfundAllowance is the xFUND the oracle key has approved a spender to transfer, in the smallest
+// unit. Spender is router, vor, or the spender's address
+type XfundAllowance struct {
+	Spender   string `json:"spender"`
+	Address   string `json:"address"`
+	Allowance string `json:"allowance"`
+}
+
+// ReconciliationDiscrepancy is a difference between the db's view of a request, or of the fee
+// balance, and the router's on-chain state. Kind is one of fulfilled_on_chain, not_in_db,
+// pending_on_chain or fee_balance. Fixed is true if the db was corrected
+type ReconciliationDiscrepancy struct {
+	Kind      string `json:"kind"`
+	RequestId string `json:"request_id,omitempty"`
+	DbStatus  string `json:"db_status,omitempty"`
+	Detail    string `json:"detail"`
+	Fixed     bool   `json:"fixed"`
+}
+
+// Reconciliation is the report of a reconciliation run, checking the requests received or
+// fulfilled in blocks FromBlock to ToBlock. Withdrawable is the providers' on-chain fee balance,
+// and ExpectedWithdrawable the balance expected from the db, which is empty on the first run
+type Reconciliation struct {
+	RanAt                int64                       `json:"ran_at"`
+	FromBlock            uint64                      `json:"from_block"`
+	ToBlock              uint64                      `json:"to_block"`
+	Checked              int                         `json:"checked"`
+	Fixed                int                         `json:"fixed"`
+	Withdrawable         string                      `json:"withdrawable"`
+	ExpectedWithdrawable string                      `json:"expected_withdrawable,omitempty"`
+	Discrepancies        []ReconciliationDiscrepancy `json:"discrepancies"`
+}
+
+// StateSnapshotDiscrepancy is a difference between a state snapshot and the db or chain when it
+// was verified. Kind is one of network, block, nonce or tx_dropped
+type StateSnapshotDiscrepancy struct {
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// StateSnapshot is the state a node saved on its last clean shutdown - the last block processed,
+// each key's next nonce by address and the fulfillment txs in flight. VerifiedAt is 0 until a
+// node has started processing since
+type StateSnapshot struct {
+	SavedAt       int64                      `json:"saved_at"`
+	Host          string                     `json:"host"`
+	NetworkId     int64                      `json:"network_id"`
+	LastBlock     uint64                     `json:"last_block"`
+	Nonces        map[string]uint64          `json:"nonces"`
+	PendingTxs    []string                   `json:"pending_txs"`
+	VerifiedAt    int64                      `json:"verified_at"`
+	Discrepancies []StateSnapshotDiscrepancy `json:"discrepancies"`
+}
+
+// ChainEvent is an event recorded for one of the chain.event_filters. Args are the event's
+// decoded arguments - addresses, hashes and byte arrays hex encoded, and integers as strings
+type ChainEvent struct {
+	Name        string                 `json:"name"`
+	Address     string                 `json:"address"`
+	Topic       string                 `json:"topic"`
+	BlockNumber uint64                 `json:"block_number"`
+	TxHash      string                 `json:"tx_hash"`
+	LogIndex    uint                   `json:"log_index"`
+	Args        map[string]interface{} `json:"args"`
+	Data        string                 `json:"data"`
+	RecordedAt  int64                  `json:"recorded_at"`
+}
+
+// ConfigReload lists the config keys changed by a reload. RestartRequired keys keep their
+// running values until the node is restarted
+type ConfigReload struct {
+	Changed         []string `json:"changed"`
+	RestartRequired []string `json:"restart_required"`
+}
+
+// ConfigSetting is a config key's running value, and where it was set - env, profile, file or
+// default. Secrets are redacted
+type ConfigSetting struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+// EffectiveConfig is the config the node is running with
+type EffectiveConfig struct {
+	ConfigFile string          `json:"config_file"`
+	Profile    string          `json:"profile"`
+	Settings   []ConfigSetting `json:"settings"`
+}
+
+type HealthCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Value  string `json:"value,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type HealthResponse struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
@@ -3,6 +3,7 @@ package utils
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -21,6 +22,34 @@ var ZeroAddress = common.Address{}
 // 0x0000000000000000000000000000000000000000000000000000000000000000
 var EmptyHash = common.Hash{}
 
+// MaxUint256 is the largest value which can be submitted on-chain as a uint256
+var MaxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// MaxUint256Decimals is the maximum number of decimals a scaled uint256 value can meaningfully have
+const MaxUint256Decimals = 77
+
+var (
+	// ErrUint256Overflow is returned when a scaled value does not fit into a uint256
+	ErrUint256Overflow = errors.New("value overflows uint256")
+	// ErrBelowPrecision is returned when a non-zero value is lost entirely when scaled
+	ErrBelowPrecision = errors.New("non-zero value is below the answer precision")
+	// ErrNegativeValue is returned when attempting to scale a negative value
+	ErrNegativeValue = errors.New("value is negative")
+)
+
+// rounding modes, for digits beyond the precision a value is scaled to
+const (
+	// RoundFloor discards the extra digits, rounding towards zero
+	RoundFloor = "floor"
+	// RoundHalfEven rounds to the nearest integer, and halves to the even one
+	RoundHalfEven = "half_even"
+	// RoundCeiling rounds any extra digits up, away from zero
+	RoundCeiling = "ceiling"
+)
+
+// RoundingModes are the valid rounding modes
+var RoundingModes = []string{RoundFloor, RoundHalfEven, RoundCeiling}
+
 // RemoveHexPrefix removes the prefix (0x) of a given hex string.
 func RemoveHexPrefix(str string) string {
 	if HasHexPrefix(str) {
@@ -85,3 +114,120 @@ func EtherToWei(eth *big.Float) *big.Int {
 	wei := new(big.Int).Add(truncInt, fracInt)
 	return wei
 }
+
+// ScaleToDecimals converts a decimal value into an integer with the given number of decimals,
+// for example 1.5 with 18 decimals is 1500000000000000000. The shortest decimal representation
+// of the value is used, so that binary floating point noise is not introduced, and any digits
+// beyond the requested precision are truncated.
+func ScaleToDecimals(value *big.Float, decimals uint) (*big.Int, error) {
+	return ScaleToDecimalsRounded(value, decimals, RoundFloor)
+}
+
+// ScaleToDecimalsRounded converts a decimal value into an integer with the given number of
+// decimals, as ScaleToDecimals, rounding digits beyond the requested precision with mode
+func ScaleToDecimalsRounded(value *big.Float, decimals uint, mode string) (*big.Int, error) {
+	if value.IsInf() {
+		return nil, ErrUint256Overflow
+	}
+
+	if value.Sign() < 0 {
+		return nil, ErrNegativeValue
+	}
+
+	rat, ok := new(big.Rat).SetString(value.Text('f', -1))
+
+	if !ok {
+		return nil, fmt.Errorf("unable to scale %s", value.Text('f', -1))
+	}
+
+	return ScaleRatToDecimalsRounded(rat, decimals, mode)
+}
+
+// ScaleRatToDecimals converts an exact rational value into an integer with the given number of
+// decimals, for example 3/2 with 18 decimals is 1500000000000000000. Digits beyond the requested
+// precision are truncated.
+func ScaleRatToDecimals(value *big.Rat, decimals uint) (*big.Int, error) {
+	return ScaleRatToDecimalsRounded(value, decimals, RoundFloor)
+}
+
+// ScaleRatToDecimalsRounded converts an exact rational value into an integer with the given
+// number of decimals, as ScaleRatToDecimals, rounding digits beyond the requested precision
+// with mode
+func ScaleRatToDecimalsRounded(value *big.Rat, decimals uint, mode string) (*big.Int, error) {
+	if value.Sign() < 0 {
+		return nil, ErrNegativeValue
+	}
+
+	if decimals > MaxUint256Decimals {
+		return nil, fmt.Errorf("decimals must be <= %d", MaxUint256Decimals)
+	}
+
+	mul := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	scaled, rem := new(big.Int).QuoRem(new(big.Int).Mul(value.Num(), mul), value.Denom(), new(big.Int))
+
+	if rem.Sign() != 0 {
+		switch mode {
+		case RoundFloor, "":
+		case RoundCeiling:
+			scaled.Add(scaled, big.NewInt(1))
+		case RoundHalfEven:
+			// compare the remainder with half the denominator
+			cmp := new(big.Int).Lsh(rem, 1).Cmp(value.Denom())
+			if cmp > 0 || (cmp == 0 && scaled.Bit(0) == 1) {
+				scaled.Add(scaled, big.NewInt(1))
+			}
+		default:
+			return nil, fmt.Errorf("unknown rounding mode %s", mode)
+		}
+	}
+
+	return checkScaled(scaled, value.Sign() > 0)
+}
+
+// RescaleDecimals converts a base 10 integer string with fromDecimals decimals into one
+// with toDecimals decimals. Digits are truncated if toDecimals < fromDecimals
+func RescaleDecimals(value string, fromDecimals uint, toDecimals uint) (string, error) {
+	if toDecimals > MaxUint256Decimals {
+		return "", fmt.Errorf("decimals must be <= %d", MaxUint256Decimals)
+	}
+
+	v, ok := new(big.Int).SetString(value, 10)
+
+	if !ok {
+		return "", fmt.Errorf("not an integer: %s", value)
+	}
+
+	if v.Sign() < 0 {
+		return "", ErrNegativeValue
+	}
+
+	scaled := new(big.Int).Set(v)
+
+	if toDecimals > fromDecimals {
+		mul := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(toDecimals-fromDecimals)), nil)
+		scaled.Mul(scaled, mul)
+	} else if fromDecimals > toDecimals {
+		div := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fromDecimals-toDecimals)), nil)
+		scaled.Quo(scaled, div)
+	}
+
+	res, err := checkScaled(scaled, v.Sign() > 0)
+
+	if err != nil {
+		return "", err
+	}
+
+	return res.String(), nil
+}
+
+func checkScaled(scaled *big.Int, nonZeroInput bool) (*big.Int, error) {
+	if scaled.Cmp(MaxUint256) > 0 {
+		return nil, ErrUint256Overflow
+	}
+
+	if scaled.Sign() == 0 && nonZeroInput {
+		return nil, ErrBelowPrecision
+	}
+
+	return scaled, nil
+}
@@ -0,0 +1,207 @@
+package utils
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func mustFloat(t *testing.T, s string) *big.Float {
+	t.Helper()
+	f, err := ParseBigFloat(s)
+	if err != nil {
+		t.Fatalf("ParseBigFloat(%q): %s", s, err)
+	}
+	return f
+}
+
+func TestScaleToDecimals(t *testing.T) {
+	// ParseBigFloat's 236 bits of precision hold 70 significant digits exactly. MaxUint256, of 78,
+	// is rounded up to 2^256
+	exact := strings.Repeat("1234567890", 7)
+	tests := []struct {
+		name     string
+		value    string
+		decimals uint
+		want     string
+		err      error
+	}{
+		{"18 decimals", "1.5", 18, "1500000000000000000", nil},
+		{"no float noise", "0.1", 18, "100000000000000000", nil},
+		{"extra digits truncated", "1.23456789", 2, "123", nil},
+		{"zero", "0", 18, "0", nil},
+		{"0 decimals", "42.9", 0, "42", nil},
+		{"sub-wei at 0 decimals", "0.9", 0, "", ErrBelowPrecision},
+		{"sub-wei at 18 decimals", "0.0000000000000000001", 18, "", ErrBelowPrecision},
+		{"70 significant digits", exact, 0, exact, nil},
+		{"70 significant digits at 7 decimals", exact, 7, exact + "0000000", nil},
+		{"max uint256 rounded up", MaxUint256.String(), 0, "", ErrUint256Overflow},
+		{"above max uint256", new(big.Int).Add(MaxUint256, big.NewInt(1)).String(), 0, "", ErrUint256Overflow},
+		{"very large supply at 18 decimals", "1" + strings.Repeat("0", 60), 18, "", ErrUint256Overflow},
+		{"large supply at 18 decimals", "1" + strings.Repeat("0", 58), 18, "1" + strings.Repeat("0", 76), nil},
+		{"77 decimals", "1", 77, "1" + strings.Repeat("0", 77), nil},
+		{"77 decimals overflow", "2", 77, "", ErrUint256Overflow},
+		{"negative", "-1", 18, "", ErrNegativeValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ScaleToDecimals(mustFloat(t, tt.value), tt.decimals)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("got %v, %v, want error %v", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScaleToDecimalsInf(t *testing.T) {
+	if _, err := ScaleToDecimals(new(big.Float).SetInf(false), 18); !errors.Is(err, ErrUint256Overflow) {
+		t.Errorf("got %v, want %v", err, ErrUint256Overflow)
+	}
+}
+
+func TestScaleToDecimals78Decimals(t *testing.T) {
+	if _, err := ScaleToDecimals(mustFloat(t, "1"), MaxUint256Decimals+1); err == nil {
+		t.Error("scaled to 78 decimals")
+	}
+	if _, err := ScaleRatToDecimals(big.NewRat(1, 1), MaxUint256Decimals+1); err == nil {
+		t.Error("scaled rat to 78 decimals")
+	}
+}
+
+func TestScaleToDecimalsRounded(t *testing.T) {
+	tests := []struct {
+		value    string
+		decimals uint
+		mode     string
+		want     string
+		err      error
+	}{
+		{"1.25", 1, RoundFloor, "12", nil},
+		{"1.25", 1, "", "12", nil},
+		{"1.25", 1, RoundCeiling, "13", nil},
+		{"1.25", 1, RoundHalfEven, "12", nil},
+		{"1.35", 1, RoundHalfEven, "14", nil},
+		{"1.251", 1, RoundHalfEven, "13", nil},
+		{"1.249", 1, RoundHalfEven, "12", nil},
+		{"1.2", 1, RoundCeiling, "12", nil},
+		{"0.5", 0, RoundHalfEven, "", ErrBelowPrecision},
+		{"1.5", 0, RoundHalfEven, "2", nil},
+		{"2.5", 0, RoundHalfEven, "2", nil},
+		// sub-wei prices round up to 1 wei, rather than being lost
+		{"0.0000001", 0, RoundCeiling, "1", nil},
+		{"0.0000001", 0, RoundFloor, "", ErrBelowPrecision},
+		{"1.5", 0, "up", "", errors.New("unknown rounding mode up")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value+"/"+tt.mode, func(t *testing.T) {
+			got, err := ScaleToDecimalsRounded(mustFloat(t, tt.value), tt.decimals, tt.mode)
+			if tt.err != nil {
+				if err == nil || (!errors.Is(err, tt.err) && err.Error() != tt.err.Error()) {
+					t.Fatalf("got %v, %v, want error %v", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScaleRatToDecimalsRounded(t *testing.T) {
+	max := new(big.Rat).SetInt(MaxUint256)
+	got, err := ScaleRatToDecimalsRounded(max, 0, RoundHalfEven)
+	if err != nil || got.Cmp(MaxUint256) != 0 {
+		t.Errorf("got %v, %v, want max uint256", got, err)
+	}
+
+	above := new(big.Rat).SetInt(new(big.Int).Add(MaxUint256, big.NewInt(1)))
+	if _, err = ScaleRatToDecimalsRounded(above, 0, RoundFloor); !errors.Is(err, ErrUint256Overflow) {
+		t.Errorf("got %v, want %v", err, ErrUint256Overflow)
+	}
+
+	// rounding a fraction above max uint256 up overflows, rounding it down does not
+	maxAndAHalf := new(big.Rat).Add(max, big.NewRat(1, 2))
+	if _, err = ScaleRatToDecimalsRounded(maxAndAHalf, 0, RoundCeiling); !errors.Is(err, ErrUint256Overflow) {
+		t.Errorf("ceiling: got %v, want %v", err, ErrUint256Overflow)
+	}
+	if got, err = ScaleRatToDecimalsRounded(maxAndAHalf, 0, RoundFloor); err != nil || got.Cmp(MaxUint256) != 0 {
+		t.Errorf("floor: got %v, %v, want max uint256", got, err)
+	}
+	// max uint256 is odd, so its half rounds up to the even 2^256
+	if _, err = ScaleRatToDecimalsRounded(maxAndAHalf, 0, RoundHalfEven); !errors.Is(err, ErrUint256Overflow) {
+		t.Errorf("half even: got %v, want %v", err, ErrUint256Overflow)
+	}
+
+	// 1 over a very large supply, at 77 decimals
+	tiny := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(78), nil))
+	if _, err = ScaleRatToDecimalsRounded(tiny, 77, RoundFloor); !errors.Is(err, ErrBelowPrecision) {
+		t.Errorf("sub-precision floor: got %v, want %v", err, ErrBelowPrecision)
+	}
+	if got, err = ScaleRatToDecimalsRounded(tiny, 77, RoundCeiling); err != nil || got.Int64() != 1 {
+		t.Errorf("sub-precision ceiling: got %v, %v, want 1", got, err)
+	}
+}
+
+func TestRescaleDecimals(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		from  uint
+		to    uint
+		want  string
+		err   error
+	}{
+		{"up", "15", 1, 18, "1500000000000000000", nil},
+		{"down truncates", "1999", 3, 0, "1", nil},
+		{"same", "12345", 6, 6, "12345", nil},
+		{"sub-wei at 0 decimals", "999", 3, 0, "", ErrBelowPrecision},
+		{"zero", "0", 18, 0, "0", nil},
+		{"max uint256", MaxUint256.String(), 18, 18, MaxUint256.String(), nil},
+		{"above max uint256", new(big.Int).Add(MaxUint256, big.NewInt(1)).String(), 18, 18, "", ErrUint256Overflow},
+		{"up to 77 decimals", "1", 0, 77, "1" + strings.Repeat("0", 77), nil},
+		{"up to 77 decimals overflow", "2", 0, 77, "", ErrUint256Overflow},
+		{"from 77 decimals", "1" + strings.Repeat("0", 77), 77, 0, "1", nil},
+		{"negative", "-1", 0, 18, "", ErrNegativeValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RescaleDecimals(tt.value, tt.from, tt.to)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("got %q, %v, want error %v", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := RescaleDecimals("1", 0, MaxUint256Decimals+1); err == nil {
+		t.Error("rescaled to 78 decimals")
+	}
+	if _, err := RescaleDecimals("1.5", 1, 18); err == nil {
+		t.Error("rescaled a non-integer")
+	}
+}
@@ -0,0 +1,293 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minRenewInterval - the token is never renewed more often than this
+const minRenewInterval = 10 * time.Second
+
+// Config configures access to HashiCorp Vault. Either Token, or RoleId and SecretId for AppRole
+// auth, must be set
+type Config struct {
+	Address   string
+	Namespace string
+	// Token if empty, VAULT_TOKEN is used, unless AppRole auth is configured
+	Token string
+	// RoleId and SecretId for AppRole auth. SecretIdFile may be used instead of SecretId,
+	// e.g. for a secret id delivered by an orchestrator
+	RoleId       string
+	SecretId     string
+	SecretIdFile string
+	// AppRoleMount path the AppRole auth method is mounted at. Defaults to approle
+	AppRoleMount string
+	Timeout      time.Duration
+}
+
+// Client reads secrets from Vault, keeping its token renewed
+type Client struct {
+	cfg    Config
+	client *http.Client
+	logger *logrus.Logger
+
+	mu        sync.Mutex
+	token     string
+	ttl       time.Duration
+	renewable bool
+}
+
+// NewClient authenticates with Vault
+func NewClient(logger *logrus.Logger, cfg Config) (*Client, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("no vault address configured")
+	}
+	cfg.Address = strings.TrimSuffix(cfg.Address, "/")
+	if cfg.AppRoleMount == "" {
+		cfg.AppRoleMount = "approle"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Token == "" && cfg.RoleId == "" {
+		cfg.Token = os.Getenv("VAULT_TOKEN")
+	}
+
+	c := &Client{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: logger,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+
+	var err error
+	if cfg.RoleId != "" {
+		err = c.loginAppRole(ctx)
+	} else if cfg.Token != "" {
+		err = c.lookupToken(ctx)
+	} else {
+		err = errors.New("no vault token or approle configured")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("vault auth: %s", err.Error())
+	}
+
+	return c, nil
+}
+
+// authResponse is the auth block returned by logins, renewals and token lookups
+type authResponse struct {
+	Auth *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int64  `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+	Data *struct {
+		Ttl       int64 `json:"ttl"`
+		Renewable bool  `json:"renewable"`
+	} `json:"data"`
+}
+
+func (c *Client) loginAppRole(ctx context.Context) error {
+	secretId := c.cfg.SecretId
+	if c.cfg.SecretIdFile != "" {
+		data, err := ioutil.ReadFile(c.cfg.SecretIdFile)
+		if err != nil {
+			return err
+		}
+		secretId = strings.TrimSpace(string(data))
+	}
+
+	var res authResponse
+	err := c.request(ctx, "POST", fmt.Sprintf("auth/%s/login", c.cfg.AppRoleMount), "", map[string]string{
+		"role_id":   c.cfg.RoleId,
+		"secret_id": secretId,
+	}, &res)
+	if err != nil {
+		return err
+	}
+	if res.Auth == nil || res.Auth.ClientToken == "" {
+		return errors.New("approle login returned no token")
+	}
+
+	c.setToken(res.Auth.ClientToken, res.Auth.LeaseDuration, res.Auth.Renewable)
+	return nil
+}
+
+// lookupToken checks a static token is valid, and gets its ttl
+func (c *Client) lookupToken(ctx context.Context) error {
+	var res authResponse
+	if err := c.request(ctx, "GET", "auth/token/lookup-self", c.cfg.Token, nil, &res); err != nil {
+		return err
+	}
+	if res.Data == nil {
+		return errors.New("token lookup returned no data")
+	}
+
+	c.setToken(c.cfg.Token, res.Data.Ttl, res.Data.Renewable)
+	return nil
+}
+
+func (c *Client) renewToken(ctx context.Context) error {
+	var res authResponse
+	if err := c.request(ctx, "POST", "auth/token/renew-self", c.currentToken(), map[string]string{}, &res); err != nil {
+		return err
+	}
+	if res.Auth == nil {
+		return errors.New("token renewal returned no auth")
+	}
+
+	c.setToken(c.currentToken(), res.Auth.LeaseDuration, res.Auth.Renewable)
+	return nil
+}
+
+func (c *Client) setToken(token string, ttlSecs int64, renewable bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+	c.ttl = time.Duration(ttlSecs) * time.Second
+	c.renewable = renewable
+}
+
+func (c *Client) currentToken() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// RunRenewal keeps the token valid until ctx is done: renewable tokens are renewed at half their
+// ttl, and AppRole tokens which can no longer be renewed are replaced by logging in again.
+// Tokens without a ttl, e.g. root tokens, need no renewal
+func (c *Client) RunRenewal(ctx context.Context) {
+	for {
+		c.mu.Lock()
+		ttl, renewable := c.ttl, c.renewable
+		c.mu.Unlock()
+
+		if ttl == 0 {
+			return
+		}
+		if !renewable && c.cfg.RoleId == "" {
+			c.logger.WithFields(logrus.Fields{
+				"package":  "vault",
+				"function": "RunRenewal",
+			}).Warn(fmt.Sprintf("vault token is not renewable and expires in %s", ttl.String()))
+			return
+		}
+
+		wait := ttl / 2
+		if wait < minRenewInterval {
+			wait = minRenewInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+		var err error
+		if renewable {
+			err = c.renewToken(reqCtx)
+		}
+		if (!renewable || err != nil) && c.cfg.RoleId != "" {
+			err = c.loginAppRole(reqCtx)
+		}
+		cancel()
+
+		if err != nil {
+			c.logger.WithFields(logrus.Fields{
+				"package":  "vault",
+				"function": "RunRenewal",
+			}).Error(fmt.Sprintf("cannot renew vault token: %s", err.Error()))
+			// retry sooner than the token expires
+			c.mu.Lock()
+			c.ttl = 2 * minRenewInterval
+			c.mu.Unlock()
+		}
+	}
+}
+
+// ReadKv reads the secret at path from a KV version 2 secrets engine mounted at mount
+func (c *Client) ReadKv(mount string, path string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
+	defer cancel()
+
+	var res struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	apiPath := fmt.Sprintf("%s/data/%s", strings.Trim(mount, "/"), strings.Trim(path, "/"))
+	if err := c.request(ctx, "GET", apiPath, c.currentToken(), nil, &res); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %s", apiPath, err.Error())
+	}
+
+	secret := make(map[string]string, len(res.Data.Data))
+	for k, v := range res.Data.Data {
+		if s, ok := v.(string); ok {
+			secret[k] = s
+		}
+	}
+	return secret, nil
+}
+
+func (c *Client) request(ctx context.Context, method string, path string, token string, params interface{}, out interface{}) error {
+	var body []byte
+	if params != nil {
+		var err error
+		if body, err = json.Marshal(params); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/v1/%s", c.cfg.Address, path), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if c.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.cfg.Namespace)
+	}
+	if params != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(respBody, &e) == nil && len(e.Errors) > 0 {
+			return fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(e.Errors, ", "))
+		}
+		return fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	return json.Unmarshal(respBody, out)
+}
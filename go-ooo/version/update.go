@@ -0,0 +1,106 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultReleaseUrl GitHub API endpoint for the latest go-ooo release
+const DefaultReleaseUrl = "https://api.github.com/repos/unification-com/xfund-router/releases/latest"
+
+// Release is the latest release published at the release endpoint
+type Release struct {
+	Version     string `json:"version"`
+	Url         string `json:"url"`
+	PublishedAt string `json:"published_at"`
+}
+
+// githubRelease is the part of GitHub's release response needed. Other endpoints returning
+// the same fields can also be used
+type githubRelease struct {
+	TagName     string `json:"tag_name"`
+	HtmlUrl     string `json:"html_url"`
+	PublishedAt string `json:"published_at"`
+}
+
+// LatestRelease queries the release endpoint at url for the latest published release
+func LatestRelease(ctx context.Context, client *http.Client, url string) (Release, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", fmt.Sprintf("%s/%s", Binary, Version))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Release{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("release endpoint returned %s", resp.Status)
+	}
+
+	var r githubRelease
+	if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return Release{}, err
+	}
+	if r.TagName == "" {
+		return Release{}, fmt.Errorf("release endpoint returned no version")
+	}
+
+	return Release{
+		Version:     strings.TrimPrefix(r.TagName, "v"),
+		Url:         r.HtmlUrl,
+		PublishedAt: r.PublishedAt,
+	}, nil
+}
+
+// IsNewer returns true if latest is a later semantic version than current. Pre-release and
+// build suffixes are ignored, and false is returned if either cannot be parsed
+func IsNewer(latest string, current string) bool {
+	l, ok := parseSemver(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseSemver(current)
+	if !ok {
+		return false
+	}
+
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+func parseSemver(v string) ([3]uint64, bool) {
+	var res [3]uint64
+
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+
+	parts := strings.Split(v, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return res, false
+	}
+
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return res, false
+		}
+		res[i] = n
+	}
+
+	return res, true
+}
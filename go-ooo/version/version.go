@@ -1,10 +1,11 @@
 package version
 
-// At build time, the variables Name, Version, Commit, and Binary
+// At build time, the variables Name, Version, Commit, BuildDate and Binary
 // can be passed as build flags as shown in the following example:
 //
 //  go build -X go-ooo/version.Version=1.0.0 \
-//		  -X go-ooo/version.Commit=abcd1234...
+//		  -X go-ooo/version.Commit=abcd1234... \
+//		  -X go-ooo/version.BuildDate=2021-11-01T12:00:00Z
 import (
 	"fmt"
 	"runtime"
@@ -15,14 +16,17 @@ var (
 	Binary  = "go-ooo"
 	Version = "0.0.1"
 	Commit  = ""
+	// BuildDate RFC 3339 UTC time the binary was built
+	BuildDate = ""
 )
 
 type Info struct {
-	Name      string
-	Binary    string
-	Version   string
-	GitCommit string
-	GoVersion string
+	Name      string `json:"name"`
+	Binary    string `json:"binary"`
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
 }
 
 func NewInfo() Info {
@@ -31,6 +35,7 @@ func NewInfo() Info {
 		Binary:    Binary,
 		Version:   Version,
 		GitCommit: Commit,
+		BuildDate: BuildDate,
 		GoVersion: fmt.Sprintf("go version %s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH),
 	}
 }
@@ -39,11 +44,12 @@ func (vi Info) String() string {
 	return fmt.Sprintf(`%s v%s
 binary: %s
 git commit: %s
+build date: %s
 %s`,
-		vi.Name, vi.Version, vi.Binary, vi.GitCommit, vi.GoVersion,
+		vi.Name, vi.Version, vi.Binary, vi.GitCommit, vi.BuildDate, vi.GoVersion,
 	)
 }
 
 func (vi Info) StringLine() string {
-	return fmt.Sprintf("%s v%s. git commit: %s. %s", vi.Name, vi.Version, vi.GitCommit, vi.GoVersion)
+	return fmt.Sprintf("%s v%s. git commit: %s. built: %s. %s", vi.Name, vi.Version, vi.GitCommit, vi.BuildDate, vi.GoVersion)
 }
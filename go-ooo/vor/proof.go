@@ -0,0 +1,304 @@
+package vor
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+)
+
+// ProofLength is the length of a marshalled VOR proof, as expected by the on-chain verifier:
+// pk (64), gamma (64), c (32), s (32), seed (32), uWitness (32), cGammaWitness (64), sHashWitness (64), zInv (32)
+const ProofLength = 64 + 64 + 32 + 32 + 32 + 32 + 64 + 64 + 32
+
+// CoordinatorProofLength is the length of the proof sent to fulfillRandomnessRequest,
+// which is the marshalled proof followed by the request block number
+const CoordinatorProofLength = ProofLength + 32
+
+// domain separators used by the on-chain VRF verifier
+var (
+	hashToCurveHashPrefix           = common.LeftPadBytes([]byte{1}, 32)
+	scalarFromCurvePointsHashPrefix = common.LeftPadBytes([]byte{2}, 32)
+	vorRandomOutputHashPrefix       = common.LeftPadBytes([]byte{3}, 32)
+)
+
+var (
+	curve      = crypto.S256()
+	fieldSize  = curve.Params().P
+	groupOrder = curve.Params().N
+	// (p + 1) / 4, used to compute square roots in the field, since p = 3 mod 4
+	sqrtPower = new(big.Int).Rsh(new(big.Int).Add(fieldSize, big.NewInt(1)), 2)
+)
+
+// Point is an affine secp256k1 curve point
+type Point struct {
+	X *big.Int
+	Y *big.Int
+}
+
+// Proof is a VOR proof, including the pre-calculated witnesses required
+// by the Solidity verifier
+type Proof struct {
+	PublicKey     Point
+	Gamma         Point
+	C             *big.Int
+	S             *big.Int
+	Seed          *big.Int
+	UWitness      common.Address
+	CGammaWitness Point
+	SHashWitness  Point
+	ZInv          *big.Int
+	Output        *big.Int
+}
+
+// PublicKey returns the VOR public key point for the given private key
+func PublicKey(sk *ecdsa.PrivateKey) Point {
+	return Point{X: new(big.Int).Set(sk.PublicKey.X), Y: new(big.Int).Set(sk.PublicKey.Y)}
+}
+
+// KeyHash returns the key hash used to identify a proving key on-chain
+func KeyHash(pk Point) common.Hash {
+	return crypto.Keccak256Hash(pk.marshal())
+}
+
+// FinalSeed returns the seed actually used by the VRF, mixing in the request's block hash
+func FinalSeed(preSeed *big.Int, blockHash common.Hash) *big.Int {
+	return new(big.Int).SetBytes(crypto.Keccak256(uint256Bytes(preSeed), blockHash.Bytes()))
+}
+
+// GenerateProof generates a VOR proof for the given seed
+func GenerateProof(sk *ecdsa.PrivateKey, seed *big.Int) (Proof, error) {
+	for {
+		nonce, err := rand.Int(rand.Reader, groupOrder)
+		if err != nil {
+			return Proof{}, err
+		}
+		if nonce.Sign() == 0 {
+			continue
+		}
+		proof, err := generateProofWithNonce(sk, seed, nonce)
+		if errors.Is(err, errDegenerateWitness) {
+			// cryptographically improbable. Try again with a new nonce
+			continue
+		}
+		return proof, err
+	}
+}
+
+// MarshalForCoordinator marshals the proof for the fulfillRandomnessRequest call. The coordinator
+// expects the pre-seed from the request event in place of the final seed, followed by the
+// request's block number, from which it re-derives the final seed.
+func MarshalForCoordinator(p Proof, preSeed *big.Int, blockNum uint64) []byte {
+	out := make([]byte, 0, CoordinatorProofLength)
+	out = append(out, p.PublicKey.marshal()...)
+	out = append(out, p.Gamma.marshal()...)
+	out = append(out, uint256Bytes(p.C)...)
+	out = append(out, uint256Bytes(p.S)...)
+	out = append(out, uint256Bytes(preSeed)...)
+	out = append(out, common.LeftPadBytes(p.UWitness.Bytes(), 32)...)
+	out = append(out, p.CGammaWitness.marshal()...)
+	out = append(out, p.SHashWitness.marshal()...)
+	out = append(out, uint256Bytes(p.ZInv)...)
+	out = append(out, uint256Bytes(new(big.Int).SetUint64(blockNum))...)
+	return out
+}
+
+var errDegenerateWitness = errors.New("c*gamma and s*hash witnesses share an x ordinate")
+
+func generateProofWithNonce(sk *ecdsa.PrivateKey, seed *big.Int, nonce *big.Int) (Proof, error) {
+	secret := sk.D
+	pk := PublicKey(sk)
+
+	h, err := hashToCurve(pk, seed)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	gamma := mul(secret, h)
+	u := baseMul(nonce)
+	uWitness := ethereumAddress(u)
+	v := mul(nonce, h)
+
+	c := scalarFromCurvePoints(h, pk, gamma, uWitness, v)
+
+	// s = (nonce - c*secret) mod groupOrder
+	s := new(big.Int).Mul(c, secret)
+	s.Sub(nonce, s)
+	s.Mod(s, groupOrder)
+
+	// sanity check: c*pk + s*G must equal u, which is what the verifier checks via ecrecover
+	if ethereumAddress(add(mul(c, pk), baseMul(s))) != uWitness {
+		return Proof{}, fmt.Errorf("generated proof failed to verify")
+	}
+
+	cGammaWitness := mul(c, gamma)
+	sHashWitness := mul(s, h)
+	if cGammaWitness.X.Cmp(sHashWitness.X) == 0 {
+		return Proof{}, errDegenerateWitness
+	}
+
+	_, _, z := projectiveECAdd(cGammaWitness, sHashWitness)
+	zInv := new(big.Int).ModInverse(z, fieldSize)
+	if zInv == nil {
+		return Proof{}, errDegenerateWitness
+	}
+
+	output := new(big.Int).SetBytes(crypto.Keccak256(vorRandomOutputHashPrefix, gamma.marshal()))
+
+	return Proof{
+		PublicKey:     pk,
+		Gamma:         gamma,
+		C:             c,
+		S:             s,
+		Seed:          seed,
+		UWitness:      uWitness,
+		CGammaWitness: cGammaWitness,
+		SHashWitness:  sHashWitness,
+		ZInv:          zInv,
+		Output:        output,
+	}, nil
+}
+
+// hashToCurve deterministically maps the public key and seed to a curve point, using the
+// same try-and-increment method as the on-chain verifier
+func hashToCurve(pk Point, seed *big.Int) (Point, error) {
+	input := append(append(append([]byte{}, hashToCurveHashPrefix...), pk.marshal()...), uint256Bytes(seed)...)
+	p := newCandidatePoint(input)
+	// each candidate has about a 50% chance of being on the curve
+	for i := 0; i < 1000; i++ {
+		if isOnCurve(p) {
+			return p, nil
+		}
+		p = newCandidatePoint(uint256Bytes(p.X))
+	}
+	return Point{}, fmt.Errorf("unable to hash seed %s to curve", seed.String())
+}
+
+func newCandidatePoint(b []byte) Point {
+	x := fieldHash(b)
+	y := new(big.Int).Exp(ySquared(x), sqrtPower, fieldSize)
+	if y.Bit(0) == 1 {
+		y.Sub(fieldSize, y)
+	}
+	return Point{X: x, Y: y}
+}
+
+// fieldHash hashes b to a uniformly distributed field element
+func fieldHash(b []byte) *big.Int {
+	x := new(big.Int).SetBytes(crypto.Keccak256(b))
+	for x.Cmp(fieldSize) >= 0 {
+		x.SetBytes(crypto.Keccak256(uint256Bytes(x)))
+	}
+	return x
+}
+
+func ySquared(x *big.Int) *big.Int {
+	y := new(big.Int).Exp(x, big.NewInt(3), fieldSize)
+	y.Add(y, big.NewInt(7))
+	return y.Mod(y, fieldSize)
+}
+
+func isOnCurve(p Point) bool {
+	y2 := new(big.Int).Mul(p.Y, p.Y)
+	y2.Mod(y2, fieldSize)
+	return y2.Cmp(ySquared(p.X)) == 0
+}
+
+func scalarFromCurvePoints(h, pk, gamma Point, uWitness common.Address, v Point) *big.Int {
+	return new(big.Int).SetBytes(crypto.Keccak256(
+		scalarFromCurvePointsHashPrefix,
+		h.marshal(),
+		pk.marshal(),
+		gamma.marshal(),
+		v.marshal(),
+		uWitness.Bytes(),
+	))
+}
+
+// projectiveECAdd mirrors VRF.sol's projectiveECAdd, so that the returned z ordinate is
+// exactly the one the verifier expects zInv to invert
+func projectiveECAdd(p, q Point) (*big.Int, *big.Int, *big.Int) {
+	one := big.NewInt(1)
+
+	lx := modSub(q.Y, p.Y)
+	lz := modSub(q.X, p.X)
+
+	sx, dx := projectiveMul(lx, lz, lx, lz)
+	sx, dx = projectiveSub(sx, dx, p.X, one)
+	sx, dx = projectiveSub(sx, dx, q.X, one)
+
+	sy, dy := projectiveSub(p.X, one, sx, dx)
+	sy, dy = projectiveMul(sy, dy, lx, lz)
+	sy, dy = projectiveSub(sy, dy, p.Y, one)
+
+	var sz *big.Int
+	if dx.Cmp(dy) != 0 {
+		sx = modMul(sx, dy)
+		sy = modMul(sy, dx)
+		sz = modMul(dx, dy)
+	} else {
+		sz = dx
+	}
+	return sx, sy, sz
+}
+
+func projectiveSub(x1, z1, x2, z2 *big.Int) (*big.Int, *big.Int) {
+	num1 := modMul(z2, x1)
+	num2 := modMul(new(big.Int).Sub(fieldSize, x2), z1)
+	return modAdd(num1, num2), modMul(z1, z2)
+}
+
+func projectiveMul(x1, z1, x2, z2 *big.Int) (*big.Int, *big.Int) {
+	return modMul(x1, x2), modMul(z1, z2)
+}
+
+func modAdd(a, b *big.Int) *big.Int {
+	r := new(big.Int).Add(a, b)
+	return r.Mod(r, fieldSize)
+}
+
+func modSub(a, b *big.Int) *big.Int {
+	r := new(big.Int).Sub(a, b)
+	return r.Mod(r, fieldSize)
+}
+
+func modMul(a, b *big.Int) *big.Int {
+	r := new(big.Int).Mul(a, b)
+	return r.Mod(r, fieldSize)
+}
+
+func mul(k *big.Int, p Point) Point {
+	x, y := curve.ScalarMult(p.X, p.Y, scalarBytes(k))
+	return Point{X: x, Y: y}
+}
+
+func baseMul(k *big.Int) Point {
+	x, y := curve.ScalarBaseMult(scalarBytes(k))
+	return Point{X: x, Y: y}
+}
+
+func add(p, q Point) Point {
+	x, y := curve.Add(p.X, p.Y, q.X, q.Y)
+	return Point{X: x, Y: y}
+}
+
+// ethereumAddress returns the Ethereum address corresponding to the point, as
+// used for the u witness
+func ethereumAddress(p Point) common.Address {
+	return common.BytesToAddress(crypto.Keccak256(p.marshal())[12:])
+}
+
+func scalarBytes(k *big.Int) []byte {
+	return uint256Bytes(new(big.Int).Mod(k, groupOrder))
+}
+
+func uint256Bytes(i *big.Int) []byte {
+	return common.LeftPadBytes(i.Bytes(), 32)
+}
+
+func (p Point) marshal() []byte {
+	return append(uint256Bytes(p.X), uint256Bytes(p.Y)...)
+}
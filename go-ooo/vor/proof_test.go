@@ -0,0 +1,187 @@
+package vor
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"testing"
+)
+
+func testKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	sk, err := crypto.HexToECDSA("59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sk
+}
+
+func hexInt(s string) *big.Int {
+	i, _ := new(big.Int).SetString(s, 16)
+	return i
+}
+
+func equal(p, q Point) bool {
+	return p.X.Cmp(q.X) == 0 && p.Y.Cmp(q.Y) == 0
+}
+
+// verify is a port of the on-chain verifier's checks in VRF.sol's verifyVRFProof, computing the
+// curve arithmetic with go-ethereum's secp256k1 rather than the proof's own witnesses
+func verify(p Proof) error {
+	for _, pt := range []Point{p.PublicKey, p.Gamma, p.CGammaWitness, p.SHashWitness} {
+		if !curve.IsOnCurve(pt.X, pt.Y) {
+			return errors.New("point not on curve")
+		}
+	}
+
+	// verifyLinearCombinationWithGenerator: u == c·pk + s·G, which the verifier checks by ecrecover
+	cpkX, cpkY := curve.ScalarMult(p.PublicKey.X, p.PublicKey.Y, scalarBytes(p.C))
+	sgX, sgY := curve.ScalarBaseMult(scalarBytes(p.S))
+	uX, uY := curve.Add(cpkX, cpkY, sgX, sgY)
+	if ethereumAddress(Point{X: uX, Y: uY}) != p.UWitness {
+		return errors.New("u != c·pk + s·G")
+	}
+
+	h, err := hashToCurve(p.PublicKey, p.Seed)
+	if err != nil {
+		return err
+	}
+
+	// linearCombination: v == c·gamma + s·h, from the witnesses and zInv
+	cgX, cgY := curve.ScalarMult(p.Gamma.X, p.Gamma.Y, scalarBytes(p.C))
+	if !equal(p.CGammaWitness, Point{X: cgX, Y: cgY}) {
+		return errors.New("cGammaWitness != c·gamma")
+	}
+	shX, shY := curve.ScalarMult(h.X, h.Y, scalarBytes(p.S))
+	if !equal(p.SHashWitness, Point{X: shX, Y: shY}) {
+		return errors.New("sHashWitness != s·h")
+	}
+	if p.CGammaWitness.X.Cmp(p.SHashWitness.X) == 0 {
+		return errors.New("witnesses have the same x ordinate")
+	}
+	x, y, z := projectiveECAdd(p.CGammaWitness, p.SHashWitness)
+	if modMul(z, p.ZInv).Cmp(big.NewInt(1)) != 0 {
+		return errors.New("z·zInv != 1")
+	}
+	v := Point{X: modMul(x, p.ZInv), Y: modMul(y, p.ZInv)}
+	vX, vY := curve.Add(cgX, cgY, shX, shY)
+	if !equal(v, Point{X: vX, Y: vY}) {
+		return errors.New("v != c·gamma + s·h")
+	}
+
+	if scalarFromCurvePoints(h, p.PublicKey, p.Gamma, p.UWitness, v).Cmp(p.C) != 0 {
+		return errors.New("c does not match the hash of the curve points")
+	}
+	if new(big.Int).SetBytes(crypto.Keccak256(vorRandomOutputHashPrefix, p.Gamma.marshal())).Cmp(p.Output) != 0 {
+		return errors.New("output is not the hash of gamma")
+	}
+	return nil
+}
+
+func TestGenerateProofVerifies(t *testing.T) {
+	sk := testKey(t)
+	for i := int64(0); i < 20; i++ {
+		seed := FinalSeed(big.NewInt(i), common.BigToHash(big.NewInt(1000+i)))
+		p, err := GenerateProof(sk, seed)
+		if err != nil {
+			t.Fatalf("seed %s: %s", seed, err)
+		}
+		if err = verify(p); err != nil {
+			t.Fatalf("seed %s: %s", seed, err)
+		}
+	}
+}
+
+func TestGenerateProofWithNonceVector(t *testing.T) {
+	sk := testKey(t)
+	p, err := generateProofWithNonce(sk, big.NewInt(42), big.NewInt(1234567890))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = verify(p); err != nil {
+		t.Fatal(err)
+	}
+
+	want := Proof{
+		Gamma: Point{
+			X: hexInt("29f73e99dcde8bcaf46258d2920085147e72ddab10645e90aa93e7266546325c"),
+			Y: hexInt("264eaea3d7b782a3439d9c2949a283a22029f228621a8b04de18275eba7c72b5"),
+		},
+		C:        hexInt("d826c8d6d9d56e97b3d70a8339d2df5d3213b0e9673f2d0707d0e5dc01a372ae"),
+		S:        hexInt("acdf5559be31989ede91c13aa4a80eafc83da2292f28d143897d4ce99836b6cb"),
+		UWitness: common.HexToAddress("0x75416b6b372c3a1121C3B1BA80170d87F59603B7"),
+		ZInv:     hexInt("d91adf1a80095a552743cf1d152fc58c072add53585ae4b4acbda9f4be88ac34"),
+		Output:   hexInt("b61c50c1a7a5571138e95f4039a4dcab63a96f04ebdc98551b06d772dee589c9"),
+	}
+	if !equal(p.Gamma, want.Gamma) {
+		t.Errorf("gamma %x, %x", p.Gamma.X, p.Gamma.Y)
+	}
+	for name, got := range map[string][2]*big.Int{
+		"c":      {p.C, want.C},
+		"s":      {p.S, want.S},
+		"zInv":   {p.ZInv, want.ZInv},
+		"output": {p.Output, want.Output},
+	} {
+		if got[0].Cmp(got[1]) != 0 {
+			t.Errorf("%s %x, want %x", name, got[0], got[1])
+		}
+	}
+	if p.UWitness != want.UWitness {
+		t.Errorf("uWitness %s, want %s", p.UWitness.Hex(), want.UWitness.Hex())
+	}
+}
+
+func TestVerifyRejectsTamperedProof(t *testing.T) {
+	sk := testKey(t)
+	p, err := generateProofWithNonce(sk, big.NewInt(42), big.NewInt(1234567890))
+	if err != nil {
+		t.Fatal(err)
+	}
+	one := big.NewInt(1)
+
+	tests := map[string]func(p *Proof){
+		"c":        func(p *Proof) { p.C = new(big.Int).Add(p.C, one) },
+		"s":        func(p *Proof) { p.S = new(big.Int).Add(p.S, one) },
+		"seed":     func(p *Proof) { p.Seed = big.NewInt(43) },
+		"zInv":     func(p *Proof) { p.ZInv = new(big.Int).Add(p.ZInv, one) },
+		"uWitness": func(p *Proof) { p.UWitness = common.Address{} },
+		"gamma":    func(p *Proof) { p.Gamma = PublicKey(sk) },
+		"witness":  func(p *Proof) { p.CGammaWitness, p.SHashWitness = p.SHashWitness, p.CGammaWitness },
+	}
+	for name, tamper := range tests {
+		tampered := p
+		tamper(&tampered)
+		if verify(tampered) == nil {
+			t.Errorf("proof with tampered %s verified", name)
+		}
+	}
+}
+
+func TestMarshalForCoordinator(t *testing.T) {
+	p, err := GenerateProof(testKey(t), big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	preSeed := hexInt("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20")
+	out := MarshalForCoordinator(p, preSeed, 123456)
+
+	if len(out) != CoordinatorProofLength {
+		t.Fatalf("length %d, want %d", len(out), CoordinatorProofLength)
+	}
+	// pk (64), gamma (64), c (32), s (32), then the pre-seed in place of the final seed
+	const seedOffset = 64 + 64 + 32 + 32
+	if got := new(big.Int).SetBytes(out[seedOffset : seedOffset+32]); got.Cmp(preSeed) != 0 {
+		t.Errorf("pre-seed %x at offset %d, want %x", got, seedOffset, preSeed)
+	}
+	if got := new(big.Int).SetBytes(out[:32]); got.Cmp(p.PublicKey.X) != 0 {
+		t.Errorf("pk x %x, want %x", got, p.PublicKey.X)
+	}
+	if got := new(big.Int).SetBytes(out[ProofLength-32 : ProofLength]); got.Cmp(p.ZInv) != 0 {
+		t.Errorf("zInv %x, want %x", got, p.ZInv)
+	}
+	if got := new(big.Int).SetBytes(out[ProofLength:]); got.Uint64() != 123456 {
+		t.Errorf("block number %s, want 123456", got)
+	}
+}
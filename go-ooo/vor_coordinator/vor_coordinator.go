@@ -0,0 +1,733 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package vor_coordinator
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// VorCoordinatorMetaData contains all meta data concerning the VorCoordinator contract.
+var VorCoordinatorMetaData = &bind.MetaData{
+	ABI: "[{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"keyHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"seed\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"fee\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"requestID\",\"type\":\"bytes32\"}],\"name\":\"RandomnessRequest\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"requestId\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"output\",\"type\":\"uint256\"}],\"name\":\"RandomnessRequestFulfilled\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"keyHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"fee\",\"type\":\"uint256\"}],\"name\":\"NewServiceAgreement\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_fee\",\"type\":\"uint256\"},{\"internalType\":\"addresspayable\",\"name\":\"_oracle\",\"type\":\"address\"},{\"internalType\":\"uint256[2]\",\"name\":\"_publicProvingKey\",\"type\":\"uint256[2]\"},{\"internalType\":\"bool\",\"name\":\"_providerPaysGas\",\"type\":\"bool\"}],\"name\":\"registerProvingKey\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes\",\"name\":\"_proof\",\"type\":\"bytes\"}],\"name\":\"fulfillRandomnessRequest\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256[2]\",\"name\":\"_publicKey\",\"type\":\"uint256[2]\"}],\"name\":\"hashOfKey\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"pure\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"_keyHash\",\"type\":\"bytes32\"}],\"name\":\"getProviderAddress\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"_keyHash\",\"type\":\"bytes32\"}],\"name\":\"getProviderFee\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// VorCoordinatorABI is the input ABI used to generate the binding from.
+// Deprecated: Use VorCoordinatorMetaData.ABI instead.
+var VorCoordinatorABI = VorCoordinatorMetaData.ABI
+
+// VorCoordinator is an auto generated Go binding around an Ethereum contract.
+type VorCoordinator struct {
+	VorCoordinatorCaller     // Read-only binding to the contract
+	VorCoordinatorTransactor // Write-only binding to the contract
+	VorCoordinatorFilterer   // Log filterer for contract events
+}
+
+// VorCoordinatorCaller is an auto generated read-only Go binding around an Ethereum contract.
+type VorCoordinatorCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// VorCoordinatorTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type VorCoordinatorTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// VorCoordinatorFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type VorCoordinatorFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// VorCoordinatorSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type VorCoordinatorSession struct {
+	Contract     *VorCoordinator   // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// VorCoordinatorCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type VorCoordinatorCallerSession struct {
+	Contract *VorCoordinatorCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts         // Call options to use throughout this session
+}
+
+// VorCoordinatorTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type VorCoordinatorTransactorSession struct {
+	Contract     *VorCoordinatorTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts         // Transaction auth options to use throughout this session
+}
+
+// VorCoordinatorRaw is an auto generated low-level Go binding around an Ethereum contract.
+type VorCoordinatorRaw struct {
+	Contract *VorCoordinator // Generic contract binding to access the raw methods on
+}
+
+// VorCoordinatorCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type VorCoordinatorCallerRaw struct {
+	Contract *VorCoordinatorCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// VorCoordinatorTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type VorCoordinatorTransactorRaw struct {
+	Contract *VorCoordinatorTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewVorCoordinator creates a new instance of VorCoordinator, bound to a specific deployed contract.
+func NewVorCoordinator(address common.Address, backend bind.ContractBackend) (*VorCoordinator, error) {
+	contract, err := bindVorCoordinator(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &VorCoordinator{VorCoordinatorCaller: VorCoordinatorCaller{contract: contract}, VorCoordinatorTransactor: VorCoordinatorTransactor{contract: contract}, VorCoordinatorFilterer: VorCoordinatorFilterer{contract: contract}}, nil
+}
+
+// NewVorCoordinatorCaller creates a new read-only instance of VorCoordinator, bound to a specific deployed contract.
+func NewVorCoordinatorCaller(address common.Address, caller bind.ContractCaller) (*VorCoordinatorCaller, error) {
+	contract, err := bindVorCoordinator(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &VorCoordinatorCaller{contract: contract}, nil
+}
+
+// NewVorCoordinatorTransactor creates a new write-only instance of VorCoordinator, bound to a specific deployed contract.
+func NewVorCoordinatorTransactor(address common.Address, transactor bind.ContractTransactor) (*VorCoordinatorTransactor, error) {
+	contract, err := bindVorCoordinator(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &VorCoordinatorTransactor{contract: contract}, nil
+}
+
+// NewVorCoordinatorFilterer creates a new log filterer instance of VorCoordinator, bound to a specific deployed contract.
+func NewVorCoordinatorFilterer(address common.Address, filterer bind.ContractFilterer) (*VorCoordinatorFilterer, error) {
+	contract, err := bindVorCoordinator(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &VorCoordinatorFilterer{contract: contract}, nil
+}
+
+// bindVorCoordinator binds a generic wrapper to an already deployed contract.
+func bindVorCoordinator(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(VorCoordinatorABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_VorCoordinator *VorCoordinatorRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _VorCoordinator.Contract.VorCoordinatorCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_VorCoordinator *VorCoordinatorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _VorCoordinator.Contract.VorCoordinatorTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_VorCoordinator *VorCoordinatorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _VorCoordinator.Contract.VorCoordinatorTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_VorCoordinator *VorCoordinatorCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _VorCoordinator.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_VorCoordinator *VorCoordinatorTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _VorCoordinator.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_VorCoordinator *VorCoordinatorTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _VorCoordinator.Contract.contract.Transact(opts, method, params...)
+}
+
+// GetProviderAddress is a free data retrieval call binding the contract method 0x9845fb9c.
+//
+// Solidity: function getProviderAddress(bytes32 _keyHash) view returns(address)
+func (_VorCoordinator *VorCoordinatorCaller) GetProviderAddress(opts *bind.CallOpts, _keyHash [32]byte) (common.Address, error) {
+	var out []interface{}
+	err := _VorCoordinator.contract.Call(opts, &out, "getProviderAddress", _keyHash)
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// GetProviderAddress is a free data retrieval call binding the contract method 0x9845fb9c.
+//
+// Solidity: function getProviderAddress(bytes32 _keyHash) view returns(address)
+func (_VorCoordinator *VorCoordinatorSession) GetProviderAddress(_keyHash [32]byte) (common.Address, error) {
+	return _VorCoordinator.Contract.GetProviderAddress(&_VorCoordinator.CallOpts, _keyHash)
+}
+
+// GetProviderAddress is a free data retrieval call binding the contract method 0x9845fb9c.
+//
+// Solidity: function getProviderAddress(bytes32 _keyHash) view returns(address)
+func (_VorCoordinator *VorCoordinatorCallerSession) GetProviderAddress(_keyHash [32]byte) (common.Address, error) {
+	return _VorCoordinator.Contract.GetProviderAddress(&_VorCoordinator.CallOpts, _keyHash)
+}
+
+// GetProviderFee is a free data retrieval call binding the contract method 0xda8d6d85.
+//
+// Solidity: function getProviderFee(bytes32 _keyHash) view returns(uint256)
+func (_VorCoordinator *VorCoordinatorCaller) GetProviderFee(opts *bind.CallOpts, _keyHash [32]byte) (*big.Int, error) {
+	var out []interface{}
+	err := _VorCoordinator.contract.Call(opts, &out, "getProviderFee", _keyHash)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetProviderFee is a free data retrieval call binding the contract method 0xda8d6d85.
+//
+// Solidity: function getProviderFee(bytes32 _keyHash) view returns(uint256)
+func (_VorCoordinator *VorCoordinatorSession) GetProviderFee(_keyHash [32]byte) (*big.Int, error) {
+	return _VorCoordinator.Contract.GetProviderFee(&_VorCoordinator.CallOpts, _keyHash)
+}
+
+// GetProviderFee is a free data retrieval call binding the contract method 0xda8d6d85.
+//
+// Solidity: function getProviderFee(bytes32 _keyHash) view returns(uint256)
+func (_VorCoordinator *VorCoordinatorCallerSession) GetProviderFee(_keyHash [32]byte) (*big.Int, error) {
+	return _VorCoordinator.Contract.GetProviderFee(&_VorCoordinator.CallOpts, _keyHash)
+}
+
+// HashOfKey is a free data retrieval call binding the contract method 0xcaf70c4a.
+//
+// Solidity: function hashOfKey(uint256[2] _publicKey) pure returns(bytes32)
+func (_VorCoordinator *VorCoordinatorCaller) HashOfKey(opts *bind.CallOpts, _publicKey [2]*big.Int) ([32]byte, error) {
+	var out []interface{}
+	err := _VorCoordinator.contract.Call(opts, &out, "hashOfKey", _publicKey)
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// HashOfKey is a free data retrieval call binding the contract method 0xcaf70c4a.
+//
+// Solidity: function hashOfKey(uint256[2] _publicKey) pure returns(bytes32)
+func (_VorCoordinator *VorCoordinatorSession) HashOfKey(_publicKey [2]*big.Int) ([32]byte, error) {
+	return _VorCoordinator.Contract.HashOfKey(&_VorCoordinator.CallOpts, _publicKey)
+}
+
+// HashOfKey is a free data retrieval call binding the contract method 0xcaf70c4a.
+//
+// Solidity: function hashOfKey(uint256[2] _publicKey) pure returns(bytes32)
+func (_VorCoordinator *VorCoordinatorCallerSession) HashOfKey(_publicKey [2]*big.Int) ([32]byte, error) {
+	return _VorCoordinator.Contract.HashOfKey(&_VorCoordinator.CallOpts, _publicKey)
+}
+
+// FulfillRandomnessRequest is a paid mutator transaction binding the contract method 0x5e1c1059.
+//
+// Solidity: function fulfillRandomnessRequest(bytes _proof) returns()
+func (_VorCoordinator *VorCoordinatorTransactor) FulfillRandomnessRequest(opts *bind.TransactOpts, _proof []byte) (*types.Transaction, error) {
+	return _VorCoordinator.contract.Transact(opts, "fulfillRandomnessRequest", _proof)
+}
+
+// FulfillRandomnessRequest is a paid mutator transaction binding the contract method 0x5e1c1059.
+//
+// Solidity: function fulfillRandomnessRequest(bytes _proof) returns()
+func (_VorCoordinator *VorCoordinatorSession) FulfillRandomnessRequest(_proof []byte) (*types.Transaction, error) {
+	return _VorCoordinator.Contract.FulfillRandomnessRequest(&_VorCoordinator.TransactOpts, _proof)
+}
+
+// FulfillRandomnessRequest is a paid mutator transaction binding the contract method 0x5e1c1059.
+//
+// Solidity: function fulfillRandomnessRequest(bytes _proof) returns()
+func (_VorCoordinator *VorCoordinatorTransactorSession) FulfillRandomnessRequest(_proof []byte) (*types.Transaction, error) {
+	return _VorCoordinator.Contract.FulfillRandomnessRequest(&_VorCoordinator.TransactOpts, _proof)
+}
+
+// RegisterProvingKey is a paid mutator transaction binding the contract method 0xb59b4feb.
+//
+// Solidity: function registerProvingKey(uint256 _fee, address _oracle, uint256[2] _publicProvingKey, bool _providerPaysGas) returns()
+func (_VorCoordinator *VorCoordinatorTransactor) RegisterProvingKey(opts *bind.TransactOpts, _fee *big.Int, _oracle common.Address, _publicProvingKey [2]*big.Int, _providerPaysGas bool) (*types.Transaction, error) {
+	return _VorCoordinator.contract.Transact(opts, "registerProvingKey", _fee, _oracle, _publicProvingKey, _providerPaysGas)
+}
+
+// RegisterProvingKey is a paid mutator transaction binding the contract method 0xb59b4feb.
+//
+// Solidity: function registerProvingKey(uint256 _fee, address _oracle, uint256[2] _publicProvingKey, bool _providerPaysGas) returns()
+func (_VorCoordinator *VorCoordinatorSession) RegisterProvingKey(_fee *big.Int, _oracle common.Address, _publicProvingKey [2]*big.Int, _providerPaysGas bool) (*types.Transaction, error) {
+	return _VorCoordinator.Contract.RegisterProvingKey(&_VorCoordinator.TransactOpts, _fee, _oracle, _publicProvingKey, _providerPaysGas)
+}
+
+// RegisterProvingKey is a paid mutator transaction binding the contract method 0xb59b4feb.
+//
+// Solidity: function registerProvingKey(uint256 _fee, address _oracle, uint256[2] _publicProvingKey, bool _providerPaysGas) returns()
+func (_VorCoordinator *VorCoordinatorTransactorSession) RegisterProvingKey(_fee *big.Int, _oracle common.Address, _publicProvingKey [2]*big.Int, _providerPaysGas bool) (*types.Transaction, error) {
+	return _VorCoordinator.Contract.RegisterProvingKey(&_VorCoordinator.TransactOpts, _fee, _oracle, _publicProvingKey, _providerPaysGas)
+}
+
+// VorCoordinatorNewServiceAgreementIterator is returned from FilterNewServiceAgreement and is used to iterate over the raw logs and unpacked data for NewServiceAgreement events raised by the VorCoordinator contract.
+type VorCoordinatorNewServiceAgreementIterator struct {
+	Event *VorCoordinatorNewServiceAgreement // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *VorCoordinatorNewServiceAgreementIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(VorCoordinatorNewServiceAgreement)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(VorCoordinatorNewServiceAgreement)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *VorCoordinatorNewServiceAgreementIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *VorCoordinatorNewServiceAgreementIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// VorCoordinatorNewServiceAgreement represents a NewServiceAgreement event raised by the VorCoordinator contract.
+type VorCoordinatorNewServiceAgreement struct {
+	KeyHash [32]byte
+	Fee     *big.Int
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterNewServiceAgreement is a free log retrieval operation binding the contract event 0xae189157e0628c1e62315e9179156e1ea10e90e9c15060002f7021e907dc2cfe.
+//
+// Solidity: event NewServiceAgreement(bytes32 keyHash, uint256 fee)
+func (_VorCoordinator *VorCoordinatorFilterer) FilterNewServiceAgreement(opts *bind.FilterOpts) (*VorCoordinatorNewServiceAgreementIterator, error) {
+
+	logs, sub, err := _VorCoordinator.contract.FilterLogs(opts, "NewServiceAgreement")
+	if err != nil {
+		return nil, err
+	}
+	return &VorCoordinatorNewServiceAgreementIterator{contract: _VorCoordinator.contract, event: "NewServiceAgreement", logs: logs, sub: sub}, nil
+}
+
+// WatchNewServiceAgreement is a free log subscription operation binding the contract event 0xae189157e0628c1e62315e9179156e1ea10e90e9c15060002f7021e907dc2cfe.
+//
+// Solidity: event NewServiceAgreement(bytes32 keyHash, uint256 fee)
+func (_VorCoordinator *VorCoordinatorFilterer) WatchNewServiceAgreement(opts *bind.WatchOpts, sink chan<- *VorCoordinatorNewServiceAgreement) (event.Subscription, error) {
+
+	logs, sub, err := _VorCoordinator.contract.WatchLogs(opts, "NewServiceAgreement")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(VorCoordinatorNewServiceAgreement)
+				if err := _VorCoordinator.contract.UnpackLog(event, "NewServiceAgreement", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseNewServiceAgreement is a log parse operation binding the contract event 0xae189157e0628c1e62315e9179156e1ea10e90e9c15060002f7021e907dc2cfe.
+//
+// Solidity: event NewServiceAgreement(bytes32 keyHash, uint256 fee)
+func (_VorCoordinator *VorCoordinatorFilterer) ParseNewServiceAgreement(log types.Log) (*VorCoordinatorNewServiceAgreement, error) {
+	event := new(VorCoordinatorNewServiceAgreement)
+	if err := _VorCoordinator.contract.UnpackLog(event, "NewServiceAgreement", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// VorCoordinatorRandomnessRequestIterator is returned from FilterRandomnessRequest and is used to iterate over the raw logs and unpacked data for RandomnessRequest events raised by the VorCoordinator contract.
+type VorCoordinatorRandomnessRequestIterator struct {
+	Event *VorCoordinatorRandomnessRequest // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *VorCoordinatorRandomnessRequestIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(VorCoordinatorRandomnessRequest)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(VorCoordinatorRandomnessRequest)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *VorCoordinatorRandomnessRequestIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *VorCoordinatorRandomnessRequestIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// VorCoordinatorRandomnessRequest represents a RandomnessRequest event raised by the VorCoordinator contract.
+type VorCoordinatorRandomnessRequest struct {
+	KeyHash   [32]byte
+	Seed      *big.Int
+	Sender    common.Address
+	Fee       *big.Int
+	RequestID [32]byte
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterRandomnessRequest is a free log retrieval operation binding the contract event 0xebb37373bb11123e38f964627878b02c247f92f3913df7cf3f270b5222c8d2be.
+//
+// Solidity: event RandomnessRequest(bytes32 keyHash, uint256 seed, address indexed sender, uint256 fee, bytes32 requestID)
+func (_VorCoordinator *VorCoordinatorFilterer) FilterRandomnessRequest(opts *bind.FilterOpts, sender []common.Address) (*VorCoordinatorRandomnessRequestIterator, error) {
+
+	var senderRule []interface{}
+	for _, senderItem := range sender {
+		senderRule = append(senderRule, senderItem)
+	}
+
+	logs, sub, err := _VorCoordinator.contract.FilterLogs(opts, "RandomnessRequest", senderRule)
+	if err != nil {
+		return nil, err
+	}
+	return &VorCoordinatorRandomnessRequestIterator{contract: _VorCoordinator.contract, event: "RandomnessRequest", logs: logs, sub: sub}, nil
+}
+
+// WatchRandomnessRequest is a free log subscription operation binding the contract event 0xebb37373bb11123e38f964627878b02c247f92f3913df7cf3f270b5222c8d2be.
+//
+// Solidity: event RandomnessRequest(bytes32 keyHash, uint256 seed, address indexed sender, uint256 fee, bytes32 requestID)
+func (_VorCoordinator *VorCoordinatorFilterer) WatchRandomnessRequest(opts *bind.WatchOpts, sink chan<- *VorCoordinatorRandomnessRequest, sender []common.Address) (event.Subscription, error) {
+
+	var senderRule []interface{}
+	for _, senderItem := range sender {
+		senderRule = append(senderRule, senderItem)
+	}
+
+	logs, sub, err := _VorCoordinator.contract.WatchLogs(opts, "RandomnessRequest", senderRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(VorCoordinatorRandomnessRequest)
+				if err := _VorCoordinator.contract.UnpackLog(event, "RandomnessRequest", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseRandomnessRequest is a log parse operation binding the contract event 0xebb37373bb11123e38f964627878b02c247f92f3913df7cf3f270b5222c8d2be.
+//
+// Solidity: event RandomnessRequest(bytes32 keyHash, uint256 seed, address indexed sender, uint256 fee, bytes32 requestID)
+func (_VorCoordinator *VorCoordinatorFilterer) ParseRandomnessRequest(log types.Log) (*VorCoordinatorRandomnessRequest, error) {
+	event := new(VorCoordinatorRandomnessRequest)
+	if err := _VorCoordinator.contract.UnpackLog(event, "RandomnessRequest", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// VorCoordinatorRandomnessRequestFulfilledIterator is returned from FilterRandomnessRequestFulfilled and is used to iterate over the raw logs and unpacked data for RandomnessRequestFulfilled events raised by the VorCoordinator contract.
+type VorCoordinatorRandomnessRequestFulfilledIterator struct {
+	Event *VorCoordinatorRandomnessRequestFulfilled // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *VorCoordinatorRandomnessRequestFulfilledIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(VorCoordinatorRandomnessRequestFulfilled)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(VorCoordinatorRandomnessRequestFulfilled)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *VorCoordinatorRandomnessRequestFulfilledIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *VorCoordinatorRandomnessRequestFulfilledIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// VorCoordinatorRandomnessRequestFulfilled represents a RandomnessRequestFulfilled event raised by the VorCoordinator contract.
+type VorCoordinatorRandomnessRequestFulfilled struct {
+	RequestId [32]byte
+	Output    *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterRandomnessRequestFulfilled is a free log retrieval operation binding the contract event 0xa2e7a402243ebda4a69ceeb3dfb682943b7a9b3ac66d6eefa8db65894009611c.
+//
+// Solidity: event RandomnessRequestFulfilled(bytes32 requestId, uint256 output)
+func (_VorCoordinator *VorCoordinatorFilterer) FilterRandomnessRequestFulfilled(opts *bind.FilterOpts) (*VorCoordinatorRandomnessRequestFulfilledIterator, error) {
+
+	logs, sub, err := _VorCoordinator.contract.FilterLogs(opts, "RandomnessRequestFulfilled")
+	if err != nil {
+		return nil, err
+	}
+	return &VorCoordinatorRandomnessRequestFulfilledIterator{contract: _VorCoordinator.contract, event: "RandomnessRequestFulfilled", logs: logs, sub: sub}, nil
+}
+
+// WatchRandomnessRequestFulfilled is a free log subscription operation binding the contract event 0xa2e7a402243ebda4a69ceeb3dfb682943b7a9b3ac66d6eefa8db65894009611c.
+//
+// Solidity: event RandomnessRequestFulfilled(bytes32 requestId, uint256 output)
+func (_VorCoordinator *VorCoordinatorFilterer) WatchRandomnessRequestFulfilled(opts *bind.WatchOpts, sink chan<- *VorCoordinatorRandomnessRequestFulfilled) (event.Subscription, error) {
+
+	logs, sub, err := _VorCoordinator.contract.WatchLogs(opts, "RandomnessRequestFulfilled")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(VorCoordinatorRandomnessRequestFulfilled)
+				if err := _VorCoordinator.contract.UnpackLog(event, "RandomnessRequestFulfilled", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseRandomnessRequestFulfilled is a log parse operation binding the contract event 0xa2e7a402243ebda4a69ceeb3dfb682943b7a9b3ac66d6eefa8db65894009611c.
+//
+// Solidity: event RandomnessRequestFulfilled(bytes32 requestId, uint256 output)
+func (_VorCoordinator *VorCoordinatorFilterer) ParseRandomnessRequestFulfilled(log types.Log) (*VorCoordinatorRandomnessRequestFulfilled, error) {
+	event := new(VorCoordinatorRandomnessRequestFulfilled)
+	if err := _VorCoordinator.contract.UnpackLog(event, "RandomnessRequestFulfilled", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
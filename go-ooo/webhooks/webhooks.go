@@ -0,0 +1,211 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// job lifecycle events
+const (
+	EventReceived  = "received"
+	EventFulfilled = "fulfilled"
+	EventFailed    = "failed"
+	EventSkipped   = "skipped"
+)
+
+// SignatureHeader - if a secret is configured, the hex encoded HMAC-SHA256 of the request body
+const SignatureHeader = "X-OoO-Signature"
+
+// queueSize - events are dropped if this many are waiting to be delivered
+const queueSize = 256
+
+// JobEvent is the JSON payload POSTed to each webhook url
+type JobEvent struct {
+	Event         string `json:"event"`
+	RequestId     string `json:"request_id"`
+	Consumer      string `json:"consumer"`
+	Endpoint      string `json:"endpoint"`
+	RequestStatus string `json:"request_status"`
+	StatusReason  string `json:"status_reason,omitempty"`
+	ErrorCode     string `json:"error_code,omitempty"`
+	Price         string `json:"price,omitempty"`
+	TxHash        string `json:"tx_hash,omitempty"`
+	Timestamp     int64  `json:"timestamp"`
+}
+
+// Notifier delivers job events to the configured webhook urls in the background
+type Notifier struct {
+	mu       sync.Mutex
+	settings *notifierSettings
+	running  bool
+	queue    chan JobEvent
+	logger   *logrus.Logger
+	ctx      context.Context
+}
+
+// notifierSettings are the webhook urls and delivery settings, which Reconfigure replaces
+type notifierSettings struct {
+	urls   []string
+	events map[string]bool
+	secret string
+	client *http.Client
+}
+
+// NewNotifier returns a Notifier. If events is empty, all events are sent. Delivery
+// runs until ctx is done
+func NewNotifier(ctx context.Context, logger *logrus.Logger, urls []string, events []string, secret string, timeout time.Duration) *Notifier {
+	n := &Notifier{
+		queue:  make(chan JobEvent, queueSize),
+		logger: logger,
+		ctx:    ctx,
+	}
+
+	n.Reconfigure(urls, events, secret, timeout)
+
+	return n
+}
+
+// Reconfigure replaces the webhook urls and delivery settings, e.g. when the config is
+// reloaded. Events already queued are delivered with the new settings
+func (n *Notifier) Reconfigure(urls []string, events []string, secret string, timeout time.Duration) {
+	s := &notifierSettings{
+		urls:   urls,
+		events: make(map[string]bool),
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+
+	for _, e := range events {
+		s.events[strings.ToLower(e)] = true
+	}
+
+	n.mu.Lock()
+	n.settings = s
+	start := len(s.urls) > 0 && !n.running
+	if start {
+		n.running = true
+	}
+	n.mu.Unlock()
+
+	if start {
+		go n.run()
+	}
+}
+
+// current returns the settings in use
+func (n *Notifier) current() *notifierSettings {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.settings
+}
+
+// Enabled returns true if any webhook urls are configured
+func (n *Notifier) Enabled() bool {
+	return n != nil && len(n.current().urls) > 0
+}
+
+// Notify queues an event for delivery. It does not block
+func (n *Notifier) Notify(ev JobEvent) {
+	if !n.Enabled() {
+		return
+	}
+
+	if s := n.current(); len(s.events) > 0 && !s.events[ev.Event] {
+		return
+	}
+
+	if ev.Timestamp == 0 {
+		ev.Timestamp = time.Now().Unix()
+	}
+
+	select {
+	case n.queue <- ev:
+	default:
+		n.logger.WithFields(logrus.Fields{
+			"package":    "webhooks",
+			"function":   "Notify",
+			"event":      ev.Event,
+			"request_id": ev.RequestId,
+		}).Warn("webhook queue full - event dropped")
+	}
+}
+
+func (n *Notifier) run() {
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case ev := <-n.queue:
+			s := n.current()
+			for _, url := range s.urls {
+				n.deliver(s, url, ev)
+			}
+		}
+	}
+}
+
+func (n *Notifier) deliver(s *notifierSettings, url string, ev JobEvent) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+
+	send := func() error {
+		req, err := http.NewRequestWithContext(n.ctx, "POST", url, bytes.NewReader(body))
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		if s.secret != "" {
+			req.Header.Set(SignatureHeader, Sign(body, s.secret))
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("webhook returned %s", resp.Status)
+		}
+		if resp.StatusCode >= 300 {
+			return backoff.Permanent(fmt.Errorf("webhook returned %s", resp.Status))
+		}
+
+		return nil
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.MaxElapsedTime = time.Minute
+
+	err = backoff.Retry(send, backoff.WithContext(b, n.ctx))
+	if err != nil {
+		n.logger.WithFields(logrus.Fields{
+			"package":    "webhooks",
+			"function":   "deliver",
+			"url":        url,
+			"event":      ev.Event,
+			"request_id": ev.RequestId,
+		}).Error(err.Error())
+	}
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of body, keyed with secret
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}